@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"pkm-sync/internal/config"
+	"pkm-sync/internal/sync"
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+// bootstrapSource imports the history of a source back to since in chunks, newest first. Each chunk is
+// exported like a regular sync and the progress is saved after it, so an interrupted or throttled bootstrap
+// resumes where it stopped on the next run. r holds the target settings; its items are ignored.
+func bootstrapSource(r syncRun, srcName string, sourceConfig models.SourceConfig, source interfaces.Source,
+	since time.Time, chunk sync.BootstrapChunk, budgets *quotaBudgets,
+) error {
+	fetcher, ok := source.(interfaces.RangeFetcher)
+	if !ok {
+		return fmt.Errorf("source '%s' does not support bootstrapping", srcName)
+	}
+
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return err
+	}
+
+	statePath := filepath.Join(configDir, sync.BootstrapStateFile(srcName))
+
+	state, err := sync.LoadBootstrap(statePath, since, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if state.Done() {
+		fmt.Printf("Bootstrap of '%s' is complete back to %s (%d items)\n", srcName,
+			state.Since.Format("2006-01-02"), state.Items)
+
+		return nil
+	}
+
+	if state.Chunks > 0 {
+		fmt.Printf("Resuming bootstrap of '%s' at %s\n", srcName, state.Cursor.Format("2006-01-02"))
+	}
+
+	fmt.Printf("Bootstrapping '%s' back to %s, one %s per chunk\n", srcName, since.Format("2006-01-02"), chunk)
+
+	for !state.Done() {
+		start, end, limit := state.Next(chunk)
+		began := time.Now()
+
+		items, err := fetcher.FetchRange(start, end, limit)
+		budgets.save()
+
+		if err != nil {
+			return fmt.Errorf("failed to fetch chunk before %s: %w", end.Format("2006-01-02"), err)
+		}
+
+		// The chunk advances by what was fetched, including items the keyword filters drop
+		chunkRun := r
+		chunkRun.settings = newItemSettings()
+		chunkRun.items = prepareSourceItems(r.cfg, srcName, sourceConfig, items, chunkRun.settings)
+		chunkRun.sourceCounts = map[string]int{srcName: len(chunkRun.items)}
+
+		if len(chunkRun.items) > 0 {
+			if err := exportSyncedItems(chunkRun); err != nil {
+				return err
+			}
+		}
+
+		state.Advance(chunk, start, items, time.Since(began))
+
+		if err := state.Save(statePath); err != nil {
+			return err
+		}
+
+		fmt.Println(state.Status())
+	}
+
+	fmt.Printf("Bootstrap of '%s' complete: %d items in %d chunks\n", srcName, state.Items, state.Chunks)
+
+	return nil
+}
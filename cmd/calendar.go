@@ -171,6 +171,10 @@ func runCalendarCommand(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("failed to create drive service: %w", err)
 		}
+
+		if err := configureDriveExport(driveService); err != nil {
+			return err
+		}
 	}
 
 	// Get date range using smart defaults.
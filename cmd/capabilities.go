@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+// sourceCapabilities returns what a configured source supports, without configuring it. Unknown source
+// types support nothing; they fail once the source is created.
+func sourceCapabilities(srcName string, sourceConfig models.SourceConfig) interfaces.SourceCapabilities {
+	source, err := newSource(srcName, sourceConfig)
+	if err != nil {
+		return interfaces.SourceCapabilities{}
+	}
+
+	return source.Capabilities()
+}
+
+// describeCapabilities lists the supported capabilities, e.g. "incremental, attachments", or "none".
+func describeCapabilities(caps interfaces.SourceCapabilities) string {
+	var names []string
+
+	for _, capability := range []struct {
+		name      string
+		supported bool
+	}{
+		{"incremental", caps.Incremental},
+		{"realtime", caps.Realtime},
+		{"attachments", caps.Attachments},
+		{"write-back", caps.WriteBack},
+	} {
+		if capability.supported {
+			names = append(names, capability.name)
+		}
+	}
+
+	if len(names) == 0 {
+		return "none"
+	}
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+func TestSourceCapabilities(t *testing.T) {
+	tests := []struct {
+		sourceType string
+		want       string
+	}{
+		{"gmail", "incremental, attachments, write-back"},
+		{"google_calendar", "attachments"},
+		{"google_tasks", "write-back"},
+		{"outlook_calendar", "none"},
+		{"bookmarks", "incremental"},
+		{"slack", "none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sourceType, func(t *testing.T) {
+			caps := sourceCapabilities("test", models.SourceConfig{Type: tt.sourceType})
+			if got := describeCapabilities(caps); got != tt.want {
+				t.Errorf("capabilities of %s = %q, want %q", tt.sourceType, got, tt.want)
+			}
+		})
+	}
+
+	listen := models.SourceConfig{Type: "ingest", Ingest: models.IngestSourceConfig{Listen: ":8080"}}
+	if caps := sourceCapabilities("ingest", listen); caps != (interfaces.SourceCapabilities{
+		Incremental: true, Realtime: true, Attachments: true,
+	}) {
+		t.Errorf("capabilities of a listening ingest source = %+v", caps)
+	}
+}
@@ -50,8 +50,11 @@ var configPathCmd = &cobra.Command{
 var configValidateCmd = &cobra.Command{
 	Use:   "validate",
 	Short: "Validate configuration file",
-	Long:  "Check if the configuration file is valid and can be loaded successfully.",
-	RunE:  runConfigValidateCommand,
+	Long: `Check if the configuration file is valid and can be loaded successfully.
+
+Settings that are valid but likely mistakes, such as a Gmail source with neither labels nor a query, are
+listed as warnings. Use --strict to fail on warnings too, e.g. in CI.`,
+	RunE: runConfigValidateCommand,
 }
 
 var configEditCmd = &cobra.Command{
@@ -73,8 +76,11 @@ func init() {
 	// Flags for config init
 	configInitCmd.Flags().BoolP("force", "f", false, "Overwrite existing config file")
 	configInitCmd.Flags().StringP("output", "o", "", "Output directory for default target")
-	configInitCmd.Flags().String("target", "", "Default target (obsidian, logseq)")
+	configInitCmd.Flags().String("target", "", "Default target (obsidian, logseq, ical)")
 	configInitCmd.Flags().String("source", "", "Default source (google_calendar)")
+
+	// Flags for config validate
+	configValidateCmd.Flags().Bool("strict", false, "Fail when the configuration has lint warnings")
 }
 func runConfigInitCommand(cmd *cobra.Command, args []string) error {
 	force, _ := cmd.Flags().GetBool("force")
@@ -205,11 +211,31 @@ func runConfigValidateCommand(cmd *cobra.Command, args []string) error {
 
 	fmt.Println("✅ Configuration is valid")
 	fmt.Printf("   Enabled sources: [%s]\n", strings.Join(enabledSources, ", "))
+
+	for _, srcName := range enabledSources {
+		fmt.Printf("     %s: %s\n", srcName, describeCapabilities(sourceCapabilities(srcName, cfg.Sources[srcName])))
+	}
+
 	fmt.Printf("   Default target: %s\n", cfg.Sync.DefaultTarget)
 	fmt.Printf("   Default output: %s\n", cfg.Sync.DefaultOutputDir)
 	fmt.Printf("   Source tags: %t\n", cfg.Sync.SourceTags)
 	fmt.Printf("   Merge sources: %t\n", cfg.Sync.MergeSources)
 
+	warnings := config.LintConfig(cfg)
+	if len(warnings) == 0 {
+		return nil
+	}
+
+	fmt.Printf("\n⚠️  %d warning(s):\n", len(warnings))
+
+	for _, warning := range warnings {
+		fmt.Printf("   %s\n", warning)
+	}
+
+	if strict, _ := cmd.Flags().GetBool("strict"); strict {
+		return fmt.Errorf("configuration has %d lint warning(s)", len(warnings))
+	}
+
 	return nil
 }
 
@@ -4,11 +4,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
+	"pkm-sync/internal/config"
+	"pkm-sync/internal/sources/google"
 	"pkm-sync/internal/sources/google/auth"
 	"pkm-sync/internal/sources/google/calendar"
 	"pkm-sync/internal/sources/google/drive"
+	"pkm-sync/pkg/models"
 
 	"github.com/spf13/cobra"
 )
@@ -58,6 +62,10 @@ func runDriveCommand(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create drive service: %w", err)
 	}
 
+	if err := configureDriveExport(driveService); err != nil {
+		return err
+	}
+
 	// Create output directory
 	if err := os.MkdirAll(driveOutputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
@@ -182,3 +190,32 @@ func getDriveExportDateRange() (time.Time, time.Time, error) {
 
 	return start, end, nil
 }
+
+// configureDriveExport applies doc_formats and max_doc_size from the first enabled Google Calendar source.
+func configureDriveExport(driveService *drive.Service) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		// If no config exists, use defaults
+		cfg = config.GetDefaultConfig()
+	}
+
+	return google.ConfigureDriveService(driveService, calendarSourceConfig(cfg).Google)
+}
+
+// calendarSourceConfig returns the first enabled Google Calendar source, by name.
+func calendarSourceConfig(cfg *models.Config) models.SourceConfig {
+	names := make([]string, 0, len(cfg.Sources))
+	for name := range cfg.Sources {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if source := cfg.Sources[name]; source.Enabled && source.Type == "google_calendar" {
+			return source
+		}
+	}
+
+	return models.SourceConfig{}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"pkm-sync/internal/recording"
+	"pkm-sync/internal/sources/google/auth"
+	"pkm-sync/pkg/models"
+)
+
+// recordableSourceTypes are the source types whose API responses can be recorded and played back: those
+// that fetch through the HTTP client they are configured with.
+var recordableSourceTypes = map[string]bool{
+	"gmail":           true,
+	"google_calendar": true,
+	"google_drive":    true,
+}
+
+// fixtureSession records the API responses of a sync with --record, or plays them back with --playback.
+// A nil session does neither.
+type fixtureSession struct {
+	dir      string
+	playback bool
+	manifest *recording.Manifest
+}
+
+// syncFixtures is the fixture session of the running sync command.
+var syncFixtures *fixtureSession
+
+// newFixtureSession starts recording to recordDir or playing back from playbackDir; with neither set it
+// returns nil.
+func newFixtureSession(recordDir, playbackDir string) (*fixtureSession, error) {
+	switch {
+	case recordDir != "" && playbackDir != "":
+		return nil, fmt.Errorf("--record and --playback cannot be used together")
+	case recordDir != "":
+		return &fixtureSession{dir: recordDir, manifest: &recording.Manifest{
+			RecordedAt: time.Now().UTC(),
+			Sources:    make(map[string]recording.Window),
+		}}, nil
+	case playbackDir != "":
+		manifest, err := recording.LoadManifest(playbackDir)
+		if err != nil {
+			return nil, err
+		}
+
+		return &fixtureSession{dir: playbackDir, playback: true, manifest: manifest}, nil
+	}
+
+	return nil, nil
+}
+
+// playingBack reports whether the sync runs against a recording.
+func (f *fixtureSession) playingBack() bool {
+	return f != nil && f.playback
+}
+
+// client returns the HTTP client a source is created with: one recording its responses, one answering
+// from the recording, or nil for the source's own client.
+func (f *fixtureSession) client(srcName string, sourceConfig models.SourceConfig) (*http.Client, error) {
+	if f == nil {
+		return nil, nil
+	}
+
+	if !recordableSourceTypes[sourceConfig.Type] {
+		return nil, fmt.Errorf("source type '%s' of source '%s' can't be recorded or played back", sourceConfig.Type,
+			srcName)
+	}
+
+	dir := recording.SourceDir(f.dir, srcName)
+
+	if f.playback {
+		player, err := recording.NewPlayer(dir)
+		if err != nil {
+			return nil, fmt.Errorf("source '%s': %w", srcName, err)
+		}
+
+		return &http.Client{Transport: player}, nil
+	}
+
+	base, err := auth.GetClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authenticated client: %w", err)
+	}
+
+	recorded := *base
+	recorded.Transport = recording.NewRecorder(base.Transport, dir)
+
+	return &recorded, nil
+}
+
+// window returns the time range a source is fetched for. Recordings remember it, and playback uses the
+// recorded one, so the same items pass the since and until filters.
+func (f *fixtureSession) window(srcName string, since, until time.Time) (time.Time, time.Time, error) {
+	if f == nil {
+		return since, until, nil
+	}
+
+	if !f.playback {
+		f.manifest.Sources[srcName] = recording.Window{Since: since, Until: until}
+
+		return since, until, nil
+	}
+
+	recorded, ok := f.manifest.Sources[srcName]
+	if !ok {
+		return since, until, fmt.Errorf("source '%s' is not in the recording %s", srcName, f.dir)
+	}
+
+	return recorded.Since, recorded.Until, nil
+}
+
+// save writes the manifest of a recording.
+func (f *fixtureSession) save() error {
+	if f == nil || f.playback {
+		return nil
+	}
+
+	if err := f.manifest.Save(f.dir); err != nil {
+		return err
+	}
+
+	fmt.Printf("Recorded API responses to %s; check them for private content before sharing\n", f.dir)
+
+	return nil
+}
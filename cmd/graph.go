@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"pkm-sync/internal/config"
+	"pkm-sync/internal/graph"
+	"pkm-sync/internal/statestore"
+	"pkm-sync/pkg/models"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	graphSourceNames []string
+	graphSince       string
+	graphFormat      string
+	graphOutput      string
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Export the link graph of synced items",
+	Long: `Fetch items from the configured sources, as a sync would, and export their link graph: items,
+the people they involve, their tags and the URLs they mention as nodes, linked by sender, recipient,
+organizer, attendee, tag, link and thread message edges. The graph is written as GraphML (Gephi, yEd),
+DOT (Graphviz) or JSON.
+
+Examples:
+  pkm-sync graph
+  pkm-sync graph --source gmail_work --since 30d --format dot -o work.dot
+  pkm-sync graph --format json -o graph.json`,
+	RunE: runGraphCommand,
+}
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+	graphCmd.Flags().StringSliceVar(&graphSourceNames, "source", nil, "Sources to include (default: all enabled sources)")
+	graphCmd.Flags().StringVar(&graphSince, "since", "", "Include items since (default: sync.default_since)")
+	graphCmd.Flags().StringVar(&graphFormat, "format", graph.FormatGraphML, "Output format (graphml, dot, json)")
+	graphCmd.Flags().StringVarP(&graphOutput, "output", "o", "", "Output file (default: graph.<format>)")
+}
+
+func runGraphCommand(cmd *cobra.Command, args []string) error {
+	switch graphFormat {
+	case graph.FormatGraphML, graph.FormatDOT, graph.FormatJSON:
+	default:
+		return fmt.Errorf("unsupported graph format '%s': supported formats are 'graphml', 'dot', 'json'", graphFormat)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		cfg = config.GetDefaultConfig()
+	}
+
+	if err := statestore.Configure(cfg.App.StateEncryption); err != nil {
+		return err
+	}
+
+	sourceNames := graphSourceNames
+	if len(sourceNames) == 0 {
+		sourceNames = getEnabledSources(cfg)
+	}
+
+	if len(sourceNames) == 0 {
+		return fmt.Errorf("no sources to include: enable a source or pass --source")
+	}
+
+	since := cfg.Sync.DefaultSince
+	if graphSince != "" {
+		since = graphSince
+	}
+
+	sinceTime, err := parseSinceTime(since)
+	if err != nil {
+		return fmt.Errorf("invalid since time: %w", err)
+	}
+
+	outputPath := graphOutput
+	if outputPath == "" {
+		outputPath = "graph." + graphFormat
+	}
+
+	var items []models.ItemInterface
+
+	settings := newItemSettings()
+	budgets := loadQuotaBudgets(cfg)
+
+	// A failing source is skipped so the graph still covers the others
+	for _, srcName := range sourceNames {
+		sourceConfig, exists := cfg.Sources[srcName]
+		if !exists {
+			fmt.Printf("Warning: source '%s' not configured, skipping\n", srcName)
+
+			continue
+		}
+
+		if !sourceConfig.Enabled {
+			fmt.Printf("Source '%s' is disabled, skipping\n", srcName)
+
+			continue
+		}
+
+		if err := budgets.use(sourceConfig); err != nil {
+			fmt.Printf("Skipping source '%s': %v\n", srcName, err)
+
+			continue
+		}
+
+		until, err := sourceUntilTime(srcName, sourceConfig, "")
+		if err != nil {
+			fmt.Printf("Warning: %v, skipping\n", err)
+
+			continue
+		}
+
+		_, sourceItems, err := fetchSourceItems(srcName, sourceConfig, sinceTime, since, graphSince != "", until, nil, nil)
+		budgets.save()
+
+		if err != nil {
+			if !budgets.exhausted(srcName, err) {
+				fmt.Printf("Warning: %v, skipping\n", err)
+			}
+
+			continue
+		}
+
+		items = append(items, prepareSourceItems(cfg, srcName, sourceConfig, sourceItems, settings)...)
+	}
+
+	linkGraph := graph.Build(items)
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create graph file: %w", err)
+	}
+
+	if err := linkGraph.Write(file, graphFormat); err != nil {
+		_ = file.Close()
+
+		return fmt.Errorf("failed to write graph: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write graph: %w", err)
+	}
+
+	fmt.Printf("Wrote graph of %d items (%d nodes, %d edges) to %s\n",
+		len(items), len(linkGraph.Nodes), len(linkGraph.Edges), outputPath)
+
+	return nil
+}
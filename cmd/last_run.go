@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"pkm-sync/internal/config"
+	"pkm-sync/internal/sync"
+	"pkm-sync/pkg/models"
+)
+
+// lastRunState holds the last successful run of each source, which a sync without --since starts from.
+type lastRunState struct {
+	path     string
+	runs     sync.LastRuns
+	overlap  time.Duration
+	excluded map[string]bool
+}
+
+// loadLastRuns reads the last run store, or returns nil when sync.ignore_last_run is set or it cannot be
+// read, in which case sources sync from their configured since.
+func loadLastRuns(cfg *models.Config) *lastRunState {
+	if cfg.Sync.IgnoreLastRun {
+		return nil
+	}
+
+	overlap, err := sync.ParseLastRunOverlap(cfg.Sync.LastRunOverlap)
+	if err != nil {
+		fmt.Printf("Warning: %v, using %s\n", err, sync.DefaultLastRunOverlap)
+
+		overlap = sync.DefaultLastRunOverlap
+	}
+
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		fmt.Printf("Warning: ignoring last sync runs: %v\n", err)
+
+		return nil
+	}
+
+	path := filepath.Join(configDir, sync.LastRunFile)
+
+	runs, err := sync.LoadLastRuns(path)
+	if err != nil {
+		fmt.Printf("Warning: ignoring last sync runs: %v\n", err)
+
+		return nil
+	}
+
+	return &lastRunState{path: path, runs: runs, overlap: overlap, excluded: make(map[string]bool)}
+}
+
+// since returns when a source should sync from after its last successful run, if there was one.
+func (l *lastRunState) since(srcName string) (time.Time, bool) {
+	if l == nil {
+		return time.Time{}, false
+	}
+
+	return l.runs.Since(srcName, l.overlap)
+}
+
+// exclude keeps a source's last run as it was when the sync is recorded, for syncs of a window that does
+// not reach up to now.
+func (l *lastRunState) exclude(srcName string) {
+	if l != nil {
+		l.excluded[srcName] = true
+	}
+}
+
+// record stores started as the last successful run of the synced sources. A failure is reported as a
+// warning since the sync itself succeeded.
+func (l *lastRunState) record(sourceCounts map[string]int, started time.Time) {
+	if l == nil || started.IsZero() {
+		return
+	}
+
+	for srcName := range sourceCounts {
+		if l.excluded[srcName] {
+			continue
+		}
+
+		l.runs[srcName] = started
+	}
+
+	if err := l.runs.Save(l.path); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"pkm-sync/internal/config"
+	"pkm-sync/internal/sync"
+	"pkm-sync/pkg/models"
+)
+
+// offlineQueueState holds the windows and items earlier syncs could not fetch or write while offline, and
+// those of the current sync, when sync.offline_queue is set.
+type offlineQueueState struct {
+	path  string
+	queue *sync.Queue
+	items map[string][]models.ItemInterface // Items collected by this sync, by source
+}
+
+// loadOfflineQueue reads the offline queue, or returns nil when sync.offline_queue is not set, a recording
+// is played back or the queue cannot be read, in which case nothing is queued or flushed.
+func loadOfflineQueue(cfg *models.Config, playback bool) *offlineQueueState {
+	if !cfg.Sync.OfflineQueue || playback {
+		return nil
+	}
+
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		fmt.Printf("Warning: ignoring offline queue: %v\n", err)
+
+		return nil
+	}
+
+	path := filepath.Join(configDir, sync.QueueFile)
+
+	queue, err := sync.LoadQueue(path)
+	if err != nil {
+		fmt.Printf("Warning: ignoring offline queue: %v\n", err)
+
+		return nil
+	}
+
+	return &offlineQueueState{path: path, queue: queue, items: make(map[string][]models.ItemInterface)}
+}
+
+// since widens a source's window to start at its queued window, which the fetch then flushes. Backfills
+// of a bounded window leave the queued window for a sync that reaches up to now.
+func (o *offlineQueueState) since(srcName string, since time.Time, until time.Time) (time.Time, bool) {
+	if o == nil || !until.IsZero() {
+		return since, false
+	}
+
+	queued, ok := o.queue.Range(srcName)
+	if !ok {
+		return since, false
+	}
+
+	fmt.Printf("Syncing %s since %s, which could not be fetched while offline\n", srcName,
+		queued.Since.Format("2006-01-02 15:04"))
+
+	if queued.Since.Before(since) {
+		return queued.Since, true
+	}
+
+	return since, true
+}
+
+// fetchFailed queues a source's window when its fetch failed for lack of connectivity, and reports whether
+// it did.
+func (o *offlineQueueState) fetchFailed(srcName string, since, until time.Time, err error) bool {
+	if o == nil || !sync.IsOffline(err) {
+		return false
+	}
+
+	o.queue.QueueRange(srcName, since, until, err, time.Now())
+	fmt.Printf("Warning: %v, queued for the next sync\n", err)
+
+	return true
+}
+
+// collected remembers a source's items, to be queued if they cannot be written, and drops its queued
+// window when the fetch covered it: it reached up to now and was not cut short by the fetch limit.
+func (o *offlineQueueState) collected(srcName string, items []models.ItemInterface, complete bool) {
+	if o == nil {
+		return
+	}
+
+	o.items[srcName] = items
+
+	if complete {
+		o.queue.ClearRange(srcName)
+	}
+}
+
+// flush adds the items earlier syncs could not write to the ones collected, leaving out those fetched
+// again.
+func (o *offlineQueueState) flush(items []models.ItemInterface) []models.ItemInterface {
+	if o == nil {
+		return items
+	}
+
+	sources, queued := o.queue.TakeItems()
+	for _, srcName := range sources {
+		fmt.Printf("Writing %d queued items from %s\n", len(queued[srcName]), srcName)
+
+		o.items[srcName] = sync.MergeQueuedItems(o.items[srcName], queued[srcName])
+		items = sync.MergeQueuedItems(items, queued[srcName])
+	}
+
+	return items
+}
+
+// purgeQueued drops a purged source from the offline queue, so the next sync does not write its notes
+// again: its queued items created before before, or, when before is zero, all of them and its queued
+// window. It returns how many entries were dropped; dry runs leave the queue as it is.
+func purgeQueued(srcName string, before time.Time, dryRun bool) (int, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return 0, err
+	}
+
+	path := filepath.Join(configDir, sync.QueueFile)
+
+	queue, err := sync.LoadQueue(path)
+	if err != nil {
+		return 0, err
+	}
+
+	dropped := queue.Drop(srcName, before)
+	if dropped == 0 || dryRun {
+		return dropped, nil
+	}
+
+	return dropped, queue.Save(path)
+}
+
+// save stores the queue once the export is done, queueing the collected items when it failed, so they are
+// written by the next sync even if it cannot fetch them. A failure is reported as a warning.
+func (o *offlineQueueState) save(exportErr error) {
+	if o == nil {
+		return
+	}
+
+	if exportErr != nil {
+		for srcName, items := range o.items {
+			o.queue.QueueItems(srcName, items)
+		}
+	}
+
+	if err := o.queue.Save(o.path); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+}
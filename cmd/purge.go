@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"pkm-sync/internal/config"
+	"pkm-sync/internal/search"
+	"pkm-sync/internal/sources/google"
+	"pkm-sync/internal/statestore"
+	"pkm-sync/internal/sync"
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	purgeSourceName string
+	purgeBefore     string
+	purgeTargetName string
+	purgeOutputDir  string
+	purgeDryRun     bool
+)
+
+var purgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Remove the synced notes of a source",
+	Long: `Remove everything synced from one source: its notes, attachments and images no other note
+links to, their entries in the target's indexes and the search index, and with no --before the
+source's sync state. Use --dry-run to list what would be removed first.
+
+Examples:
+  pkm-sync purge --source gmail_personal --before 2023-01-01 --dry-run
+  pkm-sync purge --source gmail_personal --before 2023-01-01
+  pkm-sync purge --source old_calendar`,
+	RunE: runPurgeCommand,
+}
+
+func init() {
+	rootCmd.AddCommand(purgeCmd)
+	purgeCmd.Flags().StringVar(&purgeSourceName, "source", "", "Source whose notes to remove (required)")
+	purgeCmd.Flags().StringVar(&purgeBefore, "before", "", "Only remove items created before (2006-01-02, 90d)")
+	purgeCmd.Flags().StringVar(&purgeTargetName, "target", "", "PKM target (default: sync.default_target)")
+	purgeCmd.Flags().StringVarP(&purgeOutputDir, "output", "o", "", "Output directory (default: sync.default_output_dir)")
+	purgeCmd.Flags().BoolVar(&purgeDryRun, "dry-run", false, "List what would be removed without removing it")
+	_ = purgeCmd.MarkFlagRequired("source")
+}
+
+func runPurgeCommand(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		cfg = config.GetDefaultConfig()
+	}
+
+	if err := statestore.Configure(cfg.App.StateEncryption); err != nil {
+		return err
+	}
+
+	filter, err := purgeFilter(cfg, purgeSourceName, purgeBefore)
+	if err != nil {
+		return err
+	}
+
+	targetName := cfg.Sync.DefaultTarget
+	if purgeTargetName != "" {
+		targetName = purgeTargetName
+	}
+
+	outputDir := getSourceOutputDirectory(cfg.Sync.DefaultOutputDir, cfg.Sources[purgeSourceName])
+	if purgeOutputDir != "" {
+		outputDir = purgeOutputDir
+	}
+
+	target, err := createTargetWithConfig(targetName, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create target '%s': %w", targetName, err)
+	}
+
+	purger, ok := target.(interfaces.Purger)
+	if !ok {
+		return fmt.Errorf("target '%s' does not support purging", targetName)
+	}
+
+	report, err := purger.Purge(outputDir, filter, purgeDryRun)
+	if err != nil {
+		return err
+	}
+
+	var states []string
+	if filter.Before.IsZero() {
+		states, err = purgeSourceState(purgeSourceName, purgeDryRun)
+		if err != nil {
+			return err
+		}
+	}
+
+	queued, err := purgeQueued(purgeSourceName, filter.Before, purgeDryRun)
+	if err != nil {
+		return err
+	}
+
+	verb := "Removed"
+	if purgeDryRun {
+		verb = "Would remove"
+	}
+
+	for _, note := range report.Notes {
+		fmt.Printf("  note: %s\n", note)
+	}
+
+	for _, attachment := range report.Attachments {
+		fmt.Printf("  attachment: %s\n", attachment)
+	}
+
+	for _, state := range states {
+		fmt.Printf("  state: %s\n", state)
+	}
+
+	if queued > 0 {
+		fmt.Printf("  offline queue: %d queued items and windows\n", queued)
+	}
+
+	fmt.Printf("%s %d notes, %d attachments, %d index records and %d state files of '%s'\n", verb,
+		len(report.Notes), len(report.Attachments), report.Records, len(states), purgeSourceName)
+
+	// The search index drops the entries of removed notes when brought up to date
+	if !purgeDryRun && len(report.Notes) > 0 && search.Exists(outputDir) {
+		if _, err := search.Update(outputDir); err != nil {
+			return fmt.Errorf("failed to update search index: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// purgeFilter selects the notes of a configured source created before before ("" for all). Notes only
+// record their source type, so when other sources share it the notes must carry the source tag
+// (sync.source_tags) to be told apart.
+func purgeFilter(cfg *models.Config, srcName, before string) (interfaces.PurgeFilter, error) {
+	sourceConfig, exists := cfg.Sources[srcName]
+	if !exists {
+		return interfaces.PurgeFilter{}, fmt.Errorf("source '%s' not found in config", srcName)
+	}
+
+	filter := interfaces.PurgeFilter{SourceType: sourceConfig.Type}
+
+	if before != "" {
+		beforeTime, err := parseSinceTime(before)
+		if err != nil {
+			return filter, fmt.Errorf("invalid before parameter: %w", err)
+		}
+
+		filter.Before = beforeTime
+	}
+
+	for name, other := range cfg.Sources {
+		if name == srcName || other.Type != sourceConfig.Type {
+			continue
+		}
+
+		if !cfg.Sync.SourceTags {
+			return filter, fmt.Errorf("sources '%s' and '%s' are both of type '%s': their notes can only be told apart "+
+				"by source tags, enable sync.source_tags and re-sync first", srcName, name, sourceConfig.Type)
+		}
+
+		filter.Tag = "source:" + srcName
+	}
+
+	return filter, nil
+}
+
+// purgeSourceState removes the state files a source keeps in the config directory, returning their names.
+func purgeSourceState(srcName string, dryRun bool) ([]string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+
+	for _, name := range []string{sync.BootstrapStateFile(srcName), google.DriveStateFile(srcName),
+		sync.ItemCacheFile(srcName),
+	} {
+		path := filepath.Join(configDir, name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		if !dryRun {
+			if err := os.Remove(path); err != nil {
+				return removed, fmt.Errorf("failed to remove %s: %w", name, err)
+			}
+		}
+
+		removed = append(removed, name)
+	}
+
+	return removed, nil
+}
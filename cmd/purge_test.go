@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestPurgeFilter(t *testing.T) {
+	cfg := &models.Config{
+		Sources: map[string]models.SourceConfig{
+			"gmail_personal":  {Type: "gmail"},
+			"gmail_work":      {Type: "gmail"},
+			"google_calendar": {Type: "google_calendar"},
+		},
+	}
+
+	filter, err := purgeFilter(cfg, "google_calendar", "2023-01-01")
+	if err != nil {
+		t.Fatalf("purgeFilter() error = %v", err)
+	}
+
+	if filter.SourceType != "google_calendar" || filter.Tag != "" ||
+		!filter.Before.Equal(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("purgeFilter() = %+v", filter)
+	}
+
+	// Sources sharing a type need source tags to be told apart
+	if _, err := purgeFilter(cfg, "gmail_personal", ""); err == nil {
+		t.Error("purgeFilter() without source tags accepted an ambiguous source")
+	}
+
+	cfg.Sync.SourceTags = true
+
+	if filter, err := purgeFilter(cfg, "gmail_personal", ""); err != nil || filter.Tag != "source:gmail_personal" {
+		t.Errorf("purgeFilter() = %+v, %v", filter, err)
+	}
+
+	if _, err := purgeFilter(cfg, "missing", ""); err == nil {
+		t.Error("purgeFilter() accepted an unknown source")
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"pkm-sync/internal/config"
+	"pkm-sync/internal/sources/google/auth"
+	"pkm-sync/internal/sources/google/quota"
+	"pkm-sync/pkg/models"
+)
+
+// quotaStateFile is the state store of Google API requests made per account today.
+const quotaStateFile = "google-quota.json"
+
+// googleSourceTypes are the source types whose requests count against auth.google_quota.
+var googleSourceTypes = map[string]bool{
+	"gmail":           true,
+	"google_calendar": true,
+	"google_drive":    true,
+	"google_tasks":    true,
+}
+
+// quotaBudgets meters the Google API requests of a sync against the daily budgets in auth.google_quota.
+type quotaBudgets struct {
+	ledger  *quota.Ledger
+	limits  map[string]int
+	current *quota.Budget // Budget of the source being synced
+}
+
+// loadQuotaBudgets reads today's consumption; without configured budgets requests are not metered.
+func loadQuotaBudgets(cfg *models.Config) *quotaBudgets {
+	if len(cfg.Auth.GoogleQuota) == 0 {
+		return nil
+	}
+
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		fmt.Printf("Warning: Google quota budgets disabled: %v\n", err)
+
+		return nil
+	}
+
+	ledger, err := quota.Load(filepath.Join(configDir, quotaStateFile))
+	if err != nil {
+		fmt.Printf("Warning: Google quota budgets disabled: %v\n", err)
+
+		return nil
+	}
+
+	return &quotaBudgets{ledger: ledger, limits: cfg.Auth.GoogleQuota}
+}
+
+// use meters the clients of a source about to be created against its account's budget, and returns an
+// error wrapping quota.ErrBudgetExhausted when that budget is already used today.
+func (q *quotaBudgets) use(sourceConfig models.SourceConfig) error {
+	if q == nil || !googleSourceTypes[sourceConfig.Type] {
+		auth.UseQuotaBudget(nil)
+
+		return nil
+	}
+
+	account := sourceConfig.Account
+	if account == "" {
+		account = quota.DefaultAccount
+	}
+
+	q.current = q.ledger.Budget(account, q.limits[account])
+	auth.UseQuotaBudget(q.current)
+
+	return q.current.Err()
+}
+
+// exhausted reports whether a source failed because its budget ran out while it was fetching, printing
+// why the source stops for today.
+func (q *quotaBudgets) exhausted(srcName string, err error) bool {
+	if q == nil || !errors.Is(err, quota.ErrBudgetExhausted) {
+		return false
+	}
+
+	fmt.Printf("Stopping source '%s' for today: %v\n", srcName, q.current.Err())
+
+	return true
+}
+
+// save records the requests made so far in the state store.
+func (q *quotaBudgets) save() {
+	if q == nil {
+		return
+	}
+
+	if err := q.ledger.Save(); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+}
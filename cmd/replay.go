@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"pkm-sync/internal/config"
+	"pkm-sync/internal/statestore"
+	"pkm-sync/internal/sync"
+	"pkm-sync/pkg/models"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	replaySourceNames  []string
+	replayTargetName   string
+	replayOutputDir    string
+	replaySince        string
+	replayUntil        string
+	replayDryRun       bool
+	replayOutputFormat string
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Regenerate the output from cached items without contacting the sources",
+	Long: `Export the items kept in the item cache (sync.item_cache) through the transformer pipeline to a
+PKM target, exactly like a sync but without fetching anything, so templates, transformers and target
+settings can be tried offline. Items are replayed as last fetched; the last sync runs and the sync
+stats are left alone. Use --since and --until to replay only part of the cache.
+
+Examples:
+  pkm-sync replay --dry-run
+  pkm-sync replay --source gmail_work --output ./test-vault
+  pkm-sync replay --target logseq --since 30d --dry-run --format json`,
+	RunE: runReplayCommand,
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+	replayCmd.Flags().StringSliceVar(&replaySourceNames, "source", nil, "Sources to replay (default: enabled sources)")
+	replayCmd.Flags().StringVar(&replayTargetName, "target", "", "PKM target (obsidian, logseq, ical)")
+	replayCmd.Flags().StringVarP(&replayOutputDir, "output", "o", "", "Output directory")
+	replayCmd.Flags().StringVar(&replaySince, "since", "", "Replay items created since (default: all cached items)")
+	replayCmd.Flags().StringVar(&replayUntil, "until", "", "Replay items created before (default: all cached items)")
+	replayCmd.Flags().BoolVar(&replayDryRun, "dry-run", false, "Show what would be written without making changes")
+	replayCmd.Flags().StringVar(&replayOutputFormat, "format", "summary", "Output format for dry-run (summary, json, diff)")
+}
+
+func runReplayCommand(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		cfg = config.GetDefaultConfig()
+	}
+
+	if err := statestore.Configure(cfg.App.StateEncryption); err != nil {
+		return err
+	}
+
+	var since, until time.Time
+
+	if replaySince != "" {
+		if since, err = parseSinceTime(replaySince); err != nil {
+			return fmt.Errorf("invalid since parameter: %w", err)
+		}
+	}
+
+	if replayUntil != "" {
+		if until, err = parseSinceTime(replayUntil); err != nil {
+			return fmt.Errorf("invalid until parameter: %w", err)
+		}
+	}
+
+	sourcesToReplay := replaySourceNames
+	if len(sourcesToReplay) == 0 {
+		sourcesToReplay = getEnabledSources(cfg)
+	}
+
+	if len(sourcesToReplay) == 0 {
+		return fmt.Errorf("no sources to replay: enable a source or pass --source")
+	}
+
+	finalTargetName := cfg.Sync.DefaultTarget
+	if replayTargetName != "" {
+		finalTargetName = replayTargetName
+	}
+
+	finalOutputDir := cfg.Sync.DefaultOutputDir
+	if replayOutputDir != "" {
+		finalOutputDir = replayOutputDir
+	}
+
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Replaying cached items of [%s] to %s (output: %s)\n",
+		strings.Join(sourcesToReplay, ", "), finalTargetName, finalOutputDir)
+
+	target, err := createTargetWithConfig(finalTargetName, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create target: %w", err)
+	}
+
+	var allItems []models.ItemInterface
+
+	sourceCounts := make(map[string]int)
+	settings := newItemSettings()
+
+	for _, srcName := range sourcesToReplay {
+		sourceConfig, exists := cfg.Sources[srcName]
+		if !exists {
+			fmt.Printf("Warning: source '%s' not configured, skipping\n", srcName)
+
+			continue
+		}
+
+		items, err := sync.LoadCachedItems(filepath.Join(configDir, sync.ItemCacheFile(srcName)))
+		if err != nil {
+			fmt.Printf("Warning: %v for source '%s', skipping\n", err, srcName)
+
+			continue
+		}
+
+		if len(items) == 0 {
+			fmt.Printf("No cached items for source '%s', skipping (enable sync.item_cache and sync it first)\n",
+				srcName)
+
+			continue
+		}
+
+		items = prepareSourceItems(cfg, srcName, sourceConfig, cachedInWindow(items, since, until), settings)
+
+		fmt.Printf("Replaying %d cached items from %s\n", len(items), srcName)
+
+		allItems = append(allItems, items...)
+		sourceCounts[srcName] = len(items)
+	}
+
+	fmt.Printf("Total items replayed: %d\n", len(allItems))
+
+	return exportSyncedItems(syncRun{
+		cfg:          cfg,
+		target:       target,
+		targetName:   finalTargetName,
+		outputDir:    finalOutputDir,
+		sources:      sourcesToReplay,
+		items:        allItems,
+		sourceCounts: sourceCounts,
+		settings:     settings,
+		dryRun:       replayDryRun,
+		format:       replayOutputFormat,
+		hooks:        sync.HookRun{Sources: sourcesToReplay, Target: finalTargetName, OutputDir: finalOutputDir},
+		replay:       true,
+	})
+}
+
+// cacheSourceItems adds the items fetched from a source to its item cache when sync.item_cache is set.
+// A failure is reported as a warning since the items were fetched.
+func cacheSourceItems(cfg *models.Config, srcName string, items []models.ItemInterface) {
+	if !cfg.Sync.ItemCache || len(items) == 0 {
+		return
+	}
+
+	configDir, err := config.GetConfigDir()
+	if err == nil {
+		err = sync.CacheItems(filepath.Join(configDir, sync.ItemCacheFile(srcName)), items)
+	}
+
+	if err != nil {
+		fmt.Printf("Warning: failed to cache items of source '%s': %v\n", srcName, err)
+	}
+}
+
+// cachedInWindow keeps the cached items created from since up to until; zero bounds are open.
+func cachedInWindow(items []models.ItemInterface, since, until time.Time) []models.ItemInterface {
+	if !until.IsZero() {
+		items = sync.FilterBefore(items, until)
+	}
+
+	if since.IsZero() {
+		return items
+	}
+
+	filtered := make([]models.ItemInterface, 0, len(items))
+
+	for _, item := range items {
+		if !item.GetCreatedAt().Before(since) {
+			filtered = append(filtered, item)
+		}
+	}
+
+	return filtered
+}
@@ -1,11 +1,13 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 
 	"pkm-sync/internal/config"
+	"pkm-sync/internal/sync"
 
 	"github.com/spf13/cobra"
 )
@@ -23,7 +25,10 @@ var rootCmd = &cobra.Command{
 with Personal Knowledge Management systems (Obsidian, Logseq, etc.).
 
 Commands:
+  sync      Sync configured sources of any type to PKM systems
   gmail     Sync Gmail emails to PKM systems
+  ingest    Sync items from JSON files, stdin or HTTP posts
+  schema    Print the JSON Schema of the canonical Item format
   drive     Export Google Drive documents to markdown
   calendar  List and sync Google Calendar events
   setup     Verify authentication configuration
@@ -59,9 +64,19 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&debugMode, "debug", "d", false, "Enable debug logging")
 }
 
+// exitPartialFailure is the exit code of a sync where some sources failed while others synced, so
+// schedulers can tell it apart from a run that synced nothing.
+const exitPartialFailure = 2
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
+
+		var partial *sync.PartialFailureError
+		if errors.As(err, &partial) {
+			os.Exit(exitPartialFailure)
+		}
+
 		os.Exit(1)
 	}
 }
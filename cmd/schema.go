@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"pkm-sync/pkg/models"
+
+	"github.com/spf13/cobra"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the canonical Item format",
+	Long: `Print the JSON Schema of the canonical Item format that --dry-run --format json prints and
+ingest sources read, so scripts and other tools can validate the items they exchange with pkm-sync.
+
+Item documents carry a schema_version. Older versions are upgraded when items are read; items from a
+newer version than this release supports are rejected.
+
+Examples:
+  pkm-sync schema > item.schema.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := os.Stdout.Write(models.ItemSchema); err != nil {
+			return fmt.Errorf("failed to write schema: %w", err)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"pkm-sync/internal/config"
+	"pkm-sync/internal/search"
+	"pkm-sync/internal/statestore"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchOutputDir string
+	searchLimit     int
+	searchFormat    string
+	searchReindex   bool
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search [query]",
+	Short: "Search synced notes",
+	Long: `Search the notes in the output directory using a local full-text index.
+
+Every word of the query must appear in a note; end a word with * to match words it starts.
+The index is updated during sync when sync.search_index is enabled, and brought up to date
+before each search otherwise.
+
+Examples:
+  pkm-sync search "invoice q3"
+  pkm-sync search "proj*" --limit 5
+  pkm-sync search budget --output ./vault --format json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSearchCommand,
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().StringVarP(&searchOutputDir, "output", "o", "", "Output directory to search (default: sync.default_output_dir)")
+	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 20, "Maximum number of results (0 for all)")
+	searchCmd.Flags().StringVar(&searchFormat, "format", "summary", "Output format (summary, json)")
+	searchCmd.Flags().BoolVar(&searchReindex, "reindex", false, "Rebuild the index from scratch before searching")
+}
+
+func runSearchCommand(cmd *cobra.Command, args []string) error {
+	if searchFormat != "summary" && searchFormat != "json" {
+		return fmt.Errorf("unsupported format '%s': supported formats are 'summary', 'json'", searchFormat)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		cfg = config.GetDefaultConfig()
+	}
+
+	if err := statestore.Configure(cfg.App.StateEncryption); err != nil {
+		return err
+	}
+
+	outputDir := searchOutputDir
+	if outputDir == "" {
+		outputDir = cfg.Sync.DefaultOutputDir
+	}
+
+	if _, err := os.Stat(outputDir); err != nil {
+		return fmt.Errorf("output directory %s not found: %w", outputDir, err)
+	}
+
+	index, err := search.Open(outputDir)
+	if err != nil {
+		return err
+	}
+
+	if searchReindex {
+		index.Documents = make(map[string]*search.Document)
+	}
+
+	// Catch notes written since the last sync, or everything when no index exists yet
+	stats, err := index.Update()
+	if err != nil {
+		return err
+	}
+
+	if stats.Changed() || searchReindex {
+		if err := index.Save(); err != nil {
+			return err
+		}
+	}
+
+	results, err := index.Search(strings.Join(args, " "), searchLimit)
+	if err != nil {
+		return err
+	}
+
+	if searchFormat == "json" {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode results: %w", err)
+		}
+
+		fmt.Println(string(data))
+
+		return nil
+	}
+
+	if len(results) == 0 {
+		fmt.Printf("No notes matching %q in %s\n", strings.Join(args, " "), outputDir)
+
+		return nil
+	}
+
+	for _, result := range results {
+		fmt.Println(result.Path)
+
+		if result.Snippet != "" {
+			fmt.Printf("    %s\n", result.Snippet)
+		}
+	}
+
+	if len(results) == 1 {
+		fmt.Println("\n1 matching note")
+	} else {
+		fmt.Printf("\n%d matching notes\n", len(results))
+	}
+
+	return nil
+}
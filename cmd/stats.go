@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"pkm-sync/internal/config"
+	"pkm-sync/internal/statestore"
+	"pkm-sync/internal/sync"
+	"pkm-sync/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// statsTopCount is how many senders the stats note lists.
+const statsTopCount = 10
+
+var (
+	statsFormat string
+	statsTop    int
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show sync statistics",
+	Long: `Show statistics recorded by past syncs: items per source, top senders, busiest meeting days
+and attachment volume, both for the last run and across all runs.
+
+Set sync.stats_note to also keep these statistics as a note in the vault.
+
+Examples:
+  pkm-sync stats
+  pkm-sync stats --top 5
+  pkm-sync stats --format json`,
+	RunE: runStatsCommand,
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.Flags().StringVar(&statsFormat, "format", "summary", "Output format (summary, json)")
+	statsCmd.Flags().IntVar(&statsTop, "top", statsTopCount, "Number of senders to list (0 for all)")
+}
+
+func runStatsCommand(cmd *cobra.Command, args []string) error {
+	if statsFormat != "summary" && statsFormat != "json" {
+		return fmt.Errorf("unsupported format '%s': supported formats are 'summary', 'json'", statsFormat)
+	}
+
+	if cfg, err := config.LoadConfig(); err == nil {
+		if err := statestore.Configure(cfg.App.StateEncryption); err != nil {
+			return err
+		}
+	}
+
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to get config directory: %w", err)
+	}
+
+	stats, err := sync.LoadStats(filepath.Join(configDir, sync.StatsFile))
+	if err != nil {
+		return err
+	}
+
+	if statsFormat == "json" {
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode stats: %w", err)
+		}
+
+		fmt.Println(string(data))
+
+		return nil
+	}
+
+	if stats.Runs == 0 {
+		fmt.Println("No syncs recorded yet. Run 'pkm-sync gmail' to start collecting statistics.")
+
+		return nil
+	}
+
+	fmt.Printf("Sync statistics: %d runs from %s to %s\n\n", stats.Runs,
+		stats.FirstSync.Format("2006-01-02"), stats.LastSync.Format("2006-01-02 15:04"))
+
+	if last, ok := stats.LastRun(); ok {
+		fmt.Println("Last run:")
+		printRunStats(last, statsTop)
+		fmt.Println()
+	}
+
+	fmt.Println("All runs:")
+	printRunStats(stats.Totals, statsTop)
+
+	return nil
+}
+
+func printRunStats(run sync.RunStats, top int) {
+	fmt.Printf("  Items:       %d\n", run.Items)
+	fmt.Printf("  Attachments: %d (%s)\n", run.Attachments, utils.FormatSize(run.AttachmentBytes))
+
+	printCounts("Items per source", sync.TopCounts(run.Sources, 0))
+	printCounts("Top senders", sync.TopCounts(run.Senders, top))
+	printCounts("Busiest meeting days", sync.TopCounts(run.MeetingDays, 0))
+}
+
+func printCounts(heading string, counts []sync.Count) {
+	if len(counts) == 0 {
+		return
+	}
+
+	fmt.Printf("  %s:\n", heading)
+
+	for _, count := range counts {
+		fmt.Printf("    %-40s %d\n", count.Name, count.Count)
+	}
+}
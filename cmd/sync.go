@@ -2,18 +2,31 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"time"
 
 	"pkm-sync/internal/config"
+	"pkm-sync/internal/search"
+	"pkm-sync/internal/sources/applenotes"
+	"pkm-sync/internal/sources/bookmarks"
 	"pkm-sync/internal/sources/google"
+	"pkm-sync/internal/sources/google/tasks"
+	"pkm-sync/internal/sources/ingest"
+	"pkm-sync/internal/sources/microsoft/outlook"
+	"pkm-sync/internal/sources/microsoft/teams"
+	"pkm-sync/internal/sources/mock"
+	"pkm-sync/internal/statestore"
+	"pkm-sync/internal/sync"
+	"pkm-sync/internal/targets/ical"
 	"pkm-sync/internal/targets/logseq"
 	"pkm-sync/internal/targets/obsidian"
 	"pkm-sync/internal/transform"
+	"pkm-sync/internal/utils"
 	"pkm-sync/pkg/interfaces"
 	"pkm-sync/pkg/models"
 
@@ -28,6 +41,10 @@ var (
 	gmailDryRun       bool
 	gmailLimit        int
 	gmailOutputFormat string
+	gmailUntil        string
+	gmailBootstrap    bool
+	gmailChunk        string
+	gmailNoProgress   bool
 )
 
 var gmailCmd = &cobra.Command{
@@ -38,22 +55,28 @@ var gmailCmd = &cobra.Command{
 Examples:
   pkm-sync gmail --source gmail_work --target obsidian --output ./vault
   pkm-sync gmail --source gmail_personal --target logseq --output ./graph --since 7d
-  pkm-sync gmail --source gmail_work --target obsidian --dry-run`,
+  pkm-sync gmail --source gmail_work --since 2022-01-01 --until 2023-01-01
+  pkm-sync gmail --source gmail_work --target obsidian --dry-run
+  pkm-sync gmail --source gmail_personal --bootstrap --since 2015-01-01 --chunk month`,
 	RunE: runGmailCommand,
 }
 
 func init() {
 	rootCmd.AddCommand(gmailCmd)
 	gmailCmd.Flags().StringVar(&gmailSourceName, "source", "", "Gmail source (gmail_work, gmail_personal, etc.)")
-	gmailCmd.Flags().StringVar(&gmailTargetName, "target", "", "PKM target (obsidian, logseq)")
+	gmailCmd.Flags().StringVar(&gmailTargetName, "target", "", "PKM target (obsidian, logseq, ical)")
 	gmailCmd.Flags().StringVarP(&gmailOutputDir, "output", "o", "", "Output directory")
 	gmailCmd.Flags().StringVar(&gmailSince, "since", "", "Sync emails since (7d, 2006-01-02, today)")
+	gmailCmd.Flags().StringVar(&gmailUntil, "until", "", "Sync emails before (2006-01-02, 30d); default: now")
 	gmailCmd.Flags().BoolVar(&gmailDryRun, "dry-run", false, "Show what would be synced without making changes")
 	gmailCmd.Flags().IntVar(&gmailLimit, "limit", 1000, "Maximum number of emails to fetch (default: 1000)")
-	gmailCmd.Flags().StringVar(&gmailOutputFormat, "format", "summary", "Output format for dry-run (summary, json)")
+	gmailCmd.Flags().StringVar(&gmailOutputFormat, "format", "summary", "Output format for dry-run (summary, json, diff)")
+	gmailCmd.Flags().BoolVar(&gmailNoProgress, "no-progress", false, "Don't show progress bars or progress log lines")
+	gmailCmd.Flags().BoolVar(&gmailBootstrap, "bootstrap", false, "Import the history back to --since in resumable chunks")
+	gmailCmd.Flags().StringVar(&gmailChunk, "chunk", "month", "Bootstrap chunk size: 'month' or a number of messages")
 }
 
-func runGmailCommand(cmd *cobra.Command, args []string) error {
+func runGmailCommand(cmd *cobra.Command, args []string) (err error) {
 	// Load configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
@@ -61,6 +84,10 @@ func runGmailCommand(cmd *cobra.Command, args []string) error {
 		cfg = config.GetDefaultConfig()
 	}
 
+	if err := statestore.Configure(cfg.App.StateEncryption); err != nil {
+		return err
+	}
+
 	// Determine which Gmail sources to sync
 	var sourcesToSync []string
 	if gmailSourceName != "" {
@@ -97,9 +124,48 @@ func runGmailCommand(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid since parameter: %w", err)
 	}
 
+	chunk, err := sync.ParseBootstrapChunk(gmailChunk)
+	if err != nil {
+		return err
+	}
+
+	if gmailBootstrap && gmailDryRun {
+		return fmt.Errorf("--bootstrap cannot be combined with --dry-run")
+	}
+
+	if gmailUntil != "" {
+		if gmailBootstrap {
+			return fmt.Errorf("--bootstrap cannot be combined with --until")
+		}
+
+		if _, err := parseSinceTime(gmailUntil); err != nil {
+			return fmt.Errorf("invalid until parameter: %w", err)
+		}
+	}
+
 	fmt.Printf("Syncing Gmail from sources [%s] to %s (output: %s, since: %s)\n",
 		strings.Join(sourcesToSync, ", "), finalTargetName, finalOutputDir, finalSince)
 
+	// Hooks only run for real syncs; dry runs leave the vault and its tooling alone
+	run := sync.HookRun{Sources: sourcesToSync, Target: finalTargetName, OutputDir: finalOutputDir}
+
+	if !gmailDryRun {
+		defer func() {
+			// Failed sources already ran their own on_error hooks
+			var partial *sync.PartialFailureError
+			if err != nil && !errors.As(err, &partial) {
+				run.Err = err
+				if hookErr := sync.RunHook(cfg.Sync.Hooks, sync.HookOnError, run); hookErr != nil {
+					fmt.Printf("Warning: %v\n", hookErr)
+				}
+			}
+		}()
+
+		if err := sync.RunHook(cfg.Sync.Hooks, sync.HookPreSync, run); err != nil {
+			return err
+		}
+	}
+
 	// Create target with config
 	target, err := createTargetWithConfig(finalTargetName, cfg)
 	if err != nil {
@@ -109,18 +175,37 @@ func runGmailCommand(cmd *cobra.Command, args []string) error {
 	// Collect all items from all Gmail sources for unified processing
 	var allItems []models.ItemInterface
 
+	sourceCounts := make(map[string]int)
+	settings := newItemSettings()
+	changes := readVaultChanges(target, finalOutputDir)
+	budgets := loadQuotaBudgets(cfg)
+	lastRuns := loadLastRuns(cfg)
+	started := time.Now()
+
+	// JSON dry-run output must stay parseable
+	var progress *sync.Progress
+	if !gmailNoProgress && !(gmailDryRun && gmailOutputFormat != "summary") {
+		progress = sync.NewProgress(os.Stdout)
+	}
+
+	budget := newErrorBudget(cfg, finalOutputDir, gmailDryRun)
+
+	var report sync.RunReport
+
 	// Process each Gmail source independently to support per-source customization
 	for _, srcName := range sourcesToSync {
 		// Get source-specific config
 		sourceConfig, exists := cfg.Sources[srcName]
 		if !exists {
 			fmt.Printf("Warning: Gmail source '%s' not configured, skipping\n", srcName)
+			report.Failed(srcName, fmt.Errorf("not configured"))
 
 			continue
 		}
 
 		if !sourceConfig.Enabled {
 			fmt.Printf("Gmail source '%s' is disabled, skipping\n", srcName)
+			report.Skipped(srcName, "disabled")
 
 			continue
 		}
@@ -128,18 +213,45 @@ func runGmailCommand(cmd *cobra.Command, args []string) error {
 		// Verify this is a Gmail source
 		if sourceConfig.Type != "gmail" {
 			fmt.Printf("Warning: source '%s' is not a Gmail source (type: %s), skipping\n", srcName, sourceConfig.Type)
+			report.Skipped(srcName, "not a Gmail source")
 
 			continue
 		}
 
+		if err := budgets.use(sourceConfig); err != nil {
+			fmt.Printf("Skipping Gmail source '%s': %v\n", srcName, err)
+			report.Skipped(srcName, err.Error())
+
+			continue
+		}
+
+		sourceRun := sync.HookRun{Sources: []string{srcName}, Target: finalTargetName, OutputDir: finalOutputDir}
+
+		if !gmailDryRun {
+			if err := sync.RunHook(sourceConfig.Hooks, sync.HookPreSync, sourceRun); err != nil {
+				fmt.Printf("Warning: %v for Gmail source '%s', skipping\n", err, srcName)
+				runSourceErrorHook(sourceConfig.Hooks, sourceRun, err)
+				report.Failed(srcName, err)
+
+				continue
+			}
+		}
+
 		// Create source with config
 		source, err := createSourceWithConfig(srcName, sourceConfig, nil)
 		if err != nil {
 			fmt.Printf("Warning: failed to create Gmail source '%s': %v, skipping\n", srcName, err)
+			report.Failed(srcName, err)
+
+			if !gmailDryRun {
+				runSourceErrorHook(sourceConfig.Hooks, sourceRun, err)
+			}
 
 			continue
 		}
 
+		setRawErrorHandler(source, srcName, budget)
+
 		// Use source-specific since time if configured, but CLI flag takes precedence
 		sourceSince := finalSince
 		if sourceConfig.Since != "" && gmailSince == "" {
@@ -154,6 +266,51 @@ func runGmailCommand(cmd *cobra.Command, args []string) error {
 			sourceSinceTime = sinceTime
 		}
 
+		sourceUntilTime, err := sourceUntilTime(srcName, sourceConfig, gmailUntil)
+		if err != nil {
+			fmt.Printf("Warning: %v, skipping\n", err)
+			report.Failed(srcName, err)
+
+			continue
+		}
+
+		if !sourceUntilTime.IsZero() && !sourceUntilTime.After(sourceSinceTime) {
+			fmt.Printf("Warning: until of Gmail source '%s' is not later than its since, skipping\n", srcName)
+			report.Failed(srcName, fmt.Errorf("until is not later than since"))
+
+			continue
+		}
+
+		if !sourceUntilTime.IsZero() {
+			// A backfill of a past window neither starts from nor counts as the last run
+			lastRuns.exclude(srcName)
+		} else if last, ok := lastRuns.since(srcName); ok && gmailSince == "" && !gmailBootstrap {
+			fmt.Printf("Syncing %s since its last successful run (%s)\n", srcName, last.Format("2006-01-02 15:04"))
+
+			sourceSinceTime = last
+		}
+
+		if gmailBootstrap {
+			bootstrapRun := syncRun{cfg: cfg, target: target, targetName: finalTargetName, outputDir: finalOutputDir,
+				sources: []string{srcName}, hooks: run, budget: budget}
+
+			if err := bootstrapSource(bootstrapRun, srcName, sourceConfig, source, sourceSinceTime, chunk,
+				budgets); err != nil {
+				if budgets.exhausted(srcName, err) {
+					report.Skipped(srcName, "google_quota budget used up for today")
+				} else {
+					fmt.Printf("Warning: bootstrap of Gmail source '%s' stopped: %v\n", srcName, err)
+					report.Failed(srcName, err)
+				}
+
+				runSourceErrorHook(sourceConfig.Hooks, sourceRun, err)
+			} else {
+				report.Succeeded(srcName, 0)
+			}
+
+			continue
+		}
+
 		// Use source-specific max results if configured, otherwise default to 1000
 		maxResults := 1000 // default value
 
@@ -171,81 +328,492 @@ func runGmailCommand(cmd *cobra.Command, args []string) error {
 		// Fetch items from this Gmail source
 		fmt.Printf("Fetching emails from %s...\n", srcName)
 
-		items, err := source.Fetch(sourceSinceTime, maxResults)
+		if reporter, ok := source.(interfaces.ProgressReporter); ok && progress != nil {
+			reporter.SetProgress(func(done, total int) { progress.Fetched(srcName, done, total) })
+		}
+
+		items, err := sync.FetchWindow(source, sourceSinceTime, sourceUntilTime, maxResults)
+		budgets.save()
+
 		if err != nil {
-			fmt.Printf("Warning: failed to fetch from Gmail source '%s': %v, skipping\n", srcName, err)
+			progress.Interrupt()
+
+			if budgets.exhausted(srcName, err) {
+				report.Skipped(srcName, "google_quota budget used up for today")
+			} else {
+				fmt.Printf("Warning: failed to fetch from Gmail source '%s': %v, skipping\n", srcName, err)
+				report.Failed(srcName, err)
+			}
+
+			if !gmailDryRun {
+				runSourceErrorHook(sourceConfig.Hooks, sourceRun, err)
+			}
 
 			continue
 		}
 
-		// Add source tags if enabled
-		if cfg.Sync.SourceTags {
-			for _, item := range items {
-				currentTags := item.GetTags()
-				newTags := append(currentTags, "source:"+srcName)
-				item.SetTags(newTags)
-			}
+		changes.writeBack(source, items, gmailDryRun)
+
+		if !gmailDryRun {
+			cacheSourceItems(cfg, srcName, items)
 		}
 
+		fetched := len(items)
+		items = prepareSourceItems(cfg, srcName, sourceConfig, items, settings)
+		progress.Converted(srcName, fetched, len(items))
+
 		fmt.Printf("Found %d emails from %s\n", len(items), srcName)
+		report.Succeeded(srcName, len(items))
 
 		// Add items to the collection
 		allItems = append(allItems, items...)
+		sourceCounts[srcName] = len(items)
+	}
+
+	if gmailBootstrap {
+		return reportSources(cmd, &report, false)
 	}
 
 	fmt.Printf("Total emails collected: %d\n", len(allItems))
 
-	// Initialize and apply transformer pipeline if configured
-	if cfg.Transformers.Enabled {
-		pipeline := transform.NewPipeline()
+	err = exportSyncedItems(syncRun{
+		cfg:          cfg,
+		target:       target,
+		targetName:   finalTargetName,
+		outputDir:    finalOutputDir,
+		sources:      sourcesToSync,
+		items:        allItems,
+		sourceCounts: sourceCounts,
+		settings:     settings,
+		dryRun:       gmailDryRun,
+		format:       gmailOutputFormat,
+		hooks:        run,
+		lastRuns:     lastRuns,
+		started:      started,
+		progress:     progress,
+		budget:       budget,
+	})
+	if err != nil {
+		return err
+	}
 
-		// Register all available transformers
-		for _, t := range transform.GetAllExampleTransformers() {
-			if err := pipeline.AddTransformer(t); err != nil {
-				return fmt.Errorf("failed to add transformer %s: %w", t.Name(), err)
-			}
+	return reportSources(cmd, &report, gmailDryRun && gmailOutputFormat != "summary")
+}
+
+// itemSettings holds the source settings that apply to individual items after their sources are merged,
+// keyed by item ID.
+type itemSettings struct {
+	signatureThresholds map[string]int                   // The source's signature_threshold
+	filenames           map[string]models.FilenameConfig // The source's filenames settings
+	sources             map[string]string                // The source that fetched the item
+}
+
+// filterSourceContacts applies a source's allow_contacts and deny_contacts, with the contact groups they
+// name resolved from the contacts block.
+func filterSourceContacts(cfg *models.Config, sourceConfig models.SourceConfig,
+	items []models.ItemInterface,
+) ([]models.ItemInterface, error) {
+	allow, err := sync.ResolveContacts(cfg.Contacts, sourceConfig.AllowContacts)
+	if err != nil {
+		return nil, err
+	}
+
+	deny, err := sync.ResolveContacts(cfg.Contacts, sourceConfig.DenyContacts)
+	if err != nil {
+		return nil, err
+	}
+
+	return sync.FilterContacts(items, allow, deny), nil
+}
+
+func newItemSettings() itemSettings {
+	return itemSettings{
+		signatureThresholds: make(map[string]int),
+		filenames:           make(map[string]models.FilenameConfig),
+		sources:             make(map[string]string),
+	}
+}
+
+// prepareSourceItems applies a source's settings to its fetched items: keyword and contact filters,
+// timezone, source tag, and the signature_threshold and filenames settings recorded per item. It returns
+// the items kept.
+func prepareSourceItems(cfg *models.Config, srcName string, sourceConfig models.SourceConfig,
+	items []models.ItemInterface, settings itemSettings,
+) []models.ItemInterface {
+	if kept := sync.FilterKeywords(items, sourceConfig.IncludeKeywords, sourceConfig.ExcludeKeywords); len(kept) < len(items) {
+		fmt.Printf("Filtered out %d items from %s by keywords\n", len(items)-len(kept), srcName)
+
+		items = kept
+	}
+
+	if kept, err := filterSourceContacts(cfg, sourceConfig, items); err != nil {
+		fmt.Printf("Warning: %v for source '%s', not filtering by contacts\n", err, srcName)
+	} else if len(kept) < len(items) {
+		fmt.Printf("Filtered out %d items from %s by contacts\n", len(items)-len(kept), srcName)
+
+		items = kept
+	}
+
+	// Convert dates to the source's timezone if configured
+	sourceLocation, err := utils.LoadTimezone(sourceConfig.Timezone)
+	if err != nil {
+		fmt.Printf("Warning: %v for source '%s', keeping fetched timezones\n", err, srcName)
+	}
+
+	utils.LocalizeItems(items, sourceLocation)
+
+	// Add source tags if enabled
+	if cfg.Sync.SourceTags {
+		for _, item := range items {
+			currentTags := item.GetTags()
+			newTags := append(currentTags, "source:"+srcName)
+			item.SetTags(newTags)
 		}
+	}
 
-		// Configure the pipeline from the config file
-		if err := pipeline.Configure(cfg.Transformers); err != nil {
-			return fmt.Errorf("failed to configure transformer pipeline: %w", err)
+	if sourceConfig.SignatureThreshold > 0 {
+		for _, item := range items {
+			settings.signatureThresholds[item.GetID()] = sourceConfig.SignatureThreshold
+
+			if thread, ok := models.AsThread(item); ok {
+				for _, message := range thread.GetMessages() {
+					settings.signatureThresholds[message.GetID()] = sourceConfig.SignatureThreshold
+				}
+			}
 		}
+	}
 
-		// Transform items
-		transformedItems, err := pipeline.Transform(allItems)
-		if err != nil {
-			return fmt.Errorf("failed to transform items: %w", err)
+	if sourceConfig.Filenames != (models.FilenameConfig{}) {
+		for _, item := range items {
+			settings.filenames[item.GetID()] = sourceConfig.Filenames
 		}
+	}
 
-		fmt.Printf("Transformed to %d items\n", len(transformedItems))
-		allItems = transformedItems
+	for _, item := range items {
+		settings.sources[item.GetID()] = srcName
 	}
 
-	if gmailDryRun {
-		// Generate preview of what would be done
-		previews, err := target.Preview(allItems, finalOutputDir)
+	return items
+}
+
+// syncRun is what a sync command collected from its sources, ready to be transformed and exported.
+type syncRun struct {
+	cfg          *models.Config
+	target       interfaces.Target
+	targetName   string
+	outputDir    string
+	sources      []string
+	items        []models.ItemInterface
+	sourceCounts map[string]int
+	settings     itemSettings
+	dryRun       bool
+	format       string // Dry-run output format
+	hooks        sync.HookRun
+
+	// Recorded as the sources' last successful run once the export succeeds; nil or zero records nothing
+	lastRuns *lastRunState
+	started  time.Time
+
+	// Incremental state of the fetching sources, by source, saved once every item is exported
+	sourceStates map[string]interfaces.SyncStateSaver
+
+	replay bool // Items came from the item cache or a recording, so the run is not counted in the sync stats
+
+	progress *sync.Progress // Shows the notes written when the target reports them; nil shows nothing
+
+	// Applies item_errors to the items that fail to export, counting those that failed to convert; nil
+	// creates one for the export
+	budget *sync.ErrorBudget
+}
+
+// exportSyncedItems deduplicates and transforms the collected items, then previews or exports them and
+// runs the post-sync steps: stats, search index, git commit and post_sync hooks.
+func exportSyncedItems(r syncRun) error {
+	cfg, target, allItems := r.cfg, r.target, r.items
+	run := r.hooks
+
+	var err error
+
+	// Drop duplicates collected from several sources
+	allItems, err = sync.DeduplicateItems(allItems, cfg.Sync.DeduplicateBy, cfg.Sync.DeduplicateWhere)
+	if err != nil {
+		return err
+	}
+
+	// Conversations synced from several accounts become one thread
+	if cfg.Sync.MergeThreads {
+		allItems = sync.MergeThreads(allItems)
+	}
+
+	allItems, err = transformItems(cfg, allItems, r.settings)
+	if err != nil {
+		return err
+	}
+
+	// Items are named with the filenames settings of the source that fetched them
+	if namer, ok := target.(interfaces.ItemFilenameTarget); ok && len(r.settings.filenames) > 0 {
+		if err := namer.SetItemFilenames(r.settings.filenames); err != nil {
+			return fmt.Errorf("invalid source filenames settings: %w", err)
+		}
+	}
+
+	if r.dryRun {
+		// Work out what would be done
+		plan, err := target.Plan(allItems, r.outputDir)
 		if err != nil {
 			return fmt.Errorf("failed to generate preview: %w", err)
 		}
 
-		switch gmailOutputFormat {
+		switch r.format {
 		case "json":
-			return outputDryRunJSON(allItems, previews, finalTargetName, finalOutputDir, sourcesToSync)
+			return outputDryRunJSON(allItems, plan, r.targetName, r.sources)
 		case "summary":
-			return outputDryRunSummary(allItems, previews, finalTargetName, finalOutputDir, sourcesToSync)
+			return outputDryRunSummary(allItems, plan, r.targetName)
+		case "diff":
+			return outputDryRunDiff(plan)
 		default:
-			return fmt.Errorf("unknown format '%s': supported formats are 'summary' and 'json'", gmailOutputFormat)
+			return fmt.Errorf("unknown format '%s': supported formats are 'summary', 'json' and 'diff'", r.format)
 		}
 	}
 
+	if reporter, ok := target.(interfaces.ProgressReporter); ok && r.progress != nil {
+		reporter.SetProgress(r.progress.Written)
+	}
+
+	budget := r.budget
+	if budget == nil {
+		budget = newErrorBudget(cfg, r.outputDir, false)
+	}
+
+	if handler, ok := target.(interfaces.ItemErrorHandler); ok {
+		handler.SetItemErrorHandler(budget.Handle)
+	}
+
 	// Export all items to target
-	if err := target.Export(allItems, finalOutputDir); err != nil {
+	if err := target.Export(allItems, r.outputDir); err != nil {
+		r.progress.Interrupt()
+
 		return fmt.Errorf("failed to export to target: %w", err)
 	}
 
-	fmt.Printf("Successfully exported %d emails\n", len(allItems))
+	failed, unconverted := budget.Unreported()
 
-	return nil
+	fmt.Printf("Successfully exported %d items\n", len(allItems)-failed)
+
+	if unconverted > 0 {
+		fmt.Printf("Warning: %d items failed to convert and were skipped\n", unconverted)
+	}
+
+	if failed > 0 {
+		fmt.Printf("Warning: %d items failed to export and were skipped\n", failed)
+	}
+
+	if budget.Quarantines() && failed+unconverted > 0 {
+		fmt.Printf("Failed items were saved to %s\n", budget.QuarantineDir())
+	}
+
+	r.lastRuns.record(r.sourceCounts, r.started)
+	saveSourceStates(r.sourceStates, failed)
+	if !r.replay {
+		recordSyncStats(cfg, allItems, r.sourceCounts, r.targetName, r.outputDir)
+	}
+
+	// Refresh the search index so the synced notes can be found with `pkm-sync search`
+	if cfg.Sync.SearchIndex {
+		if _, err := search.Update(r.outputDir); err != nil {
+			fmt.Printf("Warning: failed to update search index: %v\n", err)
+		}
+	}
+
+	// Record the synced changes in git if configured
+	if cfg.Sync.Git.AutoCommit {
+		committed, err := sync.CommitOutput(r.outputDir, cfg.Sync.Git, r.sourceCounts)
+		if err != nil {
+			fmt.Printf("Warning: failed to commit %s to git: %v\n", r.outputDir, err)
+		} else if committed {
+			fmt.Printf("Committed changes in %s to git\n", r.outputDir)
+		}
+	}
+
+	// Per-source post_sync hooks run for each source that was synced, then the global one
+	for _, srcName := range r.sources {
+		count, synced := r.sourceCounts[srcName]
+		if !synced {
+			continue
+		}
+
+		sourceRun := sync.HookRun{Sources: []string{srcName}, Target: r.targetName, OutputDir: r.outputDir,
+			ItemCount: count}
+		if err := sync.RunHook(cfg.Sources[srcName].Hooks, sync.HookPostSync, sourceRun); err != nil {
+			fmt.Printf("Warning: %v for source '%s'\n", err, srcName)
+		}
+	}
+
+	run.ItemCount = len(allItems)
+
+	return sync.RunHook(cfg.Sync.Hooks, sync.HookPostSync, run)
+}
+
+// saveSourceStates saves the incremental state of the sources of an export. When items failed to export,
+// the previous state is kept so the next sync fetches them again.
+func saveSourceStates(states map[string]interfaces.SyncStateSaver, failed int) {
+	for srcName, state := range states {
+		if failed > 0 {
+			fmt.Printf("Keeping the sync state of '%s' so the failed items are fetched again\n", srcName)
+
+			continue
+		}
+
+		if err := state.SaveSyncState(); err != nil {
+			fmt.Printf("Warning: failed to save the sync state of '%s': %v\n", srcName, err)
+		}
+	}
+}
+
+// newErrorBudget creates the item_errors budget of a run. Dry runs skip failing items instead of
+// quarantining them, leaving the output directory alone.
+func newErrorBudget(cfg *models.Config, outputDir string, dryRun bool) *sync.ErrorBudget {
+	itemErrors := cfg.Sync.ItemErrors
+	if dryRun && itemErrors.Policy == sync.ItemErrorsQuarantine {
+		itemErrors.Policy = sync.ItemErrorsSkip
+	}
+
+	return sync.NewErrorBudget(itemErrors, outputDir)
+}
+
+// setRawErrorHandler leaves the items a source fails to convert to budget, if the source supports it.
+func setRawErrorHandler(source interfaces.Source, srcName string, budget *sync.ErrorBudget) {
+	handler, ok := source.(interfaces.RawErrorHandler)
+	if !ok || budget == nil {
+		return
+	}
+
+	handler.SetRawErrorHandler(func(id string, raw interface{}, err error) error {
+		return budget.HandleRaw(srcName, id, raw, err)
+	})
+}
+
+// transformItems runs the collected items through the transformer pipeline. Items of sources that scope
+// the pipeline with their own transformers settings go through their source's pipeline, the others through
+// the global one.
+func transformItems(cfg *models.Config, items []models.ItemInterface, settings itemSettings,
+) ([]models.ItemInterface, error) {
+	// Per-item signature thresholds reach the transformers through their settings
+	transform.ApplySignatureThresholds(&cfg.Transformers, settings.signatureThresholds)
+
+	// Items are grouped by pipeline, keeping the order in which the groups first appear
+	var groups []string
+
+	grouped := make(map[string][]models.ItemInterface)
+
+	for _, item := range items {
+		group := settings.sources[item.GetID()]
+		if !transform.IsScoped(cfg.Sources[group].Transformers) {
+			group = ""
+		}
+
+		if _, seen := grouped[group]; !seen {
+			groups = append(groups, group)
+		}
+
+		grouped[group] = append(grouped[group], item)
+	}
+
+	var transformed []models.ItemInterface
+
+	for _, group := range groups {
+		pipelineConfig, err := transform.SourcePipelineConfig(cfg.Transformers, cfg.Sources[group].Transformers)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transformers settings of source '%s': %w", group, err)
+		}
+
+		groupItems := grouped[group]
+
+		if pipelineConfig.Enabled {
+			if groupItems, err = runTransformPipeline(pipelineConfig, groupItems); err != nil {
+				return nil, err
+			}
+
+			if group == "" {
+				fmt.Printf("Transformed to %d items\n", len(groupItems))
+			} else {
+				fmt.Printf("Transformed %s to %d items\n", group, len(groupItems))
+			}
+		}
+
+		transformed = append(transformed, groupItems...)
+	}
+
+	return transformed, nil
+}
+
+// runTransformPipeline runs items through a pipeline of all available transformers configured with
+// pipelineConfig.
+func runTransformPipeline(pipelineConfig models.TransformConfig, items []models.ItemInterface,
+) ([]models.ItemInterface, error) {
+	pipeline := transform.NewPipeline()
+
+	// Register all available transformers
+	for _, t := range transform.GetAllContentProcessingTransformers() {
+		if err := pipeline.AddTransformer(t); err != nil {
+			return nil, fmt.Errorf("failed to add transformer %s: %w", t.Name(), err)
+		}
+	}
+
+	if err := pipeline.Configure(pipelineConfig); err != nil {
+		return nil, fmt.Errorf("failed to configure transformer pipeline: %w", err)
+	}
+
+	transformedItems, err := pipeline.Transform(items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform items: %w", err)
+	}
+
+	return transformedItems, nil
+}
+
+// recordSyncStats adds the run to the statistics store and refreshes the stats note if configured.
+// Failures are reported as warnings since the sync itself succeeded.
+func recordSyncStats(cfg *models.Config, items []models.ItemInterface, sourceCounts map[string]int,
+	target, outputDir string,
+) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		fmt.Printf("Warning: failed to record sync stats: %v\n", err)
+
+		return
+	}
+
+	statsPath := filepath.Join(configDir, sync.StatsFile)
+
+	stats, err := sync.LoadStats(statsPath)
+	if err != nil {
+		fmt.Printf("Warning: failed to record sync stats: %v\n", err)
+
+		return
+	}
+
+	stats.Record(sync.CollectRunStats(items, sourceCounts, target, time.Now()))
+
+	if err := stats.Save(statsPath); err != nil {
+		fmt.Printf("Warning: failed to record sync stats: %v\n", err)
+
+		return
+	}
+
+	if cfg.Sync.StatsNote != "" {
+		if err := sync.WriteStatsNote(outputDir, cfg.Sync.StatsNote, stats, statsTopCount); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
+}
+
+// runSourceErrorHook runs a source's on_error hook, reporting a failing hook as a warning.
+func runSourceErrorHook(hooks models.HooksConfig, run sync.HookRun, err error) {
+	run.Err = err
+	if hookErr := sync.RunHook(hooks, sync.HookOnError, run); hookErr != nil {
+		fmt.Printf("Warning: %v\n", hookErr)
+	}
 }
 
 func createSource(name string, client *http.Client) (interfaces.Source, error) {
@@ -263,23 +831,40 @@ func createSource(name string, client *http.Client) (interfaces.Source, error) {
 }
 
 func createSourceWithConfig(sourceID string, sourceConfig models.SourceConfig, client *http.Client) (interfaces.Source, error) {
-	switch sourceConfig.Type {
-	case "google_calendar":
-		source := google.NewGoogleSourceWithConfig(sourceID, sourceConfig)
-		if err := source.Configure(nil, client); err != nil {
-			return nil, err
-		}
+	source, err := newSource(sourceID, sourceConfig)
+	if err != nil {
+		return nil, err
+	}
 
-		return source, nil
-	case "gmail":
-		source := google.NewGoogleSourceWithConfig(sourceID, sourceConfig)
-		if err := source.Configure(nil, client); err != nil {
-			return nil, err
-		}
+	if err := source.Configure(nil, client); err != nil {
+		return nil, err
+	}
 
-		return source, nil
+	return source, nil
+}
+
+// newSource creates a source of the configured type without configuring it, which is enough to ask for its
+// capabilities.
+func newSource(sourceID string, sourceConfig models.SourceConfig) (interfaces.Source, error) {
+	switch sourceConfig.Type {
+	case "google_calendar", "gmail", "google_drive":
+		return google.NewGoogleSourceWithConfig(sourceID, sourceConfig), nil
+	case bookmarks.SourceTypeBookmarks:
+		return bookmarks.NewBookmarksSourceWithConfig(sourceID, sourceConfig), nil
+	case ingest.SourceTypeIngest:
+		return ingest.NewIngestSourceWithConfig(sourceID, sourceConfig), nil
+	case applenotes.SourceTypeAppleNotes:
+		return applenotes.NewAppleNotesSourceWithConfig(sourceID, sourceConfig), nil
+	case teams.SourceTypeTeams:
+		return teams.NewTeamsSourceWithConfig(sourceID, sourceConfig), nil
+	case outlook.SourceTypeOutlookCalendar:
+		return outlook.NewOutlookSourceWithConfig(sourceID, sourceConfig), nil
+	case tasks.SourceTypeGoogleTasks:
+		return tasks.NewTasksSourceWithConfig(sourceID, sourceConfig), nil
+	case mock.SourceTypeMock:
+		return mock.NewMockSourceWithConfig(sourceID, sourceConfig), nil
 	default:
-		return nil, fmt.Errorf("unknown source type '%s': supported types are 'google_calendar', 'gmail' (others like slack, jira are planned for future releases)", sourceConfig.Type)
+		return nil, fmt.Errorf("unknown source type '%s': supported types are 'google_calendar', 'gmail', 'google_drive', 'google_tasks', 'bookmarks', 'ingest', 'apple_notes', 'teams', 'outlook_calendar', 'mock' (others like slack, jira are planned for future releases)", sourceConfig.Type)
 	}
 }
 
@@ -298,9 +883,34 @@ func createTarget(name string) (interfaces.Target, error) {
 			return nil, err
 		}
 
+		return target, nil
+	case "ical":
+		target := ical.NewICalTarget()
+		if err := target.Configure(nil); err != nil {
+			return nil, err
+		}
+
 		return target, nil
 	default:
-		return nil, fmt.Errorf("unknown target '%s': supported targets are 'obsidian' and 'logseq'", name)
+		return nil, fmt.Errorf("unknown target '%s': supported targets are 'obsidian', 'logseq' and 'ical'", name)
+	}
+}
+
+// applyFilenameConfig passes the shared filename policy settings to a target.
+func applyFilenameConfig(configMap map[string]interface{}, filenames models.FilenameConfig) {
+	configMap["filename_style"] = filenames.Style
+	configMap["filename_collision"] = filenames.Collision
+	configMap["filename_max_length"] = filenames.MaxLength
+	configMap["max_path_length"] = filenames.MaxPathLength
+	configMap["filename_lowercase"] = filenames.Lowercase
+	configMap["filename_ascii"] = filenames.ASCII
+	configMap["filename_separator"] = filenames.Separator
+}
+
+// applyLayoutConfig passes the sync subdirectory layout to a target when subdirectories are enabled.
+func applyLayoutConfig(configMap map[string]interface{}, syncConfig models.SyncConfig) {
+	if syncConfig.CreateSubdirs {
+		configMap["subdir_format"] = syncConfig.SubdirFormat
 	}
 }
 
@@ -311,9 +921,60 @@ func createTargetWithConfig(name string, cfg *models.Config) (interfaces.Target,
 
 		// Apply configuration
 		configMap := make(map[string]interface{})
+		applyLayoutConfig(configMap, cfg.Sync)
+
 		if targetConfig, exists := cfg.Targets[name]; exists {
+			applyFilenameConfig(configMap, targetConfig.Filenames)
+			configMap["item_folders"] = targetConfig.ItemFolders
+			configMap["folder_routes"] = targetConfig.FolderRoutes
+			configMap["timezone"] = targetConfig.Timezone
 			configMap["template_dir"] = targetConfig.Obsidian.DefaultFolder
 			configMap["daily_notes_format"] = targetConfig.Obsidian.DateFormat
+			configMap["create_daily_notes"] = targetConfig.Obsidian.CreateDailyNotes
+			configMap["daily_notes_folder"] = targetConfig.Obsidian.DailyNotesFolder
+			configMap["daily_notes_template"] = targetConfig.Obsidian.DailyNotesTemplate
+			configMap["daily_notes_embed"] = targetConfig.Obsidian.DailyNotesEmbed
+			configMap["template_file"] = targetConfig.Obsidian.TemplateFile
+			configMap["custom_fields"] = targetConfig.Obsidian.CustomFields
+			configMap["metadata_format"] = targetConfig.Obsidian.MetadataFormat
+			configMap["datetime_format"] = targetConfig.Obsidian.DateTimeFormat
+			configMap["property_names"] = targetConfig.Obsidian.PropertyNames
+			configMap["tag_prefix"] = targetConfig.Obsidian.TagPrefix
+			configMap["tag_names"] = targetConfig.Obsidian.TagNames
+			configMap["link_format"] = targetConfig.Obsidian.LinkFormat
+			configMap["download_attachments"] = targetConfig.Obsidian.DownloadAttachments
+			configMap["attachment_folder"] = targetConfig.Obsidian.AttachmentFolder
+			configMap["download_images"] = targetConfig.Obsidian.DownloadImages
+			configMap["convert_attachments"] = targetConfig.Obsidian.ConvertAttachments
+			configMap["attachment_converter"] = targetConfig.Obsidian.AttachmentConverter
+			configMap["attachment_policy"] = targetConfig.Obsidian.AttachmentPolicy
+			configMap["attachment_manifest"] = targetConfig.Obsidian.AttachmentManifest
+			configMap["canvas_threads"] = targetConfig.Obsidian.CanvasThreads
+			configMap["canvas_tags"] = targetConfig.Obsidian.CanvasTags
+			configMap["canvas_folder"] = targetConfig.Obsidian.CanvasFolder
+			configMap["kanban_board"] = targetConfig.Obsidian.KanbanBoard
+			configMap["kanban_item_types"] = targetConfig.Obsidian.KanbanItemTypes
+			configMap["kanban_status_field"] = targetConfig.Obsidian.KanbanStatusField
+			configMap["kanban_lanes"] = targetConfig.Obsidian.KanbanLanes
+			configMap["important_note"] = targetConfig.Obsidian.ImportantNote
+			configMap["important_bookmarks"] = targetConfig.Obsidian.ImportantBookmarks
+			configMap["important_when"] = targetConfig.Obsidian.ImportantWhen
+			configMap["important_priority_field"] = targetConfig.Obsidian.ImportantPriorityField
+			configMap["important_min_priority"] = targetConfig.Obsidian.ImportantMinPriority
+			configMap["index_notes"] = targetConfig.Obsidian.IndexNotes
+			configMap["index_folder"] = targetConfig.Obsidian.IndexFolder
+			configMap["cross_links"] = targetConfig.Obsidian.CrossLinks
+			configMap["changelog"] = targetConfig.Obsidian.Changelog
+			configMap["changelog_ignore"] = targetConfig.Obsidian.ChangelogIgnore
+			configMap["person_notes"] = targetConfig.Obsidian.PersonNotes
+			configMap["people_folder"] = targetConfig.Obsidian.PeopleFolder
+			configMap["person_names"] = targetConfig.Obsidian.PersonNames
+			configMap["company_notes"] = targetConfig.Obsidian.CompanyNotes
+			configMap["companies_folder"] = targetConfig.Obsidian.CompaniesFolder
+			configMap["company_names"] = targetConfig.Obsidian.CompanyNames
+			configMap["company_exclude_domains"] = targetConfig.Obsidian.CompanyExcludeDomains
+			configMap["rolling_notes"] = targetConfig.Obsidian.RollingNotes
+			configMap["rolling_note_titles"] = targetConfig.Obsidian.RollingNoteTitles
 		}
 
 		if err := target.Configure(configMap); err != nil {
@@ -327,8 +988,27 @@ func createTargetWithConfig(name string, cfg *models.Config) (interfaces.Target,
 
 		// Apply configuration
 		configMap := make(map[string]interface{})
+		applyLayoutConfig(configMap, cfg.Sync)
+
 		if targetConfig, exists := cfg.Targets[name]; exists {
+			applyFilenameConfig(configMap, targetConfig.Filenames)
+			configMap["item_folders"] = targetConfig.ItemFolders
+			configMap["folder_routes"] = targetConfig.FolderRoutes
+			configMap["timezone"] = targetConfig.Timezone
 			configMap["default_page"] = targetConfig.Logseq.DefaultPage
+			configMap["property_prefix"] = targetConfig.Logseq.PropertyPrefix
+			configMap["property_names"] = targetConfig.Logseq.PropertyNames
+			configMap["journal_date_format"] = targetConfig.Logseq.JournalDateFormat
+			configMap["export_mode"] = targetConfig.Logseq.ExportMode
+
+			// Unset settings keep the target's defaults of tabs and journal references
+			if targetConfig.Logseq.BlockIndentation != 0 {
+				configMap["block_indentation"] = targetConfig.Logseq.BlockIndentation
+			}
+
+			if targetConfig.Logseq.CreateJournalRefs != nil {
+				configMap["create_journal_refs"] = *targetConfig.Logseq.CreateJournalRefs
+			}
 		}
 
 		if err := target.Configure(configMap); err != nil {
@@ -337,42 +1017,31 @@ func createTargetWithConfig(name string, cfg *models.Config) (interfaces.Target,
 
 		return target, nil
 
-	default:
-		return nil, fmt.Errorf("unknown target '%s': supported targets are 'obsidian' and 'logseq'", name)
-	}
-}
+	case "ical":
+		target := ical.NewICalTarget()
 
-func parseSinceTime(since string) (time.Time, error) {
-	now := time.Now()
-
-	switch since {
-	case "today":
-		return now.Truncate(24 * time.Hour), nil
-	case "yesterday":
-		return now.Add(-24 * time.Hour).Truncate(24 * time.Hour), nil
-	}
+		configMap := make(map[string]interface{})
 
-	// Try relative duration (7d, 2h, etc.)
-	// Go's ParseDuration doesn't handle "d" for days, so convert explicitly
-	if strings.HasSuffix(since, "d") {
-		daysStr := strings.TrimSuffix(since, "d")
-		if daysInt, err := strconv.Atoi(daysStr); err == nil && daysInt >= 0 {
-			daysDuration := time.Duration(daysInt) * 24 * time.Hour
+		if targetConfig, exists := cfg.Targets[name]; exists {
+			configMap["feed_file"] = targetConfig.ICal.FeedFile
+			configMap["calendar_name"] = targetConfig.ICal.CalendarName
+			configMap["skip_tasks"] = targetConfig.ICal.SkipTasks
+		}
 
-			return now.Add(-daysDuration), nil
+		if err := target.Configure(configMap); err != nil {
+			return nil, err
 		}
-	}
 
-	if duration, err := time.ParseDuration(since); err == nil {
-		return now.Add(-duration), nil
-	}
+		return target, nil
 
-	// Try absolute date
-	if t, err := time.Parse("2006-01-02", since); err == nil {
-		return t, nil
+	default:
+		return nil, fmt.Errorf("unknown target '%s': supported targets are 'obsidian', 'logseq' and 'ical'", name)
 	}
+}
 
-	return time.Time{}, fmt.Errorf("unable to parse since time '%s': supported formats are 'today', 'yesterday', relative durations (7d, 24h), or absolute dates (2006-01-02)", since)
+// parseSinceTime parses a --since value or since setting relative to now; see utils.ParseSince.
+func parseSinceTime(since string) (time.Time, error) {
+	return utils.ParseSince(since, time.Now())
 }
 
 // getEnabledSources returns list of sources that are enabled in the configuration.
@@ -436,13 +1105,14 @@ func getSourceOutputDirectory(baseOutputDir string, sourceConfig models.SourceCo
 
 // DryRunOutput represents the complete output structure for JSON format.
 type DryRunOutput struct {
-	Target       string                    `json:"target"`
-	OutputDir    string                    `json:"output_dir"`
-	Sources      []string                  `json:"sources"`
-	TotalItems   int                       `json:"total_items"`
-	Summary      DryRunSummary             `json:"summary"`
-	Items        []models.ItemInterface    `json:"items"`
-	FilePreviews []*interfaces.FilePreview `json:"file_previews"`
+	SchemaVersion int                       `json:"schema_version"`
+	Target        string                    `json:"target"`
+	OutputDir     string                    `json:"output_dir"`
+	Sources       []string                  `json:"sources"`
+	TotalItems    int                       `json:"total_items"`
+	Summary       DryRunSummary             `json:"summary"`
+	Items         []models.ItemInterface    `json:"items"`
+	FilePreviews  []*interfaces.FilePreview `json:"file_previews"`
 }
 
 type DryRunSummary struct {
@@ -452,17 +1122,16 @@ type DryRunSummary struct {
 	ConflictCount int `json:"conflict_count"`
 }
 
-func outputDryRunJSON(items []models.ItemInterface, previews []*interfaces.FilePreview, target, outputDir string, sources []string) error {
-	summary := calculateSummary(previews)
-
+func outputDryRunJSON(items []models.ItemInterface, plan *interfaces.Plan, target string, sources []string) error {
 	output := DryRunOutput{
-		Target:       target,
-		OutputDir:    outputDir,
-		Sources:      sources,
-		TotalItems:   len(items),
-		Summary:      summary,
-		Items:        items,
-		FilePreviews: previews,
+		SchemaVersion: models.ItemSchemaVersion,
+		Target:        target,
+		OutputDir:     plan.OutputDir,
+		Sources:       sources,
+		TotalItems:    len(items),
+		Summary:       dryRunSummary(plan),
+		Items:         items,
+		FilePreviews:  plan.Changes,
 	}
 
 	jsonData, err := json.MarshalIndent(output, "", "  ")
@@ -475,19 +1144,19 @@ func outputDryRunJSON(items []models.ItemInterface, previews []*interfaces.FileP
 	return nil
 }
 
-func outputDryRunSummary(items []models.ItemInterface, previews []*interfaces.FilePreview, target, outputDir string, _ []string) error {
+func outputDryRunSummary(items []models.ItemInterface, plan *interfaces.Plan, target string) error {
 	fmt.Printf("=== DRY RUN: Preview of sync operation ===\n")
-	fmt.Printf("Target: %s\nOutput directory: %s\nTotal items: %d\n\n", target, outputDir, len(items))
+	fmt.Printf("Target: %s\nOutput directory: %s\nTotal items: %d\n\n", target, plan.OutputDir, len(items))
 
-	summary := calculateSummary(previews)
+	summary := plan.Summary()
 
 	fmt.Printf("Summary:\n")
-	fmt.Printf("  📝 %d files would be created\n", summary.CreateCount)
-	fmt.Printf("  ✏️  %d files would be updated\n", summary.UpdateCount)
-	fmt.Printf("  ⏭️  %d files would be skipped (no changes)\n", summary.SkipCount)
+	fmt.Printf("  📝 %d files would be created\n", summary.Create)
+	fmt.Printf("  ✏️  %d files would be updated\n", summary.Update)
+	fmt.Printf("  ⏭️  %d files would be skipped (no changes)\n", summary.Skip)
 
-	if summary.ConflictCount > 0 {
-		fmt.Printf("  ⚠️  %d files have potential conflicts\n", summary.ConflictCount)
+	if summary.Conflicts > 0 {
+		fmt.Printf("  ⚠️  %d files have potential conflicts\n", summary.Conflicts)
 	}
 
 	fmt.Printf("\n")
@@ -495,7 +1164,7 @@ func outputDryRunSummary(items []models.ItemInterface, previews []*interfaces.Fi
 	// Show detailed file operations
 	fmt.Printf("Detailed file operations:\n")
 
-	for _, preview := range previews {
+	for _, preview := range plan.Changes {
 		var emoji string
 
 		switch preview.Action {
@@ -516,28 +1185,42 @@ func outputDryRunSummary(items []models.ItemInterface, previews []*interfaces.Fi
 
 	// Ask if user wants to see file content previews
 	fmt.Printf("\nWould you like to see content previews? This will show the first few lines of each file that would be created/updated.\n")
-	fmt.Printf("Note: Use --format json to see complete data model including full content\n")
+	fmt.Printf("Note: Use --format json to see complete data model including full content, or --format diff to see the changes\n")
 
 	return nil
 }
 
-func calculateSummary(previews []*interfaces.FilePreview) DryRunSummary {
-	summary := DryRunSummary{}
+// dryRunDiffContext is the number of unchanged lines shown around each change of a dry-run diff.
+const dryRunDiffContext = 3
 
-	for _, preview := range previews {
-		switch preview.Action {
-		case "create":
-			summary.CreateCount++
-		case "update":
-			summary.UpdateCount++
-		case "skip":
-			summary.SkipCount++
+// outputDryRunDiff prints a unified diff of every file the sync would write, relative to the output
+// directory, so it can be reviewed or piped to a pager.
+func outputDryRunDiff(plan *interfaces.Plan) error {
+	for _, change := range plan.Writes() {
+		name := change.FilePath
+		if rel, err := filepath.Rel(plan.OutputDir, change.FilePath); err == nil {
+			name = filepath.ToSlash(rel)
 		}
 
-		if preview.Conflict {
-			summary.ConflictCount++
+		from := "a/" + name
+		if change.Action == "create" {
+			from = "/dev/null"
 		}
+
+		fmt.Print(utils.UnifiedDiff(from, "b/"+name, change.ExistingContent, change.Content, dryRunDiffContext))
 	}
 
-	return summary
+	return nil
+}
+
+// dryRunSummary converts a plan's counts to the JSON dry-run summary.
+func dryRunSummary(plan *interfaces.Plan) DryRunSummary {
+	summary := plan.Summary()
+
+	return DryRunSummary{
+		CreateCount:   summary.Create,
+		UpdateCount:   summary.Update,
+		SkipCount:     summary.Skip,
+		ConflictCount: summary.Conflicts,
+	}
 }
@@ -0,0 +1,421 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"pkm-sync/internal/config"
+	"pkm-sync/internal/statestore"
+	"pkm-sync/internal/sync"
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultSourceLimit is the number of items fetched per source when max_results is not set.
+const defaultSourceLimit = 1000
+
+var (
+	syncSourceNames  []string
+	syncTargetName   string
+	syncOutputDir    string
+	syncSince        string
+	syncUntil        string
+	syncDryRun       bool
+	syncOutputFormat string
+	syncNoProgress   bool
+	syncRecord       string
+	syncPlayback     string
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Sync configured sources of any type to PKM systems",
+	Long: `Sync every enabled source in the configuration, whatever its type, through the transformer
+pipeline to a PKM target. Use --source to sync specific sources. Without --since, each source
+picks up where its last successful sync left off, falling back to its configured since. With --until,
+or a source's until, only items before it are synced, so a past range can be backfilled.
+
+Examples:
+  pkm-sync sync
+  pkm-sync sync --source bookmarks --target obsidian --output ./vault
+  pkm-sync sync --source gmail_work --source bookmarks --since 30d --dry-run
+  pkm-sync sync --source gmail_work --since 2022-01-01 --until 2023-01-01
+  pkm-sync sync --source gmail_work --record fixtures/
+  pkm-sync sync --source gmail_work --playback fixtures/ --output ./repro`,
+	RunE: runSyncCommand,
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().StringSliceVar(&syncSourceNames, "source", nil, "Sources to sync (default: enabled sources)")
+	syncCmd.Flags().StringVar(&syncTargetName, "target", "", "PKM target (obsidian, logseq, ical)")
+	syncCmd.Flags().StringVarP(&syncOutputDir, "output", "o", "", "Output directory")
+	syncCmd.Flags().StringVar(&syncSince, "since", "", "Sync items since (7d, 2006-01-02, today)")
+	syncCmd.Flags().StringVar(&syncUntil, "until", "", "Sync items before (2006-01-02, 30d); default: now")
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Show what would be synced without making changes")
+	syncCmd.Flags().StringVar(&syncOutputFormat, "format", "summary", "Output format for dry-run (summary, json, diff)")
+	syncCmd.Flags().BoolVar(&syncNoProgress, "no-progress", false, "Don't show progress bars or progress log lines")
+	syncCmd.Flags().StringVar(&syncRecord, "record", "", "Save the redacted API responses of the sync to this directory")
+	syncCmd.Flags().StringVar(&syncPlayback, "playback", "", "Sync from responses saved with --record instead of the APIs")
+}
+
+func runSyncCommand(cmd *cobra.Command, args []string) (err error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		cfg = config.GetDefaultConfig()
+	}
+
+	if err := statestore.Configure(cfg.App.StateEncryption); err != nil {
+		return err
+	}
+
+	sourcesToSync := syncSourceNames
+	if len(sourcesToSync) == 0 {
+		sourcesToSync = getEnabledSources(cfg)
+	}
+
+	if len(sourcesToSync) == 0 {
+		return fmt.Errorf("no sources configured. Please configure sources in your config file or use --source flag")
+	}
+
+	finalTargetName := cfg.Sync.DefaultTarget
+	if syncTargetName != "" {
+		finalTargetName = syncTargetName
+	}
+
+	finalOutputDir := cfg.Sync.DefaultOutputDir
+	if syncOutputDir != "" {
+		finalOutputDir = syncOutputDir
+	}
+
+	finalSince := cfg.Sync.DefaultSince
+	if syncSince != "" {
+		finalSince = syncSince
+	}
+
+	sinceTime, err := parseSinceTime(finalSince)
+	if err != nil {
+		return fmt.Errorf("invalid since parameter: %w", err)
+	}
+
+	if syncUntil != "" {
+		if _, err := parseSinceTime(syncUntil); err != nil {
+			return fmt.Errorf("invalid until parameter: %w", err)
+		}
+	}
+
+	syncFixtures, err = newFixtureSession(syncRecord, syncPlayback)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Syncing sources [%s] to %s (output: %s, since: %s)\n",
+		strings.Join(sourcesToSync, ", "), finalTargetName, finalOutputDir, finalSince)
+
+	run := sync.HookRun{Sources: sourcesToSync, Target: finalTargetName, OutputDir: finalOutputDir}
+
+	if !syncDryRun {
+		defer func() {
+			// Failed sources already ran their own on_error hooks
+			var partial *sync.PartialFailureError
+			if err != nil && !errors.As(err, &partial) {
+				run.Err = err
+				if hookErr := sync.RunHook(cfg.Sync.Hooks, sync.HookOnError, run); hookErr != nil {
+					fmt.Printf("Warning: %v\n", hookErr)
+				}
+			}
+		}()
+
+		if err := sync.RunHook(cfg.Sync.Hooks, sync.HookPreSync, run); err != nil {
+			return err
+		}
+	}
+
+	target, err := createTargetWithConfig(finalTargetName, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create target: %w", err)
+	}
+
+	var allItems []models.ItemInterface
+
+	sourceCounts := make(map[string]int)
+	sourceStates := make(map[string]interfaces.SyncStateSaver)
+	settings := newItemSettings()
+	changes := readVaultChanges(target, finalOutputDir)
+	budgets := loadQuotaBudgets(cfg)
+	lastRuns := loadLastRuns(cfg)
+	queue := loadOfflineQueue(cfg, syncFixtures.playingBack())
+	started := time.Now()
+
+	// JSON dry-run output must stay parseable
+	var progress *sync.Progress
+	if !syncNoProgress && !(syncDryRun && syncOutputFormat != "summary") {
+		progress = sync.NewProgress(os.Stdout)
+	}
+
+	budget := newErrorBudget(cfg, finalOutputDir, syncDryRun)
+
+	var report sync.RunReport
+
+	// A failing source is skipped so the others still sync, and reported in the summary
+	for _, srcName := range sourcesToSync {
+		sourceConfig, exists := cfg.Sources[srcName]
+		if !exists {
+			fmt.Printf("Warning: source '%s' not configured, skipping\n", srcName)
+			report.Failed(srcName, fmt.Errorf("not configured"))
+
+			continue
+		}
+
+		if !sourceConfig.Enabled {
+			fmt.Printf("Source '%s' is disabled, skipping\n", srcName)
+			report.Skipped(srcName, "disabled")
+
+			continue
+		}
+
+		// Playback doesn't call the APIs, so it costs no quota
+		if !syncFixtures.playingBack() {
+			if err := budgets.use(sourceConfig); err != nil {
+				fmt.Printf("Skipping source '%s': %v\n", srcName, err)
+				report.Skipped(srcName, err.Error())
+
+				continue
+			}
+		}
+
+		sourceRun := sync.HookRun{Sources: []string{srcName}, Target: finalTargetName, OutputDir: finalOutputDir}
+
+		sourceUntil, err := sourceUntilTime(srcName, sourceConfig, syncUntil)
+		if err != nil {
+			fmt.Printf("Warning: %v, skipping\n", err)
+			report.Failed(srcName, err)
+
+			continue
+		}
+
+		// A bounded window is a backfill, which neither starts from nor counts as the last run; neither does
+		// playing back a recording
+		sourceSince, sinceOverridden := sinceTime, syncSince != ""
+		if !sourceUntil.IsZero() || syncFixtures.playingBack() {
+			lastRuns.exclude(srcName)
+		} else if last, ok := lastRuns.since(srcName); ok && !sinceOverridden &&
+			sourceCapabilities(srcName, sourceConfig).Incremental {
+			// Sources that fetch their whole window, such as calendars fetching events by start, keep
+			// their configured since, or changes to items dated before the last run would be missed
+			fmt.Printf("Syncing %s since its last successful run (%s)\n", srcName, last.Format("2006-01-02 15:04"))
+
+			sourceSince, sinceOverridden = last, true
+		}
+
+		if since, ok := queue.since(srcName, sourceSince, sourceUntil); ok {
+			sourceSince, sinceOverridden = since, true
+		}
+
+		source, items, err := fetchSource(srcName, sourceConfig, sourceSince, finalSince, sinceOverridden, sourceUntil,
+			sourceRun, progress, budget)
+		budgets.save()
+
+		if err != nil {
+			progress.Interrupt()
+
+			// Offline sources are fetched by the next sync rather than failing this one
+			if queue.fetchFailed(srcName, sourceSince, sourceUntil, err) {
+				report.Skipped(srcName, "offline, queued for the next sync")
+
+				continue
+			}
+
+			if budgets.exhausted(srcName, err) {
+				report.Skipped(srcName, "google_quota budget used up for today")
+			} else {
+				fmt.Printf("Warning: %v, skipping\n", err)
+				report.Failed(srcName, err)
+			}
+
+			if !syncDryRun {
+				runSourceErrorHook(sourceConfig.Hooks, sourceRun, err)
+			}
+
+			continue
+		}
+
+		// Played back items must not change the real accounts or the item cache
+		if !syncFixtures.playingBack() {
+			changes.writeBack(source, items, syncDryRun)
+
+			if !syncDryRun {
+				cacheSourceItems(cfg, srcName, items)
+			}
+		}
+
+		fetched := len(items)
+		items = prepareSourceItems(cfg, srcName, sourceConfig, items, settings)
+		progress.Converted(srcName, fetched, len(items))
+
+		fmt.Printf("Found %d items from %s\n", len(items), srcName)
+		report.Succeeded(srcName, len(items))
+
+		allItems = append(allItems, items...)
+		sourceCounts[srcName] = len(items)
+		queue.collected(srcName, items, sourceUntil.IsZero() && fetched < sourceLimit(sourceConfig))
+
+		// Like the last run, the state only advances with syncs up to now of the real accounts
+		if saver, ok := source.(interfaces.SyncStateSaver); ok && sourceUntil.IsZero() && !syncFixtures.playingBack() {
+			sourceStates[srcName] = saver
+		}
+	}
+
+	allItems = queue.flush(allItems)
+
+	fmt.Printf("Total items collected: %d\n", len(allItems))
+
+	if err := syncFixtures.save(); err != nil {
+		return err
+	}
+
+	err = exportSyncedItems(syncRun{
+		cfg:          cfg,
+		target:       target,
+		targetName:   finalTargetName,
+		outputDir:    finalOutputDir,
+		sources:      sourcesToSync,
+		items:        allItems,
+		sourceCounts: sourceCounts,
+		settings:     settings,
+		dryRun:       syncDryRun,
+		format:       syncOutputFormat,
+		hooks:        run,
+		lastRuns:     lastRuns,
+		sourceStates: sourceStates,
+		started:      started,
+		progress:     progress,
+		budget:       budget,
+		replay:       syncFixtures.playingBack(),
+	})
+
+	if !syncDryRun {
+		queue.save(err)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return reportSources(cmd, &report, syncDryRun && syncOutputFormat != "summary")
+}
+
+// reportSources prints the per-source summary of a run, unless quiet, and returns an error when sources
+// failed: a *sync.PartialFailureError, which exits with exitPartialFailure, when others synced.
+func reportSources(cmd *cobra.Command, report *sync.RunReport, quiet bool) error {
+	if !quiet {
+		fmt.Print(report.Summary())
+	}
+
+	err := report.Err()
+	if err != nil {
+		// The summary already says what went wrong
+		cmd.SilenceUsage = true
+	}
+
+	return err
+}
+
+// fetchSource runs a source's pre_sync hook, then creates the source and fetches its items. The source's
+// since applies unless overridden, by --since or the source's last successful run.
+func fetchSource(srcName string, sourceConfig models.SourceConfig, sinceTime time.Time, since string,
+	overridden bool, until time.Time, sourceRun sync.HookRun, progress *sync.Progress, budget *sync.ErrorBudget,
+) (interfaces.Source, []models.ItemInterface, error) {
+	if !syncDryRun {
+		if err := sync.RunHook(sourceConfig.Hooks, sync.HookPreSync, sourceRun); err != nil {
+			return nil, nil, fmt.Errorf("%w for source '%s'", err, srcName)
+		}
+	}
+
+	return fetchSourceItems(srcName, sourceConfig, sinceTime, since, overridden, until, progress, budget)
+}
+
+// fetchSourceItems creates a source and fetches its items since sinceTime, or since the source's own since
+// unless overridden is set, up to until if it is not zero. Sources that report progress update progress,
+// and sources that can skip items that fail to convert leave them to budget, when these are not nil.
+func fetchSourceItems(srcName string, sourceConfig models.SourceConfig, sinceTime time.Time, since string,
+	overridden bool, until time.Time, progress *sync.Progress, budget *sync.ErrorBudget,
+) (interfaces.Source, []models.ItemInterface, error) {
+	client, err := syncFixtures.client(srcName, sourceConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	source, err := createSourceWithConfig(srcName, sourceConfig, client)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create source '%s': %w", srcName, err)
+	}
+
+	if reporter, ok := source.(interfaces.ProgressReporter); ok && progress != nil {
+		reporter.SetProgress(func(done, total int) { progress.Fetched(srcName, done, total) })
+	}
+
+	setRawErrorHandler(source, srcName, budget)
+
+	if sourceConfig.Since != "" && !overridden {
+		sourceSince, err := parseSinceTime(sourceConfig.Since)
+		if err != nil {
+			fmt.Printf("Warning: invalid since time for source '%s': %v, using %s\n", srcName, err, since)
+		} else {
+			sinceTime = sourceSince
+		}
+	}
+
+	sinceTime, until, err = syncFixtures.window(srcName, sinceTime, until)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !until.IsZero() && !until.After(sinceTime) {
+		return nil, nil, fmt.Errorf("until %s of source '%s' is not later than its since %s",
+			until.Format("2006-01-02 15:04"), srcName, sinceTime.Format("2006-01-02 15:04"))
+	}
+
+	fmt.Printf("Fetching items from %s...\n", srcName)
+
+	items, err := sync.FetchWindow(source, sinceTime, until, sourceLimit(sourceConfig))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch from source '%s': %w", srcName, err)
+	}
+
+	return source, items, nil
+}
+
+// sourceLimit returns the number of items fetched from a source: max_results, or defaultSourceLimit.
+func sourceLimit(sourceConfig models.SourceConfig) int {
+	if sourceConfig.Google.MaxResults > 0 {
+		return sourceConfig.Google.MaxResults
+	}
+
+	return defaultSourceLimit
+}
+
+// sourceUntilTime returns the end of a source's sync window: the --until flag if given, otherwise the
+// source's until. It is zero when neither is set, syncing up to now.
+func sourceUntilTime(srcName string, sourceConfig models.SourceConfig, flag string) (time.Time, error) {
+	until := sourceConfig.Until
+	if flag != "" {
+		until = flag
+	}
+
+	if until == "" {
+		return time.Time{}, nil
+	}
+
+	untilTime, err := parseSinceTime(until)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid until time for source '%s': %w", srcName, err)
+	}
+
+	return untilTime, nil
+}
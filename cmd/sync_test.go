@@ -2,7 +2,9 @@ package main
 
 import (
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"pkm-sync/pkg/models"
 )
@@ -330,6 +332,28 @@ func TestParseSinceTime_EdgeCases(t *testing.T) {
 	}
 }
 
+func TestSourceUntilTime(t *testing.T) {
+	sourceConfig := models.SourceConfig{Until: "2023-01-01"}
+
+	until, err := sourceUntilTime("gmail_work", sourceConfig, "")
+	if err != nil || until.Format("2006-01-02") != "2023-01-01" {
+		t.Errorf("sourceUntilTime() = %v, %v; want the source's until", until, err)
+	}
+
+	until, err = sourceUntilTime("gmail_work", sourceConfig, "2022-07-01")
+	if err != nil || until.Format("2006-01-02") != "2022-07-01" {
+		t.Errorf("sourceUntilTime() = %v, %v; want --until to win", until, err)
+	}
+
+	if until, err := sourceUntilTime("gmail_work", models.SourceConfig{}, ""); err != nil || !until.IsZero() {
+		t.Errorf("sourceUntilTime() without until = %v, %v; want zero", until, err)
+	}
+
+	if _, err := sourceUntilTime("gmail_work", models.SourceConfig{Until: "soon"}, ""); err == nil {
+		t.Error("sourceUntilTime() accepted an invalid until")
+	}
+}
+
 func TestCreateSource_Google(t *testing.T) {
 	source, err := createSource("google_calendar", &http.Client{})
 	if err != nil {
@@ -388,13 +412,35 @@ func TestCreateTarget_Logseq(t *testing.T) {
 	}
 }
 
+func TestCreateTargetWithConfig_LogseqDefaults(t *testing.T) {
+	cfg := &models.Config{Targets: map[string]models.TargetConfig{"logseq": {Type: "logseq"}}}
+
+	target, err := createTargetWithConfig("logseq", cfg)
+	if err != nil {
+		t.Fatalf("Failed to create logseq target: %v", err)
+	}
+
+	item := models.NewBasicItem("standup", "Standup")
+	item.SetCreatedAt(time.Date(2025, 1, 13, 9, 0, 0, 0, time.UTC))
+
+	plan, err := target.Plan([]models.FullItem{item}, t.TempDir())
+	if err != nil || len(plan.Changes) != 1 {
+		t.Fatalf("Plan() = %+v, %v", plan, err)
+	}
+
+	// Omitted create_journal_refs keeps the target's default of journal references
+	if content := plan.Changes[0].Content; !strings.Contains(content, "[[Jan 13th, 2025]]") {
+		t.Errorf("page without create_journal_refs has no journal reference:\n%s", content)
+	}
+}
+
 func TestCreateTarget_Unknown(t *testing.T) {
 	_, err := createTarget("unknown")
 	if err == nil {
 		t.Error("Expected error for unknown target")
 	}
 
-	expectedError := "unknown target 'unknown': supported targets are 'obsidian' and 'logseq'"
+	expectedError := "unknown target 'unknown': supported targets are 'obsidian', 'logseq' and 'ical'"
 	if err.Error() != expectedError {
 		t.Errorf("Expected error message %q, got %q", expectedError, err.Error())
 	}
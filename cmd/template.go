@@ -0,0 +1,174 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"pkm-sync/internal/config"
+	"pkm-sync/internal/sources/ingest"
+	"pkm-sync/internal/statestore"
+	"pkm-sync/internal/sync"
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	templateFile        string
+	templateItemID      string
+	templateSourceNames []string
+	templateFixture     string
+	templateTargetName  string
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Develop note templates",
+	Long: `Work on the Go templates set with a target's template_file.
+
+Examples:
+  pkm-sync template test --file note.tmpl --item-id 18c3f2a9b7e4d001
+  pkm-sync template test --file note.tmpl --fixture items.json`,
+}
+
+var templateTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Render a template against a cached or fixture item",
+	Long: `Render a template file against one item and print the note a sync would write, without syncing.
+The item is looked up by ID in the item cache (sync.item_cache) of the enabled sources, or those given
+with --source, or read from a --fixture file in the item JSON format the ingest source accepts. Without
+--item-id the most recently created item is used. The target's other settings, such as its link style,
+apply as configured.`,
+	RunE: runTemplateTestCommand,
+}
+
+func init() {
+	rootCmd.AddCommand(templateCmd)
+	templateCmd.AddCommand(templateTestCmd)
+
+	templateTestCmd.Flags().StringVar(&templateFile, "file", "", "Template file to render (required)")
+	templateTestCmd.Flags().StringVar(&templateItemID, "item-id", "", "ID of the item to render (default: the newest)")
+	templateTestCmd.Flags().StringSliceVar(&templateSourceNames, "source", nil, "Sources whose cached items are searched")
+	templateTestCmd.Flags().StringVar(&templateFixture, "fixture", "", "Item JSON file to read the item from instead")
+	templateTestCmd.Flags().StringVar(&templateTargetName, "target", "obsidian", "Target rendering the template")
+
+	_ = templateTestCmd.MarkFlagRequired("file")
+}
+
+func runTemplateTestCommand(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		cfg = config.GetDefaultConfig()
+	}
+
+	if err := statestore.Configure(cfg.App.StateEncryption); err != nil {
+		return err
+	}
+
+	target, err := createTargetWithConfig(templateTargetName, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create target: %w", err)
+	}
+
+	renderer, ok := target.(interfaces.TemplateRenderer)
+	if !ok {
+		return fmt.Errorf("target '%s' does not render templates", templateTargetName)
+	}
+
+	items, err := templateTestItems(cfg)
+	if err != nil {
+		return err
+	}
+
+	item, err := findTemplateItem(items, templateItemID)
+	if err != nil {
+		return err
+	}
+
+	content, err := renderer.RenderTemplateFile(templateFile, item)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(content)
+
+	return nil
+}
+
+// templateTestItems reads the items a template can be tested against: those of the fixture file, or the
+// cached items of the selected sources.
+func templateTestItems(cfg *models.Config) ([]models.ItemInterface, error) {
+	if templateFixture != "" {
+		fixture := ingest.NewIngestSourceWithConfig("fixture", models.SourceConfig{
+			Type:   ingest.SourceTypeIngest,
+			Ingest: models.IngestSourceConfig{Path: templateFixture},
+		})
+
+		return fixture.Fetch(time.Time{}, 0)
+	}
+
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	sourceNames := templateSourceNames
+	if len(sourceNames) == 0 {
+		sourceNames = getEnabledSources(cfg)
+	}
+
+	var items []models.ItemInterface
+
+	for _, srcName := range sourceNames {
+		cached, err := sync.LoadCachedItems(filepath.Join(configDir, sync.ItemCacheFile(srcName)))
+		if err != nil {
+			return nil, fmt.Errorf("%w for source '%s'", err, srcName)
+		}
+
+		items = append(items, cached...)
+	}
+
+	if len(items) == 0 {
+		return nil, errors.New("no cached items to render: enable sync.item_cache and sync, or pass --fixture")
+	}
+
+	return items, nil
+}
+
+// findTemplateItem returns the item with the given ID, looking into thread messages too, or the most
+// recently created item when id is empty.
+func findTemplateItem(items []models.ItemInterface, id string) (models.ItemInterface, error) {
+	if len(items) == 0 {
+		return nil, errors.New("no items to render")
+	}
+
+	if id == "" {
+		newest := items[0]
+		for _, item := range items[1:] {
+			if item.GetCreatedAt().After(newest.GetCreatedAt()) {
+				newest = item
+			}
+		}
+
+		return newest, nil
+	}
+
+	for _, item := range items {
+		if item.GetID() == id {
+			return item, nil
+		}
+
+		if thread, ok := models.AsThread(item); ok {
+			for _, message := range thread.GetMessages() {
+				if message.GetID() == id {
+					return message, nil
+				}
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("item '%s' not found", id)
+}
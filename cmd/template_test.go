@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestFindTemplateItem(t *testing.T) {
+	older := models.NewBasicItem("older", "Older")
+	older.SetCreatedAt(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	thread := models.NewThread("thread", "Thread")
+	thread.SetCreatedAt(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))
+	thread.AddMessage(models.NewBasicItem("message", "Message"))
+
+	items := []models.ItemInterface{older, thread}
+
+	for id, want := range map[string]string{"": "thread", "older": "older", "message": "message"} {
+		item, err := findTemplateItem(items, id)
+		if err != nil || item.GetID() != want {
+			t.Errorf("findTemplateItem(%q) = %v, %v; want %s", id, item, err, want)
+		}
+	}
+
+	if _, err := findTemplateItem(items, "missing"); err == nil {
+		t.Error("findTemplateItem() found an item that does not exist")
+	}
+}
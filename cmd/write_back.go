@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+// vaultChanges are the edits made in the vault since the last sync that sources can write back: tasks
+// checked or unchecked and tags added to or removed from notes.
+type vaultChanges struct {
+	tasks []interfaces.TaskStatusChange
+	tags  []interfaces.TagChange
+}
+
+// readVaultChanges reads the vault edits a target tracks. They are read before any source is fetched,
+// since exporting overwrites them.
+func readVaultChanges(target interfaces.Target, outputDir string) vaultChanges {
+	var changes vaultChanges
+
+	if reader, ok := target.(interfaces.TaskStatusReader); ok {
+		tasks, err := reader.TaskStatusChanges(outputDir)
+		if err != nil {
+			fmt.Printf("Warning: failed to read task status from the vault: %v\n", err)
+		}
+
+		changes.tasks = tasks
+	}
+
+	if reader, ok := target.(interfaces.TagChangeReader); ok {
+		tags, err := reader.TagChanges(outputDir)
+		if err != nil {
+			fmt.Printf("Warning: failed to read tag changes from the vault: %v\n", err)
+		}
+
+		changes.tags = tags
+	}
+
+	return changes
+}
+
+// writeBack writes the vault edits to the fetched items of a source with write-back enabled. Edits to
+// items the source did not fetch wait for a later sync.
+func (c vaultChanges) writeBack(source interfaces.Source, items []models.ItemInterface, dryRun bool) {
+	fetched := make(map[string]models.ItemInterface, len(items))
+	for _, item := range items {
+		fetched[item.GetID()] = item
+	}
+
+	lookup := func(id, sourceType string) (models.ItemInterface, bool) {
+		item, exists := fetched[id]
+
+		return item, exists && item.GetSourceType() == sourceType
+	}
+
+	if !source.Capabilities().WriteBack {
+		warnUnwritableTags(source.Name(), c.tags, lookup)
+
+		return
+	}
+
+	if writer, ok := source.(interfaces.TaskStatusWriter); ok && writer.WritesTaskStatus() {
+		writeBackTasks(source.Name(), writer, c.tasks, lookup, dryRun)
+	}
+
+	if writer, ok := source.(interfaces.TagWriter); ok && writer.WritesTags() {
+		writeBackTags(source.Name(), writer, c.tags, lookup, dryRun)
+	}
+}
+
+// warnUnwritableTags warns about tag edits on notes of a source that cannot write them back, which would
+// otherwise look like they were synced.
+func warnUnwritableTags(srcName string, changes []interfaces.TagChange,
+	lookup func(id, sourceType string) (models.ItemInterface, bool),
+) {
+	edited := 0
+
+	for _, change := range changes {
+		if _, fetched := lookup(change.ItemID, change.SourceType); fetched {
+			edited++
+		}
+	}
+
+	if edited > 0 {
+		fmt.Printf("Warning: tags were edited on %d notes from %s, which cannot write them back\n", edited, srcName)
+	}
+}
+
+// writeBackTasks completes or reopens the fetched tasks that were checked or unchecked in the vault.
+func writeBackTasks(srcName string, writer interfaces.TaskStatusWriter, changes []interfaces.TaskStatusChange,
+	lookup func(id, sourceType string) (models.ItemInterface, bool), dryRun bool,
+) {
+	for _, change := range changes {
+		item, found := lookup(change.ItemID, change.SourceType)
+		if !found {
+			continue
+		}
+
+		action := "Reopening"
+		if change.Completed {
+			action = "Completing"
+		}
+
+		if dryRun {
+			fmt.Printf("Would write back: %s task '%s' in %s\n", strings.ToLower(action), item.GetTitle(), srcName)
+
+			continue
+		}
+
+		fmt.Printf("%s task '%s' in %s\n", action, item.GetTitle(), srcName)
+
+		if err := writer.SetTaskCompleted(item, change.Completed); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
+}
+
+// writeBackTags applies the tags added to and removed from the notes of fetched items.
+func writeBackTags(srcName string, writer interfaces.TagWriter, changes []interfaces.TagChange,
+	lookup func(id, sourceType string) (models.ItemInterface, bool), dryRun bool,
+) {
+	for _, change := range changes {
+		item, found := lookup(change.ItemID, change.SourceType)
+		if !found {
+			continue
+		}
+
+		if dryRun {
+			fmt.Printf("Would write back tags of '%s' in %s: %s\n", item.GetTitle(), srcName, describeTagChange(change))
+
+			continue
+		}
+
+		fmt.Printf("Writing back tags of '%s' in %s: %s\n", item.GetTitle(), srcName, describeTagChange(change))
+
+		if err := writer.ApplyTagChanges(item, change.Added, change.Removed); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
+}
+
+// describeTagChange renders a tag change as "+added -removed".
+func describeTagChange(change interfaces.TagChange) string {
+	parts := make([]string, 0, len(change.Added)+len(change.Removed))
+
+	for _, tag := range change.Added {
+		parts = append(parts, "+#"+tag)
+	}
+
+	for _, tag := range change.Removed {
+		parts = append(parts, "-#"+tag)
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -4,8 +4,17 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-
+	"strings"
+	"time"
+
+	"pkm-sync/internal/sources/bookmarks"
+	"pkm-sync/internal/sources/chat"
+	"pkm-sync/internal/sources/google/calendar"
+	pkmsync "pkm-sync/internal/sync"
+	"pkm-sync/internal/transform"
+	"pkm-sync/internal/utils"
 	"pkm-sync/pkg/models"
+	"pkm-sync/pkg/query"
 
 	"gopkg.in/yaml.v3"
 )
@@ -107,8 +116,9 @@ func GetDefaultConfig() *models.Config {
 			"logseq": {
 				Type: "logseq",
 				Logseq: models.LogseqTargetConfig{
-					DefaultPage:   "Calendar",
-					UseProperties: true,
+					DefaultPage:       "Calendar",
+					UseProperties:     true,
+					JournalDateFormat: "Jan 2nd, 2006",
 				},
 			},
 		},
@@ -195,6 +205,29 @@ func ValidateConfig(cfg *models.Config) error {
 		return fmt.Errorf("sources configuration error: %w", err)
 	}
 
+	// Scoped transformer pipelines are checked against the global pipeline they change
+	for sourceName, sourceConfig := range cfg.Sources {
+		if _, err := transform.SourcePipelineConfig(cfg.Transformers, sourceConfig.Transformers); err != nil {
+			return fmt.Errorf("sources configuration error: source '%s': transformers: %w", sourceName, err)
+		}
+	}
+
+	if err := pkmsync.ValidateContacts(cfg.Contacts); err != nil {
+		return fmt.Errorf("contacts configuration error: %w", err)
+	}
+
+	// Contact lists may name the groups of the contacts block
+	for sourceName, sourceConfig := range cfg.Sources {
+		for key, entries := range map[string][]string{
+			"allow_contacts": sourceConfig.AllowContacts,
+			"deny_contacts":  sourceConfig.DenyContacts,
+		} {
+			if _, err := pkmsync.ResolveContacts(cfg.Contacts, entries); err != nil {
+				return fmt.Errorf("sources configuration error: source '%s': %s: %w", sourceName, key, err)
+			}
+		}
+	}
+
 	// Validate targets
 	if err := validateTargets(cfg.Targets); err != nil {
 		return fmt.Errorf("targets configuration error: %w", err)
@@ -209,6 +242,18 @@ func ValidateConfig(cfg *models.Config) error {
 		}
 	}
 
+	for account, limit := range cfg.Auth.GoogleQuota {
+		if limit < 0 {
+			return fmt.Errorf("auth configuration error: google_quota for account '%s' must not be negative", account)
+		}
+	}
+
+	if cfg.Auth.TokenExpiration != "" {
+		if _, err := utils.ParseDuration(cfg.Auth.TokenExpiration); err != nil {
+			return fmt.Errorf("auth configuration error: token_expiration: %w", err)
+		}
+	}
+
 	// Validate default target exists
 	if cfg.Sync.DefaultTarget != "" {
 		if _, exists := cfg.Targets[cfg.Sync.DefaultTarget]; !exists {
@@ -235,6 +280,30 @@ func validateSyncConfig(sync *models.SyncConfig) error {
 		return fmt.Errorf("at least one source must be enabled")
 	}
 
+	if err := pkmsync.ValidateDedupe(sync.DeduplicateBy, sync.DeduplicateWhere); err != nil {
+		return err
+	}
+
+	if _, err := pkmsync.ParseLastRunOverlap(sync.LastRunOverlap); err != nil {
+		return err
+	}
+
+	if err := pkmsync.ValidateItemErrors(sync.ItemErrors); err != nil {
+		return err
+	}
+
+	if sync.DefaultSince != "" {
+		if _, err := utils.ParseSince(sync.DefaultSince, time.Now()); err != nil {
+			return fmt.Errorf("default_since: %w", err)
+		}
+	}
+
+	if sync.MaxFileAge != "" {
+		if _, err := utils.ParseDuration(sync.MaxFileAge); err != nil {
+			return fmt.Errorf("max_file_age: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -259,18 +328,153 @@ func validateSourceConfig(_ string, config models.SourceConfig) error {
 		return fmt.Errorf("type is required")
 	}
 
+	if _, err := utils.LoadTimezone(config.Timezone); err != nil {
+		return err
+	}
+
+	if _, err := utils.FilenamePolicyFromConfig(config.Filenames, utils.DefaultFilenamePolicy()); err != nil {
+		return err
+	}
+
+	if err := pkmsync.ValidateKeywords(config.IncludeKeywords, config.ExcludeKeywords); err != nil {
+		return err
+	}
+
+	if _, err := utils.ParseMeetingProviders(config.MeetingProviders); err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	var since time.Time
+
+	if config.Since != "" {
+		var err error
+		if since, err = utils.ParseSince(config.Since, now); err != nil {
+			return fmt.Errorf("since: %w", err)
+		}
+	}
+
+	if config.Until != "" {
+		until, err := utils.ParseSince(config.Until, now)
+		if err != nil {
+			return fmt.Errorf("until: %w", err)
+		}
+
+		if config.Since != "" && !until.After(since) {
+			return fmt.Errorf("until '%s' must be later than since '%s'", config.Until, config.Since)
+		}
+	}
+
 	// Validate type-specific configurations
 	switch config.Type {
 	case "google_calendar":
-		if config.Google.CalendarID == "" {
-			return fmt.Errorf("calendar_id is required for google_calendar sources")
+		if config.Google.CalendarID == "" && len(config.Google.Calendars) == 0 {
+			return fmt.Errorf("calendar_id or calendars is required for google_calendar sources")
+		}
+
+		if _, err := calendar.NewEventFilter(config.Google.EventFilters, nil); err != nil {
+			return err
+		}
+	case "google_drive":
+		if config.Google.FolderID == "" {
+			return fmt.Errorf("folder_id is required for google_drive sources")
+		}
+
+		if _, err := utils.ParseSize(config.Google.MaxDocSize); err != nil {
+			return fmt.Errorf("max_doc_size: %w", err)
 		}
 	case "gmail":
 		if config.Gmail.Name == "" {
 			return fmt.Errorf("name is required for gmail sources")
 		}
+
+		for _, rule := range config.Gmail.TaggingRules {
+			if _, err := query.Parse(rule.Condition); err != nil {
+				return fmt.Errorf("tagging rule: %w", err)
+			}
+		}
+
+		for tag, label := range config.Gmail.TagLabels {
+			if strings.TrimSpace(tag) == "" || strings.TrimSpace(label) == "" {
+				return fmt.Errorf("tag_labels entries need both a tag and a label name")
+			}
+		}
+
+		for _, age := range []struct{ key, value string }{
+			{"max_email_age", config.Gmail.MaxEmailAge},
+			{"min_email_age", config.Gmail.MinEmailAge},
+		} {
+			if age.value == "" {
+				continue
+			}
+
+			if _, err := utils.ParseDuration(age.value); err != nil {
+				return fmt.Errorf("%s: %w", age.key, err)
+			}
+		}
+
+		if _, err := utils.ParseSize(config.Gmail.MaxAttachmentSize); err != nil {
+			return fmt.Errorf("max_attachment_size: %w", err)
+		}
+	case "bookmarks":
+		if config.Bookmarks.Path == "" && config.Bookmarks.Browser == "" {
+			return fmt.Errorf("path or browser is required for bookmarks sources")
+		}
+
+		if err := bookmarks.ValidateBrowser(config.Bookmarks.Browser); err != nil {
+			return err
+		}
+	case "ingest":
+		if config.Ingest.Path != "" && config.Ingest.Listen != "" {
+			return fmt.Errorf("path and listen cannot both be set for ingest sources")
+		}
+	case "apple_notes":
+		// Account and folder filters are optional; availability is checked when the source is configured
+	case "mock":
+		for _, path := range config.Mock.Fixtures {
+			if strings.TrimSpace(path) == "" {
+				return fmt.Errorf("mock fixtures must not be empty paths")
+			}
+		}
+	case "teams":
+		if config.Teams.Auth.ClientID == "" {
+			return fmt.Errorf("auth.client_id is required for teams sources")
+		}
+
+		switch config.Teams.ThreadMode {
+		case "", "individual", "consolidated", "summary":
+		default:
+			return fmt.Errorf("unsupported thread_mode '%s': supported modes are 'individual', 'consolidated', 'summary'",
+				config.Teams.ThreadMode)
+		}
+
+		if config.Teams.Capture.SavedOnly {
+			return fmt.Errorf("capture.saved_only is not supported for teams sources: Microsoft Graph does not expose " +
+				"saved messages, use capture.reactions instead")
+		}
+
+		if err := chat.ValidateCapture(config.Teams.Capture); err != nil {
+			return err
+		}
+	case "outlook_calendar":
+		if config.Outlook.Auth.ClientID == "" {
+			return fmt.Errorf("auth.client_id is required for outlook_calendar sources")
+		}
+
+		if _, err := calendar.NewEventFilter(config.Outlook.EventFilters, nil); err != nil {
+			return err
+		}
+	case "google_tasks":
+		for _, list := range config.GoogleTasks.TaskLists {
+			if strings.TrimSpace(list) == "" {
+				return fmt.Errorf("google_tasks task_lists entries must not be empty")
+			}
+		}
 	case "slack":
-		// Add slack-specific validations if needed
+		if err := chat.ValidateCapture(config.Slack.Capture); err != nil {
+			return err
+		}
 	case "jira":
 		// Add jira-specific validations if needed
 	default:
@@ -301,12 +505,28 @@ func validateTargetConfig(_ string, config models.TargetConfig) error {
 		return fmt.Errorf("type is required")
 	}
 
+	if _, err := utils.LoadTimezone(config.Timezone); err != nil {
+		return err
+	}
+
+	if _, err := utils.FilenamePolicyFromConfig(config.Filenames, utils.DefaultFilenamePolicy()); err != nil {
+		return err
+	}
+
 	// Validate supported target types
 	switch config.Type {
 	case "obsidian":
-		// Obsidian-specific validations could go here
+		if when := config.Obsidian.ImportantWhen; when != "" {
+			if _, err := query.Parse(when); err != nil {
+				return fmt.Errorf("important_when: %w", err)
+			}
+		}
 	case "logseq":
 		// Logseq-specific validations could go here
+	case "ical":
+		if feedFile := config.ICal.FeedFile; filepath.IsAbs(feedFile) || strings.HasPrefix(filepath.Clean(feedFile), "..") {
+			return fmt.Errorf("feed_file must be relative to the output directory, got '%s'", feedFile)
+		}
 	default:
 		return fmt.Errorf("unsupported target type: %s", config.Type)
 	}
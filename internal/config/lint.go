@@ -0,0 +1,134 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"time"
+
+	pkmsync "pkm-sync/internal/sync"
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/models"
+)
+
+// LintWarning is a setting that is valid but likely not what was meant.
+type LintWarning struct {
+	Path    string // Config key the warning is about, e.g. "sources.gmail_work.gmail.labels"
+	Message string
+}
+
+func (w LintWarning) String() string {
+	return w.Path + ": " + w.Message
+}
+
+// LintConfig looks for settings that pass validation but are suspicious, such as a Gmail source that would
+// fetch the whole mailbox. The warnings are sorted by path.
+func LintConfig(cfg *models.Config) []LintWarning {
+	return lintConfig(cfg, time.Now())
+}
+
+func lintConfig(cfg *models.Config, now time.Time) []LintWarning {
+	var warnings []LintWarning
+
+	for name, source := range cfg.Sources {
+		if !source.Enabled {
+			continue
+		}
+
+		if len(cfg.Sync.EnabledSources) > 0 && !slices.Contains(cfg.Sync.EnabledSources, name) {
+			warnings = append(warnings, LintWarning{
+				Path:    "sources." + name + ".enabled",
+				Message: "source is enabled but not listed in sync.enabled_sources, so it is never synced",
+			})
+		}
+
+		if source.Type == "gmail" {
+			since := source.Since
+			if since == "" {
+				since = cfg.Sync.DefaultSince
+			}
+
+			warnings = append(warnings, lintGmail("sources."+name+".gmail", source.Gmail, since, now)...)
+		}
+	}
+
+	itemErrors := cfg.Sync.ItemErrors
+	if itemErrors.MaxErrors > 0 && (itemErrors.Policy == "" || itemErrors.Policy == pkmsync.ItemErrorsFailFast) {
+		warnings = append(warnings, LintWarning{
+			Path:    "sync.item_errors.max_errors",
+			Message: "has no effect with the fail_fast policy, which stops at the first error",
+		})
+	}
+
+	sort.SliceStable(warnings, func(i, j int) bool { return warnings[i].Path < warnings[j].Path })
+
+	return warnings
+}
+
+// lintGmail checks a Gmail source's settings; since is the source's effective since, if any.
+func lintGmail(path string, gmailConfig models.GmailSourceConfig, since string, now time.Time) []LintWarning {
+	var warnings []LintWarning
+
+	warn := func(key, format string, args ...interface{}) {
+		warnings = append(warnings, LintWarning{Path: path + "." + key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(gmailConfig.Labels) == 0 && gmailConfig.Query == "" {
+		warn("labels", "neither labels nor query is set, so every message in the mailbox is fetched")
+	}
+
+	if gmailConfig.DownloadAttachments && len(gmailConfig.AttachmentTypes) == 0 {
+		warn("attachment_types", "is empty, so attachments of every type are downloaded")
+	}
+
+	if !gmailConfig.DownloadAttachments && len(gmailConfig.AttachmentTypes) > 0 {
+		warn("attachment_types", "has no effect without download_attachments")
+	}
+
+	if gmailConfig.ThreadMode != "" && !gmailConfig.IncludeThreads {
+		warn("thread_mode", "has no effect without include_threads")
+	}
+
+	maxAge := lintEmailAge(gmailConfig.MaxEmailAge, path+".max_email_age", &warnings)
+	minAge := lintEmailAge(gmailConfig.MinEmailAge, path+".min_email_age", &warnings)
+
+	if maxAge > 0 && minAge >= maxAge {
+		warn("min_email_age", "is not less than max_email_age (%s), so no message matches", gmailConfig.MaxEmailAge)
+	}
+
+	if maxAge > 0 && since != "" {
+		if start, err := utils.ParseSince(since, now); err == nil && now.Sub(start) < maxAge {
+			warn("max_email_age", "has no effect, since %q already limits the sync to newer messages", since)
+		}
+	}
+
+	return warnings
+}
+
+// minutesOnly matches an email age in whole minutes, such as "6m", which was likely meant as months.
+var minutesOnly = regexp.MustCompile(`(?i)^\s*\d+m\s*$`)
+
+// lintEmailAge parses an email age setting, warning about a minutes unit likely meant as months. It returns
+// zero for unset or invalid values.
+func lintEmailAge(value, path string, warnings *[]LintWarning) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	age, err := utils.ParseDuration(value)
+	if err != nil {
+		*warnings = append(*warnings, LintWarning{Path: path, Message: fmt.Sprintf("is ignored: %v", err)})
+
+		return 0
+	}
+
+	if minutesOnly.MatchString(value) {
+		*warnings = append(*warnings, LintWarning{
+			Path:    path,
+			Message: fmt.Sprintf("%q means minutes; use \"mo\" for months", value),
+		})
+	}
+
+	return age
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintConfig(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	lintPaths := func(cfg *models.Config) []string {
+		var paths []string
+		for _, warning := range lintConfig(cfg, now) {
+			paths = append(paths, warning.Path)
+		}
+
+		return paths
+	}
+
+	gmailConfig := func(gmailCfg models.GmailSourceConfig, since string) *models.Config {
+		return &models.Config{
+			Sources: map[string]models.SourceConfig{
+				"gmail_work": {Enabled: true, Type: "gmail", Since: since, Gmail: gmailCfg},
+			},
+		}
+	}
+
+	t.Run("clean config has no warnings", func(t *testing.T) {
+		cfg := gmailConfig(models.GmailSourceConfig{
+			Labels:              []string{"IMPORTANT"},
+			MaxEmailAge:         "30d",
+			DownloadAttachments: true,
+			AttachmentTypes:     []string{"pdf"},
+		}, "90d")
+
+		assert.Empty(t, lintConfig(cfg, now))
+	})
+
+	t.Run("gmail without labels or query", func(t *testing.T) {
+		assert.Equal(t, []string{"sources.gmail_work.gmail.labels"}, lintPaths(gmailConfig(models.GmailSourceConfig{}, "")))
+	})
+
+	t.Run("attachment settings", func(t *testing.T) {
+		cfg := gmailConfig(models.GmailSourceConfig{Query: "in:inbox", DownloadAttachments: true}, "")
+		assert.Equal(t, []string{"sources.gmail_work.gmail.attachment_types"}, lintPaths(cfg))
+
+		cfg = gmailConfig(models.GmailSourceConfig{Query: "in:inbox", AttachmentTypes: []string{"pdf"}}, "")
+		assert.Equal(t, []string{"sources.gmail_work.gmail.attachment_types"}, lintPaths(cfg))
+	})
+
+	t.Run("since shorter than max_email_age", func(t *testing.T) {
+		cfg := gmailConfig(models.GmailSourceConfig{Query: "in:inbox", MaxEmailAge: "30d"}, "7d")
+		assert.Equal(t, []string{"sources.gmail_work.gmail.max_email_age"}, lintPaths(cfg))
+
+		cfg.Sources["gmail_work"] = models.SourceConfig{
+			Enabled: true, Type: "gmail", Gmail: models.GmailSourceConfig{Query: "in:inbox", MaxEmailAge: "30d"},
+		}
+		cfg.Sync.DefaultSince = "2025-05-30"
+		assert.Equal(t, []string{"sources.gmail_work.gmail.max_email_age"}, lintPaths(cfg))
+	})
+
+	t.Run("email age units and order", func(t *testing.T) {
+		cfg := gmailConfig(models.GmailSourceConfig{Query: "in:inbox", MaxEmailAge: "6m"}, "")
+		assert.Equal(t, []string{"sources.gmail_work.gmail.max_email_age"}, lintPaths(cfg))
+
+		cfg = gmailConfig(models.GmailSourceConfig{Query: "in:inbox", MaxEmailAge: "1h30m"}, "")
+		assert.Empty(t, lintPaths(cfg))
+
+		cfg = gmailConfig(models.GmailSourceConfig{Query: "in:inbox", MaxEmailAge: "7d", MinEmailAge: "2w"}, "")
+		assert.Equal(t, []string{"sources.gmail_work.gmail.min_email_age"}, lintPaths(cfg))
+	})
+
+	t.Run("thread_mode without include_threads", func(t *testing.T) {
+		cfg := gmailConfig(models.GmailSourceConfig{Query: "in:inbox", ThreadMode: "summary"}, "")
+		assert.Equal(t, []string{"sources.gmail_work.gmail.thread_mode"}, lintPaths(cfg))
+	})
+
+	t.Run("sync settings", func(t *testing.T) {
+		cfg := gmailConfig(models.GmailSourceConfig{Query: "in:inbox"}, "")
+		cfg.Sync.EnabledSources = []string{"calendar"}
+		cfg.Sync.ItemErrors.MaxErrors = 5
+
+		assert.Equal(t, []string{"sources.gmail_work.enabled", "sync.item_errors.max_errors"}, lintPaths(cfg))
+	})
+
+	t.Run("disabled sources are not linted", func(t *testing.T) {
+		cfg := gmailConfig(models.GmailSourceConfig{}, "")
+		cfg.Sources["gmail_work"] = models.SourceConfig{Type: "gmail"}
+
+		assert.Empty(t, lintConfig(cfg, now))
+	})
+}
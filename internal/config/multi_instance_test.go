@@ -148,7 +148,8 @@ func TestTargetsConfigLoading(t *testing.T) {
 	assert.True(t, logseqConfig.UseProperties)
 	assert.Equal(t, "sync::", logseqConfig.PropertyPrefix)
 	assert.Equal(t, 2, logseqConfig.BlockIndentation)
-	assert.True(t, logseqConfig.CreateJournalRefs)
+	require.NotNil(t, logseqConfig.CreateJournalRefs)
+	assert.True(t, *logseqConfig.CreateJournalRefs)
 	assert.Equal(t, "2006-01-02", logseqConfig.JournalDateFormat)
 }
 
@@ -70,6 +70,28 @@ func GetTokenPath() (string, error) {
 	return filepath.Join(configDir, "token.json"), nil
 }
 
+// GetGoogleTokenPath returns where the token of a Google source with its own permissions is cached, e.g.
+// google_tasks_token.json. Other Google sources share the token at GetTokenPath.
+func GetGoogleTokenPath(name string) (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, fmt.Sprintf("google_%s_token.json", name)), nil
+}
+
+// GetMicrosoftTokenPath returns where the Microsoft Graph token for a source type is cached. Each source type
+// has its own token since each asks for different permissions.
+func GetMicrosoftTokenPath(sourceType string) (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, fmt.Sprintf("microsoft_%s_token.json", sourceType)), nil
+}
+
 func FindCredentialsFile() (string, error) {
 	if customCredentialsPath != "" {
 		if _, err := os.Stat(customCredentialsPath); err == nil {
@@ -0,0 +1,352 @@
+// Package convert turns document attachments into markdown or plain text companions that can be read and
+// searched inside the vault.
+package convert
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ConverterNative reads Office documents directly and uses pdftotext for PDFs.
+	ConverterNative = "native"
+	// ConverterPandoc converts every supported format with pandoc.
+	ConverterPandoc = "pandoc"
+
+	// commandTimeout bounds external converters so a broken document cannot stall a sync
+	commandTimeout = 60 * time.Second
+
+	// maxPartSize is the largest XML part read from an Office document
+	maxPartSize = 64 << 20
+)
+
+// Converter converts attachments to markdown.
+type Converter struct {
+	mode string
+
+	// lookPath finds external commands; replaced in tests
+	lookPath func(string) (string, error)
+}
+
+// New creates a converter for mode, "native" (default) or "pandoc".
+func New(mode string) (*Converter, error) {
+	switch mode {
+	case "", ConverterNative:
+		mode = ConverterNative
+	case ConverterPandoc:
+	default:
+		return nil, fmt.Errorf("unsupported attachment_converter '%s': supported converters are 'native', 'pandoc'", mode)
+	}
+
+	return &Converter{mode: mode, lookPath: exec.LookPath}, nil
+}
+
+// Supports reports whether an attachment with this name can be converted.
+func (c *Converter) Supports(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".docx", ".pptx":
+		return c.mode == ConverterNative || c.commandAvailable("pandoc")
+	case ".pdf":
+		return c.commandAvailable("pdftotext") || (c.mode == ConverterPandoc && c.commandAvailable("pandoc"))
+	}
+
+	return false
+}
+
+func (c *Converter) commandAvailable(name string) bool {
+	_, err := c.lookPath(name)
+
+	return err == nil
+}
+
+// Convert returns the markdown text of an attachment.
+func (c *Converter) Convert(name string, data []byte) (string, error) {
+	ext := strings.ToLower(filepath.Ext(name))
+
+	if c.mode == ConverterPandoc && ext != ".pdf" {
+		return runCommand(data, "pandoc", "--from", strings.TrimPrefix(ext, "."), "--to", "gfm", "-")
+	}
+
+	switch ext {
+	case ".docx":
+		return DocxToMarkdown(data)
+	case ".pptx":
+		return PptxToMarkdown(data)
+	case ".pdf":
+		if c.commandAvailable("pdftotext") {
+			return runCommand(data, "pdftotext", "-layout", "-", "-")
+		}
+
+		return runCommand(data, "pandoc", "--from", "pdf", "--to", "gfm", "-")
+	}
+
+	return "", fmt.Errorf("unsupported attachment format '%s'", ext)
+}
+
+// runCommand pipes data through an external converter and returns its output.
+func runCommand(data []byte, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s failed: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// DocxToMarkdown converts a Word document's paragraphs to markdown. Heading styles become headings and
+// list paragraphs become bullets; tables are written one row per line.
+func DocxToMarkdown(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("failed to open docx: %w", err)
+	}
+
+	part, err := readPart(archive, "word/document.xml")
+	if err != nil {
+		return "", err
+	}
+
+	var blocks []string
+
+	err = walkParagraphs(part, func(p paragraph) {
+		text := strings.TrimSpace(p.text)
+		if text == "" {
+			return
+		}
+
+		switch {
+		case p.heading > 0:
+			text = strings.Repeat("#", p.heading) + " " + text
+		case p.list:
+			text = "- " + text
+		}
+
+		blocks = append(blocks, text)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to parse docx: %w", err)
+	}
+
+	return joinBlocks(blocks), nil
+}
+
+// PptxToMarkdown converts a presentation to markdown with a section per slide.
+func PptxToMarkdown(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("failed to open pptx: %w", err)
+	}
+
+	var slides []string
+
+	for _, file := range archive.File {
+		if strings.HasPrefix(file.Name, "ppt/slides/slide") && strings.HasSuffix(file.Name, ".xml") {
+			slides = append(slides, file.Name)
+		}
+	}
+
+	// slide10.xml comes after slide9.xml
+	sort.Slice(slides, func(i, j int) bool { return slideNumber(slides[i]) < slideNumber(slides[j]) })
+
+	var blocks []string
+
+	for i, name := range slides {
+		part, err := readPart(archive, name)
+		if err != nil {
+			return "", err
+		}
+
+		var lines []string
+
+		err = walkParagraphs(part, func(p paragraph) {
+			if text := strings.TrimSpace(p.text); text != "" {
+				lines = append(lines, text)
+			}
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+
+		if len(lines) == 0 {
+			continue
+		}
+
+		// The first line is usually the slide title
+		block := fmt.Sprintf("## Slide %d: %s", i+1, lines[0])
+		for _, line := range lines[1:] {
+			block += "\n- " + line
+		}
+
+		blocks = append(blocks, block)
+	}
+
+	return joinBlocks(blocks), nil
+}
+
+func slideNumber(name string) int {
+	number, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, "ppt/slides/slide"), ".xml"))
+
+	return number
+}
+
+func readPart(archive *zip.Reader, name string) ([]byte, error) {
+	file, err := archive.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("missing %s: %w", name, err)
+	}
+	defer file.Close()
+
+	return io.ReadAll(io.LimitReader(file, maxPartSize))
+}
+
+// paragraph is the text of one <w:p> or <a:p> element.
+type paragraph struct {
+	text    string
+	heading int  // Heading level from a "Heading N" or "Title" style
+	list    bool // Numbered or bulleted paragraph
+}
+
+// walkParagraphs calls fn for every WordprocessingML or DrawingML paragraph in an Office XML part. Table
+// cells are joined with " | " into one paragraph per row.
+func walkParagraphs(part []byte, fn func(paragraph)) error {
+	decoder := xml.NewDecoder(bytes.NewReader(part))
+
+	var (
+		current  *paragraph
+		text     strings.Builder
+		inText   bool
+		rowCells []string
+		inRow    bool
+	)
+
+	// encoding/xml reports namespace URLs; the Office main namespaces all end in "/main"
+	namespaceOf := func(space string) bool {
+		return strings.HasSuffix(space, "/main")
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if !namespaceOf(t.Name.Space) {
+				continue
+			}
+
+			switch t.Name.Local {
+			case "p":
+				current = &paragraph{}
+				text.Reset()
+			case "t":
+				inText = true
+			case "tab":
+				text.WriteString("\t")
+			case "br":
+				text.WriteString(" ")
+			case "pStyle":
+				if current != nil {
+					current.heading = headingLevel(attribute(t, "val"))
+				}
+			case "numPr", "buChar", "buAutoNum":
+				if current != nil {
+					current.list = true
+				}
+			case "tr":
+				inRow = true
+				rowCells = nil
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			if !namespaceOf(t.Name.Space) {
+				continue
+			}
+
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if current == nil {
+					continue
+				}
+
+				current.text = text.String()
+				if inRow {
+					rowCells = append(rowCells, strings.TrimSpace(current.text))
+				} else {
+					fn(*current)
+				}
+
+				current = nil
+			case "tr":
+				inRow = false
+				fn(paragraph{text: strings.Join(rowCells, " | ")})
+			}
+		}
+	}
+}
+
+func attribute(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+
+	return ""
+}
+
+// headingLevel maps Word paragraph styles such as "Heading2" or "Title" to a markdown heading level.
+func headingLevel(style string) int {
+	lower := strings.ToLower(strings.ReplaceAll(style, " ", ""))
+
+	if lower == "title" {
+		return 1
+	}
+
+	if !strings.HasPrefix(lower, "heading") {
+		return 0
+	}
+
+	if level, err := strconv.Atoi(strings.TrimPrefix(lower, "heading")); err == nil {
+		if level > 6 {
+			level = 6
+		}
+
+		return level
+	}
+
+	return 0
+}
+
+func joinBlocks(blocks []string) string {
+	return strings.Join(blocks, "\n\n")
+}
@@ -0,0 +1,120 @@
+package convert
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"testing"
+)
+
+const (
+	wordNamespace    = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"`
+	drawingNamespace = `xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" ` +
+		`xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main"`
+)
+
+// officeFile builds a zip archive with the given parts.
+func officeFile(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	archive := zip.NewWriter(&buf)
+
+	for name, content := range parts {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestDocxToMarkdown(t *testing.T) {
+	document := `<w:document ` + wordNamespace + `><w:body>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Quarterly report</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Revenue grew </w:t></w:r><w:r><w:t>12%.</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/></w:numPr></w:pPr><w:r><w:t>Hire two engineers</w:t></w:r></w:p>
+<w:p></w:p>
+<w:tbl><w:tr><w:tc><w:p><w:r><w:t>Region</w:t></w:r></w:p></w:tc>
+<w:tc><w:p><w:r><w:t>EMEA</w:t></w:r></w:p></w:tc></w:tr></w:tbl>
+</w:body></w:document>`
+
+	got, err := DocxToMarkdown(officeFile(t, map[string]string{"word/document.xml": document}))
+	if err != nil {
+		t.Fatalf("DocxToMarkdown() error = %v", err)
+	}
+
+	want := "# Quarterly report\n\nRevenue grew 12%.\n\n- Hire two engineers\n\nRegion | EMEA"
+	if got != want {
+		t.Errorf("DocxToMarkdown() = %q, want %q", got, want)
+	}
+}
+
+func TestPptxToMarkdown(t *testing.T) {
+	slide := func(lines ...string) string {
+		body := ""
+		for _, line := range lines {
+			body += "<a:p><a:r><a:t>" + line + "</a:t></a:r></a:p>"
+		}
+
+		return `<p:sld ` + drawingNamespace + `><p:cSld><p:spTree><p:sp><p:txBody>` + body +
+			`</p:txBody></p:sp></p:spTree></p:cSld></p:sld>`
+	}
+
+	data := officeFile(t, map[string]string{
+		"ppt/slides/slide10.xml": slide("Questions"),
+		"ppt/slides/slide2.xml":  slide("Roadmap", "Launch in May", "Beta in June"),
+		"ppt/slides/slide1.xml":  slide("Kickoff"),
+	})
+
+	got, err := PptxToMarkdown(data)
+	if err != nil {
+		t.Fatalf("PptxToMarkdown() error = %v", err)
+	}
+
+	want := "## Slide 1: Kickoff\n\n## Slide 2: Roadmap\n- Launch in May\n- Beta in June\n\n## Slide 3: Questions"
+	if got != want {
+		t.Errorf("PptxToMarkdown() = %q, want %q", got, want)
+	}
+}
+
+func TestSupports(t *testing.T) {
+	notFound := func(string) (string, error) { return "", errors.New("not found") }
+
+	native := &Converter{mode: ConverterNative, lookPath: notFound}
+	pandoc := &Converter{mode: ConverterPandoc, lookPath: notFound}
+
+	tests := []struct {
+		converter *Converter
+		name      string
+		want      bool
+	}{
+		{native, "Report.DOCX", true},
+		{native, "slides.pptx", true},
+		{native, "scan.pdf", false}, // pdftotext not installed
+		{native, "notes.txt", false},
+		{pandoc, "report.docx", false}, // pandoc not installed
+	}
+
+	for _, tt := range tests {
+		if got := tt.converter.Supports(tt.name); got != tt.want {
+			t.Errorf("Supports(%q) in %s mode = %v, want %v", tt.name, tt.converter.mode, got, tt.want)
+		}
+	}
+}
+
+func TestNewRejectsUnknownConverter(t *testing.T) {
+	if _, err := New("libreoffice"); err == nil {
+		t.Error("New() expected an error for an unknown converter")
+	}
+}
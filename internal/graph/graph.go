@@ -0,0 +1,298 @@
+// Package graph builds the link graph of synced items, with the items, people, tags and URLs they mention
+// as nodes, and writes it as GraphML, DOT or JSON for analysis in external graph tools.
+package graph
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+// Node kinds.
+const (
+	KindItem   = "item"
+	KindPerson = "person"
+	KindTag    = "tag"
+	KindURL    = "url"
+)
+
+// Edge kinds, from an item to what it mentions.
+const (
+	EdgeFrom      = "from"
+	EdgeTo        = "to"
+	EdgeCC        = "cc"
+	EdgeOrganizer = "organizer"
+	EdgeAttendee  = "attendee"
+	EdgeTag       = "tag"
+	EdgeLink      = "link"
+	EdgeMessage   = "message" // From a thread to one of its messages
+)
+
+// Formats supported by Write.
+const (
+	FormatGraphML = "graphml"
+	FormatDOT     = "dot"
+	FormatJSON    = "json"
+)
+
+// Node is an item or something items mention.
+type Node struct {
+	ID     string `json:"id"` // Kind-prefixed, e.g. "person:ada@example.com"
+	Kind   string `json:"kind"`
+	Label  string `json:"label"`
+	Type   string `json:"type,omitempty"`   // Item type
+	Source string `json:"source,omitempty"` // Item source type
+	Date   string `json:"date,omitempty"`   // Item creation date, RFC 3339
+}
+
+// Edge connects an item to a node it mentions.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// Graph is the link graph of a set of items.
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+
+	index map[string]int
+	edges map[Edge]bool
+}
+
+// Build returns the graph of items: a node per item (and per message of a thread), linked to its people
+// (sender, recipients, organizer and attendees, identified by email address), tags and URLs. Nodes and
+// edges are sorted, so the same items always give the same output.
+func Build(items []models.ItemInterface) *Graph {
+	g := &Graph{index: make(map[string]int), edges: make(map[Edge]bool)}
+
+	for _, item := range items {
+		g.addItem(item)
+	}
+
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+
+		if a.To != b.To {
+			return a.To < b.To
+		}
+
+		return a.Kind < b.Kind
+	})
+
+	return g
+}
+
+func (g *Graph) addItem(item models.ItemInterface) string {
+	id := KindItem + ":" + item.GetID()
+
+	node := Node{ID: id, Kind: KindItem, Label: item.GetTitle(), Type: item.GetItemType(), Source: item.GetSourceType()}
+	if created := item.GetCreatedAt(); !created.IsZero() {
+		node.Date = created.Format(time.RFC3339)
+	}
+
+	g.addNode(node)
+
+	metadata := models.Metadata(item.GetMetadata())
+
+	if sender, ok := metadata.GetRecipient(models.MetadataFrom); ok {
+		g.addPerson(id, EdgeFrom, sender.Identity(), sender.Name)
+	}
+
+	for _, field := range [][2]string{{models.MetadataTo, EdgeTo}, {models.MetadataCC, EdgeCC}} {
+		key, kind := field[0], field[1]
+
+		recipients, _ := metadata.GetRecipients(key)
+		for _, recipient := range recipients {
+			g.addPerson(id, kind, recipient.Identity(), recipient.Name)
+		}
+	}
+
+	for _, field := range [][2]string{{models.MetadataOrganizer, EdgeOrganizer}, {models.MetadataAttendees, EdgeAttendee}} {
+		key, kind := field[0], field[1]
+
+		attendees, _ := metadata.GetAttendees(key)
+		for _, attendee := range attendees {
+			identity := attendee.Email
+			if identity == "" {
+				identity = attendee.DisplayName
+			}
+
+			g.addPerson(id, kind, identity, attendee.DisplayName)
+		}
+	}
+
+	for _, tag := range item.GetTags() {
+		tagID := KindTag + ":" + tag
+		g.addNode(Node{ID: tagID, Kind: KindTag, Label: tag})
+		g.addEdge(Edge{From: id, To: tagID, Kind: EdgeTag})
+	}
+
+	for _, link := range item.GetLinks() {
+		if link.URL == "" {
+			continue
+		}
+
+		urlID := KindURL + ":" + link.URL
+		g.addNode(Node{ID: urlID, Kind: KindURL, Label: link.URL})
+		g.addEdge(Edge{From: id, To: urlID, Kind: EdgeLink})
+	}
+
+	if thread, ok := models.AsThread(item); ok {
+		for _, message := range thread.GetMessages() {
+			if messageID := g.addItem(message); messageID != id {
+				g.addEdge(Edge{From: id, To: messageID, Kind: EdgeMessage})
+			}
+		}
+	}
+
+	return id
+}
+
+// addPerson links an item to a person, identified by lowercase email address (or name without one).
+func (g *Graph) addPerson(itemID, kind, identity, name string) {
+	identity = strings.ToLower(strings.TrimSpace(identity))
+	if identity == "" {
+		return
+	}
+
+	label := name
+	if label == "" {
+		label = identity
+	}
+
+	personID := KindPerson + ":" + identity
+	g.addNode(Node{ID: personID, Kind: KindPerson, Label: label})
+	g.addEdge(Edge{From: itemID, To: personID, Kind: kind})
+}
+
+// addNode adds a node once; a person's name replaces a label that was only their address.
+func (g *Graph) addNode(node Node) {
+	if i, exists := g.index[node.ID]; exists {
+		existing := &g.Nodes[i]
+		if existing.Kind == KindPerson && existing.Label == strings.TrimPrefix(existing.ID, KindPerson+":") {
+			existing.Label = node.Label
+		}
+
+		return
+	}
+
+	g.index[node.ID] = len(g.Nodes)
+	g.Nodes = append(g.Nodes, node)
+}
+
+func (g *Graph) addEdge(edge Edge) {
+	if !g.edges[edge] {
+		g.edges[edge] = true
+		g.Edges = append(g.Edges, edge)
+	}
+}
+
+// Write writes the graph in a format: FormatGraphML, FormatDOT or FormatJSON.
+func (g *Graph) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatGraphML:
+		return g.writeGraphML(w)
+	case FormatDOT:
+		return g.writeDOT(w)
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(g)
+	default:
+		return fmt.Errorf("unsupported graph format '%s': supported formats are 'graphml', 'dot', 'json'", format)
+	}
+}
+
+// graphMLKeys are the node and edge attributes declared in GraphML output.
+var graphMLKeys = []struct{ id, domain string }{
+	{"kind", "node"}, {"label", "node"}, {"type", "node"}, {"source", "node"}, {"date", "node"}, {"kind", "edge"},
+}
+
+func (g *Graph) writeGraphML(w io.Writer) error {
+	var sb strings.Builder
+
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+
+	for _, key := range graphMLKeys {
+		fmt.Fprintf(&sb, "  <key id=\"%s_%s\" for=\"%s\" attr.name=\"%s\" attr.type=\"string\"/>\n",
+			key.domain, key.id, key.domain, key.id)
+	}
+
+	sb.WriteString(`  <graph id="pkm-sync" edgedefault="directed">` + "\n")
+
+	for _, node := range g.Nodes {
+		fmt.Fprintf(&sb, "    <node id=\"%s\">\n", escapeXML(node.ID))
+
+		for _, field := range [][2]string{
+			{"kind", node.Kind}, {"label", node.Label}, {"type", node.Type}, {"source", node.Source}, {"date", node.Date},
+		} {
+			if field[1] != "" {
+				fmt.Fprintf(&sb, "      <data key=\"node_%s\">%s</data>\n", field[0], escapeXML(field[1]))
+			}
+		}
+
+		sb.WriteString("    </node>\n")
+	}
+
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&sb, "    <edge source=\"%s\" target=\"%s\">\n      <data key=\"edge_kind\">%s</data>\n    </edge>\n",
+			escapeXML(edge.From), escapeXML(edge.To), escapeXML(edge.Kind))
+	}
+
+	sb.WriteString("  </graph>\n</graphml>\n")
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+// dotShapes draws each node kind differently in DOT output.
+var dotShapes = map[string]string{KindItem: "box", KindPerson: "ellipse", KindTag: "hexagon", KindURL: "note"}
+
+func (g *Graph) writeDOT(w io.Writer) error {
+	var sb strings.Builder
+
+	sb.WriteString("digraph pkm_sync {\n")
+
+	for _, node := range g.Nodes {
+		fmt.Fprintf(&sb, "  %s [label=%s, shape=%s, kind=%s];\n",
+			quoteDOT(node.ID), quoteDOT(node.Label), dotShapes[node.Kind], quoteDOT(node.Kind))
+	}
+
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&sb, "  %s -> %s [label=%s];\n", quoteDOT(edge.From), quoteDOT(edge.To), quoteDOT(edge.Kind))
+	}
+
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+func escapeXML(s string) string {
+	var sb strings.Builder
+
+	_ = xml.EscapeText(&sb, []byte(s))
+
+	return sb.String()
+}
+
+// quoteDOT quotes a DOT identifier, escaping quotes and backslashes and flattening newlines.
+func quoteDOT(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ", "\r", "").Replace(s) + `"`
+}
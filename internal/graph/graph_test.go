@@ -0,0 +1,184 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func testItems() []models.ItemInterface {
+	email := models.NewBasicItem("m1", "Budget review")
+	email.SetSourceType("gmail")
+	email.SetItemType("email")
+	email.SetCreatedAt(time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC))
+	email.SetTags([]string{"finance"})
+	email.SetLinks([]models.Link{{URL: "https://docs.example.com/budget"}})
+	email.SetMetadata(map[string]interface{}{
+		models.MetadataFrom: "ada@example.com",
+		models.MetadataTo:   `Grace <GRACE@example.com>, "Bob \"B\"" <bob@example.com>`,
+	})
+
+	reply := models.NewBasicItem("m2", "Re: Budget review")
+	reply.SetMetadata(map[string]interface{}{models.MetadataFrom: "Grace <grace@example.com>"})
+
+	thread := models.NewThread("t1", "Budget review")
+	thread.AddMessage(email)
+	thread.AddMessage(reply)
+
+	event := models.NewBasicItem("e1", "Budget meeting")
+	event.SetSourceType("google_calendar")
+	event.SetTags([]string{"finance"})
+	event.SetMetadata(map[string]interface{}{
+		models.MetadataOrganizer: models.Attendee{Email: "ada@example.com", DisplayName: "Ada"},
+		models.MetadataAttendees: []models.Attendee{{Email: "grace@example.com"}, {DisplayName: "Room 4 & 5"}},
+	})
+
+	return []models.ItemInterface{thread, event}
+}
+
+func TestBuild(t *testing.T) {
+	g := Build(testItems())
+
+	nodes := make(map[string]Node)
+	for _, node := range g.Nodes {
+		nodes[node.ID] = node
+	}
+
+	for _, id := range []string{
+		"item:t1", "item:m1", "item:m2", "item:e1", "tag:finance", "url:https://docs.example.com/budget",
+		"person:ada@example.com", "person:grace@example.com", "person:bob@example.com", "person:room 4 & 5",
+	} {
+		if _, ok := nodes[id]; !ok {
+			t.Errorf("missing node %s", id)
+		}
+	}
+
+	if len(nodes) != 10 {
+		t.Errorf("Build() has %d nodes, want 10: %v", len(nodes), g.Nodes)
+	}
+
+	// People are merged by address, picking up a name once one is seen
+	if label := nodes["person:ada@example.com"].Label; label != "Ada" {
+		t.Errorf("ada label = %q, want Ada", label)
+	}
+
+	if label := nodes["person:grace@example.com"].Label; label != "Grace" {
+		t.Errorf("grace label = %q, want Grace", label)
+	}
+
+	if node := nodes["item:m1"]; node.Source != "gmail" || node.Type != "email" || node.Date != "2025-03-03T09:00:00Z" {
+		t.Errorf("item node = %+v", node)
+	}
+
+	edges := make(map[Edge]bool)
+	for _, edge := range g.Edges {
+		edges[edge] = true
+	}
+
+	for _, edge := range []Edge{
+		{"item:t1", "item:m1", EdgeMessage},
+		{"item:t1", "item:m2", EdgeMessage},
+		{"item:m1", "person:ada@example.com", EdgeFrom},
+		{"item:m1", "person:grace@example.com", EdgeTo},
+		{"item:m1", "tag:finance", EdgeTag},
+		{"item:m1", "url:https://docs.example.com/budget", EdgeLink},
+		{"item:m2", "person:grace@example.com", EdgeFrom},
+		{"item:e1", "person:ada@example.com", EdgeOrganizer},
+		{"item:e1", "person:room 4 & 5", EdgeAttendee},
+		{"item:e1", "tag:finance", EdgeTag},
+	} {
+		if !edges[edge] {
+			t.Errorf("missing edge %+v", edge)
+		}
+	}
+
+	// The same items give the same graph
+	if again := Build(testItems()); !equalGraphs(g, again) {
+		t.Error("Build() is not deterministic")
+	}
+}
+
+func equalGraphs(a, b *Graph) bool {
+	var bufA, bufB bytes.Buffer
+
+	_ = a.Write(&bufA, FormatJSON)
+	_ = b.Write(&bufB, FormatJSON)
+
+	return bufA.String() == bufB.String()
+}
+
+func TestWrite(t *testing.T) {
+	g := Build(testItems())
+
+	t.Run("graphml", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := g.Write(&buf, FormatGraphML); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+
+		var doc struct {
+			Graph struct {
+				Nodes []struct {
+					ID string `xml:"id,attr"`
+				} `xml:"node"`
+				Edges []struct {
+					Source string `xml:"source,attr"`
+				} `xml:"edge"`
+			} `xml:"graph"`
+		}
+
+		if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("GraphML is not valid XML: %v\n%s", err, buf.String())
+		}
+
+		if len(doc.Graph.Nodes) != len(g.Nodes) || len(doc.Graph.Edges) != len(g.Edges) {
+			t.Errorf("GraphML has %d nodes and %d edges, want %d and %d",
+				len(doc.Graph.Nodes), len(doc.Graph.Edges), len(g.Nodes), len(g.Edges))
+		}
+	})
+
+	t.Run("dot", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := g.Write(&buf, FormatDOT); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+
+		out := buf.String()
+		if !strings.HasPrefix(out, "digraph pkm_sync {\n") || !strings.HasSuffix(out, "}\n") {
+			t.Errorf("DOT output = %s", out)
+		}
+
+		if !strings.Contains(out, `"item:t1" -> "item:m1" [label="message"];`) {
+			t.Errorf("DOT output is missing the thread edge:\n%s", out)
+		}
+
+		if !strings.Contains(out, `label="Bob \"B\""`) {
+			t.Errorf("DOT output does not escape quotes:\n%s", out)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := g.Write(&buf, FormatJSON); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+
+		var decoded Graph
+		if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+			t.Fatalf("JSON output error = %v", err)
+		}
+
+		if len(decoded.Nodes) != len(g.Nodes) || len(decoded.Edges) != len(g.Edges) {
+			t.Errorf("JSON has %d nodes and %d edges", len(decoded.Nodes), len(decoded.Edges))
+		}
+	})
+
+	if err := g.Write(&bytes.Buffer{}, "csv"); err == nil {
+		t.Error("Write() accepted an unsupported format")
+	}
+}
@@ -0,0 +1,252 @@
+// Package recording records the API responses of a sync to fixture files and plays them back, so
+// conversion bugs can be reproduced from a shareable, redacted recording instead of the reporter's account.
+package recording
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pkm-sync/internal/utils"
+)
+
+// ManifestFile records the sync window of each source of a recording.
+const ManifestFile = "manifest.json"
+
+// interaction is one recorded request and its response.
+type interaction struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Query       string `json:"query,omitempty"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body,omitempty"`        // Text and JSON responses, redacted
+	BodyBase64  string `json:"body_base64,omitempty"` // Other responses, such as images, as they were
+}
+
+// Window is the time range a source was synced for in a recording.
+type Window struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until,omitempty"`
+}
+
+// Manifest describes a recording.
+type Manifest struct {
+	RecordedAt time.Time         `json:"recorded_at"`
+	Sources    map[string]Window `json:"sources"`
+}
+
+// LoadManifest reads the manifest of the recording in dir.
+func LoadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording manifest: %w", err)
+	}
+
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse recording manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// Save writes the manifest to the recording in dir.
+func (m *Manifest) Save(dir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode recording manifest: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create recording directory: %w", err)
+	}
+
+	return os.WriteFile(filepath.Join(dir, ManifestFile), data, 0644)
+}
+
+// SourceDir returns the directory of a source's interactions in the recording in dir.
+func SourceDir(dir, source string) string {
+	return filepath.Join(dir, utils.SanitizeFilename(source))
+}
+
+// Recorder is an http.RoundTripper saving each response it passes on to a directory, redacted.
+type Recorder struct {
+	base http.RoundTripper
+	dir  string
+	seq  atomic.Int64
+}
+
+// NewRecorder records the responses of base to dir.
+func NewRecorder(base http.RoundTripper, dir string) *Recorder {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Recorder{base: base, dir: dir}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response to record: %w", err)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := r.save(req, resp, body); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// save writes an interaction as the next numbered file, so playback serves responses in recorded order.
+func (r *Recorder) save(req *http.Request, resp *http.Response, body []byte) error {
+	contentType := resp.Header.Get("Content-Type")
+
+	recorded := interaction{
+		Method:      req.Method,
+		Path:        Redact(req.URL.Path),
+		Query:       redactQuery(req.URL.Query().Encode()),
+		Status:      resp.StatusCode,
+		ContentType: contentType,
+	}
+
+	if isText(contentType) {
+		recorded.Body = redactBody(contentType, body)
+	} else if len(body) > 0 {
+		recorded.BodyBase64 = base64.StdEncoding.EncodeToString(body)
+	}
+
+	data, err := json.MarshalIndent(recorded, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode recorded response: %w", err)
+	}
+
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create recording directory: %w", err)
+	}
+
+	path := filepath.Join(r.dir, fmt.Sprintf("%06d.json", r.seq.Add(1)))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write recorded response: %w", err)
+	}
+
+	return nil
+}
+
+// Player is an http.RoundTripper answering requests from a recording instead of the network. Requests are
+// matched by method and path, in recorded order, so query parameters derived from the time of the sync,
+// such as time ranges, don't have to match.
+type Player struct {
+	mu     sync.Mutex
+	queued map[string][]interaction
+}
+
+// NewPlayer loads the interactions recorded in dir.
+func NewPlayer(dir string) (*Player, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			names = append(names, entry.Name())
+		}
+	}
+
+	sort.Strings(names)
+
+	player := &Player{queued: make(map[string][]interaction)}
+
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recorded response: %w", err)
+		}
+
+		var recorded interaction
+		if err := json.Unmarshal(data, &recorded); err != nil {
+			return nil, fmt.Errorf("failed to parse recorded response %s: %w", name, err)
+		}
+
+		key := recorded.Method + " " + recorded.Path
+		player.queued[key] = append(player.queued[key], recorded)
+	}
+
+	return player, nil
+}
+
+func (p *Player) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	key := req.Method + " " + Redact(req.URL.Path)
+
+	p.mu.Lock()
+	queued := p.queued[key]
+
+	if len(queued) == 0 {
+		p.mu.Unlock()
+
+		return nil, fmt.Errorf("no recorded response for %s", key)
+	}
+
+	recorded := queued[0]
+	p.queued[key] = queued[1:]
+	p.mu.Unlock()
+
+	body := []byte(recorded.Body)
+
+	if recorded.BodyBase64 != "" {
+		decoded, err := base64.StdEncoding.DecodeString(recorded.BodyBase64)
+		if err != nil {
+			return nil, fmt.Errorf("recorded response for %s: %w", key, err)
+		}
+
+		body = decoded
+	}
+
+	header := make(http.Header)
+	if recorded.ContentType != "" {
+		header.Set("Content-Type", recorded.ContentType)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+		StatusCode:    recorded.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// isText reports whether a response is kept as redacted text rather than as base64.
+func isText(contentType string) bool {
+	return contentType == "" || strings.HasPrefix(contentType, "text/") || strings.Contains(contentType, "json")
+}
@@ -0,0 +1,148 @@
+package recording
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordAndPlayBack(t *testing.T) {
+	body := base64.URLEncoding.EncodeToString([]byte("Hi, write to ada@example.org"))
+	pages := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gmail/v1/users/me/messages":
+			pages++
+			w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+			fmt.Fprintf(w, `{"page": %d, "query": %q}`, pages, r.URL.Query().Get("q"))
+		case "/gmail/v1/users/me/messages/m1":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"id": "m1", "payload": {"headers": [{"name": "From", "value": "Ada <ada@example.org>"}],
+				"body": {"data": %q}}}`, body)
+		case "/image.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte{0x89, 'P', 'N', 'G'})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	recorded := &http.Client{Transport: NewRecorder(nil, dir)}
+
+	for _, path := range []string{
+		"/gmail/v1/users/me/messages?q=after:2025/01/01&access_token=secret",
+		"/gmail/v1/users/me/messages?q=after:2025/01/01&pageToken=2",
+		"/gmail/v1/users/me/messages/m1",
+		"/image.png",
+	} {
+		resp, err := recorded.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("recorded GET %s: %v", path, err)
+		}
+
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 4 {
+		t.Fatalf("recorded %d responses, want 4", len(files))
+	}
+
+	for _, file := range files {
+		data, _ := os.ReadFile(file)
+		if strings.Contains(string(data), "ada@example.org") || strings.Contains(string(data), "secret") {
+			t.Errorf("%s was not redacted:\n%s", filepath.Base(file), data)
+		}
+	}
+
+	player, err := NewPlayer(dir)
+	if err != nil {
+		t.Fatalf("NewPlayer() error = %v", err)
+	}
+
+	played := &http.Client{Transport: player}
+
+	get := func(path string) (int, string) {
+		t.Helper()
+
+		resp, err := played.Get("http://api.invalid" + path)
+		if err != nil {
+			t.Fatalf("played back GET %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+
+		data, _ := io.ReadAll(resp.Body)
+
+		return resp.StatusCode, string(data)
+	}
+
+	// Queries may differ, e.g. because since moved on; pages still come back in order
+	if _, page := get("/gmail/v1/users/me/messages?q=after:2025/02/01"); !strings.Contains(page, `"page":1`) {
+		t.Errorf("first page = %s", page)
+	}
+
+	if _, page := get("/gmail/v1/users/me/messages?q=after:2025/02/01&pageToken=2"); !strings.Contains(page, `"page":2`) {
+		t.Errorf("second page = %s", page)
+	}
+
+	status, message := get("/gmail/v1/users/me/messages/m1")
+	if status != http.StatusOK || !strings.Contains(message, Redact("ada@example.org")) {
+		t.Errorf("message = %d %s, want the redacted sender", status, message)
+	}
+
+	if _, image := get("/image.png"); image != "\x89PNG" {
+		t.Errorf("image = %q, want the recorded bytes", image)
+	}
+
+	if _, err := played.Get("http://api.invalid/gmail/v1/users/me/messages/m2"); err == nil {
+		t.Error("playing back an unrecorded request should fail")
+	}
+}
+
+func TestRedactEncodedContent(t *testing.T) {
+	encoded := base64.URLEncoding.EncodeToString([]byte("From: grace@example.com"))
+
+	redacted := redactBody("application/json", []byte(fmt.Sprintf(`{"parts": [{"body": {"data": %q}}]}`, encoded)))
+	if strings.Contains(redacted, encoded) {
+		t.Fatalf("redactBody() kept the encoded address: %s", redacted)
+	}
+
+	want := base64.URLEncoding.EncodeToString([]byte("From: " + Redact("grace@example.com")))
+	if !strings.Contains(redacted, want) {
+		t.Errorf("redactBody() = %s, want the content re-encoded with the address redacted", redacted)
+	}
+
+	if Redact("Grace@Example.com") != Redact("grace@example.com") {
+		t.Error("Redact() should give an address the same placeholder regardless of case")
+	}
+}
+
+func TestManifestRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	manifest := &Manifest{RecordedAt: since, Sources: map[string]Window{"gmail_work": {Since: since}}}
+	if err := manifest.Save(dir); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatalf("LoadManifest() error = %v", err)
+	}
+
+	if !loaded.Sources["gmail_work"].Since.Equal(since) {
+		t.Errorf("LoadManifest() = %+v", loaded)
+	}
+}
@@ -0,0 +1,101 @@
+package recording
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// emailAddress matches the email addresses redacted from recordings.
+var emailAddress = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)
+
+// secretParameters are query parameters dropped from recordings.
+var secretParameters = []string{"access_token", "key", "oauth_token"}
+
+// encodedFields are the JSON fields holding base64url-encoded content, such as Gmail message parts, which
+// are decoded to be redacted.
+var encodedFields = map[string]bool{"data": true, "raw": true}
+
+// Redact replaces the email addresses in text with placeholders. The same address always gets the same
+// placeholder, so conversations and attendees still line up in a recording.
+func Redact(text string) string {
+	return emailAddress.ReplaceAllStringFunc(text, func(address string) string {
+		sum := sha256.Sum256([]byte(strings.ToLower(address)))
+
+		return "user-" + hex.EncodeToString(sum[:])[:8] + "@example.com"
+	})
+}
+
+// redactQuery drops credentials from a query and redacts the rest.
+func redactQuery(query string) string {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return Redact(query)
+	}
+
+	for _, parameter := range secretParameters {
+		values.Del(parameter)
+	}
+
+	return Redact(values.Encode())
+}
+
+// redactBody redacts a text or JSON response. JSON is redacted value by value, decoding base64 content.
+func redactBody(contentType string, body []byte) string {
+	if !strings.Contains(contentType, "json") {
+		return Redact(string(body))
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return Redact(string(body))
+	}
+
+	redacted, err := json.Marshal(redactValue("", value))
+	if err != nil {
+		return Redact(string(body))
+	}
+
+	return string(redacted)
+}
+
+func redactValue(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for field, fieldValue := range v {
+			v[field] = redactValue(field, fieldValue)
+		}
+
+		return v
+	case []interface{}:
+		for i, element := range v {
+			v[i] = redactValue(key, element)
+		}
+
+		return v
+	case string:
+		if encodedFields[key] {
+			return redactEncoded(v)
+		}
+
+		return Redact(v)
+	}
+
+	return value
+}
+
+// redactEncoded redacts base64url content, keeping its padding style. Content that doesn't decode is
+// redacted as is.
+func redactEncoded(value string) string {
+	for _, encoding := range []*base64.Encoding{base64.URLEncoding, base64.RawURLEncoding} {
+		if decoded, err := encoding.DecodeString(value); err == nil {
+			return encoding.EncodeToString([]byte(Redact(string(decoded))))
+		}
+	}
+
+	return Redact(value)
+}
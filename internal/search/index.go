@@ -0,0 +1,454 @@
+// Package search maintains a full-text index of the notes in an output directory, so synced items can be
+// searched from the command line where no PKM app is installed.
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"pkm-sync/internal/statestore"
+)
+
+const (
+	// IndexFile is the index kept in the root of the output directory.
+	IndexFile = ".pkm-sync-search.json"
+
+	indexVersion = 1
+	titleWeight  = 3
+	snippetWidth = 160
+)
+
+// noteExtensions are the files exported by the targets that get indexed.
+var noteExtensions = map[string]bool{".md": true, ".markdown": true, ".txt": true}
+
+// Index maps the notes of an output directory to the terms they contain.
+type Index struct {
+	Version   int                  `json:"version"`
+	Documents map[string]*Document `json:"documents"` // Keyed by slash-separated path relative to the output directory
+
+	dir string
+}
+
+// Document is what the index records about a note.
+type Document struct {
+	Title   string         `json:"title"`
+	ModTime time.Time      `json:"mod_time"`
+	Size    int64          `json:"size"`
+	Length  int            `json:"length"` // Number of terms
+	Terms   map[string]int `json:"terms"`  // Term frequencies, title terms weighted higher
+}
+
+// Result is a note matching a search.
+type Result struct {
+	Path    string  `json:"path"`
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet"`
+	Score   float64 `json:"score"`
+}
+
+// UpdateStats counts what an index update changed.
+type UpdateStats struct {
+	Added   int
+	Updated int
+	Removed int
+	Total   int
+}
+
+// Changed reports whether the update touched the index.
+func (s UpdateStats) Changed() bool {
+	return s.Added+s.Updated+s.Removed > 0
+}
+
+// Open loads the index of an output directory; a missing index is returned empty.
+func Open(dir string) (*Index, error) {
+	index := &Index{Version: indexVersion, Documents: make(map[string]*Document), dir: dir}
+
+	data, err := statestore.ReadFile(filepath.Join(dir, IndexFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return index, nil
+		}
+
+		return nil, fmt.Errorf("failed to read search index: %w", err)
+	}
+
+	var stored Index
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to parse search index: %w", err)
+	}
+
+	// An index written by another version is rebuilt rather than migrated
+	if stored.Version == indexVersion && stored.Documents != nil {
+		index.Documents = stored.Documents
+	}
+
+	return index, nil
+}
+
+// Exists reports whether an output directory has a search index.
+func Exists(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, IndexFile))
+
+	return err == nil
+}
+
+// Update opens the index of an output directory, brings it up to date and saves it.
+func Update(dir string) (UpdateStats, error) {
+	index, err := Open(dir)
+	if err != nil {
+		return UpdateStats{}, err
+	}
+
+	stats, err := index.Update()
+	if err != nil {
+		return stats, err
+	}
+
+	if stats.Changed() || !Exists(dir) {
+		if err := index.Save(); err != nil {
+			return stats, err
+		}
+	}
+
+	return stats, nil
+}
+
+// Update indexes notes that are new or changed since the last update and drops notes that were deleted.
+// Hidden files and directories (.obsidian, .git, the pkm-sync indexes) are skipped.
+func (idx *Index) Update() (UpdateStats, error) {
+	var stats UpdateStats
+
+	seen := make(map[string]bool)
+
+	err := filepath.WalkDir(idx.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path != idx.dir && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if entry.IsDir() || !noteExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+
+		rel, err := filepath.Rel(idx.dir, path)
+		if err != nil {
+			return err
+		}
+
+		rel = filepath.ToSlash(rel)
+		seen[rel] = true
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		existing, ok := idx.Documents[rel]
+		if ok && existing.ModTime.Equal(info.ModTime()) && existing.Size == info.Size() {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		idx.Documents[rel] = newDocument(rel, string(data), info)
+
+		if ok {
+			stats.Updated++
+		} else {
+			stats.Added++
+		}
+
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return stats, fmt.Errorf("failed to index %s: %w", idx.dir, err)
+	}
+
+	for rel := range idx.Documents {
+		if !seen[rel] {
+			delete(idx.Documents, rel)
+			stats.Removed++
+		}
+	}
+
+	stats.Total = len(idx.Documents)
+
+	return stats, nil
+}
+
+// Save writes the index to the output directory.
+func (idx *Index) Save() error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("failed to encode search index: %w", err)
+	}
+
+	if err := os.MkdirAll(idx.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if err := statestore.WriteFile(filepath.Join(idx.dir, IndexFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write search index: %w", err)
+	}
+
+	return nil
+}
+
+// Search returns the notes containing every term of the query, best matches first. A term ending in "*"
+// matches any word it starts; limit <= 0 returns all matches.
+func (idx *Index) Search(queryText string, limit int) ([]Result, error) {
+	terms := queryTerms(queryText)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("search query has no searchable words")
+	}
+
+	idf := idx.inverseFrequencies(terms)
+
+	var results []Result
+
+	for rel, doc := range idx.Documents {
+		score, ok := rank(doc, terms, idf)
+		if !ok {
+			continue
+		}
+
+		results = append(results, Result{
+			Path:  filepath.Join(idx.dir, filepath.FromSlash(rel)),
+			Title: doc.Title,
+			Score: score,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+
+		return results[i].Path < results[j].Path
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	for i := range results {
+		data, err := os.ReadFile(results[i].Path)
+		if err != nil {
+			// The note changed since the index was built; keep the hit without a snippet
+			continue
+		}
+
+		results[i].Snippet = snippet(string(data), terms)
+	}
+
+	return results, nil
+}
+
+// inverseFrequencies weights each query term by how few notes contain it.
+func (idx *Index) inverseFrequencies(terms []queryTerm) []float64 {
+	idf := make([]float64, len(terms))
+
+	for i, term := range terms {
+		matching := 0
+
+		for _, doc := range idx.Documents {
+			if term.frequency(doc) > 0 {
+				matching++
+			}
+		}
+
+		idf[i] = math.Log(1 + float64(len(idx.Documents))/float64(max(matching, 1)))
+	}
+
+	return idf
+}
+
+// rank scores a document by TF-IDF over the query terms; ok is false unless every term occurs.
+func rank(doc *Document, terms []queryTerm, idf []float64) (float64, bool) {
+	var total float64
+
+	for i, term := range terms {
+		frequency := term.frequency(doc)
+		if frequency == 0 {
+			return 0, false
+		}
+
+		total += float64(frequency) / float64(doc.Length+1) * idf[i]
+	}
+
+	return total, true
+}
+
+// queryTerm is a query word, optionally matching as a prefix.
+type queryTerm struct {
+	text   string
+	prefix bool
+}
+
+func (t queryTerm) matches(word string) bool {
+	if t.prefix {
+		return strings.HasPrefix(word, t.text)
+	}
+
+	return word == t.text
+}
+
+func (t queryTerm) frequency(doc *Document) int {
+	if !t.prefix {
+		return doc.Terms[t.text]
+	}
+
+	frequency := 0
+
+	for word, count := range doc.Terms {
+		if strings.HasPrefix(word, t.text) {
+			frequency += count
+		}
+	}
+
+	return frequency
+}
+
+func queryTerms(queryText string) []queryTerm {
+	var terms []queryTerm
+
+	for _, field := range strings.Fields(queryText) {
+		prefix := strings.HasSuffix(field, "*")
+
+		words := Tokenize(field)
+		for i, word := range words {
+			terms = append(terms, queryTerm{text: word, prefix: prefix && i == len(words)-1})
+		}
+	}
+
+	return terms
+}
+
+func newDocument(rel, content string, info fs.FileInfo) *Document {
+	title := noteTitle(rel, content)
+	terms := make(map[string]int)
+	length := 0
+
+	for _, word := range Tokenize(content) {
+		terms[word]++
+		length++
+	}
+
+	for _, word := range Tokenize(title) {
+		terms[word] += titleWeight
+	}
+
+	return &Document{Title: title, ModTime: info.ModTime(), Size: info.Size(), Length: length, Terms: terms}
+}
+
+// noteTitle takes the title from the frontmatter or the first heading, falling back to the file name.
+func noteTitle(rel, content string) string {
+	frontmatter, body := splitFrontmatter(content)
+
+	for _, line := range strings.Split(frontmatter, "\n") {
+		if value, ok := strings.CutPrefix(line, "title:"); ok {
+			if title := strings.Trim(strings.TrimSpace(value), `"'`); title != "" {
+				return title
+			}
+		}
+	}
+
+	for _, line := range strings.Split(body, "\n") {
+		if heading, ok := strings.CutPrefix(strings.TrimSpace(line), "# "); ok {
+			return strings.TrimSpace(heading)
+		}
+	}
+
+	return strings.TrimSuffix(filepath.Base(rel), filepath.Ext(rel))
+}
+
+// splitFrontmatter separates a leading YAML frontmatter block from the rest of a note.
+func splitFrontmatter(content string) (string, string) {
+	if !strings.HasPrefix(content, "---\n") {
+		return "", content
+	}
+
+	end := strings.Index(content[4:], "\n---")
+	if end < 0 {
+		return "", content
+	}
+
+	body := content[4+end+4:]
+
+	return content[4 : 4+end], strings.TrimPrefix(body, "\n")
+}
+
+// Tokenize splits text into lowercase words.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// snippet returns the first line of a note's body that contains a query term, shortened around the match.
+// Headings repeat the title, so body text is preferred.
+func snippet(content string, terms []queryTerm) string {
+	_, body := splitFrontmatter(content)
+	lines := strings.Split(body, "\n")
+
+	for _, headings := range []bool{false, true} {
+		for _, line := range lines {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") != headings {
+				continue
+			}
+
+			for _, word := range Tokenize(line) {
+				for _, term := range terms {
+					if term.matches(word) {
+						return shorten(line, word)
+					}
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// shorten cuts a line to about snippetWidth characters centred on the first occurrence of word.
+func shorten(line, word string) string {
+	runes := []rune(line)
+	if len(runes) <= snippetWidth {
+		return line
+	}
+
+	at := len([]rune(line[:min(max(strings.Index(strings.ToLower(line), word), 0), len(line))]))
+	start := max(at-snippetWidth/2, 0)
+	end := min(start+snippetWidth, len(runes))
+	start = max(end-snippetWidth, 0)
+
+	shortened := strings.TrimSpace(string(runes[start:end]))
+	if start > 0 {
+		shortened = "…" + shortened
+	}
+
+	if end < len(runes) {
+		shortened += "…"
+	}
+
+	return shortened
+}
@@ -0,0 +1,128 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeNote(t *testing.T, dir, rel, content string) {
+	t.Helper()
+
+	path := filepath.Join(dir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpdateAndSearch(t *testing.T) {
+	dir := t.TempDir()
+
+	writeNote(t, dir, "Mail/Q3-invoice.md", "---\ntitle: \"Q3 invoice\"\nfrom: billing@vendor.com\n---\n\n"+
+		"Hello,\n\nPlease find the invoice for Q3 attached.\n")
+	writeNote(t, dir, "Mail/Lunch.md", "# Lunch on Friday\n\nShall we get lunch? No invoice involved.\n")
+	writeNote(t, dir, "Meetings/Planning.md", "# Q3 planning\n\nRoadmap for the quarter.\n")
+	writeNote(t, dir, ".obsidian/workspace.md", "invoice q3")
+
+	stats, err := Update(dir)
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	if stats.Added != 3 || stats.Total != 3 {
+		t.Errorf("Update() = %+v, want 3 notes added", stats)
+	}
+
+	index, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	results, err := index.Search("invoice q3", 10)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("Search() returned %d results, want 1: %+v", len(results), results)
+	}
+
+	if results[0].Path != filepath.Join(dir, "Mail", "Q3-invoice.md") || results[0].Title != "Q3 invoice" {
+		t.Errorf("Search() result = %+v", results[0])
+	}
+
+	if results[0].Snippet != "Please find the invoice for Q3 attached." {
+		t.Errorf("Search() snippet = %q", results[0].Snippet)
+	}
+
+	results, err = index.Search("invo*", 10)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	if len(results) != 2 || results[0].Title != "Q3 invoice" {
+		t.Errorf("prefix Search() = %+v, want both invoice notes with the titled one first", results)
+	}
+
+	if _, err := index.Search("  --  ", 10); err == nil {
+		t.Error("Search() expected error for query without words")
+	}
+}
+
+func TestUpdateIsIncremental(t *testing.T) {
+	dir := t.TempDir()
+
+	writeNote(t, dir, "a.md", "alpha")
+	writeNote(t, dir, "b.md", "beta")
+
+	if _, err := Update(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := Update(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.Changed() {
+		t.Errorf("second Update() = %+v, want no changes", stats)
+	}
+
+	writeNote(t, dir, "a.md", "alpha gamma")
+
+	if err := os.Remove(filepath.Join(dir, "b.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err = Update(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.Updated != 1 || stats.Removed != 1 || stats.Total != 1 {
+		t.Errorf("Update() = %+v, want 1 updated and 1 removed", stats)
+	}
+
+	index, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if results, _ := index.Search("gamma", 0); len(results) != 1 {
+		t.Errorf("Search() after update = %+v, want the changed note", results)
+	}
+}
+
+func TestShorten(t *testing.T) {
+	line := strings.Repeat("filler ", 40) + "invoice" + strings.Repeat(" tail", 40)
+
+	got := shorten(line, "invoice")
+	if !strings.Contains(got, "invoice") || !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") {
+		t.Errorf("shorten() = %q", got)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !darwin
+
+package applenotes
+
+import "errors"
+
+var errUnsupportedOS = errors.New("apple_notes sources are only supported on macOS")
+
+func exportNotes() ([]byte, error) {
+	return nil, errUnsupportedOS
+}
+
+func checkAvailable() error {
+	return errUnsupportedOS
+}
@@ -0,0 +1,213 @@
+// Package applenotes implements a macOS-only source that exports notes from the Apple Notes app. Note
+// bodies are HTML and are converted to markdown with the content_cleanup transformer's HTML conversion.
+package applenotes
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"pkm-sync/internal/transform"
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+const (
+	SourceTypeAppleNotes = "apple_notes"
+
+	itemTypeNote = "note"
+
+	// recentlyDeletedFolder holds deleted notes until they expire; they are never synced
+	recentlyDeletedFolder = "Recently Deleted"
+)
+
+// inlineImage matches images embedded in note bodies as data URIs.
+var inlineImage = regexp.MustCompile(`(?is)<img\b[^>]*?\bsrc\s*=\s*["']data:(image/[\w.+-]+);base64,([^"']+)["'][^>]*>`)
+
+// note is one note as exported by the Notes script.
+type note struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Body     string    `json:"body"`
+	Folder   string    `json:"folder"`
+	Account  string    `json:"account"`
+	Created  time.Time `json:"created"`
+	Modified time.Time `json:"modified"`
+	Locked   bool      `json:"locked"`
+}
+
+type AppleNotesSource struct {
+	config   models.SourceConfig
+	sourceID string
+
+	// export returns the notes as JSON; runs the Notes script on macOS and is replaced in tests
+	export func() ([]byte, error)
+}
+
+func NewAppleNotesSourceWithConfig(sourceID string, config models.SourceConfig) *AppleNotesSource {
+	return &AppleNotesSource{
+		sourceID: sourceID,
+		config:   config,
+		export:   exportNotes,
+	}
+}
+
+func (s *AppleNotesSource) Name() string {
+	if s.sourceID != "" {
+		return s.sourceID
+	}
+
+	return SourceTypeAppleNotes
+}
+
+// Configure checks that Apple Notes is available on this system.
+func (s *AppleNotesSource) Configure(_ map[string]interface{}, _ *http.Client) error {
+	return checkAvailable()
+}
+
+// Fetch exports notes modified after since, newest first. Password-protected notes and the Recently
+// Deleted folder are skipped.
+func (s *AppleNotesSource) Fetch(since time.Time, limit int) ([]models.FullItem, error) {
+	data, err := s.export()
+	if err != nil {
+		return nil, err
+	}
+
+	var notes []note
+	if err := json.Unmarshal(data, &notes); err != nil {
+		return nil, fmt.Errorf("failed to parse Apple Notes export: %w", err)
+	}
+
+	cfg := s.config.AppleNotes
+	cleanup := transform.NewContentCleanupTransformer()
+
+	var items []models.FullItem
+
+	for _, n := range notes {
+		if n.Locked || n.Folder == recentlyDeletedFolder {
+			continue
+		}
+
+		if !matches(n.Account, cfg.Accounts, nil) || !matches(n.Folder, cfg.Folders, cfg.ExcludeFolders) {
+			continue
+		}
+
+		if !since.IsZero() && n.Modified.Before(since) {
+			continue
+		}
+
+		items = append(items, s.toItem(n, cleanup))
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].GetUpdatedAt().After(items[j].GetUpdatedAt()) })
+
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+
+	return items, nil
+}
+
+func (s *AppleNotesSource) Capabilities() interfaces.SourceCapabilities {
+	return interfaces.SourceCapabilities{Incremental: true, Attachments: true}
+}
+
+// toItem converts a note to an item. Inline images become attachments, and the heading Notes repeats the
+// title in is dropped from the content.
+func (s *AppleNotesSource) toItem(n note, cleanup *transform.ContentCleanupTransformer) models.FullItem {
+	sum := sha256.Sum256([]byte(n.ID))
+
+	item := models.NewBasicItem("applenotes_"+hex.EncodeToString(sum[:])[:16], n.Name)
+	item.SetSourceType(SourceTypeAppleNotes)
+	item.SetItemType(itemTypeNote)
+	item.SetCreatedAt(n.Created)
+	item.SetUpdatedAt(n.Modified)
+
+	body, attachments := extractImages(n.Body)
+	item.SetAttachments(attachments)
+	item.SetContent(dropTitleLine(cleanup.ProcessHTMLContent(body), n.Name))
+
+	if s.config.AppleNotes.FolderTags && n.Folder != "" {
+		item.SetTags([]string{strings.Join(strings.Fields(strings.ToLower(n.Folder)), "-")})
+	}
+
+	item.SetMetadata(map[string]interface{}{
+		"folder":  n.Folder,
+		"account": n.Account,
+		"note_id": n.ID,
+	})
+
+	return item
+}
+
+// extractImages removes data-URI images from a note body and returns them as attachments.
+func extractImages(body string) (string, []models.Attachment) {
+	var attachments []models.Attachment
+
+	body = inlineImage.ReplaceAllStringFunc(body, func(tag string) string {
+		match := inlineImage.FindStringSubmatch(tag)
+
+		data, err := base64.StdEncoding.DecodeString(match[2])
+		if err != nil {
+			return tag
+		}
+
+		name := fmt.Sprintf("image-%d", len(attachments)+1)
+		if exts, err := mime.ExtensionsByType(match[1]); err == nil && len(exts) > 0 {
+			name += exts[len(exts)-1]
+		}
+
+		attachments = append(attachments, models.Attachment{
+			Name:     name,
+			MimeType: match[1],
+			Size:     int64(len(data)),
+			Data:     match[2],
+		})
+
+		return ""
+	})
+
+	return body, attachments
+}
+
+// dropTitleLine removes the first line of content when it only repeats the note title.
+func dropTitleLine(content, title string) string {
+	first, rest, _ := strings.Cut(content, "\n")
+	if strings.TrimSpace(strings.TrimLeft(first, "# *")) == strings.TrimSpace(title) {
+		return strings.TrimSpace(rest)
+	}
+
+	return content
+}
+
+// matches reports whether name is in include (everything when empty) and not in exclude, ignoring case.
+func matches(name string, include, exclude []string) bool {
+	for _, excluded := range exclude {
+		if strings.EqualFold(name, excluded) {
+			return false
+		}
+	}
+
+	if len(include) == 0 {
+		return true
+	}
+
+	for _, included := range include {
+		if strings.EqualFold(name, included) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Ensure AppleNotesSource implements Source interface.
+var _ interfaces.Source = (*AppleNotesSource)(nil)
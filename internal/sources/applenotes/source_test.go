@@ -0,0 +1,109 @@
+package applenotes
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+const notesExport = `[
+  {"id": "x-coredata://1/ICNote/p1", "name": "Groceries", "folder": "Home", "account": "iCloud",
+   "body": "<div><h1>Groceries</h1></div><div><ul><li>Milk</li><li>Eggs</li></ul></div>",
+   "created": "2025-01-01T10:00:00Z", "modified": "2025-03-01T10:00:00Z", "locked": false},
+  {"id": "x-coredata://1/ICNote/p2", "name": "Diagram", "folder": "Work Projects", "account": "iCloud",
+   "body": "<div><b>Diagram</b></div><div>See <img src=\"data:image/png;base64,aGVsbG8=\"> below</div>",
+   "created": "2025-02-01T10:00:00Z", "modified": "2025-04-01T10:00:00Z", "locked": false},
+  {"id": "x-coredata://1/ICNote/p3", "name": "Secrets", "folder": "Home", "account": "iCloud",
+   "body": "", "created": "2025-01-01T10:00:00Z", "modified": "2025-05-01T10:00:00Z", "locked": true},
+  {"id": "x-coredata://1/ICNote/p4", "name": "Old", "folder": "Recently Deleted", "account": "iCloud",
+   "body": "<div>Old</div>", "created": "2025-01-01T10:00:00Z", "modified": "2025-05-01T10:00:00Z", "locked": false},
+  {"id": "x-coredata://2/ICNote/p1", "name": "Standup", "folder": "Notes", "account": "Exchange",
+   "body": "<div>Standup notes</div>", "created": "2024-06-01T10:00:00Z", "modified": "2024-06-01T10:00:00Z",
+   "locked": false}
+]`
+
+func fetch(t *testing.T, config models.AppleNotesSourceConfig, since time.Time, limit int) []models.FullItem {
+	t.Helper()
+
+	source := NewAppleNotesSourceWithConfig("notes", models.SourceConfig{Type: SourceTypeAppleNotes, AppleNotes: config})
+	source.export = func() ([]byte, error) { return []byte(notesExport), nil }
+
+	items, err := source.Fetch(since, limit)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	return items
+}
+
+func titles(items []models.FullItem) []string {
+	var names []string
+	for _, item := range items {
+		names = append(names, item.GetTitle())
+	}
+
+	return names
+}
+
+func TestFetchSkipsLockedAndDeletedNotes(t *testing.T) {
+	items := fetch(t, models.AppleNotesSourceConfig{}, time.Time{}, 0)
+
+	if want := []string{"Diagram", "Groceries", "Standup"}; !reflect.DeepEqual(titles(items), want) {
+		t.Errorf("Fetch() titles = %v, want %v (newest first)", titles(items), want)
+	}
+}
+
+func TestFetchConvertsBody(t *testing.T) {
+	items := fetch(t, models.AppleNotesSourceConfig{FolderTags: true}, time.Time{}, 0)
+
+	groceries := items[1]
+	if strings.Contains(groceries.GetContent(), "Groceries") {
+		t.Errorf("title heading should be dropped from content: %q", groceries.GetContent())
+	}
+
+	if !strings.Contains(groceries.GetContent(), "Milk") || strings.Contains(groceries.GetContent(), "<li>") {
+		t.Errorf("body not converted to markdown: %q", groceries.GetContent())
+	}
+
+	if groceries.GetSourceType() != SourceTypeAppleNotes || groceries.GetItemType() != "note" {
+		t.Errorf("unexpected source/item type %q/%q", groceries.GetSourceType(), groceries.GetItemType())
+	}
+
+	if groceries.GetMetadata()["folder"] != "Home" || groceries.GetMetadata()["account"] != "iCloud" {
+		t.Errorf("unexpected metadata: %v", groceries.GetMetadata())
+	}
+
+	diagram := items[0]
+	if want := []string{"work-projects"}; !reflect.DeepEqual(diagram.GetTags(), want) {
+		t.Errorf("folder tags = %v, want %v", diagram.GetTags(), want)
+	}
+
+	attachments := diagram.GetAttachments()
+	if len(attachments) != 1 || attachments[0].Name != "image-1.png" || attachments[0].Size != 5 {
+		t.Fatalf("inline image not extracted: %+v", attachments)
+	}
+
+	if strings.Contains(diagram.GetContent(), "base64") {
+		t.Errorf("data URI left in content: %q", diagram.GetContent())
+	}
+}
+
+func TestFetchFilters(t *testing.T) {
+	items := fetch(t, models.AppleNotesSourceConfig{Accounts: []string{"icloud"}}, time.Time{}, 0)
+	if want := []string{"Diagram", "Groceries"}; !reflect.DeepEqual(titles(items), want) {
+		t.Errorf("accounts filter returned %v", titles(items))
+	}
+
+	items = fetch(t, models.AppleNotesSourceConfig{ExcludeFolders: []string{"Work Projects"}}, time.Time{}, 0)
+	if want := []string{"Groceries", "Standup"}; !reflect.DeepEqual(titles(items), want) {
+		t.Errorf("exclude_folders filter returned %v", titles(items))
+	}
+
+	items = fetch(t, models.AppleNotesSourceConfig{}, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), 1)
+	if want := []string{"Diagram"}; !reflect.DeepEqual(titles(items), want) {
+		t.Errorf("since and limit returned %v", titles(items))
+	}
+}
@@ -0,0 +1,90 @@
+package bookmarks
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// chromeEpochOffset is the number of microseconds between 1601-01-01, the epoch of Chrome timestamps, and
+// the Unix epoch.
+const chromeEpochOffset = 11644473600000000
+
+// chromeNode is a bookmark or folder in a Chrome-style Bookmarks file.
+type chromeNode struct {
+	Type         string       `json:"type"` // "url" or "folder"
+	Name         string       `json:"name"`
+	URL          string       `json:"url"`
+	DateAdded    string       `json:"date_added"`
+	DateModified string       `json:"date_modified"`
+	Children     []chromeNode `json:"children"`
+}
+
+type chromeBookmarks struct {
+	Roots map[string]json.RawMessage `json:"roots"`
+}
+
+func isChromeBookmarks(data []byte) bool {
+	var file chromeBookmarks
+
+	return json.Unmarshal(data, &file) == nil && len(file.Roots) > 0
+}
+
+// parseChromeBookmarks reads the Bookmarks file of Chrome and other Chromium-based browsers. The root
+// folders (bookmarks bar, other, mobile) are not part of the folder path.
+func parseChromeBookmarks(data []byte) ([]bookmark, error) {
+	var file chromeBookmarks
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse Chrome bookmarks: %w", err)
+	}
+
+	var bookmarks []bookmark
+
+	for _, name := range []string{"bookmark_bar", "other", "synced"} {
+		raw, ok := file.Roots[name]
+		if !ok {
+			continue
+		}
+
+		var root chromeNode
+		if err := json.Unmarshal(raw, &root); err != nil {
+			return nil, fmt.Errorf("failed to parse Chrome bookmarks: %w", err)
+		}
+
+		for _, child := range root.Children {
+			bookmarks = collectChrome(bookmarks, child, nil)
+		}
+	}
+
+	return bookmarks, nil
+}
+
+func collectChrome(bookmarks []bookmark, node chromeNode, folders []string) []bookmark {
+	if node.Type == "folder" {
+		path := append(append([]string{}, folders...), node.Name)
+		for _, child := range node.Children {
+			bookmarks = collectChrome(bookmarks, child, path)
+		}
+
+		return bookmarks
+	}
+
+	return append(bookmarks, bookmark{
+		title:    node.Name,
+		url:      node.URL,
+		folders:  folders,
+		added:    chromeTime(node.DateAdded),
+		modified: chromeTime(node.DateModified),
+	})
+}
+
+// chromeTime converts a Chrome timestamp, microseconds since 1601-01-01 UTC as a string.
+func chromeTime(value string) time.Time {
+	micros, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || micros <= chromeEpochOffset {
+		return time.Time{}
+	}
+
+	return time.UnixMicro(micros - chromeEpochOffset).UTC()
+}
@@ -0,0 +1,241 @@
+package bookmarks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// firefoxRootGUIDs are Firefox's built-in root folders (menu, toolbar, other, mobile); they are not part
+// of folder paths. Bookmarks under the tags root are tag entries, not bookmarks.
+var firefoxRootGUIDs = map[string]bool{
+	"root________": true,
+	"menu________": true,
+	"toolbar_____": true,
+	"unfiled_____": true,
+	"mobile______": true,
+}
+
+const firefoxTagsGUID = "tags________"
+
+// firefoxNode is a bookmark or folder in a Firefox JSON backup.
+type firefoxNode struct {
+	GUID         string        `json:"guid"`
+	Title        string        `json:"title"`
+	TypeCode     int           `json:"typeCode"` // 1 bookmark, 2 folder, 3 separator
+	URI          string        `json:"uri"`
+	DateAdded    int64         `json:"dateAdded"`    // Microseconds since the Unix epoch
+	LastModified int64         `json:"lastModified"` // Microseconds since the Unix epoch
+	Children     []firefoxNode `json:"children"`
+}
+
+// parseFirefoxBackup reads a bookmarks backup made with Firefox's Library > Backup (a .json file).
+func parseFirefoxBackup(data []byte) ([]bookmark, error) {
+	var root firefoxNode
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse Firefox bookmarks backup: %w", err)
+	}
+
+	if root.TypeCode != 2 && len(root.Children) == 0 {
+		return nil, fmt.Errorf("unrecognized bookmarks file: expected a Chrome Bookmarks file or Firefox backup")
+	}
+
+	return collectFirefox(nil, root, nil), nil
+}
+
+func collectFirefox(bookmarks []bookmark, node firefoxNode, folders []string) []bookmark {
+	switch node.TypeCode {
+	case 1:
+		return append(bookmarks, bookmark{
+			title:    node.Title,
+			url:      node.URI,
+			folders:  folders,
+			added:    firefoxTime(node.DateAdded),
+			modified: firefoxTime(node.LastModified),
+		})
+	case 2:
+		if node.GUID == firefoxTagsGUID {
+			return bookmarks
+		}
+
+		path := folders
+		if !firefoxRootGUIDs[node.GUID] {
+			path = append(append([]string{}, folders...), node.Title)
+		}
+
+		for _, child := range node.Children {
+			bookmarks = collectFirefox(bookmarks, child, path)
+		}
+	}
+
+	return bookmarks
+}
+
+func firefoxTime(micros int64) time.Time {
+	if micros <= 0 {
+		return time.Time{}
+	}
+
+	return time.UnixMicro(micros).UTC()
+}
+
+// readFirefoxPlaces reads bookmarks from a Firefox profile's places.sqlite with the sqlite3 command. The
+// database is copied first because a running Firefox keeps it locked.
+func readFirefoxPlaces(path string) ([]bookmark, error) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		return nil, fmt.Errorf("reading a Firefox profile requires the sqlite3 command; " +
+			"export bookmarks to HTML or JSON instead, or install sqlite3")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "pkm-sync-places-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy Firefox bookmarks: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	copyPath := filepath.Join(tmpDir, "places.sqlite")
+
+	// Recent changes may still be in the write-ahead log next to the database
+	for _, suffix := range []string{"", "-wal"} {
+		data, err := os.ReadFile(path + suffix)
+		if err != nil {
+			if suffix != "" && os.IsNotExist(err) {
+				continue
+			}
+
+			return nil, fmt.Errorf("failed to copy Firefox bookmarks: %w", err)
+		}
+
+		if err := os.WriteFile(copyPath+suffix, data, 0600); err != nil {
+			return nil, fmt.Errorf("failed to copy Firefox bookmarks: %w", err)
+		}
+	}
+
+	var folders []struct {
+		ID     int64  `json:"id"`
+		Parent int64  `json:"parent"`
+		GUID   string `json:"guid"`
+		Title  string `json:"title"`
+	}
+
+	err = querySQLite(copyPath, "SELECT id, parent, guid, IFNULL(title, '') AS title FROM moz_bookmarks "+
+		"WHERE type = 2", &folders)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		Parent   int64  `json:"parent"`
+		Title    string `json:"title"`
+		URL      string `json:"url"`
+		Added    int64  `json:"added"`
+		Modified int64  `json:"modified"`
+	}
+
+	err = querySQLite(copyPath, "SELECT b.parent, IFNULL(b.title, '') AS title, p.url, "+
+		"IFNULL(b.dateAdded, 0) AS added, IFNULL(b.lastModified, 0) AS modified "+
+		"FROM moz_bookmarks b JOIN moz_places p ON p.id = b.fk WHERE b.type = 1", &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	type folder struct {
+		parent int64
+		guid   string
+		title  string
+	}
+
+	byID := make(map[int64]folder, len(folders))
+	for _, f := range folders {
+		byID[f.ID] = folder{parent: f.Parent, guid: f.GUID, title: f.Title}
+	}
+
+	var bookmarks []bookmark
+
+rows:
+	for _, row := range rows {
+		var path []string
+
+		// Walk up to the root; the depth bound guards against a corrupt parent cycle
+		for id, depth := row.Parent, 0; depth < 100; depth++ {
+			f, ok := byID[id]
+			if !ok {
+				break
+			}
+
+			if f.guid == firefoxTagsGUID {
+				continue rows
+			}
+
+			if !firefoxRootGUIDs[f.guid] {
+				path = append([]string{f.title}, path...)
+			}
+
+			id = f.parent
+		}
+
+		bookmarks = append(bookmarks, bookmark{
+			title:    row.Title,
+			url:      row.URL,
+			folders:  path,
+			added:    firefoxTime(row.Added),
+			modified: firefoxTime(row.Modified),
+		})
+	}
+
+	return bookmarks, nil
+}
+
+// querySQLite runs a read-only query with the sqlite3 command and decodes its JSON output into rows.
+func querySQLite(path, query string, rows interface{}) error {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command("sqlite3", "-readonly", "-json", path, query)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to query Firefox bookmarks: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	// sqlite3 prints nothing for an empty result
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), rows); err != nil {
+		return fmt.Errorf("failed to parse Firefox bookmarks: %w", err)
+	}
+
+	return nil
+}
+
+// firefoxProfile returns the places.sqlite of the default Firefox profile: the profile folder whose name
+// ends in ".default-release", falling back to ".default".
+func firefoxProfile(home string) (string, error) {
+	var profilesDir string
+
+	switch runtime.GOOS {
+	case "darwin":
+		profilesDir = filepath.Join(home, "Library", "Application Support", "Firefox", "Profiles")
+	case "windows":
+		profilesDir = filepath.Join(home, "AppData", "Roaming", "Mozilla", "Firefox", "Profiles")
+	default:
+		profilesDir = filepath.Join(home, ".mozilla", "firefox")
+	}
+
+	for _, pattern := range []string{"*.default-release", "*.default"} {
+		matches, _ := filepath.Glob(filepath.Join(profilesDir, pattern, "places.sqlite"))
+		if len(matches) > 0 {
+			return matches[0], nil
+		}
+	}
+
+	return "", fmt.Errorf("no Firefox profile found in %s; set path to the profile folder or an export", profilesDir)
+}
@@ -0,0 +1,145 @@
+package bookmarks
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// htmlRootFolderAttrs mark the root folders of Netscape bookmark exports (toolbar, other bookmarks); they
+// are not part of folder paths.
+var htmlRootFolderAttrs = []string{"personal_toolbar_folder", "unfiled_bookmarks_folder"}
+
+// readHTMLBookmarks reads a Netscape bookmark file, the HTML format every browser exports. Folders are <H3>
+// headings followed by a <DL> list holding their bookmarks.
+func readHTMLBookmarks(path string) ([]bookmark, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bookmarks: %w", err)
+	}
+	defer file.Close()
+
+	var (
+		bookmarks []bookmark
+		folders   []string // Open folders; "" for root folders and lists without a heading
+		heading   *string  // Folder heading waiting for its list
+		current   *bookmark
+		text      strings.Builder
+		inHeading bool
+	)
+
+	tokenizer := html.NewTokenizer(file)
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				return nil, fmt.Errorf("failed to parse bookmarks: %w", err)
+			}
+
+			return bookmarks, nil
+		case html.StartTagToken:
+			token := tokenizer.Token()
+
+			switch token.Data {
+			case "h3":
+				inHeading = true
+
+				text.Reset()
+
+				if isRootFolder(token) {
+					empty := ""
+					heading = &empty
+					inHeading = false
+				}
+			case "dl":
+				name := ""
+				if heading != nil {
+					name = *heading
+				}
+
+				folders = append(folders, name)
+				heading = nil
+			case "a":
+				text.Reset()
+
+				current = &bookmark{folders: folderPath(folders)}
+
+				for _, attr := range token.Attr {
+					switch attr.Key {
+					case "href":
+						current.url = attr.Val
+					case "add_date":
+						current.added = unixSeconds(attr.Val)
+					case "last_modified":
+						current.modified = unixSeconds(attr.Val)
+					}
+				}
+			}
+		case html.TextToken:
+			if inHeading || current != nil {
+				text.Write(tokenizer.Text())
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+
+			switch string(name) {
+			case "h3":
+				if inHeading {
+					title := strings.TrimSpace(text.String())
+					heading = &title
+					inHeading = false
+				}
+			case "dl":
+				if len(folders) > 0 {
+					folders = folders[:len(folders)-1]
+				}
+			case "a":
+				if current != nil {
+					current.title = strings.TrimSpace(text.String())
+					bookmarks = append(bookmarks, *current)
+					current = nil
+				}
+			}
+		}
+	}
+}
+
+func isRootFolder(token html.Token) bool {
+	for _, attr := range token.Attr {
+		for _, root := range htmlRootFolderAttrs {
+			if attr.Key == root && strings.EqualFold(attr.Val, "true") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// folderPath drops the unnamed entries of the open folder stack.
+func folderPath(folders []string) []string {
+	var path []string
+
+	for _, folder := range folders {
+		if folder != "" {
+			path = append(path, folder)
+		}
+	}
+
+	return path
+}
+
+func unixSeconds(value string) time.Time {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds <= 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(seconds, 0).UTC()
+}
@@ -0,0 +1,365 @@
+// Package bookmarks implements a source that syncs browser bookmarks as link notes. It reads Chrome-style
+// Bookmarks files, Firefox profiles and JSON backups, and the HTML exports every browser can write.
+package bookmarks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+const (
+	SourceTypeBookmarks = "bookmarks"
+
+	itemTypeBookmark = "bookmark"
+	linkTypeBookmark = "bookmark"
+
+	browserFirefox = "firefox"
+)
+
+// bookmark is a browser bookmark in any of the supported formats.
+type bookmark struct {
+	title    string
+	url      string
+	folders  []string // Folder path below the browser's root folders
+	added    time.Time
+	modified time.Time
+}
+
+type BookmarksSource struct {
+	config   models.SourceConfig
+	sourceID string
+}
+
+func NewBookmarksSourceWithConfig(sourceID string, config models.SourceConfig) *BookmarksSource {
+	return &BookmarksSource{
+		sourceID: sourceID,
+		config:   config,
+	}
+}
+
+func (s *BookmarksSource) Name() string {
+	if s.sourceID != "" {
+		return s.sourceID
+	}
+
+	return SourceTypeBookmarks
+}
+
+// Configure validates the browser; bookmarks are read from local files so no client is needed.
+func (s *BookmarksSource) Configure(_ map[string]interface{}, _ *http.Client) error {
+	return ValidateBrowser(s.config.Bookmarks.Browser)
+}
+
+// ValidateBrowser checks a configured browser name.
+func ValidateBrowser(browser string) error {
+	if _, ok := chromiumProfiles[browser]; ok || browser == "" || browser == browserFirefox {
+		return nil
+	}
+
+	return fmt.Errorf("unsupported browser '%s': supported browsers are 'chrome', 'chromium', 'brave', 'edge', "+
+		"'firefox'", browser)
+}
+
+// Fetch returns bookmarks added or changed after since, newest first, as link notes. Bookmarks of the same
+// URL in several folders become one note carrying the tags of every folder.
+func (s *BookmarksSource) Fetch(since time.Time, limit int) ([]models.FullItem, error) {
+	path, err := s.bookmarksPath()
+	if err != nil {
+		return nil, err
+	}
+
+	bookmarks, err := readBookmarks(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := s.config.Bookmarks
+
+	var (
+		items []models.FullItem
+		byURL = make(map[string]models.FullItem)
+	)
+
+	for _, b := range bookmarks {
+		if !isSyncableURL(b.url) || !inFolders(b.folders, cfg.Folders, cfg.ExcludeFolders) {
+			continue
+		}
+
+		if !since.IsZero() && b.added.Before(since) && b.modified.Before(since) {
+			continue
+		}
+
+		tags := s.folderTags(b.folders)
+
+		if existing, ok := byURL[b.url]; ok {
+			existing.SetTags(appendMissing(existing.GetTags(), tags...))
+
+			continue
+		}
+
+		item := s.toItem(b, tags)
+		byURL[b.url] = item
+		items = append(items, item)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].GetCreatedAt().After(items[j].GetCreatedAt()) })
+
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+
+	return items, nil
+}
+
+func (s *BookmarksSource) Capabilities() interfaces.SourceCapabilities {
+	return interfaces.SourceCapabilities{Incremental: true}
+}
+
+// bookmarksPath returns the configured file, or the bookmarks file of the browser's default profile.
+func (s *BookmarksSource) bookmarksPath() (string, error) {
+	cfg := s.config.Bookmarks
+
+	if cfg.Path != "" {
+		path := cfg.Path
+		if strings.HasPrefix(path, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, path[2:])
+			}
+		}
+
+		return path, nil
+	}
+
+	if cfg.Browser == "" {
+		return "", fmt.Errorf("path or browser is required for bookmarks sources")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to get user home directory: %w", err)
+	}
+
+	if cfg.Browser == browserFirefox {
+		return firefoxProfile(home)
+	}
+
+	return filepath.Join(chromiumProfileDir(home, cfg.Browser), "Default", "Bookmarks"), nil
+}
+
+// readBookmarks reads bookmarks from an HTML export, a Firefox places database or JSON backup, a Chrome
+// Bookmarks file, or a profile folder containing one of those.
+func readBookmarks(path string) ([]bookmark, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bookmarks: %w", err)
+	}
+
+	if info.IsDir() {
+		for _, name := range []string{"places.sqlite", "Bookmarks"} {
+			if _, err := os.Stat(filepath.Join(path, name)); err == nil {
+				return readBookmarks(filepath.Join(path, name))
+			}
+		}
+
+		return nil, fmt.Errorf("no bookmarks file found in %s", path)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return readHTMLBookmarks(path)
+	case ".sqlite":
+		return readFirefoxPlaces(path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bookmarks: %w", err)
+	}
+
+	if isChromeBookmarks(data) {
+		return parseChromeBookmarks(data)
+	}
+
+	return parseFirefoxBackup(data)
+}
+
+// toItem builds the link note of a bookmark.
+func (s *BookmarksSource) toItem(b bookmark, tags []string) models.FullItem {
+	sum := sha256.Sum256([]byte(b.url))
+
+	title := b.title
+	if title == "" {
+		title = b.url
+	}
+
+	item := models.NewBasicItem("bookmark_"+hex.EncodeToString(sum[:])[:16], title)
+	item.SetSourceType(SourceTypeBookmarks)
+	item.SetItemType(itemTypeBookmark)
+	item.SetContent(fmt.Sprintf("[%s](%s)", escapeLinkText(title), b.url))
+	item.SetLinks([]models.Link{{URL: b.url, Title: title, Type: linkTypeBookmark}})
+	item.SetTags(tags)
+
+	created := b.added
+	if created.IsZero() {
+		created = b.modified
+	}
+
+	updated := b.modified
+	if updated.Before(created) {
+		updated = created
+	}
+
+	item.SetCreatedAt(created)
+	item.SetUpdatedAt(updated)
+
+	metadata := map[string]interface{}{"url": b.url}
+	if u, err := url.Parse(b.url); err == nil && u.Hostname() != "" {
+		metadata["domain"] = strings.TrimPrefix(u.Hostname(), "www.")
+	}
+
+	if len(b.folders) > 0 {
+		metadata["folder"] = strings.Join(b.folders, "/")
+	}
+
+	if s.config.Bookmarks.Browser != "" {
+		metadata["browser"] = s.config.Bookmarks.Browser
+	}
+
+	item.SetMetadata(metadata)
+
+	return item
+}
+
+// folderTags maps a bookmark's folders to tags with folder_tags: folder_tag_map entries first, otherwise the
+// folder name in lowercase with spaces as dashes, each with tag_prefix.
+func (s *BookmarksSource) folderTags(folders []string) []string {
+	cfg := s.config.Bookmarks
+	if !cfg.FolderTags {
+		return nil
+	}
+
+	var tags []string
+
+	for _, folder := range folders {
+		tag, mapped := cfg.FolderTagMap[folder]
+		if !mapped {
+			tag = strings.Join(strings.Fields(strings.ToLower(folder)), "-")
+		}
+
+		if tag != "" {
+			tags = appendMissing(tags, cfg.TagPrefix+tag)
+		}
+	}
+
+	return tags
+}
+
+// inFolders reports whether a folder path is under one of include (all when empty) and none of exclude.
+// Folder paths are matched case-insensitively by whole folder names, e.g. "Dev" matches "Dev/Go".
+func inFolders(folders, include, exclude []string) bool {
+	path := strings.ToLower(strings.Join(folders, "/")) + "/"
+
+	under := func(prefix string) bool {
+		return strings.HasPrefix(path, strings.ToLower(strings.Trim(prefix, "/"))+"/")
+	}
+
+	for _, prefix := range exclude {
+		if under(prefix) {
+			return false
+		}
+	}
+
+	if len(include) == 0 {
+		return true
+	}
+
+	for _, prefix := range include {
+		if under(prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isSyncableURL skips bookmarklets, Firefox smart folders and browser-internal pages.
+func isSyncableURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https" || u.Scheme == "ftp")
+}
+
+func escapeLinkText(text string) string {
+	return strings.NewReplacer("[", `\[`, "]", `\]`).Replace(text)
+}
+
+func appendMissing(values []string, additions ...string) []string {
+	for _, addition := range additions {
+		found := false
+
+		for _, value := range values {
+			if value == addition {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			values = append(values, addition)
+		}
+	}
+
+	return values
+}
+
+// chromiumProfiles are the profile folders of Chromium-based browsers per operating system, relative to
+// the home directory.
+var chromiumProfiles = map[string]map[string]string{
+	"chrome": {
+		"darwin":  "Library/Application Support/Google/Chrome",
+		"windows": "AppData/Local/Google/Chrome/User Data",
+		"linux":   ".config/google-chrome",
+	},
+	"chromium": {
+		"darwin":  "Library/Application Support/Chromium",
+		"windows": "AppData/Local/Chromium/User Data",
+		"linux":   ".config/chromium",
+	},
+	"brave": {
+		"darwin":  "Library/Application Support/BraveSoftware/Brave-Browser",
+		"windows": "AppData/Local/BraveSoftware/Brave-Browser/User Data",
+		"linux":   ".config/BraveSoftware/Brave-Browser",
+	},
+	"edge": {
+		"darwin":  "Library/Application Support/Microsoft Edge",
+		"windows": "AppData/Local/Microsoft/Edge/User Data",
+		"linux":   ".config/microsoft-edge",
+	},
+}
+
+func chromiumProfileDir(home, browser string) string {
+	dirs := chromiumProfiles[browser]
+
+	dir, ok := dirs[runtime.GOOS]
+	if !ok {
+		dir = dirs["linux"]
+	}
+
+	return filepath.Join(home, filepath.FromSlash(dir))
+}
+
+// Ensure BookmarksSource implements Source interface.
+var _ interfaces.Source = (*BookmarksSource)(nil)
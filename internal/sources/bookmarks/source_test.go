@@ -0,0 +1,192 @@
+package bookmarks
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+const chromeFile = `{
+  "roots": {
+    "bookmark_bar": {"type": "folder", "name": "Bookmarks bar", "children": [
+      {"type": "url", "name": "Go", "url": "https://go.dev/", "date_added": "13350000000000000"},
+      {"type": "folder", "name": "Dev Tools", "children": [
+        {"type": "url", "name": "pkg.go.dev", "url": "https://pkg.go.dev/", "date_added": "13360000000000000"},
+        {"type": "url", "name": "Bookmarklet", "url": "javascript:alert(1)", "date_added": "13360000000000000"}
+      ]}
+    ]},
+    "other": {"type": "folder", "name": "Other bookmarks", "children": [
+      {"type": "folder", "name": "Reading", "children": [
+        {"type": "url", "name": "Go again", "url": "https://go.dev/", "date_added": "13340000000000000"}
+      ]}
+    ]}
+  },
+  "version": 1
+}`
+
+const firefoxBackup = `{"guid": "root________", "title": "", "typeCode": 2, "children": [
+  {"guid": "toolbar_____", "title": "toolbar", "typeCode": 2, "children": [
+    {"guid": "a1", "title": "Research", "typeCode": 2, "children": [
+      {"guid": "b1", "title": "arXiv", "typeCode": 1, "uri": "https://arxiv.org/", "dateAdded": 1700000000000000}
+    ]}
+  ]},
+  {"guid": "tags________", "title": "tags", "typeCode": 2, "children": [
+    {"guid": "t1", "title": "science", "typeCode": 2, "children": [
+      {"guid": "b2", "typeCode": 1, "uri": "https://arxiv.org/"}
+    ]}
+  ]}
+]}`
+
+const htmlExport = `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+    <DT><H3 ADD_DATE="1700000000" PERSONAL_TOOLBAR_FOLDER="true">Bookmarks bar</H3>
+    <DL><p>
+        <DT><A HREF="https://news.ycombinator.com/" ADD_DATE="1700000100">Hacker News</A>
+        <DT><H3>Recipes</H3>
+        <DL><p>
+            <DT><A HREF="https://example.com/soup" ADD_DATE="1700000200">Soup &amp; bread</A>
+        </DL><p>
+    </DL><p>
+    <DT><A HREF="https://example.org/" ADD_DATE="1700000300">Top level</A>
+</DL><p>`
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func fetch(t *testing.T, config models.BookmarksSourceConfig, since time.Time) []models.FullItem {
+	t.Helper()
+
+	source := NewBookmarksSourceWithConfig("bookmarks", models.SourceConfig{Type: SourceTypeBookmarks, Bookmarks: config})
+	if err := source.Configure(nil, nil); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	items, err := source.Fetch(since, 0)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	return items
+}
+
+func byTitle(items []models.FullItem) map[string]models.FullItem {
+	titles := make(map[string]models.FullItem)
+	for _, item := range items {
+		titles[item.GetTitle()] = item
+	}
+
+	return titles
+}
+
+func TestFetchChromeBookmarks(t *testing.T) {
+	items := fetch(t, models.BookmarksSourceConfig{
+		Path:         writeFile(t, "Bookmarks", chromeFile),
+		FolderTags:   true,
+		FolderTagMap: map[string]string{"Reading": "to-read"},
+		TagPrefix:    "bookmarks/",
+	}, time.Time{})
+
+	if len(items) != 2 {
+		t.Fatalf("expected 2 link notes (bookmarklet skipped, duplicate merged), got %d", len(items))
+	}
+
+	titles := byTitle(items)
+
+	goItem, ok := titles["Go"]
+	if !ok {
+		t.Fatalf("missing Go bookmark: %v", titles)
+	}
+
+	if goItem.GetContent() != "[Go](https://go.dev/)" || goItem.GetItemType() != "bookmark" {
+		t.Errorf("unexpected Go note: content=%q type=%q", goItem.GetContent(), goItem.GetItemType())
+	}
+
+	if want := []string{"bookmarks/to-read"}; !reflect.DeepEqual(goItem.GetTags(), want) {
+		t.Errorf("duplicate bookmark tags = %v, want %v", goItem.GetTags(), want)
+	}
+
+	pkg := titles["pkg.go.dev"]
+	if want := []string{"bookmarks/dev-tools"}; !reflect.DeepEqual(pkg.GetTags(), want) {
+		t.Errorf("folder tags = %v, want %v", pkg.GetTags(), want)
+	}
+
+	if pkg.GetMetadata()["folder"] != "Dev Tools" || pkg.GetMetadata()["domain"] != "pkg.go.dev" {
+		t.Errorf("unexpected metadata: %v", pkg.GetMetadata())
+	}
+
+	if pkg.GetCreatedAt().Year() != 2024 {
+		t.Errorf("Chrome timestamp converted to %v", pkg.GetCreatedAt())
+	}
+}
+
+func TestFetchFirefoxBackupSkipsTags(t *testing.T) {
+	items := fetch(t, models.BookmarksSourceConfig{
+		Path:       writeFile(t, "bookmarks-2025-01-01.json", firefoxBackup),
+		FolderTags: true,
+	}, time.Time{})
+
+	if len(items) != 1 {
+		t.Fatalf("expected 1 bookmark, got %d", len(items))
+	}
+
+	if items[0].GetTitle() != "arXiv" || !reflect.DeepEqual(items[0].GetTags(), []string{"research"}) {
+		t.Errorf("unexpected bookmark %q tags %v", items[0].GetTitle(), items[0].GetTags())
+	}
+}
+
+func TestFetchHTMLExport(t *testing.T) {
+	items := fetch(t, models.BookmarksSourceConfig{Path: writeFile(t, "bookmarks.html", htmlExport)}, time.Time{})
+
+	titles := byTitle(items)
+	if len(titles) != 3 {
+		t.Fatalf("expected 3 bookmarks, got %v", titles)
+	}
+
+	if soup := titles["Soup & bread"]; soup == nil || soup.GetMetadata()["folder"] != "Recipes" {
+		t.Errorf("nested folder not read: %v", titles)
+	}
+
+	if top := titles["Top level"]; top == nil || top.GetMetadata()["folder"] != nil {
+		t.Errorf("top-level bookmark should have no folder: %v", titles)
+	}
+}
+
+func TestFetchFiltersFoldersAndSince(t *testing.T) {
+	path := writeFile(t, "bookmarks.html", htmlExport)
+
+	items := fetch(t, models.BookmarksSourceConfig{Path: path, Folders: []string{"recipes"}}, time.Time{})
+	if len(items) != 1 || items[0].GetTitle() != "Soup & bread" {
+		t.Errorf("folders filter returned %v", byTitle(items))
+	}
+
+	items = fetch(t, models.BookmarksSourceConfig{Path: path, ExcludeFolders: []string{"Recipes"}},
+		time.Unix(1700000150, 0))
+	if len(items) != 1 || items[0].GetTitle() != "Top level" {
+		t.Errorf("since and exclude_folders returned %v", byTitle(items))
+	}
+}
+
+func TestConfigureRejectsUnknownBrowser(t *testing.T) {
+	source := NewBookmarksSourceWithConfig("bookmarks", models.SourceConfig{
+		Type:      SourceTypeBookmarks,
+		Bookmarks: models.BookmarksSourceConfig{Browser: "netscape"},
+	})
+
+	if err := source.Configure(nil, nil); err == nil {
+		t.Error("Configure() expected an error for an unknown browser")
+	}
+}
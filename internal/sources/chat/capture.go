@@ -0,0 +1,147 @@
+// Package chat holds behaviour shared by chat sources (Slack, Teams and the like), starting with
+// saved-items and reaction capture: syncing only the messages someone marked as worth keeping.
+package chat
+
+import (
+	"fmt"
+	"strings"
+
+	"pkm-sync/pkg/models"
+)
+
+const (
+	ReactedByMe     = "me"
+	ReactedByAnyone = "anyone"
+
+	// MetadataCapturedBy is the item metadata key holding why a message was captured: CaptureSaved or
+	// CaptureReaction
+	MetadataCapturedBy = "captured_by"
+	CaptureSaved       = "saved"
+	CaptureReaction    = "reaction"
+)
+
+// shortcodes maps the shortcodes chat services use for common capture reactions to their emoji, so either
+// form can be configured and matched.
+var shortcodes = map[string]string{
+	"pushpin":          "📌",
+	"round_pushpin":    "📍",
+	"bookmark":         "🔖",
+	"star":             "⭐",
+	"heart":            "❤",
+	"+1":               "👍",
+	"thumbsup":         "👍",
+	"like":             "👍",
+	"white_check_mark": "✅",
+	"eyes":             "👀",
+	"memo":             "📝",
+	"bulb":             "💡",
+	"inbox_tray":       "📥",
+	"laugh":            "😆",
+	"surprised":        "😮",
+	"sad":              "😢",
+	"angry":            "😡",
+}
+
+// Reaction is one emoji on a message and the IDs of the users who added it.
+type Reaction struct {
+	Emoji string
+	Users []string
+}
+
+// Message is the part of a chat message capture decisions look at.
+type Message struct {
+	Saved     bool
+	Reactions []Reaction
+}
+
+// Capture decides which chat messages to keep under a ChatCaptureConfig.
+type Capture struct {
+	savedOnly bool
+	reactions map[string]bool
+	anyone    bool
+}
+
+// ValidateCapture checks the reacted_by setting.
+func ValidateCapture(config models.ChatCaptureConfig) error {
+	switch config.ReactedBy {
+	case "", ReactedByMe, ReactedByAnyone:
+		return nil
+	default:
+		return fmt.Errorf("unsupported reacted_by '%s': supported values are '%s', '%s'",
+			config.ReactedBy, ReactedByMe, ReactedByAnyone)
+	}
+}
+
+func NewCapture(config models.ChatCaptureConfig) *Capture {
+	capture := &Capture{
+		savedOnly: config.SavedOnly,
+		reactions: make(map[string]bool, len(config.Reactions)),
+		anyone:    config.ReactedBy == ReactedByAnyone,
+	}
+
+	for _, reaction := range config.Reactions {
+		if emoji := NormalizeEmoji(reaction); emoji != "" {
+			capture.reactions[emoji] = true
+		}
+	}
+
+	return capture
+}
+
+// Enabled reports whether messages are filtered at all. Sources can use it to fetch saved items directly
+// instead of walking every channel.
+func (c *Capture) Enabled() bool {
+	return c.savedOnly || len(c.reactions) > 0
+}
+
+// SavedOnly reports whether only saved messages are wanted, with no reaction capture.
+func (c *Capture) SavedOnly() bool {
+	return c.savedOnly && len(c.reactions) == 0
+}
+
+// Match reports whether msg is captured and why. self is the ID of the authenticated user, whose reactions
+// count when reacted_by is "me". Every message matches when capture is not enabled.
+func (c *Capture) Match(msg Message, self string) (string, bool) {
+	if !c.Enabled() {
+		return "", true
+	}
+
+	if c.savedOnly && msg.Saved {
+		return CaptureSaved, true
+	}
+
+	for _, reaction := range msg.Reactions {
+		if !c.reactions[NormalizeEmoji(reaction.Emoji)] {
+			continue
+		}
+
+		if c.anyone && len(reaction.Users) > 0 {
+			return CaptureReaction, true
+		}
+
+		for _, user := range reaction.Users {
+			if user == self {
+				return CaptureReaction, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// NormalizeEmoji turns a shortcode such as ":pushpin:" or "pushpin" into its emoji and strips skin tone
+// modifiers (Slack's "::skin-tone-2") and variation selectors, so reactions compare equal whichever form a
+// service reports.
+func NormalizeEmoji(name string) string {
+	name = strings.TrimSpace(name)
+	if before, _, found := strings.Cut(name, "::"); found {
+		name = before
+	}
+
+	name = strings.Trim(name, ":")
+	if emoji, ok := shortcodes[strings.ToLower(name)]; ok {
+		name = emoji
+	}
+
+	return strings.TrimRight(name, "\uFE0F\U0001F3FB\U0001F3FC\U0001F3FD\U0001F3FE\U0001F3FF")
+}
@@ -0,0 +1,75 @@
+package chat
+
+import (
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestCaptureMatch(t *testing.T) {
+	pinnedByMe := Message{Reactions: []Reaction{{Emoji: "pushpin", Users: []string{"U1"}}}}
+	pinnedByOther := Message{Reactions: []Reaction{{Emoji: "📌", Users: []string{"U2"}}}}
+	saved := Message{Saved: true}
+	plain := Message{Reactions: []Reaction{{Emoji: "tada", Users: []string{"U1"}}}}
+
+	tests := []struct {
+		name   string
+		config models.ChatCaptureConfig
+		msg    Message
+		reason string
+		want   bool
+	}{
+		{"disabled keeps everything", models.ChatCaptureConfig{}, plain, "", true},
+		{"saved only", models.ChatCaptureConfig{SavedOnly: true}, saved, CaptureSaved, true},
+		{"saved only skips unsaved", models.ChatCaptureConfig{SavedOnly: true}, pinnedByMe, "", false},
+		{"my reaction", models.ChatCaptureConfig{Reactions: []string{"📌"}}, pinnedByMe, CaptureReaction, true},
+		{"other user's reaction", models.ChatCaptureConfig{Reactions: []string{":pushpin:"}}, pinnedByOther, "", false},
+		{
+			"anyone's reaction",
+			models.ChatCaptureConfig{Reactions: []string{":pushpin:"}, ReactedBy: ReactedByAnyone},
+			pinnedByOther, CaptureReaction, true,
+		},
+		{"other emoji", models.ChatCaptureConfig{Reactions: []string{"📌"}}, plain, "", false},
+		{
+			"saved or reacted",
+			models.ChatCaptureConfig{SavedOnly: true, Reactions: []string{"📌"}},
+			pinnedByMe, CaptureReaction, true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, ok := NewCapture(tt.config).Match(tt.msg, "U1")
+			if ok != tt.want || reason != tt.reason {
+				t.Errorf("Match() = %q, %v; want %q, %v", reason, ok, tt.reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeEmoji(t *testing.T) {
+	tests := map[string]string{
+		":pushpin:":             "📌",
+		"thumbsup::skin-tone-3": "👍",
+		"👍🏽":                    "👍",
+		"❤️":                    "❤",
+		"heart":                 "❤",
+		"partyparrot":           "partyparrot",
+	}
+
+	for input, want := range tests {
+		if got := NormalizeEmoji(input); got != want {
+			t.Errorf("NormalizeEmoji(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestValidateCapture(t *testing.T) {
+	if err := ValidateCapture(models.ChatCaptureConfig{ReactedBy: "team"}); err == nil {
+		t.Error("expected an error for an unknown reacted_by value")
+	}
+
+	if err := ValidateCapture(models.ChatCaptureConfig{ReactedBy: ReactedByAnyone}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
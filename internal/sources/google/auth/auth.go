@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"pkm-sync/internal/config"
+	"pkm-sync/internal/sources/google/quota"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -18,21 +19,42 @@ import (
 	"google.golang.org/api/gmail/v1"
 )
 
+// quotaBudget meters the requests of the clients created while it is set.
+var quotaBudget *quota.Budget
+
+// UseQuotaBudget makes the clients created from now on count their requests against budget; nil stops
+// metering new clients.
+func UseQuotaBudget(budget *quota.Budget) {
+	quotaBudget = budget
+}
+
 func GetClient() (*http.Client, error) {
-	config, err := getOAuthConfig()
+	tokenPath, err := config.GetTokenPath()
+	if err != nil {
+		return nil, err
+	}
+
+	return GetClientWithScopes(tokenPath, calendar.CalendarReadonlyScope, drive.DriveReadonlyScope, gmail.GmailReadonlyScope)
+}
+
+// GetClientWithScopes authorizes a client for the given scopes and caches its token at tokenPath. Sources that
+// need other permissions than the shared read-only token use their own token, so granting them does not
+// invalidate the shared one.
+func GetClientWithScopes(tokenPath string, scopes ...string) (*http.Client, error) {
+	config, err := getOAuthConfig(scopes...)
 	if err != nil {
 		return nil, fmt.Errorf("unable to get OAuth config: %w", err)
 	}
 
-	token, err := getToken(config)
+	token, err := getToken(config, tokenPath)
 	if err != nil {
 		return nil, fmt.Errorf("unable to get token: %w", err)
 	}
 
-	return config.Client(context.Background(), token), nil
+	return quotaBudget.Client(config.Client(context.Background(), token)), nil
 }
 
-func getOAuthConfig() (*oauth2.Config, error) {
+func getOAuthConfig(scopes ...string) (*oauth2.Config, error) {
 	credentialsPath, err := config.FindCredentialsFile()
 	if err != nil {
 		return nil, fmt.Errorf("unable to find credentials file: %w", err)
@@ -43,11 +65,7 @@ func getOAuthConfig() (*oauth2.Config, error) {
 		return nil, fmt.Errorf("unable to read client secret file: %w", err)
 	}
 
-	oauthConfig, err := google.ConfigFromJSON(b,
-		calendar.CalendarReadonlyScope,
-		drive.DriveReadonlyScope,
-		gmail.GmailReadonlyScope,
-	)
+	oauthConfig, err := google.ConfigFromJSON(b, scopes...)
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse client secret file to config: %w", err)
 	}
@@ -55,8 +73,8 @@ func getOAuthConfig() (*oauth2.Config, error) {
 	return oauthConfig, nil
 }
 
-func getToken(oauthConfig *oauth2.Config) (*oauth2.Token, error) {
-	token, err := tokenFromFile()
+func getToken(oauthConfig *oauth2.Config, tokenPath string) (*oauth2.Token, error) {
+	token, err := tokenFromFile(tokenPath)
 	if err != nil {
 		// No existing token, get new one
 		token, err = getTokenFromWeb(oauthConfig)
@@ -64,7 +82,7 @@ func getToken(oauthConfig *oauth2.Config) (*oauth2.Token, error) {
 			return nil, err
 		}
 
-		if err := saveToken(token); err != nil {
+		if err := saveToken(tokenPath, token); err != nil {
 			return nil, fmt.Errorf("unable to save token: %w", err)
 		}
 
@@ -82,7 +100,7 @@ func getToken(oauthConfig *oauth2.Config) (*oauth2.Token, error) {
 			return nil, err
 		}
 
-		if err := saveToken(token); err != nil {
+		if err := saveToken(tokenPath, token); err != nil {
 			return nil, fmt.Errorf("unable to save token: %w", err)
 		}
 	}
@@ -159,12 +177,7 @@ func extractAuthCode(input string) string {
 	return ""
 }
 
-func tokenFromFile() (*oauth2.Token, error) {
-	tokenPath, err := config.GetTokenPath()
-	if err != nil {
-		return nil, err
-	}
-
+func tokenFromFile(tokenPath string) (*oauth2.Token, error) {
 	f, err := os.Open(tokenPath)
 	if err != nil {
 		return nil, err
@@ -182,12 +195,7 @@ func tokenFromFile() (*oauth2.Token, error) {
 	return token, err
 }
 
-func saveToken(token *oauth2.Token) error {
-	tokenPath, err := config.GetTokenPath()
-	if err != nil {
-		return fmt.Errorf("unable to get token path: %w", err)
-	}
-
+func saveToken(tokenPath string, token *oauth2.Token) error {
 	fmt.Printf("Saving credential file to: %s\n", tokenPath)
 
 	f, err := os.OpenFile(tokenPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
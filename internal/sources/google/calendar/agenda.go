@@ -0,0 +1,137 @@
+package calendar
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	// agendaHeadingPattern matches the line introducing an agenda, such as "Agenda:", "**Agenda**" or "## Topics".
+	agendaHeadingPattern = regexp.MustCompile(`(?i)^[#*_\s]*(agenda|topics)[*_\s]*:?[*_\s]*$`)
+
+	// agendaItemPattern matches a bulleted or numbered list line, capturing its indentation, marker and text.
+	agendaItemPattern = regexp.MustCompile(`^(\s*)([-*•·]|\d+[.)])\s+(.+)$`)
+
+	htmlTagPattern  = regexp.MustCompile(`(?s)<(/?)([a-zA-Z0-9]+)[^>]*>`)
+	htmlHrefPattern = regexp.MustCompile(`(?i)href\s*=\s*["']([^"']+)["']`)
+)
+
+// ExtractAgenda returns the agenda of an event description as markdown list lines, nested items indented
+// by two spaces per level. The agenda is the list following an "Agenda" or "Topics" line, or the whole
+// description when it is nothing but a list. Descriptions may be plain text, markdown or the HTML the
+// Calendar editor writes.
+func ExtractAgenda(description string) []string {
+	lines := strings.Split(descriptionText(description), "\n")
+
+	start := -1
+
+	for i, line := range lines {
+		if agendaHeadingPattern.MatchString(line) {
+			start = i + 1
+
+			break
+		}
+	}
+
+	if start < 0 {
+		for _, line := range lines {
+			if strings.TrimSpace(line) != "" && !agendaItemPattern.MatchString(line) {
+				return nil
+			}
+		}
+
+		start = 0
+	}
+
+	var (
+		agenda  []string
+		indents []int // Indentation of each open nesting level
+	)
+
+	for _, line := range lines[start:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		match := agendaItemPattern.FindStringSubmatch(line)
+		if match == nil {
+			if len(agenda) > 0 {
+				break
+			}
+
+			continue
+		}
+
+		indent := len(strings.ReplaceAll(match[1], "\t", "    "))
+		for len(indents) > 0 && indents[len(indents)-1] > indent {
+			indents = indents[:len(indents)-1]
+		}
+
+		if len(indents) == 0 || indents[len(indents)-1] < indent {
+			indents = append(indents, indent)
+		}
+
+		// Numbered items keep their number, bullets of any kind become dashes
+		marker := "-"
+		if number := strings.TrimRight(match[2], ".)"); number != match[2] {
+			marker = number + "."
+		}
+
+		agenda = append(agenda, strings.Repeat("  ", len(indents)-1)+marker+" "+strings.TrimSpace(match[3]))
+	}
+
+	return agenda
+}
+
+// descriptionText turns an HTML description into plain text lines, keeping list items as bulleted lines
+// indented by their nesting and links as markdown links. Text without tags is returned unchanged.
+func descriptionText(description string) string {
+	if !htmlTagPattern.MatchString(description) {
+		return description
+	}
+
+	var (
+		sb    strings.Builder
+		depth int
+		href  string
+	)
+
+	last := 0
+
+	for _, loc := range htmlTagPattern.FindAllStringSubmatchIndex(description, -1) {
+		sb.WriteString(html.UnescapeString(description[last:loc[0]]))
+		last = loc[1]
+
+		closing := description[loc[2]:loc[3]] == "/"
+		tag := strings.ToLower(description[loc[4]:loc[5]])
+
+		switch {
+		case (tag == "ul" || tag == "ol") && !closing:
+			depth++
+		case tag == "ul" || tag == "ol":
+			depth = max(depth-1, 0)
+
+			sb.WriteString("\n")
+		case tag == "li" && !closing:
+			sb.WriteString("\n" + strings.Repeat("  ", max(depth-1, 0)) + "- ")
+		case tag == "a" && !closing:
+			if match := htmlHrefPattern.FindStringSubmatch(description[loc[0]:loc[1]]); match != nil {
+				href = html.UnescapeString(match[1])
+
+				sb.WriteString("[")
+			}
+		case tag == "a":
+			if href != "" {
+				sb.WriteString("](" + href + ")")
+				href = ""
+			}
+		case tag == "br" || tag == "p" || tag == "div" || tag == "li":
+			sb.WriteString("\n")
+		}
+	}
+
+	sb.WriteString(html.UnescapeString(description[last:]))
+
+	return sb.String()
+}
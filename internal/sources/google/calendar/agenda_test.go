@@ -0,0 +1,87 @@
+package calendar
+
+import (
+	"slices"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestExtractAgenda(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		want        []string
+	}{
+		{
+			name:        "plain text after heading",
+			description: "Weekly sync.\n\nAgenda:\n- Roadmap\n  * Q3 goals\n- Hiring\n\nJoin with Meet.",
+			want:        []string{"- Roadmap", "  - Q3 goals", "- Hiring"},
+		},
+		{
+			name:        "numbered markdown heading",
+			description: "## Topics\n1. Intro\n2) Demo",
+			want:        []string{"1. Intro", "2. Demo"},
+		},
+		{
+			name: "calendar editor html",
+			description: `<b>Agenda</b><br><ul><li>Review <a href="https://docs.google.com/document/d/abc123/edit">` +
+				`the plan</a></li><li>Budget<ul><li>Travel &amp; events</li></ul></li></ul><br>Notes to follow`,
+			want: []string{
+				"- Review [the plan](https://docs.google.com/document/d/abc123/edit)",
+				"- Budget",
+				"  - Travel & events",
+			},
+		},
+		{
+			name:        "description that is only a list",
+			description: "• Status\n• Blockers",
+			want:        []string{"- Status", "- Blockers"},
+		},
+		{
+			name:        "no agenda",
+			description: "Quick chat about the launch.\n- bring laptops",
+			want:        nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractAgenda(tt.description); !slices.Equal(got, tt.want) {
+				t.Errorf("ExtractAgenda() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_ConvertToModelWithDriveLinkedDocs(t *testing.T) {
+	event := &calendar.Event{
+		Id:      "event",
+		Summary: "Planning",
+		Description: "Agenda:\n- Plan\n\nDoc: https://docs.google.com/document/d/doc1/edit and " +
+			`<a href="https://drive.google.com/file/d/file2/view">budget.xlsx</a>`,
+		Start:       &calendar.EventDateTime{DateTime: "2025-03-12T10:00:00Z"},
+		End:         &calendar.EventDateTime{DateTime: "2025-03-12T11:00:00Z"},
+		Attachments: []*calendar.EventAttachment{{FileId: "doc1", Title: "Plan", FileUrl: "https://docs.google.com/document/d/doc1"}},
+	}
+
+	if got := (&Service{}).ConvertToModelWithDrive(event); len(got.Attachments) != 1 || got.Agenda != nil {
+		t.Fatalf("without extract_agenda: attachments = %v, agenda = %v", got.Attachments, got.Agenda)
+	}
+
+	service := &Service{}
+	service.SetExtractAgenda(true)
+
+	got := service.ConvertToModelWithDrive(event)
+	if !slices.Equal(got.Agenda, []string{"- Plan"}) {
+		t.Errorf("Agenda = %q, want [- Plan]", got.Agenda)
+	}
+
+	if len(got.Attachments) != 2 {
+		t.Fatalf("Attachments = %v, want the native one and the linked Drive file", got.Attachments)
+	}
+
+	if linked := got.Attachments[1]; linked.FileID != "file2" || linked.Title != "budget.xlsx" {
+		t.Errorf("linked attachment = %+v, want file2 titled budget.xlsx", linked)
+	}
+}
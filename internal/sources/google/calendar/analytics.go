@@ -0,0 +1,340 @@
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/models"
+)
+
+// Analytics periods for GoogleSourceConfig.Analytics.
+const (
+	AnalyticsWeek  = "week"
+	AnalyticsMonth = "month"
+
+	// AnalyticsItemType is the item type of generated analytics notes.
+	AnalyticsItemType = "calendar_analytics"
+
+	maxTopCollaborators = 10
+	maxBusiestDays      = 5
+)
+
+// ValidateAnalyticsPeriods checks analytics values against the supported periods.
+func ValidateAnalyticsPeriods(periods []string) error {
+	for _, period := range periods {
+		switch period {
+		case AnalyticsWeek, AnalyticsMonth:
+		default:
+			return fmt.Errorf("unsupported analytics period '%s': supported periods are 'week', 'month'", period)
+		}
+	}
+
+	return nil
+}
+
+// CollaboratorStats counts the meetings shared with one person.
+type CollaboratorStats struct {
+	Name     string  `json:"name"`
+	Email    string  `json:"email,omitempty"`
+	Meetings int     `json:"meetings"`
+	Hours    float64 `json:"hours"`
+}
+
+// DayStats counts the meetings of one day.
+type DayStats struct {
+	Date     string  `json:"date"`
+	Meetings int     `json:"meetings"`
+	Hours    float64 `json:"hours"`
+}
+
+// PeriodAnalytics summarizes the meetings of one week or month.
+type PeriodAnalytics struct {
+	Period        string              `json:"period"`
+	Label         string              `json:"label"`
+	Start         time.Time           `json:"start"`
+	End           time.Time           `json:"end"`
+	Meetings      int                 `json:"meetings"`
+	Hours         float64             `json:"hours"`
+	Collaborators []CollaboratorStats `json:"collaborators"`
+	BusiestDays   []DayStats          `json:"busiest_days"`
+}
+
+// ComputeAnalytics groups timed events into periods and summarizes each one, oldest period first.
+// All-day events, which have no start time of day, are not counted.
+func ComputeAnalytics(events []*models.CalendarEvent, period string) []PeriodAnalytics {
+	byPeriod := make(map[time.Time]*periodAccumulator)
+
+	for _, event := range events {
+		if event.IsAllDay || event.Start.IsZero() {
+			continue
+		}
+
+		start := periodStart(event.Start, period)
+
+		acc, exists := byPeriod[start]
+		if !exists {
+			acc = newPeriodAccumulator(period, start)
+			byPeriod[start] = acc
+		}
+
+		acc.add(event)
+	}
+
+	starts := make([]time.Time, 0, len(byPeriod))
+	for start := range byPeriod {
+		starts = append(starts, start)
+	}
+
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+	summaries := make([]PeriodAnalytics, 0, len(starts))
+	for _, start := range starts {
+		summaries = append(summaries, byPeriod[start].summary())
+	}
+
+	return summaries
+}
+
+// AnalyticsItems builds one analytics item per period with meetings, for each requested period kind.
+func AnalyticsItems(events []*models.CalendarEvent, periods []string) []*models.Item {
+	var items []*models.Item
+
+	for _, period := range periods {
+		for _, summary := range ComputeAnalytics(events, period) {
+			items = append(items, summary.ToItem())
+		}
+	}
+
+	return items
+}
+
+// AnalyticsWindow widens the sync window from since to until to whole periods of each requested kind, so
+// the periods it touches are summarized from all their meetings rather than only those inside the window.
+func AnalyticsWindow(since, until time.Time, periods []string) (time.Time, time.Time) {
+	for _, period := range periods {
+		if start := periodStart(since, period); start.Before(since) {
+			since = start
+		}
+
+		if end := periodEnd(periodStart(until.Add(-time.Nanosecond), period), period); end.After(until) {
+			until = end
+		}
+	}
+
+	return since, until
+}
+
+// ToItem renders the summary as a note with tables and a JSON data block for charting plugins.
+func (a PeriodAnalytics) ToItem() *models.Item {
+	return &models.Item{
+		ID:         fmt.Sprintf("calendar-analytics-%s-%s", a.Period, a.Label),
+		Title:      "Meeting Analytics " + a.Label,
+		Content:    a.markdown(),
+		SourceType: "google_calendar",
+		ItemType:   AnalyticsItemType,
+		CreatedAt:  a.Start,
+		UpdatedAt:  a.Start,
+		Tags:       []string{"calendar-analytics"},
+		Metadata: map[string]interface{}{
+			"period":        a.Period,
+			"period_start":  a.Start,
+			"period_end":    a.End,
+			"meeting_count": a.Meetings,
+			"meeting_hours": a.Hours,
+		},
+	}
+}
+
+func (a PeriodAnalytics) markdown() string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("%d meetings, %s hours between %s and %s.\n\n", a.Meetings, formatHours(a.Hours),
+		a.Start.Format("2006-01-02"), a.End.AddDate(0, 0, -1).Format("2006-01-02")))
+
+	sb.WriteString("## Top Collaborators\n\n")
+	sb.WriteString("| Person | Meetings | Hours |\n|--------|----------|-------|\n")
+
+	for _, c := range a.Collaborators {
+		sb.WriteString(fmt.Sprintf("| %s | %d | %s |\n", utils.EscapeTableCell(c.Name), c.Meetings, formatHours(c.Hours)))
+	}
+
+	sb.WriteString("\n## Busiest Days\n\n")
+	sb.WriteString("| Day | Meetings | Hours |\n|-----|----------|-------|\n")
+
+	for _, d := range a.BusiestDays {
+		sb.WriteString(fmt.Sprintf("| %s | %d | %s |\n", d.Date, d.Meetings, formatHours(d.Hours)))
+	}
+
+	sb.WriteString("\n## Data\n\n```json\n")
+
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err == nil {
+		sb.Write(data)
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("```\n")
+
+	return sb.String()
+}
+
+// periodAccumulator collects the totals of a single period.
+type periodAccumulator struct {
+	period        string
+	start         time.Time
+	meetings      int
+	hours         float64
+	collaborators map[string]*CollaboratorStats
+	days          map[string]*DayStats
+}
+
+func newPeriodAccumulator(period string, start time.Time) *periodAccumulator {
+	return &periodAccumulator{
+		period:        period,
+		start:         start,
+		collaborators: make(map[string]*CollaboratorStats),
+		days:          make(map[string]*DayStats),
+	}
+}
+
+func (p *periodAccumulator) add(event *models.CalendarEvent) {
+	hours := 0.0
+	if event.End.After(event.Start) {
+		hours = event.End.Sub(event.Start).Hours()
+	}
+
+	p.meetings++
+	p.hours += hours
+
+	date := event.Start.Format("2006-01-02")
+
+	day, exists := p.days[date]
+	if !exists {
+		day = &DayStats{Date: date}
+		p.days[date] = day
+	}
+
+	day.Meetings++
+	day.Hours += hours
+
+	seen := make(map[string]bool)
+
+	for _, attendee := range append([]models.Attendee{event.Organizer}, event.Attendees...) {
+		key := strings.ToLower(attendee.Email)
+		if attendee.Self || key == "" || seen[key] {
+			continue
+		}
+
+		seen[key] = true
+
+		c, exists := p.collaborators[key]
+		if !exists {
+			c = &CollaboratorStats{Name: attendee.GetDisplayName(), Email: attendee.Email}
+			p.collaborators[key] = c
+		}
+
+		c.Meetings++
+		c.Hours += hours
+	}
+}
+
+func (p *periodAccumulator) summary() PeriodAnalytics {
+	summary := PeriodAnalytics{
+		Period:   p.period,
+		Label:    periodLabel(p.start, p.period),
+		Start:    p.start,
+		End:      periodEnd(p.start, p.period),
+		Meetings: p.meetings,
+		Hours:    roundHours(p.hours),
+	}
+
+	for _, c := range p.collaborators {
+		c.Hours = roundHours(c.Hours)
+		summary.Collaborators = append(summary.Collaborators, *c)
+	}
+
+	sort.Slice(summary.Collaborators, func(i, j int) bool {
+		a, b := summary.Collaborators[i], summary.Collaborators[j]
+		if a.Meetings != b.Meetings {
+			return a.Meetings > b.Meetings
+		}
+
+		if a.Hours != b.Hours {
+			return a.Hours > b.Hours
+		}
+
+		return a.Name < b.Name
+	})
+
+	if len(summary.Collaborators) > maxTopCollaborators {
+		summary.Collaborators = summary.Collaborators[:maxTopCollaborators]
+	}
+
+	for _, d := range p.days {
+		d.Hours = roundHours(d.Hours)
+		summary.BusiestDays = append(summary.BusiestDays, *d)
+	}
+
+	sort.Slice(summary.BusiestDays, func(i, j int) bool {
+		a, b := summary.BusiestDays[i], summary.BusiestDays[j]
+		if a.Hours != b.Hours {
+			return a.Hours > b.Hours
+		}
+
+		if a.Meetings != b.Meetings {
+			return a.Meetings > b.Meetings
+		}
+
+		return a.Date < b.Date
+	})
+
+	if len(summary.BusiestDays) > maxBusiestDays {
+		summary.BusiestDays = summary.BusiestDays[:maxBusiestDays]
+	}
+
+	return summary
+}
+
+// periodStart returns the first day of the week (Monday) or month containing t, in t's location.
+func periodStart(t time.Time, period string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+
+	if period == AnalyticsMonth {
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// periodEnd returns the exclusive end of the period starting at start.
+func periodEnd(start time.Time, period string) time.Time {
+	if period == AnalyticsMonth {
+		return start.AddDate(0, 1, 0)
+	}
+
+	return start.AddDate(0, 0, 7)
+}
+
+// periodLabel names a period: ISO week ("2025-W03") or month ("2025-01").
+func periodLabel(start time.Time, period string) string {
+	if period == AnalyticsMonth {
+		return start.Format("2006-01")
+	}
+
+	year, week := start.ISOWeek()
+
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+func roundHours(hours float64) float64 {
+	return math.Round(hours*100) / 100
+}
+
+func formatHours(hours float64) string {
+	return fmt.Sprintf("%.1f", hours)
+}
@@ -0,0 +1,97 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestComputeAnalytics(t *testing.T) {
+	at := func(d, h int) time.Time { return time.Date(2025, 1, d, h, 0, 0, 0, time.UTC) }
+	self := models.Attendee{Email: "me@example.com", Self: true}
+	alice := models.Attendee{Email: "alice@example.com", DisplayName: "Alice"}
+	bob := models.Attendee{Email: "bob@example.com", DisplayName: "Bob"}
+
+	events := []*models.CalendarEvent{
+		// Week 2025-W03 starts on Monday 13 January
+		{ID: "a", Start: at(13, 9), End: at(13, 10), Organizer: alice, Attendees: []models.Attendee{self, alice, bob}},
+		{ID: "b", Start: at(15, 9), End: at(15, 12), Attendees: []models.Attendee{self, alice}},
+		{ID: "c", Start: at(20, 9), End: at(20, 10), Attendees: []models.Attendee{self, bob}},
+		{ID: "all-day", Summary: "Holiday"},
+	}
+
+	weeks := ComputeAnalytics(events, AnalyticsWeek)
+	if len(weeks) != 2 {
+		t.Fatalf("ComputeAnalytics(week) returned %d periods, want 2", len(weeks))
+	}
+
+	week := weeks[0]
+	if week.Label != "2025-W03" || week.Meetings != 2 || week.Hours != 4 || !week.Start.Equal(at(13, 0)) {
+		t.Errorf("first week = %+v", week)
+	}
+
+	if len(week.Collaborators) != 2 || week.Collaborators[0].Name != "Alice" || week.Collaborators[0].Meetings != 2 {
+		t.Errorf("collaborators = %+v, want Alice first with 2 meetings", week.Collaborators)
+	}
+
+	if week.BusiestDays[0].Date != "2025-01-15" || week.BusiestDays[0].Hours != 3 {
+		t.Errorf("busiest day = %+v, want 2025-01-15 with 3 hours", week.BusiestDays[0])
+	}
+
+	months := ComputeAnalytics(events, AnalyticsMonth)
+	if len(months) != 1 || months[0].Label != "2025-01" || months[0].Meetings != 3 {
+		t.Errorf("months = %+v", months)
+	}
+
+	item := months[0].ToItem()
+	if item.ItemType != AnalyticsItemType || item.ID != "calendar-analytics-month-2025-01" {
+		t.Errorf("item = %s (%s)", item.ID, item.ItemType)
+	}
+
+	for _, want := range []string{"| Alice | 2 | 4.0 |", "| 2025-01-15 | 1 | 3.0 |", "```json", `"meetings": 3`} {
+		if !strings.Contains(item.Content, want) {
+			t.Errorf("analytics content missing %q:\n%s", want, item.Content)
+		}
+	}
+}
+
+func TestAnalyticsWindow(t *testing.T) {
+	at := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 0, 0, 0, 0, time.UTC) }
+	since, until := at(time.January, 15).Add(9*time.Hour), at(time.January, 22).Add(9*time.Hour)
+
+	tests := []struct {
+		name      string
+		periods   []string
+		wantSince time.Time
+		wantUntil time.Time
+	}{
+		{name: "no analytics", wantSince: since, wantUntil: until},
+		{name: "week", periods: []string{AnalyticsWeek}, wantSince: at(time.January, 13),
+			wantUntil: at(time.January, 27)},
+		{name: "week and month", periods: []string{AnalyticsWeek, AnalyticsMonth}, wantSince: at(time.January, 1),
+			wantUntil: at(time.February, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSince, gotUntil := AnalyticsWindow(since, until, tt.periods)
+			if !gotSince.Equal(tt.wantSince) || !gotUntil.Equal(tt.wantUntil) {
+				t.Errorf("AnalyticsWindow() = %v, %v, want %v, %v", gotSince, gotUntil, tt.wantSince, tt.wantUntil)
+			}
+		})
+	}
+
+	// A window ending where a period starts is not widened into the next one
+	_, gotUntil := AnalyticsWindow(since, at(time.January, 20), []string{AnalyticsWeek})
+	if !gotUntil.Equal(at(time.January, 20)) {
+		t.Errorf("AnalyticsWindow() until = %v, want the window's own end", gotUntil)
+	}
+}
+
+func TestValidateAnalyticsPeriods(t *testing.T) {
+	if err := ValidateAnalyticsPeriods([]string{AnalyticsWeek, "quarter"}); err == nil {
+		t.Error("ValidateAnalyticsPeriods() expected error for unsupported period")
+	}
+}
@@ -0,0 +1,26 @@
+package calendar
+
+import (
+	"regexp"
+	"strings"
+
+	"pkm-sync/internal/transform"
+)
+
+// htmlElementPattern matches an opening, closing or self-closing HTML tag such as "<b>", "</p>" or "<br/>",
+// but not an autolink such as "<https://example.com>", which plain text descriptions from Outlook contain.
+var htmlElementPattern = regexp.MustCompile(`</?[a-zA-Z][a-zA-Z0-9]*(\s[^<>]*)?/?>`)
+
+// descriptionMarkdown converts an HTML description, as written by the Calendar editor, Outlook organizers and
+// scheduling tools, to markdown with the content_cleanup transformer. Plain text descriptions are kept.
+func (s *Service) descriptionMarkdown(description string) string {
+	if !htmlElementPattern.MatchString(description) {
+		return description
+	}
+
+	if s.cleanup == nil {
+		s.cleanup = transform.NewContentCleanupTransformer()
+	}
+
+	return strings.TrimSpace(s.cleanup.ProcessHTMLContent(description))
+}
@@ -0,0 +1,57 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestService_ConvertToModelDescription(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		want        []string // Substrings of the converted description
+		notWant     []string
+	}{
+		{
+			name: "HTML from an Outlook organizer",
+			description: `<html><body><p>Hi all,</p><p>Please review the <b>Q3 plan</b> before we meet:</p>` +
+				`<ul><li>Budget</li><li>Hiring</li></ul>` +
+				`<a href="https://example.com/plan">Plan</a></body></html>`,
+			want:    []string{"Hi all,", "**Q3 plan**", "- Budget", "- Hiring", "[Plan](https://example.com/plan)"},
+			notWant: []string{"<p>", "<b>", "<li>", "</body>"},
+		},
+		{
+			name:        "plain text with an autolink",
+			description: "Join: <https://example.zoom.us/j/123>\nDial a < b > c",
+			want:        []string{"Join: <https://example.zoom.us/j/123>\nDial a < b > c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &calendar.Event{
+				Id:          "event",
+				Summary:     "Planning",
+				Description: tt.description,
+				Start:       &calendar.EventDateTime{DateTime: "2025-03-12T10:00:00Z"},
+				End:         &calendar.EventDateTime{DateTime: "2025-03-12T11:00:00Z"},
+			}
+
+			got := (&Service{}).ConvertToModel(event).Description
+
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Description missing %q:\n%s", want, got)
+				}
+			}
+
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("Description still contains %q:\n%s", notWant, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package calendar
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/models"
+)
+
+// maxWorkingDaysChecked bounds the days a long timed event is checked for overlap with working hours.
+const maxWorkingDaysChecked = 7
+
+// outOfOfficeTitlePattern matches the titles of absences entered as ordinary all-day events.
+var outOfOfficeTitlePattern = regexp.MustCompile(`(?i)\b(ooo|pto|vacation|out of (the )?office)\b`)
+
+// weekdayNames maps the names working days are configured by, e.g. "mon" or "monday", to weekdays.
+var weekdayNames = func() map[string]time.Weekday {
+	names := make(map[string]time.Weekday, 14)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		names[name], names[name[:3]] = day, day
+	}
+
+	return names
+}()
+
+// EventFilter drops events by when they happen and how long they last: timed events outside working
+// hours, all-day out-of-office events, and timed events shorter or longer than a threshold. A nil
+// EventFilter keeps every event.
+type EventFilter struct {
+	workDays           map[time.Weekday]bool // Nil when no working hours are set
+	workStart, workEnd time.Duration         // Since midnight
+	location           *time.Location
+
+	excludeOutOfOffice       bool
+	minDuration, maxDuration time.Duration // Zero for no threshold
+}
+
+// NewEventFilter parses a source's event_filters, with working hours in location (nil for local time). It
+// returns nil when no filter is set.
+func NewEventFilter(config models.EventFilterConfig, location *time.Location) (*EventFilter, error) {
+	if location == nil {
+		location = time.Local
+	}
+
+	filter := &EventFilter{location: location, excludeOutOfOffice: config.ExcludeOutOfOffice}
+
+	if err := filter.parseWorkingHours(config.WorkingHours); err != nil {
+		return nil, fmt.Errorf("event_filters.working_hours: %w", err)
+	}
+
+	var err error
+
+	if config.MinDuration != "" {
+		if filter.minDuration, err = utils.ParseDuration(config.MinDuration); err != nil {
+			return nil, fmt.Errorf("event_filters.min_duration: %w", err)
+		}
+	}
+
+	if config.MaxDuration != "" {
+		if filter.maxDuration, err = utils.ParseDuration(config.MaxDuration); err != nil {
+			return nil, fmt.Errorf("event_filters.max_duration: %w", err)
+		}
+	}
+
+	if filter.maxDuration > 0 && filter.minDuration > filter.maxDuration {
+		return nil, fmt.Errorf("event_filters: min_duration %s is longer than max_duration %s",
+			config.MinDuration, config.MaxDuration)
+	}
+
+	if filter.workDays == nil && !filter.excludeOutOfOffice && filter.minDuration == 0 && filter.maxDuration == 0 {
+		return nil, nil
+	}
+
+	return filter, nil
+}
+
+// parseWorkingHours reads working hours as "HH:MM" start and end times and weekday names.
+func (f *EventFilter) parseWorkingHours(config models.WorkingHoursConfig) error {
+	if config.Start == "" && config.End == "" && len(config.Days) == 0 {
+		return nil
+	}
+
+	if config.Start == "" || config.End == "" {
+		return fmt.Errorf("start and end are required")
+	}
+
+	var err error
+
+	if f.workStart, err = parseClock(config.Start); err != nil {
+		return err
+	}
+
+	if f.workEnd, err = parseClock(config.End); err != nil {
+		return err
+	}
+
+	if f.workEnd <= f.workStart {
+		return fmt.Errorf("end %s must be after start %s", config.End, config.Start)
+	}
+
+	days := config.Days
+	if len(days) == 0 {
+		days = []string{"mon", "tue", "wed", "thu", "fri"}
+	}
+
+	f.workDays = make(map[time.Weekday]bool, len(days))
+
+	for _, day := range days {
+		weekday, ok := weekdayNames[strings.ToLower(strings.TrimSpace(day))]
+		if !ok {
+			return fmt.Errorf("unsupported day '%s': use mon, tue, wed, thu, fri, sat or sun", day)
+		}
+
+		f.workDays[weekday] = true
+	}
+
+	return nil
+}
+
+// parseClock parses a time of day such as "09:00" as the time since midnight.
+func parseClock(value string) (time.Duration, error) {
+	clock, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time '%s': use HH:MM, e.g. 09:00", value)
+	}
+
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute, nil
+}
+
+// Include reports whether an event passes the filters. Working hours and duration thresholds only apply to
+// timed events, as all-day events have no time of day.
+func (f *EventFilter) Include(event *models.CalendarEvent) bool {
+	if f == nil {
+		return true
+	}
+
+	if isAllDay(event) {
+		return !f.excludeOutOfOffice || !isOutOfOffice(event)
+	}
+
+	duration := event.End.Sub(event.Start)
+	if f.minDuration > 0 && duration < f.minDuration {
+		return false
+	}
+
+	if f.maxDuration > 0 && duration > f.maxDuration {
+		return false
+	}
+
+	return f.duringWorkingHours(event.Start, event.End)
+}
+
+// duringWorkingHours reports whether a timed event overlaps the working hours of a working day.
+func (f *EventFilter) duringWorkingHours(start, end time.Time) bool {
+	if f.workDays == nil {
+		return true
+	}
+
+	start, end = start.In(f.location), end.In(f.location)
+	if !end.After(start) {
+		end = start.Add(time.Minute) // An event without a duration counts at its start
+	}
+
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, f.location)
+
+	for i := 0; i < maxWorkingDaysChecked && day.Before(end); i++ {
+		if f.workDays[day.Weekday()] {
+			workStart, workEnd := day.Add(f.workStart), day.Add(f.workEnd)
+			if start.Before(workEnd) && end.After(workStart) {
+				return true
+			}
+		}
+
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return false
+}
+
+// isAllDay reports whether an event is all-day or, like most Google out-of-office events, spans whole days.
+func isAllDay(event *models.CalendarEvent) bool {
+	return event.IsAllDay || event.End.Sub(event.Start) >= 24*time.Hour
+}
+
+// isOutOfOffice reports whether an event marks an absence, by its type or its title.
+func isOutOfOffice(event *models.CalendarEvent) bool {
+	return event.OutOfOffice || outOfOfficeTitlePattern.MatchString(event.Summary)
+}
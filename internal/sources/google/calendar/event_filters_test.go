@@ -0,0 +1,149 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestEventFilter(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	filter, err := NewEventFilter(models.EventFilterConfig{
+		WorkingHours:       models.WorkingHoursConfig{Start: "09:00", End: "17:30"},
+		ExcludeOutOfOffice: true,
+		MinDuration:        "10min",
+		MaxDuration:        "4h",
+	}, berlin)
+	if err != nil {
+		t.Fatalf("NewEventFilter() error = %v", err)
+	}
+
+	// Wednesday 2025-03-12, Berlin is UTC+1
+	at := func(hour, minute int) time.Time { return time.Date(2025, 3, 12, hour, minute, 0, 0, berlin) }
+	timed := func(summary string, start, end time.Time) *models.CalendarEvent {
+		return &models.CalendarEvent{Summary: summary, Start: start, End: end}
+	}
+	allDay := func(summary string, outOfOffice bool) *models.CalendarEvent {
+		return &models.CalendarEvent{Summary: summary, Start: at(0, 0), End: at(0, 0).AddDate(0, 0, 1), IsAllDay: true,
+			OutOfOffice: outOfOffice}
+	}
+
+	tests := []struct {
+		name  string
+		event *models.CalendarEvent
+		want  bool
+	}{
+		{"during working hours", timed("Planning", at(10, 0), at(11, 0)), true},
+		{"overlapping the end of the day", timed("Wrap-up", at(17, 0), at(18, 0)), true},
+		{"in the evening", timed("Dinner", at(19, 0), at(20, 0)), false},
+		{"in the evening in UTC", timed("Late call", at(17, 30).UTC(), at(18, 30).UTC()), false},
+		{"on a Saturday", timed("Hike", at(10, 0).AddDate(0, 0, 3), at(12, 0).AddDate(0, 0, 3)), false},
+		{"shorter than min_duration", timed("Check-in", at(10, 0), at(10, 5)), false},
+		{"longer than max_duration", timed("Workshop", at(9, 0), at(17, 0)), false},
+		{"all-day", allDay("Company offsite", false), true},
+		{"all-day out of office", allDay("Away", true), false},
+		{"all-day titled OOO", allDay("OOO - dentist", false), false},
+		{"multi-day out of office", &models.CalendarEvent{Summary: "Vacation", Start: at(0, 0),
+			End: at(0, 0).AddDate(0, 0, 5)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filter.Include(tt.event); got != tt.want {
+				t.Errorf("Include() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventFilterConvertedEvents(t *testing.T) {
+	filter, err := NewEventFilter(models.EventFilterConfig{
+		WorkingHours:       models.WorkingHoursConfig{Start: "09:00", End: "17:30"},
+		ExcludeOutOfOffice: true,
+		MinDuration:        "10min",
+	}, time.UTC)
+	if err != nil {
+		t.Fatalf("NewEventFilter() error = %v", err)
+	}
+
+	allDay := func(summary, eventType, start, end string) *calendar.Event {
+		return &calendar.Event{Summary: summary, EventType: eventType, Start: &calendar.EventDateTime{Date: start},
+			End: &calendar.EventDateTime{Date: end}}
+	}
+
+	tests := []struct {
+		name  string
+		event *calendar.Event
+		want  bool
+	}{
+		{"all-day", allDay("Company offsite", EventTypeDefault, "2025-03-12", "2025-03-13"), true},
+		{"all-day on a Saturday", allDay("Conference", EventTypeDefault, "2025-03-15", "2025-03-16"), true},
+		{"out of office", allDay("Away", EventTypeOutOfOffice, "2025-03-12", "2025-03-14"), false},
+		{"all-day titled PTO", allDay("PTO", EventTypeDefault, "2025-03-12", "2025-03-13"), false},
+		{"timed during working hours", &calendar.Event{Summary: "Planning",
+			Start: &calendar.EventDateTime{DateTime: "2025-03-12T10:00:00Z"},
+			End:   &calendar.EventDateTime{DateTime: "2025-03-12T11:00:00Z"}}, true},
+		{"timed in the evening", &calendar.Event{Summary: "Dinner",
+			Start: &calendar.EventDateTime{DateTime: "2025-03-12T19:00:00Z"},
+			End:   &calendar.EventDateTime{DateTime: "2025-03-12T20:00:00Z"}}, false},
+	}
+
+	service := &Service{}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := service.ConvertToModel(tt.event)
+			if tt.event.Start.Date != "" && (!event.IsAllDay || event.Start.IsZero()) {
+				t.Fatalf("ConvertToModel() = all-day %v from %v, want an all-day event with dates", event.IsAllDay,
+					event.Start)
+			}
+
+			if got := filter.Include(event); got != tt.want {
+				t.Errorf("Include() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEventFilter(t *testing.T) {
+	if filter, err := NewEventFilter(models.EventFilterConfig{}, nil); filter != nil || err != nil {
+		t.Errorf("NewEventFilter() without filters = %v, %v, want nil", filter, err)
+	}
+
+	var none *EventFilter
+	if !none.Include(&models.CalendarEvent{}) {
+		t.Error("a nil EventFilter should keep every event")
+	}
+
+	filter, err := NewEventFilter(models.EventFilterConfig{
+		WorkingHours: models.WorkingHoursConfig{Start: "22:00", End: "23:59", Days: []string{"Saturday", "sun"}},
+	}, time.UTC)
+	if err != nil {
+		t.Fatalf("NewEventFilter() error = %v", err)
+	}
+
+	saturday := time.Date(2025, 3, 15, 22, 30, 0, 0, time.UTC)
+	if !filter.Include(&models.CalendarEvent{Start: saturday, End: saturday.Add(time.Hour)}) {
+		t.Error("event during weekend working hours was dropped")
+	}
+
+	for _, config := range []models.EventFilterConfig{
+		{WorkingHours: models.WorkingHoursConfig{Start: "09:00"}},
+		{WorkingHours: models.WorkingHoursConfig{Start: "9am", End: "17:00"}},
+		{WorkingHours: models.WorkingHoursConfig{Start: "17:00", End: "09:00"}},
+		{WorkingHours: models.WorkingHoursConfig{Start: "09:00", End: "17:00", Days: []string{"someday"}}},
+		{MinDuration: "soon"},
+		{MinDuration: "2h", MaxDuration: "1h"},
+	} {
+		if _, err := NewEventFilter(config, nil); err == nil {
+			t.Errorf("NewEventFilter(%+v) error = nil", config)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package calendar
+
+import (
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/models"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// setMeetingURL sets an event's join link from its conferencing data, or else the first link of a meeting
+// provider in its location or description, as events created by Zoom or Outlook invites only have it there.
+func (s *Service) setMeetingURL(modelEvent *models.CalendarEvent, event *calendar.Event) {
+	providers := s.meetingProviders
+	if providers == nil {
+		providers = utils.DefaultMeetingProviders
+	}
+
+	if event.ConferenceData != nil {
+		for _, entryPoint := range event.ConferenceData.EntryPoints {
+			if entryPoint.EntryPointType == "video" && entryPoint.Uri != "" {
+				modelEvent.MeetingURL = entryPoint.Uri
+				modelEvent.MeetingProvider = utils.MeetingProviderOf(providers, entryPoint.Uri)
+
+				return
+			}
+		}
+	}
+
+	modelEvent.MeetingProvider, modelEvent.MeetingURL = utils.FindMeetingURL(providers, event.Location,
+		event.Description)
+}
@@ -0,0 +1,76 @@
+package calendar
+
+import (
+	"testing"
+
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/models"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestService_ConvertToModelMeetingURL(t *testing.T) {
+	providers, err := utils.ParseMeetingProviders([]models.MeetingProviderConfig{
+		{Name: "jitsi", Pattern: `https://meet\.jit\.si/\S+`},
+	})
+	if err != nil {
+		t.Fatalf("ParseMeetingProviders() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		event        *calendar.Event
+		wantProvider string
+		wantURL      string
+	}{
+		{
+			name: "conference data",
+			event: &calendar.Event{
+				ConferenceData: &calendar.ConferenceData{EntryPoints: []*calendar.EntryPoint{
+					{EntryPointType: "phone", Uri: "tel:+1-555-0100"},
+					{EntryPointType: "video", Uri: "https://meet.google.com/abc-defg-hij"},
+				}},
+				Description: "https://acme.zoom.us/j/123",
+			},
+			wantProvider: "google_meet",
+			wantURL:      "https://meet.google.com/abc-defg-hij",
+		},
+		{
+			name:         "zoom invite description",
+			event:        &calendar.Event{Description: "Join Zoom Meeting<br>https://acme.zoom.us/j/123?pwd=x<br>"},
+			wantProvider: "zoom",
+			wantURL:      "https://acme.zoom.us/j/123?pwd=x",
+		},
+		{
+			name:         "configured provider in the location",
+			event:        &calendar.Event{Location: "https://meet.jit.si/TeamSync"},
+			wantProvider: "jitsi",
+			wantURL:      "https://meet.jit.si/TeamSync",
+		},
+	}
+
+	service := &Service{}
+	service.SetMeetingProviders(providers)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.event.Id = "event"
+			tt.event.Start = &calendar.EventDateTime{DateTime: "2025-03-12T10:00:00Z"}
+			tt.event.End = &calendar.EventDateTime{DateTime: "2025-03-12T11:00:00Z"}
+
+			got := service.ConvertToModel(tt.event)
+			if got.MeetingProvider != tt.wantProvider || got.MeetingURL != tt.wantURL {
+				t.Errorf("meeting = %q, %q, want %q, %q", got.MeetingProvider, got.MeetingURL, tt.wantProvider, tt.wantURL)
+			}
+
+			metadata := models.Metadata(models.FromCalendarEvent(got).Metadata)
+			if url, _ := metadata.GetString(models.MetadataMeetingURL); url != tt.wantURL {
+				t.Errorf("meeting_url metadata = %q, want %q", url, tt.wantURL)
+			}
+
+			if provider, _ := metadata.GetString(models.MetadataMeetingProvider); provider != tt.wantProvider {
+				t.Errorf("meeting_provider metadata = %q, want %q", provider, tt.wantProvider)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+// Recurring event modes for GoogleSourceConfig.RecurringEvents.
+const (
+	// RecurringExpand emits one item per occurrence within the sync window.
+	RecurringExpand = "expand"
+	// RecurringSeries emits one item per recurring series, listing its occurrences.
+	RecurringSeries = "series"
+)
+
+// ValidateRecurringMode checks a recurring_events value; an empty mode means expand.
+func ValidateRecurringMode(mode string) error {
+	switch mode {
+	case "", RecurringExpand, RecurringSeries:
+		return nil
+	default:
+		return fmt.Errorf("unsupported recurring_events '%s': supported modes are 'expand', 'series'", mode)
+	}
+}
+
+// GroupSeries merges the occurrences of each recurring series into a single event.
+// The series event keeps the first occurrence's details, takes the series ID and records every
+// occurrence's start time. Non-recurring events are returned unchanged, in their original order.
+func GroupSeries(events []*models.CalendarEvent) []*models.CalendarEvent {
+	grouped := make([]*models.CalendarEvent, 0, len(events))
+	series := make(map[string]*models.CalendarEvent)
+
+	for _, event := range events {
+		if event.RecurringEventID == "" {
+			grouped = append(grouped, event)
+
+			continue
+		}
+
+		if existing, exists := series[event.RecurringEventID]; exists {
+			existing.Occurrences = append(existing.Occurrences, event.Start)
+
+			continue
+		}
+
+		merged := *event
+		merged.ID = event.RecurringEventID
+		merged.Occurrences = []time.Time{event.Start}
+		series[event.RecurringEventID] = &merged
+		grouped = append(grouped, &merged)
+	}
+
+	return grouped
+}
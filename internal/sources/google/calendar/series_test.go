@@ -0,0 +1,48 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestGroupSeries(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 9, 0, 0, 0, time.UTC) }
+
+	events := []*models.CalendarEvent{
+		{ID: "standup_1", Summary: "Standup", Start: day(13), RecurringEventID: "standup"},
+		{ID: "review", Summary: "Design review", Start: day(13)},
+		{ID: "standup_2", Summary: "Standup", Start: day(14), RecurringEventID: "standup"},
+		{ID: "standup_3", Summary: "Standup", Start: day(15), RecurringEventID: "standup"},
+	}
+
+	grouped := GroupSeries(events)
+	if len(grouped) != 2 {
+		t.Fatalf("GroupSeries() returned %d events, want 2", len(grouped))
+	}
+
+	series := grouped[0]
+	if series.ID != "standup" || len(series.Occurrences) != 3 || !series.Occurrences[2].Equal(day(15)) {
+		t.Errorf("series event = %+v", series)
+	}
+
+	if grouped[1].ID != "review" || grouped[1].Occurrences != nil {
+		t.Errorf("non-recurring event changed: %+v", grouped[1])
+	}
+
+	if events[0].ID != "standup_1" {
+		t.Error("GroupSeries() modified the input events")
+	}
+
+	item := models.FromCalendarEvent(series)
+	if item.Metadata["occurrence_count"] != 3 || item.Metadata["recurring_event_id"] != "standup" {
+		t.Errorf("series item metadata = %v", item.Metadata)
+	}
+}
+
+func TestValidateRecurringMode(t *testing.T) {
+	if err := ValidateRecurringMode("weekly"); err == nil {
+		t.Error("ValidateRecurringMode() expected error for unsupported mode")
+	}
+}
@@ -7,17 +7,38 @@ import (
 	"strings"
 	"time"
 
+	"pkm-sync/internal/sources/google/drive"
+	"pkm-sync/internal/transform"
+	"pkm-sync/internal/utils"
 	"pkm-sync/pkg/models"
 
 	"google.golang.org/api/calendar/v3"
 	"google.golang.org/api/option"
 )
 
+// Event types reported by the Calendar API.
+const (
+	EventTypeDefault         = "default"
+	EventTypeFocusTime       = "focusTime"
+	EventTypeOutOfOffice     = "outOfOffice"
+	EventTypeWorkingLocation = "workingLocation"
+	EventTypeBirthday        = "birthday"
+	EventTypeFromGmail       = "fromGmail"
+)
+
 type Service struct {
 	calendarService          *calendar.Service
 	attendeeAllowList        []string
 	requireMultipleAttendees bool
 	includeSelfOnlyEvents    bool
+	includeDeclined          bool
+	eventTypes               []string
+	extractAgenda            bool
+
+	cleanup *transform.ContentCleanupTransformer // Converts HTML descriptions to markdown
+
+	// Recognize join links in locations and descriptions; nil uses utils.DefaultMeetingProviders
+	meetingProviders []utils.MeetingProvider
 }
 
 func NewService(client *http.Client) (*Service, error) {
@@ -33,6 +54,7 @@ func NewService(client *http.Client) (*Service, error) {
 		calendarService:          calendarService,
 		requireMultipleAttendees: true,  // Default: filter out 0-1 attendee events
 		includeSelfOnlyEvents:    false, // Default: don't include solo events
+		cleanup:                  transform.NewContentCleanupTransformer(),
 	}, nil
 }
 
@@ -41,6 +63,11 @@ func (s *Service) SetAttendeeAllowList(allowList []string) {
 	s.attendeeAllowList = allowList
 }
 
+// SetMeetingProviders configures the providers whose join links are found in locations and descriptions.
+func (s *Service) SetMeetingProviders(providers []utils.MeetingProvider) {
+	s.meetingProviders = providers
+}
+
 // SetRequireMultipleAttendees configures whether to require multiple attendees.
 func (s *Service) SetRequireMultipleAttendees(require bool) {
 	s.requireMultipleAttendees = require
@@ -51,17 +78,90 @@ func (s *Service) SetIncludeSelfOnlyEvents(include bool) {
 	s.includeSelfOnlyEvents = include
 }
 
-// shouldIncludeEvent applies two-step filtering: 1) attendee allow list, 2) self-only rules.
+// SetIncludeDeclined configures whether to include events you have declined.
+func (s *Service) SetIncludeDeclined(include bool) {
+	s.includeDeclined = include
+}
+
+// SetEventTypes restricts events to the given event types; an empty list includes all types.
+func (s *Service) SetEventTypes(eventTypes []string) {
+	s.eventTypes = eventTypes
+}
+
+// SetExtractAgenda configures whether agendas and docs linked from event descriptions are extracted.
+func (s *Service) SetExtractAgenda(extract bool) {
+	s.extractAgenda = extract
+}
+
+// ValidateEventTypes checks event_types values against the types reported by the Calendar API.
+func ValidateEventTypes(eventTypes []string) error {
+	for _, eventType := range eventTypes {
+		switch eventType {
+		case EventTypeDefault, EventTypeFocusTime, EventTypeOutOfOffice, EventTypeWorkingLocation,
+			EventTypeBirthday, EventTypeFromGmail:
+		default:
+			return fmt.Errorf("unsupported event type '%s': supported types are 'default', 'focusTime', "+
+				"'outOfOffice', 'workingLocation', 'birthday', 'fromGmail'", eventType)
+		}
+	}
+
+	return nil
+}
+
+// shouldIncludeEvent applies four-step filtering: 1) event type, 2) RSVP status, 3) attendee allow list,
+// 4) self-only rules.
 func (s *Service) shouldIncludeEvent(event *calendar.Event) bool {
-	// Step 1: Apply attendee allow list filtering
+	// Step 1: Apply event type filtering
+	if !s.passesEventTypeFilter(event) {
+		return false
+	}
+
+	// Step 2: Drop events you declined unless they are wanted
+	if !s.includeDeclined && isDeclinedBySelf(event) {
+		return false
+	}
+
+	// Step 3: Apply attendee allow list filtering
 	if !s.passesAttendeeAllowListFilter(event) {
 		return false
 	}
 
-	// Step 2: Apply self-only event filtering
+	// Step 4: Apply self-only event filtering
 	return s.passesSelfOnlyEventFilter(event)
 }
 
+// passesEventTypeFilter checks if the event's type is one of the configured event types.
+func (s *Service) passesEventTypeFilter(event *calendar.Event) bool {
+	if len(s.eventTypes) == 0 {
+		return true
+	}
+
+	// The API omits the type for regular events
+	eventType := event.EventType
+	if eventType == "" {
+		eventType = EventTypeDefault
+	}
+
+	for _, allowed := range s.eventTypes {
+		if allowed == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isDeclinedBySelf reports whether the authenticated user has declined the event.
+func isDeclinedBySelf(event *calendar.Event) bool {
+	for _, attendee := range event.Attendees {
+		if attendee.Self {
+			return attendee.ResponseStatus == "declined"
+		}
+	}
+
+	return false
+}
+
 // passesAttendeeAllowListFilter checks if event passes the attendee allow list filter.
 func (s *Service) passesAttendeeAllowListFilter(event *calendar.Event) bool {
 	// If no allow list is configured, all events pass this filter
@@ -136,10 +236,15 @@ func (s *Service) GetUpcomingEvents(maxResults int64) ([]*calendar.Event, error)
 }
 
 func (s *Service) GetEventsInRange(start, end time.Time, maxResults int64) ([]*calendar.Event, error) {
+	return s.GetCalendarEventsInRange("primary", start, end, maxResults)
+}
+
+// GetCalendarEventsInRange returns the events of a specific calendar within a time range.
+func (s *Service) GetCalendarEventsInRange(calendarID string, start, end time.Time, maxResults int64) ([]*calendar.Event, error) {
 	startTime := start.Format(time.RFC3339)
 	endTime := end.Format(time.RFC3339)
 
-	events, err := s.calendarService.Events.List("primary").
+	events, err := s.calendarService.Events.List(calendarID).
 		ShowDeleted(false).
 		SingleEvents(true).
 		TimeMin(startTime).
@@ -148,29 +253,64 @@ func (s *Service) GetEventsInRange(start, end time.Time, maxResults int64) ([]*c
 		OrderBy("startTime").
 		Do()
 	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve events in range: %w", err)
+		return nil, fmt.Errorf("unable to retrieve events in range for calendar %s: %w", calendarID, err)
 	}
 
 	return s.filterEvents(events.Items), nil
 }
 
+// ListReadableCalendars returns the IDs of all calendars the user can read, including the primary calendar.
+func (s *Service) ListReadableCalendars() ([]string, error) {
+	var calendarIDs []string
+
+	pageToken := ""
+
+	for {
+		call := s.calendarService.CalendarList.List().MinAccessRole("reader")
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		list, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to list calendars: %w", err)
+		}
+
+		for _, entry := range list.Items {
+			calendarIDs = append(calendarIDs, entry.Id)
+		}
+
+		if list.NextPageToken == "" {
+			return calendarIDs, nil
+		}
+
+		pageToken = list.NextPageToken
+	}
+}
+
 func (s *Service) ConvertToModel(event *calendar.Event) *models.CalendarEvent {
 	modelEvent := &models.CalendarEvent{
-		ID:          event.Id,
-		Summary:     event.Summary,
-		Description: event.Description,
-		Location:    event.Location,
+		ID:               event.Id,
+		Summary:          event.Summary,
+		Description:      s.descriptionMarkdown(event.Description),
+		Location:         event.Location,
+		RecurringEventID: event.RecurringEventId,
 	}
 
-	if event.Start.DateTime != "" {
-		if startTime, err := time.Parse(time.RFC3339, event.Start.DateTime); err == nil {
-			modelEvent.Start = startTime
-		}
+	if start, allDay, ok := parseEventTime(event.Start); ok {
+		modelEvent.Start = start
+		modelEvent.IsAllDay = allDay
 	}
 
-	if event.End.DateTime != "" {
-		if endTime, err := time.Parse(time.RFC3339, event.End.DateTime); err == nil {
-			modelEvent.End = endTime
+	if end, _, ok := parseEventTime(event.End); ok {
+		modelEvent.End = end
+	}
+
+	if event.Organizer != nil && event.Organizer.Email != "" {
+		modelEvent.Organizer = models.Attendee{
+			Email:       event.Organizer.Email,
+			DisplayName: event.Organizer.DisplayName,
+			Self:        event.Organizer.Self,
 		}
 	}
 
@@ -179,20 +319,14 @@ func (s *Service) ConvertToModel(event *calendar.Event) *models.CalendarEvent {
 			modelAttendee := models.Attendee{
 				Email:       attendee.Email,
 				DisplayName: attendee.DisplayName,
+				Self:        attendee.Self,
 			}
 			modelEvent.Attendees = append(modelEvent.Attendees, modelAttendee)
 		}
 	}
 
-	if event.ConferenceData != nil && len(event.ConferenceData.EntryPoints) > 0 {
-		for _, entryPoint := range event.ConferenceData.EntryPoints {
-			if entryPoint.EntryPointType == "video" && entryPoint.Uri != "" {
-				modelEvent.MeetingURL = entryPoint.Uri
-
-				break
-			}
-		}
-	}
+	s.setMeetingURL(modelEvent, event)
+	modelEvent.OutOfOffice = event.EventType == EventTypeOutOfOffice
 
 	// Process native Calendar API attachments
 	for _, attachment := range event.Attachments {
@@ -206,13 +340,70 @@ func (s *Service) ConvertToModel(event *calendar.Event) *models.CalendarEvent {
 		modelEvent.Attachments = append(modelEvent.Attachments, calAttachment)
 	}
 
+	if s.extractAgenda {
+		modelEvent.Agenda = ExtractAgenda(event.Description)
+	}
+
 	return modelEvent
 }
 
+// parseEventTime parses the start or end of an event: a time for timed events, or, for all-day events, a date
+// taken as local midnight. The end date of an all-day event is exclusive.
+func parseEventTime(eventTime *calendar.EventDateTime) (parsed time.Time, allDay, ok bool) {
+	if eventTime == nil {
+		return time.Time{}, false, false
+	}
+
+	if eventTime.DateTime != "" {
+		parsed, err := time.Parse(time.RFC3339, eventTime.DateTime)
+
+		return parsed, false, err == nil
+	}
+
+	if eventTime.Date != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", eventTime.Date, time.Local)
+
+		return parsed, true, err == nil
+	}
+
+	return time.Time{}, false, false
+}
+
 // ConvertToModelWithDrive converts a calendar event to a model with drive file attachments populated.
+// Besides the native Calendar API attachments, docs linked from the description are attached when
+// agendas are extracted.
 func (s *Service) ConvertToModelWithDrive(event *calendar.Event) *models.CalendarEvent {
-	// Now that we use native Calendar API attachments, just use the base conversion
-	return s.ConvertToModel(event)
+	modelEvent := s.ConvertToModel(event)
+	if !s.extractAgenda {
+		return modelEvent
+	}
+
+	attached := make(map[string]bool, len(modelEvent.Attachments))
+	for _, attachment := range modelEvent.Attachments {
+		attached[attachment.FileID] = true
+	}
+
+	for _, file := range drive.ExtractLinkedFiles(event.Description) {
+		if attached[file.ID] {
+			continue
+		}
+
+		attached[file.ID] = true
+
+		title := file.Title
+		if title == "" {
+			title = file.ID
+		}
+
+		modelEvent.Attachments = append(modelEvent.Attachments, models.CalendarAttachment{
+			FileURL:  file.URL,
+			FileID:   file.ID,
+			Title:    title,
+			MimeType: file.MimeType,
+		})
+	}
+
+	return modelEvent
 }
 
 // DriveServiceInterface defines the interface for drive service operations needed by calendar
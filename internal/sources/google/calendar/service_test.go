@@ -521,3 +521,69 @@ func TestService_SetIncludeSelfOnlyEvents(t *testing.T) {
 		t.Errorf("SetIncludeSelfOnlyEvents(false) = %v, expected false", service.includeSelfOnlyEvents)
 	}
 }
+
+func TestService_passesEventTypeFilter(t *testing.T) {
+	tests := []struct {
+		name       string
+		eventTypes []string
+		eventType  string
+		expected   bool
+	}{
+		{"no filter includes all types", nil, EventTypeFocusTime, true},
+		{"matching type", []string{EventTypeDefault, EventTypeOutOfOffice}, EventTypeOutOfOffice, true},
+		{"non-matching type", []string{EventTypeDefault}, EventTypeWorkingLocation, false},
+		{"missing type is default", []string{EventTypeDefault}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &Service{eventTypes: tt.eventTypes}
+
+			if got := service.passesEventTypeFilter(&calendar.Event{EventType: tt.eventType}); got != tt.expected {
+				t.Errorf("passesEventTypeFilter() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestService_shouldIncludeEventDeclined(t *testing.T) {
+	declined := &calendar.Event{
+		Attendees: []*calendar.EventAttendee{
+			{Email: "me@example.com", Self: true, ResponseStatus: "declined"},
+			{Email: "other@example.com", ResponseStatus: "accepted"},
+		},
+	}
+
+	service := &Service{}
+	if service.shouldIncludeEvent(declined) {
+		t.Error("shouldIncludeEvent() included an event declined by the user")
+	}
+
+	service.SetIncludeDeclined(true)
+
+	if !service.shouldIncludeEvent(declined) {
+		t.Error("shouldIncludeEvent() excluded a declined event with include_declined enabled")
+	}
+
+	// Other attendees declining does not matter
+	accepted := &calendar.Event{
+		Attendees: []*calendar.EventAttendee{
+			{Email: "me@example.com", Self: true, ResponseStatus: "accepted"},
+			{Email: "other@example.com", ResponseStatus: "declined"},
+		},
+	}
+
+	if !(&Service{}).shouldIncludeEvent(accepted) {
+		t.Error("shouldIncludeEvent() excluded an accepted event")
+	}
+}
+
+func TestValidateEventTypes(t *testing.T) {
+	if err := ValidateEventTypes([]string{EventTypeDefault, EventTypeFocusTime}); err != nil {
+		t.Errorf("ValidateEventTypes() error = %v", err)
+	}
+
+	if err := ValidateEventTypes([]string{"meeting"}); err == nil {
+		t.Error("ValidateEventTypes() expected error for unsupported type")
+	}
+}
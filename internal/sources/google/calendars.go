@@ -0,0 +1,86 @@
+package google
+
+import (
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/models"
+)
+
+const (
+	defaultCalendarID = "primary"
+
+	// allCalendars selects every calendar the user can read.
+	allCalendars = "all"
+)
+
+// configuredCalendars returns the calendars selected for this instance, falling back to calendar_id.
+func (g *GoogleSource) configuredCalendars() []models.CalendarConfig {
+	if len(g.config.Google.Calendars) > 0 {
+		return g.config.Google.Calendars
+	}
+
+	calendarID := g.config.Google.CalendarID
+	if calendarID == "" {
+		calendarID = defaultCalendarID
+	}
+
+	return []models.CalendarConfig{{ID: calendarID}}
+}
+
+// resolveCalendars expands "all" into every readable calendar that is not configured explicitly.
+// Expanded calendars inherit the tags and folder of the "all" entry.
+func resolveCalendars(calendars []models.CalendarConfig, listReadable func() ([]string, error)) ([]models.CalendarConfig, error) {
+	explicit := make(map[string]bool, len(calendars))
+
+	for _, cal := range calendars {
+		if cal.ID != allCalendars {
+			explicit[cal.ID] = true
+		}
+	}
+
+	resolved := make([]models.CalendarConfig, 0, len(calendars))
+
+	for _, cal := range calendars {
+		if cal.ID != allCalendars {
+			resolved = append(resolved, cal)
+
+			continue
+		}
+
+		ids, err := listReadable()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, id := range ids {
+			if explicit[id] {
+				continue
+			}
+
+			explicit[id] = true
+			resolved = append(resolved, models.CalendarConfig{ID: id, Tags: cal.Tags, Folder: cal.Folder})
+		}
+	}
+
+	return resolved, nil
+}
+
+// applyCalendarMapping records the calendar on an event item and applies its tags and folder.
+func applyCalendarMapping(item models.ItemInterface, cal models.CalendarConfig) {
+	metadata := item.GetMetadata()
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+
+	metadata["calendar_id"] = cal.ID
+
+	if cal.Folder != "" {
+		metadata[utils.FolderMetadataKey] = cal.Folder
+	}
+
+	item.SetMetadata(metadata)
+
+	if len(cal.Tags) > 0 {
+		tags := append(append([]string{}, item.GetTags()...), cal.Tags...)
+		item.SetTags(tags)
+	}
+}
@@ -0,0 +1,51 @@
+package google
+
+import (
+	"testing"
+
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfiguredCalendars(t *testing.T) {
+	source := NewGoogleSourceWithConfig("calendar", models.SourceConfig{Type: "google_calendar"})
+	assert.Equal(t, []models.CalendarConfig{{ID: "primary"}}, source.configuredCalendars())
+
+	source.config.Google.CalendarID = "team@group.calendar.google.com"
+	assert.Equal(t, []models.CalendarConfig{{ID: "team@group.calendar.google.com"}}, source.configuredCalendars())
+
+	source.config.Google.Calendars = []models.CalendarConfig{{ID: "primary"}, {ID: "all"}}
+	assert.Equal(t, source.config.Google.Calendars, source.configuredCalendars())
+}
+
+func TestResolveCalendarsExpandsAll(t *testing.T) {
+	listReadable := func() ([]string, error) {
+		return []string{"me@example.com", "team@example.com", "holidays@example.com"}, nil
+	}
+
+	resolved, err := resolveCalendars([]models.CalendarConfig{
+		{ID: "team@example.com", Tags: []string{"team"}, Folder: "Team"},
+		{ID: "all", Folder: "Other"},
+	}, listReadable)
+	require.NoError(t, err)
+
+	assert.Equal(t, []models.CalendarConfig{
+		{ID: "team@example.com", Tags: []string{"team"}, Folder: "Team"},
+		{ID: "me@example.com", Folder: "Other"},
+		{ID: "holidays@example.com", Folder: "Other"},
+	}, resolved)
+}
+
+func TestApplyCalendarMapping(t *testing.T) {
+	item := models.NewBasicItem("evt-1", "Planning")
+	item.SetTags([]string{"meeting"})
+
+	applyCalendarMapping(item, models.CalendarConfig{ID: "team@example.com", Tags: []string{"team"}, Folder: "Meetings/Team"})
+
+	assert.Equal(t, []string{"meeting", "team"}, item.GetTags())
+	assert.Equal(t, "team@example.com", item.GetMetadata()["calendar_id"])
+	assert.Equal(t, "Meetings/Team", item.GetMetadata()[utils.FolderMetadataKey])
+}
@@ -0,0 +1,222 @@
+package drive
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// Comment styles for GoogleSourceConfig.DocComments.
+const (
+	CommentsSection   = "section"   // A "## Comments" section after the document
+	CommentsFootnotes = "footnotes" // A footnote after the commented text
+)
+
+const commentFields = "nextPageToken, comments(id,author(displayName),content,quotedFileContent(value)," +
+	"resolved,createdTime,deleted,replies(author(displayName),content,action,deleted))"
+
+// ValidateCommentStyle checks a doc_comments value; empty leaves comments out.
+func ValidateCommentStyle(style string) error {
+	switch style {
+	case "", CommentsSection, CommentsFootnotes:
+		return nil
+	default:
+		return fmt.Errorf("unsupported doc_comments '%s': supported styles are 'section', 'footnotes'", style)
+	}
+}
+
+// SetDocComments configures how comment threads are added to markdown exports; empty leaves them out.
+func (s *Service) SetDocComments(style string) {
+	s.docComments = style
+}
+
+// GetComments returns the comment threads of a file, without deleted comments.
+func (s *Service) GetComments(fileID string) ([]*drive.Comment, error) {
+	var comments []*drive.Comment
+
+	pageToken := ""
+
+	for {
+		call := s.client.Comments.List(fileID).Fields(commentFields).PageSize(100)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		list, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve comments: %w", err)
+		}
+
+		for _, comment := range list.Comments {
+			if !comment.Deleted {
+				comments = append(comments, comment)
+			}
+		}
+
+		if list.NextPageToken == "" {
+			return comments, nil
+		}
+
+		pageToken = list.NextPageToken
+	}
+}
+
+// addComments fetches the comments of a doc and renders them into its markdown export.
+// A failure to fetch comments keeps the export without them.
+func (s *Service) addComments(fileID string, markdown []byte) []byte {
+	if s.docComments == "" {
+		return markdown
+	}
+
+	comments, err := s.GetComments(fileID)
+	if err != nil {
+		fmt.Printf("Warning: Could not fetch comments for %s: %v\n", fileID, err)
+
+		return markdown
+	}
+
+	return []byte(RenderComments(string(markdown), comments, s.docComments))
+}
+
+// RenderComments adds comment threads to a markdown document, either as a "## Comments" section or as
+// footnotes placed after the first occurrence of the commented text (at the end when it is not found).
+func RenderComments(markdown string, comments []*drive.Comment, style string) string {
+	if len(comments) == 0 {
+		return markdown
+	}
+
+	markdown = strings.TrimRight(markdown, "\n") + "\n"
+
+	if style == CommentsFootnotes {
+		return renderFootnotes(markdown, comments)
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(markdown)
+	sb.WriteString("\n## Comments\n")
+
+	for _, comment := range comments {
+		sb.WriteString("\n")
+
+		if quoted := quotedText(comment); quoted != "" {
+			sb.WriteString("> " + quoted + "\n\n")
+		}
+
+		sb.WriteString("- " + commentSummary(comment) + "\n")
+
+		for _, reply := range visibleReplies(comment) {
+			sb.WriteString("  - " + replySummary(reply) + "\n")
+		}
+	}
+
+	return sb.String()
+}
+
+func renderFootnotes(markdown string, comments []*drive.Comment) string {
+	var (
+		definitions strings.Builder
+		unplaced    []string
+	)
+
+	for i, comment := range comments {
+		marker := fmt.Sprintf("[^c%d]", i+1)
+
+		placed := false
+		if quoted := quotedText(comment); quoted != "" {
+			if idx := strings.Index(markdown, quoted); idx >= 0 {
+				end := idx + len(quoted)
+				markdown = markdown[:end] + marker + markdown[end:]
+				placed = true
+			}
+		}
+
+		if !placed {
+			unplaced = append(unplaced, marker)
+		}
+
+		definitions.WriteString(marker + ": " + commentSummary(comment))
+
+		for _, reply := range visibleReplies(comment) {
+			definitions.WriteString("\n    - " + replySummary(reply))
+		}
+
+		definitions.WriteString("\n")
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(markdown)
+
+	if len(unplaced) > 0 {
+		sb.WriteString("\n" + strings.Join(unplaced, " ") + "\n")
+	}
+
+	sb.WriteString("\n" + definitions.String())
+
+	return sb.String()
+}
+
+// commentSummary renders the author, status, date and text of a comment.
+func commentSummary(comment *drive.Comment) string {
+	status := "open"
+	if comment.Resolved {
+		status = "resolved"
+	}
+
+	details := status
+	if created, err := time.Parse(time.RFC3339, comment.CreatedTime); err == nil {
+		details += ", " + created.Format("2006-01-02")
+	}
+
+	return fmt.Sprintf("**%s** (%s): %s", authorName(comment.Author), details, singleLine(comment.Content))
+}
+
+// replySummary renders a reply; replies that only resolve or reopen a thread say so.
+func replySummary(reply *drive.Reply) string {
+	text := singleLine(reply.Content)
+
+	switch reply.Action {
+	case "resolve":
+		text = strings.TrimSpace("*marked as resolved* " + text)
+	case "reopen":
+		text = strings.TrimSpace("*reopened* " + text)
+	}
+
+	return fmt.Sprintf("**%s**: %s", authorName(reply.Author), text)
+}
+
+func visibleReplies(comment *drive.Comment) []*drive.Reply {
+	var replies []*drive.Reply
+
+	for _, reply := range comment.Replies {
+		if !reply.Deleted {
+			replies = append(replies, reply)
+		}
+	}
+
+	return replies
+}
+
+func quotedText(comment *drive.Comment) string {
+	if comment.QuotedFileContent == nil {
+		return ""
+	}
+
+	return singleLine(comment.QuotedFileContent.Value)
+}
+
+func authorName(author *drive.User) string {
+	if author == nil || author.DisplayName == "" {
+		return "Unknown"
+	}
+
+	return author.DisplayName
+}
+
+// singleLine joins multi-line comment text so it stays inside one list item or footnote.
+func singleLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
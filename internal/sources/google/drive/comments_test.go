@@ -0,0 +1,78 @@
+package drive
+
+import (
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+)
+
+func testComments() []*drive.Comment {
+	return []*drive.Comment{
+		{
+			Author:            &drive.User{DisplayName: "Alice"},
+			Content:           "Can we ship\nthis sooner?",
+			QuotedFileContent: &drive.CommentQuotedFileContent{Value: "launch in March"},
+			CreatedTime:       "2025-01-13T09:30:00Z",
+			Resolved:          true,
+			Replies: []*drive.Reply{
+				{Author: &drive.User{DisplayName: "Bob"}, Content: "February works."},
+				{Author: &drive.User{DisplayName: "Alice"}, Action: "resolve"},
+				{Author: &drive.User{DisplayName: "Eve"}, Content: "gone", Deleted: true},
+			},
+		},
+		{
+			Content:           "Typo",
+			QuotedFileContent: &drive.CommentQuotedFileContent{Value: "text that was removed"},
+		},
+	}
+}
+
+func TestRenderCommentsSection(t *testing.T) {
+	got := RenderComments("# Plan\n\nWe launch in March.\n", testComments(), CommentsSection)
+
+	want := "# Plan\n\nWe launch in March.\n" +
+		"\n## Comments\n" +
+		"\n> launch in March\n\n" +
+		"- **Alice** (resolved, 2025-01-13): Can we ship this sooner?\n" +
+		"  - **Bob**: February works.\n" +
+		"  - **Alice**: *marked as resolved*\n" +
+		"\n> text that was removed\n\n" +
+		"- **Unknown** (open): Typo\n"
+
+	if got != want {
+		t.Errorf("RenderComments() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderCommentsFootnotes(t *testing.T) {
+	got := RenderComments("# Plan\n\nWe launch in March.\n\n", testComments(), CommentsFootnotes)
+
+	want := "# Plan\n\nWe launch in March[^c1].\n" +
+		"\n[^c2]\n" +
+		"\n[^c1]: **Alice** (resolved, 2025-01-13): Can we ship this sooner?\n" +
+		"    - **Bob**: February works.\n" +
+		"    - **Alice**: *marked as resolved*\n" +
+		"[^c2]: **Unknown** (open): Typo\n"
+
+	if got != want {
+		t.Errorf("RenderComments() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderCommentsWithoutComments(t *testing.T) {
+	if got := RenderComments("# Plan\n", nil, CommentsSection); got != "# Plan\n" {
+		t.Errorf("RenderComments() = %q, want document unchanged", got)
+	}
+}
+
+func TestValidateCommentStyle(t *testing.T) {
+	for _, style := range []string{"", CommentsSection, CommentsFootnotes} {
+		if err := ValidateCommentStyle(style); err != nil {
+			t.Errorf("ValidateCommentStyle(%q) error = %v", style, err)
+		}
+	}
+
+	if err := ValidateCommentStyle("inline"); err == nil {
+		t.Error("ValidateCommentStyle() expected error for unsupported style")
+	}
+}
@@ -0,0 +1,220 @@
+package drive
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+const (
+	folderMimeType = "application/vnd.google-apps.folder"
+
+	folderFileFields = "nextPageToken, files(id,name,mimeType,webViewLink,modifiedTime,owners,size)"
+)
+
+// FolderFile is a file found while walking a Drive folder.
+type FolderFile struct {
+	models.DriveFile
+
+	Path string // Slash-separated subfolder path relative to the walked folder; "" for its top level
+}
+
+// IsFolder checks if a file is a Drive folder.
+func (s *Service) IsFolder(mimeType string) bool {
+	return mimeType == folderMimeType
+}
+
+// IsGoogleNative checks if a file is a native Google file (Docs, Sheets, Slides, ...) that has no binary content.
+func (s *Service) IsGoogleNative(mimeType string) bool {
+	return strings.HasPrefix(mimeType, "application/vnd.google-apps.")
+}
+
+// ListFolderFiles returns every file below a Drive folder, descending into subfolders.
+// Folders themselves are not returned.
+func (s *Service) ListFolderFiles(folderID string) ([]FolderFile, error) {
+	var files []FolderFile
+
+	type pendingFolder struct {
+		id   string
+		path string
+	}
+
+	queue := []pendingFolder{{id: folderID}}
+	visited := map[string]bool{folderID: true}
+
+	for len(queue) > 0 {
+		folder := queue[0]
+		queue = queue[1:]
+
+		pageToken := ""
+
+		for {
+			call := s.client.Files.List().
+				Q(fmt.Sprintf("'%s' in parents and trashed = false", folder.id)).
+				Fields(folderFileFields).
+				SupportsAllDrives(true).
+				IncludeItemsFromAllDrives(true).
+				PageSize(1000)
+			if pageToken != "" {
+				call = call.PageToken(pageToken)
+			}
+
+			list, err := call.Do()
+			if err != nil {
+				return nil, fmt.Errorf("unable to list folder contents: %w", err)
+			}
+
+			for _, file := range list.Files {
+				if s.IsFolder(file.MimeType) {
+					// A folder can appear under several parents; walk it once
+					if !visited[file.Id] {
+						visited[file.Id] = true
+						queue = append(queue, pendingFolder{id: file.Id, path: path.Join(folder.path, file.Name)})
+					}
+
+					continue
+				}
+
+				driveFile := models.DriveFile{
+					ID:          file.Id,
+					Name:        file.Name,
+					MimeType:    file.MimeType,
+					WebViewLink: file.WebViewLink,
+					Size:        file.Size,
+				}
+
+				if modified, err := time.Parse(time.RFC3339, file.ModifiedTime); err == nil {
+					driveFile.ModifiedTime = modified
+				}
+
+				for _, owner := range file.Owners {
+					driveFile.Owners = append(driveFile.Owners, owner.DisplayName)
+				}
+
+				driveFile.Shared = len(file.Owners) > 1
+
+				files = append(files, FolderFile{DriveFile: driveFile, Path: folder.path})
+			}
+
+			if list.NextPageToken == "" {
+				break
+			}
+
+			pageToken = list.NextPageToken
+		}
+	}
+
+	return files, nil
+}
+
+// GetFolderName returns the name of a Drive folder.
+func (s *Service) GetFolderName(folderID string) (string, error) {
+	file, err := s.client.Files.Get(folderID).Fields("name,mimeType").SupportsAllDrives(true).Do()
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve folder: %w", err)
+	}
+
+	if !s.IsFolder(file.MimeType) {
+		return "", fmt.Errorf("file %s is not a folder (type: %s)", folderID, file.MimeType)
+	}
+
+	return file.Name, nil
+}
+
+// GetStartPageToken returns the Drive changes token marking the current state of the user's files.
+func (s *Service) GetStartPageToken() (string, error) {
+	token, err := s.client.Changes.GetStartPageToken().SupportsAllDrives(true).Do()
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve changes token: %w", err)
+	}
+
+	return token.StartPageToken, nil
+}
+
+// ListChangedFileIDs returns the IDs of files changed since a changes token, and the token to use next time.
+// Removed files are not included.
+func (s *Service) ListChangedFileIDs(pageToken string) (map[string]bool, string, error) {
+	changed := make(map[string]bool)
+
+	for {
+		list, err := s.client.Changes.List(pageToken).
+			Fields("nextPageToken, newStartPageToken, changes(fileId,removed)").
+			SupportsAllDrives(true).
+			IncludeItemsFromAllDrives(true).
+			PageSize(1000).
+			Do()
+		if err != nil {
+			return nil, "", fmt.Errorf("unable to list changes: %w", err)
+		}
+
+		for _, change := range list.Changes {
+			if !change.Removed {
+				changed[change.FileId] = true
+			}
+		}
+
+		if list.NewStartPageToken != "" {
+			return changed, list.NewStartPageToken, nil
+		}
+
+		pageToken = list.NextPageToken
+	}
+}
+
+// ExportDocContent returns a Google Doc exported as markdown, with its comments when doc_comments is set.
+// Docs larger than max_doc_size return an *ErrDocTooLarge.
+func (s *Service) ExportDocContent(fileID string) ([]byte, error) {
+	resp, err := s.client.Files.Export(fileID, exportMimeTypes[FormatMarkdown]).Download()
+	if err != nil {
+		return nil, fmt.Errorf("unable to export document: %w", err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	markdown, err := s.readLimited(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.addComments(fileID, markdown), nil
+}
+
+// DownloadFile returns the content of a binary Drive file. Files larger than max_doc_size return
+// an *ErrDocTooLarge.
+func (s *Service) DownloadFile(fileID string) ([]byte, error) {
+	resp, err := s.client.Files.Get(fileID).SupportsAllDrives(true).Download()
+	if err != nil {
+		return nil, fmt.Errorf("unable to download file: %w", err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	return s.readLimited(resp.Body)
+}
+
+// readLimited reads a download, reading one byte past max_doc_size to detect oversized files.
+func (s *Service) readLimited(body io.Reader) ([]byte, error) {
+	if s.maxDocSize > 0 {
+		body = io.LimitReader(body, s.maxDocSize+1)
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, body); err != nil {
+		return nil, fmt.Errorf("unable to read file content: %w", err)
+	}
+
+	if s.maxDocSize > 0 && int64(buf.Len()) > s.maxDocSize {
+		return nil, &ErrDocTooLarge{Size: int64(buf.Len()), Limit: s.maxDocSize}
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,47 @@
+package drive
+
+import (
+	"fmt"
+
+	"pkm-sync/internal/utils"
+)
+
+// Export formats for GoogleSourceConfig.DocFormats.
+const (
+	FormatMarkdown = "markdown"
+	FormatPDF      = "pdf"
+	FormatDOCX     = "docx"
+)
+
+var exportMimeTypes = map[string]string{
+	FormatMarkdown: "text/plain", // Plain text is the closest export to markdown
+	FormatPDF:      "application/pdf",
+	FormatDOCX:     "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+}
+
+var exportExtensions = map[string]string{
+	FormatMarkdown: ".md",
+	FormatPDF:      ".pdf",
+	FormatDOCX:     ".docx",
+}
+
+// ErrDocTooLarge reports a document skipped because it exceeds max_doc_size.
+type ErrDocTooLarge struct {
+	Size  int64
+	Limit int64
+}
+
+func (e *ErrDocTooLarge) Error() string {
+	return fmt.Sprintf("size %s exceeds max_doc_size %s", utils.FormatSize(e.Size), utils.FormatSize(e.Limit))
+}
+
+// ValidateDocFormats checks doc_formats values against the supported export formats.
+func ValidateDocFormats(formats []string) error {
+	for _, format := range formats {
+		if _, ok := exportMimeTypes[format]; !ok {
+			return fmt.Errorf("unsupported doc format '%s': supported formats are 'markdown', 'pdf', 'docx'", format)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,24 @@
+package drive
+
+import "testing"
+
+func TestValidateDocFormats(t *testing.T) {
+	if err := ValidateDocFormats([]string{FormatMarkdown, FormatPDF, FormatDOCX}); err != nil {
+		t.Errorf("ValidateDocFormats() error = %v", err)
+	}
+
+	if err := ValidateDocFormats([]string{"odt"}); err == nil {
+		t.Error("ValidateDocFormats() expected error for unsupported format")
+	}
+}
+
+func TestDocFilename(t *testing.T) {
+	if got := docFilename("Q1: Plan", FormatPDF); got != "Q1- Plan.pdf" {
+		t.Errorf("docFilename() = %q, want %q", got, "Q1- Plan.pdf")
+	}
+
+	err := &ErrDocTooLarge{Size: 15 << 20, Limit: 10 << 20}
+	if got := err.Error(); got != "size 15MB exceeds max_doc_size 10MB" {
+		t.Errorf("ErrDocTooLarge.Error() = %q", got)
+	}
+}
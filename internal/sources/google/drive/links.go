@@ -0,0 +1,72 @@
+package drive
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	// linkedFilePattern matches links to Docs, Slides, Sheets and Drive files, such as
+	// https://docs.google.com/document/d/FILE_ID/edit or https://drive.google.com/open?id=FILE_ID.
+	linkedFilePattern = regexp.MustCompile(`https://(?:docs\.google\.com/(document|presentation|spreadsheets)/d/|` +
+		`drive\.google\.com/(?:file/d/|open\?id=))([A-Za-z0-9_-]+)[^\s"'<>)\]]*`)
+
+	// anchorPattern matches HTML links, capturing their target and text.
+	anchorPattern = regexp.MustCompile(`(?is)<a\s[^>]*href\s*=\s*["']([^"']+)["'][^>]*>(.*?)</a>`)
+
+	tagPattern = regexp.MustCompile(`<[^>]+>`)
+)
+
+var linkedFileMimeTypes = map[string]string{
+	"document":     "application/vnd.google-apps.document",
+	"presentation": slidesMimeType,
+	"spreadsheets": "application/vnd.google-apps.spreadsheet",
+}
+
+// LinkedFile is a Drive file linked from text, such as an event description.
+type LinkedFile struct {
+	ID       string
+	URL      string
+	Title    string // Text of the HTML link, if it was not the URL itself
+	MimeType string // Derived from the URL; empty for links to plain Drive files
+}
+
+// ExtractLinkedFiles returns the Drive files linked from plain text, markdown or HTML, in the order they
+// are first linked. A file linked several times is returned once.
+func ExtractLinkedFiles(text string) []LinkedFile {
+	titles := make(map[string]string)
+
+	for _, anchor := range anchorPattern.FindAllStringSubmatch(text, -1) {
+		match := linkedFilePattern.FindStringSubmatch(html.UnescapeString(anchor[1]))
+		if match == nil {
+			continue
+		}
+
+		title := strings.TrimSpace(html.UnescapeString(tagPattern.ReplaceAllString(anchor[2], "")))
+		if _, seen := titles[match[2]]; !seen && title != "" && !strings.HasPrefix(title, "https://") {
+			titles[match[2]] = title
+		}
+	}
+
+	var files []LinkedFile
+
+	seen := make(map[string]bool)
+
+	for _, match := range linkedFilePattern.FindAllStringSubmatch(html.UnescapeString(text), -1) {
+		id := match[2]
+		if seen[id] {
+			continue
+		}
+
+		seen[id] = true
+		files = append(files, LinkedFile{
+			ID:       id,
+			URL:      strings.TrimRight(match[0], ".,;:!?"), // Sentence punctuation after a plain URL
+			Title:    titles[id],
+			MimeType: linkedFileMimeTypes[match[1]],
+		})
+	}
+
+	return files
+}
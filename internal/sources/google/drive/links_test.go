@@ -0,0 +1,37 @@
+package drive
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExtractLinkedFiles(t *testing.T) {
+	text := `Notes: https://docs.google.com/document/d/doc1/edit?usp=sharing, deck
+<a href="https://docs.google.com/presentation/d/deck2/edit">Q3 <b>deck</b></a> and
+<a href="https://docs.google.com/document/d/doc1/edit">https://docs.google.com/document/d/doc1/edit</a>
+https://drive.google.com/open?id=file3&amp;authuser=0 (https://docs.google.com/spreadsheets/d/sheet4/edit#gid=0)
+https://example.com/d/other`
+
+	files := ExtractLinkedFiles(text)
+
+	ids := make([]string, 0, len(files))
+	for _, file := range files {
+		ids = append(ids, file.ID)
+	}
+
+	if want := []string{"doc1", "deck2", "file3", "sheet4"}; !slices.Equal(ids, want) {
+		t.Fatalf("IDs = %v, want %v", ids, want)
+	}
+
+	if files[0].Title != "" || files[0].URL != "https://docs.google.com/document/d/doc1/edit?usp=sharing" {
+		t.Errorf("doc1 = %+v, want no title and the full URL", files[0])
+	}
+
+	if files[1].Title != "Q3 deck" || files[1].MimeType != slidesMimeType {
+		t.Errorf("deck2 = %+v, want title 'Q3 deck' and the Slides type", files[1])
+	}
+
+	if files[2].MimeType != "" || files[3].MimeType != "application/vnd.google-apps.spreadsheet" {
+		t.Errorf("MIME types = %q, %q", files[2].MimeType, files[3].MimeType)
+	}
+}
@@ -2,21 +2,29 @@ package drive
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"pkm-sync/pkg/models"
 
 	"google.golang.org/api/drive/v3"
 	"google.golang.org/api/option"
+	"google.golang.org/api/slides/v1"
 )
 
 type Service struct {
-	client *drive.Service
+	client      *drive.Service
+	slides      *slides.Service
+	httpClient  *http.Client
+	docFormats  []string
+	maxDocSize  int64  // Bytes; 0 means no limit
+	docComments string // Comment style for markdown exports; empty leaves comments out
 }
 
 func NewService(httpClient *http.Client) (*Service, error) {
@@ -25,12 +33,41 @@ func NewService(httpClient *http.Client) (*Service, error) {
 		return nil, fmt.Errorf("unable to retrieve Drive client: %w", err)
 	}
 
-	return &Service{client: driveService}, nil
+	slidesService, err := slides.NewService(context.Background(), option.WithHTTPClient(httpClient))
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve Slides client: %w", err)
+	}
+
+	return &Service{
+		client:     driveService,
+		slides:     slidesService,
+		httpClient: httpClient,
+		docFormats: []string{FormatMarkdown}, // Default: markdown only
+	}, nil
+}
+
+// SetDocFormats configures the formats attached docs are exported in; an empty list keeps markdown only.
+func (s *Service) SetDocFormats(formats []string) {
+	if len(formats) == 0 {
+		formats = []string{FormatMarkdown}
+	}
+
+	s.docFormats = formats
+}
+
+// SetMaxDocSize configures the largest document, in bytes, that is exported; 0 disables the limit.
+func (s *Service) SetMaxDocSize(maxBytes int64) {
+	s.maxDocSize = maxBytes
+}
+
+// MaxDocSize returns the largest document, in bytes, that is exported; 0 means no limit.
+func (s *Service) MaxDocSize() int64 {
+	return s.maxDocSize
 }
 
 // GetFileMetadata retrieves metadata for a Google Drive file.
 func (s *Service) GetFileMetadata(fileID string) (*models.DriveFile, error) {
-	file, err := s.client.Files.Get(fileID).Fields("id,name,mimeType,webViewLink,modifiedTime,owners").Do()
+	file, err := s.client.Files.Get(fileID).Fields("id,name,mimeType,webViewLink,modifiedTime,owners,size").Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve file metadata: %w", err)
 	}
@@ -40,6 +77,11 @@ func (s *Service) GetFileMetadata(fileID string) (*models.DriveFile, error) {
 		Name:        file.Name,
 		MimeType:    file.MimeType,
 		WebViewLink: file.WebViewLink,
+		Size:        file.Size,
+	}
+
+	if modified, err := time.Parse(time.RFC3339, file.ModifiedTime); err == nil {
+		driveFile.ModifiedTime = modified
 	}
 
 	for _, owner := range file.Owners {
@@ -51,6 +93,27 @@ func (s *Service) GetFileMetadata(fileID string) (*models.DriveFile, error) {
 	return driveFile, nil
 }
 
+// exportIsCurrent reports whether an earlier export is still up to date. Exports take the document's
+// modified time as their file time, like a Last-Modified date, so an unchanged document is neither
+// downloaded nor converted again.
+func exportIsCurrent(path string, modified time.Time) bool {
+	if modified.IsZero() {
+		return false
+	}
+
+	info, err := os.Stat(path)
+
+	// Some filesystems keep file times to the second only
+	return err == nil && info.ModTime().Truncate(time.Second).Equal(modified.Truncate(time.Second))
+}
+
+// markExported stamps an export with the modified time of its document for exportIsCurrent.
+func markExported(path string, modified time.Time) {
+	if !modified.IsZero() {
+		_ = os.Chtimes(path, time.Now(), modified)
+	}
+}
+
 // IsGoogleDoc checks if a file is a Google Doc that can be exported to markdown.
 func (s *Service) IsGoogleDoc(mimeType string) bool {
 	return mimeType == "application/vnd.google-apps.document"
@@ -62,8 +125,13 @@ func (s *Service) ExportDocAsMarkdown(fileID string, outputPath string) error {
 		return fmt.Errorf("file %s is not a Google Doc", fileID)
 	}
 
-	// Export as plain text first (closest to markdown)
-	resp, err := s.client.Files.Export(fileID, "text/plain").Download()
+	return s.exportDoc(fileID, FormatMarkdown, outputPath)
+}
+
+// exportDoc downloads a Google Doc in the given format. Exports larger than max_doc_size are discarded
+// with an *ErrDocTooLarge.
+func (s *Service) exportDoc(fileID, format, outputPath string) error {
+	resp, err := s.client.Files.Export(fileID, exportMimeTypes[format]).Download()
 	if err != nil {
 		return fmt.Errorf("unable to export document: %w", err)
 	}
@@ -87,12 +155,37 @@ func (s *Service) ExportDocAsMarkdown(fileID string, outputPath string) error {
 		_ = outFile.Close()
 	}()
 
-	// Copy content to file
-	_, err = io.Copy(outFile, resp.Body)
+	// Copy content to file, reading one byte past the limit to detect oversized exports
+	var body io.Reader = resp.Body
+	if s.maxDocSize > 0 {
+		body = io.LimitReader(resp.Body, s.maxDocSize+1)
+	}
+
+	written, err := io.Copy(outFile, body)
 	if err != nil {
 		return fmt.Errorf("unable to write file content: %w", err)
 	}
 
+	if s.maxDocSize > 0 && written > s.maxDocSize {
+		_ = outFile.Close()
+		_ = os.Remove(outputPath)
+
+		return &ErrDocTooLarge{Size: written, Limit: s.maxDocSize}
+	}
+
+	if format == FormatMarkdown && s.docComments != "" {
+		_ = outFile.Close()
+
+		markdown, err := os.ReadFile(outputPath)
+		if err != nil {
+			return fmt.Errorf("unable to read exported document: %w", err)
+		}
+
+		if err := os.WriteFile(outputPath, s.addComments(fileID, markdown), 0644); err != nil {
+			return fmt.Errorf("unable to write document comments: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -106,72 +199,18 @@ func (s *Service) IsGoogleDocByID(fileID string) bool {
 	return s.IsGoogleDoc(file.MimeType)
 }
 
-// GetAttachmentsFromEvent extracts Google Drive file attachments from a calendar event.
+// GetAttachmentsFromEvent extracts the IDs of the Google Drive files linked from a calendar event's
+// description, whether written as plain URLs or HTML links.
 func (s *Service) GetAttachmentsFromEvent(eventDescription string) ([]string, error) {
 	var fileIDs []string
 
-	// Look for Google Drive links in the event description
-	// Google Drive links typically follow patterns like:
-	// https://docs.google.com/document/d/FILE_ID/edit
-	// https://drive.google.com/file/d/FILE_ID/view
-
-	lines := strings.Split(eventDescription, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, "docs.google.com/document/d/") {
-			// Extract file ID from Google Docs URL
-			if fileID := extractFileIDFromDocsURL(line); fileID != "" {
-				fileIDs = append(fileIDs, fileID)
-			}
-		} else if strings.Contains(line, "drive.google.com/file/d/") {
-			// Extract file ID from Google Drive URL
-			if fileID := extractFileIDFromDriveURL(line); fileID != "" {
-				fileIDs = append(fileIDs, fileID)
-			}
-		}
+	for _, file := range ExtractLinkedFiles(eventDescription) {
+		fileIDs = append(fileIDs, file.ID)
 	}
 
 	return fileIDs, nil
 }
 
-// extractFileIDFromDocsURL extracts file ID from Google Docs URL.
-func extractFileIDFromDocsURL(url string) string {
-	// Pattern: https://docs.google.com/document/d/FILE_ID/edit
-	parts := strings.Split(url, "/")
-	for i, part := range parts {
-		if part == "d" && i+1 < len(parts) {
-			fileID := parts[i+1]
-			// Remove any query parameters
-			if idx := strings.Index(fileID, "?"); idx != -1 {
-				fileID = fileID[:idx]
-			}
-
-			return fileID
-		}
-	}
-
-	return ""
-}
-
-// extractFileIDFromDriveURL extracts file ID from Google Drive URL.
-func extractFileIDFromDriveURL(url string) string {
-	// Pattern: https://drive.google.com/file/d/FILE_ID/view
-	parts := strings.Split(url, "/")
-	for i, part := range parts {
-		if part == "d" && i+1 < len(parts) {
-			fileID := parts[i+1]
-			// Remove any query parameters
-			if idx := strings.Index(fileID, "?"); idx != -1 {
-				fileID = fileID[:idx]
-			}
-
-			return fileID
-		}
-	}
-
-	return ""
-}
-
 // ExportAttachedDocsFromEvent exports all Google Docs attached to an event.
 func (s *Service) ExportAttachedDocsFromEvent(eventDescription, outputDir string) ([]string, error) {
 	fileIDs, err := s.GetAttachmentsFromEvent(eventDescription)
@@ -190,6 +229,29 @@ func (s *Service) ExportAttachedDocsFromEvent(eventDescription, outputDir string
 			continue
 		}
 
+		// Slides decks become an outline with one image per slide
+		if s.IsGoogleSlides(metadata.MimeType) {
+			if files, current := slidesExportIsCurrent(metadata, outputDir); current {
+				exportedFiles = append(exportedFiles, files...)
+				fmt.Printf("Unchanged: %s -> %s\n", metadata.Name, files[0])
+
+				continue
+			}
+
+			files, err := s.ExportSlidesDeck(fileID, metadata.Name, outputDir)
+			if err != nil {
+				fmt.Printf("Warning: Could not export %s: %v\n", metadata.Name, err)
+
+				continue
+			}
+
+			markExported(files[0], metadata.ModifiedTime)
+			exportedFiles = append(exportedFiles, files...)
+			fmt.Printf("Exported: %s -> %s (%d slide images)\n", metadata.Name, files[0], len(files)-1)
+
+			continue
+		}
+
 		// Only export Google Docs
 		if !s.IsGoogleDoc(metadata.MimeType) {
 			fmt.Printf("Skipping %s: not a Google Doc (type: %s)\n", metadata.Name, metadata.MimeType)
@@ -197,28 +259,57 @@ func (s *Service) ExportAttachedDocsFromEvent(eventDescription, outputDir string
 			continue
 		}
 
-		// Generate output filename
-		filename := sanitizeFilename(metadata.Name)
-		if !strings.HasSuffix(filename, ".md") {
-			filename += ".md"
+		// Drive reports a size for some docs; exports are checked against the limit as they download
+		if s.maxDocSize > 0 && metadata.Size > s.maxDocSize {
+			fmt.Printf("Skipping %s: %s\n", metadata.Name, &ErrDocTooLarge{Size: metadata.Size, Limit: s.maxDocSize})
+
+			continue
 		}
 
-		outputPath := filepath.Join(outputDir, filename)
+		for _, format := range s.docFormats {
+			outputPath := filepath.Join(outputDir, docFilename(metadata.Name, format))
 
-		// Export the document
-		if err := s.ExportDocAsMarkdown(fileID, outputPath); err != nil {
-			fmt.Printf("Warning: Could not export %s: %v\n", metadata.Name, err)
+			if exportIsCurrent(outputPath, metadata.ModifiedTime) {
+				exportedFiles = append(exportedFiles, outputPath)
+				fmt.Printf("Unchanged: %s -> %s\n", metadata.Name, outputPath)
 
-			continue
-		}
+				continue
+			}
+
+			if err := s.exportDoc(fileID, format, outputPath); err != nil {
+				var tooLarge *ErrDocTooLarge
+				if errors.As(err, &tooLarge) {
+					fmt.Printf("Skipping %s as %s: %v\n", metadata.Name, format, err)
+
+					continue
+				}
 
-		exportedFiles = append(exportedFiles, outputPath)
-		fmt.Printf("Exported: %s -> %s\n", metadata.Name, outputPath)
+				fmt.Printf("Warning: Could not export %s as %s: %v\n", metadata.Name, format, err)
+
+				continue
+			}
+
+			markExported(outputPath, metadata.ModifiedTime)
+			exportedFiles = append(exportedFiles, outputPath)
+			fmt.Printf("Exported: %s -> %s\n", metadata.Name, outputPath)
+		}
 	}
 
 	return exportedFiles, nil
 }
 
+// docFilename returns the file name of a doc exported in the given format.
+func docFilename(name, format string) string {
+	filename := sanitizeFilename(name)
+
+	extension := exportExtensions[format]
+	if !strings.HasSuffix(filename, extension) {
+		filename += extension
+	}
+
+	return filename
+}
+
 // sanitizeFilename removes or replaces characters that are invalid in filenames.
 func sanitizeFilename(filename string) string {
 	// Replace common problematic characters
@@ -0,0 +1,39 @@
+package drive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExportIsCurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Roadmap.md")
+	modified := time.Date(2025, 3, 3, 10, 15, 30, 250_000_000, time.UTC)
+
+	if exportIsCurrent(path, modified) {
+		t.Error("missing export reported as current")
+	}
+
+	if err := os.WriteFile(path, []byte("# Roadmap"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if exportIsCurrent(path, modified) {
+		t.Error("fresh file reported as current before it was marked")
+	}
+
+	markExported(path, modified)
+
+	if !exportIsCurrent(path, modified) {
+		t.Error("marked export not reported as current")
+	}
+
+	if exportIsCurrent(path, modified.Add(time.Minute)) {
+		t.Error("export of an older revision reported as current")
+	}
+
+	if exportIsCurrent(path, time.Time{}) {
+		t.Error("unknown modified time reported as current")
+	}
+}
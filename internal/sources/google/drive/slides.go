@@ -0,0 +1,255 @@
+package drive
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"pkm-sync/pkg/models"
+
+	"google.golang.org/api/slides/v1"
+)
+
+const slidesMimeType = "application/vnd.google-apps.presentation"
+
+// slideContent is the text of one slide, used to build the deck outline.
+type slideContent struct {
+	Title string
+	Body  []string
+	Notes []string
+	Image string // Path of the slide image relative to the outline, empty when it could not be rendered
+}
+
+// IsGoogleSlides checks if a file is a Google Slides deck.
+func (s *Service) IsGoogleSlides(mimeType string) bool {
+	return mimeType == slidesMimeType
+}
+
+// ExportSlidesDeck writes a markdown outline of a Slides deck with one PNG image per slide.
+// Images go to a "<deck> slides" folder next to the outline; skipped slides are left out.
+func (s *Service) ExportSlidesDeck(fileID, name, outputDir string) ([]string, error) {
+	presentation, err := s.slides.Presentations.Get(fileID).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve presentation: %w", err)
+	}
+
+	deckName := sanitizeFilename(name)
+	imageFolder := deckName + " slides"
+
+	if err := os.MkdirAll(filepath.Join(outputDir, imageFolder), 0755); err != nil {
+		return nil, fmt.Errorf("unable to create slide image directory: %w", err)
+	}
+
+	var (
+		contents []slideContent
+		written  []string
+	)
+
+	for _, page := range presentation.Slides {
+		if page.SlideProperties != nil && page.SlideProperties.IsSkipped {
+			continue
+		}
+
+		content := slideText(page)
+
+		imageName := fmt.Sprintf("slide-%02d.png", len(contents)+1)
+		imagePath := filepath.Join(outputDir, imageFolder, imageName)
+
+		if err := s.downloadSlideImage(fileID, page.ObjectId, imagePath); err != nil {
+			fmt.Printf("Warning: Could not render slide %d of %s: %v\n", len(contents)+1, name, err)
+		} else {
+			content.Image = imageFolder + "/" + imageName
+			written = append(written, imagePath)
+		}
+
+		contents = append(contents, content)
+	}
+
+	outlinePath := filepath.Join(outputDir, deckName+".md")
+	if err := os.WriteFile(outlinePath, []byte(slidesOutline(name, contents)), 0644); err != nil {
+		return nil, fmt.Errorf("unable to write slides outline: %w", err)
+	}
+
+	return append([]string{outlinePath}, written...), nil
+}
+
+// slidesExportIsCurrent returns the outline and slide images of an earlier export of a deck when it is
+// still up to date.
+func slidesExportIsCurrent(metadata *models.DriveFile, outputDir string) ([]string, bool) {
+	deckName := sanitizeFilename(metadata.Name)
+
+	outlinePath := filepath.Join(outputDir, deckName+".md")
+	if !exportIsCurrent(outlinePath, metadata.ModifiedTime) {
+		return nil, false
+	}
+
+	files := []string{outlinePath}
+
+	entries, _ := os.ReadDir(filepath.Join(outputDir, deckName+" slides"))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "slide-") && strings.HasSuffix(entry.Name(), ".png") {
+			files = append(files, filepath.Join(outputDir, deckName+" slides", entry.Name()))
+		}
+	}
+
+	return files, true
+}
+
+// downloadSlideImage saves a large PNG rendering of a slide.
+func (s *Service) downloadSlideImage(presentationID, pageID, outputPath string) error {
+	thumbnail, err := s.slides.Presentations.Pages.GetThumbnail(presentationID, pageID).
+		ThumbnailPropertiesMimeType("PNG").
+		ThumbnailPropertiesThumbnailSize("LARGE").
+		Do()
+	if err != nil {
+		return fmt.Errorf("unable to render slide: %w", err)
+	}
+
+	resp, err := s.httpClient.Get(thumbnail.ContentUrl)
+	if err != nil {
+		return fmt.Errorf("unable to download slide image: %w", err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to download slide image: status %d", resp.StatusCode)
+	}
+
+	outFile, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("unable to create slide image: %w", err)
+	}
+
+	defer func() {
+		_ = outFile.Close()
+	}()
+
+	if _, err := io.Copy(outFile, resp.Body); err != nil {
+		return fmt.Errorf("unable to write slide image: %w", err)
+	}
+
+	return nil
+}
+
+// slideText extracts the title, body text and speaker notes of a slide.
+func slideText(page *slides.Page) slideContent {
+	var content slideContent
+
+	for _, element := range flattenElements(page.PageElements) {
+		if element.Shape == nil || element.Shape.Text == nil {
+			continue
+		}
+
+		paragraphs := textParagraphs(element.Shape.Text)
+		if len(paragraphs) == 0 {
+			continue
+		}
+
+		if placeholder := element.Shape.Placeholder; content.Title == "" && placeholder != nil &&
+			(placeholder.Type == "TITLE" || placeholder.Type == "CENTERED_TITLE") {
+			content.Title = strings.Join(paragraphs, " ")
+
+			continue
+		}
+
+		content.Body = append(content.Body, paragraphs...)
+	}
+
+	if page.SlideProperties != nil && page.SlideProperties.NotesPage != nil {
+		notesPage := page.SlideProperties.NotesPage
+
+		var notesID string
+		if notesPage.NotesProperties != nil {
+			notesID = notesPage.NotesProperties.SpeakerNotesObjectId
+		}
+
+		for _, element := range notesPage.PageElements {
+			if element.ObjectId == notesID && element.Shape != nil && element.Shape.Text != nil {
+				content.Notes = textParagraphs(element.Shape.Text)
+			}
+		}
+	}
+
+	return content
+}
+
+// flattenElements expands grouped page elements.
+func flattenElements(elements []*slides.PageElement) []*slides.PageElement {
+	var flat []*slides.PageElement
+
+	for _, element := range elements {
+		if element.ElementGroup != nil {
+			flat = append(flat, flattenElements(element.ElementGroup.Children)...)
+
+			continue
+		}
+
+		flat = append(flat, element)
+	}
+
+	return flat
+}
+
+// textParagraphs joins the text runs of a shape into non-empty paragraphs.
+func textParagraphs(text *slides.TextContent) []string {
+	var sb strings.Builder
+
+	for _, element := range text.TextElements {
+		if element.TextRun != nil {
+			sb.WriteString(element.TextRun.Content)
+		}
+	}
+
+	var paragraphs []string
+
+	for _, line := range strings.Split(sb.String(), "\n") {
+		// Slides uses vertical tabs for line breaks within a paragraph
+		if line = strings.TrimSpace(strings.ReplaceAll(line, "\v", " ")); line != "" {
+			paragraphs = append(paragraphs, line)
+		}
+	}
+
+	return paragraphs
+}
+
+// slidesOutline renders the deck as markdown: one section per slide with its image, text and notes.
+func slidesOutline(deckTitle string, contents []slideContent) string {
+	var sb strings.Builder
+
+	sb.WriteString("# " + deckTitle + "\n")
+
+	for i, content := range contents {
+		heading := fmt.Sprintf("Slide %d", i+1)
+		if content.Title != "" {
+			heading += ": " + content.Title
+		}
+
+		sb.WriteString("\n## " + heading + "\n\n")
+
+		if content.Image != "" {
+			sb.WriteString(fmt.Sprintf("![Slide %d](%s)\n\n", i+1, strings.ReplaceAll(content.Image, " ", "%20")))
+		}
+
+		for _, line := range content.Body {
+			sb.WriteString("- " + line + "\n")
+		}
+
+		if len(content.Notes) > 0 {
+			if len(content.Body) > 0 {
+				sb.WriteString("\n")
+			}
+
+			for _, line := range content.Notes {
+				sb.WriteString("> " + line + "\n")
+			}
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,65 @@
+package drive
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/api/slides/v1"
+)
+
+func textShape(placeholder string, text string) *slides.PageElement {
+	shape := &slides.Shape{
+		Text: &slides.TextContent{TextElements: []*slides.TextElement{{TextRun: &slides.TextRun{Content: text}}}},
+	}
+	if placeholder != "" {
+		shape.Placeholder = &slides.Placeholder{Type: placeholder}
+	}
+
+	return &slides.PageElement{ObjectId: placeholder + text, Shape: shape}
+}
+
+func TestSlideText(t *testing.T) {
+	page := &slides.Page{
+		PageElements: []*slides.PageElement{
+			textShape("TITLE", "Roadmap\n"),
+			{ElementGroup: &slides.Group{Children: []*slides.PageElement{
+				textShape("BODY", "Ship sync\n\nAdd\vSlides\n"),
+			}}},
+		},
+		SlideProperties: &slides.SlideProperties{NotesPage: &slides.Page{
+			NotesProperties: &slides.NotesProperties{SpeakerNotesObjectId: "notes"},
+			PageElements: []*slides.PageElement{
+				{ObjectId: "notes", Shape: &slides.Shape{Text: &slides.TextContent{
+					TextElements: []*slides.TextElement{{TextRun: &slides.TextRun{Content: "Mention the beta\n"}}},
+				}}},
+			},
+		}},
+	}
+
+	content := slideText(page)
+	if content.Title != "Roadmap" {
+		t.Errorf("Title = %q, want %q", content.Title, "Roadmap")
+	}
+
+	if strings.Join(content.Body, "|") != "Ship sync|Add Slides" {
+		t.Errorf("Body = %q", content.Body)
+	}
+
+	if len(content.Notes) != 1 || content.Notes[0] != "Mention the beta" {
+		t.Errorf("Notes = %q", content.Notes)
+	}
+}
+
+func TestSlidesOutline(t *testing.T) {
+	outline := slidesOutline("Q1 Review", []slideContent{
+		{Title: "Roadmap", Body: []string{"Ship sync"}, Notes: []string{"Mention the beta"}, Image: "Q1 Review slides/slide-01.png"},
+		{Body: []string{"Questions?"}},
+	})
+
+	expected := "# Q1 Review\n\n" +
+		"## Slide 1: Roadmap\n\n![Slide 1](Q1%20Review%20slides/slide-01.png)\n\n- Ship sync\n\n> Mention the beta\n\n" +
+		"## Slide 2\n\n- Questions?\n"
+	if outline != expected {
+		t.Errorf("slidesOutline() =\n%q\nwant\n%q", outline, expected)
+	}
+}
@@ -0,0 +1,282 @@
+package google
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"pkm-sync/internal/config"
+	"pkm-sync/internal/sources/google/drive"
+	"pkm-sync/internal/statestore"
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/models"
+)
+
+// driveFolderState is the Drive changes token saved after a folder sync, so the next sync only
+// fetches files changed since.
+type driveFolderState struct {
+	FolderID  string `json:"folder_id"`
+	PageToken string `json:"page_token"`
+}
+
+// DriveStateFile returns the name of the state file of a google_drive source instance in the config directory.
+func DriveStateFile(source string) string {
+	return "drive-state-" + source + ".json"
+}
+
+// driveStatePath returns the state file of a google_drive source instance in the config directory.
+func (g *GoogleSource) driveStatePath() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, DriveStateFile(g.Name())), nil
+}
+
+// loadDriveFolderState reads the saved changes token; a missing file means the folder was never synced.
+func loadDriveFolderState(statePath string) (driveFolderState, error) {
+	var state driveFolderState
+
+	data, err := statestore.ReadFile(statePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+
+		return state, fmt.Errorf("failed to read Drive sync state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("failed to parse Drive sync state: %w", err)
+	}
+
+	return state, nil
+}
+
+// saveDriveFolderState persists the changes token for the next sync.
+func saveDriveFolderState(statePath string, state driveFolderState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode Drive sync state: %w", err)
+	}
+
+	if err := statestore.WriteFile(statePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write Drive sync state: %w", err)
+	}
+
+	return nil
+}
+
+// SaveSyncState saves the Drive changes token of the last fetch, once the files it returned are exported.
+func (g *GoogleSource) SaveSyncState() error {
+	if g.driveState == nil {
+		return nil
+	}
+
+	statePath, err := g.driveStatePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate Drive sync state: %w", err)
+	}
+
+	if err := saveDriveFolderState(statePath, *g.driveState); err != nil {
+		return err
+	}
+
+	g.driveState = nil
+
+	return nil
+}
+
+// fetchDriveFolder mirrors the configured Drive folder. The first sync returns every file below it;
+// later syncs return only files the Drive changes API reports as changed since the previous sync. The new
+// changes token is kept for SaveSyncState, unless limit cut the files short, so the next sync fetches them
+// again.
+func (g *GoogleSource) fetchDriveFolder(_ time.Time, limit int) ([]models.ItemInterface, error) {
+	if g.driveService == nil {
+		return nil, fmt.Errorf("drive service not initialized")
+	}
+
+	g.driveState = nil
+
+	folderID := g.config.Google.FolderID
+
+	statePath, err := g.driveStatePath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate Drive sync state: %w", err)
+	}
+
+	state, err := loadDriveFolderState(statePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// A changed folder_id invalidates the saved token: mirror the new folder in full
+	var changed map[string]bool
+
+	nextToken := ""
+
+	if state.FolderID == folderID && state.PageToken != "" {
+		changed, nextToken, err = g.driveService.ListChangedFileIDs(state.PageToken)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch Drive changes: %w", err)
+		}
+
+		if len(changed) == 0 {
+			g.driveState = &driveFolderState{FolderID: folderID, PageToken: nextToken}
+
+			return nil, nil
+		}
+	} else {
+		// Taken before listing so changes made while the folder is walked are picked up next time
+		nextToken, err = g.driveService.GetStartPageToken()
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch Drive changes token: %w", err)
+		}
+	}
+
+	folderName, err := g.driveService.GetFolderName(folderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Drive folder: %w", err)
+	}
+
+	files, err := g.driveService.ListFolderFiles(folderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Drive folder: %w", err)
+	}
+
+	files = filterChangedFiles(files, changed)
+	items := make([]models.ItemInterface, 0, len(files))
+	truncated := false
+
+	for _, file := range files {
+		if limit > 0 && len(items) >= limit {
+			truncated = true
+
+			break
+		}
+
+		item, err := g.driveFileItem(folderName, file)
+		if err != nil {
+			var tooLarge *drive.ErrDocTooLarge
+			if errors.As(err, &tooLarge) {
+				fmt.Printf("Skipping %s: %v\n", file.Name, err)
+
+				continue
+			}
+
+			fmt.Printf("Warning: Could not fetch %s: %v\n", file.Name, err)
+
+			continue
+		}
+
+		if item != nil {
+			items = append(items, item)
+		}
+	}
+
+	if !truncated {
+		g.driveState = &driveFolderState{FolderID: folderID, PageToken: nextToken}
+	}
+
+	return items, nil
+}
+
+// filterChangedFiles keeps the files reported as changed; a nil set keeps every file.
+func filterChangedFiles(files []drive.FolderFile, changed map[string]bool) []drive.FolderFile {
+	if changed == nil {
+		return files
+	}
+
+	var kept []drive.FolderFile
+
+	for _, file := range files {
+		if changed[file.ID] {
+			kept = append(kept, file)
+		}
+	}
+
+	return kept
+}
+
+// driveFileItem fetches the content of a folder file: Google Docs are exported to markdown, binary files
+// are attached. Other native Google files (Sheets, Slides, ...) have no exportable content and are skipped.
+func (g *GoogleSource) driveFileItem(folderName string, file drive.FolderFile) (models.ItemInterface, error) {
+	switch {
+	case g.driveService.IsGoogleDoc(file.MimeType):
+		content, err := g.driveService.ExportDocContent(file.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		return newDriveFileItem(folderName, file, string(content), nil), nil
+	case g.driveService.IsGoogleNative(file.MimeType):
+		fmt.Printf("Skipping %s: not a Google Doc or binary file (type: %s)\n", file.Name, file.MimeType)
+
+		return nil, nil
+	default:
+		// Drive reports the size of binary files, so oversized files are skipped before downloading
+		if limit := g.driveService.MaxDocSize(); limit > 0 && file.Size > limit {
+			return nil, &drive.ErrDocTooLarge{Size: file.Size, Limit: limit}
+		}
+
+		data, err := g.driveService.DownloadFile(file.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		return newDriveFileItem(folderName, file, "", data), nil
+	}
+}
+
+// newDriveFileItem builds the item for a folder file. Docs carry their markdown as content; binary files
+// carry their data as an attachment. The item's folder mirrors its place in the Drive folder.
+func newDriveFileItem(folderName string, file drive.FolderFile, content string, data []byte) models.ItemInterface {
+	title := file.Name
+	itemType := "document"
+
+	var attachments []models.Attachment
+
+	if data != nil {
+		title = strings.TrimSuffix(file.Name, filepath.Ext(file.Name))
+		itemType = "file"
+		attachments = []models.Attachment{{
+			ID:       file.ID,
+			Name:     file.Name,
+			MimeType: file.MimeType,
+			URL:      file.WebViewLink,
+			Data:     base64.StdEncoding.EncodeToString(data),
+			Size:     int64(len(data)),
+		}}
+	}
+
+	item := &models.Item{
+		ID:          file.ID,
+		Title:       title,
+		Content:     content,
+		SourceType:  SourceTypeDrive,
+		ItemType:    itemType,
+		CreatedAt:   file.ModifiedTime,
+		UpdatedAt:   file.ModifiedTime,
+		Tags:        []string{},
+		Attachments: attachments,
+		Metadata: map[string]interface{}{
+			utils.FolderMetadataKey: path.Join(folderName, file.Path),
+			"drive_path":            path.Join(file.Path, file.Name),
+			"mime_type":             file.MimeType,
+			"owners":                file.Owners,
+		},
+	}
+
+	if file.WebViewLink != "" {
+		item.Links = []models.Link{{URL: file.WebViewLink, Title: "Open in Google Drive", Type: "document"}}
+	}
+
+	return models.AsItemInterface(item)
+}
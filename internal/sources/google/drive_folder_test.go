@@ -0,0 +1,119 @@
+package google
+
+import (
+	"encoding/base64"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pkm-sync/internal/config"
+	"pkm-sync/internal/sources/google/drive"
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDriveFileItem(t *testing.T) {
+	modified := time.Date(2025, 1, 13, 9, 0, 0, 0, time.UTC)
+
+	doc := drive.FolderFile{
+		DriveFile: models.DriveFile{
+			ID:           "doc-1",
+			Name:         "Roadmap",
+			MimeType:     "application/vnd.google-apps.document",
+			WebViewLink:  "https://docs.google.com/document/d/doc-1/edit",
+			ModifiedTime: modified,
+		},
+		Path: "Planning/2025",
+	}
+
+	item := newDriveFileItem("Team", doc, "# Roadmap\n", nil)
+	assert.Equal(t, "Roadmap", item.GetTitle())
+	assert.Equal(t, "document", item.GetItemType())
+	assert.Equal(t, SourceTypeDrive, item.GetSourceType())
+	assert.Equal(t, "# Roadmap\n", item.GetContent())
+	assert.Equal(t, modified, item.GetUpdatedAt())
+	assert.Equal(t, "Team/Planning/2025", item.GetMetadata()[utils.FolderMetadataKey])
+	assert.Equal(t, "Planning/2025/Roadmap", item.GetMetadata()["drive_path"])
+	assert.Empty(t, item.GetAttachments())
+	require.Len(t, item.GetLinks(), 1)
+	assert.Equal(t, doc.WebViewLink, item.GetLinks()[0].URL)
+
+	binary := drive.FolderFile{
+		DriveFile: models.DriveFile{ID: "pdf-1", Name: "Contract.pdf", MimeType: "application/pdf"},
+	}
+
+	item = newDriveFileItem("Team", binary, "", []byte("%PDF-1.7"))
+	assert.Equal(t, "Contract", item.GetTitle())
+	assert.Equal(t, "file", item.GetItemType())
+	assert.Equal(t, "Team", item.GetMetadata()[utils.FolderMetadataKey])
+	assert.Empty(t, item.GetLinks())
+	require.Len(t, item.GetAttachments(), 1)
+
+	attachment := item.GetAttachments()[0]
+	assert.Equal(t, "Contract.pdf", attachment.Name)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("%PDF-1.7")), attachment.Data)
+	assert.Equal(t, int64(8), attachment.Size)
+}
+
+func TestFilterChangedFiles(t *testing.T) {
+	files := []drive.FolderFile{
+		{DriveFile: models.DriveFile{ID: "a"}},
+		{DriveFile: models.DriveFile{ID: "b"}},
+		{DriveFile: models.DriveFile{ID: "c"}},
+	}
+
+	assert.Equal(t, files, filterChangedFiles(files, nil), "first sync keeps every file")
+	assert.Empty(t, filterChangedFiles(files, map[string]bool{"other": true}))
+
+	kept := filterChangedFiles(files, map[string]bool{"c": true, "a": true})
+	require.Len(t, kept, 2)
+	assert.Equal(t, "a", kept[0].ID)
+	assert.Equal(t, "c", kept[1].ID)
+}
+
+func TestDriveFolderStateRoundTrip(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "drive-state-team_drive.json")
+
+	state, err := loadDriveFolderState(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, driveFolderState{}, state, "missing state means the folder was never synced")
+
+	saved := driveFolderState{FolderID: "folder-1", PageToken: "12345"}
+	require.NoError(t, saveDriveFolderState(statePath, saved))
+
+	state, err = loadDriveFolderState(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, saved, state)
+}
+
+func TestSaveSyncState(t *testing.T) {
+	configDir := t.TempDir()
+	config.SetCustomConfigDir(configDir)
+
+	t.Cleanup(func() { config.SetCustomConfigDir("") })
+
+	statePath := filepath.Join(configDir, DriveStateFile("team_drive"))
+	source := NewGoogleSourceWithConfig("team_drive", models.SourceConfig{Type: SourceTypeDrive})
+
+	require.NoError(t, source.SaveSyncState(), "a fetch cut short leaves nothing to save")
+	assert.NoFileExists(t, statePath)
+
+	source.driveState = &driveFolderState{FolderID: "folder-1", PageToken: "12345"}
+	require.NoError(t, source.SaveSyncState())
+
+	state, err := loadDriveFolderState(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, driveFolderState{FolderID: "folder-1", PageToken: "12345"}, state)
+	assert.Nil(t, source.driveState)
+}
+
+func TestDriveSourceRequiresFolderID(t *testing.T) {
+	source := NewGoogleSourceWithConfig("team_drive", models.SourceConfig{Type: SourceTypeDrive})
+	assert.Equal(t, "team_drive", source.Name())
+
+	err := source.initializeDriveService(nil)
+	assert.ErrorContains(t, err, "folder_id is required")
+}
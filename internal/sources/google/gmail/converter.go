@@ -3,19 +3,21 @@ package gmail
 import (
 	"fmt"
 	"net/mail"
+	"regexp"
 	"strings"
 	"time"
 
 	"pkm-sync/pkg/models"
+	"pkm-sync/pkg/query"
 
 	"google.golang.org/api/gmail/v1"
 )
 
+// messageIDPattern matches a bracketed Message-ID in an In-Reply-To or References header.
+var messageIDPattern = regexp.MustCompile(`<([^<>\s]+)>`)
+
 // EmailRecipient represents an email recipient with name and email.
-type EmailRecipient struct {
-	Name  string `json:"name"`
-	Email string `json:"email"`
-}
+type EmailRecipient = models.Recipient
 
 // FromGmailMessage converts a Gmail message to the universal Item format.
 func FromGmailMessage(msg *gmail.Message, config models.GmailSourceConfig) (*models.Item, error) {
@@ -56,7 +58,7 @@ func FromGmailMessageWithService(
 		CreatedAt:  createdAt,
 		UpdatedAt:  createdAt, // Gmail doesn't track modifications, use creation date
 		Metadata:   make(map[string]interface{}),
-		Tags:       buildTags(msg, config),
+		Tags:       buildTags(msg, config, service.labelTagsOrNil()),
 	}
 
 	// Extract comprehensive metadata
@@ -149,6 +151,22 @@ func getProcessedBody(msg *gmail.Message, config models.GmailSourceConfig) (stri
 	return processor.ProcessEmailBody(msg)
 }
 
+// messageIDs returns the Message-IDs of an In-Reply-To or References header without their angle brackets.
+// Headers written without brackets are split on whitespace.
+func messageIDs(header string) []string {
+	var ids []string
+
+	for _, match := range messageIDPattern.FindAllStringSubmatch(header, -1) {
+		ids = append(ids, match[1])
+	}
+
+	if len(ids) == 0 {
+		ids = strings.Fields(header)
+	}
+
+	return ids
+}
+
 // addBasicMetadata adds basic email metadata to the item.
 func addBasicMetadata(item *models.Item, msg *gmail.Message) {
 	item.Metadata["message_id"] = getHeader(msg, "message-id")
@@ -157,10 +175,31 @@ func addBasicMetadata(item *models.Item, msg *gmail.Message) {
 	item.Metadata["snippet"] = msg.Snippet
 	item.Metadata["size"] = msg.SizeEstimate
 
+	// Threading headers link the copies of a conversation in several accounts
+	if inReplyTo := messageIDs(getHeader(msg, "in-reply-to")); len(inReplyTo) > 0 {
+		item.Metadata[models.MetadataInReplyTo] = inReplyTo[0]
+	}
+
+	if references := messageIDs(getHeader(msg, "references")); len(references) > 0 {
+		item.Metadata[models.MetadataReferences] = references
+	}
+
 	// Add reply-to if present
 	if replyTo := getHeader(msg, "reply-to"); replyTo != "" {
 		item.Metadata["reply_to"] = replyTo
 	}
+
+	// Mailing list headers tell newsletters and notifications from personal mail
+	for key, header := range map[string]string{
+		models.MetadataListID:          "list-id",
+		models.MetadataListUnsubscribe: "list-unsubscribe",
+		models.MetadataPrecedence:      "precedence",
+		models.MetadataAutoSubmitted:   "auto-submitted",
+	} {
+		if value := getHeader(msg, header); value != "" {
+			item.Metadata[key] = value
+		}
+	}
 }
 
 // addRecipientMetadata extracts and adds recipient information to metadata.
@@ -325,8 +364,9 @@ func splitEmailAddresses(addressList string) []string {
 	return addresses
 }
 
-// buildTags builds tags for the email based on configuration and message properties.
-func buildTags(msg *gmail.Message, config models.GmailSourceConfig) []string {
+// buildTags builds tags for the email based on configuration and message properties. labelTags maps the
+// label IDs of tag_labels to their tags.
+func buildTags(msg *gmail.Message, config models.GmailSourceConfig, labelTags map[string]string) []string {
 	var tags []string
 
 	// Add source identifier.
@@ -335,20 +375,11 @@ func buildTags(msg *gmail.Message, config models.GmailSourceConfig) []string {
 	// Add labels as tags.
 	for _, labelID := range msg.LabelIds {
 		// Convert system labels to readable tags.
-		switch labelID {
-		case "IMPORTANT":
-			tags = append(tags, "important")
-		case "STARRED":
-			tags = append(tags, "starred")
-		case "UNREAD":
-			tags = append(tags, "unread")
-		case "INBOX":
-			tags = append(tags, "inbox")
-		case "SENT":
-			tags = append(tags, "sent")
-		case "DRAFT":
-			tags = append(tags, "draft")
-		default:
+		if tag, ok := systemLabelTags[labelID]; ok {
+			tags = append(tags, tag)
+		} else if tag, ok := labelTags[labelID]; ok {
+			tags = append(tags, tag)
+		} else {
 			// Use label as-is for custom labels.
 			tags = append(tags, labelID)
 		}
@@ -369,39 +400,58 @@ func buildTags(msg *gmail.Message, config models.GmailSourceConfig) []string {
 	return tags
 }
 
-// matchesCondition checks if a message matches a tagging rule condition.
+// matchesCondition checks if a message matches a tagging rule condition, written in the query language
+// (e.g. `from ~ "@company.com" AND has:attachment`). Invalid conditions never match; they are rejected
+// when the configuration is validated.
 func matchesCondition(msg *gmail.Message, condition string) bool {
-	// Simple condition matching - could be enhanced.
-	condition = strings.ToLower(condition)
-
-	if strings.HasPrefix(condition, "from:") {
-		fromEmail := getHeader(msg, "from")
-		targetEmail := strings.TrimPrefix(condition, "from:")
-
-		return strings.Contains(strings.ToLower(fromEmail), targetEmail)
+	q, err := query.Parse(condition)
+	if err != nil {
+		return false
 	}
 
-	if strings.HasPrefix(condition, "subject:") {
-		subject := getSubject(msg)
-		targetSubject := strings.TrimPrefix(condition, "subject:")
+	return q.Match(messageRecord{msg: msg})
+}
 
-		return strings.Contains(strings.ToLower(subject), targetSubject)
-	}
+// messageRecord evaluates tagging rule conditions against a raw Gmail message.
+type messageRecord struct {
+	msg *gmail.Message
+}
 
-	if condition == "has:attachment" {
-		return hasAttachments(msg)
+func (r messageRecord) Values(field string) []string {
+	switch field {
+	case "subject", "title":
+		return []string{getSubject(r.msg)}
+	case "body", "content", "snippet":
+		return []string{r.msg.Snippet}
+	case query.FieldText:
+		return []string{getSubject(r.msg), r.msg.Snippet}
+	case "label", "labels":
+		return r.msg.LabelIds
+	case "id":
+		return []string{r.msg.Id}
+	case "source":
+		return []string{"gmail"}
+	case "type":
+		return []string{"email"}
+	default:
+		// Headers such as from, to, cc, reply-to or list-id
+		return []string{getHeader(r.msg, field)}
 	}
+}
 
-	if strings.HasPrefix(condition, "label:") {
-		targetLabel := strings.TrimPrefix(condition, "label:")
-		for _, label := range msg.LabelIds {
-			if strings.ToLower(label) == targetLabel {
+func (r messageRecord) Has(name string) bool {
+	switch name {
+	case "attachment", "attachments":
+		return hasAttachments(r.msg)
+	default:
+		for _, value := range r.Values(name) {
+			if value != "" {
 				return true
 			}
 		}
-	}
 
-	return false
+		return false
+	}
 }
 
 // hasAttachments checks if a message has attachments.
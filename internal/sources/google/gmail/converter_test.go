@@ -1,6 +1,7 @@
 package gmail
 
 import (
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -119,6 +120,21 @@ func TestFromGmailMessage(t *testing.T) {
 	}
 }
 
+func TestMessageIDs(t *testing.T) {
+	got := messageIDs("<a@mail.example>\r\n <b@mail.example>,<c@mail.example>")
+	if want := []string{"a@mail.example", "b@mail.example", "c@mail.example"}; !slices.Equal(got, want) {
+		t.Errorf("messageIDs() = %v, want %v", got, want)
+	}
+
+	if got := messageIDs("a@mail.example"); !slices.Equal(got, []string{"a@mail.example"}) {
+		t.Errorf("messageIDs() of an unbracketed ID = %v", got)
+	}
+
+	if got := messageIDs(""); len(got) != 0 {
+		t.Errorf("messageIDs(\"\") = %v, want none", got)
+	}
+}
+
 func TestParseEmailAddress(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -239,7 +255,7 @@ func TestBuildTags(t *testing.T) {
 		},
 	}
 
-	tags := buildTags(msg, config)
+	tags := buildTags(msg, config, nil)
 
 	expectedTags := []string{"gmail", "important", "starred", "inbox", "high-priority", "source:work-emails"}
 	if !containsAll(tags, expectedTags) {
@@ -285,6 +301,21 @@ func TestMatchesCondition(t *testing.T) {
 			condition: "label:important",
 			want:      true,
 		},
+		{
+			name:      "query expression match",
+			condition: `from ~ "@company.com" AND NOT has(attachment)`,
+			want:      true,
+		},
+		{
+			name:      "query expression no match",
+			condition: `subject ~ urgent AND label = spam`,
+			want:      false,
+		},
+		{
+			name:      "invalid condition",
+			condition: `from ~`,
+			want:      false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -441,3 +472,33 @@ func containsAll(slice []string, items []string) bool {
 
 	return true
 }
+
+func TestProcessEmailAttachmentsMaxSize(t *testing.T) {
+	msg := &gmail.Message{
+		Id: "msg1",
+		Payload: &gmail.MessagePart{
+			Parts: []*gmail.MessagePart{
+				{Filename: "small.pdf", Body: &gmail.MessagePartBody{AttachmentId: "a1", Size: 100 << 10}},
+				{Filename: "large.pdf", Body: &gmail.MessagePartBody{AttachmentId: "a2", Size: 20 << 20}},
+				{Filename: "photo.jpg", Body: &gmail.MessagePartBody{AttachmentId: "a3", Size: 1 << 10}},
+			},
+		},
+	}
+
+	processor := NewContentProcessor(models.GmailSourceConfig{
+		DownloadAttachments: true,
+		AttachmentTypes:     []string{"pdf"},
+		MaxAttachmentSize:   "5MB",
+	})
+
+	attachments := processor.ProcessEmailAttachments(msg)
+	if len(attachments) != 1 || attachments[0].Name != "small.pdf" {
+		t.Errorf("ProcessEmailAttachments() = %+v, want only small.pdf", attachments)
+	}
+
+	processor = NewContentProcessor(models.GmailSourceConfig{DownloadAttachments: true, MaxAttachmentSize: "5MB"})
+
+	if attachments := processor.ProcessEmailAttachments(msg); len(attachments) != 2 {
+		t.Errorf("ProcessEmailAttachments() without types = %d attachments, want 2", len(attachments))
+	}
+}
@@ -0,0 +1,212 @@
+package gmail
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"pkm-sync/pkg/models"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+// ArchiveTag archives an email when added to its note and moves it back to the inbox when removed.
+const ArchiveTag = "archive"
+
+// systemLabelTags are the tags system labels are synced as.
+var systemLabelTags = map[string]string{
+	"IMPORTANT": "important",
+	"STARRED":   "starred",
+	"UNREAD":    "unread",
+	"INBOX":     "inbox",
+	"SENT":      "sent",
+	"DRAFT":     "draft",
+}
+
+// writableSystemLabels can be changed through their tags; Gmail sets SENT and DRAFT itself.
+var writableSystemLabels = []string{"IMPORTANT", "STARRED", "UNREAD", "INBOX"}
+
+// LabelChanges translates tags added to and removed from an email note into the labels, by ID or name, to
+// add and remove. Tags of the writable system labels and of tag_labels map to their label and the archive
+// tag to leaving the inbox; other tags are ignored. A label both added and removed is left alone.
+func LabelChanges(added, removed []string, tagLabels map[string]string) (add, remove []string) {
+	for _, tag := range added {
+		if strings.EqualFold(tag, ArchiveTag) {
+			remove = append(remove, "INBOX")
+		} else if label := labelForTag(tag, tagLabels); label != "" {
+			add = append(add, label)
+		}
+	}
+
+	for _, tag := range removed {
+		if strings.EqualFold(tag, ArchiveTag) {
+			add = append(add, "INBOX")
+		} else if label := labelForTag(tag, tagLabels); label != "" {
+			remove = append(remove, label)
+		}
+	}
+
+	return withoutLabels(add, remove), withoutLabels(remove, add)
+}
+
+// labelForTag returns the label a tag writes back to, or "" when it has none.
+func labelForTag(tag string, tagLabels map[string]string) string {
+	for _, labelID := range writableSystemLabels {
+		if strings.EqualFold(tag, systemLabelTags[labelID]) {
+			return labelID
+		}
+	}
+
+	for mappedTag, label := range tagLabels {
+		if strings.EqualFold(tag, mappedTag) {
+			return label
+		}
+	}
+
+	return ""
+}
+
+// withoutLabels returns the distinct labels that are not in exclude.
+func withoutLabels(labels, exclude []string) []string {
+	var result []string
+
+	for _, label := range labels {
+		if !containsFold(exclude, label) && !containsFold(result, label) {
+			result = append(result, label)
+		}
+	}
+
+	return result
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// labelTags maps the label IDs of tag_labels to their tags. Label names are resolved once per service; an
+// unknown label is skipped.
+func (s *Service) labelTags() map[string]string {
+	if len(s.config.TagLabels) == 0 {
+		return nil
+	}
+
+	s.labelTagsOnce.Do(func() {
+		labels, err := s.GetLabels()
+		if err != nil {
+			slog.Warn("Unable to resolve tag_labels, syncing label IDs as tags", "source", s.sourceID, "error", err)
+
+			return
+		}
+
+		s.labelTagMap = make(map[string]string, len(s.config.TagLabels))
+
+		for tag, name := range s.config.TagLabels {
+			if label := findLabel(labels, name); label != nil {
+				s.labelTagMap[label.Id] = tag
+			}
+		}
+	})
+
+	return s.labelTagMap
+}
+
+// labelTagsOrNil is labelTags for a service that may be nil.
+func (s *Service) labelTagsOrNil() map[string]string {
+	if s == nil {
+		return nil
+	}
+
+	return s.labelTags()
+}
+
+func findLabel(labels []*gmail.Label, idOrName string) *gmail.Label {
+	for _, label := range labels {
+		if label.Id == idOrName || strings.EqualFold(label.Name, idOrName) {
+			return label
+		}
+	}
+
+	return nil
+}
+
+// ApplyTagChanges writes the tags added to and removed from an email or thread note back to Gmail labels,
+// then updates the item's tags and labels to match. Threads are changed on every message.
+func (s *Service) ApplyTagChanges(item models.FullItem, added, removed []string) error {
+	add, remove := LabelChanges(added, removed, s.config.TagLabels)
+	if len(add) == 0 && len(remove) == 0 {
+		return nil
+	}
+
+	labels, err := s.GetLabels()
+	if err != nil {
+		return err
+	}
+
+	addIDs := make([]string, 0, len(add))
+
+	for _, name := range add {
+		label := findLabel(labels, name)
+		if label == nil {
+			return fmt.Errorf("label '%s' not found in Gmail", name)
+		}
+
+		addIDs = append(addIDs, label.Id)
+	}
+
+	removeIDs := make([]string, 0, len(remove))
+
+	for _, name := range remove {
+		if label := findLabel(labels, name); label != nil {
+			removeIDs = append(removeIDs, label.Id)
+		}
+	}
+
+	metadata := models.Metadata(item.GetMetadata())
+	threadID, _ := metadata.GetString(models.MetadataThreadID)
+
+	_, err = s.executeWithRetry(func() (interface{}, error) {
+		if item.GetItemType() != "email" && threadID != "" {
+			return s.service.Users.Threads.Modify("me", threadID,
+				&gmail.ModifyThreadRequest{AddLabelIds: addIDs, RemoveLabelIds: removeIDs}).Do()
+		}
+
+		return s.service.Users.Messages.Modify("me", item.GetID(),
+			&gmail.ModifyMessageRequest{AddLabelIds: addIDs, RemoveLabelIds: removeIDs}).Do()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update labels of '%s': %w", item.GetTitle(), err)
+	}
+
+	item.SetTags(editedTags(item.GetTags(), added, removed))
+
+	if labelIDs, ok := metadata.GetStrings(models.MetadataLabels); ok {
+		metadata[models.MetadataLabels] = append(withoutLabels(labelIDs, removeIDs), withoutLabels(addIDs, labelIDs)...)
+	}
+
+	return nil
+}
+
+// editedTags applies tag edits to an item's tags; archiving also drops the inbox tag and unarchiving adds it.
+func editedTags(tags, added, removed []string) []string {
+	if containsFold(added, ArchiveTag) {
+		removed = append(removed, systemLabelTags["INBOX"])
+	} else if containsFold(removed, ArchiveTag) {
+		added = append(added, systemLabelTags["INBOX"])
+	}
+
+	result := make([]string, 0, len(tags)+len(added))
+
+	for _, tag := range tags {
+		if !containsFold(removed, tag) {
+			result = append(result, tag)
+		}
+	}
+
+	return append(result, withoutLabels(added, result)...)
+}
@@ -0,0 +1,101 @@
+package gmail
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"pkm-sync/pkg/models"
+
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/option"
+)
+
+func TestLabelChanges(t *testing.T) {
+	tagLabels := map[string]string{"follow-up": "Follow-up"}
+
+	tests := []struct {
+		name       string
+		added      []string
+		removed    []string
+		wantAdd    []string
+		wantRemove []string
+	}{
+		{"archive", []string{"archive"}, nil, nil, []string{"INBOX"}},
+		{"unarchive", nil, []string{"archive"}, []string{"INBOX"}, nil},
+		{"system and mapped tags", []string{"Starred", "follow-up"}, []string{"unread"},
+			[]string{"STARRED", "Follow-up"}, []string{"UNREAD"}},
+		{"unmapped tags are ignored", []string{"personal", "sent"}, []string{"gmail"}, nil, nil},
+		{"archiving and removing the inbox tag agree", []string{"archive"}, []string{"inbox"}, nil, []string{"INBOX"}},
+		{"conflicting edits cancel out", []string{"archive", "inbox"}, nil, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			add, remove := LabelChanges(tt.added, tt.removed, tagLabels)
+			if !reflect.DeepEqual(add, tt.wantAdd) || !reflect.DeepEqual(remove, tt.wantRemove) {
+				t.Errorf("LabelChanges() = %v, %v, want %v, %v", add, remove, tt.wantAdd, tt.wantRemove)
+			}
+		})
+	}
+}
+
+func TestApplyTagChanges(t *testing.T) {
+	var modified gmail.ModifyMessageRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gmail/v1/users/me/labels":
+			fmt.Fprint(w, `{"labels": [{"id": "INBOX", "name": "INBOX"}, {"id": "Label_7", "name": "Follow-up"}]}`)
+		case "/gmail/v1/users/me/messages/m1/modify":
+			if err := json.NewDecoder(r.Body).Decode(&modified); err != nil {
+				t.Errorf("invalid modify body: %v", err)
+			}
+
+			fmt.Fprint(w, `{"id": "m1"}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	api, err := gmail.NewService(context.Background(), option.WithHTTPClient(server.Client()), option.WithEndpoint(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service := &Service{
+		service: api,
+		config:  models.GmailSourceConfig{TagLabels: map[string]string{"follow-up": "Follow-up"}},
+	}
+
+	item := models.NewBasicItem("m1", "Quarterly report")
+	item.SetItemType("email")
+	item.SetTags([]string{"gmail", "inbox"})
+	item.SetMetadata(map[string]interface{}{"labels": []string{"INBOX"}, "thread_id": "t1"})
+
+	if err := service.ApplyTagChanges(item, []string{"archive", "follow-up"}, nil); err != nil {
+		t.Fatalf("ApplyTagChanges() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(modified.AddLabelIds, []string{"Label_7"}) || !reflect.DeepEqual(modified.RemoveLabelIds, []string{"INBOX"}) {
+		t.Errorf("modify request = %+v", modified)
+	}
+
+	if want := []string{"gmail", "archive", "follow-up"}; !reflect.DeepEqual(item.GetTags(), want) {
+		t.Errorf("tags = %v, want %v", item.GetTags(), want)
+	}
+
+	if labels := item.GetMetadata()["labels"]; !reflect.DeepEqual(labels, []string{"Label_7"}) {
+		t.Errorf("labels = %v", labels)
+	}
+
+	if tags := service.labelTags(); tags["Label_7"] != "follow-up" {
+		t.Errorf("labelTags() = %v", tags)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"strings"
 
+	"pkm-sync/internal/utils"
 	"pkm-sync/pkg/models"
 
 	"google.golang.org/api/gmail/v1"
@@ -177,16 +178,28 @@ func (p *ContentProcessor) fetchAttachmentData(messageID string, attachment *mod
 
 // filterAttachments filters attachments based on configuration.
 func (p *ContentProcessor) filterAttachments(attachments []models.Attachment) []models.Attachment {
-	if len(p.config.AttachmentTypes) == 0 {
+	// Validation rejects invalid sizes, so an error here leaves attachments unlimited
+	maxSize, _ := utils.ParseSize(p.config.MaxAttachmentSize)
+
+	if len(p.config.AttachmentTypes) == 0 && maxSize == 0 {
 		return attachments // No filtering
 	}
 
 	var filtered []models.Attachment
 
 	for _, attachment := range attachments {
-		if p.isAllowedAttachmentType(attachment) {
-			filtered = append(filtered, attachment)
+		if len(p.config.AttachmentTypes) > 0 && !p.isAllowedAttachmentType(attachment) {
+			continue
 		}
+
+		if maxSize > 0 && attachment.Size > maxSize {
+			slog.Info("Skipping attachment larger than max_attachment_size", "attachment_name", attachment.Name,
+				"size", utils.FormatSize(attachment.Size), "max_attachment_size", utils.FormatSize(maxSize))
+
+			continue
+		}
+
+		filtered = append(filtered, attachment)
 	}
 
 	return filtered
@@ -3,10 +3,10 @@ package gmail
 import (
 	"fmt"
 	"log/slog"
-	"strconv"
 	"strings"
 	"time"
 
+	"pkm-sync/internal/utils"
 	"pkm-sync/pkg/models"
 )
 
@@ -19,7 +19,7 @@ func buildQuery(config models.GmailSourceConfig, since time.Time) string {
 
 	// Max email age filter - exclude emails older than this.
 	if config.MaxEmailAge != "" {
-		if duration, err := parseDuration(config.MaxEmailAge); err == nil {
+		if duration, err := utils.ParseDuration(config.MaxEmailAge); err == nil {
 			// MaxEmailAge means "emails not older than X days".
 			// So we want emails after (now - maxAge).
 			maxAgeStart := time.Now().Add(-duration)
@@ -32,7 +32,7 @@ func buildQuery(config models.GmailSourceConfig, since time.Time) string {
 
 	// Min email age filter - exclude very recent emails.
 	if config.MinEmailAge != "" {
-		if duration, err := parseDuration(config.MinEmailAge); err == nil {
+		if duration, err := utils.ParseDuration(config.MinEmailAge); err == nil {
 			// MinEmailAge means "emails older than X days".
 			// So we want emails before (now - minAge).
 			minAgeEnd := time.Now().Add(-duration)
@@ -202,54 +202,6 @@ func buildQueryWithRange(config models.GmailSourceConfig, start, end time.Time)
 	return strings.Join(parts, " ")
 }
 
-// parseDuration parses duration strings like "30d", "1y", "2w", "12h".
-func parseDuration(s string) (time.Duration, error) {
-	if s == "" {
-		return 0, fmt.Errorf("empty duration string")
-	}
-
-	// Extract number and unit.
-	var (
-		numStr, unit string
-		i            int
-	)
-
-	for i = 0; i < len(s); i++ {
-		if s[i] < '0' || s[i] > '9' {
-			break
-		}
-	}
-
-	if i == 0 {
-		return 0, fmt.Errorf("invalid duration format: %s", s)
-	}
-
-	numStr = s[:i]
-	unit = s[i:]
-
-	num, err := strconv.Atoi(numStr)
-	if err != nil {
-		return 0, fmt.Errorf("invalid number in duration: %s", numStr)
-	}
-
-	switch strings.ToLower(unit) {
-	case "m", "min", "minute", "minutes":
-		return time.Duration(num) * time.Minute, nil
-	case "h", "hr", "hour", "hours":
-		return time.Duration(num) * time.Hour, nil
-	case "d", "day", "days":
-		return time.Duration(num) * 24 * time.Hour, nil
-	case "w", "week", "weeks":
-		return time.Duration(num) * 7 * 24 * time.Hour, nil
-	case "mo", "month", "months":
-		return time.Duration(num) * 30 * 24 * time.Hour, nil
-	case "y", "year", "years":
-		return time.Duration(num) * 365 * 24 * time.Hour, nil
-	default:
-		return 0, fmt.Errorf("unsupported duration unit: %s", unit)
-	}
-}
-
 // ValidateQuery checks if a Gmail query is syntactically valid.
 func ValidateQuery(query string) error {
 	if query == "" {
@@ -53,19 +53,6 @@ func BenchmarkBuildComplexQuery(b *testing.B) {
 	}
 }
 
-// BenchmarkParseDuration tests the performance of duration parsing.
-func BenchmarkParseDuration(b *testing.B) {
-	durations := []string{"30d", "1y", "2w", "12h", "45m"}
-
-	b.ResetTimer()
-
-	for i := 0; i < b.N; i++ {
-		for _, d := range durations {
-			_, _ = parseDuration(d)
-		}
-	}
-}
-
 // BenchmarkQueryValidation tests the performance of query validation.
 func BenchmarkQueryValidation(b *testing.B) {
 	queries := []string{
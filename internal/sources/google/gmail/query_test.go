@@ -178,124 +178,6 @@ func TestBuildQueryWithRange(t *testing.T) {
 	}
 }
 
-func TestParseDuration(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		expected time.Duration
-		wantErr  bool
-	}{
-		{
-			name:     "minutes",
-			input:    "30m",
-			expected: 30 * time.Minute,
-			wantErr:  false,
-		},
-		{
-			name:     "hours",
-			input:    "2h",
-			expected: 2 * time.Hour,
-			wantErr:  false,
-		},
-		{
-			name:     "days",
-			input:    "7d",
-			expected: 7 * 24 * time.Hour,
-			wantErr:  false,
-		},
-		{
-			name:     "weeks",
-			input:    "2w",
-			expected: 2 * 7 * 24 * time.Hour,
-			wantErr:  false,
-		},
-		{
-			name:     "months",
-			input:    "1mo",
-			expected: 30 * 24 * time.Hour,
-			wantErr:  false,
-		},
-		{
-			name:     "years",
-			input:    "1y",
-			expected: 365 * 24 * time.Hour,
-			wantErr:  false,
-		},
-		{
-			name:     "long form minutes",
-			input:    "15minutes",
-			expected: 15 * time.Minute,
-			wantErr:  false,
-		},
-		{
-			name:     "long form hours",
-			input:    "3hours",
-			expected: 3 * time.Hour,
-			wantErr:  false,
-		},
-		{
-			name:     "long form days",
-			input:    "5days",
-			expected: 5 * 24 * time.Hour,
-			wantErr:  false,
-		},
-		{
-			name:     "empty string",
-			input:    "",
-			expected: 0,
-			wantErr:  true,
-		},
-		{
-			name:     "invalid format",
-			input:    "abc",
-			expected: 0,
-			wantErr:  true,
-		},
-		{
-			name:     "invalid number",
-			input:    "xyd",
-			expected: 0,
-			wantErr:  true,
-		},
-		{
-			name:     "invalid unit",
-			input:    "5z",
-			expected: 0,
-			wantErr:  true,
-		},
-		{
-			name:     "zero value",
-			input:    "0d",
-			expected: 0,
-			wantErr:  false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result, err := parseDuration(tt.input)
-
-			if tt.wantErr {
-				if err == nil {
-					t.Errorf("parseDuration() expected error, got nil")
-				}
-
-				return
-			}
-
-			if err != nil {
-				t.Errorf("parseDuration() unexpected error: %v", err)
-
-				return
-			}
-
-			if result != tt.expected {
-				t.Errorf("parseDuration() = %v, want %v", result, tt.expected)
-			}
-		})
-	}
-}
-
 func TestValidateQuery(t *testing.T) {
 	tests := []struct {
 		name    string
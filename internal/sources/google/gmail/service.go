@@ -10,6 +10,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"pkm-sync/pkg/interfaces"
 	"pkm-sync/pkg/models"
 
 	"google.golang.org/api/gmail/v1"
@@ -23,6 +24,28 @@ type Service struct {
 	service  *gmail.Service
 	config   models.GmailSourceConfig
 	sourceID string
+
+	labelTagsOnce sync.Once
+	labelTagMap   map[string]string // Label ID to its tag_labels tag
+
+	// Download progress across the listings of a fetch, see SetProgress
+	progress                    interfaces.ProgressFunc
+	progressDone, progressTotal int
+}
+
+// SetProgress reports the messages downloaded out of those listed so far to report.
+func (s *Service) SetProgress(report interfaces.ProgressFunc) {
+	s.progress = report
+	s.progressDone, s.progressTotal = 0, 0
+}
+
+// messageDone counts a listed message as downloaded, or skipped, for the progress report.
+func (s *Service) messageDone() {
+	s.progressDone++
+
+	if s.progress != nil {
+		s.progress(s.progressDone, s.progressTotal)
+	}
 }
 
 // NewService creates a new Gmail service wrapper.
@@ -179,6 +202,53 @@ func (s *Service) GetMessagesInRange(start, end time.Time, limit int) ([]*gmail.
 	return messages, nil
 }
 
+// GetMessagesBefore retrieves the messages matching the configured filters from since up to, but excluding,
+// until, newest first, following result pages until limit messages are read. A limit of 0 or less reads all
+// of them.
+func (s *Service) GetMessagesBefore(since, until time.Time, limit int) ([]*gmail.Message, error) {
+	if !until.After(since) {
+		return []*gmail.Message{}, nil
+	}
+
+	// Gmail takes epoch seconds for exact bounds; before: excludes the given second.
+	query := fmt.Sprintf("%s before:%d", s.buildQuery(since), until.Unix())
+
+	batchSize := 500
+	if s.config.BatchSize > 0 && s.config.BatchSize < batchSize {
+		batchSize = s.config.BatchSize
+	}
+
+	var messages []*gmail.Message
+
+	pageToken := ""
+
+	for limit <= 0 || len(messages) < limit {
+		currentBatch := batchSize
+		if limit > 0 && limit-len(messages) < batchSize {
+			currentBatch = limit - len(messages)
+		}
+
+		batch, nextPageToken, skipped, err := s.getMessageBatch(query, currentBatch, pageToken, s.config.RequestDelay)
+		if err != nil {
+			return messages, fmt.Errorf("unable to list messages before %s: %w", until.Format(time.RFC3339), err)
+		}
+
+		if skipped > 0 {
+			slog.Info("Message range batch retrieved", "retrieved", len(batch), "skipped", skipped)
+		}
+
+		messages = append(messages, batch...)
+
+		if nextPageToken == "" {
+			break
+		}
+
+		pageToken = nextPageToken
+	}
+
+	return messages, nil
+}
+
 // buildQuery constructs a Gmail search query based on configuration and since time.
 func (s *Service) buildQuery(since time.Time) string {
 	return buildQuery(s.config, since)
@@ -369,7 +439,8 @@ func (s *Service) getMessagesWithBatchProcessing(since time.Time, limit int) ([]
 			currentBatch = remaining
 		}
 
-		messages, nextPageToken, skipped, err := s.getMessageBatch(since, currentBatch, pageToken, requestDelay)
+		messages, nextPageToken, skipped, err := s.getMessageBatch(s.buildQuery(since), currentBatch, pageToken,
+			requestDelay)
 		if err != nil {
 			return allMessages, fmt.Errorf("batch processing failed: %w", err)
 		}
@@ -401,15 +472,13 @@ func (s *Service) getMessagesWithBatchProcessing(since time.Time, limit int) ([]
 	return allMessages, nil
 }
 
-// getMessageBatch retrieves a single batch of messages with optimizations.
+// getMessageBatch retrieves a single batch of messages matching query with optimizations.
 func (s *Service) getMessageBatch(
-	since time.Time,
+	query string,
 	batchSize int,
 	pageToken string,
 	_ time.Duration,
 ) ([]*gmail.Message, string, int, error) {
-	query := s.buildQuery(since)
-
 	// List messages for this batch.
 	req := s.service.Users.Messages.List("me").Q(query).MaxResults(int64(batchSize))
 	if pageToken != "" {
@@ -466,7 +535,8 @@ func (s *Service) GetMessagesStream(since time.Time, batchSize int, callback fun
 	totalProcessed := 0
 
 	for {
-		messages, nextPageToken, skipped, err := s.getMessageBatch(since, batchSize, pageToken, s.config.RequestDelay)
+		messages, nextPageToken, skipped, err := s.getMessageBatch(s.buildQuery(since), batchSize, pageToken,
+			s.config.RequestDelay)
 		if err != nil {
 			return fmt.Errorf("streaming batch failed: %w", err)
 		}
@@ -523,6 +593,8 @@ func (s *Service) fetchMessagesConcurrently(messageList []*gmail.Message) ([]*gm
 	// Use atomic counter to avoid data race.
 	var skippedCount int32
 
+	s.progressTotal += len(messageList)
+
 	// Start workers.
 	var wg sync.WaitGroup
 	for i := 0; i < maxWorkers; i++ {
@@ -577,10 +649,13 @@ func (s *Service) fetchMessagesConcurrently(messageList []*gmail.Message) ([]*gm
 				resultChan = nil
 			} else {
 				messages = append(messages, msg)
+				s.messageDone()
 			}
 		case _, ok := <-errorChan:
 			if !ok {
 				errorChan = nil
+			} else {
+				s.messageDone()
 			}
 		}
 
@@ -2,6 +2,8 @@ package gmail
 
 import (
 	"fmt"
+	"math"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -18,26 +20,43 @@ const (
 // ThreadGroup represents a group of emails that belong to the same thread.
 type ThreadGroup struct {
 	ThreadID     string         `json:"thread_id"`
-	Subject      string         `json:"subject"`
+	Subject      string         `json:"subject"` // Subject of the newest message
 	Messages     []*models.Item `json:"messages"`
 	Participants []string       `json:"participants"`
 	StartTime    time.Time      `json:"start_time"`
 	EndTime      time.Time      `json:"end_time"`
 	MessageCount int            `json:"message_count"`
+
+	// AlternateSubjects are the earlier subjects of a thread whose subject changed mid-conversation, oldest first
+	AlternateSubjects []string `json:"alternate_subjects,omitempty"`
+
+	// Participation: messages sent per participant, the sender of the newest message and the average time
+	// a participant took to answer someone else
+	ParticipantMessages map[string]int `json:"participant_messages,omitempty"`
+	LastSender          string         `json:"last_sender,omitempty"`
+	AverageResponseTime time.Duration  `json:"average_response_time,omitempty"`
 }
 
 // ThreadProcessor handles thread grouping and consolidation.
 type ThreadProcessor struct {
-	config models.GmailSourceConfig
+	config    models.GmailSourceConfig
+	filenames utils.FilenamePolicy // Naming of thread subjects in thread titles
 }
 
 // NewThreadProcessor creates a new thread processor with the given configuration.
 func NewThreadProcessor(config models.GmailSourceConfig) *ThreadProcessor {
 	return &ThreadProcessor{
-		config: config,
+		config:    config,
+		filenames: utils.DefaultFilenamePolicy(),
 	}
 }
 
+// SetFilenamePolicy sets how thread subjects are sanitized for thread titles, e.g. from a source's filenames
+// settings.
+func (tp *ThreadProcessor) SetFilenamePolicy(policy utils.FilenamePolicy) {
+	tp.filenames = policy
+}
+
 // ProcessThreads groups messages by thread and applies the configured thread mode.
 func (tp *ThreadProcessor) ProcessThreads(items []*models.Item) ([]*models.Item, error) {
 	// Ensure we always return a non-nil slice.
@@ -118,11 +137,73 @@ func (tp *ThreadProcessor) groupMessagesByThread(items []*models.Item) map[strin
 		})
 		// Update message count to be thread-safe.
 		group.MessageCount = len(group.Messages)
+
+		// Title the thread by its newest subject, whatever the subject was when it started.
+		group.Subject, group.AlternateSubjects = tp.threadSubjects(group.Messages)
+
+		tp.updateParticipation(group)
 	}
 
 	return threadGroups
 }
 
+// threadSubjects returns the subject of the newest message with one and the distinct earlier subjects.
+func (tp *ThreadProcessor) threadSubjects(messages []*models.Item) (string, []string) {
+	var subjects []string
+
+	for _, message := range messages {
+		subject := tp.extractThreadSubject(message)
+		if subject == "" {
+			continue
+		}
+
+		subjects = slices.DeleteFunc(subjects, func(seen string) bool {
+			return strings.EqualFold(seen, subject)
+		})
+		subjects = append(subjects, subject)
+	}
+
+	if len(subjects) == 0 {
+		return "", nil
+	}
+
+	return subjects[len(subjects)-1], subjects[:len(subjects)-1]
+}
+
+// updateParticipation computes a group's participation from its messages, sorted by creation time. A
+// response is a message following one from a different sender.
+func (tp *ThreadProcessor) updateParticipation(group *ThreadGroup) {
+	group.ParticipantMessages = make(map[string]int)
+
+	var (
+		previousSender string
+		previousTime   time.Time
+		responseTotal  time.Duration
+		responses      int
+	)
+
+	for _, message := range group.Messages {
+		sender := tp.extractSender(message)
+		if sender == "" {
+			continue
+		}
+
+		group.ParticipantMessages[sender]++
+		group.LastSender = sender
+
+		if previousSender != "" && sender != previousSender {
+			responseTotal += message.CreatedAt.Sub(previousTime)
+			responses++
+		}
+
+		previousSender, previousTime = sender, message.CreatedAt
+	}
+
+	if responses > 0 {
+		group.AverageResponseTime = responseTotal / time.Duration(responses)
+	}
+}
+
 // consolidateThreads creates one item per thread containing all messages (Option 2A).
 func (tp *ThreadProcessor) consolidateThreads(threadGroups map[string]*ThreadGroup) []*models.Item {
 	consolidatedItems := make([]*models.Item, 0, len(threadGroups))
@@ -137,7 +218,7 @@ func (tp *ThreadProcessor) consolidateThreads(threadGroups map[string]*ThreadGro
 
 		// Create consolidated thread item.
 		title := fmt.Sprintf("Thread_%s_%d-messages",
-			utils.SanitizeThreadSubject(group.Subject, group.ThreadID),
+			tp.filenames.ThreadSubject(group.Subject, group.ThreadID),
 			group.MessageCount)
 		consolidated := &models.Item{
 			ID:         fmt.Sprintf("thread_%s", group.ThreadID),
@@ -176,7 +257,7 @@ func (tp *ThreadProcessor) summarizeThreads(threadGroups map[string]*ThreadGroup
 		}
 
 		title := fmt.Sprintf("Thread-Summary_%s_%d-messages",
-			utils.SanitizeThreadSubject(group.Subject, group.ThreadID),
+			tp.filenames.ThreadSubject(group.Subject, group.ThreadID),
 			group.MessageCount)
 		summary := &models.Item{
 			ID:         fmt.Sprintf("thread_summary_%s", group.ThreadID),
@@ -361,11 +442,9 @@ func (tp *ThreadProcessor) selectAdditionalMessages(messages []*models.Item, max
 // Helper functions.
 
 func (tp *ThreadProcessor) extractThreadID(item *models.Item) string {
-	if threadID, exists := item.Metadata["thread_id"].(string); exists {
-		return threadID
-	}
+	threadID, _ := models.Metadata(item.Metadata).GetString(models.MetadataThreadID)
 
-	return ""
+	return threadID
 }
 
 func (tp *ThreadProcessor) extractThreadSubject(item *models.Item) string {
@@ -401,22 +480,15 @@ func (tp *ThreadProcessor) extractParticipants(item *models.Item) []string {
 	var participants []string
 
 	// Extract from metadata if available.
-	if from, exists := item.Metadata["from"]; exists {
-		if sender := tp.extractEmailFromRecipient(from); sender != "" {
-			participants = append(participants, sender)
-		}
+	if sender := tp.extractEmailFromRecipient(item.Metadata[models.MetadataFrom]); sender != "" {
+		participants = append(participants, sender)
 	}
 
 	return participants
 }
 
 func (tp *ThreadProcessor) updateParticipants(group *ThreadGroup, item *models.Item) {
-	from, exists := item.Metadata["from"]
-	if !exists {
-		return
-	}
-
-	sender := tp.extractEmailFromRecipient(from)
+	sender := tp.extractEmailFromRecipient(item.Metadata[models.MetadataFrom])
 	if sender == "" {
 		return
 	}
@@ -431,46 +503,13 @@ func (tp *ThreadProcessor) updateParticipants(group *ThreadGroup, item *models.I
 }
 
 func (tp *ThreadProcessor) extractSender(item *models.Item) string {
-	if from, exists := item.Metadata["from"]; exists {
-		return tp.extractEmailFromRecipient(from)
-	}
-
-	return ""
+	return tp.extractEmailFromRecipient(item.Metadata[models.MetadataFrom])
 }
 
 func (tp *ThreadProcessor) extractEmailFromRecipient(recipient interface{}) string {
-	if recipient == nil {
-		return ""
-	}
-
-	switch r := recipient.(type) {
-	case string:
-		// Handle "Name <email@example.com>" format
-		if strings.Contains(r, "<") && strings.Contains(r, ">") {
-			start := strings.LastIndex(r, "<")
-
-			end := strings.LastIndex(r, ">")
-			if start != -1 && end != -1 && end > start {
-				return r[start+1 : end]
-			}
-		}
-
-		return r
-	case map[string]interface{}:
-		if r == nil {
-			return ""
-		}
-
-		if email, ok := r["email"].(string); ok && email != "" {
-			return email
-		}
-		// Fallback for other possible structures, though less common
-		if name, ok := r["name"].(string); ok && name != "" {
-			return name // Or handle as an identifier if email is missing
-		}
-	}
+	r, _ := models.AsRecipient(recipient)
 
-	return ""
+	return r.Identity()
 }
 
 func (tp *ThreadProcessor) buildThreadMetadata(group *ThreadGroup) map[string]interface{} {
@@ -486,6 +525,14 @@ func (tp *ThreadProcessor) buildThreadMetadata(group *ThreadGroup) map[string]in
 	metadata["start_time"] = group.StartTime
 	metadata["end_time"] = group.EndTime
 
+	if len(group.AlternateSubjects) > 0 {
+		metadata[models.MetadataAlternateSubjects] = group.AlternateSubjects
+	}
+
+	if ids := tp.messageIDs(group.Messages); len(ids) > 0 {
+		metadata[models.MetadataMessageIDs] = ids
+	}
+
 	// Safe duration calculation.
 	if !group.StartTime.IsZero() && !group.EndTime.IsZero() {
 		metadata["duration_hours"] = group.EndTime.Sub(group.StartTime).Hours()
@@ -493,9 +540,32 @@ func (tp *ThreadProcessor) buildThreadMetadata(group *ThreadGroup) map[string]in
 		metadata["duration_hours"] = 0.0
 	}
 
+	if len(group.ParticipantMessages) > 0 {
+		metadata[models.MetadataParticipantMessages] = group.ParticipantMessages
+		metadata[models.MetadataLastSender] = group.LastSender
+	}
+
+	if group.AverageResponseTime > 0 {
+		metadata[models.MetadataAverageResponseHours] = math.Round(group.AverageResponseTime.Hours()*100) / 100
+	}
+
 	return metadata
 }
 
+// messageIDs returns the Message-IDs of a thread's messages, which link the thread to its copies in other
+// accounts.
+func (tp *ThreadProcessor) messageIDs(messages []*models.Item) []string {
+	var ids []string
+
+	for _, message := range messages {
+		if id, _ := models.Metadata(message.Metadata).GetString(models.MetadataMessageID); id != "" {
+			ids = append(ids, strings.Trim(id, "<> "))
+		}
+	}
+
+	return ids
+}
+
 func (tp *ThreadProcessor) buildThreadTags(group *ThreadGroup) []string {
 	var tags []string
 
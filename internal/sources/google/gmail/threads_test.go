@@ -0,0 +1,114 @@
+package gmail
+
+import (
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestThreadParticipation(t *testing.T) {
+	start := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+
+	message := func(id, from string, offset time.Duration) *models.Item {
+		return &models.Item{
+			ID:        id,
+			Title:     "Budget",
+			CreatedAt: start.Add(offset),
+			Metadata: map[string]interface{}{
+				"thread_id":         "thread-1",
+				models.MetadataFrom: models.Recipient{Email: from},
+			},
+		}
+	}
+
+	tp := NewThreadProcessor(models.GmailSourceConfig{IncludeThreads: true, ThreadMode: "consolidated"})
+
+	// Out of order on purpose: participation follows creation time
+	groups := tp.groupMessagesByThread([]*models.Item{
+		message("3", "alice@example.com", 5*time.Hour),
+		message("1", "alice@example.com", 0),
+		message("2", "bob@example.com", 2*time.Hour),
+		message("4", "alice@example.com", 6*time.Hour),
+	})
+
+	group := groups["thread-1"]
+	if group == nil {
+		t.Fatal("thread-1 was not grouped")
+	}
+
+	if got := group.ParticipantMessages; got["alice@example.com"] != 3 || got["bob@example.com"] != 1 {
+		t.Errorf("ParticipantMessages = %v, want alice 3 and bob 1", got)
+	}
+
+	if group.LastSender != "alice@example.com" {
+		t.Errorf("LastSender = %q, want alice@example.com", group.LastSender)
+	}
+
+	// Bob answered after 2h and Alice after 3h; Alice following up on herself is no response
+	if group.AverageResponseTime != 150*time.Minute {
+		t.Errorf("AverageResponseTime = %v, want 2h30m", group.AverageResponseTime)
+	}
+
+	metadata := tp.buildThreadMetadata(group)
+
+	if metadata[models.MetadataLastSender] != "alice@example.com" {
+		t.Errorf("last_sender = %v, want alice@example.com", metadata[models.MetadataLastSender])
+	}
+
+	if metadata[models.MetadataAverageResponseHours] != 2.5 {
+		t.Errorf("average_response_hours = %v, want 2.5", metadata[models.MetadataAverageResponseHours])
+	}
+
+	solo := tp.groupMessagesByThread([]*models.Item{message("1", "alice@example.com", 0)})["thread-1"]
+	if _, ok := tp.buildThreadMetadata(solo)[models.MetadataAverageResponseHours]; ok {
+		t.Error("average_response_hours set for a thread without responses")
+	}
+}
+
+func TestThreadSubjectChange(t *testing.T) {
+	start := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+
+	message := func(id, subject string, offset time.Duration) *models.Item {
+		return &models.Item{
+			ID:        id,
+			Title:     subject,
+			CreatedAt: start.Add(offset),
+			Metadata:  map[string]interface{}{"thread_id": "thread-1"},
+		}
+	}
+
+	tp := NewThreadProcessor(models.GmailSourceConfig{IncludeThreads: true, ThreadMode: "consolidated"})
+
+	groups := tp.groupMessagesByThread([]*models.Item{
+		message("3", "Re: Budget (approved)", 2*time.Hour),
+		message("1", "Budget", 0),
+		message("2", "RE: budget", time.Hour),
+	})
+
+	if len(groups) != 1 {
+		t.Fatalf("got %d groups, want the thread kept together", len(groups))
+	}
+
+	group := groups["thread-1"]
+	if group.Subject != "Budget (approved)" {
+		t.Errorf("Subject = %q, want the newest subject", group.Subject)
+	}
+
+	if len(group.AlternateSubjects) != 1 || group.AlternateSubjects[0] != "budget" {
+		t.Errorf("AlternateSubjects = %v, want [budget]", group.AlternateSubjects)
+	}
+
+	items, err := tp.ProcessThreads(group.Messages)
+	if err != nil {
+		t.Fatalf("ProcessThreads() error = %v", err)
+	}
+
+	if items[0].Title != "Thread_Budget-approved_3-messages" {
+		t.Errorf("thread title = %q, want it named after the newest subject", items[0].Title)
+	}
+
+	if subjects, _ := items[0].Metadata[models.MetadataAlternateSubjects].([]string); len(subjects) != 1 {
+		t.Errorf("alternate_subjects = %v, want the earlier subject", items[0].Metadata[models.MetadataAlternateSubjects])
+	}
+}
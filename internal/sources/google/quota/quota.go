@@ -0,0 +1,236 @@
+// Package quota keeps a daily budget of Google API requests per account, so syncs stop before Google's own
+// limits are reached and resume the next day.
+package quota
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"pkm-sync/internal/statestore"
+)
+
+// DefaultAccount is the account of Google sources that do not name one.
+const DefaultAccount = "default"
+
+// ErrBudgetExhausted is returned for requests made after an account used its budget for the day.
+var ErrBudgetExhausted = errors.New("daily Google API quota budget used")
+
+// Google resets daily quotas at midnight Pacific time.
+var quotaLocation = loadQuotaLocation()
+
+func loadQuotaLocation() *time.Location {
+	if location, err := time.LoadLocation("America/Los_Angeles"); err == nil {
+		return location
+	}
+
+	return time.UTC
+}
+
+// usage is an account's consumption on one quota day.
+type usage struct {
+	Day       string `json:"day"`
+	Requests  int    `json:"requests"`
+	Throttled bool   `json:"throttled,omitempty"` // Google reported its daily limit as reached
+}
+
+// Ledger is the state store of request counts, one entry per account for the current quota day.
+type Ledger struct {
+	path string
+	now  func() time.Time
+
+	mu       sync.Mutex
+	accounts map[string]*usage
+}
+
+// Load reads the ledger at path; a missing file starts an empty one.
+func Load(path string) (*Ledger, error) {
+	ledger := &Ledger{path: path, now: time.Now, accounts: make(map[string]*usage)}
+
+	data, err := statestore.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ledger, nil
+		}
+
+		return nil, fmt.Errorf("failed to read quota state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &ledger.accounts); err != nil {
+		return nil, fmt.Errorf("failed to parse quota state: %w", err)
+	}
+
+	return ledger, nil
+}
+
+// Save writes the ledger.
+func (l *Ledger) Save() error {
+	l.mu.Lock()
+	data, err := json.MarshalIndent(l.accounts, "", "  ")
+	l.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("failed to encode quota state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create quota state directory: %w", err)
+	}
+
+	if err := statestore.WriteFile(l.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write quota state: %w", err)
+	}
+
+	return nil
+}
+
+// today returns an account's usage for the current quota day, starting a new day when it changed. The
+// caller holds the lock.
+func (l *Ledger) today(account string) *usage {
+	day := l.now().In(quotaLocation).Format("2006-01-02")
+
+	current, exists := l.accounts[account]
+	if !exists || current.Day != day {
+		current = &usage{Day: day}
+		l.accounts[account] = current
+	}
+
+	return current
+}
+
+// Budget returns the daily budget of an account; a limit of 0 or less allows any number of requests.
+func (l *Ledger) Budget(account string, limit int) *Budget {
+	if account == "" {
+		account = DefaultAccount
+	}
+
+	return &Budget{ledger: l, account: account, limit: limit}
+}
+
+// Budget meters the requests of one account against its daily limit. A nil budget allows everything.
+type Budget struct {
+	ledger  *Ledger
+	account string
+	limit   int
+}
+
+// Used returns the requests made today.
+func (b *Budget) Used() int {
+	if b == nil {
+		return 0
+	}
+
+	b.ledger.mu.Lock()
+	defer b.ledger.mu.Unlock()
+
+	return b.ledger.today(b.account).Requests
+}
+
+// Err returns an error wrapping ErrBudgetExhausted once the account cannot make more requests today.
+func (b *Budget) Err() error {
+	if b == nil {
+		return nil
+	}
+
+	b.ledger.mu.Lock()
+	defer b.ledger.mu.Unlock()
+
+	return b.errLocked(b.ledger.today(b.account))
+}
+
+func (b *Budget) errLocked(current *usage) error {
+	if current.Throttled {
+		return fmt.Errorf("%w: Google reported the daily limit of account '%s' as reached; syncing resumes tomorrow",
+			ErrBudgetExhausted, b.account)
+	}
+
+	if b.limit > 0 && current.Requests >= b.limit {
+		return fmt.Errorf("%w: account '%s' made %d of its %d requests today; syncing resumes tomorrow",
+			ErrBudgetExhausted, b.account, current.Requests, b.limit)
+	}
+
+	return nil
+}
+
+// take counts a request, or returns the exhausted error without counting it.
+func (b *Budget) take() error {
+	b.ledger.mu.Lock()
+	defer b.ledger.mu.Unlock()
+
+	current := b.ledger.today(b.account)
+	if err := b.errLocked(current); err != nil {
+		return err
+	}
+
+	current.Requests++
+
+	return nil
+}
+
+func (b *Budget) markThrottled() {
+	b.ledger.mu.Lock()
+	defer b.ledger.mu.Unlock()
+
+	b.ledger.today(b.account).Throttled = true
+}
+
+// Client returns a copy of client whose requests count against the budget.
+func (b *Budget) Client(client *http.Client) *http.Client {
+	if b == nil || client == nil {
+		return client
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	metered := *client
+	metered.Transport = &transport{budget: b, base: base}
+
+	return &metered
+}
+
+type transport struct {
+	budget *Budget
+	base   http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.budget.take(); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && dailyLimitReached(resp) {
+		t.budget.markThrottled()
+	}
+
+	return resp, err
+}
+
+// dailyLimitReached reports whether a response says a daily quota ran out. Per-minute rate limits (429)
+// are retried by the sources and do not end the day.
+func dailyLimitReached(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden || resp.Body == nil {
+		return false
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err != nil {
+		return false
+	}
+
+	return strings.Contains(string(body), "dailyLimitExceeded") || strings.Contains(string(body), "quotaExceeded")
+}
@@ -0,0 +1,106 @@
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBudgetStopsAndResumesNextDay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "google-quota.json")
+
+	ledger, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2025, 3, 3, 10, 0, 0, 0, quotaLocation)
+	ledger.now = func() time.Time { return now }
+
+	budget := ledger.Budget("", 2)
+	client := budget.Client(server.Client())
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("request %d: %v", i+1, err)
+		}
+
+		resp.Body.Close()
+	}
+
+	if _, err := client.Get(server.URL); !errors.Is(err, ErrBudgetExhausted) {
+		t.Fatalf("third request error = %v, want ErrBudgetExhausted", err)
+	}
+
+	if budget.Used() != 2 || !errors.Is(budget.Err(), ErrBudgetExhausted) {
+		t.Errorf("Used() = %d, Err() = %v", budget.Used(), budget.Err())
+	}
+
+	// Consumption survives a restart until the quota day ends
+	if err := ledger.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded.now = ledger.now
+
+	if err := reloaded.Budget(DefaultAccount, 2).Err(); !errors.Is(err, ErrBudgetExhausted) {
+		t.Errorf("reloaded budget Err() = %v, want exhausted", err)
+	}
+
+	now = now.Add(24 * time.Hour)
+
+	if err := reloaded.Budget(DefaultAccount, 2).Err(); err != nil {
+		t.Errorf("next day Err() = %v, want budget reset", err)
+	}
+
+	if used := reloaded.Budget("other", 0).Used(); used != 0 {
+		t.Errorf("other account used %d requests", used)
+	}
+}
+
+func TestBudgetStopsWhenGoogleReportsDailyLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"error": {"errors": [{"reason": "dailyLimitExceeded"}]}}`)
+	}))
+	defer server.Close()
+
+	ledger, err := Load(filepath.Join(t.TempDir(), "google-quota.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	budget := ledger.Budget("work", 0)
+	client := budget.Client(server.Client())
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp.Body.Close()
+
+	if !errors.Is(budget.Err(), ErrBudgetExhausted) {
+		t.Errorf("Err() = %v after Google's daily limit, want exhausted", budget.Err())
+	}
+
+	var nilBudget *Budget
+	if nilBudget.Client(server.Client()) != server.Client() || nilBudget.Err() != nil {
+		t.Error("nil budget should not meter requests")
+	}
+}
@@ -5,27 +5,40 @@ import (
 	"net/http"
 	"time"
 
+	"pkm-sync/internal/config"
 	"pkm-sync/internal/sources/google/auth"
 	"pkm-sync/internal/sources/google/calendar"
 	"pkm-sync/internal/sources/google/drive"
 	"pkm-sync/internal/sources/google/gmail"
+	"pkm-sync/internal/utils"
 	"pkm-sync/pkg/interfaces"
 	"pkm-sync/pkg/models"
+
+	gmailapi "google.golang.org/api/gmail/v1"
 )
 
 const (
 	SourceTypeGoogle   = "google"
 	SourceTypeGmail    = "gmail"
 	SourceTypeCalendar = "google_calendar"
+	SourceTypeDrive    = "google_drive"
+
+	// maxCalendarRangeResults is the most events the Calendar API returns for one request, used when a
+	// range is fetched without a limit.
+	maxCalendarRangeResults = 2500
 )
 
 type GoogleSource struct {
 	calendarService *calendar.Service
+	eventFilter     *calendar.EventFilter // Nil keeps every event
 	driveService    *drive.Service
+	driveState      *driveFolderState // Saved by SaveSyncState once the fetched files are exported
 	gmailService    *gmail.Service
+	labelWriter     *gmail.Service // Gmail with the modify scope, authorized on the first label write-back
 	httpClient      *http.Client
 	config          models.SourceConfig
 	sourceID        string
+	rawErrors       interfaces.RawErrorFunc // Decides about messages that fail to convert, see SetRawErrorHandler
 }
 
 func NewGoogleSource() *GoogleSource {
@@ -44,8 +57,8 @@ func (g *GoogleSource) Name() string {
 		return g.sourceID
 	}
 
-	if g.config.Type == SourceTypeGmail {
-		return SourceTypeGmail
+	if g.config.Type == SourceTypeGmail || g.config.Type == SourceTypeDrive {
+		return g.config.Type
 	}
 
 	return SourceTypeCalendar
@@ -68,6 +81,10 @@ func (g *GoogleSource) Configure(config map[string]interface{}, client *http.Cli
 		return g.initializeGmailService(client)
 	}
 
+	if g.config.Type == SourceTypeDrive {
+		return g.initializeDriveService(client)
+	}
+
 	// Default to calendar and drive services
 	return g.initializeCalendarAndDriveServices(client, config)
 }
@@ -84,10 +101,52 @@ func (g *GoogleSource) initializeGmailService(client *http.Client) error {
 	return nil
 }
 
+// initializeDriveService initializes the Drive service for Drive folder sources.
+func (g *GoogleSource) initializeDriveService(client *http.Client) error {
+	if g.config.Google.FolderID == "" {
+		return fmt.Errorf("folder_id is required for google_drive sources")
+	}
+
+	var err error
+
+	g.driveService, err = drive.NewService(client)
+	if err != nil {
+		return fmt.Errorf("failed to initialize Drive service: %w", err)
+	}
+
+	return ConfigureDriveService(g.driveService, g.config.Google)
+}
+
 // initializeCalendarAndDriveServices initializes calendar and drive services for non-Gmail sources.
 func (g *GoogleSource) initializeCalendarAndDriveServices(client *http.Client, config map[string]interface{}) error {
 	var err error
 
+	if err := calendar.ValidateRecurringMode(g.config.Google.RecurringEvents); err != nil {
+		return err
+	}
+
+	if err := calendar.ValidateEventTypes(g.config.Google.EventTypes); err != nil {
+		return err
+	}
+
+	if err := calendar.ValidateAnalyticsPeriods(g.config.Google.Analytics); err != nil {
+		return err
+	}
+
+	meetingProviders, err := utils.ParseMeetingProviders(g.config.MeetingProviders)
+	if err != nil {
+		return err
+	}
+
+	location, err := utils.LoadTimezone(g.config.Timezone)
+	if err != nil {
+		return err
+	}
+
+	if g.eventFilter, err = calendar.NewEventFilter(g.config.Google.EventFilters, location); err != nil {
+		return err
+	}
+
 	// Initialize calendar service
 	g.calendarService, err = calendar.NewService(client)
 	if err != nil {
@@ -96,6 +155,7 @@ func (g *GoogleSource) initializeCalendarAndDriveServices(client *http.Client, c
 
 	// Configure calendar service options
 	g.configureCalendarService(config)
+	g.calendarService.SetMeetingProviders(meetingProviders)
 
 	// Initialize drive service
 	g.driveService, err = drive.NewService(client)
@@ -103,11 +163,36 @@ func (g *GoogleSource) initializeCalendarAndDriveServices(client *http.Client, c
 		return fmt.Errorf("failed to initialize Drive service: %w", err)
 	}
 
-	return nil
+	return ConfigureDriveService(g.driveService, g.config.Google)
 }
 
 // configureCalendarService applies configuration settings to the calendar service.
 func (g *GoogleSource) configureCalendarService(config map[string]interface{}) {
+	// Instance settings first; explicit configuration keys below override them
+	g.calendarService.SetIncludeDeclined(g.config.Google.IncludeDeclined)
+	g.calendarService.SetEventTypes(g.config.Google.EventTypes)
+	g.calendarService.SetExtractAgenda(g.config.Google.ExtractAgenda)
+
+	if includeDeclined, ok := config["include_declined"].(bool); ok {
+		g.calendarService.SetIncludeDeclined(includeDeclined)
+	}
+
+	if extractAgenda, ok := config["extract_agenda"].(bool); ok {
+		g.calendarService.SetExtractAgenda(extractAgenda)
+	}
+
+	if eventTypes, ok := config["event_types"].([]interface{}); ok {
+		var stringEventTypes []string
+
+		for _, item := range eventTypes {
+			if eventType, ok := item.(string); ok {
+				stringEventTypes = append(stringEventTypes, eventType)
+			}
+		}
+
+		g.calendarService.SetEventTypes(stringEventTypes)
+	}
+
 	// Configure attendee allow list if provided
 	if allowListInterface, exists := config["attendee_allow_list"]; exists {
 		if allowList, ok := allowListInterface.([]interface{}); ok {
@@ -142,8 +227,12 @@ func (g *GoogleSource) Fetch(since time.Time, limit int) ([]models.ItemInterface
 		return g.fetchGmail(since, limit)
 	}
 
-	// Default: Handle Google Calendar sources
-	return g.fetchCalendar(since, limit)
+	if g.config.Type == SourceTypeDrive {
+		return g.fetchDriveFolder(since, limit)
+	}
+
+	// Default: Handle Google Calendar sources, including events scheduled in the coming month
+	return g.fetchCalendar(since, time.Now().AddDate(0, 1, 0), limit)
 }
 
 func (g *GoogleSource) fetchGmail(since time.Time, limit int) ([]models.ItemInterface, error) {
@@ -156,12 +245,59 @@ func (g *GoogleSource) fetchGmail(since time.Time, limit int) ([]models.ItemInte
 		return nil, fmt.Errorf("failed to fetch Gmail messages: %w", err)
 	}
 
+	return g.gmailItems(messages)
+}
+
+// FetchRange fetches the emails or events from since up to, but excluding, until. Drive folders are mirrored
+// rather than fetched by date, so they do not fetch bounded windows.
+func (g *GoogleSource) FetchRange(since, until time.Time, limit int) ([]models.ItemInterface, error) {
+	if g.config.Type == SourceTypeDrive {
+		return nil, fmt.Errorf("source type '%s' does not support fetching a date range", g.config.Type)
+	}
+
+	if g.config.Type != SourceTypeGmail {
+		if limit <= 0 {
+			limit = maxCalendarRangeResults
+		}
+
+		return g.fetchCalendar(since, until, limit)
+	}
+
+	if g.gmailService == nil {
+		return nil, fmt.Errorf("gmail service not initialized")
+	}
+
+	messages, err := g.gmailService.GetMessagesBefore(since, until, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Gmail messages: %w", err)
+	}
+
+	return g.gmailItems(messages)
+}
+
+// SetRawErrorHandler lets fetches carry on past Gmail messages that fail to convert when handle allows it;
+// handle gets the message as returned by the API.
+func (g *GoogleSource) SetRawErrorHandler(handle interfaces.RawErrorFunc) {
+	g.rawErrors = handle
+}
+
+// gmailItems converts fetched messages to items, grouped into threads if configured.
+func (g *GoogleSource) gmailItems(messages []*gmailapi.Message) ([]models.ItemInterface, error) {
 	items := make([]models.ItemInterface, 0, len(messages))
 
 	for _, message := range messages {
 		legacyItem, err := gmail.FromGmailMessageWithService(message, g.config.Gmail, g.gmailService)
 		if err != nil {
-			return nil, fmt.Errorf("failed to convert Gmail message to item: %w", err)
+			err = fmt.Errorf("failed to convert Gmail message %s to item: %w", message.Id, err)
+			if g.rawErrors == nil {
+				return nil, err
+			}
+
+			if err := g.rawErrors(message.Id, message, err); err != nil {
+				return nil, err
+			}
+
+			continue
 		}
 
 		// Convert legacy item to ItemInterface
@@ -170,7 +306,13 @@ func (g *GoogleSource) fetchGmail(since time.Time, limit int) ([]models.ItemInte
 	}
 
 	if g.config.Gmail.IncludeThreads {
+		filenames, err := utils.FilenamePolicyFromConfig(g.config.Filenames, utils.DefaultFilenamePolicy())
+		if err != nil {
+			return nil, err
+		}
+
 		threadProcessor := gmail.NewThreadProcessor(g.config.Gmail)
+		threadProcessor.SetFilenamePolicy(filenames)
 
 		// The thread processor still works with []*models.Item, so we need to convert
 		legacyItems := make([]*models.Item, len(items))
@@ -195,32 +337,176 @@ func (g *GoogleSource) fetchGmail(since time.Time, limit int) ([]models.ItemInte
 	return items, nil
 }
 
-func (g *GoogleSource) fetchCalendar(since time.Time, limit int) ([]models.ItemInterface, error) {
+// eventsInWindow keeps the events that overlap the window from since to until, like the Calendar API's
+// timeMin and timeMax.
+func eventsInWindow(events []*models.CalendarEvent, since, until time.Time) []*models.CalendarEvent {
+	kept := events[:0]
+
+	for _, event := range events {
+		end := event.End
+		if end.IsZero() {
+			end = event.Start
+		}
+
+		if end.After(since) && event.Start.Before(until) {
+			kept = append(kept, event)
+		}
+	}
+
+	return kept
+}
+
+func (g *GoogleSource) fetchCalendar(since, until time.Time, limit int) ([]models.ItemInterface, error) {
 	if g.calendarService == nil {
 		return nil, fmt.Errorf("calendar service not initialized")
 	}
 
-	events, err := g.calendarService.GetEventsInRange(since, time.Now().AddDate(0, 1, 0), int64(limit))
+	calendars, err := resolveCalendars(g.configuredCalendars(), g.calendarService.ListReadableCalendars)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch calendar events: %w", err)
+		return nil, fmt.Errorf("failed to resolve calendars: %w", err)
 	}
 
-	items := make([]models.ItemInterface, 0, len(events))
+	// Analytics summarize whole periods, so their meetings are fetched past the window too
+	fetchSince, fetchUntil := calendar.AnalyticsWindow(since, until, g.config.Google.Analytics)
 
-	for _, event := range events {
-		// Convert API event to model, then to legacy item, then to interface
-		calEvent := g.calendarService.ConvertToModelWithDrive(event)
+	var calEvents []*models.CalendarEvent
+
+	seen := make(map[string]bool)
+	byCalendar := make(map[string]models.CalendarConfig, len(calendars))
+
+	for _, cal := range calendars {
+		byCalendar[cal.ID] = cal
+
+		events, err := g.calendarService.GetCalendarEventsInRange(cal.ID, fetchSince, fetchUntil, int64(limit))
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch calendar events: %w", err)
+		}
+
+		for _, event := range events {
+			// A meeting shared between selected calendars is kept once, under the first calendar
+			if seen[event.Id] {
+				continue
+			}
+
+			seen[event.Id] = true
+			calEvent := g.calendarService.ConvertToModelWithDrive(event)
+
+			if !g.eventFilter.Include(calEvent) {
+				continue
+			}
+
+			calEvent.CalendarID = cal.ID
+			calEvents = append(calEvents, calEvent)
+		}
+	}
+
+	// Analytics count every occurrence, so they are computed before series are collapsed
+	analytics := calendar.AnalyticsItems(calEvents, g.config.Google.Analytics)
+	if !fetchSince.Equal(since) || !fetchUntil.Equal(until) {
+		calEvents = eventsInWindow(calEvents, since, until)
+	}
+
+	// Occurrences are already expanded by the API; collapse them when one note per series is wanted
+	if g.config.Google.RecurringEvents == calendar.RecurringSeries {
+		calEvents = calendar.GroupSeries(calEvents)
+	}
+
+	items := make([]models.ItemInterface, 0, len(calEvents)+len(analytics))
+
+	for _, calEvent := range calEvents {
+		// Convert model to legacy item, then to interface
 		legacyItem := models.FromCalendarEvent(calEvent)
 		item := models.AsItemInterface(legacyItem)
+		applyCalendarMapping(item, byCalendar[calEvent.CalendarID])
 		items = append(items, item)
 	}
 
+	for _, analyticsItem := range analytics {
+		items = append(items, models.AsItemInterface(analyticsItem))
+	}
+
 	return items, nil
 }
 
-func (g *GoogleSource) SupportsRealtime() bool {
-	return false // Future: implement webhooks
+// ConfigureDriveService applies the doc_formats, max_doc_size and doc_comments settings of a source to
+// a Drive service.
+func ConfigureDriveService(driveService *drive.Service, config models.GoogleSourceConfig) error {
+	if err := drive.ValidateDocFormats(config.DocFormats); err != nil {
+		return err
+	}
+
+	if err := drive.ValidateCommentStyle(config.DocComments); err != nil {
+		return err
+	}
+
+	maxDocSize, err := utils.ParseSize(config.MaxDocSize)
+	if err != nil {
+		return fmt.Errorf("max_doc_size: %w", err)
+	}
+
+	driveService.SetDocFormats(config.DocFormats)
+	driveService.SetMaxDocSize(maxDocSize)
+	driveService.SetDocComments(config.DocComments)
+
+	return nil
+}
+
+// SetProgress reports the messages downloaded during a Gmail fetch; other services don't report progress.
+func (g *GoogleSource) SetProgress(report interfaces.ProgressFunc) {
+	if g.gmailService != nil {
+		g.gmailService.SetProgress(report)
+	}
+}
+
+// Capabilities reports what the configured service supports: calendar events are fetched by their start
+// within the sync window, changed or not, and only Gmail labels can be written back. Realtime sync awaits
+// webhooks.
+func (g *GoogleSource) Capabilities() interfaces.SourceCapabilities {
+	return interfaces.SourceCapabilities{
+		Incremental: g.config.Type == SourceTypeGmail || g.config.Type == SourceTypeDrive,
+		Attachments: true,
+		WriteBack:   g.config.Type == SourceTypeGmail,
+	}
+}
+
+func (g *GoogleSource) WritesTags() bool {
+	return g.config.Type == SourceTypeGmail && g.config.Gmail.LabelWriteBack
 }
 
-// Ensure GoogleSource implements Source interface.
-var _ interfaces.Source = (*GoogleSource)(nil)
+// ApplyTagChanges writes tag edits on an email note back to Gmail labels. Label changes need a token with
+// the modify scope (google_gmail_modify_token.json), authorized the first time one is written.
+func (g *GoogleSource) ApplyTagChanges(item models.FullItem, added, removed []string) error {
+	if !g.WritesTags() {
+		return fmt.Errorf("label_write_back is not enabled for source '%s'", g.Name())
+	}
+
+	if add, remove := gmail.LabelChanges(added, removed, g.config.Gmail.TagLabels); len(add) == 0 && len(remove) == 0 {
+		return nil
+	}
+
+	if g.labelWriter == nil {
+		tokenPath, err := config.GetGoogleTokenPath("gmail_modify")
+		if err != nil {
+			return err
+		}
+
+		client, err := auth.GetClientWithScopes(tokenPath, gmailapi.GmailModifyScope)
+		if err != nil {
+			return fmt.Errorf("failed to authorize Gmail label changes: %w", err)
+		}
+
+		g.labelWriter, err = gmail.NewService(client, g.config.Gmail, g.sourceID)
+		if err != nil {
+			return fmt.Errorf("failed to initialize Gmail service: %w", err)
+		}
+	}
+
+	return g.labelWriter.ApplyTagChanges(item, added, removed)
+}
+
+// Ensure GoogleSource implements the Source and TagWriter interfaces.
+var (
+	_ interfaces.Source       = (*GoogleSource)(nil)
+	_ interfaces.TagWriter    = (*GoogleSource)(nil)
+	_ interfaces.RangeFetcher = (*GoogleSource)(nil)
+)
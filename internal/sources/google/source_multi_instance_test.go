@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"pkm-sync/pkg/interfaces"
 	"pkm-sync/pkg/models"
 
 	"github.com/stretchr/testify/assert"
@@ -152,9 +153,16 @@ func TestGoogleSourceName(t *testing.T) {
 	}
 }
 
-func TestGoogleSourceSupportsRealtime(t *testing.T) {
+func TestGoogleSourceCapabilities(t *testing.T) {
 	source := NewGoogleSourceWithConfig("test", models.SourceConfig{})
-	assert.False(t, source.SupportsRealtime())
+	assert.False(t, source.Capabilities().Realtime)
+
+	gmailCaps := NewGoogleSourceWithConfig("gmail", models.SourceConfig{Type: SourceTypeGmail}).Capabilities()
+	assert.Equal(t, interfaces.SourceCapabilities{Incremental: true, Attachments: true, WriteBack: true}, gmailCaps)
+
+	calendarCaps := NewGoogleSourceWithConfig("calendar", models.SourceConfig{Type: SourceTypeCalendar}).Capabilities()
+	assert.False(t, calendarCaps.Incremental, "calendar events are fetched by start within the window")
+	assert.False(t, calendarCaps.WriteBack)
 }
 
 func TestMultipleGmailInstances(t *testing.T) {
@@ -0,0 +1,85 @@
+package google
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"pkm-sync/internal/sources/google/calendar"
+	"pkm-sync/pkg/models"
+
+	gmailapi "google.golang.org/api/gmail/v1"
+)
+
+func TestGmailItemsLeavesUnconvertibleMessagesToHandler(t *testing.T) {
+	messages := []*gmailapi.Message{
+		{Id: "good", InternalDate: 1700000000000, Payload: &gmailapi.MessagePart{}},
+		{Id: "undated", Payload: &gmailapi.MessagePart{}},
+	}
+
+	source := NewGoogleSourceWithConfig("gmail_work", models.SourceConfig{Type: SourceTypeGmail})
+
+	if _, err := source.gmailItems(messages); err == nil {
+		t.Fatal("gmailItems() without a handler should fail on the undated message")
+	}
+
+	var handled []string
+
+	source.SetRawErrorHandler(func(id string, raw interface{}, err error) error {
+		if raw != messages[1] {
+			t.Errorf("handler got %v, want the raw message", raw)
+		}
+
+		handled = append(handled, id)
+
+		return nil
+	})
+
+	items, err := source.gmailItems(messages)
+	if err != nil {
+		t.Fatalf("gmailItems() error = %v", err)
+	}
+
+	if len(items) != 1 || items[0].GetID() != "good" {
+		t.Errorf("gmailItems() = %d items, want only the good message", len(items))
+	}
+
+	if fmt.Sprint(handled) != "[undated]" {
+		t.Errorf("handled %v, want [undated]", handled)
+	}
+
+	source.SetRawErrorHandler(func(id string, raw interface{}, err error) error { return err })
+
+	if _, err := source.gmailItems(messages); err == nil {
+		t.Error("gmailItems() should fail when the handler returns the error")
+	}
+}
+
+func TestCalendarAnalyticsCoverWholePeriods(t *testing.T) {
+	at := func(d, h int) time.Time { return time.Date(2025, 1, d, h, 0, 0, 0, time.UTC) }
+	since, until := at(15, 8), at(17, 8)
+
+	// Fetched over the widened window: the week of 13 January
+	fetched := []*models.CalendarEvent{
+		{ID: "monday", Start: at(13, 9), End: at(13, 10)},
+		{ID: "wednesday", Start: at(15, 9), End: at(15, 10)},
+		{ID: "friday", Start: at(17, 9), End: at(17, 10)},
+	}
+
+	fetchSince, fetchUntil := calendar.AnalyticsWindow(since, until, []string{calendar.AnalyticsWeek})
+	for _, event := range fetched {
+		if event.Start.Before(fetchSince) || !event.Start.Before(fetchUntil) {
+			t.Fatalf("event %s is outside the fetched window %v to %v", event.ID, fetchSince, fetchUntil)
+		}
+	}
+
+	analytics := calendar.AnalyticsItems(fetched, []string{calendar.AnalyticsWeek})
+	if len(analytics) != 1 || analytics[0].Metadata["meeting_count"] != 3 {
+		t.Fatalf("analytics = %+v, want one week with all 3 meetings", analytics)
+	}
+
+	notes := eventsInWindow(fetched, since, until)
+	if len(notes) != 1 || notes[0].ID != "wednesday" {
+		t.Errorf("eventsInWindow() kept %d events, want only the one inside the sync window", len(notes))
+	}
+}
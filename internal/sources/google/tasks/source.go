@@ -0,0 +1,296 @@
+// Package tasks implements a Google Tasks source. Open tasks are always synced and completed ones since the
+// sync window; with write_back, tasks checked or unchecked in the vault are completed or reopened.
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"pkm-sync/internal/config"
+	"pkm-sync/internal/sources/google/auth"
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+
+	"google.golang.org/api/option"
+	tasksapi "google.golang.org/api/tasks/v1"
+)
+
+const (
+	SourceTypeGoogleTasks = "google_tasks"
+
+	itemTypeTask = "task"
+
+	// Statuses written to the status metadata; the Obsidian Kanban board checks "done" cards
+	StatusOpen = "todo"
+	StatusDone = "done"
+
+	apiStatusCompleted   = "completed"
+	apiStatusNeedsAction = "needsAction"
+
+	metadataTaskList   = "task_list"
+	metadataTaskListID = "task_list_id"
+	metadataTaskID     = "task_id"
+
+	pageSize = 100
+)
+
+type TasksSource struct {
+	config   models.SourceConfig
+	sourceID string
+
+	service *tasksapi.Service
+}
+
+func NewTasksSourceWithConfig(sourceID string, config models.SourceConfig) *TasksSource {
+	return &TasksSource{
+		sourceID: sourceID,
+		config:   config,
+	}
+}
+
+func (s *TasksSource) Name() string {
+	if s.sourceID != "" {
+		return s.sourceID
+	}
+
+	return SourceTypeGoogleTasks
+}
+
+// Configure authorizes Google Tasks with a token of its own, read-only unless write_back is enabled. The
+// client argument is ignored since the shared Google token has no Tasks permission.
+func (s *TasksSource) Configure(_ map[string]interface{}, _ *http.Client) error {
+	scope, tokenName := tasksapi.TasksReadonlyScope, "tasks_readonly"
+	if s.config.GoogleTasks.WriteBack {
+		scope, tokenName = tasksapi.TasksScope, "tasks"
+	}
+
+	tokenPath, err := config.GetGoogleTokenPath(tokenName)
+	if err != nil {
+		return err
+	}
+
+	client, err := auth.GetClientWithScopes(tokenPath, scope)
+	if err != nil {
+		return fmt.Errorf("failed to authorize Google Tasks: %w", err)
+	}
+
+	return s.useClient(client)
+}
+
+func (s *TasksSource) useClient(client *http.Client, opts ...option.ClientOption) error {
+	service, err := tasksapi.NewService(context.Background(), append(opts, option.WithHTTPClient(client))...)
+	if err != nil {
+		return fmt.Errorf("failed to create Google Tasks service: %w", err)
+	}
+
+	s.service = service
+
+	return nil
+}
+
+// Fetch returns every open task and the tasks completed since the given time.
+func (s *TasksSource) Fetch(since time.Time, limit int) ([]models.FullItem, error) {
+	if s.service == nil {
+		return nil, fmt.Errorf("google_tasks source is not configured")
+	}
+
+	lists, err := s.taskLists()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []models.FullItem
+
+	for _, list := range lists {
+		call := s.service.Tasks.List(list.Id).ShowCompleted(true).ShowHidden(true).MaxResults(pageSize)
+
+		err := call.Pages(context.Background(), func(page *tasksapi.Tasks) error {
+			for _, task := range page.Items {
+				if task.Deleted || task.Title == "" {
+					continue
+				}
+
+				if task.Status == apiStatusCompleted && completedBefore(task, since) {
+					continue
+				}
+
+				items = append(items, s.toItem(list, task))
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tasks of '%s': %w", list.Title, err)
+		}
+	}
+
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+
+	return items, nil
+}
+
+// Capabilities reports that open tasks are fetched on every sync and that their status can be written back.
+func (s *TasksSource) Capabilities() interfaces.SourceCapabilities {
+	return interfaces.SourceCapabilities{WriteBack: true}
+}
+
+func (s *TasksSource) WritesTaskStatus() bool {
+	return s.config.GoogleTasks.WriteBack
+}
+
+// SetTaskCompleted completes or reopens a fetched task and updates the item's status to match.
+func (s *TasksSource) SetTaskCompleted(item models.FullItem, completed bool) error {
+	if !s.config.GoogleTasks.WriteBack {
+		return fmt.Errorf("write_back is not enabled for source '%s'", s.Name())
+	}
+
+	if s.service == nil {
+		return fmt.Errorf("google_tasks source is not configured")
+	}
+
+	metadata := models.Metadata(item.GetMetadata())
+	listID, _ := metadata.GetString(metadataTaskListID)
+	taskID, _ := metadata.GetString(metadataTaskID)
+
+	if listID == "" || taskID == "" {
+		return fmt.Errorf("item %s is not a Google task", item.GetID())
+	}
+
+	patch := &tasksapi.Task{Status: apiStatusNeedsAction, NullFields: []string{"Completed"}}
+	if completed {
+		patch = &tasksapi.Task{Status: apiStatusCompleted}
+	}
+
+	task, err := s.service.Tasks.Patch(listID, taskID, patch).Do()
+	if err != nil {
+		return fmt.Errorf("failed to update task '%s': %w", item.GetTitle(), err)
+	}
+
+	setStatus(metadata, task)
+
+	if updated, err := time.Parse(time.RFC3339, task.Updated); err == nil {
+		item.SetUpdatedAt(updated)
+	}
+
+	return nil
+}
+
+// taskLists resolves the configured task list names and IDs; empty means every list.
+func (s *TasksSource) taskLists() ([]*tasksapi.TaskList, error) {
+	var available []*tasksapi.TaskList
+
+	call := s.service.Tasklists.List().MaxResults(pageSize)
+
+	err := call.Pages(context.Background(), func(page *tasksapi.TaskLists) error {
+		available = append(available, page.Items...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task lists: %w", err)
+	}
+
+	configured := s.config.GoogleTasks.TaskLists
+	if len(configured) == 0 {
+		return available, nil
+	}
+
+	lists := make([]*tasksapi.TaskList, 0, len(configured))
+
+	for _, want := range configured {
+		found := false
+
+		for _, list := range available {
+			if list.Id == want || strings.EqualFold(list.Title, want) {
+				lists = append(lists, list)
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("task list '%s' not found", want)
+		}
+	}
+
+	return lists, nil
+}
+
+func (s *TasksSource) toItem(list *tasksapi.TaskList, task *tasksapi.Task) models.FullItem {
+	item := models.NewBasicItem(task.Id, task.Title)
+	item.SetSourceType(SourceTypeGoogleTasks)
+	item.SetItemType(itemTypeTask)
+	item.SetContent(strings.TrimSpace(task.Notes))
+
+	updated, _ := time.Parse(time.RFC3339, task.Updated)
+	item.SetCreatedAt(updated)
+	item.SetUpdatedAt(updated)
+
+	metadata := models.Metadata{
+		metadataTaskList:   list.Title,
+		metadataTaskListID: list.Id,
+		metadataTaskID:     task.Id,
+	}
+
+	if due, err := time.Parse(time.RFC3339, task.Due); err == nil {
+		// The API keeps only the date of a due time
+		metadata[models.MetadataDue] = due.UTC().Format("2006-01-02")
+	}
+
+	if task.Parent != "" {
+		metadata["parent_task_id"] = task.Parent
+	}
+
+	setStatus(metadata, task)
+	item.SetMetadata(metadata)
+
+	if task.WebViewLink != "" {
+		item.SetLinks([]models.Link{{URL: task.WebViewLink, Title: "Open in Google Tasks", Type: "external"}})
+	}
+
+	return item
+}
+
+func setStatus(metadata models.Metadata, task *tasksapi.Task) {
+	completed := task.Status == apiStatusCompleted
+
+	metadata[models.MetadataCompleted] = completed
+	metadata[models.MetadataStatus] = StatusOpen
+
+	delete(metadata, "completed_at")
+
+	if completed {
+		metadata[models.MetadataStatus] = StatusDone
+
+		if task.Completed != nil {
+			if at, err := time.Parse(time.RFC3339, *task.Completed); err == nil {
+				metadata["completed_at"] = at
+			}
+		}
+	}
+}
+
+// completedBefore reports whether a completed task was finished before since, falling back to its
+// last update when the completion time is missing.
+func completedBefore(task *tasksapi.Task, since time.Time) bool {
+	at := task.Updated
+	if task.Completed != nil {
+		at = *task.Completed
+	}
+
+	finished, err := time.Parse(time.RFC3339, at)
+
+	return err == nil && finished.Before(since)
+}
+
+// Ensure TasksSource implements the Source and TaskStatusWriter interfaces.
+var (
+	_ interfaces.Source           = (*TasksSource)(nil)
+	_ interfaces.TaskStatusWriter = (*TasksSource)(nil)
+)
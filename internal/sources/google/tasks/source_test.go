@@ -0,0 +1,120 @@
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+
+	"google.golang.org/api/option"
+)
+
+const taskItems = `{"items": [
+  {"id": "t1", "title": "Write spec", "status": "needsAction", "updated": "2025-01-10T09:00:00Z",
+   "due": "2025-03-05T00:00:00Z", "notes": "Outline first\n"},
+  {"id": "t2", "title": "Ship it", "status": "completed", "updated": "2025-03-02T09:00:00Z",
+   "completed": "2025-03-02T09:00:00Z"},
+  {"id": "t3", "title": "Old chore", "status": "completed", "updated": "2025-01-02T09:00:00Z",
+   "completed": "2025-01-02T09:00:00Z"},
+  {"id": "t4", "title": "Removed", "status": "needsAction", "deleted": true, "updated": "2025-03-02T09:00:00Z"}
+]}`
+
+func newTestSource(t *testing.T, config models.GoogleTasksSourceConfig, patched *map[string]interface{}) *TasksSource {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/tasks/v1/users/@me/lists":
+			fmt.Fprint(w, `{"items": [{"id": "l1", "title": "My Tasks"}, {"id": "l2", "title": "Shopping"}]}`)
+		case r.URL.Path == "/tasks/v1/lists/l1/tasks":
+			fmt.Fprint(w, taskItems)
+		case r.URL.Path == "/tasks/v1/lists/l2/tasks":
+			fmt.Fprint(w, `{"items": []}`)
+		case r.URL.Path == "/tasks/v1/lists/l1/tasks/t1" && r.Method == http.MethodPatch:
+			if err := json.NewDecoder(r.Body).Decode(patched); err != nil {
+				t.Errorf("invalid patch body: %v", err)
+			}
+
+			fmt.Fprint(w, `{"id": "t1", "status": "completed", "updated": "2025-03-03T10:00:00Z",
+				"completed": "2025-03-03T10:00:00Z"}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	source := NewTasksSourceWithConfig("tasks", models.SourceConfig{Type: SourceTypeGoogleTasks, GoogleTasks: config})
+	if err := source.useClient(server.Client(), option.WithEndpoint(server.URL)); err != nil {
+		t.Fatalf("useClient() error = %v", err)
+	}
+
+	return source
+}
+
+func TestFetch(t *testing.T) {
+	source := newTestSource(t, models.GoogleTasksSourceConfig{TaskLists: []string{"my tasks"}}, nil)
+
+	items, err := source.Fetch(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), 0)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if len(items) != 2 || items[0].GetID() != "t1" || items[1].GetID() != "t2" {
+		t.Fatalf("Fetch() = %d items, want the open task and the one completed in the window", len(items))
+	}
+
+	open := models.Metadata(items[0].GetMetadata())
+	if status, _ := open.GetString(models.MetadataStatus); status != StatusOpen {
+		t.Errorf("open task status = %q", status)
+	}
+
+	if due, _ := open.GetString(models.MetadataDue); due != "2025-03-05" {
+		t.Errorf("due = %q", due)
+	}
+
+	if items[0].GetContent() != "Outline first" || items[0].GetItemType() != itemTypeTask {
+		t.Errorf("unexpected task item: %q, %q", items[0].GetContent(), items[0].GetItemType())
+	}
+
+	if completed, _ := models.Metadata(items[1].GetMetadata()).GetBool(models.MetadataCompleted); !completed {
+		t.Error("completed task not marked completed")
+	}
+}
+
+func TestSetTaskCompleted(t *testing.T) {
+	readOnly := newTestSource(t, models.GoogleTasksSourceConfig{}, nil)
+
+	items, err := readOnly.Fetch(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), 1)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if err := readOnly.SetTaskCompleted(items[0], true); err == nil {
+		t.Error("expected an error without write_back")
+	}
+
+	var patched map[string]interface{}
+
+	source := newTestSource(t, models.GoogleTasksSourceConfig{WriteBack: true}, &patched)
+	if err := source.SetTaskCompleted(items[0], true); err != nil {
+		t.Fatalf("SetTaskCompleted() error = %v", err)
+	}
+
+	if patched["status"] != apiStatusCompleted {
+		t.Errorf("patch = %v", patched)
+	}
+
+	metadata := models.Metadata(items[0].GetMetadata())
+	if status, _ := metadata.GetString(models.MetadataStatus); status != StatusDone {
+		t.Errorf("status after completing = %q", status)
+	}
+
+	if !items[0].GetUpdatedAt().Equal(time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("updated_at = %v", items[0].GetUpdatedAt())
+	}
+}
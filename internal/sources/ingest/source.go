@@ -0,0 +1,400 @@
+// Package ingest implements a source that reads items in the canonical Item JSON schema from a file, stdin
+// or a small HTTP listener, so scripts can feed content into the sync pipeline.
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+const (
+	SourceTypeIngest = "ingest"
+
+	// stdinPath reads items from standard input
+	stdinPath = "-"
+
+	defaultItemType      = "note"
+	defaultListenTimeout = 5 * time.Minute
+
+	// maxRequestSize bounds a single listener request body
+	maxRequestSize = 32 << 20
+)
+
+type IngestSource struct {
+	config   models.SourceConfig
+	sourceID string
+
+	// stdin and listener replace standard input and the configured listen address in tests
+	stdin    io.Reader
+	listener net.Listener
+}
+
+func NewIngestSourceWithConfig(sourceID string, config models.SourceConfig) *IngestSource {
+	return &IngestSource{
+		sourceID: sourceID,
+		config:   config,
+		stdin:    os.Stdin,
+	}
+}
+
+func (s *IngestSource) Name() string {
+	if s.sourceID != "" {
+		return s.sourceID
+	}
+
+	return SourceTypeIngest
+}
+
+// Configure needs no client; items arrive already in the canonical schema.
+func (s *IngestSource) Configure(_ map[string]interface{}, _ *http.Client) error {
+	return nil
+}
+
+// Fetch reads items from the configured listener, file or stdin. Items with a timestamp older than since
+// are skipped; at most limit items are returned when limit is positive.
+func (s *IngestSource) Fetch(since time.Time, limit int) ([]models.FullItem, error) {
+	var (
+		items []models.FullItem
+		err   error
+	)
+
+	switch {
+	case s.config.Ingest.Listen != "" || s.listener != nil:
+		items, err = s.listen(limit)
+	case s.config.Ingest.Path == "" || s.config.Ingest.Path == stdinPath:
+		items, err = readItems(s.stdin)
+	default:
+		var file *os.File
+
+		file, err = os.Open(s.config.Ingest.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open ingest file: %w", err)
+		}
+		defer file.Close()
+
+		items, err = readItems(file)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var fetched []models.FullItem
+
+	for _, item := range items {
+		if !since.IsZero() && !item.GetUpdatedAt().IsZero() && item.GetUpdatedAt().Before(since) {
+			continue
+		}
+
+		fetched = append(fetched, item)
+
+		if limit > 0 && len(fetched) >= limit {
+			break
+		}
+	}
+
+	return fetched, nil
+}
+
+// Capabilities reports items as realtime when they are pushed to the HTTP listener.
+func (s *IngestSource) Capabilities() interfaces.SourceCapabilities {
+	return interfaces.SourceCapabilities{Incremental: true, Realtime: s.config.Ingest.Listen != "", Attachments: true}
+}
+
+// readItems decodes a JSON array of items, a single item, one item per line (JSON Lines), or an item
+// document such as --dry-run --format json output. Items are upgraded from older schema versions first.
+func readItems(r io.Reader) ([]models.FullItem, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read items: %w", err)
+	}
+
+	var raws []json.RawMessage
+
+	version := 0
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &raws); err != nil {
+			return nil, fmt.Errorf("failed to parse items: %w", err)
+		}
+	} else if document, ok := parseDocument(trimmed); ok {
+		raws = *document.Items
+		version = document.SchemaVersion
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+
+		for {
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to parse item %d: %w", len(raws)+1, err)
+			}
+
+			raws = append(raws, raw)
+		}
+	}
+
+	items := make([]models.FullItem, 0, len(raws))
+
+	for i, raw := range raws {
+		upgraded, err := models.UpgradeItemJSON(raw, version)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i+1, err)
+		}
+
+		item, err := decodeItem(upgraded)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i+1, err)
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+type itemDocument struct {
+	SchemaVersion int                `json:"schema_version"`
+	Items         *[]json.RawMessage `json:"items"`
+}
+
+// parseDocument reports whether data is a single object with an items array.
+func parseDocument(data []byte) (itemDocument, bool) {
+	var document itemDocument
+	if err := json.Unmarshal(data, &document); err != nil || document.Items == nil {
+		return itemDocument{}, false
+	}
+
+	return document, true
+}
+
+// decodeItem decodes one item; items with messages become threads.
+func decodeItem(raw json.RawMessage) (models.FullItem, error) {
+	var probe struct {
+		Messages []json.RawMessage `json:"messages"`
+	}
+
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, err
+	}
+
+	var item models.FullItem
+
+	if len(probe.Messages) > 0 {
+		thread := &models.Thread{}
+		if err := json.Unmarshal(raw, thread); err != nil {
+			return nil, err
+		}
+
+		item = thread
+	} else {
+		basic := &models.BasicItem{}
+		if err := json.Unmarshal(raw, basic); err != nil {
+			return nil, err
+		}
+
+		item = basic
+	}
+
+	if err := applyDefaults(item); err != nil {
+		return nil, err
+	}
+
+	if thread, ok := models.AsThread(item); ok {
+		for i, message := range thread.GetMessages() {
+			if message.GetID() == "" {
+				message.SetID(fmt.Sprintf("%s-%d", thread.GetID(), i+1))
+			}
+
+			if err := applyDefaults(message); err != nil {
+				return nil, fmt.Errorf("message %d: %w", i+1, err)
+			}
+		}
+	}
+
+	return item, nil
+}
+
+// applyDefaults fills the fields scripts may leave out. Items without an ID get one derived from their
+// title, content and creation time so re-ingesting the same item updates its note.
+func applyDefaults(item models.FullItem) error {
+	if item.GetTitle() == "" && item.GetContent() == "" {
+		return errors.New("title or content is required")
+	}
+
+	if item.GetID() == "" {
+		sum := sha256.Sum256([]byte(item.GetTitle() + "\x00" + item.GetContent() + "\x00" +
+			item.GetCreatedAt().UTC().Format(time.RFC3339)))
+		item.SetID(SourceTypeIngest + "-" + hex.EncodeToString(sum[:])[:16])
+	}
+
+	if item.GetTitle() == "" {
+		item.SetTitle(firstLine(item.GetContent()))
+	}
+
+	if item.GetSourceType() == "" {
+		item.SetSourceType(SourceTypeIngest)
+	}
+
+	if item.GetItemType() == "" {
+		item.SetItemType(defaultItemType)
+	}
+
+	if item.GetCreatedAt().IsZero() {
+		item.SetCreatedAt(time.Now())
+	}
+
+	if item.GetUpdatedAt().IsZero() {
+		item.SetUpdatedAt(item.GetCreatedAt())
+	}
+
+	if item.GetMetadata() == nil {
+		item.SetMetadata(make(map[string]interface{}))
+	}
+
+	// Decoded JSON has strings and maps where sources store times and recipients
+	models.Metadata(item.GetMetadata()).Normalize()
+
+	return nil
+}
+
+func firstLine(content string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(content), "\n")
+
+	return strings.TrimSpace(line)
+}
+
+// listen accepts items POSTed to the listener until listen_timeout passes, a request is POSTed to /done,
+// or limit items have arrived. Each request body holds items in any format readItems accepts.
+func (s *IngestSource) listen(limit int) ([]models.FullItem, error) {
+	listener := s.listener
+	if listener == nil {
+		var err error
+
+		listener, err = net.Listen("tcp", s.config.Ingest.Listen)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start ingest listener: %w", err)
+		}
+	}
+
+	timeout := s.config.Ingest.ListenTimeout
+	if timeout <= 0 {
+		timeout = defaultListenTimeout
+	}
+
+	var items []models.FullItem
+
+	received := make(chan []models.FullItem)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	server := &http.Server{
+		Handler:           s.handler(received, done, stopped),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+
+	go func() { serveErr <- server.Serve(listener) }()
+
+	slog.Info("Listening for ingest items", "address", listener.Addr().String(), "timeout", timeout)
+
+	deadline := time.After(timeout)
+
+collect:
+	for {
+		select {
+		case batch := <-received:
+			items = append(items, batch...)
+			if limit > 0 && len(items) >= limit {
+				break collect
+			}
+		case <-done:
+			break collect
+		case <-deadline:
+			break collect
+		case err := <-serveErr:
+			return nil, fmt.Errorf("ingest listener failed: %w", err)
+		}
+	}
+
+	// Requests still in flight are turned away with 503 so their senders know to retry
+	close(stopped)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		slog.Warn("Failed to stop ingest listener", "error", err)
+	}
+
+	return items, nil
+}
+
+// handler accepts POSTed items, sending each request's items to received; POST /done closes done. Once
+// stopped is closed no more items are accepted.
+func (s *IngestSource) handler(received chan<- []models.FullItem, done, stopped chan struct{}) http.Handler {
+	var finish sync.Once
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		if token := s.config.Ingest.Token; token != "" && r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+
+			return
+		}
+
+		if r.URL.Path == "/done" {
+			finish.Do(func() { close(done) })
+
+			w.WriteHeader(http.StatusNoContent)
+
+			return
+		}
+
+		items, err := readItems(http.MaxBytesReader(w, r.Body, maxRequestSize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		select {
+		case received <- items:
+		case <-stopped:
+			http.Error(w, "ingest listener is shutting down", http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(map[string]int{"accepted": len(items)})
+	})
+}
+
+// Ensure IngestSource implements Source interface.
+var _ interfaces.Source = (*IngestSource)(nil)
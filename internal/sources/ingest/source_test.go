@@ -0,0 +1,182 @@
+package ingest
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestFetchFromStdinFormats(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"array", `[{"id": "a", "title": "First"}, {"id": "b", "title": "Second"}]`},
+		{"json lines", "{\"id\": \"a\", \"title\": \"First\"}\n{\"id\": \"b\", \"title\": \"Second\"}\n"},
+		{"document", `{"schema_version": 1, "target": "obsidian", "items": [{"id": "a", "title": "First"},
+			{"id": "b", "title": "Second"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := NewIngestSourceWithConfig("script", models.SourceConfig{Type: SourceTypeIngest})
+			source.stdin = strings.NewReader(tt.input)
+
+			items, err := source.Fetch(time.Time{}, 0)
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+
+			if len(items) != 2 || items[0].GetID() != "a" || items[1].GetTitle() != "Second" {
+				t.Errorf("Fetch() = %v", items)
+			}
+		})
+	}
+}
+
+func TestFetchAppliesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.json")
+
+	input := `{"content": "Buy milk\nand eggs"}
+{"title": "Standup", "messages": [{"title": "Re: Standup", "content": "Running late"}]}`
+	if err := os.WriteFile(path, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source := NewIngestSourceWithConfig("script", models.SourceConfig{
+		Type:   SourceTypeIngest,
+		Ingest: models.IngestSourceConfig{Path: path},
+	})
+
+	items, err := source.Fetch(time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+
+	note := items[0]
+	if !strings.HasPrefix(note.GetID(), "ingest-") || note.GetTitle() != "Buy milk" ||
+		note.GetSourceType() != SourceTypeIngest || note.GetItemType() != "note" || note.GetCreatedAt().IsZero() {
+		t.Errorf("defaults not applied: id=%q title=%q source=%q type=%q", note.GetID(), note.GetTitle(),
+			note.GetSourceType(), note.GetItemType())
+	}
+
+	thread, ok := models.AsThread(items[1])
+	if !ok {
+		t.Fatalf("item with messages should be a thread, got %T", items[1])
+	}
+
+	if messages := thread.GetMessages(); len(messages) != 1 || messages[0].GetID() != thread.GetID()+"-1" {
+		t.Errorf("unexpected thread messages: %v", messages)
+	}
+}
+
+func TestFetchSinceAndLimit(t *testing.T) {
+	source := NewIngestSourceWithConfig("script", models.SourceConfig{Type: SourceTypeIngest})
+	source.stdin = strings.NewReader(`[
+		{"id": "old", "title": "Old", "updated_at": "2024-01-01T00:00:00Z"},
+		{"id": "new", "title": "New", "updated_at": "2025-06-01T00:00:00Z"},
+		{"id": "newer", "title": "Newer", "updated_at": "2025-07-01T00:00:00Z"}
+	]`)
+
+	items, err := source.Fetch(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), 1)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if len(items) != 1 || items[0].GetID() != "new" {
+		t.Errorf("Fetch() = %v, want only 'new'", items)
+	}
+}
+
+func TestFetchRejectsNewerSchemaVersion(t *testing.T) {
+	source := NewIngestSourceWithConfig("script", models.SourceConfig{Type: SourceTypeIngest})
+	source.stdin = strings.NewReader(`{"schema_version": 99, "items": [{"id": "a", "title": "From the future"}]}`)
+
+	if _, err := source.Fetch(time.Time{}, 0); err == nil || !strings.Contains(err.Error(), "schema_version 99") {
+		t.Errorf("Fetch() error = %v, want a schema version error", err)
+	}
+}
+
+func TestFetchRejectsEmptyItem(t *testing.T) {
+	source := NewIngestSourceWithConfig("script", models.SourceConfig{Type: SourceTypeIngest})
+	source.stdin = strings.NewReader(`[{"id": "a", "title": "Fine"}, {"tags": ["x"]}]`)
+
+	if _, err := source.Fetch(time.Time{}, 0); err == nil || !strings.Contains(err.Error(), "item 2") {
+		t.Errorf("Fetch() error = %v, want an error for item 2", err)
+	}
+}
+
+func TestFetchFromListener(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := NewIngestSourceWithConfig("webhook", models.SourceConfig{
+		Type:   SourceTypeIngest,
+		Ingest: models.IngestSourceConfig{Token: "secret", ListenTimeout: 10 * time.Second},
+	})
+	source.listener = listener
+
+	url := "http://" + listener.Addr().String()
+
+	post := func(path, body, token string) int {
+		req, err := http.NewRequest(http.MethodPost, url+path, strings.NewReader(body))
+		if err != nil {
+			t.Error(err)
+
+			return 0
+		}
+
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Error(err)
+
+			return 0
+		}
+		defer resp.Body.Close()
+
+		return resp.StatusCode
+	}
+
+	statuses := make(chan []int, 1)
+
+	go func() {
+		statuses <- []int{
+			post("/items", `{"id": "x", "title": "Unauthorized"}`, "wrong"),
+			post("/items", `{"id": "a", "title": "Pushed"}`, "secret"),
+			post("/items", `not json`, "secret"),
+			post("/done", "", "secret"),
+		}
+	}()
+
+	items, err := source.Fetch(time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	got := <-statuses
+	want := []int{http.StatusUnauthorized, http.StatusAccepted, http.StatusBadRequest, http.StatusNoContent}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("request %d status = %d, want %d", i+1, got[i], want[i])
+		}
+	}
+
+	if len(items) != 1 || items[0].GetID() != "a" {
+		t.Errorf("Fetch() = %v, want the pushed item", items)
+	}
+}
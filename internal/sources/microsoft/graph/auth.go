@@ -0,0 +1,146 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+
+	"pkm-sync/internal/config"
+	"pkm-sync/pkg/models"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/endpoints"
+)
+
+// offlineAccessScope asks for a refresh token so sign-in is only needed once.
+const offlineAccessScope = "offline_access"
+
+// NewHTTPClient returns a client authorized for Microsoft Graph with the given delegated scopes. The first run
+// signs in with the device code flow, which works without a browser on the machine running pkm-sync; the
+// token is then cached and refreshed as needed.
+func NewHTTPClient(ctx context.Context, auth models.MicrosoftAuthConfig, sourceType string,
+	scopes ...string,
+) (*http.Client, error) {
+	if auth.ClientID == "" {
+		return nil, fmt.Errorf("auth.client_id is required for %s sources: register an app in Microsoft Entra ID",
+			sourceType)
+	}
+
+	tokenPath := auth.TokenPath
+	if tokenPath == "" {
+		var err error
+
+		tokenPath, err = config.GetMicrosoftTokenPath(sourceType)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get token path: %w", err)
+		}
+	}
+
+	oauthConfig := &oauth2.Config{
+		ClientID: auth.ClientID,
+		Endpoint: endpoints.AzureAD(auth.TenantID),
+		Scopes:   append(scopes, offlineAccessScope),
+	}
+
+	token, err := loadToken(tokenPath)
+	if err != nil {
+		token, err = deviceSignIn(ctx, oauthConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := saveToken(tokenPath, token); err != nil {
+			return nil, err
+		}
+	}
+
+	source := &savingTokenSource{
+		base: oauthConfig.TokenSource(ctx, token),
+		path: tokenPath,
+		last: token.AccessToken,
+	}
+
+	return oauth2.NewClient(ctx, oauth2.ReuseTokenSource(token, source)), nil
+}
+
+func deviceSignIn(ctx context.Context, oauthConfig *oauth2.Config) (*oauth2.Token, error) {
+	response, err := oauthConfig.DeviceAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to start Microsoft sign-in: %w", err)
+	}
+
+	fmt.Println("To authorize this application, visit this URL in your browser:")
+	fmt.Printf("%s\n\n", response.VerificationURI)
+	fmt.Printf("and enter the code %s\n", response.UserCode)
+
+	token, err := oauthConfig.DeviceAccessToken(ctx, response)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve Microsoft token: %w", err)
+	}
+
+	return token, nil
+}
+
+// savingTokenSource writes refreshed tokens back to the cache, since Microsoft rotates refresh tokens and
+// the cached one stops working once it has been used.
+type savingTokenSource struct {
+	base oauth2.TokenSource
+	path string
+
+	mu   sync.Mutex
+	last string
+}
+
+func (s *savingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.base.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if token.AccessToken != s.last {
+		s.last = token.AccessToken
+		if err := saveToken(s.path, token); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+
+	return token, nil
+}
+
+func loadToken(path string) (*oauth2.Token, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	token := &oauth2.Token{}
+	if err := json.Unmarshal(data, token); err != nil {
+		return nil, err
+	}
+
+	if token.AccessToken == "" && token.RefreshToken == "" {
+		return nil, fmt.Errorf("token in %s is empty", path)
+	}
+
+	return token, nil
+}
+
+func saveToken(path string, token *oauth2.Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("unable to encode Microsoft token: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("unable to cache Microsoft token: %w", err)
+	}
+
+	return nil
+}
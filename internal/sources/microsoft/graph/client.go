@@ -0,0 +1,177 @@
+// Package graph is a small Microsoft Graph client shared by the Microsoft sources: device code sign-in,
+// paged list requests and throttling retries.
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultBaseURL = "https://graph.microsoft.com/v1.0"
+
+	// maxRetries bounds how often a throttled (429) or unavailable (503) request is retried
+	maxRetries = 3
+	// maxRetryWait caps the Retry-After wait honoured before each retry
+	maxRetryWait = time.Minute
+)
+
+type Client struct {
+	http    *http.Client
+	baseURL string
+	header  http.Header
+
+	// sleep waits before retries; replaced in tests
+	sleep func(time.Duration)
+}
+
+// NewClient returns a client for baseURL, or DefaultBaseURL when it is empty.
+func NewClient(httpClient *http.Client, baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &Client{
+		http:    httpClient,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		header:  http.Header{},
+		sleep:   time.Sleep,
+	}
+}
+
+// WithHeader returns a copy of the client that sends an extra header with every request, such as the
+// Prefer header choosing Outlook time zones and body formats.
+func (c *Client) WithHeader(key, value string) *Client {
+	clone := *c
+	clone.header = c.header.Clone()
+	clone.header.Add(key, value)
+
+	return &clone
+}
+
+// Error is a failed Graph request.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("graph request failed (%d %s): %s", e.Status, e.Code, e.Message)
+	}
+
+	return fmt.Sprintf("graph request failed (%d)", e.Status)
+}
+
+// Get fetches path (relative to the base URL) with query parameters and decodes the JSON response into v.
+func (c *Client) Get(path string, query url.Values, v interface{}) error {
+	return c.getURL(c.url(path, query), v)
+}
+
+// List pages through a collection, calling fn with each page's values until there are no more pages or fn
+// returns false.
+func (c *Client) List(path string, query url.Values, fn func(values []json.RawMessage) (bool, error)) error {
+	next := c.url(path, query)
+
+	for next != "" {
+		var page struct {
+			Value    []json.RawMessage `json:"value"`
+			NextLink string            `json:"@odata.nextLink"`
+		}
+
+		if err := c.getURL(next, &page); err != nil {
+			return err
+		}
+
+		more, err := fn(page.Value)
+		if err != nil || !more {
+			return err
+		}
+
+		next = page.NextLink
+	}
+
+	return nil
+}
+
+func (c *Client) url(path string, query url.Values) string {
+	u := c.baseURL + "/" + strings.TrimLeft(path, "/")
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	return u
+}
+
+func (c *Client) getURL(u string, v interface{}) error {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return fmt.Errorf("graph request failed: %w", err)
+		}
+
+		req.Header = c.header.Clone()
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return fmt.Errorf("graph request failed: %w", err)
+		}
+
+		if (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) &&
+			attempt < maxRetries {
+			resp.Body.Close()
+			c.sleep(retryAfter(resp.Header.Get("Retry-After"), attempt))
+
+			continue
+		}
+
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return decodeError(resp)
+		}
+
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return fmt.Errorf("failed to decode graph response: %w", err)
+		}
+
+		return nil
+	}
+}
+
+// retryAfter returns the wait a throttled response asks for, or an exponential backoff without one.
+func retryAfter(header string, attempt int) time.Duration {
+	wait := time.Duration(1<<attempt) * time.Second
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		wait = time.Duration(seconds) * time.Second
+	}
+
+	return min(wait, maxRetryWait)
+}
+
+func decodeError(resp *http.Response) error {
+	graphErr := &Error{Status: resp.StatusCode}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+
+	var payload struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	if json.Unmarshal(body, &payload) == nil {
+		graphErr.Code = payload.Error.Code
+		graphErr.Message = payload.Error.Message
+	}
+
+	return graphErr
+}
@@ -0,0 +1,60 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetRetriesThrottledRequests(t *testing.T) {
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+
+			return
+		}
+
+		fmt.Fprint(w, `{"id": "u1"}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.Client(), server.URL)
+
+	var waits []time.Duration
+
+	client.sleep = func(d time.Duration) { waits = append(waits, d) }
+
+	var me struct {
+		ID string `json:"id"`
+	}
+
+	if err := client.Get("/me", nil, &me); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if me.ID != "u1" || len(waits) != 2 || waits[0] != 7*time.Second {
+		t.Errorf("got id %q after waits %v", me.ID, waits)
+	}
+}
+
+func TestGetDecodesErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"error": {"code": "Forbidden", "message": "Missing scope"}}`)
+	}))
+	defer server.Close()
+
+	err := NewClient(server.Client(), server.URL).Get("/me", nil, &struct{}{})
+
+	var graphErr *Error
+	if !errors.As(err, &graphErr) || graphErr.Status != http.StatusForbidden || graphErr.Code != "Forbidden" {
+		t.Errorf("Get() error = %v, want a decoded Graph error", err)
+	}
+}
@@ -0,0 +1,218 @@
+package outlook
+
+import (
+	"strings"
+	"time"
+
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/models"
+)
+
+// graphDateTime is the layout of Graph dateTimeTimeZone values, which carry no offset.
+const graphDateTime = "2006-01-02T15:04:05.9999999"
+
+type emailAddress struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+type dateTimeTimeZone struct {
+	DateTime string `json:"dateTime"`
+	TimeZone string `json:"timeZone"`
+}
+
+// event is a Graph event, as returned by calendarView with occurrences expanded.
+type event struct {
+	ID             string           `json:"id"`
+	ICalUID        string           `json:"iCalUId"`
+	SeriesMasterID string           `json:"seriesMasterId"`
+	Subject        string           `json:"subject"`
+	Start          dateTimeTimeZone `json:"start"`
+	End            dateTimeTimeZone `json:"end"`
+	IsAllDay       bool             `json:"isAllDay"`
+	IsCancelled    bool             `json:"isCancelled"`
+	IsOrganizer    bool             `json:"isOrganizer"`
+	Body           struct {
+		Content string `json:"content"`
+	} `json:"body"`
+	Location struct {
+		DisplayName string `json:"displayName"`
+	} `json:"location"`
+	Organizer struct {
+		EmailAddress emailAddress `json:"emailAddress"`
+	} `json:"organizer"`
+	Attendees []struct {
+		EmailAddress emailAddress `json:"emailAddress"`
+	} `json:"attendees"`
+	ResponseStatus struct {
+		Response string `json:"response"`
+	} `json:"responseStatus"`
+	OnlineMeeting *struct {
+		JoinURL string `json:"joinUrl"`
+	} `json:"onlineMeeting"`
+	OnlineMeetingURL string `json:"onlineMeetingUrl"`
+	ShowAs           string `json:"showAs"` // "oof" for out of office
+}
+
+// eventFilter applies the Google calendar filtering steps to Outlook events: 1) cancellations and RSVP
+// status, 2) attendee allow list, 3) self-only rules.
+type eventFilter struct {
+	self                     map[string]bool
+	attendeeAllowList        []string
+	includeDeclined          bool
+	requireMultipleAttendees bool
+	includeSelfOnlyEvents    bool
+
+	// Recognize join links in locations and bodies; nil uses utils.DefaultMeetingProviders
+	meetingProviders []utils.MeetingProvider
+}
+
+func newEventFilter(config models.OutlookSourceConfig, selfAddresses ...string) *eventFilter {
+	filter := &eventFilter{
+		self:                     make(map[string]bool),
+		attendeeAllowList:        config.AttendeeAllowList,
+		includeDeclined:          config.IncludeDeclined,
+		requireMultipleAttendees: true, // Default: filter out 0-1 attendee events
+		includeSelfOnlyEvents:    config.IncludeSelfOnlyEvents,
+	}
+
+	if config.RequireMultipleAttendees != nil {
+		filter.requireMultipleAttendees = *config.RequireMultipleAttendees
+	}
+
+	for _, address := range selfAddresses {
+		if address != "" {
+			filter.self[strings.ToLower(address)] = true
+		}
+	}
+
+	return filter
+}
+
+func (f *eventFilter) include(ev event) bool {
+	if ev.IsCancelled {
+		return false
+	}
+
+	if !f.includeDeclined && ev.ResponseStatus.Response == "declined" {
+		return false
+	}
+
+	if len(f.attendeeAllowList) > 0 && !f.hasAllowedAttendee(ev) {
+		return false
+	}
+
+	// Events with 0 or 1 attendees are considered "self-only" events
+	if f.requireMultipleAttendees && len(attendeeAddresses(ev)) <= 1 {
+		return f.includeSelfOnlyEvents
+	}
+
+	return true
+}
+
+func (f *eventFilter) hasAllowedAttendee(ev event) bool {
+	for _, address := range attendeeAddresses(ev) {
+		for _, allowed := range f.attendeeAllowList {
+			if strings.ToLower(strings.TrimSpace(allowed)) == address {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// attendeeAddresses returns the lowercased addresses of everyone invited. Unlike Google, Graph leaves the
+// organizer out of the attendee list, so it is added to count attendees the same way.
+func attendeeAddresses(ev event) []string {
+	var addresses []string
+
+	seen := make(map[string]bool)
+
+	add := func(address string) {
+		address = strings.ToLower(strings.TrimSpace(address))
+		if address != "" && !seen[address] {
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+
+	add(ev.Organizer.EmailAddress.Address)
+
+	for _, attendee := range ev.Attendees {
+		add(attendee.EmailAddress.Address)
+	}
+
+	return addresses
+}
+
+func (f *eventFilter) toModel(ev event) *models.CalendarEvent {
+	calEvent := &models.CalendarEvent{
+		ID:               hashID(ev.ID),
+		Summary:          ev.Subject,
+		Description:      strings.TrimSpace(ev.Body.Content),
+		Start:            parseDateTime(ev.Start),
+		End:              parseDateTime(ev.End),
+		IsAllDay:         ev.IsAllDay,
+		Location:         ev.Location.DisplayName,
+		RecurringEventID: hashID(ev.SeriesMasterID),
+	}
+
+	if organizer := ev.Organizer.EmailAddress; organizer.Address != "" {
+		calEvent.Organizer = models.Attendee{
+			Email:       organizer.Address,
+			DisplayName: organizer.Name,
+			Self:        ev.IsOrganizer || f.self[strings.ToLower(organizer.Address)],
+		}
+	}
+
+	for _, attendee := range ev.Attendees {
+		if attendee.EmailAddress.Address != "" {
+			calEvent.Attendees = append(calEvent.Attendees, models.Attendee{
+				Email:       attendee.EmailAddress.Address,
+				DisplayName: attendee.EmailAddress.Name,
+				Self:        f.self[strings.ToLower(attendee.EmailAddress.Address)],
+			})
+		}
+	}
+
+	calEvent.OutOfOffice = ev.ShowAs == "oof"
+
+	providers := f.meetingProviders
+	if providers == nil {
+		providers = utils.DefaultMeetingProviders
+	}
+
+	// Invites from other services, such as Zoom, only have their join link in the location or body
+	calEvent.MeetingURL = ev.OnlineMeetingURL
+	if ev.OnlineMeeting != nil && ev.OnlineMeeting.JoinURL != "" {
+		calEvent.MeetingURL = ev.OnlineMeeting.JoinURL
+	}
+
+	if calEvent.MeetingURL != "" {
+		calEvent.MeetingProvider = utils.MeetingProviderOf(providers, calEvent.MeetingURL)
+	} else {
+		calEvent.MeetingProvider, calEvent.MeetingURL = utils.FindMeetingURL(providers, ev.Location.DisplayName,
+			ev.Body.Content)
+	}
+
+	return calEvent
+}
+
+// parseDateTime reads a dateTimeTimeZone value. Times are requested in UTC; other IANA zones are honoured
+// and unknown ones read as UTC.
+func parseDateTime(value dateTimeTimeZone) time.Time {
+	location := time.UTC
+	if value.TimeZone != "" && value.TimeZone != "UTC" {
+		if loaded, err := time.LoadLocation(value.TimeZone); err == nil {
+			location = loaded
+		}
+	}
+
+	parsed, err := time.ParseInLocation(graphDateTime, value.DateTime, location)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return parsed
+}
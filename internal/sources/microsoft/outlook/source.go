@@ -0,0 +1,269 @@
+// Package outlook implements an Outlook/Exchange calendar source reading events through Microsoft Graph,
+// filtered the same way as Google calendar sources.
+package outlook
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"pkm-sync/internal/sources/google/calendar"
+	"pkm-sync/internal/sources/microsoft/graph"
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+const (
+	SourceTypeOutlookCalendar = "outlook_calendar"
+
+	// allCalendars selects every calendar of the mailbox
+	allCalendars = "all"
+
+	pageSize = "50"
+
+	// preferHeader returns times in UTC and bodies as plain text instead of HTML
+	preferHeader = `outlook.timezone="UTC", outlook.body-content-type="text"`
+)
+
+var graphScopes = []string{"User.Read", "Calendars.Read"}
+
+type OutlookSource struct {
+	config   models.SourceConfig
+	sourceID string
+
+	graph            *graph.Client
+	meetingProviders []utils.MeetingProvider
+	eventFilter      *calendar.EventFilter // Nil keeps every event
+	// now bounds the sync window, which ends a month ahead like Google calendar sources; replaced in tests
+	now func() time.Time
+}
+
+func NewOutlookSourceWithConfig(sourceID string, config models.SourceConfig) *OutlookSource {
+	return &OutlookSource{
+		sourceID: sourceID,
+		config:   config,
+		now:      time.Now,
+	}
+}
+
+func (s *OutlookSource) Name() string {
+	if s.sourceID != "" {
+		return s.sourceID
+	}
+
+	return SourceTypeOutlookCalendar
+}
+
+// Configure signs in to Microsoft Graph. The client argument is ignored since it is authorized for Google.
+func (s *OutlookSource) Configure(_ map[string]interface{}, _ *http.Client) error {
+	if err := calendar.ValidateRecurringMode(s.config.Outlook.RecurringEvents); err != nil {
+		return err
+	}
+
+	meetingProviders, err := utils.ParseMeetingProviders(s.config.MeetingProviders)
+	if err != nil {
+		return err
+	}
+
+	s.meetingProviders = meetingProviders
+
+	location, err := utils.LoadTimezone(s.config.Timezone)
+	if err != nil {
+		return err
+	}
+
+	if s.eventFilter, err = calendar.NewEventFilter(s.config.Outlook.EventFilters, location); err != nil {
+		return err
+	}
+
+	client, err := graph.NewHTTPClient(context.Background(), s.config.Outlook.Auth, SourceTypeOutlookCalendar,
+		graphScopes...)
+	if err != nil {
+		return fmt.Errorf("failed to sign in to Microsoft Graph: %w", err)
+	}
+
+	s.graph = graph.NewClient(client, "")
+
+	return nil
+}
+
+// Fetch returns the events from since until a month ahead on the configured calendars. Recurring events
+// are expanded into occurrences unless recurring_events is "series".
+func (s *OutlookSource) Fetch(since time.Time, limit int) ([]models.FullItem, error) {
+	if s.graph == nil {
+		return nil, fmt.Errorf("outlook_calendar source is not configured")
+	}
+
+	client := s.graph.WithHeader("Prefer", preferHeader)
+
+	var me struct {
+		Mail              string `json:"mail"`
+		UserPrincipalName string `json:"userPrincipalName"`
+	}
+
+	if err := client.Get("/me", nil, &me); err != nil {
+		return nil, fmt.Errorf("failed to get signed-in user: %w", err)
+	}
+
+	calendars, err := s.calendars(client)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := newEventFilter(s.config.Outlook, me.Mail, me.UserPrincipalName)
+	filter.meetingProviders = s.meetingProviders
+	window := url.Values{
+		"startDateTime": {since.UTC().Format(time.RFC3339)},
+		"endDateTime":   {s.now().AddDate(0, 1, 0).UTC().Format(time.RFC3339)},
+		"$orderby":      {"start/dateTime"},
+		"$top":          {pageSize},
+	}
+
+	var events []*models.CalendarEvent
+
+	// A meeting on several selected calendars is kept once, under the first calendar
+	seen := make(map[string]bool)
+
+	for _, cal := range calendars {
+		fetched := 0
+
+		err := client.List(cal.path+"/calendarView", window, func(page []json.RawMessage) (bool, error) {
+			for _, raw := range page {
+				var ev event
+				if err := json.Unmarshal(raw, &ev); err != nil {
+					return false, fmt.Errorf("failed to decode event: %w", err)
+				}
+
+				key := ev.ICalUID + "/" + ev.Start.DateTime
+				if ev.ICalUID == "" {
+					key = ev.ID
+				}
+
+				if seen[key] || !filter.include(ev) {
+					continue
+				}
+
+				seen[key] = true
+				calEvent := filter.toModel(ev)
+
+				if !s.eventFilter.Include(calEvent) {
+					continue
+				}
+
+				calEvent.CalendarID = cal.id
+				events = append(events, calEvent)
+				fetched++
+			}
+
+			return limit <= 0 || fetched < limit, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch events of calendar '%s': %w", cal.name, err)
+		}
+	}
+
+	if s.config.Outlook.RecurringEvents == calendar.RecurringSeries {
+		events = calendar.GroupSeries(events)
+	}
+
+	items := make([]models.FullItem, 0, len(events))
+
+	for _, calEvent := range events {
+		item := models.AsItemInterface(models.FromCalendarEvent(calEvent))
+		item.SetSourceType(SourceTypeOutlookCalendar)
+		item.GetMetadata()["calendar"] = calEvent.CalendarID
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// Capabilities reports that Fetch returns every event in its window, changed since the last sync or not.
+func (s *OutlookSource) Capabilities() interfaces.SourceCapabilities {
+	return interfaces.SourceCapabilities{}
+}
+
+type calendarRef struct {
+	id   string
+	name string
+	path string
+}
+
+// calendars resolves the configured calendar names and IDs; empty means the default calendar.
+func (s *OutlookSource) calendars(client *graph.Client) ([]calendarRef, error) {
+	configured := s.config.Outlook.Calendars
+	if len(configured) == 0 {
+		return []calendarRef{{id: "default", name: "default", path: "/me/calendar"}}, nil
+	}
+
+	var available []calendarRef
+
+	err := client.List("/me/calendars", url.Values{"$top": {pageSize}}, func(page []json.RawMessage) (bool, error) {
+		for _, raw := range page {
+			var cal struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			}
+
+			if err := json.Unmarshal(raw, &cal); err != nil {
+				return false, err
+			}
+
+			available = append(available, calendarRef{
+				id:   cal.ID,
+				name: cal.Name,
+				path: "/me/calendars/" + url.PathEscape(cal.ID),
+			})
+		}
+
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calendars: %w", err)
+	}
+
+	var refs []calendarRef
+
+	for _, want := range configured {
+		if want == allCalendars {
+			return available, nil
+		}
+
+		found := false
+
+		for _, cal := range available {
+			if cal.id == want || strings.EqualFold(cal.name, want) {
+				refs = append(refs, cal)
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("calendar '%s' not found", want)
+		}
+	}
+
+	return refs, nil
+}
+
+func hashID(id string) string {
+	if id == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(id))
+
+	return "outlook_" + hex.EncodeToString(sum[:])[:16]
+}
+
+// Ensure OutlookSource implements Source interface.
+var _ interfaces.Source = (*OutlookSource)(nil)
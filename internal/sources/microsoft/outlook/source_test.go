@@ -0,0 +1,165 @@
+package outlook
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"pkm-sync/internal/sources/microsoft/graph"
+	"pkm-sync/pkg/models"
+)
+
+const calendarView = `{"value": [
+  {"id": "e1", "iCalUId": "ical-1", "subject": "Design review", "isOrganizer": true,
+   "start": {"dateTime": "2025-03-03T15:00:00.0000000", "timeZone": "UTC"},
+   "end": {"dateTime": "2025-03-03T16:00:00.0000000", "timeZone": "UTC"},
+   "body": {"content": "Agenda: flows\n"}, "location": {"displayName": "Room 4"},
+   "organizer": {"emailAddress": {"name": "Me", "address": "me@example.com"}},
+   "attendees": [{"emailAddress": {"name": "Ada", "address": "ada@example.com"}}],
+   "responseStatus": {"response": "organizer"},
+   "onlineMeeting": {"joinUrl": "https://teams.example/join/1"}},
+  {"id": "e2", "iCalUId": "ical-2", "subject": "Declined sync",
+   "start": {"dateTime": "2025-03-04T09:00:00.0000000", "timeZone": "UTC"},
+   "end": {"dateTime": "2025-03-04T09:30:00.0000000", "timeZone": "UTC"},
+   "organizer": {"emailAddress": {"address": "grace@example.com"}},
+   "attendees": [{"emailAddress": {"address": "me@example.com"}}],
+   "responseStatus": {"response": "declined"}},
+  {"id": "e3", "iCalUId": "ical-3", "subject": "Focus block",
+   "start": {"dateTime": "2025-03-05T09:00:00.0000000", "timeZone": "UTC"},
+   "end": {"dateTime": "2025-03-05T11:00:00.0000000", "timeZone": "UTC"},
+   "organizer": {"emailAddress": {"address": "me@example.com"}}, "attendees": []},
+  {"id": "e4", "iCalUId": "ical-4", "seriesMasterId": "s1", "subject": "Standup",
+   "start": {"dateTime": "2025-03-06T09:00:00.0000000", "timeZone": "UTC"},
+   "end": {"dateTime": "2025-03-06T09:15:00.0000000", "timeZone": "UTC"},
+   "organizer": {"emailAddress": {"address": "grace@example.com"}},
+   "attendees": [{"emailAddress": {"address": "me@example.com"}}]},
+  {"id": "e5", "iCalUId": "ical-4", "seriesMasterId": "s1", "subject": "Standup",
+   "start": {"dateTime": "2025-03-07T09:00:00.0000000", "timeZone": "UTC"},
+   "end": {"dateTime": "2025-03-07T09:15:00.0000000", "timeZone": "UTC"},
+   "organizer": {"emailAddress": {"address": "grace@example.com"}},
+   "attendees": [{"emailAddress": {"address": "me@example.com"}}]},
+  {"id": "e6", "iCalUId": "ical-6", "subject": "Cancelled", "isCancelled": true,
+   "start": {"dateTime": "2025-03-08T09:00:00.0000000", "timeZone": "UTC"},
+   "end": {"dateTime": "2025-03-08T09:15:00.0000000", "timeZone": "UTC"},
+   "organizer": {"emailAddress": {"address": "grace@example.com"}},
+   "attendees": [{"emailAddress": {"address": "me@example.com"}}]}
+]}`
+
+func fetch(t *testing.T, config models.OutlookSourceConfig) []models.FullItem {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Prefer") == "" {
+			t.Errorf("request to %s without Prefer header", r.URL.Path)
+		}
+
+		switch r.URL.Path {
+		case "/me":
+			fmt.Fprint(w, `{"mail": "me@example.com"}`)
+		case "/me/calendars":
+			fmt.Fprint(w, `{"value": [{"id": "cal-1", "name": "Calendar"}, {"id": "cal-2", "name": "Team"}]}`)
+		case "/me/calendar/calendarView", "/me/calendars/cal-2/calendarView":
+			fmt.Fprint(w, calendarView)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	source := NewOutlookSourceWithConfig("outlook", models.SourceConfig{Type: SourceTypeOutlookCalendar, Outlook: config})
+	source.graph = graph.NewClient(server.Client(), server.URL)
+	source.now = func() time.Time { return time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC) }
+
+	items, err := source.Fetch(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), 0)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	return items
+}
+
+func titles(items []models.FullItem) []string {
+	var names []string
+	for _, item := range items {
+		names = append(names, item.GetTitle())
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func TestFetchAppliesDefaultFilters(t *testing.T) {
+	items := fetch(t, models.OutlookSourceConfig{})
+
+	got := titles(items)
+	want := []string{"Design review", "Standup", "Standup"}
+
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("titles = %v, want %v (declined, solo and cancelled events dropped)", got, want)
+	}
+
+	for _, item := range items {
+		if item.GetTitle() != "Design review" {
+			continue
+		}
+
+		if item.GetSourceType() != SourceTypeOutlookCalendar || item.GetContent() != "Agenda: flows" {
+			t.Errorf("unexpected event: type=%q content=%q", item.GetSourceType(), item.GetContent())
+		}
+
+		if !item.GetCreatedAt().Equal(time.Date(2025, 3, 3, 15, 0, 0, 0, time.UTC)) {
+			t.Errorf("start parsed as %v", item.GetCreatedAt())
+		}
+
+		links := item.GetLinks()
+		if len(links) != 1 || links[0].URL != "https://teams.example/join/1" {
+			t.Errorf("meeting URL not kept: %v", links)
+		}
+
+		organizer, ok := item.GetMetadata()["organizer"].(models.Attendee)
+		if !ok || !organizer.Self {
+			t.Errorf("organizer not marked as self: %v", item.GetMetadata()["organizer"])
+		}
+	}
+}
+
+func TestFetchFilterOptions(t *testing.T) {
+	include := false
+
+	items := fetch(t, models.OutlookSourceConfig{
+		IncludeDeclined:          true,
+		RequireMultipleAttendees: &include,
+		AttendeeAllowList:        []string{"Grace@example.com"},
+	})
+
+	if want := []string{"Declined sync", "Standup", "Standup"}; fmt.Sprint(titles(items)) != fmt.Sprint(want) {
+		t.Errorf("titles = %v, want %v", titles(items), want)
+	}
+
+	items = fetch(t, models.OutlookSourceConfig{IncludeSelfOnlyEvents: true})
+	if want := []string{"Design review", "Focus block", "Standup", "Standup"}; fmt.Sprint(titles(items)) != fmt.Sprint(want) {
+		t.Errorf("include_self_only_events titles = %v, want %v", titles(items), want)
+	}
+}
+
+func TestFetchGroupsSeriesAndDedupesCalendars(t *testing.T) {
+	items := fetch(t, models.OutlookSourceConfig{Calendars: []string{"team", "cal-2"}, RecurringEvents: "series"})
+
+	if want := []string{"Design review", "Standup"}; fmt.Sprint(titles(items)) != fmt.Sprint(want) {
+		t.Fatalf("titles = %v, want %v", titles(items), want)
+	}
+
+	for _, item := range items {
+		if item.GetTitle() == "Standup" && item.GetMetadata()["occurrence_count"] != 2 {
+			t.Errorf("series not grouped: %v", item.GetMetadata())
+		}
+
+		if item.GetMetadata()["calendar"] != "cal-2" {
+			t.Errorf("calendar metadata = %v", item.GetMetadata()["calendar"])
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package teams
+
+import (
+	"strings"
+	"time"
+
+	"pkm-sync/internal/sources/chat"
+)
+
+// maxTitleLength bounds titles taken from the first line of a message.
+const maxTitleLength = 80
+
+type identity struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+}
+
+type identitySet struct {
+	User *identity `json:"user"`
+}
+
+// message is a Graph chatMessage, used for both channel posts and chat messages.
+type message struct {
+	ID          string       `json:"id"`
+	ReplyToID   string       `json:"replyToId"`
+	MessageType string       `json:"messageType"`
+	Created     time.Time    `json:"createdDateTime"`
+	Modified    time.Time    `json:"lastModifiedDateTime"`
+	Deleted     *time.Time   `json:"deletedDateTime"`
+	Subject     string       `json:"subject"`
+	From        *identitySet `json:"from"`
+	WebURL      string       `json:"webUrl"`
+	Body        struct {
+		ContentType string `json:"contentType"`
+		Content     string `json:"content"`
+	} `json:"body"`
+	Reactions []struct {
+		ReactionType string      `json:"reactionType"`
+		User         identitySet `json:"user"`
+	} `json:"reactions"`
+	Attachments []struct {
+		ID          string `json:"id"`
+		ContentType string `json:"contentType"`
+		ContentURL  string `json:"contentUrl"`
+		Name        string `json:"name"`
+	} `json:"attachments"`
+	Replies []message `json:"replies"`
+}
+
+// isContent reports whether the message is a user message still present, not a system event such as a
+// member joining.
+func (m message) isContent() bool {
+	return m.MessageType == "message" && m.Deleted == nil
+}
+
+func (m message) author() string {
+	if m.From == nil || m.From.User == nil {
+		return ""
+	}
+
+	return m.From.User.DisplayName
+}
+
+// lastActivity is the latest change to the message.
+func (m message) lastActivity() time.Time {
+	if m.Modified.After(m.Created) {
+		return m.Modified
+	}
+
+	return m.Created
+}
+
+// chatMessage groups reactions by emoji for capture decisions. Teams cannot save messages through Graph,
+// so messages are never saved.
+func (m message) chatMessage() chat.Message {
+	var reactions []chat.Reaction
+
+	index := make(map[string]int)
+
+	for _, reaction := range m.Reactions {
+		emoji := chat.NormalizeEmoji(reaction.ReactionType)
+
+		i, ok := index[emoji]
+		if !ok {
+			i = len(reactions)
+			index[emoji] = i
+			reactions = append(reactions, chat.Reaction{Emoji: emoji})
+		}
+
+		if reaction.User.User != nil {
+			reactions[i].Users = append(reactions[i].Users, reaction.User.User.ID)
+		}
+	}
+
+	return chat.Message{Reactions: reactions}
+}
+
+// firstLine returns the first non-empty line of markdown content, shortened for use as a title.
+func firstLine(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(line, "#>*- "))
+		if line == "" {
+			continue
+		}
+
+		if runes := []rune(line); len(runes) > maxTitleLength {
+			return strings.TrimSpace(string(runes[:maxTitleLength])) + "…"
+		}
+
+		return line
+	}
+
+	return ""
+}
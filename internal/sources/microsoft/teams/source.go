@@ -0,0 +1,486 @@
+// Package teams implements a Microsoft Teams source reading channel messages and chats through Microsoft
+// Graph. Messages are grouped into threads the same way as Gmail's thread modes.
+package teams
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"pkm-sync/internal/sources/chat"
+	"pkm-sync/internal/sources/microsoft/graph"
+	"pkm-sync/internal/transform"
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+const (
+	SourceTypeTeams = "teams"
+
+	itemTypeMessage = "message"
+
+	ThreadModeIndividual   = "individual"
+	ThreadModeConsolidated = "consolidated"
+	ThreadModeSummary      = "summary"
+
+	pageSize = "50"
+)
+
+type TeamsSource struct {
+	config   models.SourceConfig
+	sourceID string
+
+	graph   *graph.Client
+	capture *chat.Capture
+	cleanup *transform.ContentCleanupTransformer
+}
+
+// conversation is a channel or chat messages are read from.
+type conversation struct {
+	id      string
+	team    string
+	channel string
+	chat    string
+}
+
+func (c conversation) name() string {
+	if c.chat != "" {
+		return c.chat
+	}
+
+	return c.team + " / " + c.channel
+}
+
+func NewTeamsSourceWithConfig(sourceID string, config models.SourceConfig) *TeamsSource {
+	return &TeamsSource{
+		sourceID: sourceID,
+		config:   config,
+		capture:  chat.NewCapture(config.Teams.Capture),
+		cleanup:  transform.NewContentCleanupTransformer(),
+	}
+}
+
+func (s *TeamsSource) Name() string {
+	if s.sourceID != "" {
+		return s.sourceID
+	}
+
+	return SourceTypeTeams
+}
+
+// Configure signs in to Microsoft Graph. The client argument is ignored since it is authorized for Google.
+func (s *TeamsSource) Configure(_ map[string]interface{}, _ *http.Client) error {
+	client, err := graph.NewHTTPClient(context.Background(), s.config.Teams.Auth, SourceTypeTeams, s.scopes()...)
+	if err != nil {
+		return fmt.Errorf("failed to sign in to Microsoft Graph: %w", err)
+	}
+
+	s.graph = graph.NewClient(client, "")
+
+	return nil
+}
+
+// scopes returns the delegated permissions the configured channels and chats need.
+func (s *TeamsSource) scopes() []string {
+	scopes := []string{"User.Read"}
+	if s.syncChannels() {
+		scopes = append(scopes, "Team.ReadBasic.All", "Channel.ReadBasic.All", "ChannelMessage.Read.All")
+	}
+
+	if s.config.Teams.IncludeChats {
+		scopes = append(scopes, "Chat.Read")
+	}
+
+	return scopes
+}
+
+// syncChannels reports whether channel messages are synced; they are unless the source only asks for chats.
+func (s *TeamsSource) syncChannels() bool {
+	cfg := s.config.Teams
+
+	return !cfg.IncludeChats || len(cfg.Teams) > 0 || len(cfg.Channels) > 0
+}
+
+// Fetch returns the messages changed after since, newest first, grouped by the configured thread mode.
+// Outside individual mode, a channel thread with new activity is fetched whole so its note stays complete.
+func (s *TeamsSource) Fetch(since time.Time, limit int) ([]models.FullItem, error) {
+	if s.graph == nil {
+		return nil, fmt.Errorf("teams source is not configured")
+	}
+
+	var self string
+
+	if s.capture.Enabled() {
+		var me identity
+		if err := s.graph.Get("/me", nil, &me); err != nil {
+			return nil, fmt.Errorf("failed to get signed-in user: %w", err)
+		}
+
+		self = me.ID
+	}
+
+	var items []models.FullItem
+
+	if s.syncChannels() {
+		channelItems, err := s.fetchChannels(since, self)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, channelItems...)
+	}
+
+	if s.config.Teams.IncludeChats {
+		chatItems, err := s.fetchChats(since, self)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, chatItems...)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].GetCreatedAt().After(items[j].GetCreatedAt()) })
+
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+
+	return s.groupThreads(items)
+}
+
+func (s *TeamsSource) Capabilities() interfaces.SourceCapabilities {
+	return interfaces.SourceCapabilities{Incremental: true, Attachments: true}
+}
+
+func (s *TeamsSource) threadMode() string {
+	if s.config.Teams.ThreadMode == "" {
+		return ThreadModeConsolidated
+	}
+
+	return strings.ToLower(s.config.Teams.ThreadMode)
+}
+
+func (s *TeamsSource) groupThreads(items []models.FullItem) ([]models.FullItem, error) {
+	if s.threadMode() == ThreadModeIndividual {
+		return items, nil
+	}
+
+	grouping := transform.NewThreadGroupingTransformer()
+
+	settings := map[string]interface{}{"mode": s.threadMode()}
+	if s.config.Teams.ThreadSummaryLength > 0 {
+		settings["max_thread_items"] = s.config.Teams.ThreadSummaryLength
+	}
+
+	if err := grouping.Configure(settings); err != nil {
+		return nil, err
+	}
+
+	return grouping.Transform(items)
+}
+
+func (s *TeamsSource) fetchChannels(since time.Time, self string) ([]models.FullItem, error) {
+	cfg := s.config.Teams
+
+	var teams []identity
+	if err := s.listAll("/me/joinedTeams", nil, &teams); err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+
+	var items []models.FullItem
+
+	for _, team := range teams {
+		if !matchesAny(cfg.Teams, team.ID, team.DisplayName) {
+			continue
+		}
+
+		var channels []identity
+		if err := s.listAll("/teams/"+url.PathEscape(team.ID)+"/channels", nil, &channels); err != nil {
+			return nil, fmt.Errorf("failed to list channels of team '%s': %w", team.DisplayName, err)
+		}
+
+		for _, channel := range channels {
+			if !matchesAny(cfg.Channels, channel.ID, channel.DisplayName) {
+				continue
+			}
+
+			conv := conversation{id: team.ID + "/" + channel.ID, team: team.DisplayName, channel: channel.DisplayName}
+			path := "/teams/" + url.PathEscape(team.ID) + "/channels/" + url.PathEscape(channel.ID) + "/messages"
+
+			var roots []message
+			if err := s.listAll(path, url.Values{"$top": {pageSize}, "$expand": {"replies"}}, &roots); err != nil {
+				return nil, fmt.Errorf("failed to list messages of channel '%s': %w", conv.name(), err)
+			}
+
+			for _, root := range roots {
+				items = append(items, s.threadItems(conv, root, since, self)...)
+			}
+		}
+	}
+
+	return items, nil
+}
+
+// threadItems converts a channel post and its replies. Replies are titled after the post so the thread
+// keeps one subject.
+func (s *TeamsSource) threadItems(conv conversation, root message, since time.Time, self string) []models.FullItem {
+	thread := append([]message{root}, root.Replies...)
+
+	active := false
+
+	for _, msg := range thread {
+		if !msg.lastActivity().Before(since) {
+			active = true
+
+			break
+		}
+	}
+
+	if !active {
+		return nil
+	}
+
+	threadID := hashID(conv.id + "/" + root.ID)
+	subject := root.Subject
+
+	if subject == "" {
+		subject = firstLine(s.content(root))
+	}
+
+	if subject == "" {
+		subject = conv.name()
+	}
+
+	var items []models.FullItem
+
+	for i, msg := range thread {
+		if !msg.isContent() {
+			continue
+		}
+
+		if s.threadMode() == ThreadModeIndividual && msg.lastActivity().Before(since) {
+			continue
+		}
+
+		title := subject
+		if i > 0 {
+			title = "Re: " + subject
+		}
+
+		if item := s.toItem(conv, msg, title, threadID, subject, self); item != nil {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+type chatInfo struct {
+	ID          string    `json:"id"`
+	Topic       string    `json:"topic"`
+	ChatType    string    `json:"chatType"`
+	LastUpdated time.Time `json:"lastUpdatedDateTime"`
+	Members     []struct {
+		UserID      string `json:"userId"`
+		DisplayName string `json:"displayName"`
+	} `json:"members"`
+}
+
+// displayName is the chat topic, or the other members' names for chats without one.
+func (c chatInfo) displayName(self string) string {
+	if c.Topic != "" {
+		return c.Topic
+	}
+
+	var names []string
+
+	for _, member := range c.Members {
+		if member.UserID != self && member.DisplayName != "" {
+			names = append(names, member.DisplayName)
+		}
+	}
+
+	if len(names) == 0 {
+		return "Chat"
+	}
+
+	return "Chat with " + strings.Join(names, ", ")
+}
+
+// fetchChats reads chat messages. A chat has no threads of its own, so each day of a chat is a thread; the
+// whole first day is fetched so that day's note stays complete.
+func (s *TeamsSource) fetchChats(since time.Time, self string) ([]models.FullItem, error) {
+	var chats []chatInfo
+	if err := s.listAll("/me/chats", url.Values{"$top": {pageSize}, "$expand": {"members"}}, &chats); err != nil {
+		return nil, fmt.Errorf("failed to list chats: %w", err)
+	}
+
+	if !since.IsZero() && s.threadMode() != ThreadModeIndividual {
+		since = time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, since.Location())
+	}
+
+	var items []models.FullItem
+
+	for _, info := range chats {
+		name := info.displayName(self)
+		if !matchesAny(s.config.Teams.Chats, info.ID, name) {
+			continue
+		}
+
+		if !since.IsZero() && !info.LastUpdated.IsZero() && info.LastUpdated.Before(since) {
+			continue
+		}
+
+		query := url.Values{"$top": {pageSize}}
+		if !since.IsZero() {
+			query.Set("$orderby", "lastModifiedDateTime desc")
+			query.Set("$filter", "lastModifiedDateTime gt "+since.UTC().Format(time.RFC3339))
+		}
+
+		var messages []message
+		if err := s.listAll("/chats/"+url.PathEscape(info.ID)+"/messages", query, &messages); err != nil {
+			return nil, fmt.Errorf("failed to list messages of chat '%s': %w", name, err)
+		}
+
+		conv := conversation{id: info.ID, chat: name}
+
+		for _, msg := range messages {
+			if !msg.isContent() {
+				continue
+			}
+
+			day := msg.Created.Local().Format("2006-01-02")
+			subject := name + " " + day
+
+			title := firstLine(s.content(msg))
+			if title == "" {
+				title = subject
+			}
+
+			if item := s.toItem(conv, msg, title, hashID(info.ID+"/"+day), subject, self); item != nil {
+				items = append(items, item)
+			}
+		}
+	}
+
+	return items, nil
+}
+
+// toItem converts a message, or returns nil when capture is enabled and the message was not captured.
+func (s *TeamsSource) toItem(conv conversation, msg message, title, threadID, subject, self string) models.FullItem {
+	capturedBy, ok := s.capture.Match(msg.chatMessage(), self)
+	if !ok {
+		return nil
+	}
+
+	item := models.NewBasicItem(hashID(conv.id+"/"+msg.ID), title)
+	item.SetSourceType(SourceTypeTeams)
+	item.SetItemType(itemTypeMessage)
+	item.SetContent(s.content(msg))
+	item.SetCreatedAt(msg.Created)
+	item.SetUpdatedAt(msg.lastActivity())
+
+	metadata := map[string]interface{}{
+		"thread_id":      threadID,
+		"thread_subject": subject,
+		"message_id":     msg.ID,
+	}
+
+	if author := msg.author(); author != "" {
+		metadata["from"] = author
+	}
+
+	if conv.chat != "" {
+		metadata["chat"] = conv.chat
+	} else {
+		metadata["team"] = conv.team
+		metadata["channel"] = conv.channel
+	}
+
+	if capturedBy != "" {
+		metadata[chat.MetadataCapturedBy] = capturedBy
+	}
+
+	item.SetMetadata(metadata)
+
+	if msg.WebURL != "" {
+		item.SetLinks([]models.Link{{URL: msg.WebURL, Title: "Open in Teams", Type: "external"}})
+	}
+
+	var attachments []models.Attachment
+
+	for _, attachment := range msg.Attachments {
+		// File shares are references to SharePoint or OneDrive; cards and message references have no file
+		if attachment.ContentType == "reference" && attachment.ContentURL != "" {
+			attachments = append(attachments, models.Attachment{
+				ID:   attachment.ID,
+				Name: attachment.Name,
+				URL:  attachment.ContentURL,
+			})
+		}
+	}
+
+	item.SetAttachments(attachments)
+
+	return item
+}
+
+func (s *TeamsSource) content(msg message) string {
+	if strings.EqualFold(msg.Body.ContentType, "html") {
+		return s.cleanup.ProcessHTMLContent(msg.Body.Content)
+	}
+
+	return strings.TrimSpace(msg.Body.Content)
+}
+
+// listAll reads every page of a collection into out, a pointer to a slice.
+func (s *TeamsSource) listAll(path string, query url.Values, out interface{}) error {
+	var values []json.RawMessage
+
+	err := s.graph.List(path, query, func(page []json.RawMessage) (bool, error) {
+		values = append(values, page...)
+
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, out)
+}
+
+// matchesAny reports whether filters is empty or holds the ID or name, ignoring case.
+func matchesAny(filters []string, id, name string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+
+	for _, filter := range filters {
+		if filter == id || strings.EqualFold(filter, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hashID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return "teams_" + hex.EncodeToString(sum[:])[:16]
+}
+
+// Ensure TeamsSource implements Source interface.
+var _ interfaces.Source = (*TeamsSource)(nil)
@@ -0,0 +1,195 @@
+package teams
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pkm-sync/internal/sources/chat"
+	"pkm-sync/internal/sources/microsoft/graph"
+	"pkm-sync/pkg/models"
+)
+
+const (
+	channelMessagesPage1 = `{"value": [
+  {"id": "100", "messageType": "message", "subject": "Release plan",
+   "createdDateTime": "2025-03-01T09:00:00Z", "lastModifiedDateTime": "2025-03-01T09:00:00Z",
+   "from": {"user": {"id": "u2", "displayName": "Ada"}}, "webUrl": "https://teams.example/100",
+   "body": {"contentType": "html", "content": "<p>Shipping <b>Friday</b></p>"},
+   "attachments": [{"id": "a1", "contentType": "reference", "contentUrl": "https://sp.example/plan.docx",
+                    "name": "plan.docx"}],
+   "replies": [
+     {"id": "101", "replyToId": "100", "messageType": "message",
+      "createdDateTime": "2025-03-01T10:00:00Z", "lastModifiedDateTime": "2025-03-01T10:00:00Z",
+      "from": {"user": {"id": "u1", "displayName": "Me"}},
+      "body": {"contentType": "text", "content": "Sounds good"},
+      "reactions": [{"reactionType": "📌", "user": {"user": {"id": "u1"}}}]},
+     {"id": "102", "replyToId": "100", "messageType": "systemEventMessage",
+      "createdDateTime": "2025-03-01T10:30:00Z", "body": {"contentType": "html", "content": ""}}
+   ]}
+], "@odata.nextLink": "%s/teams/t1/channels/c1/messages?page=2"}`
+
+	channelMessagesPage2 = `{"value": [
+  {"id": "90", "messageType": "message", "subject": "",
+   "createdDateTime": "2024-12-01T09:00:00Z", "lastModifiedDateTime": "2024-12-01T09:00:00Z",
+   "from": {"user": {"id": "u2", "displayName": "Ada"}},
+   "body": {"contentType": "text", "content": "Old news"}, "replies": []}
+]}`
+
+	chatsPage = `{"value": [
+  {"id": "19:chat", "topic": null, "chatType": "oneOnOne", "lastUpdatedDateTime": "2025-03-02T12:00:00Z",
+   "members": [{"userId": "u1", "displayName": "Me"}, {"userId": "u3", "displayName": "Grace"}]}
+]}`
+
+	chatMessagesPage = `{"value": [
+  {"id": "201", "messageType": "message", "createdDateTime": "2025-03-02T12:00:00Z",
+   "from": {"user": {"id": "u3", "displayName": "Grace"}},
+   "body": {"contentType": "text", "content": "Lunch?"},
+   "reactions": [{"reactionType": "like", "user": {"user": {"id": "u1"}}}]},
+  {"id": "200", "messageType": "message", "createdDateTime": "2025-03-02T11:00:00Z",
+   "from": {"user": {"id": "u1", "displayName": "Me"}},
+   "body": {"contentType": "text", "content": "Morning"}}
+]}`
+)
+
+func newTestSource(t *testing.T, config models.TeamsSourceConfig) (*TeamsSource, *[]string) {
+	t.Helper()
+
+	var requests []string
+
+	var server *httptest.Server
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+
+		switch {
+		case r.URL.Path == "/me":
+			fmt.Fprint(w, `{"id": "u1", "displayName": "Me"}`)
+		case r.URL.Path == "/me/joinedTeams":
+			fmt.Fprint(w, `{"value": [{"id": "t1", "displayName": "Platform"}, {"id": "t2", "displayName": "Sales"}]}`)
+		case r.URL.Path == "/teams/t1/channels":
+			fmt.Fprint(w, `{"value": [{"id": "c1", "displayName": "General"}]}`)
+		case r.URL.Path == "/teams/t1/channels/c1/messages" && r.URL.Query().Get("page") == "2":
+			fmt.Fprint(w, channelMessagesPage2)
+		case r.URL.Path == "/teams/t1/channels/c1/messages":
+			fmt.Fprintf(w, channelMessagesPage1, server.URL)
+		case r.URL.Path == "/me/chats":
+			fmt.Fprint(w, chatsPage)
+		case r.URL.Path == "/chats/19:chat/messages":
+			fmt.Fprint(w, chatMessagesPage)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"code": "NotFound", "message": "no such resource"}}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	source := NewTeamsSourceWithConfig("teams", models.SourceConfig{Type: SourceTypeTeams, Teams: config})
+	source.graph = graph.NewClient(server.Client(), server.URL)
+
+	return source, &requests
+}
+
+func byTitle(items []models.FullItem) map[string]models.FullItem {
+	titles := make(map[string]models.FullItem)
+	for _, item := range items {
+		titles[item.GetTitle()] = item
+	}
+
+	return titles
+}
+
+func TestFetchChannelMessagesIndividually(t *testing.T) {
+	source, _ := newTestSource(t, models.TeamsSourceConfig{Teams: []string{"platform"}, ThreadMode: ThreadModeIndividual})
+
+	items, err := source.Fetch(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), 0)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	titles := byTitle(items)
+	if len(items) != 2 {
+		t.Fatalf("expected the post and its reply (system message and old thread skipped), got %v", titles)
+	}
+
+	post := titles["Release plan"]
+	if post == nil || post.GetContent() != "Shipping **Friday**" {
+		t.Fatalf("post not converted: %v", titles)
+	}
+
+	if post.GetMetadata()["team"] != "Platform" || post.GetMetadata()["from"] != "Ada" {
+		t.Errorf("unexpected metadata: %v", post.GetMetadata())
+	}
+
+	if attachments := post.GetAttachments(); len(attachments) != 1 || attachments[0].Name != "plan.docx" {
+		t.Errorf("file reference not kept: %+v", attachments)
+	}
+
+	reply := titles["Re: Release plan"]
+	if reply == nil || reply.GetMetadata()["thread_id"] != post.GetMetadata()["thread_id"] {
+		t.Errorf("reply not in the post's thread: %v", titles)
+	}
+}
+
+func TestFetchConsolidatesThreads(t *testing.T) {
+	source, _ := newTestSource(t, models.TeamsSourceConfig{Teams: []string{"t1"}})
+
+	items, err := source.Fetch(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), 0)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if len(items) != 1 {
+		t.Fatalf("expected one thread note, got %v", byTitle(items))
+	}
+
+	thread := items[0]
+	if thread.GetItemType() != "thread" || !strings.Contains(thread.GetContent(), "Sounds good") ||
+		!strings.Contains(thread.GetContent(), "Release plan") {
+		t.Errorf("unexpected thread %q (%s):\n%s", thread.GetTitle(), thread.GetItemType(), thread.GetContent())
+	}
+}
+
+func TestFetchChatsByDayWithReactionCapture(t *testing.T) {
+	source, requests := newTestSource(t, models.TeamsSourceConfig{
+		IncludeChats: true,
+		ThreadMode:   ThreadModeIndividual,
+		Capture:      models.ChatCaptureConfig{Reactions: []string{":thumbsup:"}},
+	})
+
+	items, err := source.Fetch(time.Date(2025, 3, 2, 8, 0, 0, 0, time.UTC), 0)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if len(items) != 1 || items[0].GetTitle() != "Lunch?" {
+		t.Fatalf("expected only the message I reacted to, got %v", byTitle(items))
+	}
+
+	metadata := items[0].GetMetadata()
+	if metadata["chat"] != "Chat with Grace" || metadata[chat.MetadataCapturedBy] != chat.CaptureReaction {
+		t.Errorf("unexpected metadata: %v", metadata)
+	}
+
+	for _, request := range *requests {
+		if strings.HasPrefix(request, "/me/joinedTeams") {
+			t.Errorf("chat-only source listed teams: %v", *requests)
+		}
+
+		if strings.HasPrefix(request, "/chats/") && !strings.Contains(request, "%24filter=lastModifiedDateTime+gt") {
+			t.Errorf("chat messages fetched without a since filter: %s", request)
+		}
+	}
+}
+
+func TestFetchReportsGraphErrors(t *testing.T) {
+	source, _ := newTestSource(t, models.TeamsSourceConfig{Teams: []string{"Sales"}})
+
+	_, err := source.Fetch(time.Time{}, 0)
+	if err == nil || !strings.Contains(err.Error(), "NotFound") {
+		t.Errorf("Fetch() error = %v, want the Graph error", err)
+	}
+}
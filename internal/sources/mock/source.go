@@ -0,0 +1,355 @@
+// Package mock implements a source that generates emails, threads and calendar events from fixture files
+// instead of an account, so targets and transformers can be tried, demoed and debugged offline. Fixtures
+// are converted through the same code as fetched Gmail messages and Calendar events.
+package mock
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"pkm-sync/internal/sources/google/gmail"
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+
+	gmailapi "google.golang.org/api/gmail/v1"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	SourceTypeMock = "mock"
+
+	// defaultEventDuration is the length of fixture events without an end or duration
+	defaultEventDuration = time.Hour
+
+	// messageIDDomain makes the Message-ID headers of fixture emails
+	messageIDDomain = "mock.pkm-sync"
+)
+
+// demoFixtures is used when a mock source has no fixtures configured.
+//
+//go:embed fixtures/demo.yaml
+var demoFixtures []byte
+
+// fixtures is the content of a fixture file.
+type fixtures struct {
+	Emails []emailFixture `yaml:"emails"`
+	Events []eventFixture `yaml:"events"`
+}
+
+// emailFixture describes one email. Emails sharing a thread form a thread when the source's gmail
+// settings include threads.
+type emailFixture struct {
+	ID      string   `yaml:"id"`
+	Thread  string   `yaml:"thread"`
+	From    string   `yaml:"from"`
+	To      []string `yaml:"to"`
+	Cc      []string `yaml:"cc"`
+	Subject string   `yaml:"subject"`
+	Date    string   `yaml:"date"` // A date or timestamp, or how long ago in --since syntax, e.g. "2d"
+	Labels  []string `yaml:"labels"`
+	Body    string   `yaml:"body"`
+	HTML    bool     `yaml:"html"` // The body is HTML rather than plain text
+}
+
+// eventFixture describes one calendar event.
+type eventFixture struct {
+	ID          string   `yaml:"id"`
+	Title       string   `yaml:"title"`
+	Start       string   `yaml:"start"` // Like an email's date
+	End         string   `yaml:"end"`
+	Duration    string   `yaml:"duration"` // Used without end, e.g. "30m"; defaults to an hour
+	AllDay      bool     `yaml:"all_day"`
+	Location    string   `yaml:"location"`
+	Organizer   string   `yaml:"organizer"`
+	Attendees   []string `yaml:"attendees"`
+	Description string   `yaml:"description"`
+	MeetingURL  string   `yaml:"meeting_url"`
+}
+
+type MockSource struct {
+	config   models.SourceConfig
+	sourceID string
+
+	fixtures fixtures
+
+	// now anchors relative fixture dates; replaced in tests
+	now func() time.Time
+}
+
+func NewMockSourceWithConfig(sourceID string, config models.SourceConfig) *MockSource {
+	return &MockSource{
+		sourceID: sourceID,
+		config:   config,
+		now:      time.Now,
+	}
+}
+
+func (s *MockSource) Name() string {
+	if s.sourceID != "" {
+		return s.sourceID
+	}
+
+	return SourceTypeMock
+}
+
+// Configure loads the fixture files, or the built-in demo fixtures when none are configured.
+func (s *MockSource) Configure(_ map[string]interface{}, _ *http.Client) error {
+	s.fixtures = fixtures{}
+
+	if len(s.config.Mock.Fixtures) == 0 {
+		return parseFixtures(demoFixtures, "built-in demo fixtures", &s.fixtures)
+	}
+
+	for _, path := range s.config.Mock.Fixtures {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read mock fixtures: %w", err)
+		}
+
+		if err := parseFixtures(data, path, &s.fixtures); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseFixtures adds the emails and events of a fixture file, YAML or JSON, to all.
+func parseFixtures(data []byte, name string, all *fixtures) error {
+	var parsed fixtures
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		return fmt.Errorf("failed to parse mock fixtures %s: %w", name, err)
+	}
+
+	all.Emails = append(all.Emails, parsed.Emails...)
+	all.Events = append(all.Events, parsed.Events...)
+
+	return nil
+}
+
+// Fetch generates the fixture items created since since, newest first. Emails are converted with the
+// source's gmail settings, like those of a gmail source.
+func (s *MockSource) Fetch(since time.Time, limit int) ([]models.FullItem, error) {
+	now := s.now()
+
+	var items []models.FullItem
+
+	var messages []*models.Item
+
+	for i, fixture := range s.fixtures.Emails {
+		message, err := fixture.message(now)
+		if err != nil {
+			return nil, fmt.Errorf("mock email %d: %w", i+1, err)
+		}
+
+		item, err := gmail.FromGmailMessage(message, s.config.Gmail)
+		if err != nil {
+			return nil, fmt.Errorf("mock email %d: %w", i+1, err)
+		}
+
+		if !item.CreatedAt.Before(since) {
+			messages = append(messages, item)
+		}
+	}
+
+	if s.config.Gmail.IncludeThreads {
+		threadProcessor := gmail.NewThreadProcessor(s.config.Gmail)
+
+		grouped, err := threadProcessor.ProcessThreads(messages)
+		if err != nil {
+			return nil, fmt.Errorf("failed to process mock threads: %w", err)
+		}
+
+		messages = grouped
+	}
+
+	for _, message := range messages {
+		items = append(items, models.AsItemInterface(message))
+	}
+
+	for i, fixture := range s.fixtures.Events {
+		event, err := fixture.event(now)
+		if err != nil {
+			return nil, fmt.Errorf("mock event %d: %w", i+1, err)
+		}
+
+		if !event.Start.Before(since) {
+			items = append(items, models.AsItemInterface(models.FromCalendarEvent(event)))
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].GetCreatedAt().After(items[j].GetCreatedAt()) })
+
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+
+	return items, nil
+}
+
+// Capabilities reports that fixture items are filtered by since, like the sources they stand in for.
+func (s *MockSource) Capabilities() interfaces.SourceCapabilities {
+	return interfaces.SourceCapabilities{Incremental: true}
+}
+
+// message builds the Gmail API message a fixture email would be fetched as.
+func (f emailFixture) message(now time.Time) (*gmailapi.Message, error) {
+	date, err := fixtureTime(f.Date, now)
+	if err != nil {
+		return nil, fmt.Errorf("date: %w", err)
+	}
+
+	id := f.ID
+	if id == "" {
+		id = fixtureID("mock_email", f.Subject, date)
+	}
+
+	thread := f.Thread
+	if thread == "" {
+		thread = id
+	}
+
+	headers := []*gmailapi.MessagePartHeader{
+		{Name: "Message-ID", Value: "<" + id + "@" + messageIDDomain + ">"},
+		{Name: "Subject", Value: f.Subject},
+		{Name: "Date", Value: date.Format(time.RFC1123Z)},
+	}
+
+	for name, value := range map[string]string{
+		"From": f.From,
+		"To":   strings.Join(f.To, ", "),
+		"Cc":   strings.Join(f.Cc, ", "),
+	} {
+		if value != "" {
+			headers = append(headers, &gmailapi.MessagePartHeader{Name: name, Value: value})
+		}
+	}
+
+	sort.SliceStable(headers, func(i, j int) bool { return headers[i].Name < headers[j].Name })
+
+	mimeType := "text/plain"
+	if f.HTML {
+		mimeType = "text/html"
+	}
+
+	return &gmailapi.Message{
+		Id:           id,
+		ThreadId:     thread,
+		LabelIds:     f.Labels,
+		Snippet:      snippet(f.Body),
+		InternalDate: date.UnixMilli(),
+		SizeEstimate: int64(len(f.Body)),
+		Payload: &gmailapi.MessagePart{
+			MimeType: mimeType,
+			Headers:  headers,
+			Body:     &gmailapi.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte(f.Body))},
+		},
+	}, nil
+}
+
+// event builds the calendar event a fixture event would be fetched as.
+func (f eventFixture) event(now time.Time) (*models.CalendarEvent, error) {
+	start, err := fixtureTime(f.Start, now)
+	if err != nil {
+		return nil, fmt.Errorf("start: %w", err)
+	}
+
+	end := start.Add(defaultEventDuration)
+
+	switch {
+	case f.End != "":
+		if end, err = fixtureTime(f.End, now); err != nil {
+			return nil, fmt.Errorf("end: %w", err)
+		}
+	case f.Duration != "":
+		duration, err := time.ParseDuration(f.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("duration: %w", err)
+		}
+
+		end = start.Add(duration)
+	}
+
+	if f.AllDay {
+		start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+		end = start.AddDate(0, 0, 1)
+	}
+
+	id := f.ID
+	if id == "" {
+		id = fixtureID("mock_event", f.Title, start)
+	}
+
+	event := &models.CalendarEvent{
+		ID:          id,
+		Summary:     f.Title,
+		Description: f.Description,
+		Start:       start,
+		End:         end,
+		StartTime:   start,
+		EndTime:     end,
+		IsAllDay:    f.AllDay,
+		Location:    f.Location,
+		MeetingURL:  f.MeetingURL,
+	}
+
+	if f.MeetingURL != "" {
+		event.MeetingProvider = utils.MeetingProviderOf(utils.DefaultMeetingProviders, f.MeetingURL)
+	}
+
+	if f.Organizer != "" {
+		event.Organizer = attendee(f.Organizer)
+	}
+
+	for _, address := range f.Attendees {
+		event.Attendees = append(event.Attendees, attendee(address))
+	}
+
+	return event, nil
+}
+
+// fixtureTime reads a fixture date: anything --since accepts, so "2d" is two days before now.
+func fixtureTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return now, nil
+	}
+
+	return utils.ParseSince(value, now)
+}
+
+// fixtureID derives a stable ID for a fixture without one.
+func fixtureID(prefix, title string, date time.Time) string {
+	sum := sha256.Sum256([]byte(title + "\x00" + date.UTC().Format(time.RFC3339)))
+
+	return prefix + "_" + hex.EncodeToString(sum[:])[:16]
+}
+
+// attendee reads an address such as "Ada Lovelace <ada@example.com>".
+func attendee(address string) models.Attendee {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return models.Attendee{Email: strings.TrimSpace(address)}
+	}
+
+	return models.Attendee{Email: parsed.Address, DisplayName: parsed.Name}
+}
+
+// snippet is the start of a body, like the snippets Gmail returns.
+func snippet(body string) string {
+	text := strings.Join(strings.Fields(body), " ")
+	if runes := []rune(text); len(runes) > 100 {
+		return string(runes[:100])
+	}
+
+	return text
+}
@@ -0,0 +1,151 @@
+package mock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+var testNow = time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+
+const testFixtures = `
+emails:
+  - id: m1
+    thread: t1
+    from: Ada Lovelace <ada@example.com>
+    to: [grace@example.com]
+    subject: Kickoff
+    date: 2025-06-01T09:00:00Z
+    body: Let's start.
+  - id: m2
+    thread: t1
+    from: grace@example.com
+    subject: "Re: Kickoff"
+    date: 2025-06-02T09:00:00Z
+    body: Agreed.
+  - subject: Old news
+    date: 2025-01-01
+    body: Too old.
+events:
+  - title: Standup
+    start: 2025-06-03T09:00:00Z
+    duration: 15m
+    organizer: Ada Lovelace <ada@example.com>
+    attendees: [grace@example.com]
+`
+
+func newTestSource(t *testing.T, config models.SourceConfig) *MockSource {
+	t.Helper()
+
+	source := NewMockSourceWithConfig("demo", config)
+	source.now = func() time.Time { return testNow }
+
+	if err := source.Configure(nil, nil); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	return source
+}
+
+func writeFixtures(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "fixtures.yaml")
+	if err := os.WriteFile(path, []byte(testFixtures), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestFetchFixtures(t *testing.T) {
+	config := models.SourceConfig{Type: SourceTypeMock, Mock: models.MockSourceConfig{Fixtures: []string{writeFixtures(t)}}}
+	config.Gmail.ExtractRecipients = true
+
+	source := newTestSource(t, config)
+
+	items, err := source.Fetch(time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), 0)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	var ids []string
+	for _, item := range items {
+		ids = append(ids, item.GetID())
+	}
+
+	if len(items) != 3 || ids[1] != "m2" || ids[2] != "m1" {
+		t.Fatalf("Fetch() = %v, want the event, m2 and m1, newest first", ids)
+	}
+
+	event := items[0]
+	if event.GetItemType() != "event" || event.GetTitle() != "Standup" {
+		t.Errorf("first item = %s %q, want the Standup event", event.GetItemType(), event.GetTitle())
+	}
+
+	if end, _ := event.GetMetadata()["end_time"].(time.Time); !end.Equal(time.Date(2025, 6, 3, 9, 15, 0, 0, time.UTC)) {
+		t.Errorf("event end_time = %v, want 15 minutes after its start", event.GetMetadata()["end_time"])
+	}
+
+	email := items[2]
+	if email.GetSourceType() != "gmail" || email.GetContent() != "Let's start." {
+		t.Errorf("email = %s %q, want a converted Gmail message", email.GetSourceType(), email.GetContent())
+	}
+
+	if from, ok := email.GetMetadata()["from"].(models.Recipient); !ok || from.Email != "ada@example.com" {
+		t.Errorf("email from = %v, want ada@example.com", email.GetMetadata()["from"])
+	}
+
+	// Fixtures without an ID get the same one on every run
+	again, err := source.Fetch(time.Time{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if again[len(again)-1].GetID() != fixtureID("mock_email", "Old news", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("fixture without ID got ID %s", again[len(again)-1].GetID())
+	}
+}
+
+func TestFetchThreads(t *testing.T) {
+	config := models.SourceConfig{Type: SourceTypeMock, Mock: models.MockSourceConfig{Fixtures: []string{writeFixtures(t)}}}
+	config.Gmail.IncludeThreads = true
+	config.Gmail.ThreadMode = "consolidated"
+
+	items, err := newTestSource(t, config).Fetch(time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), 0)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("Fetch() = %d items, want the event and one thread", len(items))
+	}
+}
+
+func TestDemoFixtures(t *testing.T) {
+	source := newTestSource(t, models.SourceConfig{Type: SourceTypeMock})
+
+	items, err := source.Fetch(testNow.AddDate(0, 0, -7), 0)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if len(items) != len(source.fixtures.Emails)+len(source.fixtures.Events) {
+		t.Errorf("Fetch() = %d items, want every demo fixture within a week", len(items))
+	}
+}
+
+func TestConfigureRejectsBadFixtures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.yaml")
+	if err := os.WriteFile(path, []byte("emails: {"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source := NewMockSourceWithConfig("demo", models.SourceConfig{Mock: models.MockSourceConfig{Fixtures: []string{path}}})
+	if err := source.Configure(nil, nil); err == nil {
+		t.Error("Configure() accepted unparseable fixtures")
+	}
+}
@@ -0,0 +1,241 @@
+// Package statestore reads and writes the state stores and indexes pkm-sync keeps between runs, which can
+// hold subjects, task text and the terms of email bodies. With app.state_encryption they are encrypted at
+// rest with AES-256-GCM under a key derived from a passphrase.
+package statestore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	"pkm-sync/pkg/models"
+)
+
+const (
+	// DefaultPassphraseEnv is the environment variable holding the passphrase when no other is configured.
+	DefaultPassphraseEnv = "PKM_SYNC_PASSPHRASE"
+
+	// header starts every encrypted file; files without it are read as plain text.
+	header        = "pkm-sync encrypted v1\n"
+	saltSize      = 16
+	keySize       = 32 // AES-256
+	kdfIterations = 600000
+)
+
+// ErrLocked is returned when reading an encrypted file without a passphrase.
+var ErrLocked = errors.New("file is encrypted: enable app.state_encryption and provide its passphrase")
+
+var (
+	mu         sync.Mutex
+	passphrase string
+	writeSalt  []byte            // Salt of the files written by this process
+	keys       map[string][]byte // Derived keys by salt, since deriving one is deliberately slow
+)
+
+// Configure sets up encryption from app.state_encryption, reading the passphrase from passphrase_command
+// (e.g. an OS keyring lookup) or else the passphrase_env environment variable. Disabled settings turn
+// encryption off.
+func Configure(settings models.StateEncryptionConfig) error {
+	if !settings.Enabled {
+		SetPassphrase("")
+
+		return nil
+	}
+
+	secret, err := resolvePassphrase(settings)
+	if err != nil {
+		return err
+	}
+
+	SetPassphrase(secret)
+
+	return nil
+}
+
+// resolvePassphrase returns the passphrase of enabled settings.
+func resolvePassphrase(settings models.StateEncryptionConfig) (string, error) {
+	if command := strings.TrimSpace(settings.PassphraseCommand); command != "" {
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.Command("cmd", "/C", command)
+		} else {
+			cmd = exec.Command("sh", "-c", command)
+		}
+
+		cmd.Stderr = os.Stderr
+
+		output, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("state_encryption passphrase_command failed: %w", err)
+		}
+
+		secret := strings.TrimRight(string(output), "\r\n")
+		if secret == "" {
+			return "", errors.New("state_encryption passphrase_command printed no passphrase")
+		}
+
+		return secret, nil
+	}
+
+	env := settings.PassphraseEnv
+	if env == "" {
+		env = DefaultPassphraseEnv
+	}
+
+	if secret := os.Getenv(env); secret != "" {
+		return secret, nil
+	}
+
+	return "", fmt.Errorf("state_encryption is enabled but no passphrase is set: export %s or configure passphrase_command",
+		env)
+}
+
+// SetPassphrase turns on encryption of written files under a passphrase; "" turns it off. Encrypted files
+// stay readable only with the passphrase they were written with.
+func SetPassphrase(secret string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	passphrase = secret
+	writeSalt = nil
+	keys = nil
+}
+
+// Enabled reports whether written files are encrypted.
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return passphrase != ""
+}
+
+// Encrypted reports whether data is the content of an encrypted file.
+func Encrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(header))
+}
+
+// ReadFile reads a file written by WriteFile, decrypting it when it is encrypted. Plain files are returned
+// as they are, so turning encryption on keeps existing state, which is encrypted when next written. Errors
+// of reading the file are returned unwrapped, so os.IsNotExist works on them.
+func ReadFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil || !Encrypted(data) {
+		return data, err
+	}
+
+	plain, err := open(data[len(header):])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return plain, nil
+}
+
+// WriteFile writes data to path like os.WriteFile, encrypting it when a passphrase is set. Encrypted files
+// are only readable by their owner.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	if !Enabled() {
+		return os.WriteFile(path, data, perm)
+	}
+
+	sealed, err := seal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt %s: %w", path, err)
+	}
+
+	return os.WriteFile(path, sealed, perm&0600)
+}
+
+// seal encrypts data as header, salt, nonce and the AES-GCM ciphertext.
+func seal(data []byte) ([]byte, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if writeSalt == nil {
+		writeSalt = make([]byte, saltSize)
+		if _, err := rand.Read(writeSalt); err != nil {
+			return nil, err
+		}
+	}
+
+	aead, err := cipherFor(writeSalt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := append([]byte(header), writeSalt...)
+	sealed = append(sealed, nonce...)
+
+	return aead.Seal(sealed, nonce, data, nil), nil
+}
+
+// open decrypts the salt, nonce and ciphertext following an encrypted file's header.
+func open(data []byte) ([]byte, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if passphrase == "" {
+		return nil, ErrLocked
+	}
+
+	if len(data) < saltSize {
+		return nil, errors.New("encrypted file is truncated")
+	}
+
+	aead, err := cipherFor(data[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+
+	data = data[saltSize:]
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("encrypted file is truncated")
+	}
+
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt: wrong passphrase or corrupted file")
+	}
+
+	return plain, nil
+}
+
+// cipherFor returns the AES-GCM cipher of the key derived from the passphrase and salt. mu must be held.
+func cipherFor(salt []byte) (cipher.AEAD, error) {
+	key, found := keys[string(salt)]
+	if !found {
+		derived, err := pbkdf2.Key(sha256.New, passphrase, salt, kdfIterations, keySize)
+		if err != nil {
+			return nil, err
+		}
+
+		if keys == nil {
+			keys = make(map[string][]byte)
+		}
+
+		key = derived
+		keys[string(salt)] = key
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
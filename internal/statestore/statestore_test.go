@@ -0,0 +1,104 @@
+package statestore
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestWriteFileEncryptsWithPassphrase(t *testing.T) {
+	t.Cleanup(func() { SetPassphrase("") })
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	content := []byte(`{"subject":"Quarterly results"}`)
+
+	SetPassphrase("correct horse")
+
+	if err := WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !Encrypted(raw) || bytes.Contains(raw, []byte("Quarterly")) {
+		t.Fatalf("file is not encrypted: %q", raw)
+	}
+
+	if got, err := ReadFile(path); err != nil || !bytes.Equal(got, content) {
+		t.Errorf("ReadFile() = %q, %v", got, err)
+	}
+
+	// A new process derives the key from the file's salt
+	SetPassphrase("correct horse")
+
+	if got, err := ReadFile(path); err != nil || !bytes.Equal(got, content) {
+		t.Errorf("ReadFile() after restart = %q, %v", got, err)
+	}
+
+	SetPassphrase("wrong")
+
+	if _, err := ReadFile(path); err == nil {
+		t.Error("ReadFile() with wrong passphrase succeeded")
+	}
+
+	SetPassphrase("")
+
+	if _, err := ReadFile(path); !errors.Is(err, ErrLocked) {
+		t.Errorf("ReadFile() without passphrase = %v, want ErrLocked", err)
+	}
+}
+
+func TestReadFileKeepsPlainState(t *testing.T) {
+	t.Cleanup(func() { SetPassphrase("") })
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte(`{"runs":3}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	SetPassphrase("secret")
+
+	if got, err := ReadFile(path); err != nil || string(got) != `{"runs":3}` {
+		t.Errorf("ReadFile() of plain file = %q, %v", got, err)
+	}
+
+	if _, err := ReadFile(filepath.Join(t.TempDir(), "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("ReadFile() of missing file = %v, want not exist", err)
+	}
+}
+
+func TestConfigurePassphraseSources(t *testing.T) {
+	t.Cleanup(func() { SetPassphrase("") })
+
+	t.Setenv("PKM_SYNC_TEST_PASSPHRASE", "from-env")
+
+	if err := Configure(models.StateEncryptionConfig{Enabled: true, PassphraseEnv: "PKM_SYNC_TEST_PASSPHRASE"}); err != nil ||
+		passphrase != "from-env" {
+		t.Errorf("Configure() from env = %v, passphrase %q", err, passphrase)
+	}
+
+	t.Setenv(DefaultPassphraseEnv, "")
+
+	if err := Configure(models.StateEncryptionConfig{Enabled: true}); err == nil {
+		t.Error("Configure() without passphrase succeeded")
+	}
+
+	if runtime.GOOS != "windows" {
+		if err := Configure(models.StateEncryptionConfig{Enabled: true, PassphraseCommand: "echo from-keyring"}); err != nil ||
+			passphrase != "from-keyring" {
+			t.Errorf("Configure() from command = %v, passphrase %q", err, passphrase)
+		}
+	}
+
+	if err := Configure(models.StateEncryptionConfig{}); err != nil || Enabled() {
+		t.Errorf("Configure() disabled = %v, enabled %v", err, Enabled())
+	}
+}
@@ -0,0 +1,202 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"pkm-sync/internal/statestore"
+	"pkm-sync/pkg/models"
+)
+
+// bootstrapBarWidth is the number of cells in the bootstrap progress bar.
+const bootstrapBarWidth = 30
+
+// BootstrapStateFile returns the name of the progress store of a source's bootstrap in the config directory.
+func BootstrapStateFile(source string) string {
+	return "bootstrap-" + source + ".json"
+}
+
+// BootstrapChunk is how much history a bootstrap imports per step: one calendar month, or a number of
+// messages.
+type BootstrapChunk struct {
+	Messages int // Messages per chunk; 0 imports a calendar month per chunk
+}
+
+// ParseBootstrapChunk parses a chunk size: "month" or a number of messages.
+func ParseBootstrapChunk(s string) (BootstrapChunk, error) {
+	if s == "" || strings.EqualFold(s, "month") {
+		return BootstrapChunk{}, nil
+	}
+
+	messages, err := strconv.Atoi(s)
+	if err != nil || messages <= 0 {
+		return BootstrapChunk{}, fmt.Errorf("invalid bootstrap chunk '%s': use 'month' or a number of messages", s)
+	}
+
+	return BootstrapChunk{Messages: messages}, nil
+}
+
+func (c BootstrapChunk) String() string {
+	if c.Messages > 0 {
+		return fmt.Sprintf("%d messages", c.Messages)
+	}
+
+	return "month"
+}
+
+// BootstrapState is the progress of a bootstrap, which imports a source's history newest first. Everything
+// from Cursor up to Top is imported; items newer than Top are left to regular syncs.
+type BootstrapState struct {
+	Since   time.Time     `json:"since"`
+	Top     time.Time     `json:"top"`
+	Cursor  time.Time     `json:"cursor"`
+	Chunks  int           `json:"chunks"`
+	Items   int           `json:"items"`
+	Elapsed time.Duration `json:"elapsed"` // Time spent importing chunks, over all runs
+}
+
+// LoadBootstrap reads the progress of a bootstrap back to since, starting one at now when path does not
+// exist. A bootstrap resumed with an earlier since continues into the older history.
+func LoadBootstrap(path string, since, now time.Time) (*BootstrapState, error) {
+	data, err := statestore.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &BootstrapState{Since: since, Top: now, Cursor: now}, nil
+		}
+
+		return nil, fmt.Errorf("failed to read bootstrap state: %w", err)
+	}
+
+	var state BootstrapState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse bootstrap state: %w", err)
+	}
+
+	state.Since = since
+
+	return &state, nil
+}
+
+// Save writes the progress to path.
+func (s *BootstrapState) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode bootstrap state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create bootstrap state directory: %w", err)
+	}
+
+	if err := statestore.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write bootstrap state: %w", err)
+	}
+
+	return nil
+}
+
+// Done reports whether the history back to Since is imported.
+func (s *BootstrapState) Done() bool {
+	return !s.Cursor.After(s.Since)
+}
+
+// Next returns the window of the next chunk: from start up to, but excluding, end, limited to limit items
+// (0 for no limit).
+func (s *BootstrapState) Next(chunk BootstrapChunk) (start, end time.Time, limit int) {
+	if chunk.Messages > 0 {
+		return s.Since, s.Cursor, chunk.Messages
+	}
+
+	last := s.Cursor.Add(-time.Nanosecond)
+	start = time.Date(last.Year(), last.Month(), 1, 0, 0, 0, 0, last.Location())
+
+	if start.Before(s.Since) {
+		start = s.Since
+	}
+
+	return start, s.Cursor, 0
+}
+
+// Advance records an imported chunk that started at start and took took. A month chunk moves the cursor to
+// its start. A message chunk moves it to its oldest item, or to start once a chunk comes back empty.
+func (s *BootstrapState) Advance(chunk BootstrapChunk, start time.Time, items []models.ItemInterface,
+	took time.Duration,
+) {
+	s.Chunks++
+	s.Items += len(items)
+	s.Elapsed += took
+
+	if chunk.Messages == 0 || len(items) == 0 {
+		s.Cursor = start
+
+		return
+	}
+
+	oldest := s.Cursor
+	for _, item := range items {
+		if created := item.GetCreatedAt(); !created.IsZero() && created.Before(oldest) {
+			oldest = created
+		}
+	}
+
+	// Messages sharing the oldest second are fetched again rather than skipped, unless the cursor would
+	// not move
+	next := oldest.Truncate(time.Second).Add(time.Second)
+	if !next.Before(s.Cursor) {
+		next = oldest.Truncate(time.Second)
+	}
+
+	if !next.Before(s.Cursor) {
+		next = s.Cursor.Add(-time.Second)
+	}
+
+	if next.Before(s.Since) {
+		next = s.Since
+	}
+
+	s.Cursor = next
+}
+
+// Progress returns the share of the history between Since and Top that is imported, from 0 to 1.
+func (s *BootstrapState) Progress() float64 {
+	span := s.Top.Sub(s.Since)
+	if span <= 0 || s.Done() {
+		return 1
+	}
+
+	progress := float64(s.Top.Sub(s.Cursor)) / float64(span)
+
+	return min(max(progress, 0), 1)
+}
+
+// ETA estimates the time left from the pace so far; it is 0 until a chunk was imported.
+func (s *BootstrapState) ETA() time.Duration {
+	progress := s.Progress()
+	if progress <= 0 || progress >= 1 {
+		return 0
+	}
+
+	return time.Duration(float64(s.Elapsed) * (1 - progress) / progress)
+}
+
+// Status renders a progress bar with the import position and ETA, e.g.
+// "[#########---------------------]  30% back to 2023-04-01, 5120 items in 12 chunks, ETA 21m0s".
+func (s *BootstrapState) Status() string {
+	progress := s.Progress()
+	filled := int(progress * bootstrapBarWidth)
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", bootstrapBarWidth-filled)
+
+	status := fmt.Sprintf("[%s] %3.0f%% back to %s, %d items in %d chunks", bar, progress*100,
+		s.Cursor.Format("2006-01-02"), s.Items, s.Chunks)
+
+	if eta := s.ETA(); eta > 0 {
+		status += ", ETA " + eta.Round(time.Second).String()
+	}
+
+	return status
+}
@@ -0,0 +1,129 @@
+package sync
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func bootstrapItem(id string, created time.Time) models.ItemInterface {
+	item := models.NewBasicItem(id, id)
+	item.SetCreatedAt(created)
+
+	return item
+}
+
+func TestBootstrapByMonth(t *testing.T) {
+	since := time.Date(2024, 11, 15, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), BootstrapStateFile("gmail_personal"))
+
+	state, err := LoadBootstrap(path, since, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var windows []string
+
+	for !state.Done() {
+		start, end, limit := state.Next(BootstrapChunk{})
+		if limit != 0 {
+			t.Fatalf("month chunk limit = %d, want none", limit)
+		}
+
+		windows = append(windows, start.Format("2006-01-02")+".."+end.Format("2006-01-02"))
+		state.Advance(BootstrapChunk{}, start, []models.ItemInterface{bootstrapItem("m", start)}, time.Minute)
+
+		// Progress survives restarts
+		if err := state.Save(path); err != nil {
+			t.Fatal(err)
+		}
+
+		if state, err = LoadBootstrap(path, since, now.Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{"2025-01-01..2025-01-10", "2024-12-01..2025-01-01", "2024-11-15..2024-12-01"}
+	if strings.Join(windows, " ") != strings.Join(want, " ") {
+		t.Errorf("windows = %v, want %v", windows, want)
+	}
+
+	if state.Items != 3 || state.Chunks != 3 || !state.Top.Equal(now) || state.Progress() != 1 {
+		t.Errorf("state = %+v, progress %v", state, state.Progress())
+	}
+
+	// An earlier since continues into the older history
+	if state, err = LoadBootstrap(path, since.AddDate(0, -1, 0), now); err != nil {
+		t.Fatal(err)
+	}
+
+	if start, end, _ := state.Next(BootstrapChunk{}); state.Done() ||
+		!start.Equal(time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)) || !end.Equal(since) {
+		t.Errorf("Next() after extending since = %v..%v", start, end)
+	}
+}
+
+func TestBootstrapByMessages(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC)
+	chunk := BootstrapChunk{Messages: 2}
+
+	state, err := LoadBootstrap(filepath.Join(t.TempDir(), "state.json"), since, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start, end, limit := state.Next(chunk)
+	if !start.Equal(since) || !end.Equal(now) || limit != 2 {
+		t.Fatalf("Next() = %v..%v limit %d", start, end, limit)
+	}
+
+	oldest := time.Date(2024, 1, 6, 9, 30, 15, 500, time.UTC)
+	state.Advance(chunk, start, []models.ItemInterface{
+		bootstrapItem("new", now.Add(-time.Hour)),
+		bootstrapItem("old", oldest),
+	}, 10*time.Second)
+
+	// The cursor keeps the oldest second so messages sharing it are not skipped
+	if want := time.Date(2024, 1, 6, 9, 30, 16, 0, time.UTC); !state.Cursor.Equal(want) {
+		t.Errorf("Cursor = %v, want %v", state.Cursor, want)
+	}
+
+	// 4.6 of 10 days took 10s
+	if progress := state.Progress(); progress < 0.45 || progress > 0.47 {
+		t.Errorf("Progress() = %v, want 0.46", progress)
+	}
+
+	if status := state.Status(); !strings.Contains(status, "[#############-----------------]  46%") ||
+		!strings.Contains(status, "back to 2024-01-06, 2 items in 1 chunks, ETA 12s") {
+		t.Errorf("Status() = %q", status)
+	}
+
+	// An empty chunk ends the bootstrap
+	start, _, _ = state.Next(chunk)
+	state.Advance(chunk, start, nil, time.Second)
+
+	if !state.Done() || state.ETA() != 0 {
+		t.Errorf("state after empty chunk = %+v", state)
+	}
+}
+
+func TestParseBootstrapChunk(t *testing.T) {
+	if chunk, err := ParseBootstrapChunk("month"); err != nil || chunk.Messages != 0 {
+		t.Errorf("ParseBootstrapChunk(month) = %+v, %v", chunk, err)
+	}
+
+	if chunk, err := ParseBootstrapChunk("500"); err != nil || chunk.Messages != 500 || chunk.String() != "500 messages" {
+		t.Errorf("ParseBootstrapChunk(500) = %+v, %v", chunk, err)
+	}
+
+	for _, invalid := range []string{"week", "0", "-5"} {
+		if _, err := ParseBootstrapChunk(invalid); err == nil {
+			t.Errorf("ParseBootstrapChunk(%q) accepted", invalid)
+		}
+	}
+}
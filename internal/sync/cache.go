@@ -0,0 +1,145 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"pkm-sync/internal/statestore"
+	"pkm-sync/pkg/models"
+)
+
+// ItemCacheDir is the directory in the config directory holding the fetched items of each source.
+const ItemCacheDir = "cache"
+
+// ItemCacheFile returns the path of a source's item cache relative to the config directory.
+func ItemCacheFile(source string) string {
+	return filepath.Join(ItemCacheDir, source+".json")
+}
+
+// itemCache is the stored form of a cache, the item document `--dry-run --format json` and the ingest
+// source also use.
+type itemCache struct {
+	SchemaVersion int               `json:"schema_version"`
+	Items         []json.RawMessage `json:"items"`
+}
+
+// LoadCachedItems reads the items of a source's cache, upgraded to the current schema version. A missing
+// cache is returned empty.
+func LoadCachedItems(path string) ([]models.ItemInterface, error) {
+	data, err := statestore.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read item cache: %w", err)
+	}
+
+	var cache itemCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("failed to parse item cache: %w", err)
+	}
+
+	items := make([]models.ItemInterface, 0, len(cache.Items))
+
+	for i, raw := range cache.Items {
+		upgraded, err := models.UpgradeItemJSON(raw, cache.SchemaVersion)
+		if err != nil {
+			return nil, fmt.Errorf("cached item %d: %w", i+1, err)
+		}
+
+		item, err := decodeCachedItem(upgraded)
+		if err != nil {
+			return nil, fmt.Errorf("cached item %d: %w", i+1, err)
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// CacheItems adds fetched items to a source's cache, replacing the cached versions of items fetched again.
+// The cache is kept in creation order.
+func CacheItems(path string, items []models.ItemInterface) error {
+	cached, err := LoadCachedItems(path)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[string]int, len(cached))
+	for i, item := range cached {
+		byID[item.GetID()] = i
+	}
+
+	for _, item := range items {
+		if i, ok := byID[item.GetID()]; ok {
+			cached[i] = item
+
+			continue
+		}
+
+		byID[item.GetID()] = len(cached)
+		cached = append(cached, item)
+	}
+
+	sort.SliceStable(cached, func(i, j int) bool {
+		return cached[i].GetCreatedAt().Before(cached[j].GetCreatedAt())
+	})
+
+	cache := itemCache{SchemaVersion: models.ItemSchemaVersion, Items: make([]json.RawMessage, 0, len(cached))}
+
+	for _, item := range cached {
+		raw, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("failed to encode cached item '%s': %w", item.GetID(), err)
+		}
+
+		cache.Items = append(cache.Items, raw)
+	}
+
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return fmt.Errorf("failed to encode item cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create item cache directory: %w", err)
+	}
+
+	if err := statestore.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write item cache: %w", err)
+	}
+
+	return nil
+}
+
+// decodeCachedItem decodes one cached item; items with messages are threads.
+func decodeCachedItem(raw json.RawMessage) (models.ItemInterface, error) {
+	var probe struct {
+		Messages []json.RawMessage `json:"messages"`
+	}
+
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, err
+	}
+
+	if len(probe.Messages) > 0 {
+		thread := &models.Thread{}
+		if err := json.Unmarshal(raw, thread); err != nil {
+			return nil, err
+		}
+
+		return thread, nil
+	}
+
+	item := &models.BasicItem{}
+	if err := json.Unmarshal(raw, item); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
@@ -0,0 +1,49 @@
+package sync
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestCacheItems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ItemCacheFile("gmail_work"))
+
+	if items, err := LoadCachedItems(path); err != nil || len(items) != 0 {
+		t.Fatalf("LoadCachedItems() of a missing cache = %v, %v", items, err)
+	}
+
+	older := models.NewBasicItem("older", "Older")
+	older.SetCreatedAt(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	newer := models.NewBasicItem("newer", "Newer")
+	newer.SetCreatedAt(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))
+
+	if err := CacheItems(path, []models.ItemInterface{newer, older}); err != nil {
+		t.Fatalf("CacheItems() error = %v", err)
+	}
+
+	thread := models.NewThread("older", "Older, replied")
+	thread.SetCreatedAt(older.GetCreatedAt())
+	thread.AddMessage(models.NewBasicItem("reply", "Re: Older"))
+
+	if err := CacheItems(path, []models.ItemInterface{thread}); err != nil {
+		t.Fatalf("CacheItems() error = %v", err)
+	}
+
+	items, err := LoadCachedItems(path)
+	if err != nil {
+		t.Fatalf("LoadCachedItems() error = %v", err)
+	}
+
+	if len(items) != 2 || items[0].GetID() != "older" || items[1].GetID() != "newer" {
+		t.Fatalf("LoadCachedItems() = %d items, want older then newer", len(items))
+	}
+
+	cached, ok := models.AsThread(items[0])
+	if !ok || items[0].GetTitle() != "Older, replied" || len(cached.GetMessages()) != 1 {
+		t.Errorf("refetched item was not replaced by its thread: %+v", items[0])
+	}
+}
@@ -0,0 +1,168 @@
+package sync
+
+import (
+	"fmt"
+	"strings"
+
+	"pkm-sync/pkg/models"
+)
+
+// isContactGroup reports whether a contact entry names a group of the contacts block rather than being an
+// address, a domain or a display name: group names have neither "@", "." nor spaces.
+func isContactGroup(entry string) bool {
+	return !strings.ContainsAny(entry, "@. ")
+}
+
+// ValidateContacts checks the groups of the contacts block: every group needs entries, and entries are
+// addresses, domains or names rather than other groups.
+func ValidateContacts(groups map[string][]string) error {
+	for name, entries := range groups {
+		if !isContactGroup(name) {
+			return fmt.Errorf("invalid contact group name '%s': names must not contain '@', '.' or spaces", name)
+		}
+
+		if len(entries) == 0 {
+			return fmt.Errorf("contact group '%s' has no contacts", name)
+		}
+
+		for _, entry := range entries {
+			if strings.TrimSpace(entry) == "" || isContactGroup(strings.TrimSpace(entry)) {
+				return fmt.Errorf("contact group '%s': invalid contact '%s': contacts are addresses "+
+					"(alice@company.com), domains (@company.com) or names (Alice Smith)", name, entry)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ResolveContacts expands the groups named in a source's allow_contacts or deny_contacts into their
+// contacts; other entries are kept as they are.
+func ResolveContacts(groups map[string][]string, entries []string) ([]string, error) {
+	var resolved []string
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+
+		if entry == "" {
+			return nil, fmt.Errorf("contact lists must not contain empty entries")
+		}
+
+		if !isContactGroup(entry) {
+			resolved = append(resolved, entry)
+
+			continue
+		}
+
+		contacts, ok := groups[entry]
+		if !ok {
+			return nil, fmt.Errorf("unknown contact group '%s'", entry)
+		}
+
+		resolved = append(resolved, contacts...)
+	}
+
+	return resolved, nil
+}
+
+// FilterContacts keeps the items with at least one contact in allow (all items when allow is empty) and
+// drops those sent or organized by a contact in deny. An item's contacts are its sender, recipients,
+// organizer, attendees and participants, including those of a thread's messages. Items without contacts
+// are kept unless there is an allow list.
+func FilterContacts(items []models.ItemInterface, allow, deny []string) []models.ItemInterface {
+	if len(allow) == 0 && len(deny) == 0 {
+		return items
+	}
+
+	kept := make([]models.ItemInterface, 0, len(items))
+
+	for _, item := range items {
+		senders, contacts := itemContacts(item)
+
+		if len(allow) > 0 && !anyContactMatches(contacts, allow) {
+			continue
+		}
+
+		if anyContactMatches(senders, deny) {
+			continue
+		}
+
+		kept = append(kept, item)
+	}
+
+	return kept
+}
+
+// itemContacts returns the senders and organizers of an item and its messages, and all of their contacts.
+func itemContacts(item models.ItemInterface) ([]models.Recipient, []models.Recipient) {
+	var senders, contacts []models.Recipient
+
+	add := func(metadata models.Metadata) {
+		for _, key := range []string{models.MetadataFrom, models.MetadataOrganizer} {
+			if sender, ok := metadata.GetRecipient(key); ok {
+				senders = append(senders, sender)
+				contacts = append(contacts, sender)
+			}
+		}
+
+		for _, key := range []string{models.MetadataTo, models.MetadataCC, models.MetadataBCC, models.MetadataReplyTo} {
+			if recipients, ok := metadata.GetRecipients(key); ok {
+				contacts = append(contacts, recipients...)
+			}
+		}
+
+		if attendees, ok := metadata.GetAttendees(models.MetadataAttendees); ok {
+			for _, attendee := range attendees {
+				contacts = append(contacts, models.Recipient{Name: attendee.DisplayName, Email: attendee.Email})
+			}
+		}
+
+		if participants, ok := metadata.GetRecipients(models.MetadataParticipants); ok {
+			contacts = append(contacts, participants...)
+		}
+	}
+
+	add(item.GetMetadata())
+
+	if thread, ok := models.AsThread(item); ok {
+		for _, message := range thread.GetMessages() {
+			add(message.GetMetadata())
+		}
+	}
+
+	return senders, contacts
+}
+
+// anyContactMatches reports whether one of the contacts matches an entry: an address matches itself, a
+// domain ("@company.com" or "company.com") every address at it, and any other entry a display name.
+func anyContactMatches(contacts []models.Recipient, entries []string) bool {
+	for _, contact := range contacts {
+		email := strings.ToLower(strings.TrimSpace(contact.Email))
+		name := strings.TrimSpace(contact.Name)
+
+		for _, entry := range entries {
+			entry = strings.ToLower(strings.TrimSpace(entry))
+
+			switch {
+			case strings.HasPrefix(entry, "@"):
+				if email != "" && strings.HasSuffix(email, entry) {
+					return true
+				}
+			case strings.Contains(entry, "@"):
+				if email == entry {
+					return true
+				}
+			case strings.Contains(entry, ".") && !strings.Contains(entry, " "):
+				if email != "" && strings.HasSuffix(email, "@"+entry) {
+					return true
+				}
+			default:
+				if strings.EqualFold(name, entry) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,96 @@
+package sync
+
+import (
+	"slices"
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func contactItem(id string, metadata map[string]interface{}) models.ItemInterface {
+	item := models.NewBasicItem(id, id)
+	item.SetMetadata(metadata)
+
+	return item
+}
+
+func TestResolveContacts(t *testing.T) {
+	groups := map[string][]string{
+		"team":   {"alice@company.com", "@company.com"},
+		"family": {"mom@example.com"},
+	}
+
+	got, err := ResolveContacts(groups, []string{"team", "bob@partner.com", "Carol Jones"})
+	if err != nil {
+		t.Fatalf("ResolveContacts() error = %v", err)
+	}
+
+	if want := []string{"alice@company.com", "@company.com", "bob@partner.com", "Carol Jones"}; !slices.Equal(got, want) {
+		t.Errorf("ResolveContacts() = %v, want %v", got, want)
+	}
+
+	if _, err := ResolveContacts(groups, []string{"friends"}); err == nil {
+		t.Error("ResolveContacts() accepted an unknown group")
+	}
+
+	if err := ValidateContacts(map[string][]string{"team": {"family"}}); err == nil {
+		t.Error("ValidateContacts() accepted a group listing another group")
+	}
+
+	if err := ValidateContacts(groups); err != nil {
+		t.Errorf("ValidateContacts() error = %v", err)
+	}
+}
+
+func TestFilterContacts(t *testing.T) {
+	thread := models.NewThread("thread", "Re: plans")
+	thread.AddMessage(contactItem("reply", map[string]interface{}{
+		models.MetadataFrom: models.Recipient{Name: "Mom", Email: "mom@example.com"},
+	}))
+
+	items := []models.ItemInterface{
+		contactItem("email", map[string]interface{}{
+			models.MetadataFrom: models.Recipient{Email: "boss@company.com"},
+			models.MetadataTo:   []models.Recipient{{Email: "me@example.com"}},
+		}),
+		contactItem("event", map[string]interface{}{
+			models.MetadataOrganizer: models.Attendee{Email: "vendor@sales.example"},
+			models.MetadataAttendees: []models.Attendee{{Email: "alice@company.com"}},
+		}),
+		contactItem("chat", map[string]interface{}{models.MetadataFrom: "Carol Jones"}),
+		contactItem("note", nil),
+		thread,
+	}
+
+	ids := func(items []models.ItemInterface) []string {
+		var ids []string
+		for _, item := range items {
+			ids = append(ids, item.GetID())
+		}
+
+		return ids
+	}
+
+	tests := []struct {
+		name  string
+		allow []string
+		deny  []string
+		want  []string
+	}{
+		{"no lists", nil, nil, []string{"email", "event", "chat", "note", "thread"}},
+		{"allow domain", []string{"@company.com"}, nil, []string{"email", "event"}},
+		{"allow bare domain and name", []string{"example.com", "carol jones"}, nil, []string{"email", "chat", "thread"}},
+		{"allow message sender", []string{"mom@example.com"}, nil, []string{"thread"}},
+		{"deny organizer only", nil, []string{"vendor@sales.example", "alice@company.com"}, []string{"email", "chat", "note", "thread"}},
+		{"deny message sender", nil, []string{"mom@example.com"}, []string{"email", "event", "chat", "note"}},
+		{"allow and deny", []string{"@company.com"}, []string{"@sales.example"}, []string{"email"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(FilterContacts(items, tt.allow, tt.deny)); !slices.Equal(got, tt.want) {
+				t.Errorf("FilterContacts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
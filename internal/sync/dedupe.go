@@ -0,0 +1,239 @@
+package sync
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"pkm-sync/pkg/models"
+	"pkm-sync/pkg/query"
+)
+
+// Deduplication strategies for sync.deduplicate_by.
+const (
+	DedupeByID      = "id"
+	DedupeByTitle   = "title"
+	DedupeByContent = "content"
+	DedupeNone      = "none"
+)
+
+// ValidateDedupe checks the deduplicate_by strategy and the deduplicate_where query.
+func ValidateDedupe(by, where string) error {
+	switch by {
+	case "", DedupeByID, DedupeByTitle, DedupeByContent, DedupeNone:
+	default:
+		return fmt.Errorf("unsupported deduplicate_by '%s': supported strategies are 'id', 'title', 'content', 'none'", by)
+	}
+
+	if where != "" {
+		if _, err := query.Parse(where); err != nil {
+			return fmt.Errorf("invalid deduplicate_where: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// DeduplicateItems drops items with the same ID, title or content as an earlier item, keeping the first.
+// When where is set, only items matching it are deduplicated; others are always kept.
+func DeduplicateItems(items []models.ItemInterface, by, where string) ([]models.ItemInterface, error) {
+	if by == "" || by == DedupeNone {
+		return items, nil
+	}
+
+	if err := ValidateDedupe(by, where); err != nil {
+		return nil, err
+	}
+
+	var predicate *query.Query
+	if where != "" {
+		predicate, _ = query.Parse(where)
+	}
+
+	seen := make(map[string]bool)
+	kept := make([]models.ItemInterface, 0, len(items))
+
+	for _, item := range items {
+		if predicate != nil && !predicate.MatchItem(item) {
+			kept = append(kept, item)
+
+			continue
+		}
+
+		key := dedupeKey(item, by)
+		if key != "" && seen[key] {
+			continue
+		}
+
+		seen[key] = true
+
+		kept = append(kept, item)
+	}
+
+	return kept, nil
+}
+
+// dedupeKey returns what two items must share to count as duplicates; titles and content are compared
+// ignoring case and surrounding whitespace.
+func dedupeKey(item models.ItemInterface, by string) string {
+	switch by {
+	case DedupeByTitle:
+		return strings.ToLower(strings.TrimSpace(item.GetTitle()))
+	case DedupeByContent:
+		return strings.ToLower(strings.TrimSpace(item.GetContent()))
+	default:
+		return item.GetID()
+	}
+}
+
+// MergeThreads merges the threads a conversation has in several email accounts. Items sharing a Message-ID,
+// as their own, one of their thread's messages or one they reply to or reference, belong to one
+// conversation and are given the thread ID of its first item, so thread grouping puts them together. An
+// item whose messages all are already among those of a kept item is a copy from another account and is
+// dropped; the item with the most messages of a conversation is kept first.
+func MergeThreads(items []models.ItemInterface) []models.ItemInterface {
+	// Union-find over the items, joined through the Message-IDs they share
+	parent := make([]int, len(items))
+	for i := range parent {
+		parent[i] = i
+	}
+
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+
+		return parent[i]
+	}
+
+	own := make([][]string, len(items))
+	owners := make(map[string]int)
+
+	for i, item := range items {
+		var linked []string
+
+		own[i], linked = threadMessageIDs(item)
+		for _, id := range slices.Concat(own[i], linked) {
+			if j, ok := owners[id]; ok {
+				parent[find(i)] = find(j)
+			} else {
+				owners[id] = i
+			}
+		}
+	}
+
+	// Copies are found largest first, so a thread is kept over the single messages it contains
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		return len(own[order[a]]) > len(own[order[b]])
+	})
+
+	kept := make(map[string]bool)
+	dropped := make([]bool, len(items))
+
+	for _, i := range order {
+		if len(own[i]) > 0 && !slices.ContainsFunc(own[i], func(id string) bool { return !kept[id] }) {
+			dropped[i] = true
+
+			continue
+		}
+
+		for _, id := range own[i] {
+			kept[id] = true
+		}
+	}
+
+	// Each conversation takes the thread ID of its first item that has one
+	threadIDs := make(map[int]string)
+
+	for i, item := range items {
+		root := find(i)
+		if _, ok := threadIDs[root]; ok {
+			continue
+		}
+
+		if threadID, _ := models.Metadata(item.GetMetadata()).GetString(models.MetadataThreadID); threadID != "" {
+			threadIDs[root] = threadID
+		}
+	}
+
+	merged := make([]models.ItemInterface, 0, len(items))
+
+	for i, item := range items {
+		if dropped[i] {
+			continue
+		}
+
+		threadID := threadIDs[find(i)]
+		if current, _ := models.Metadata(item.GetMetadata()).GetString(models.MetadataThreadID); threadID != "" &&
+			current != "" && current != threadID {
+			metadata := make(map[string]interface{}, len(item.GetMetadata()))
+			for key, value := range item.GetMetadata() {
+				metadata[key] = value
+			}
+
+			metadata[models.MetadataThreadID] = threadID
+			item.SetMetadata(metadata)
+		}
+
+		merged = append(merged, item)
+	}
+
+	return merged
+}
+
+// threadMessageIDs returns the Message-IDs of an item's own messages, its own or those of a thread, and of
+// the messages it replies to or references. Only addresses with an "@" count, leaving out the message IDs
+// of chat sources.
+func threadMessageIDs(item models.ItemInterface) ([]string, []string) {
+	metadata := models.Metadata(item.GetMetadata())
+
+	var own, linked []string
+
+	add := func(ids *[]string, id string) {
+		if id = strings.Trim(id, "<> "); strings.Contains(id, "@") && !slices.Contains(*ids, id) {
+			*ids = append(*ids, id)
+		}
+	}
+
+	if id, ok := metadata.GetString(models.MetadataMessageID); ok {
+		add(&own, id)
+	}
+
+	if ids, ok := metadata.GetStrings(models.MetadataMessageIDs); ok {
+		for _, id := range ids {
+			add(&own, id)
+		}
+	}
+
+	if thread, ok := models.AsThread(item); ok {
+		for _, message := range thread.GetMessages() {
+			messageOwn, messageLinked := threadMessageIDs(message)
+			for _, id := range messageOwn {
+				add(&own, id)
+			}
+
+			for _, id := range messageLinked {
+				add(&linked, id)
+			}
+		}
+	}
+
+	if id, ok := metadata.GetString(models.MetadataInReplyTo); ok {
+		add(&linked, id)
+	}
+
+	if ids, ok := metadata.GetStrings(models.MetadataReferences); ok {
+		for _, id := range ids {
+			add(&linked, id)
+		}
+	}
+
+	return own, linked
+}
@@ -0,0 +1,135 @@
+package sync
+
+import (
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func dedupeTestItems() []models.ItemInterface {
+	newItem := func(id, title, sourceType string) models.ItemInterface {
+		item := models.NewBasicItem(id, title)
+		item.SetSourceType(sourceType)
+
+		return item
+	}
+
+	return []models.ItemInterface{
+		newItem("1", "Weekly report", "gmail"),
+		newItem("1", "Weekly report (copy)", "gmail"),
+		newItem("2", " weekly REPORT ", "gmail"),
+		newItem("3", "Weekly report", "google_calendar"),
+	}
+}
+
+func itemIDs(items []models.ItemInterface) string {
+	ids := ""
+	for _, item := range items {
+		ids += item.GetID()
+	}
+
+	return ids
+}
+
+func TestDeduplicateItems(t *testing.T) {
+	tests := []struct {
+		by    string
+		where string
+		want  string
+	}{
+		{by: "", want: "1123"},
+		{by: DedupeNone, want: "1123"},
+		{by: DedupeByID, want: "123"},
+		{by: DedupeByTitle, want: "11"},
+		{by: DedupeByTitle, where: "source = gmail", want: "113"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.by+" "+tt.where, func(t *testing.T) {
+			got, err := DeduplicateItems(dedupeTestItems(), tt.by, tt.where)
+			if err != nil {
+				t.Fatalf("DeduplicateItems() error = %v", err)
+			}
+
+			if ids := itemIDs(got); ids != tt.want {
+				t.Errorf("DeduplicateItems() kept %q, want %q", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateDedupe(t *testing.T) {
+	if err := ValidateDedupe(DedupeByContent, "has(attachment)"); err != nil {
+		t.Errorf("ValidateDedupe() error = %v", err)
+	}
+
+	if err := ValidateDedupe("subject", ""); err == nil {
+		t.Error("ValidateDedupe() expected error for unsupported strategy")
+	}
+
+	if err := ValidateDedupe(DedupeByID, "tag = ("); err == nil {
+		t.Error("ValidateDedupe() expected error for invalid query")
+	}
+}
+
+func TestMergeThreads(t *testing.T) {
+	message := func(id, threadID, messageID string, metadata map[string]interface{}) models.ItemInterface {
+		item := models.NewBasicItem(id, "Budget")
+		item.SetSourceType("gmail")
+
+		if metadata == nil {
+			metadata = make(map[string]interface{})
+		}
+
+		metadata[models.MetadataThreadID] = threadID
+		metadata[models.MetadataMessageID] = messageID
+		item.SetMetadata(metadata)
+
+		return item
+	}
+
+	// The same conversation in a work and a personal account, which joined it at the second message
+	items := []models.ItemInterface{
+		message("w1", "work-thread", "<a@mail.example>", nil),
+		message("w2", "work-thread", "<b@mail.example>",
+			map[string]interface{}{models.MetadataInReplyTo: "a@mail.example"}),
+		message("p2", "personal-thread", "<b@mail.example>",
+			map[string]interface{}{models.MetadataInReplyTo: "a@mail.example"}),
+		message("p3", "personal-thread", "<c@mail.example>",
+			map[string]interface{}{models.MetadataReferences: []string{"a@mail.example", "b@mail.example"}}),
+		message("other", "other-thread", "<z@mail.example>", nil),
+		message("chat", "chat-thread", "1690000000000", nil),
+	}
+
+	merged := MergeThreads(items)
+
+	if ids := itemIDs(merged); ids != "w1w2p3otherchat" {
+		t.Fatalf("MergeThreads() kept %q, want the personal copy of b dropped", ids)
+	}
+
+	if threadID := merged[2].GetMetadata()[models.MetadataThreadID]; threadID != "work-thread" {
+		t.Errorf("p3 thread_id = %v, want work-thread", threadID)
+	}
+
+	if threadID := merged[3].GetMetadata()[models.MetadataThreadID]; threadID != "other-thread" {
+		t.Errorf("other thread_id = %v, want it unchanged", threadID)
+	}
+}
+
+func TestMergeThreadsKeepsLargerThread(t *testing.T) {
+	thread := models.NewBasicItem("thread_work", "Thread_Budget_2-messages")
+	thread.SetMetadata(map[string]interface{}{
+		models.MetadataThreadID:   "work-thread",
+		models.MetadataMessageIDs: []string{"a@mail.example", "b@mail.example"},
+	})
+
+	single := models.NewBasicItem("p2", "Re: Budget")
+	single.SetMetadata(map[string]interface{}{
+		models.MetadataThreadID:  "personal-thread",
+		models.MetadataMessageID: "<b@mail.example>",
+	})
+
+	if ids := itemIDs(MergeThreads([]models.ItemInterface{single, thread})); ids != "thread_work" {
+		t.Errorf("MergeThreads() kept %q, want only the thread", ids)
+	}
+}
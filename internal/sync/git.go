@@ -0,0 +1,148 @@
+package sync
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"pkm-sync/pkg/models"
+)
+
+// Identity used for commits when git has no user configured, so unattended syncs can still commit.
+const (
+	gitFallbackName  = "pkm-sync"
+	gitFallbackEmail = "pkm-sync@localhost"
+)
+
+// CommitOutput commits the changes below outputDir to git after a sync, creating the repository when
+// outputDir is not inside one. Only paths below outputDir are staged and committed. Returns false when
+// there was nothing to commit.
+func CommitOutput(outputDir string, gitConfig models.GitConfig, sourceCounts map[string]int) (bool, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return false, fmt.Errorf("git is not installed: %w", err)
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return false, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if _, err := runGit(outputDir, "rev-parse", "--is-inside-work-tree"); err != nil {
+		if _, err := runGit(outputDir, "init"); err != nil {
+			return false, err
+		}
+	}
+
+	if _, err := runGit(outputDir, "add", "--all", "--", "."); err != nil {
+		return false, err
+	}
+
+	status, err := runGit(outputDir, "status", "--porcelain", "--", ".")
+	if err != nil {
+		return false, err
+	}
+
+	if strings.TrimSpace(status) == "" {
+		return false, nil
+	}
+
+	subject, body := CommitMessage(sourceCounts)
+
+	args := []string{"commit", "--quiet", "-m", subject}
+	if body != "" {
+		args = append(args, "-m", body)
+	}
+
+	args = append(args, "--", ".")
+
+	var env []string
+	if email, _ := runGit(outputDir, "config", "user.email"); strings.TrimSpace(email) == "" {
+		env = []string{
+			"GIT_AUTHOR_NAME=" + gitFallbackName, "GIT_AUTHOR_EMAIL=" + gitFallbackEmail,
+			"GIT_COMMITTER_NAME=" + gitFallbackName, "GIT_COMMITTER_EMAIL=" + gitFallbackEmail,
+		}
+	}
+
+	if _, err := runGitWithEnv(outputDir, env, args...); err != nil {
+		return false, err
+	}
+
+	if gitConfig.Push {
+		pushArgs := []string{"push"}
+		if gitConfig.Remote != "" {
+			pushArgs = append(pushArgs, gitConfig.Remote, "HEAD")
+		}
+
+		if _, err := runGit(outputDir, pushArgs...); err != nil {
+			return true, err
+		}
+	}
+
+	return true, nil
+}
+
+// CommitMessage builds the subject and body of a sync commit from the number of items synced per source,
+// e.g. "Sync 12 items from 2 sources" with one "gmail_work: 10 items" line per source.
+func CommitMessage(sourceCounts map[string]int) (string, string) {
+	sources := make([]string, 0, len(sourceCounts))
+	total := 0
+
+	for source, count := range sourceCounts {
+		sources = append(sources, source)
+		total += count
+	}
+
+	sort.Strings(sources)
+
+	subject := "Sync " + pluralize(total, "item")
+
+	switch len(sources) {
+	case 0:
+		return subject, ""
+	case 1:
+		return subject + " from " + sources[0], ""
+	}
+
+	subject += " from " + pluralize(len(sources), "source")
+
+	lines := make([]string, 0, len(sources))
+	for _, source := range sources {
+		lines = append(lines, fmt.Sprintf("%s: %s", source, pluralize(sourceCounts[source], "item")))
+	}
+
+	return subject, strings.Join(lines, "\n")
+}
+
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// runGit runs a git command in dir and returns its output; failures include git's error output.
+func runGit(dir string, args ...string) (string, error) {
+	return runGitWithEnv(dir, nil, args...)
+}
+
+// runGitWithEnv runs a git command with extra environment variables.
+func runGitWithEnv(dir string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return stdout.String(), fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
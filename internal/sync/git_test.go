@@ -0,0 +1,86 @@
+package sync
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestCommitMessage(t *testing.T) {
+	tests := []struct {
+		name        string
+		counts      map[string]int
+		wantSubject string
+		wantBody    string
+	}{
+		{name: "no sources", counts: nil, wantSubject: "Sync 0 items"},
+		{name: "one source", counts: map[string]int{"gmail_work": 1}, wantSubject: "Sync 1 item from gmail_work"},
+		{
+			name:        "several sources",
+			counts:      map[string]int{"gmail_work": 10, "gmail_personal": 2},
+			wantSubject: "Sync 12 items from 2 sources",
+			wantBody:    "gmail_personal: 2 items\ngmail_work: 10 items",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject, body := CommitMessage(tt.counts)
+			if subject != tt.wantSubject {
+				t.Errorf("subject = %q, want %q", subject, tt.wantSubject)
+			}
+
+			if body != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestCommitOutput(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	outputDir := filepath.Join(t.TempDir(), "vault")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(outputDir, "note.md"), []byte("# Note\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	counts := map[string]int{"gmail_work": 1}
+
+	committed, err := CommitOutput(outputDir, models.GitConfig{AutoCommit: true}, counts)
+	if err != nil {
+		t.Fatalf("CommitOutput() error = %v", err)
+	}
+
+	if !committed {
+		t.Fatal("CommitOutput() should commit the new note")
+	}
+
+	log, err := runGit(outputDir, "log", "--format=%s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.TrimSpace(log); got != "Sync 1 item from gmail_work" {
+		t.Errorf("commit subject = %q", got)
+	}
+
+	committed, err = CommitOutput(outputDir, models.GitConfig{AutoCommit: true}, counts)
+	if err != nil {
+		t.Fatalf("CommitOutput() error = %v", err)
+	}
+
+	if committed {
+		t.Error("CommitOutput() should not commit when nothing changed")
+	}
+}
@@ -0,0 +1,84 @@
+package sync
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"pkm-sync/pkg/models"
+)
+
+// Hook events; the running event is passed to hook commands as PKM_SYNC_HOOK.
+const (
+	HookPreSync  = "pre_sync"
+	HookPostSync = "post_sync"
+	HookOnError  = "on_error"
+)
+
+// HookRun describes the sync run a hook command is called for.
+type HookRun struct {
+	Sources   []string
+	Target    string
+	OutputDir string
+	ItemCount int   // Items synced; set for post_sync
+	Err       error // Failure; set for on_error
+}
+
+// Env returns the PKM_SYNC_* environment variables describing the run to a hook command.
+func (r HookRun) Env(event string) []string {
+	env := []string{
+		"PKM_SYNC_HOOK=" + event,
+		"PKM_SYNC_SOURCES=" + strings.Join(r.Sources, ","),
+		"PKM_SYNC_TARGET=" + r.Target,
+		"PKM_SYNC_OUTPUT_DIR=" + r.OutputDir,
+		"PKM_SYNC_ITEM_COUNT=" + strconv.Itoa(r.ItemCount),
+	}
+
+	if r.Err != nil {
+		env = append(env, "PKM_SYNC_ERROR="+r.Err.Error())
+	}
+
+	return env
+}
+
+// RunHook runs the command configured for a hook event through the shell (sh -c, or cmd /C on Windows),
+// passing its output through. Events without a command do nothing.
+func RunHook(hooks models.HooksConfig, event string, run HookRun) error {
+	command := hookCommand(hooks, event)
+	if command == "" {
+		return nil
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+
+	cmd.Env = append(os.Environ(), run.Env(event)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s hook failed: %w", event, err)
+	}
+
+	return nil
+}
+
+func hookCommand(hooks models.HooksConfig, event string) string {
+	switch event {
+	case HookPreSync:
+		return hooks.PreSync
+	case HookPostSync:
+		return hooks.PostSync
+	case HookOnError:
+		return hooks.OnError
+	default:
+		return ""
+	}
+}
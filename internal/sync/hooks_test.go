@@ -0,0 +1,68 @@
+package sync
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestHookRunEnv(t *testing.T) {
+	run := HookRun{
+		Sources:   []string{"gmail_work", "gmail_personal"},
+		Target:    "obsidian",
+		OutputDir: "./vault",
+		ItemCount: 12,
+		Err:       errors.New("quota exceeded"),
+	}
+
+	want := []string{
+		"PKM_SYNC_HOOK=on_error",
+		"PKM_SYNC_SOURCES=gmail_work,gmail_personal",
+		"PKM_SYNC_TARGET=obsidian",
+		"PKM_SYNC_OUTPUT_DIR=./vault",
+		"PKM_SYNC_ITEM_COUNT=12",
+		"PKM_SYNC_ERROR=quota exceeded",
+	}
+
+	if got := run.Env(HookOnError); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Env() = %v, want %v", got, want)
+	}
+}
+
+func TestRunHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands in this test use sh syntax")
+	}
+
+	outFile := filepath.Join(t.TempDir(), "hook.txt")
+	hooks := models.HooksConfig{
+		PostSync: `echo "$PKM_SYNC_HOOK $PKM_SYNC_SOURCES $PKM_SYNC_ITEM_COUNT" > "` + outFile + `"`,
+		OnError:  "exit 3",
+	}
+
+	if err := RunHook(hooks, HookPreSync, HookRun{}); err != nil {
+		t.Errorf("RunHook() without a command error = %v", err)
+	}
+
+	if err := RunHook(hooks, HookPostSync, HookRun{Sources: []string{"gmail_work"}, ItemCount: 4}); err != nil {
+		t.Fatalf("RunHook() error = %v", err)
+	}
+
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.TrimSpace(string(data)); got != "post_sync gmail_work 4" {
+		t.Errorf("hook output = %q", got)
+	}
+
+	if err := RunHook(hooks, HookOnError, HookRun{}); err == nil || !strings.Contains(err.Error(), "on_error hook failed") {
+		t.Errorf("RunHook() error = %v, want on_error hook failure", err)
+	}
+}
@@ -0,0 +1,171 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"pkm-sync/internal/statestore"
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/models"
+)
+
+// Item error policies of the item_errors option.
+const (
+	ItemErrorsFailFast   = "fail_fast"
+	ItemErrorsSkip       = "skip"
+	ItemErrorsQuarantine = "quarantine"
+
+	// DefaultQuarantineFolder is where quarantined items are saved in the output directory.
+	DefaultQuarantineFolder = "errors"
+)
+
+// ValidateItemErrors checks the item_errors option.
+func ValidateItemErrors(cfg models.ItemErrorsConfig) error {
+	switch cfg.Policy {
+	case "", ItemErrorsFailFast, ItemErrorsSkip, ItemErrorsQuarantine:
+	default:
+		return fmt.Errorf("item_errors.policy: unknown policy '%s', expected fail_fast, skip or quarantine",
+			cfg.Policy)
+	}
+
+	if cfg.MaxErrors < 0 {
+		return fmt.Errorf("item_errors.max_errors must not be negative")
+	}
+
+	return nil
+}
+
+// ErrorBudget applies the item_errors policy to the items of a run that fail to be converted or written:
+// it lets the run carry on past them until more than max_errors failed, saving them to the quarantine
+// folder with the quarantine policy.
+type ErrorBudget struct {
+	policy        string
+	maxErrors     int
+	quarantineDir string
+
+	Failed      []string // IDs of the items that failed to export and were left out
+	Unconverted []string // IDs of the raw payloads, such as Gmail messages, that failed to convert to items
+
+	reportedFailed, reportedUnconverted int
+}
+
+// NewErrorBudget creates the error budget of an export to outputDir.
+func NewErrorBudget(cfg models.ItemErrorsConfig, outputDir string) *ErrorBudget {
+	folder := cfg.QuarantineFolder
+	if folder == "" {
+		folder = DefaultQuarantineFolder
+	}
+
+	if !filepath.IsAbs(folder) {
+		folder = filepath.Join(outputDir, folder)
+	}
+
+	return &ErrorBudget{policy: cfg.Policy, maxErrors: cfg.MaxErrors, quarantineDir: folder}
+}
+
+// QuarantineDir returns the folder quarantined items are saved to.
+func (b *ErrorBudget) QuarantineDir() string {
+	return b.quarantineDir
+}
+
+// Quarantines reports whether failed items are saved to the quarantine folder.
+func (b *ErrorBudget) Quarantines() bool {
+	return b.policy == ItemErrorsQuarantine
+}
+
+// Handle is an interfaces.ItemErrorFunc: it returns err under the fail_fast policy, or once the budget is
+// used up, and nil when the export should carry on without the item.
+func (b *ErrorBudget) Handle(item models.FullItem, err error) error {
+	if b.failsFast() {
+		return err
+	}
+
+	b.Failed = append(b.Failed, item.GetID())
+
+	if err := b.spend(b.quarantineDir, item.GetID(), item, err); err != nil {
+		return err
+	}
+
+	slog.Warn("Skipping item that failed to export", "item", item.GetID(), "error", err)
+
+	return nil
+}
+
+// HandleRaw is the interfaces.RawErrorFunc of a source: like Handle, but for a raw payload that failed to
+// convert to an item. Quarantined payloads are saved to a folder of the source in the quarantine folder.
+func (b *ErrorBudget) HandleRaw(source, id string, raw interface{}, err error) error {
+	if b.failsFast() {
+		return err
+	}
+
+	b.Unconverted = append(b.Unconverted, id)
+
+	if err := b.spend(filepath.Join(b.quarantineDir, utils.SanitizeFilename(source)), id, raw, err); err != nil {
+		return err
+	}
+
+	slog.Warn("Skipping item that failed to convert", "source", source, "id", id, "error", err)
+
+	return nil
+}
+
+// Unreported returns how many items failed to export and to convert since the last call, so exports
+// sharing a budget, such as the chunks of a bootstrap, each report their own.
+func (b *ErrorBudget) Unreported() (failed, unconverted int) {
+	failed, unconverted = len(b.Failed)-b.reportedFailed, len(b.Unconverted)-b.reportedUnconverted
+	b.reportedFailed, b.reportedUnconverted = len(b.Failed), len(b.Unconverted)
+
+	return failed, unconverted
+}
+
+func (b *ErrorBudget) failsFast() bool {
+	return b.policy == "" || b.policy == ItemErrorsFailFast
+}
+
+// spend quarantines a failed payload if configured, returning an error when the budget is used up.
+func (b *ErrorBudget) spend(dir, name string, payload interface{}, err error) error {
+	if b.Quarantines() {
+		if _, qErr := Quarantine(dir, name, payload, err); qErr != nil {
+			return fmt.Errorf("%w (and quarantining the item failed: %v)", err, qErr)
+		}
+	}
+
+	if b.maxErrors > 0 && len(b.Failed)+len(b.Unconverted) > b.maxErrors {
+		return fmt.Errorf("more than item_errors.max_errors (%d) items failed: %w", b.maxErrors, err)
+	}
+
+	return nil
+}
+
+// quarantinedItem is the stored form of a quarantined item.
+type quarantinedItem struct {
+	Error    string      `json:"error"`
+	FailedAt time.Time   `json:"failed_at"`
+	Payload  interface{} `json:"payload"`
+}
+
+// Quarantine saves the payload that failed with err, such as an item or a raw API response, to dir as
+// <name>.json, so the failure can be inspected and reported without losing the data. It returns the
+// file's path.
+func Quarantine(dir, name string, payload interface{}, err error) (string, error) {
+	data, mErr := json.MarshalIndent(quarantinedItem{Error: err.Error(), FailedAt: time.Now(), Payload: payload},
+		"", "  ")
+	if mErr != nil {
+		return "", fmt.Errorf("failed to encode quarantined payload: %w", mErr)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create quarantine folder: %w", err)
+	}
+
+	path := filepath.Join(dir, utils.SanitizeFilename(name)+".json")
+	if err := statestore.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write quarantined payload: %w", err)
+	}
+
+	return path, nil
+}
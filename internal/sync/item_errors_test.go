@@ -0,0 +1,129 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"pkm-sync/internal/statestore"
+	"pkm-sync/pkg/models"
+)
+
+func TestErrorBudgetHandle(t *testing.T) {
+	failure := fmt.Errorf("broken attachment")
+
+	tests := []struct {
+		name       string
+		cfg        models.ItemErrorsConfig
+		failures   int
+		wantStop   int // 1-based failure that stops the export; 0 never stops
+		quarantine bool
+	}{
+		{name: "default fails fast", failures: 2, wantStop: 1},
+		{name: "fail_fast", cfg: models.ItemErrorsConfig{Policy: ItemErrorsFailFast}, failures: 2, wantStop: 1},
+		{name: "skip without limit", cfg: models.ItemErrorsConfig{Policy: ItemErrorsSkip}, failures: 5},
+		{name: "skip up to max_errors", cfg: models.ItemErrorsConfig{Policy: ItemErrorsSkip, MaxErrors: 2},
+			failures: 3, wantStop: 3},
+		{name: "quarantine", cfg: models.ItemErrorsConfig{Policy: ItemErrorsQuarantine}, failures: 2,
+			quarantine: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			budget := NewErrorBudget(tt.cfg, outputDir)
+
+			stopped := 0
+
+			for i := 1; i <= tt.failures; i++ {
+				if err := budget.Handle(models.NewBasicItem(fmt.Sprintf("item-%d", i), "Item"), failure); err != nil {
+					stopped = i
+
+					break
+				}
+			}
+
+			if stopped != tt.wantStop {
+				t.Errorf("stopped at failure %d, want %d", stopped, tt.wantStop)
+			}
+
+			entries, _ := os.ReadDir(filepath.Join(outputDir, DefaultQuarantineFolder))
+			if tt.quarantine != (len(entries) == tt.failures) {
+				t.Errorf("quarantined %d items, want quarantine = %v", len(entries), tt.quarantine)
+			}
+		})
+	}
+}
+
+func TestQuarantine(t *testing.T) {
+	dir := t.TempDir()
+
+	path, err := Quarantine(dir, "msg/1", map[string]string{"id": "msg/1"}, fmt.Errorf("bad date"))
+	if err != nil {
+		t.Fatalf("Quarantine() error = %v", err)
+	}
+
+	if filepath.Dir(path) != dir {
+		t.Errorf("Quarantine() path = %s, want a file in %s", path, dir)
+	}
+
+	data, err := statestore.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stored struct {
+		Error   string            `json:"error"`
+		Payload map[string]string `json:"payload"`
+	}
+
+	if err := json.Unmarshal(data, &stored); err != nil {
+		t.Fatalf("quarantined file is not JSON: %v", err)
+	}
+
+	if stored.Error != "bad date" || stored.Payload["id"] != "msg/1" {
+		t.Errorf("quarantined %+v", stored)
+	}
+}
+
+func TestValidateItemErrors(t *testing.T) {
+	if err := ValidateItemErrors(models.ItemErrorsConfig{Policy: "retry"}); err == nil {
+		t.Error("ValidateItemErrors() accepted an unknown policy")
+	}
+
+	if err := ValidateItemErrors(models.ItemErrorsConfig{Policy: ItemErrorsSkip, MaxErrors: -1}); err == nil {
+		t.Error("ValidateItemErrors() accepted negative max_errors")
+	}
+
+	if err := ValidateItemErrors(models.ItemErrorsConfig{Policy: ItemErrorsQuarantine, MaxErrors: 10}); err != nil {
+		t.Errorf("ValidateItemErrors() error = %v", err)
+	}
+}
+
+func TestErrorBudgetHandleRaw(t *testing.T) {
+	outputDir := t.TempDir()
+	budget := NewErrorBudget(models.ItemErrorsConfig{Policy: ItemErrorsQuarantine, MaxErrors: 1}, outputDir)
+
+	err := budget.HandleRaw("gmail_work", "msg-1", map[string]string{"id": "msg-1"}, fmt.Errorf("bad date"))
+	if err != nil {
+		t.Fatalf("HandleRaw() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, DefaultQuarantineFolder, "gmail_work", "msg-1.json")); err != nil {
+		t.Errorf("raw payload not quarantined: %v", err)
+	}
+
+	if err := budget.Handle(models.NewBasicItem("item-1", "Item"), fmt.Errorf("boom")); err == nil {
+		t.Error("Handle() should fail once conversion and export failures exceed max_errors")
+	}
+
+	if failed, unconverted := budget.Unreported(); failed != 1 || unconverted != 1 {
+		t.Errorf("Unreported() = %d, %d, want 1, 1", failed, unconverted)
+	}
+
+	if failed, unconverted := budget.Unreported(); failed != 0 || unconverted != 0 {
+		t.Errorf("Unreported() after reporting = %d, %d, want 0, 0", failed, unconverted)
+	}
+}
@@ -0,0 +1,112 @@
+package sync
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"pkm-sync/pkg/models"
+)
+
+// ValidateKeywords checks a source's include_keywords and exclude_keywords for empty keywords.
+func ValidateKeywords(include, exclude []string) error {
+	for i, keywords := range [][]string{include, exclude} {
+		for _, keyword := range keywords {
+			if strings.TrimSpace(keyword) == "" {
+				return fmt.Errorf("%s must not contain empty keywords", []string{"include_keywords", "exclude_keywords"}[i])
+			}
+		}
+	}
+
+	return nil
+}
+
+// FilterKeywords keeps the items whose title or body mentions one of the include keywords (all items when
+// there are none) and none of the exclude keywords. The bodies of a thread's messages count as its body.
+// Keywords match whole words or phrases, ignoring case, so "art" does not match "party".
+func FilterKeywords(items []models.ItemInterface, include, exclude []string) []models.ItemInterface {
+	if len(include) == 0 && len(exclude) == 0 {
+		return items
+	}
+
+	kept := make([]models.ItemInterface, 0, len(items))
+
+	for _, item := range items {
+		text := keywordText(item)
+
+		if len(include) > 0 && !mentionsAny(text, include) {
+			continue
+		}
+
+		if mentionsAny(text, exclude) {
+			continue
+		}
+
+		kept = append(kept, item)
+	}
+
+	return kept
+}
+
+// keywordText returns the lowercase title and body of an item, including its messages' bodies.
+func keywordText(item models.ItemInterface) string {
+	parts := []string{item.GetTitle(), item.GetContent()}
+
+	if thread, ok := models.AsThread(item); ok {
+		for _, message := range thread.GetMessages() {
+			parts = append(parts, message.GetTitle(), message.GetContent())
+		}
+	}
+
+	return strings.ToLower(strings.Join(parts, "\n"))
+}
+
+// mentionsAny reports whether lowercase text contains one of the keywords as a whole word or phrase.
+func mentionsAny(text string, keywords []string) bool {
+	for _, keyword := range keywords {
+		keyword = strings.ToLower(strings.TrimSpace(keyword))
+		if keyword == "" {
+			continue
+		}
+
+		for offset := 0; ; {
+			index := strings.Index(text[offset:], keyword)
+			if index < 0 {
+				break
+			}
+
+			start, end := offset+index, offset+index+len(keyword)
+			if isWordBoundary(text, start, true) && isWordBoundary(text, end, false) {
+				return true
+			}
+
+			_, size := utf8.DecodeRuneInString(text[start:])
+			offset = start + size
+		}
+	}
+
+	return false
+}
+
+// isWordBoundary reports whether a match starting (before) or ending at byte i is not part of a longer
+// word: the rune before, or at, i is not a letter or digit.
+func isWordBoundary(text string, i int, before bool) bool {
+	var r rune
+
+	if before {
+		if i == 0 {
+			return true
+		}
+
+		r, _ = utf8.DecodeLastRuneInString(text[:i])
+	} else {
+		if i >= len(text) {
+			return true
+		}
+
+		r, _ = utf8.DecodeRuneInString(text[i:])
+	}
+
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
@@ -0,0 +1,67 @@
+package sync
+
+import (
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func keywordItem(id, title, content string) models.ItemInterface {
+	item := models.NewBasicItem(id, title)
+	item.SetContent(content)
+
+	return item
+}
+
+func TestFilterKeywords(t *testing.T) {
+	thread := models.NewThread("4", "Re: lunch")
+	thread.AddMessage(keywordItem("4a", "Re: lunch", "The Invoice is attached"))
+
+	items := []models.ItemInterface{
+		keywordItem("1", "Invoice for March", "Please pay by Friday"),
+		keywordItem("2", "Party planning", "Bring snacks"),
+		keywordItem("3", "Invoice reminder", "Unsubscribe from these emails"),
+		thread,
+	}
+
+	ids := func(items []models.ItemInterface) string {
+		var joined string
+		for _, item := range items {
+			joined += item.GetID()
+		}
+
+		return joined
+	}
+
+	tests := []struct {
+		name     string
+		include  []string
+		exclude  []string
+		expected string
+	}{
+		{"no keywords", nil, nil, "1234"},
+		{"include matches title and message bodies, ignoring case", []string{"INVOICE"}, nil, "134"},
+		{"exclude", nil, []string{"unsubscribe"}, "124"},
+		{"include and exclude", []string{"invoice"}, []string{"unsubscribe"}, "14"},
+		{"whole words only", []string{"art"}, nil, ""},
+		{"phrases", []string{"pay by friday"}, nil, "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(FilterKeywords(items, tt.include, tt.exclude)); got != tt.expected {
+				t.Errorf("FilterKeywords() kept %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateKeywords(t *testing.T) {
+	if err := ValidateKeywords([]string{"invoice"}, []string{"newsletter"}); err != nil {
+		t.Errorf("ValidateKeywords() = %v", err)
+	}
+
+	if err := ValidateKeywords(nil, []string{" "}); err == nil || err.Error() != "exclude_keywords must not contain empty keywords" {
+		t.Errorf("ValidateKeywords() = %v, want empty keyword error", err)
+	}
+}
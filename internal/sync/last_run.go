@@ -0,0 +1,86 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"pkm-sync/internal/statestore"
+	"pkm-sync/internal/utils"
+)
+
+const (
+	// LastRunFile records when each source last synced successfully, kept in the config directory.
+	LastRunFile = "last_runs.json"
+
+	// DefaultLastRunOverlap is how far before its last successful run a source syncs from, so items
+	// that arrived late or were being written during that run are picked up.
+	DefaultLastRunOverlap = time.Hour
+)
+
+// LastRuns maps source names to the start of their last successful sync.
+type LastRuns map[string]time.Time
+
+// LoadLastRuns reads the last run store; a missing store is returned empty.
+func LoadLastRuns(path string) (LastRuns, error) {
+	runs := make(LastRuns)
+
+	data, err := statestore.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return runs, nil
+		}
+
+		return nil, fmt.Errorf("failed to read last runs: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &runs); err != nil {
+		return nil, fmt.Errorf("failed to parse last runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// Save writes the last run store.
+func (l LastRuns) Save(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode last runs: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create last runs directory: %w", err)
+	}
+
+	if err := statestore.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write last runs: %w", err)
+	}
+
+	return nil
+}
+
+// Since returns when a source should sync from: overlap before its last successful run, if it had one.
+func (l LastRuns) Since(source string, overlap time.Duration) (time.Time, bool) {
+	last, ok := l[source]
+	if !ok || last.IsZero() {
+		return time.Time{}, false
+	}
+
+	return last.Add(-overlap), true
+}
+
+// ParseLastRunOverlap parses sync.last_run_overlap, a duration such as "30m", "2h" or "1d"; empty is the default.
+func ParseLastRunOverlap(overlap string) (time.Duration, error) {
+	if overlap == "" {
+		return DefaultLastRunOverlap, nil
+	}
+
+	duration, err := utils.ParseDuration(overlap)
+	if err != nil {
+		return 0, fmt.Errorf("invalid last_run_overlap '%s': use a duration such as '30m', '2h' or '1d'", overlap)
+	}
+
+	return duration, nil
+}
@@ -0,0 +1,56 @@
+package sync
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLastRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LastRunFile)
+
+	runs, err := LoadLastRuns(path)
+	if err != nil || len(runs) != 0 {
+		t.Fatalf("LoadLastRuns() of a missing store = %v, %v", runs, err)
+	}
+
+	if _, ok := runs.Since("gmail_work", time.Hour); ok {
+		t.Error("Since() found a run for a source that never synced")
+	}
+
+	started := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	runs["gmail_work"] = started
+
+	if err := runs.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadLastRuns(path)
+	if err != nil {
+		t.Fatalf("LoadLastRuns() error = %v", err)
+	}
+
+	if since, ok := loaded.Since("gmail_work", 30*time.Minute); !ok || !since.Equal(started.Add(-30*time.Minute)) {
+		t.Errorf("Since() = %v, %v; want 30 minutes before the last run", since, ok)
+	}
+}
+
+func TestParseLastRunOverlap(t *testing.T) {
+	if overlap, err := ParseLastRunOverlap(""); err != nil || overlap != DefaultLastRunOverlap {
+		t.Errorf("ParseLastRunOverlap(\"\") = %v, %v", overlap, err)
+	}
+
+	if overlap, err := ParseLastRunOverlap("15m"); err != nil || overlap != 15*time.Minute {
+		t.Errorf("ParseLastRunOverlap(15m) = %v, %v", overlap, err)
+	}
+
+	if overlap, err := ParseLastRunOverlap("1d"); err != nil || overlap != 24*time.Hour {
+		t.Errorf("ParseLastRunOverlap(1d) = %v, %v", overlap, err)
+	}
+
+	for _, invalid := range []string{"-1h", "6 months", "soon"} {
+		if _, err := ParseLastRunOverlap(invalid); err == nil {
+			t.Errorf("ParseLastRunOverlap(%q) accepted an invalid overlap", invalid)
+		}
+	}
+}
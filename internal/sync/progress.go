@@ -0,0 +1,165 @@
+package sync
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// progressBarWidth is the number of cells in a sync progress bar.
+	progressBarWidth = 20
+
+	// progressLogInterval is how often progress is logged when the output is not a terminal.
+	progressLogInterval = 10 * time.Second
+)
+
+// Progress shows how far a sync run is: a bar per source with its fetched and converted items, then one
+// for the notes written, each with an ETA from the pace so far. On a terminal the bar is redrawn in place;
+// otherwise a line is logged at most every progressLogInterval, so long runs don't look hung in logs either.
+// A nil Progress shows nothing.
+type Progress struct {
+	out         io.Writer
+	interactive bool
+	now         func() time.Time
+
+	stage   string // Source name, or "export" while notes are written
+	started time.Time
+	lastLog time.Time
+	drawn   bool // Whether the current bar is on screen without a newline
+}
+
+// NewProgress creates a Progress writing to out, drawing bars when out is a terminal.
+func NewProgress(out *os.File) *Progress {
+	interactive := false
+	if info, err := out.Stat(); err == nil {
+		interactive = info.Mode()&os.ModeCharDevice != 0
+	}
+
+	return newProgress(out, interactive, time.Now)
+}
+
+func newProgress(out io.Writer, interactive bool, now func() time.Time) *Progress {
+	return &Progress{out: out, interactive: interactive, now: now}
+}
+
+// Fetched reports that done of a source's total items are fetched.
+func (p *Progress) Fetched(source string, done, total int) {
+	if p == nil {
+		return
+	}
+
+	p.update(source, done, total, fmt.Sprintf("%d/%d fetched", done, total))
+}
+
+// Converted finishes a source's bar with the items fetched and those kept after conversion and filtering.
+func (p *Progress) Converted(source string, fetched, converted int) {
+	if p == nil {
+		return
+	}
+
+	p.finish(source, fetched, fmt.Sprintf("%d fetched, %d converted", fetched, converted))
+}
+
+// Written reports that done of total notes are written; the bar is finished once all are.
+func (p *Progress) Written(done, total int) {
+	if p == nil {
+		return
+	}
+
+	if done >= total {
+		p.finish("export", total, fmt.Sprintf("%d written", total))
+
+		return
+	}
+
+	p.update("export", done, total, fmt.Sprintf("%d/%d written", done, total))
+}
+
+// Interrupt ends a bar left on screen, so other output, such as a warning about a failed fetch, starts on its
+// own line.
+func (p *Progress) Interrupt() {
+	if p == nil {
+		return
+	}
+
+	p.endLine()
+	p.stage = ""
+}
+
+// update redraws the stage's bar, or logs it when the log interval has passed.
+func (p *Progress) update(stage string, done, total int, counts string) {
+	now := p.now()
+
+	if stage != p.stage {
+		p.endLine()
+		p.stage, p.started, p.lastLog = stage, now, now
+	}
+
+	line := progressLine(stage, done, total, counts, now.Sub(p.started))
+
+	if p.interactive {
+		fmt.Fprintf(p.out, "\r%s\033[K", line)
+		p.drawn = true
+
+		return
+	}
+
+	if now.Sub(p.lastLog) >= progressLogInterval {
+		fmt.Fprintln(p.out, line)
+		p.lastLog = now
+	}
+}
+
+// finish draws the stage's completed bar and ends its line. Non-interactive runs only log what the
+// periodic lines did not already show, i.e. stages that took longer than a log interval.
+func (p *Progress) finish(stage string, total int, counts string) {
+	now := p.now()
+
+	if stage != p.stage {
+		p.endLine()
+		p.stage, p.started, p.lastLog = stage, now, now
+	}
+
+	line := progressLine(stage, total, total, counts, 0)
+
+	switch {
+	case p.interactive:
+		fmt.Fprintf(p.out, "\r%s\033[K\n", line)
+	case now.Sub(p.started) >= progressLogInterval:
+		fmt.Fprintln(p.out, line)
+	}
+
+	p.stage, p.drawn = "", false
+}
+
+// endLine moves past a bar left on screen, before another stage or output starts.
+func (p *Progress) endLine() {
+	if p.drawn {
+		fmt.Fprintln(p.out)
+		p.drawn = false
+	}
+}
+
+// progressLine renders a stage's bar, e.g.
+// "gmail_work [######--------------]  30% 300/1000 fetched, ETA 1m10s".
+func progressLine(stage string, done, total int, counts string, elapsed time.Duration) string {
+	progress := 1.0
+	if total > 0 {
+		progress = min(max(float64(done)/float64(total), 0), 1)
+	}
+
+	filled := int(progress * progressBarWidth)
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", progressBarWidth-filled)
+
+	line := fmt.Sprintf("%s [%s] %3.0f%% %s", stage, bar, progress*100, counts)
+
+	if done > 0 && done < total && elapsed > 0 {
+		eta := time.Duration(float64(elapsed) * float64(total-done) / float64(done))
+		line += ", ETA " + eta.Round(time.Second).String()
+	}
+
+	return line
+}
@@ -0,0 +1,89 @@
+package sync
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock returns a clock advanced by step on every reading.
+func fakeClock(step time.Duration) func() time.Time {
+	now := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	return func() time.Time {
+		now = now.Add(step)
+
+		return now
+	}
+}
+
+func TestProgressDrawsBarsOnTerminal(t *testing.T) {
+	var out bytes.Buffer
+
+	progress := newProgress(&out, true, fakeClock(time.Second))
+	progress.Fetched("gmail_work", 0, 4)
+	progress.Fetched("gmail_work", 1, 4)
+	progress.Converted("gmail_work", 4, 3)
+	progress.Written(1, 3)
+	progress.Written(3, 3)
+
+	lines := strings.Split(out.String(), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("want a finished line per stage, got %q", out.String())
+	}
+
+	for _, want := range []string{
+		"\rgmail_work [#####---------------]  25% 1/4 fetched, ETA 3s\033[K",
+		"\rgmail_work [####################] 100% 4 fetched, 3 converted\033[K",
+	} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("source line missing %q:\n%q", want, lines[0])
+		}
+	}
+
+	if !strings.HasSuffix(lines[1], "\rexport [####################] 100% 3 written\033[K") {
+		t.Errorf("export line = %q", lines[1])
+	}
+}
+
+func TestProgressLogsPeriodicallyWithoutTerminal(t *testing.T) {
+	var out bytes.Buffer
+
+	progress := newProgress(&out, false, fakeClock(4*time.Second))
+	for done := 0; done <= 10; done++ {
+		progress.Fetched("gmail_work", done, 10)
+	}
+
+	progress.Converted("gmail_work", 10, 10)
+
+	// Written in under a log interval, so nothing is logged
+	progress.Written(0, 2)
+	progress.Written(2, 2)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("want a line every 10s and a final one, got:\n%s", out.String())
+	}
+
+	if strings.Contains(out.String(), "\r") || strings.Contains(out.String(), "written") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	if lines[0] != "gmail_work [######--------------]  30% 3/10 fetched, ETA 28s" {
+		t.Errorf("first line = %q", lines[0])
+	}
+
+	if lines[3] != "gmail_work [####################] 100% 10 fetched, 10 converted" {
+		t.Errorf("last line = %q", lines[3])
+	}
+}
+
+func TestNilProgressShowsNothing(t *testing.T) {
+	var progress *Progress
+
+	progress.Fetched("gmail_work", 1, 2)
+	progress.Converted("gmail_work", 2, 2)
+	progress.Written(1, 1)
+	progress.Interrupt()
+}
@@ -0,0 +1,314 @@
+package sync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"pkm-sync/internal/statestore"
+	"pkm-sync/pkg/models"
+)
+
+// QueueFile holds the work of syncs that could not finish while offline, kept in the config directory.
+const QueueFile = "offline_queue.json"
+
+// offlineMessages are the error texts of connectivity failures, for errors that were not wrapped with %w
+// and so cannot be matched by type.
+var offlineMessages = []string{
+	"no such host",
+	"network is unreachable",
+	"connection refused",
+	"connection reset",
+	"i/o timeout",
+	"tls handshake timeout",
+	"server misbehaving",
+}
+
+// QueuedRange is a sync window of a source whose fetch failed for lack of connectivity.
+type QueuedRange struct {
+	Since    time.Time `json:"since"`
+	Until    time.Time `json:"until"`
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// Queue is what syncs left undone for lack of connectivity, by source: the windows that could not be
+// fetched, and the fetched items that could not be written. The next sync fetches and writes them.
+type Queue struct {
+	Items  map[string][]models.ItemInterface
+	Ranges map[string]QueuedRange
+}
+
+// storedQueue is the stored form of a queue; items are kept like those of the item cache.
+type storedQueue struct {
+	SchemaVersion int                          `json:"schema_version"`
+	Items         map[string][]json.RawMessage `json:"items,omitempty"`
+	Ranges        map[string]QueuedRange       `json:"ranges,omitempty"`
+}
+
+// LoadQueue reads the offline queue, upgrading queued items to the current schema version. A missing queue
+// is returned empty.
+func LoadQueue(path string) (*Queue, error) {
+	queue := &Queue{Items: make(map[string][]models.ItemInterface), Ranges: make(map[string]QueuedRange)}
+
+	data, err := statestore.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return queue, nil
+		}
+
+		return nil, fmt.Errorf("failed to read offline queue: %w", err)
+	}
+
+	var stored storedQueue
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to parse offline queue: %w", err)
+	}
+
+	for source, raws := range stored.Items {
+		for i, raw := range raws {
+			upgraded, err := models.UpgradeItemJSON(raw, stored.SchemaVersion)
+			if err != nil {
+				return nil, fmt.Errorf("queued item %d of '%s': %w", i+1, source, err)
+			}
+
+			item, err := decodeCachedItem(upgraded)
+			if err != nil {
+				return nil, fmt.Errorf("queued item %d of '%s': %w", i+1, source, err)
+			}
+
+			queue.Items[source] = append(queue.Items[source], item)
+		}
+	}
+
+	for source, window := range stored.Ranges {
+		queue.Ranges[source] = window
+	}
+
+	return queue, nil
+}
+
+// Save writes the queue, removing the file once nothing is queued.
+func (q *Queue) Save(path string) error {
+	if q.Empty() {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove offline queue: %w", err)
+		}
+
+		return nil
+	}
+
+	stored := storedQueue{
+		SchemaVersion: models.ItemSchemaVersion,
+		Items:         make(map[string][]json.RawMessage, len(q.Items)),
+		Ranges:        q.Ranges,
+	}
+
+	for source, items := range q.Items {
+		for _, item := range items {
+			raw, err := json.Marshal(item)
+			if err != nil {
+				return fmt.Errorf("failed to encode queued item '%s': %w", item.GetID(), err)
+			}
+
+			stored.Items[source] = append(stored.Items[source], raw)
+		}
+	}
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("failed to encode offline queue: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create offline queue directory: %w", err)
+	}
+
+	if err := statestore.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write offline queue: %w", err)
+	}
+
+	return nil
+}
+
+// Empty reports whether nothing is queued.
+func (q *Queue) Empty() bool {
+	return len(q.Items) == 0 && len(q.Ranges) == 0
+}
+
+// QueueItems queues a source's items to be written by the next sync, replacing queued versions of the
+// same items.
+func (q *Queue) QueueItems(source string, items []models.ItemInterface) {
+	if len(items) == 0 {
+		return
+	}
+
+	q.Items[source] = mergeItems(q.Items[source], items)
+}
+
+// TakeItems returns the queued items of every source, sorted by source name, and removes them from the
+// queue.
+func (q *Queue) TakeItems() ([]string, map[string][]models.ItemInterface) {
+	items := q.Items
+	q.Items = make(map[string][]models.ItemInterface)
+
+	sources := make([]string, 0, len(items))
+	for source := range items {
+		sources = append(sources, source)
+	}
+
+	sort.Strings(sources)
+
+	return sources, items
+}
+
+// QueueRange queues a source's window whose fetch failed, widening a window already queued for it, so the
+// next sync fetches all of it.
+func (q *Queue) QueueRange(source string, since, until time.Time, err error, now time.Time) {
+	if until.IsZero() {
+		until = now
+	}
+
+	window := QueuedRange{Since: since, Until: until, Error: err.Error(), FailedAt: now}
+
+	if queued, ok := q.Ranges[source]; ok {
+		if queued.Since.Before(window.Since) {
+			window.Since = queued.Since
+		}
+
+		if queued.Until.After(window.Until) {
+			window.Until = queued.Until
+		}
+	}
+
+	q.Ranges[source] = window
+}
+
+// Range returns the queued window of a source, if it has one.
+func (q *Queue) Range(source string) (QueuedRange, bool) {
+	window, ok := q.Ranges[source]
+
+	return window, ok
+}
+
+// ClearRange removes a source's queued window once it is fetched.
+func (q *Queue) ClearRange(source string) {
+	delete(q.Ranges, source)
+}
+
+// Drop removes a source's queued items created before before, or, when before is zero, all of its items
+// and its queued window. It returns how many items and windows were removed.
+func (q *Queue) Drop(source string, before time.Time) int {
+	dropped := 0
+
+	if before.IsZero() {
+		dropped = len(q.Items[source])
+		delete(q.Items, source)
+
+		if _, ok := q.Ranges[source]; ok {
+			dropped++
+
+			delete(q.Ranges, source)
+		}
+
+		return dropped
+	}
+
+	var kept []models.ItemInterface
+
+	for _, item := range q.Items[source] {
+		if created := item.GetCreatedAt(); !created.IsZero() && created.Before(before) {
+			dropped++
+
+			continue
+		}
+
+		kept = append(kept, item)
+	}
+
+	if len(kept) == 0 {
+		delete(q.Items, source)
+	} else {
+		q.Items[source] = kept
+	}
+
+	return dropped
+}
+
+// IsOffline reports whether err is a connectivity failure, such as a failed DNS lookup or a connection that
+// could not be made or timed out, rather than an error reported by the source.
+func IsOffline(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, offline := range offlineMessages {
+		if strings.Contains(message, offline) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MergeQueuedItems adds queued items to fetched ones, leaving out those fetched again, which are newer.
+func MergeQueuedItems(fetched, queued []models.ItemInterface) []models.ItemInterface {
+	seen := make(map[string]bool, len(fetched))
+	for _, item := range fetched {
+		seen[item.GetID()] = true
+	}
+
+	merged := fetched
+
+	for _, item := range queued {
+		if !seen[item.GetID()] {
+			seen[item.GetID()] = true
+			merged = append(merged, item)
+		}
+	}
+
+	return merged
+}
+
+// mergeItems replaces items of existing with the same ID by those of items and appends the others.
+func mergeItems(existing, items []models.ItemInterface) []models.ItemInterface {
+	byID := make(map[string]int, len(existing))
+	for i, item := range existing {
+		byID[item.GetID()] = i
+	}
+
+	for _, item := range items {
+		if i, ok := byID[item.GetID()]; ok {
+			existing[i] = item
+
+			continue
+		}
+
+		byID[item.GetID()] = len(existing)
+		existing = append(existing, item)
+	}
+
+	return existing
+}
@@ -0,0 +1,121 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), QueueFile)
+
+	queue, err := LoadQueue(path)
+	if err != nil || !queue.Empty() {
+		t.Fatalf("LoadQueue() of a missing queue = %+v, %v", queue, err)
+	}
+
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	offline := errors.New("dial tcp: lookup gmail.googleapis.com: no such host")
+
+	queue.QueueRange("gmail_work", day(5), time.Time{}, offline, day(6))
+	queue.QueueRange("gmail_work", day(3), day(4), offline, day(7))
+	queue.QueueItems("drive", []models.ItemInterface{models.NewBasicItem("a", "A"), models.NewBasicItem("b", "B")})
+	queue.QueueItems("drive", []models.ItemInterface{models.NewBasicItem("a", "A, edited")})
+
+	if err := queue.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	queue, err = LoadQueue(path)
+	if err != nil {
+		t.Fatalf("LoadQueue() error = %v", err)
+	}
+
+	window, ok := queue.Range("gmail_work")
+	if !ok || !window.Since.Equal(day(3)) || !window.Until.Equal(day(6)) || !window.FailedAt.Equal(day(7)) {
+		t.Errorf("Range() = %+v, %v, want the widened window from the 3rd to the 6th", window, ok)
+	}
+
+	sources, items := queue.TakeItems()
+	if len(sources) != 1 || sources[0] != "drive" || len(items["drive"]) != 2 ||
+		items["drive"][0].GetTitle() != "A, edited" {
+		t.Fatalf("TakeItems() = %v, %+v, want drive's two items with A replaced", sources, items)
+	}
+
+	queue.ClearRange("gmail_work")
+
+	if err := queue.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("empty queue was not removed: %v", err)
+	}
+}
+
+func TestQueueDrop(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	item := func(id string, created time.Time) models.ItemInterface {
+		basic := models.NewBasicItem(id, id)
+		basic.SetCreatedAt(created)
+
+		return basic
+	}
+
+	queue := &Queue{Items: make(map[string][]models.ItemInterface), Ranges: make(map[string]QueuedRange)}
+	queue.QueueItems("gmail_work", []models.ItemInterface{item("old", day(1)), item("new", day(9))})
+	queue.QueueItems("drive", []models.ItemInterface{item("doc", day(1))})
+	queue.QueueRange("gmail_work", day(5), time.Time{}, errors.New("offline"), day(6))
+
+	if dropped := queue.Drop("gmail_work", day(5)); dropped != 1 || len(queue.Items["gmail_work"]) != 1 {
+		t.Errorf("Drop() before the 5th = %d, left %d items, want 1 dropped and 1 left", dropped,
+			len(queue.Items["gmail_work"]))
+	}
+
+	if _, ok := queue.Range("gmail_work"); !ok {
+		t.Error("Drop() with a before time dropped the queued window")
+	}
+
+	if dropped := queue.Drop("gmail_work", time.Time{}); dropped != 2 {
+		t.Errorf("Drop() = %d, want the item and the window", dropped)
+	}
+
+	if _, ok := queue.Range("gmail_work"); ok || len(queue.Items["drive"]) != 1 {
+		t.Errorf("Drop() left %+v, want only drive's item", queue)
+	}
+}
+
+func TestIsOffline(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{fmt.Errorf("failed to fetch: %w", &net.DNSError{Err: "no such host", Name: "example.com"}), true},
+		{&net.OpError{Op: "dial", Err: errors.New("connect: network is unreachable")}, true},
+		{errors.New("Get \"https://graph.microsoft.com\": dial tcp: i/o timeout"), true},
+		{errors.New("googleapi: Error 401: Invalid Credentials"), false},
+	}
+
+	for _, tt := range tests {
+		if got := IsOffline(tt.err); got != tt.want {
+			t.Errorf("IsOffline(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestMergeQueuedItems(t *testing.T) {
+	fetched := []models.ItemInterface{models.NewBasicItem("a", "A, refetched")}
+	queued := []models.ItemInterface{models.NewBasicItem("a", "A"), models.NewBasicItem("b", "B")}
+
+	merged := MergeQueuedItems(fetched, queued)
+	if len(merged) != 2 || merged[0].GetTitle() != "A, refetched" || merged[1].GetID() != "b" {
+		t.Errorf("MergeQueuedItems() = %+v, want the refetched A then B", merged)
+	}
+}
@@ -0,0 +1,97 @@
+package sync
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SourceOutcome is how one source fared in a sync run.
+type SourceOutcome struct {
+	Source  string
+	Items   int    // Items collected from the source
+	Err     error  // Why the source failed, if it did
+	Skipped string // Why the source was not synced, e.g. "disabled", if it wasn't
+}
+
+// RunReport collects the outcome of every source of a sync run, so a failing source, such as one with an
+// expired token, is reported at the end rather than only in a warning between the other sources' output.
+type RunReport struct {
+	Outcomes []SourceOutcome
+}
+
+// PartialFailureError is returned for runs where some sources failed while others synced.
+type PartialFailureError struct {
+	Failed []string
+	Total  int // Sources that were synced or failed, leaving out skipped ones
+}
+
+func (e *PartialFailureError) Error() string {
+	return fmt.Sprintf("%d of %d sources failed: %s", len(e.Failed), e.Total, strings.Join(e.Failed, ", "))
+}
+
+// Succeeded records that a source synced its items.
+func (r *RunReport) Succeeded(source string, items int) {
+	r.Outcomes = append(r.Outcomes, SourceOutcome{Source: source, Items: items})
+}
+
+// Failed records that a source could not be synced.
+func (r *RunReport) Failed(source string, err error) {
+	r.Outcomes = append(r.Outcomes, SourceOutcome{Source: source, Err: err})
+}
+
+// Skipped records that a source was left out on purpose, e.g. because it is disabled or out of quota.
+func (r *RunReport) Skipped(source, reason string) {
+	r.Outcomes = append(r.Outcomes, SourceOutcome{Source: source, Skipped: reason})
+}
+
+// Summary renders a line per source: its item count, or why it failed or was skipped.
+func (r *RunReport) Summary() string {
+	width := 0
+	for _, outcome := range r.Outcomes {
+		width = max(width, len(outcome.Source))
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("Sync summary:\n")
+
+	for _, outcome := range r.Outcomes {
+		switch {
+		case outcome.Err != nil:
+			sb.WriteString(fmt.Sprintf("  ❌ %-*s  failed: %v\n", width, outcome.Source, outcome.Err))
+		case outcome.Skipped != "":
+			sb.WriteString(fmt.Sprintf("  ⏭️  %-*s  skipped: %s\n", width, outcome.Source, outcome.Skipped))
+		default:
+			sb.WriteString(fmt.Sprintf("  ✅ %-*s  %d items\n", width, outcome.Source, outcome.Items))
+		}
+	}
+
+	return sb.String()
+}
+
+// Err returns nil when no source failed, a *PartialFailureError when others synced, and an error naming
+// the failed sources when none did.
+func (r *RunReport) Err() error {
+	var failed []string
+
+	synced := 0
+
+	for _, outcome := range r.Outcomes {
+		switch {
+		case outcome.Err != nil:
+			failed = append(failed, outcome.Source)
+		case outcome.Skipped == "":
+			synced++
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	if synced == 0 {
+		return fmt.Errorf("all sources failed: %s", strings.Join(failed, ", "))
+	}
+
+	return &PartialFailureError{Failed: failed, Total: synced + len(failed)}
+}
@@ -0,0 +1,90 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRunReportSummary(t *testing.T) {
+	var report RunReport
+
+	report.Succeeded("gmail_work", 142)
+	report.Failed("gmail_personal", fmt.Errorf("token expired"))
+	report.Skipped("drive", "disabled")
+
+	want := strings.Join([]string{
+		"Sync summary:",
+		"  ✅ gmail_work      142 items",
+		"  ❌ gmail_personal  failed: token expired",
+		"  ⏭️  drive           skipped: disabled",
+		"",
+	}, "\n")
+
+	if got := report.Summary(); got != want {
+		t.Errorf("Summary() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRunReportErr(t *testing.T) {
+	tests := []struct {
+		name    string
+		report  func(r *RunReport)
+		want    string
+		partial bool
+	}{
+		{
+			name: "all synced",
+			report: func(r *RunReport) {
+				r.Succeeded("a", 1)
+				r.Skipped("b", "disabled")
+			},
+		},
+		{
+			name: "some failed",
+			report: func(r *RunReport) {
+				r.Succeeded("a", 1)
+				r.Failed("b", fmt.Errorf("boom"))
+				r.Failed("c", fmt.Errorf("boom"))
+				r.Skipped("d", "disabled")
+			},
+			want:    "2 of 3 sources failed: b, c",
+			partial: true,
+		},
+		{
+			name: "all failed",
+			report: func(r *RunReport) {
+				r.Failed("a", fmt.Errorf("boom"))
+				r.Skipped("b", "disabled")
+			},
+			want: "all sources failed: a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var report RunReport
+
+			tt.report(&report)
+
+			err := report.Err()
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Err() = %v, want nil", err)
+				}
+
+				return
+			}
+
+			if err == nil || err.Error() != tt.want {
+				t.Fatalf("Err() = %v, want %q", err, tt.want)
+			}
+
+			var partial *PartialFailureError
+			if errors.As(err, &partial) != tt.partial {
+				t.Errorf("Err() partial = %v, want %v", !tt.partial, tt.partial)
+			}
+		})
+	}
+}
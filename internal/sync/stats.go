@@ -0,0 +1,266 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"pkm-sync/internal/statestore"
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/models"
+)
+
+const (
+	// StatsFile is the statistics store kept in the config directory.
+	StatsFile = "stats.json"
+
+	// maxStatsHistory is how many runs are kept alongside the cumulative totals.
+	maxStatsHistory = 100
+)
+
+// RunStats describes what one sync run, or the sum of all runs, brought in.
+type RunStats struct {
+	Time            time.Time      `json:"time,omitempty"`
+	Target          string         `json:"target,omitempty"`
+	Items           int            `json:"items"`
+	Sources         map[string]int `json:"sources,omitempty"`      // Items per configured source
+	Senders         map[string]int `json:"senders,omitempty"`      // Messages per sender address
+	MeetingDays     map[string]int `json:"meeting_days,omitempty"` // Meetings per weekday
+	Attachments     int            `json:"attachments"`
+	AttachmentBytes int64          `json:"attachment_bytes"`
+}
+
+// Stats is the statistics store: cumulative totals plus the most recent runs.
+type Stats struct {
+	Runs      int        `json:"runs"`
+	FirstSync time.Time  `json:"first_sync,omitempty"`
+	LastSync  time.Time  `json:"last_sync,omitempty"`
+	Totals    RunStats   `json:"totals"`
+	History   []RunStats `json:"history,omitempty"` // Oldest first
+}
+
+// Count is a named count, as returned by TopCounts.
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// CollectRunStats summarizes the items of a sync run. sourceCounts holds the items fetched per source.
+func CollectRunStats(items []models.ItemInterface, sourceCounts map[string]int, target string, at time.Time) RunStats {
+	run := RunStats{
+		Time:        at,
+		Target:      target,
+		Items:       len(items),
+		Sources:     make(map[string]int),
+		Senders:     make(map[string]int),
+		MeetingDays: make(map[string]int),
+	}
+
+	for name, count := range sourceCounts {
+		run.Sources[name] = count
+	}
+
+	for _, item := range items {
+		run.addItem(item)
+
+		if thread, ok := models.AsThread(item); ok {
+			for _, message := range thread.GetMessages() {
+				run.addItem(message)
+			}
+		}
+	}
+
+	return run
+}
+
+func (r *RunStats) addItem(item models.ItemInterface) {
+	metadata := models.Metadata(item.GetMetadata())
+
+	if sender, ok := metadata.GetRecipient(models.MetadataFrom); ok && sender.Email != "" {
+		r.Senders[strings.ToLower(strings.TrimSpace(sender.Email))]++
+	}
+
+	if start, ok := metadata.GetTime(models.MetadataStartTime); ok && item.GetItemType() == "event" {
+		r.MeetingDays[start.Weekday().String()]++
+	}
+
+	for _, attachment := range item.GetAttachments() {
+		r.Attachments++
+		r.AttachmentBytes += attachmentSize(attachment)
+	}
+}
+
+// add folds another run into r.
+func (r *RunStats) add(other RunStats) {
+	r.Items += other.Items
+	r.Attachments += other.Attachments
+	r.AttachmentBytes += other.AttachmentBytes
+	r.Sources = addCounts(r.Sources, other.Sources)
+	r.Senders = addCounts(r.Senders, other.Senders)
+	r.MeetingDays = addCounts(r.MeetingDays, other.MeetingDays)
+}
+
+func addCounts(into, from map[string]int) map[string]int {
+	if into == nil {
+		into = make(map[string]int)
+	}
+
+	for name, count := range from {
+		into[name] += count
+	}
+
+	return into
+}
+
+// attachmentSize returns an attachment's size, estimating it from the base64 data when not recorded.
+func attachmentSize(attachment models.Attachment) int64 {
+	if attachment.Size > 0 {
+		return attachment.Size
+	}
+
+	return int64(len(attachment.Data)) * 3 / 4
+}
+
+// LoadStats reads the statistics store; a missing store is returned empty.
+func LoadStats(path string) (*Stats, error) {
+	stats := &Stats{}
+
+	data, err := statestore.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return stats, nil
+		}
+
+		return nil, fmt.Errorf("failed to read sync stats: %w", err)
+	}
+
+	if err := json.Unmarshal(data, stats); err != nil {
+		return nil, fmt.Errorf("failed to parse sync stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// Save writes the statistics store.
+func (s *Stats) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode sync stats: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create stats directory: %w", err)
+	}
+
+	if err := statestore.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write sync stats: %w", err)
+	}
+
+	return nil
+}
+
+// Record adds a run to the totals and history, dropping the oldest runs beyond maxStatsHistory.
+func (s *Stats) Record(run RunStats) {
+	s.Runs++
+
+	if s.FirstSync.IsZero() {
+		s.FirstSync = run.Time
+	}
+
+	s.LastSync = run.Time
+	s.Totals.add(run)
+
+	s.History = append(s.History, run)
+	if len(s.History) > maxStatsHistory {
+		s.History = s.History[len(s.History)-maxStatsHistory:]
+	}
+}
+
+// LastRun returns the most recent run, if any.
+func (s *Stats) LastRun() (RunStats, bool) {
+	if len(s.History) == 0 {
+		return RunStats{}, false
+	}
+
+	return s.History[len(s.History)-1], true
+}
+
+// TopCounts returns the n largest counts, ties broken by name; n <= 0 returns all.
+func TopCounts(counts map[string]int, n int) []Count {
+	top := make([]Count, 0, len(counts))
+	for name, count := range counts {
+		top = append(top, Count{Name: name, Count: count})
+	}
+
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+
+		return top[i].Name < top[j].Name
+	})
+
+	if n > 0 && len(top) > n {
+		top = top[:n]
+	}
+
+	return top
+}
+
+// FormatStatsNote renders the statistics as a markdown note for the vault.
+func FormatStatsNote(s *Stats, top int) string {
+	var sb strings.Builder
+
+	sb.WriteString("# Sync Statistics\n\n")
+
+	if s.Runs == 0 {
+		sb.WriteString("No syncs recorded yet.\n")
+
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "- **Runs:** %d (%s to %s)\n", s.Runs,
+		s.FirstSync.Format("2006-01-02"), s.LastSync.Format("2006-01-02 15:04"))
+	fmt.Fprintf(&sb, "- **Items:** %d\n", s.Totals.Items)
+	fmt.Fprintf(&sb, "- **Attachments:** %d (%s)\n", s.Totals.Attachments, utils.FormatSize(s.Totals.AttachmentBytes))
+
+	if last, ok := s.LastRun(); ok {
+		fmt.Fprintf(&sb, "- **Last run:** %d items, %d attachments\n", last.Items, last.Attachments)
+	}
+
+	writeCountTable(&sb, "Items per Source", "Source", TopCounts(s.Totals.Sources, 0))
+	writeCountTable(&sb, "Top Senders", "Sender", TopCounts(s.Totals.Senders, top))
+	writeCountTable(&sb, "Busiest Meeting Days", "Day", TopCounts(s.Totals.MeetingDays, 0))
+
+	return sb.String()
+}
+
+func writeCountTable(sb *strings.Builder, heading, column string, counts []Count) {
+	if len(counts) == 0 {
+		return
+	}
+
+	fmt.Fprintf(sb, "\n## %s\n\n| %s | Count |\n|---|---|\n", heading, column)
+
+	for _, count := range counts {
+		fmt.Fprintf(sb, "| %s | %d |\n", utils.EscapeTableCell(count.Name), count.Count)
+	}
+}
+
+// WriteStatsNote writes the statistics note to path, relative to the output directory.
+func WriteStatsNote(outputDir, path string, s *Stats, top int) error {
+	notePath := filepath.Join(outputDir, path)
+	if err := os.MkdirAll(filepath.Dir(notePath), 0755); err != nil {
+		return fmt.Errorf("failed to create stats note directory: %w", err)
+	}
+
+	if err := os.WriteFile(notePath, []byte(FormatStatsNote(s, top)), 0644); err != nil {
+		return fmt.Errorf("failed to write stats note: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package sync
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+type testRecipient struct {
+	Name  string
+	Email string
+}
+
+func statsTestItems() []models.ItemInterface {
+	email := models.NewBasicItem("m1", "Invoice")
+	email.SetItemType("email")
+	email.SetMetadata(map[string]interface{}{"from": testRecipient{Name: "Alice", Email: "Alice@Company.com"}})
+	email.SetAttachments([]models.Attachment{{Name: "invoice.pdf", Size: 2048}, {Name: "logo.png", Data: "AAAA"}})
+
+	reply := models.NewBasicItem("m2", "Re: Invoice")
+	reply.SetMetadata(map[string]interface{}{"from": "Bob <bob@company.com>"})
+
+	thread := models.NewThread("t1", "Invoice")
+	thread.AddMessage(reply)
+	thread.AddMessage(models.NewBasicItem("m3", "Re: Invoice"))
+
+	meeting := models.NewBasicItem("e1", "Planning")
+	meeting.SetItemType("event")
+	meeting.SetMetadata(map[string]interface{}{
+		"start_time": time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC), // Tuesday
+		"from":       map[string]interface{}{"email": "alice@company.com"},
+	})
+
+	return []models.ItemInterface{email, thread, meeting}
+}
+
+func TestCollectRunStats(t *testing.T) {
+	at := time.Date(2025, 3, 5, 9, 0, 0, 0, time.UTC)
+	run := CollectRunStats(statsTestItems(), map[string]int{"gmail_work": 2, "calendar": 1}, "obsidian", at)
+
+	if run.Items != 3 || run.Sources["gmail_work"] != 2 || run.Target != "obsidian" || !run.Time.Equal(at) {
+		t.Errorf("CollectRunStats() = %+v", run)
+	}
+
+	if run.Senders["alice@company.com"] != 2 || run.Senders["bob@company.com"] != 1 || len(run.Senders) != 2 {
+		t.Errorf("Senders = %v", run.Senders)
+	}
+
+	if run.MeetingDays["Tuesday"] != 1 || len(run.MeetingDays) != 1 {
+		t.Errorf("MeetingDays = %v", run.MeetingDays)
+	}
+
+	if run.Attachments != 2 || run.AttachmentBytes != 2051 {
+		t.Errorf("Attachments = %d (%d bytes), want 2 (2051 bytes)", run.Attachments, run.AttachmentBytes)
+	}
+}
+
+func TestStatsRecordAndPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), StatsFile)
+
+	stats, err := LoadStats(path)
+	if err != nil {
+		t.Fatalf("LoadStats() error = %v", err)
+	}
+
+	first := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	second := first.Add(24 * time.Hour)
+
+	stats.Record(CollectRunStats(statsTestItems(), map[string]int{"gmail_work": 3}, "obsidian", first))
+	stats.Record(CollectRunStats(statsTestItems()[:1], map[string]int{"gmail_work": 1}, "obsidian", second))
+
+	if err := stats.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadStats(path)
+	if err != nil {
+		t.Fatalf("LoadStats() error = %v", err)
+	}
+
+	if loaded.Runs != 2 || !loaded.FirstSync.Equal(first) || !loaded.LastSync.Equal(second) {
+		t.Errorf("loaded stats = %+v", loaded)
+	}
+
+	totals := loaded.Totals
+	if totals.Items != 4 || totals.Sources["gmail_work"] != 4 || totals.Senders["alice@company.com"] != 3 {
+		t.Errorf("Totals = %+v", totals)
+	}
+
+	if last, ok := loaded.LastRun(); !ok || last.Items != 1 {
+		t.Errorf("LastRun() = %+v, %v", last, ok)
+	}
+}
+
+func TestTopCounts(t *testing.T) {
+	got := TopCounts(map[string]int{"b": 2, "a": 2, "c": 5, "d": 1}, 3)
+
+	want := []Count{{"c", 5}, {"a", 2}, {"b", 2}}
+	if len(got) != len(want) {
+		t.Fatalf("TopCounts() = %v, want %v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("TopCounts()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestFormatStatsNote(t *testing.T) {
+	stats := &Stats{}
+	stats.Record(CollectRunStats(statsTestItems(), map[string]int{"gmail_work": 3}, "obsidian", time.Now()))
+
+	note := FormatStatsNote(stats, 1)
+
+	for _, want := range []string{"# Sync Statistics", "- **Items:** 3", "## Top Senders", "| alice@company.com | 2 |",
+		"| Tuesday | 1 |", "| gmail_work | 3 |"} {
+		if !strings.Contains(note, want) {
+			t.Errorf("FormatStatsNote() missing %q:\n%s", want, note)
+		}
+	}
+
+	if strings.Contains(note, "bob@company.com") {
+		t.Errorf("FormatStatsNote() should list only the top sender:\n%s", note)
+	}
+}
@@ -27,8 +27,8 @@ func (m *MockSource) Fetch(since time.Time, limit int) ([]models.ItemInterface,
 	return m.itemsToReturn, nil
 }
 
-func (m *MockSource) SupportsRealtime() bool {
-	return false
+func (m *MockSource) Capabilities() interfaces.SourceCapabilities {
+	return interfaces.SourceCapabilities{}
 }
 
 // MockTarget is a mock implementation of the Target interface for testing.
@@ -62,8 +62,8 @@ func (m *MockTarget) FormatMetadata(metadata map[string]interface{}) string {
 	return ""
 }
 
-func (m *MockTarget) Preview(items []models.ItemInterface, outputDir string) ([]*interfaces.FilePreview, error) {
-	return nil, nil
+func (m *MockTarget) Plan(items []models.ItemInterface, outputDir string) (*interfaces.Plan, error) {
+	return &interfaces.Plan{OutputDir: outputDir}, nil
 }
 
 func TestSyncerWithTransformerPipeline(t *testing.T) {
@@ -0,0 +1,41 @@
+package sync
+
+import (
+	"time"
+
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+// FetchWindow fetches the items of a source from since up to, but excluding, until; a zero until fetches
+// everything since. Sources that fetch bounded windows are asked for the range directly, others are
+// fetched since and their later items dropped.
+func FetchWindow(source interfaces.Source, since, until time.Time, limit int) ([]models.ItemInterface, error) {
+	if until.IsZero() {
+		return source.Fetch(since, limit)
+	}
+
+	if fetcher, ok := source.(interfaces.RangeFetcher); ok {
+		return fetcher.FetchRange(since, until, limit)
+	}
+
+	items, err := source.Fetch(since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return FilterBefore(items, until), nil
+}
+
+// FilterBefore keeps the items created before until. Items without a creation time are kept.
+func FilterBefore(items []models.ItemInterface, until time.Time) []models.ItemInterface {
+	filtered := make([]models.ItemInterface, 0, len(items))
+
+	for _, item := range items {
+		if created := item.GetCreatedAt(); created.IsZero() || created.Before(until) {
+			filtered = append(filtered, item)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,61 @@
+package sync
+
+import (
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+// rangeSource is a mock source that fetches bounded windows itself.
+type rangeSource struct {
+	MockSource
+	since, until time.Time
+}
+
+func (r *rangeSource) FetchRange(since, until time.Time, limit int) ([]models.ItemInterface, error) {
+	r.since, r.until = since, until
+
+	return r.itemsToReturn, nil
+}
+
+func TestFetchWindow(t *testing.T) {
+	until := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	since := until.AddDate(-1, 0, 0)
+
+	undated := models.NewBasicItem("undated", "Undated")
+	undated.SetCreatedAt(time.Time{})
+
+	items := []models.ItemInterface{undated}
+
+	for i, created := range []time.Time{since, until.Add(-time.Second), until, until.AddDate(0, 1, 0)} {
+		item := models.NewBasicItem(string(rune('a'+i)), "Item")
+		item.SetCreatedAt(created)
+		items = append(items, item)
+	}
+
+	got, err := FetchWindow(&MockSource{itemsToReturn: items}, since, until, 10)
+	if err != nil {
+		t.Fatalf("FetchWindow() error = %v", err)
+	}
+
+	if len(got) != 3 {
+		t.Errorf("FetchWindow() kept %d items, want the undated item and the 2 created before until", len(got))
+	}
+
+	got, err = FetchWindow(&MockSource{itemsToReturn: items}, since, time.Time{}, 10)
+	if err != nil || len(got) != len(items) {
+		t.Errorf("FetchWindow() without until = %d items, %v; want all of them", len(got), err)
+	}
+
+	ranged := &rangeSource{MockSource: MockSource{itemsToReturn: items}}
+
+	got, err = FetchWindow(ranged, since, until, 10)
+	if err != nil || len(got) != len(items) {
+		t.Errorf("FetchWindow() of a range fetcher = %d items, %v; want its items unfiltered", len(got), err)
+	}
+
+	if !ranged.since.Equal(since) || !ranged.until.Equal(until) {
+		t.Errorf("FetchRange() called with %v to %v, want %v to %v", ranged.since, ranged.until, since, until)
+	}
+}
@@ -0,0 +1,132 @@
+package ical
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	icalDate     = "20060102"
+	icalDateTime = "20060102T150405Z"
+
+	// maxLineOctets is where RFC 5545 folds content lines.
+	maxLineOctets = 75
+)
+
+// entry is a VEVENT of the feed, as recorded in the entry index.
+type entry struct {
+	Summary     string    `json:"summary"`
+	Description string    `json:"description,omitempty"`
+	Location    string    `json:"location,omitempty"`
+	URL         string    `json:"url,omitempty"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	AllDay      bool      `json:"all_day,omitempty"`
+	Organizer   string    `json:"organizer,omitempty"` // Email address
+	Attendees   []string  `json:"attendees,omitempty"` // Email addresses
+	Categories  []string  `json:"categories,omitempty"`
+	Updated     time.Time `json:"updated,omitzero"`
+}
+
+// formatFeed renders entries as a VCALENDAR, ordered by start time so the feed only changes when its
+// entries do.
+func formatFeed(calendarName string, entries map[string]entry) string {
+	var sb strings.Builder
+
+	writeLine(&sb, "BEGIN:VCALENDAR")
+	writeLine(&sb, "VERSION:2.0")
+	writeLine(&sb, "PRODID:-//pkm-sync//pkm-sync//EN")
+	writeLine(&sb, "CALSCALE:GREGORIAN")
+	writeLine(&sb, "X-WR-CALNAME:"+escapeText(calendarName))
+
+	for _, uid := range sortedUIDs(entries) {
+		e := entries[uid]
+
+		writeLine(&sb, "BEGIN:VEVENT")
+		writeLine(&sb, "UID:"+escapeText(uid))
+
+		// DTSTAMP is required; the item's update time keeps it stable between syncs
+		stamp := e.Updated
+		if stamp.IsZero() {
+			stamp = e.Start
+		}
+
+		writeLine(&sb, "DTSTAMP:"+stamp.UTC().Format(icalDateTime))
+		writeEntryProperties(&sb, e)
+		writeLine(&sb, "END:VEVENT")
+	}
+
+	writeLine(&sb, "END:VCALENDAR")
+
+	return sb.String()
+}
+
+// writeEntryProperties writes the properties of an entry's VEVENT other than UID and DTSTAMP.
+func writeEntryProperties(sb *strings.Builder, e entry) {
+	if e.AllDay {
+		writeLine(sb, "DTSTART;VALUE=DATE:"+e.Start.Format(icalDate))
+		writeLine(sb, "DTEND;VALUE=DATE:"+e.End.Format(icalDate))
+	} else {
+		writeLine(sb, "DTSTART:"+e.Start.UTC().Format(icalDateTime))
+		writeLine(sb, "DTEND:"+e.End.UTC().Format(icalDateTime))
+	}
+
+	writeLine(sb, "SUMMARY:"+escapeText(e.Summary))
+
+	if e.Description != "" {
+		writeLine(sb, "DESCRIPTION:"+escapeText(e.Description))
+	}
+
+	if e.Location != "" {
+		writeLine(sb, "LOCATION:"+escapeText(e.Location))
+	}
+
+	if e.URL != "" {
+		writeLine(sb, "URL:"+e.URL)
+	}
+
+	if e.Organizer != "" {
+		writeLine(sb, "ORGANIZER:mailto:"+e.Organizer)
+	}
+
+	for _, attendee := range e.Attendees {
+		writeLine(sb, "ATTENDEE:mailto:"+attendee)
+	}
+
+	if len(e.Categories) > 0 {
+		categories := make([]string, 0, len(e.Categories))
+		for _, category := range e.Categories {
+			categories = append(categories, escapeText(category))
+		}
+
+		writeLine(sb, "CATEGORIES:"+strings.Join(categories, ","))
+	}
+}
+
+// escapeText escapes an iCalendar TEXT value.
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", "").Replace(s)
+}
+
+// writeLine writes a content line ending in CRLF, folded into lines of at most 75 octets without
+// splitting UTF-8 sequences.
+func writeLine(sb *strings.Builder, line string) {
+	limit := maxLineOctets
+
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		sb.WriteString(line[:cut])
+		sb.WriteString("\r\n ")
+
+		line = line[cut:]
+		limit = maxLineOctets - 1 // Continuation lines start with a space
+	}
+
+	sb.WriteString(line)
+	sb.WriteString("\r\n")
+}
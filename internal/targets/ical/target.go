@@ -0,0 +1,297 @@
+// Package ical implements a target that keeps an iCalendar (.ics) feed of synced calendar events and
+// tasks with a due date, so calendars filtered or merged by pkm-sync can be subscribed to from other apps.
+package ical
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"pkm-sync/internal/statestore"
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+const (
+	defaultFeedFile     = "calendar.ics"
+	defaultCalendarName = "pkm-sync"
+
+	// entryIndexFile keeps every entry of the feed, so each sync regenerates the whole feed from the
+	// items it fetched and those of earlier syncs.
+	entryIndexFile = ".pkm-sync-ical.json"
+)
+
+type ICalTarget struct {
+	feedFile     string // Relative to the output directory
+	calendarName string
+	skipTasks    bool // Leave tasks with a due date out of the feed
+}
+
+func NewICalTarget() *ICalTarget {
+	return &ICalTarget{
+		feedFile:     defaultFeedFile,
+		calendarName: defaultCalendarName,
+	}
+}
+
+func (t *ICalTarget) Name() string {
+	return "ical"
+}
+
+func (t *ICalTarget) Configure(config map[string]interface{}) error {
+	if feedFile, ok := config["feed_file"].(string); ok && feedFile != "" {
+		if filepath.IsAbs(feedFile) || strings.HasPrefix(filepath.Clean(feedFile), "..") {
+			return fmt.Errorf("feed_file must be relative to the output directory, got '%s'", feedFile)
+		}
+
+		t.feedFile = feedFile
+	}
+
+	if name, ok := config["calendar_name"].(string); ok && name != "" {
+		t.calendarName = name
+	}
+
+	if skipTasks, ok := config["skip_tasks"].(bool); ok {
+		t.skipTasks = skipTasks
+	}
+
+	return nil
+}
+
+// Export adds the events and due tasks among items to the feed's entries and rewrites the feed. Items
+// that are no longer feed entries, such as completed tasks, are removed from it.
+func (t *ICalTarget) Export(items []models.FullItem, outputDir string) error {
+	entries, content, err := t.render(items, outputDir)
+	if err != nil {
+		return err
+	}
+
+	feedPath := filepath.Join(outputDir, filepath.FromSlash(t.feedFile))
+	if err := os.MkdirAll(filepath.Dir(feedPath), 0755); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(feedPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write feed: %w", err)
+	}
+
+	return saveEntryIndex(outputDir, entries)
+}
+
+// Plan shows the feed as Export would write it.
+func (t *ICalTarget) Plan(items []models.FullItem, outputDir string) (*interfaces.Plan, error) {
+	_, content, err := t.render(items, outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	feedPath := filepath.Join(outputDir, filepath.FromSlash(t.feedFile))
+
+	action := "create"
+
+	existing, err := os.ReadFile(feedPath)
+	switch {
+	case err == nil && string(existing) == content:
+		action = "skip"
+	case err == nil:
+		action = "update"
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("failed to read existing feed: %w", err)
+	}
+
+	return &interfaces.Plan{OutputDir: outputDir, Changes: []*interfaces.FilePreview{{
+		FilePath:        feedPath,
+		Action:          action,
+		Content:         content,
+		ExistingContent: string(existing),
+	}}}, nil
+}
+
+// render merges items into the entries recorded by earlier syncs and renders the resulting feed.
+func (t *ICalTarget) render(items []models.FullItem, outputDir string) (map[string]entry, string, error) {
+	entries, err := loadEntryIndex(outputDir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, item := range items {
+		uid := entryUID(item)
+
+		if e, ok := t.toEntry(item); ok {
+			entries[uid] = e
+		} else {
+			delete(entries, uid)
+		}
+	}
+
+	return entries, formatFeed(t.calendarName, entries), nil
+}
+
+// entryUID identifies an item's entry across syncs and feed subscribers.
+func entryUID(item models.ItemInterface) string {
+	return item.GetSourceType() + "-" + item.GetID() + "@pkm-sync"
+}
+
+// toEntry returns the feed entry of a calendar event, or of a task with a due date, which becomes an
+// all-day event on that date.
+func (t *ICalTarget) toEntry(item models.ItemInterface) (entry, bool) {
+	metadata := models.Metadata(item.GetMetadata())
+
+	e := entry{
+		Summary:     item.GetTitle(),
+		Description: item.GetContent(),
+		Categories:  item.GetTags(),
+		Updated:     item.GetUpdatedAt(),
+	}
+
+	if location, ok := metadata.GetString("location"); ok {
+		e.Location = location
+	}
+
+	for _, link := range item.GetLinks() {
+		if link.URL != "" {
+			e.URL = link.URL
+
+			break
+		}
+	}
+
+	if start, ok := metadata.GetTime(models.MetadataStartTime); ok {
+		e.Start = start
+		e.End = start
+
+		if end, ok := metadata.GetTime(models.MetadataEndTime); ok && end.After(start) {
+			e.End = end
+		}
+
+		e.AllDay = isAllDay(e.Start, e.End)
+
+		if organizer, ok := metadata.GetAttendees(models.MetadataOrganizer); ok && len(organizer) > 0 {
+			e.Organizer = organizer[0].Email
+		}
+
+		attendees, _ := metadata.GetAttendees(models.MetadataAttendees)
+		for _, attendee := range attendees {
+			if attendee.Email != "" {
+				e.Attendees = append(e.Attendees, attendee.Email)
+			}
+		}
+
+		return e, true
+	}
+
+	if t.skipTasks {
+		return entry{}, false
+	}
+
+	due, ok := metadata.GetTime(models.MetadataDue)
+	if !ok {
+		return entry{}, false
+	}
+
+	if completed, _ := metadata.GetBool(models.MetadataCompleted); completed {
+		return entry{}, false
+	}
+
+	e.Start = time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, time.UTC)
+	e.End = e.Start.AddDate(0, 0, 1)
+	e.AllDay = true
+
+	return e, true
+}
+
+// isAllDay reports whether an event spans whole days: it starts and ends at midnight.
+func isAllDay(start, end time.Time) bool {
+	midnight := func(t time.Time) bool { return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 }
+
+	return end.After(start) && midnight(start) && midnight(end)
+}
+
+func loadEntryIndex(outputDir string) (map[string]entry, error) {
+	entries := make(map[string]entry)
+
+	data, err := statestore.ReadFile(filepath.Join(outputDir, entryIndexFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+
+		return nil, fmt.Errorf("failed to read feed index: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse feed index: %w", err)
+	}
+
+	return entries, nil
+}
+
+func saveEntryIndex(outputDir string, entries map[string]entry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode feed index: %w", err)
+	}
+
+	if err := statestore.WriteFile(filepath.Join(outputDir, entryIndexFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write feed index: %w", err)
+	}
+
+	return nil
+}
+
+func (t *ICalTarget) FormatFilename(title string) string {
+	return utils.SanitizeFilename(title) + t.GetFileExtension()
+}
+
+func (t *ICalTarget) GetFileExtension() string {
+	return ".ics"
+}
+
+// FormatMetadata renders metadata as the iCalendar properties of an event; unknown keys are skipped.
+func (t *ICalTarget) FormatMetadata(metadata map[string]interface{}) string {
+	e, ok := t.toEntry(metadataItem(metadata))
+	if !ok {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	writeEntryProperties(&sb, e)
+
+	return sb.String()
+}
+
+// metadataItem wraps bare metadata in an item for toEntry.
+func metadataItem(metadata map[string]interface{}) models.ItemInterface {
+	item := models.NewBasicItem("", "")
+	item.SetMetadata(metadata)
+
+	return item
+}
+
+// sortedUIDs returns the UIDs of entries by start time, then UID.
+func sortedUIDs(entries map[string]entry) []string {
+	uids := make([]string, 0, len(entries))
+	for uid := range entries {
+		uids = append(uids, uid)
+	}
+
+	sort.Slice(uids, func(i, j int) bool {
+		a, b := entries[uids[i]], entries[uids[j]]
+		if !a.Start.Equal(b.Start) {
+			return a.Start.Before(b.Start)
+		}
+
+		return uids[i] < uids[j]
+	})
+
+	return uids
+}
+
+// Ensure ICalTarget implements Target interface.
+var _ interfaces.Target = (*ICalTarget)(nil)
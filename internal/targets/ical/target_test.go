@@ -0,0 +1,152 @@
+package ical
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"pkm-sync/pkg/models"
+)
+
+func calendarEvent(id, title string, start, end time.Time) models.FullItem {
+	item := models.NewBasicItem(id, title)
+	item.SetSourceType("google_calendar")
+	item.SetItemType("event")
+	item.SetUpdatedAt(time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC))
+	item.SetMetadata(map[string]interface{}{
+		models.MetadataStartTime: start,
+		models.MetadataEndTime:   end,
+		"location":               "Room 4, 2nd floor",
+		models.MetadataOrganizer: models.Attendee{Email: "ada@example.com"},
+		models.MetadataAttendees: []models.Attendee{{Email: "grace@example.com"}, {DisplayName: "Room"}},
+	})
+
+	return item
+}
+
+func task(id, title, due string, completed bool) models.FullItem {
+	item := models.NewBasicItem(id, title)
+	item.SetSourceType("google_tasks")
+	item.SetItemType("task")
+	item.SetMetadata(map[string]interface{}{models.MetadataDue: due, models.MetadataCompleted: completed})
+
+	return item
+}
+
+func TestExportWritesFeed(t *testing.T) {
+	outputDir := t.TempDir()
+
+	target := NewICalTarget()
+	if err := target.Configure(map[string]interface{}{"calendar_name": "Work"}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	start := time.Date(2025, 3, 3, 15, 0, 0, 0, time.FixedZone("CET", 3600))
+	holiday := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	email := models.NewBasicItem("m1", "Not an event")
+
+	items := []models.FullItem{
+		calendarEvent("e1", "Planning; Q2, budget", start, start.Add(time.Hour)),
+		calendarEvent("e2", "Holiday", holiday, holiday.AddDate(0, 0, 1)),
+		task("t1", "Send report", "2025-03-05", false),
+		task("t2", "Done already", "2025-03-04", true),
+		email,
+	}
+
+	if err := target.Export(items, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "calendar.ics"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := string(data)
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"X-WR-CALNAME:Work\r\n",
+		"UID:google_calendar-e1@pkm-sync\r\n",
+		"DTSTART:20250303T140000Z\r\nDTEND:20250303T150000Z\r\n",
+		`SUMMARY:Planning\; Q2\, budget` + "\r\n",
+		`LOCATION:Room 4\, 2nd floor` + "\r\n",
+		"ORGANIZER:mailto:ada@example.com\r\n",
+		"ATTENDEE:mailto:grace@example.com\r\n",
+		"DTSTART;VALUE=DATE:20250310\r\nDTEND;VALUE=DATE:20250311\r\n",
+		"DTSTART;VALUE=DATE:20250305\r\nDTEND;VALUE=DATE:20250306\r\nSUMMARY:Send report\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(feed, want) {
+			t.Errorf("feed is missing %q:\n%s", want, feed)
+		}
+	}
+
+	for _, unwanted := range []string{"t2@pkm-sync", "Not an event", "mailto:Room"} {
+		if strings.Contains(feed, unwanted) {
+			t.Errorf("feed contains %q:\n%s", unwanted, feed)
+		}
+	}
+
+	// Events are ordered by start
+	if strings.Index(feed, "e1@pkm-sync") > strings.Index(feed, "t1@pkm-sync") ||
+		strings.Index(feed, "t1@pkm-sync") > strings.Index(feed, "e2@pkm-sync") {
+		t.Errorf("feed is not ordered by start:\n%s", feed)
+	}
+
+	// A later sync keeps earlier entries and drops tasks completed since
+	if err := target.Export([]models.FullItem{task("t1", "Send report", "2025-03-05", true)}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	data, _ = os.ReadFile(filepath.Join(outputDir, "calendar.ics"))
+	if feed := string(data); !strings.Contains(feed, "e1@pkm-sync") || strings.Contains(feed, "t1@pkm-sync") {
+		t.Errorf("second sync feed:\n%s", feed)
+	}
+
+	plan, err := target.Plan(nil, outputDir)
+	if err != nil || len(plan.Changes) != 1 || plan.Changes[0].Action != "skip" || len(plan.Writes()) != 0 {
+		t.Errorf("Plan() = %+v, %v; want an unchanged feed", plan, err)
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	target := NewICalTarget()
+	if err := target.Configure(map[string]interface{}{"feed_file": "feeds/work.ics", "skip_tasks": true}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	if _, ok := target.toEntry(task("t1", "Report", "2025-03-05", false)); ok {
+		t.Error("skip_tasks kept a task")
+	}
+
+	if err := target.Configure(map[string]interface{}{"feed_file": "../calendar.ics"}); err == nil {
+		t.Error("Configure() accepted a feed_file outside the output directory")
+	}
+}
+
+func TestWriteLineFolds(t *testing.T) {
+	var sb strings.Builder
+
+	writeLine(&sb, "DESCRIPTION:"+strings.Repeat("é", 60))
+
+	lines := strings.Split(strings.TrimSuffix(sb.String(), "\r\n"), "\r\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], " ") {
+		t.Fatalf("writeLine() = %q", sb.String())
+	}
+
+	for _, line := range lines {
+		if len(line) > maxLineOctets || !utf8.ValidString(line) {
+			t.Errorf("folded line %q is %d octets", line, len(line))
+		}
+	}
+
+	unfolded := strings.ReplaceAll(sb.String(), "\r\n ", "")
+	if unfolded != "DESCRIPTION:"+strings.Repeat("é", 60)+"\r\n" {
+		t.Errorf("unfolded line = %q", unfolded)
+	}
+}
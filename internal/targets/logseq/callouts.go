@@ -0,0 +1,74 @@
+package logseq
+
+import (
+	"regexp"
+	"strings"
+)
+
+// foldableCallout matches the first line of an Obsidian callout that can be folded, such as the
+// "> [!quote]- Previous messages" written by content_cleanup's quoted_text_mode: collapse.
+var foldableCallout = regexp.MustCompile(`^>\s*\[!(\w+)\]([+-])\s*(.*)$`)
+
+// contentSegment is a run of plain content lines or a foldable callout.
+type contentSegment struct {
+	lines     []string
+	callout   bool
+	title     string
+	collapsed bool
+}
+
+// splitCallouts separates foldable callouts from the rest of an item's content.
+func splitCallouts(content string) []contentSegment {
+	var segments []contentSegment
+
+	var current *contentSegment
+
+	for _, line := range strings.Split(content, "\n") {
+		if match := foldableCallout.FindStringSubmatch(line); match != nil {
+			title := strings.TrimSpace(match[3])
+			if title == "" {
+				title = match[1]
+			}
+
+			segments = append(segments, contentSegment{callout: true, title: title, collapsed: match[2] == "-"})
+			current = &segments[len(segments)-1]
+
+			continue
+		}
+
+		if current != nil && current.callout && strings.HasPrefix(line, ">") {
+			current.lines = append(current.lines, strings.TrimPrefix(strings.TrimPrefix(line, ">"), " "))
+
+			continue
+		}
+
+		if current == nil || current.callout {
+			segments = append(segments, contentSegment{})
+			current = &segments[len(segments)-1]
+		}
+
+		current.lines = append(current.lines, line)
+	}
+
+	return segments
+}
+
+// formatCalloutBlock renders a foldable callout as a block titled like the callout with each non-empty line
+// as a child block; folded callouts get the collapsed:: true property so Logseq shows them closed.
+func (l *LogseqTarget) formatCalloutBlock(depth int, segment contentSegment) string {
+	var sb strings.Builder
+
+	sb.WriteString(l.formatBlock(depth, segment.title))
+
+	if segment.collapsed {
+		sb.WriteString(l.indent(depth) + "  collapsed:: true\n")
+	}
+
+	for _, line := range segment.lines {
+		if strings.TrimSpace(line) != "" {
+			sb.WriteString(l.formatBlock(depth+1, strings.TrimRight(line, " \t")))
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,299 @@
+package logseq
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+// defaultJournalDateFormat matches Logseq's default journal page title ("Jan 15th, 2025").
+const defaultJournalDateFormat = "Jan 2nd, 2006"
+
+// formatJournalDate formats a date for a journal page title.
+// Go layouts have no ordinal day, so the "2nd" token is expanded to 1st, 2nd, 3rd, 4th, ...
+func formatJournalDate(t time.Time, layout string) string {
+	parts := strings.Split(layout, "2nd")
+	for i, part := range parts {
+		parts[i] = t.Format(part)
+	}
+
+	return strings.Join(parts, ordinalDay(t.Day()))
+}
+
+func ordinalDay(day int) string {
+	suffix := "th"
+
+	switch {
+	case day%100 >= 11 && day%100 <= 13:
+		// 11th, 12th, 13th
+	case day%10 == 1:
+		suffix = "st"
+	case day%10 == 2:
+		suffix = "nd"
+	case day%10 == 3:
+		suffix = "rd"
+	}
+
+	return fmt.Sprintf("%d%s", day, suffix)
+}
+
+// formatDate renders a date as a journal page reference, or as plain text when journal refs are disabled.
+func (l *LogseqTarget) formatDate(t time.Time) string {
+	date := formatJournalDate(t, l.journalDateFormat)
+
+	if l.createJournalRefs {
+		return "[[" + date + "]]"
+	}
+
+	return date
+}
+
+const (
+	exportModePages   = "pages"
+	exportModeJournal = "journal"
+
+	// journalFileFormat is Logseq's default journal file name layout (journals/2025_01_15.md).
+	journalFileFormat = "2006_01_02"
+)
+
+// journalFile returns the journal page path for a date.
+func (l *LogseqTarget) journalFile(outputDir string, t time.Time) string {
+	dir := filepath.Join(outputDir, "journals")
+	if l.journalPath != "" {
+		dir = l.journalPath
+	}
+
+	return filepath.Join(dir, t.Format(journalFileFormat)+l.GetFileExtension())
+}
+
+// groupByJournal groups items by the journal page of their creation date, in date order.
+func (l *LogseqTarget) groupByJournal(items []models.FullItem, outputDir string) ([]string, map[string][]models.FullItem) {
+	byPath := make(map[string][]models.FullItem)
+
+	for _, item := range items {
+		created := item.GetCreatedAt()
+		if created.IsZero() {
+			created = item.GetUpdatedAt()
+		}
+
+		path := l.journalFile(outputDir, created)
+		byPath[path] = append(byPath[path], item)
+	}
+
+	paths := make([]string, 0, len(byPath))
+	for path := range byPath {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	return paths, byPath
+}
+
+// formatJournalBlock renders an item as a single top-level journal block with nested content.
+func (l *LogseqTarget) formatJournalBlock(item models.ItemInterface) string {
+	var sb strings.Builder
+
+	heading := utils.EscapeMarkdown(item.GetTitle())
+	for _, tag := range item.GetTags() {
+		heading += " #" + tag
+	}
+
+	sb.WriteString(l.formatBlock(0, heading))
+
+	// Block properties sit on the lines directly after the block's first line
+	propertyIndent := "  "
+
+	for _, property := range [][2]string{
+		{l.propertyNames.Name("id"), item.GetID()},
+		{l.propertyNames.Name("source"), item.GetSourceType()},
+		{l.propertyNames.Name("type"), item.GetItemType()},
+	} {
+		if property[1] != "" {
+			sb.WriteString(propertyIndent + l.propertyPrefix + property[0] + ":: " + property[1] + "\n")
+		}
+	}
+
+	for _, line := range strings.Split(strings.TrimRight(l.FormatMetadata(item.GetMetadata()), "\n"), "\n") {
+		if line != "" {
+			sb.WriteString(propertyIndent + strings.TrimPrefix(line, "- ") + "\n")
+		}
+	}
+
+	if content := strings.TrimSpace(item.GetContent()); content != "" {
+		for _, segment := range splitCallouts(content) {
+			if segment.callout {
+				sb.WriteString(l.formatCalloutBlock(1, segment))
+
+				continue
+			}
+
+			text := strings.TrimSpace(strings.Join(segment.lines, "\n"))
+			if text == "" {
+				continue
+			}
+
+			lines := strings.Split(text, "\n")
+
+			sb.WriteString(l.formatBlock(1, lines[0]))
+
+			continuation := l.indent(1) + "  "
+			for _, line := range lines[1:] {
+				sb.WriteString(strings.TrimRight(continuation+line, " \t") + "\n")
+			}
+		}
+	}
+
+	for _, attachment := range item.GetAttachments() {
+		if attachment.URL != "" {
+			sb.WriteString(l.formatBlock(1, "["+attachment.Name+"]("+attachment.URL+")"))
+		} else {
+			sb.WriteString(l.formatBlock(1, "[["+attachment.Name+"]]"))
+		}
+	}
+
+	for _, link := range item.GetLinks() {
+		sb.WriteString(l.formatBlock(1, "["+link.Title+"]("+link.URL+")"))
+	}
+
+	return sb.String()
+}
+
+// mergeJournalBlocks replaces blocks for items already in the journal page and appends new ones.
+// Blocks are matched on their id property so re-syncs update in place instead of duplicating.
+func (l *LogseqTarget) mergeJournalBlocks(existing string, items []models.FullItem) string {
+	blocks := splitTopLevelBlocks(existing)
+
+	for _, item := range items {
+		block := l.formatJournalBlock(item)
+		marker := "  " + l.idProperty() + ":: " + item.GetID() + "\n"
+
+		replaced := false
+
+		for i, existingBlock := range blocks {
+			if strings.Contains(existingBlock, marker) {
+				blocks[i] = block
+				replaced = true
+
+				break
+			}
+		}
+
+		if !replaced {
+			blocks = append(blocks, block)
+		}
+	}
+
+	return strings.Join(blocks, "")
+}
+
+// splitTopLevelBlocks splits a page into its top-level blocks, each keeping its trailing newline.
+// Text before the first block (such as page properties) is kept as its own chunk.
+func splitTopLevelBlocks(content string) []string {
+	if content == "" {
+		return nil
+	}
+
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+
+	var blocks []string
+
+	var current strings.Builder
+
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "- ") && current.Len() > 0 {
+			blocks = append(blocks, current.String())
+			current.Reset()
+		}
+
+		current.WriteString(line)
+	}
+
+	if current.Len() > 0 {
+		blocks = append(blocks, current.String())
+	}
+
+	return blocks
+}
+
+// exportJournal appends items as blocks into the journal page of their date.
+func (l *LogseqTarget) exportJournal(items []models.FullItem, outputDir string) error {
+	paths, byPath := l.groupByJournal(items, outputDir)
+
+	for _, path := range paths {
+		existing, err := readPage(path)
+		if err != nil {
+			return err
+		}
+
+		content := l.mergeJournalBlocks(existing, byPath[path])
+		if content == existing {
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create journals folder: %w", err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write journal page %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// previewJournal generates previews for the journal pages that would change.
+func (l *LogseqTarget) previewJournal(items []models.FullItem, outputDir string) ([]*interfaces.FilePreview, error) {
+	paths, byPath := l.groupByJournal(items, outputDir)
+	previews := make([]*interfaces.FilePreview, 0, len(paths))
+
+	for _, path := range paths {
+		existing, err := readPage(path)
+		if err != nil {
+			return nil, err
+		}
+
+		content := l.mergeJournalBlocks(existing, byPath[path])
+
+		action, existingContent, err := determineFileAction(path, content)
+		if err != nil {
+			return nil, fmt.Errorf("could not determine action for %s: %w", path, err)
+		}
+
+		previews = append(previews, &interfaces.FilePreview{
+			FilePath:        path,
+			Action:          action,
+			Content:         content,
+			ExistingContent: existingContent,
+		})
+	}
+
+	return previews, nil
+}
+
+func readPage(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("failed to read page %s: %w", path, err)
+	}
+
+	return string(data), nil
+}
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
+	"pkm-sync/internal/utils"
 	"pkm-sync/pkg/interfaces"
 	"pkm-sync/pkg/models"
 )
@@ -14,10 +17,53 @@ type LogseqTarget struct {
 	graphPath   string
 	journalPath string
 	pagesPath   string
+
+	// Block formatting
+	propertyPrefix   string
+	blockIndentation int // Spaces per nesting level; 0 uses tabs like Logseq itself
+
+	// Names of the standard id, source, type, created and tags properties (property_names)
+	propertyNames utils.PropertyNames
+
+	// Pages written out of the items of an export, see SetProgress
+	progress interfaces.ProgressFunc
+
+	// Decides about pages that fail to be written, see SetItemErrorHandler
+	itemErrors interfaces.ItemErrorFunc
+
+	// Journal integration
+	createJournalRefs bool
+	journalDateFormat string
+
+	// "pages" writes one page per item; "journal" appends items to their day's journal page
+	exportMode string
+
+	// Filename policy; Logseq keeps spaces and Unicode in page names by default
+	filenamePolicy utils.FilenamePolicy
+	itemPolicies   map[string]utils.FilenamePolicy // Item ID -> policy of its source's filenames settings
+
+	// Folder routing for pages inside the output directory (item_folders, sync.subdir_format)
+	layout utils.OutputLayout
+
+	// Timezone dates and journal days are rendered in; nil keeps each item's zone
+	location *time.Location
 }
 
 func NewLogseqTarget() *LogseqTarget {
-	return &LogseqTarget{}
+	return &LogseqTarget{
+		createJournalRefs: true,
+		journalDateFormat: defaultJournalDateFormat,
+		exportMode:        exportModePages,
+		filenamePolicy:    defaultFilenamePolicy(),
+	}
+}
+
+func defaultFilenamePolicy() utils.FilenamePolicy {
+	policy := utils.DefaultFilenamePolicy()
+	policy.Style = utils.FilenameStylePreserve
+	policy.MaxLength = 0
+
+	return policy
 }
 
 func (l *LogseqTarget) Name() string {
@@ -31,24 +77,119 @@ func (l *LogseqTarget) Configure(config map[string]interface{}) error {
 		l.pagesPath = filepath.Join(graphPath, "pages")
 	}
 
+	policy, err := utils.ParseFilenamePolicy(config, l.filenamePolicy)
+	if err != nil {
+		return err
+	}
+
+	l.filenamePolicy = policy
+
+	layout, err := utils.ParseOutputLayout(config, l.layout)
+	if err != nil {
+		return err
+	}
+
+	l.layout = layout
+
+	location, err := utils.ParseTimezone(config)
+	if err != nil {
+		return err
+	}
+
+	l.location = location
+
+	if prefix, ok := config["property_prefix"].(string); ok {
+		l.propertyPrefix = prefix
+	}
+
+	if value, exists := config["property_names"]; exists && value != nil {
+		names, err := utils.ParsePropertyNames(value)
+		if err != nil {
+			return err
+		}
+
+		l.propertyNames = names
+	}
+
+	if indentation, ok := config["block_indentation"].(int); ok {
+		if indentation < 0 {
+			return fmt.Errorf("block_indentation must not be negative, got %d", indentation)
+		}
+
+		l.blockIndentation = indentation
+	}
+
+	if createJournalRefs, ok := config["create_journal_refs"].(bool); ok {
+		l.createJournalRefs = createJournalRefs
+	}
+
+	if format, ok := config["journal_date_format"].(string); ok && format != "" {
+		l.journalDateFormat = format
+	}
+
+	if mode, ok := config["export_mode"].(string); ok && mode != "" {
+		switch mode {
+		case exportModePages, exportModeJournal:
+			l.exportMode = mode
+		default:
+			return fmt.Errorf("unsupported export_mode '%s': supported modes are 'pages', 'journal'", mode)
+		}
+	}
+
 	return nil
 }
 
 func (l *LogseqTarget) Export(items []models.FullItem, outputDir string) error {
-	// Use flat structure - all files in outputDir
-	for _, item := range items {
-		if err := l.exportItem(item, outputDir); err != nil {
-			return fmt.Errorf("failed to export item %s: %w", item.GetID(), err)
+	utils.LocalizeItems(items, l.location)
+
+	if l.exportMode == exportModeJournal {
+		return l.exportJournal(items, outputDir)
+	}
+
+	allocator := l.newAllocator(outputDir)
+
+	for i, item := range items {
+		if l.progress != nil {
+			l.progress(i, len(items))
+		}
+
+		filePath := l.pagePath(allocator, item, outputDir)
+		if err := l.exportItem(item, filePath); err != nil {
+			if err := l.itemFailed(item, fmt.Errorf("failed to export item %s: %w", item.GetID(), err)); err != nil {
+				return err
+			}
 		}
 	}
 
+	if l.progress != nil {
+		l.progress(len(items), len(items))
+	}
+
 	return nil
 }
 
-func (l *LogseqTarget) exportItem(item models.FullItem, outputDir string) error {
-	filename := l.FormatFilename(item.GetTitle())
-	filePath := filepath.Join(outputDir, filename)
+// SetProgress reports the pages written during Export; journal exports, which write a page per day, don't
+// report progress.
+func (l *LogseqTarget) SetProgress(report interfaces.ProgressFunc) {
+	l.progress = report
+}
+
+// SetItemErrorHandler lets Export carry on past pages that fail to be written when handle allows it.
+func (l *LogseqTarget) SetItemErrorHandler(handle interfaces.ItemErrorFunc) {
+	l.itemErrors = handle
+}
 
+// itemFailed returns the error an item failed to export with, unless the item error handler lets the
+// export carry on.
+func (l *LogseqTarget) itemFailed(item models.FullItem, err error) error {
+	if l.itemErrors == nil {
+		return err
+	}
+
+	return l.itemErrors(item, err)
+}
+
+func (l *LogseqTarget) exportItem(item models.FullItem, filePath string) error {
 	// Create directory if needed
 	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
 		return err
@@ -63,19 +204,17 @@ func (l *LogseqTarget) formatContent(item models.ItemInterface) string {
 	var sb strings.Builder
 
 	// Properties block (Logseq-specific)
-	sb.WriteString("- id:: " + item.GetID() + "\n")
-	sb.WriteString("- source:: " + item.GetSourceType() + "\n")
-	sb.WriteString("- type:: " + item.GetItemType() + "\n")
-	sb.WriteString("- created:: [[" + item.GetCreatedAt().Format("Jan 2nd, 2006") + "]]\n")
+	sb.WriteString(l.formatProperty(l.propertyNames.Name("id"), item.GetID()))
+	sb.WriteString(l.formatProperty(l.propertyNames.Name("source"), item.GetSourceType()))
+	sb.WriteString(l.formatProperty(l.propertyNames.Name("type"), item.GetItemType()))
+	sb.WriteString(l.formatProperty(l.propertyNames.Name("created"), l.formatDate(item.GetCreatedAt())))
 
 	// Add custom metadata
-	for key, value := range item.GetMetadata() {
-		sb.WriteString(fmt.Sprintf("- %s:: %v\n", key, value))
-	}
+	sb.WriteString(l.FormatMetadata(item.GetMetadata()))
 
 	// Tags
 	if len(item.GetTags()) > 0 {
-		sb.WriteString("- tags:: ")
+		sb.WriteString("- " + l.propertyNames.Name("tags") + ":: ")
 
 		for i, tag := range item.GetTags() {
 			if i > 0 {
@@ -91,46 +230,126 @@ func (l *LogseqTarget) formatContent(item models.ItemInterface) string {
 	sb.WriteString("\n")
 
 	// Title as heading
-	sb.WriteString("# " + item.GetTitle() + "\n\n")
+	sb.WriteString("# " + utils.EscapeMarkdown(item.GetTitle()) + "\n\n")
 
-	// Content
+	// Content, with foldable callouts (such as collapsed quoted text) as collapsed blocks
 	if item.GetContent() != "" {
-		sb.WriteString(item.GetContent())
-		sb.WriteString("\n\n")
+		for _, segment := range splitCallouts(item.GetContent()) {
+			if segment.callout {
+				sb.WriteString(l.formatCalloutBlock(0, segment))
+			} else if text := strings.Join(segment.lines, "\n"); strings.TrimSpace(text) != "" {
+				sb.WriteString(strings.Trim(text, "\n"))
+				sb.WriteString("\n")
+			}
+		}
+
+		sb.WriteString("\n")
 	}
 
-	// Attachments as blocks
+	// Attachments as child blocks
 	if len(item.GetAttachments()) > 0 {
-		sb.WriteString("## Attachments\n")
+		sb.WriteString(l.formatBlock(0, "## Attachments"))
 
 		for _, attachment := range item.GetAttachments() {
 			if attachment.URL != "" {
-				sb.WriteString("- [" + attachment.Name + "](" + attachment.URL + ")\n")
+				sb.WriteString(l.formatBlock(1, "["+attachment.Name+"]("+attachment.URL+")"))
 			} else {
-				sb.WriteString("- [[" + attachment.Name + "]]\n")
+				sb.WriteString(l.formatBlock(1, "[["+attachment.Name+"]]"))
 			}
 		}
 
 		sb.WriteString("\n")
 	}
 
-	// Links as blocks
+	// Links as child blocks
 	if len(item.GetLinks()) > 0 {
-		sb.WriteString("## Links\n")
+		sb.WriteString(l.formatBlock(0, "## Links"))
 
 		for _, link := range item.GetLinks() {
-			sb.WriteString("- [" + link.Title + "](" + link.URL + ")\n")
+			sb.WriteString(l.formatBlock(1, "["+link.Title+"]("+link.URL+")"))
 		}
 	}
 
 	return sb.String()
 }
 
+// formatBlock renders a block at the given nesting depth.
+func (l *LogseqTarget) formatBlock(depth int, text string) string {
+	return l.indent(depth) + "- " + text + "\n"
+}
+
+// indent returns the leading whitespace for a block at the given nesting depth.
+func (l *LogseqTarget) indent(depth int) string {
+	unit := "\t"
+	if l.blockIndentation > 0 {
+		unit = strings.Repeat(" ", l.blockIndentation)
+	}
+
+	return strings.Repeat(unit, depth)
+}
+
+// formatProperty renders a property block, applying the configured property prefix.
+func (l *LogseqTarget) formatProperty(key, value string) string {
+	return l.formatBlock(0, l.propertyPrefix+key+":: "+value)
+}
+
+// idProperty returns the property pages and journal blocks record their item ID in.
+func (l *LogseqTarget) idProperty() string {
+	return l.propertyPrefix + l.propertyNames.Name("id")
+}
+
+// formatPropertyValue renders a metadata value, turning dates into journal references.
+func (l *LogseqTarget) formatPropertyValue(value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		return l.formatDate(v)
+	case *time.Time:
+		if v != nil {
+			return l.formatDate(*v)
+		}
+	case models.Contact:
+		// Signature contacts link to the person's page
+		return "[[" + v.Name + "]]"
+	}
+
+	return fmt.Sprintf("%v", value)
+}
+
 func (l *LogseqTarget) FormatFilename(title string) string {
 	// Logseq prefers page references format
-	filename := sanitizeFilename(title)
+	return l.filenamePolicy.Sanitize(title) + l.GetFileExtension()
+}
+
+// newAllocator creates the filename allocator for one export run.
+// Existing pages recording a different item ID are not overwritten, and pages the user renamed or moved
+// are found by their id property. Logseq's bak folder holds stale copies, so it is not searched.
+func (l *LogseqTarget) newAllocator(outputDir string) *utils.FilenameAllocator {
+	allocator := utils.NewFilenameAllocator(l.filenamePolicy)
+	allocator.ItemPolicies = l.itemPolicies
+	allocator.IDOf = func(path string) string {
+		return utils.ReadDeclaredID(path, l.idProperty())
+	}
+	allocator.LocateExisting(outputDir, l.GetFileExtension(), l.idProperty(), "bak")
+
+	return allocator
+}
+
+// SetItemFilenames names the pages of individual items with filenames settings layered over the target's,
+// e.g. those of the source that fetched them.
+func (l *LogseqTarget) SetItemFilenames(filenames map[string]models.FilenameConfig) error {
+	policies, err := utils.ItemPolicies(filenames, l.filenamePolicy)
+	if err != nil {
+		return err
+	}
+
+	l.itemPolicies = policies
 
-	return filename + l.GetFileExtension()
+	return nil
+}
+
+// pagePath allocates the page file for an item within its item type folder and subdirectory.
+func (l *LogseqTarget) pagePath(allocator *utils.FilenameAllocator, item models.ItemInterface, outputDir string) string {
+	return allocator.Allocate(l.layout.ItemDir(outputDir, item), item.GetTitle(), l.GetFileExtension(), item.GetID())
 }
 
 func (l *LogseqTarget) GetFileExtension() string {
@@ -138,21 +357,42 @@ func (l *LogseqTarget) GetFileExtension() string {
 }
 
 func (l *LogseqTarget) FormatMetadata(metadata map[string]interface{}) string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		// Only routes the page, see utils.OutputLayout.ItemFolder
+		if key != utils.FolderMetadataKey {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
 	var sb strings.Builder
-	for key, value := range metadata {
-		sb.WriteString(fmt.Sprintf("- %s:: %v\n", key, value))
+	for _, key := range keys {
+		sb.WriteString(l.formatProperty(key, l.formatPropertyValue(metadata[key])))
 	}
 
 	return sb.String()
 }
 
-// Preview generates a preview of what files would be created/modified without actually writing them.
-func (l *LogseqTarget) Preview(items []models.FullItem, outputDir string) ([]*interfaces.FilePreview, error) {
+// Plan works out which files Export would create or modify, without actually writing them.
+func (l *LogseqTarget) Plan(items []models.FullItem, outputDir string) (*interfaces.Plan, error) {
+	utils.LocalizeItems(items, l.location)
+
+	if l.exportMode == exportModeJournal {
+		previews, err := l.previewJournal(items, outputDir)
+		if err != nil {
+			return nil, err
+		}
+
+		return &interfaces.Plan{OutputDir: outputDir, Changes: previews}, nil
+	}
+
 	previews := make([]*interfaces.FilePreview, 0, len(items))
+	allocator := l.newAllocator(outputDir)
 
 	for _, item := range items {
-		filename := l.FormatFilename(item.GetTitle())
-		filePath := filepath.Join(outputDir, filename)
+		filePath := l.pagePath(allocator, item, outputDir)
 		content := l.formatContent(item)
 
 		action, existingContent, err := determineFileAction(filePath, content)
@@ -166,12 +406,13 @@ func (l *LogseqTarget) Preview(items []models.FullItem, outputDir string) ([]*in
 			Content:         content,
 			ExistingContent: existingContent,
 			Conflict:        false, // Simplified for Logseq
+			ItemID:          item.GetID(),
 		}
 
 		previews = append(previews, preview)
 	}
 
-	return previews, nil
+	return &interfaces.Plan{OutputDir: outputDir, Changes: previews}, nil
 }
 
 func determineFileAction(filePath, newContent string) (string, string, error) {
@@ -192,32 +433,8 @@ func determineFileAction(filePath, newContent string) (string, string, error) {
 	return "update", existingContent, nil
 }
 
-// sanitizeFilename removes or replaces characters that are invalid in filenames.
-func sanitizeFilename(filename string) string {
-	replacements := map[string]string{
-		"/":  "-",
-		"\\": "-",
-
-		":":  "-",
-		"*":  "",
-		"?":  "",
-		"\"": "",
-		"<":  "",
-		">":  "",
-		"|":  "-",
-	}
-
-	for old, new := range replacements {
-		filename = strings.ReplaceAll(filename, old, new)
-	}
-
-	filename = strings.TrimSpace(filename)
-	for strings.Contains(filename, "  ") {
-		filename = strings.ReplaceAll(filename, "  ", " ")
-	}
-
-	return filename
-}
-
 // Ensure LogseqTarget implements Target interface.
-var _ interfaces.Target = (*LogseqTarget)(nil)
+var (
+	_ interfaces.Target             = (*LogseqTarget)(nil)
+	_ interfaces.ItemFilenameTarget = (*LogseqTarget)(nil)
+)
@@ -0,0 +1,195 @@
+package logseq
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestFormatJournalDate(t *testing.T) {
+	tests := []struct {
+		day      int
+		layout   string
+		expected string
+	}{
+		{day: 1, layout: defaultJournalDateFormat, expected: "Jan 1st, 2025"},
+		{day: 2, layout: defaultJournalDateFormat, expected: "Jan 2nd, 2025"},
+		{day: 3, layout: defaultJournalDateFormat, expected: "Jan 3rd, 2025"},
+		{day: 11, layout: defaultJournalDateFormat, expected: "Jan 11th, 2025"},
+		{day: 22, layout: defaultJournalDateFormat, expected: "Jan 22nd, 2025"},
+		{day: 15, layout: "2006-01-02", expected: "2025-01-15"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			date := time.Date(2025, 1, tt.day, 9, 0, 0, 0, time.UTC)
+			if got := formatJournalDate(date, tt.layout); got != tt.expected {
+				t.Errorf("formatJournalDate() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatContentHonorsConfig(t *testing.T) {
+	target := NewLogseqTarget()
+	if err := target.Configure(map[string]interface{}{
+		"property_prefix":     "pkm-",
+		"block_indentation":   2,
+		"create_journal_refs": true,
+	}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	item := models.NewBasicItem("1", "Weekly sync")
+	item.SetCreatedAt(time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC))
+	item.SetTags([]string{"meeting"})
+	item.SetMetadata(map[string]interface{}{"start_time": time.Date(2025, 1, 16, 9, 0, 0, 0, time.UTC)})
+	item.SetLinks([]models.Link{{Title: "Agenda", URL: "https://example.com"}})
+
+	content := target.formatContent(item)
+
+	for _, want := range []string{
+		"- pkm-id:: 1\n",
+		"- pkm-created:: [[Jan 15th, 2025]]\n",
+		"- pkm-start_time:: [[Jan 16th, 2025]]\n",
+		"- tags:: #meeting\n",
+		"- ## Links\n  - [Agenda](https://example.com)\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("content missing %q:\n%s", want, content)
+		}
+	}
+
+	if err := target.Configure(map[string]interface{}{"create_journal_refs": false, "block_indentation": 0}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	content = target.formatContent(item)
+	if !strings.Contains(content, "- pkm-created:: Jan 15th, 2025\n") || !strings.Contains(content, "\t- [Agenda]") {
+		t.Errorf("expected plain dates and tab indentation:\n%s", content)
+	}
+}
+
+func TestFormatContentPropertyNames(t *testing.T) {
+	target := NewLogseqTarget()
+	if err := target.Configure(map[string]interface{}{
+		"property_prefix": "pkm-",
+		"property_names":  map[string]interface{}{"source": "origin", "tags": "topics"},
+	}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	item := models.NewBasicItem("1", "Weekly sync")
+	item.SetSourceType("google_calendar")
+	item.SetTags([]string{"meeting"})
+
+	content := target.formatContent(item)
+
+	for _, want := range []string{"- pkm-id:: 1\n", "- pkm-origin:: google_calendar\n", "- topics:: #meeting\n"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("content missing %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestJournalExportMode(t *testing.T) {
+	outputDir := t.TempDir()
+
+	target := NewLogseqTarget()
+	if err := target.Configure(map[string]interface{}{"export_mode": "journal", "block_indentation": 2}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	item := models.NewBasicItem("evt-1", "Weekly sync")
+	item.SetCreatedAt(time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC))
+	item.SetSourceType("google_calendar")
+	item.SetContent("Agenda\nNotes")
+
+	journal := filepath.Join(outputDir, "journals", "2025_01_15.md")
+	if err := os.MkdirAll(filepath.Dir(journal), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(journal, []byte("- Morning thoughts\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := target.Export([]models.FullItem{item}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	// Re-sync with changed content updates the block in place
+	item.SetContent("Agenda\nUpdated notes")
+
+	if err := target.Export([]models.FullItem{item}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	data, err := os.ReadFile(journal)
+	if err != nil {
+		t.Fatalf("journal page not written: %v", err)
+	}
+
+	expected := "- Morning thoughts\n" +
+		"- Weekly sync\n" +
+		"  id:: evt-1\n" +
+		"  source:: google_calendar\n" +
+		"  - Agenda\n" +
+		"    Updated notes\n"
+	if string(data) != expected {
+		t.Errorf("journal page =\n%q\nwant\n%q", string(data), expected)
+	}
+}
+
+func TestExportSubdirFormat(t *testing.T) {
+	outputDir := t.TempDir()
+
+	target := NewLogseqTarget()
+	if err := target.Configure(map[string]interface{}{"subdir_format": "yyyy/mm"}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	item := models.NewBasicItem("msg-1", "Quarterly plan")
+	item.SetCreatedAt(time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC))
+
+	if err := target.Export([]models.FullItem{item}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "2025", "03", "Quarterly plan.md")); err != nil {
+		t.Errorf("page not written to dated subdirectory: %v", err)
+	}
+
+	if err := target.Configure(map[string]interface{}{"subdir_format": "weekly"}); err == nil {
+		t.Error("expected error for unsupported subdir_format")
+	}
+}
+
+func TestCollapsedCalloutsBecomeCollapsedBlocks(t *testing.T) {
+	target := NewLogseqTarget()
+	if err := target.Configure(map[string]interface{}{"block_indentation": 2}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	item := models.NewBasicItem("1", "Re: Lunch")
+	item.SetItemType("email")
+	item.SetContent("Sure!\n\n> [!quote]- Previous messages\n> On Mon, John wrote:\n>\n> > Lunch?")
+
+	content := target.formatContent(item)
+
+	want := "Sure!\n- Previous messages\n  collapsed:: true\n  - On Mon, John wrote:\n  - > Lunch?\n"
+	if !strings.Contains(content, want) {
+		t.Errorf("page content missing %q:\n%s", want, content)
+	}
+
+	block := target.formatJournalBlock(item)
+
+	want = "  - Sure!\n  - Previous messages\n    collapsed:: true\n    - On Mon, John wrote:\n    - > Lunch?\n"
+	if !strings.Contains(block, want) {
+		t.Errorf("journal block missing %q:\n%s", want, block)
+	}
+}
@@ -0,0 +1,142 @@
+package obsidian
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+// manifestHashLength is how many hex digits of an attachment's SHA-256 the manifest shows.
+const manifestHashLength = 12
+
+// attachmentManifestPath returns the path of the attachment_manifest note.
+func (o *ObsidianTarget) attachmentManifestPath(outputDir string) string {
+	return filepath.Join(outputDir, o.filenamePolicy.Sanitize(o.attachmentManifest)+o.GetFileExtension())
+}
+
+// attachmentManifestEntries returns, per source type, a line for every stored attachment of the items: its
+// link, size, the note it belongs to and its content hash. Attachments that were rejected or have no content
+// are left out.
+func (o *ObsidianTarget) attachmentManifestEntries(items []models.FullItem) map[string][]string {
+	entries := make(map[string][]string)
+
+	if o.attachments == nil {
+		return entries
+	}
+
+	for _, item := range items {
+		source := item.GetSourceType()
+		if source == "" {
+			source = "unknown"
+		}
+
+		for _, attachment := range itemAttachments(item) {
+			data, ok := decodeAttachment(attachment)
+			if !ok {
+				continue
+			}
+
+			hash := hashContent(data)
+
+			relPath, stored := o.attachments.index[hash]
+			if !stored {
+				continue
+			}
+
+			entry := fmt.Sprintf("- %s · %s · %s · `%s`", o.formatFileLink(relPath, attachment.Name),
+				utils.FormatSize(int64(len(data))), o.formatNoteLink(o.noteName(item), item.GetTitle()),
+				hash[:manifestHashLength])
+			if !containsString(entries[source], entry) {
+				entries[source] = append(entries[source], entry)
+			}
+		}
+	}
+
+	return entries
+}
+
+// attachmentManifestContent adds the entries to the manifest note under a heading per source. Entries of
+// earlier syncs stay, so the note lists every attachment saved into the vault.
+func (o *ObsidianTarget) attachmentManifestContent(existing string, entries map[string][]string) string {
+	content := existing
+	if content == "" {
+		content = "# " + o.attachmentManifest + "\n"
+	}
+
+	sources := make([]string, 0, len(entries))
+	for source := range entries {
+		sources = append(sources, source)
+	}
+
+	sort.Strings(sources)
+
+	for _, source := range sources {
+		content = mergeLinksUnderHeading(content, "## "+source, entries[source])
+	}
+
+	return content
+}
+
+// updateAttachmentManifest lists the attachments stored for the exported items in the manifest note.
+func (o *ObsidianTarget) updateAttachmentManifest(items []models.FullItem, outputDir string) error {
+	entries := o.attachmentManifestEntries(items)
+	if len(entries) == 0 {
+		return nil
+	}
+
+	path := o.attachmentManifestPath(outputDir)
+
+	existing, err := readOptionalFile(path)
+	if err != nil {
+		return err
+	}
+
+	content := o.attachmentManifestContent(existing, entries)
+	if content == existing {
+		return nil
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// previewAttachmentManifest previews the manifest note update, or returns nil when no attachment is stored.
+func (o *ObsidianTarget) previewAttachmentManifest(items []models.FullItem, outputDir string,
+) (*interfaces.FilePreview, error) {
+	entries := o.attachmentManifestEntries(items)
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	path := o.attachmentManifestPath(outputDir)
+
+	existing, err := readOptionalFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	content := o.attachmentManifestContent(existing, entries)
+
+	action := "create"
+	if existing != "" {
+		action = "update"
+		if content == existing {
+			action = "skip"
+		}
+	}
+
+	return &interfaces.FilePreview{
+		FilePath:        path,
+		Action:          action,
+		Content:         content,
+		ExistingContent: existing,
+	}, nil
+}
@@ -0,0 +1,76 @@
+package obsidian
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestExportAttachmentManifest(t *testing.T) {
+	outputDir := t.TempDir()
+
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{
+		"download_attachments": true,
+		"attachment_manifest":  "Attachment Manifest",
+	}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	report := base64.StdEncoding.EncodeToString([]byte("%PDF quarterly report"))
+
+	email := models.NewBasicItem("1", "Q3 numbers")
+	email.SetSourceType("gmail")
+	email.SetAttachments([]models.Attachment{{Name: "report.pdf", Data: report}, {Name: "link-only.pdf"}})
+
+	doc := models.NewBasicItem("2", "Board pack")
+	doc.SetSourceType("google_drive")
+	doc.SetAttachments([]models.Attachment{{Name: "report.pdf", Data: report}})
+
+	if err := target.Export([]models.FullItem{email, doc}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	path := filepath.Join(outputDir, "Attachment-Manifest.md")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("manifest not written: %v", err)
+	}
+
+	content := string(data)
+	hash := hashContent([]byte("%PDF quarterly report"))[:manifestHashLength]
+
+	for _, want := range []string{
+		"# Attachment Manifest\n",
+		"## gmail\n\n- [[Attachments/report.pdf]] · 21B · [[Q3-numbers|Q3 numbers]] · `" + hash + "`\n",
+		"## google_drive\n\n- [[Attachments/report.pdf]] · 21B · [[Board-pack|Board pack]] · `" + hash + "`\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("manifest missing %q:\n%s", want, content)
+		}
+	}
+
+	if strings.Contains(content, "link-only.pdf") {
+		t.Errorf("manifest lists an attachment that was not stored:\n%s", content)
+	}
+
+	// A later sync keeps the earlier entries and adds none twice
+	if err := target.Export([]models.FullItem{email}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	if again, _ := os.ReadFile(path); string(again) != content {
+		t.Errorf("manifest changed on re-sync:\n%s", again)
+	}
+}
+
+func TestConfigureAttachmentManifestNeedsDownloads(t *testing.T) {
+	if err := NewObsidianTarget().Configure(map[string]interface{}{"attachment_manifest": "Manifest"}); err == nil {
+		t.Error("Configure() expected error without download_attachments")
+	}
+}
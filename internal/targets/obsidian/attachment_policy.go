@@ -0,0 +1,205 @@
+package obsidian
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/models"
+)
+
+// attachmentPolicy decides which attachments and downloaded images may be written to the vault
+// (attachment_policy).
+type attachmentPolicy struct {
+	allow   []string // Lowercase extensions without the dot; empty allows every extension
+	deny    []string
+	maxSize int64  // Bytes; 0 for no limit
+	command string // Scanner run on each file; a non-zero exit rejects it
+
+	// Quotas in bytes (0 for no limit): files written per run, and the whole attachment folder
+	maxRunSize    int64
+	maxFolderSize int64
+}
+
+// parseAttachmentPolicy reads attachment_policy from a models.AttachmentPolicyConfig or a map with the same
+// keys. An empty policy returns nil.
+func parseAttachmentPolicy(value interface{}) (*attachmentPolicy, error) {
+	var config models.AttachmentPolicyConfig
+
+	switch v := value.(type) {
+	case models.AttachmentPolicyConfig:
+		config = v
+	case map[string]interface{}:
+		for key, target := range map[string]*[]string{
+			"allow_extensions": &config.AllowExtensions,
+			"deny_extensions":  &config.DenyExtensions,
+		} {
+			if list, exists := v[key]; exists && list != nil {
+				extensions, err := configStringList("attachment_policy."+key, list)
+				if err != nil {
+					return nil, err
+				}
+
+				*target = extensions
+			}
+		}
+
+		config.MaxSize, _ = v["max_size"].(string)
+		config.MaxRunSize, _ = v["max_run_size"].(string)
+		config.MaxFolderSize, _ = v["max_folder_size"].(string)
+		config.Command, _ = v["command"].(string)
+	default:
+		return nil, fmt.Errorf("attachment_policy must be a map, got %T", value)
+	}
+
+	maxSize, err := utils.ParseSize(config.MaxSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attachment_policy max_size '%s': use a size such as '10MB', '512KB' or a byte count",
+			config.MaxSize)
+	}
+
+	policy := &attachmentPolicy{
+		allow:   normalizeExtensions(config.AllowExtensions),
+		deny:    normalizeExtensions(config.DenyExtensions),
+		maxSize: maxSize,
+		command: strings.TrimSpace(config.Command),
+	}
+
+	for key, quota := range map[string]struct {
+		raw    string
+		target *int64
+	}{
+		"max_run_size":    {config.MaxRunSize, &policy.maxRunSize},
+		"max_folder_size": {config.MaxFolderSize, &policy.maxFolderSize},
+	} {
+		size, err := utils.ParseSize(quota.raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid attachment_policy %s '%s': use a size such as '1GB', '500MB' or a byte count",
+				key, quota.raw)
+		}
+
+		*quota.target = size
+	}
+
+	if len(policy.allow) == 0 && len(policy.deny) == 0 && policy.maxSize == 0 && policy.command == "" &&
+		policy.maxRunSize == 0 && policy.maxFolderSize == 0 {
+		return nil, nil
+	}
+
+	return policy, nil
+}
+
+// normalizeExtensions lowercases extensions and drops their leading dots, so ".PDF" and "pdf" match alike.
+func normalizeExtensions(extensions []string) []string {
+	normalized := make([]string, 0, len(extensions))
+
+	for _, extension := range extensions {
+		if extension = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(extension), ".")); extension != "" {
+			normalized = append(normalized, extension)
+		}
+	}
+
+	return normalized
+}
+
+// check returns why a file may not be written to the vault, or nil when it passes every rule. The scanner
+// command only runs for files the built-in rules allow.
+func (p *attachmentPolicy) check(name string, data []byte) error {
+	if p == nil {
+		return nil
+	}
+
+	extension := strings.ToLower(strings.TrimPrefix(filepath.Ext(name), "."))
+
+	if slices.Contains(p.deny, extension) {
+		return fmt.Errorf("extension '%s' is denied", extension)
+	}
+
+	if len(p.allow) > 0 && !slices.Contains(p.allow, extension) {
+		return fmt.Errorf("extension '%s' is not in allow_extensions", extension)
+	}
+
+	if p.maxSize > 0 && int64(len(data)) > p.maxSize {
+		return fmt.Errorf("size %s exceeds max_size %s", utils.FormatSize(int64(len(data))), utils.FormatSize(p.maxSize))
+	}
+
+	if p.command != "" {
+		return p.scan(name, data)
+	}
+
+	return nil
+}
+
+// checkQuota returns why a file of size bytes may not be written once written bytes were written this run
+// and the attachment folder holds folderSize bytes, or nil when both quotas leave room for it.
+func (p *attachmentPolicy) checkQuota(size, written, folderSize int64) error {
+	if p == nil {
+		return nil
+	}
+
+	if p.maxRunSize > 0 && written+size > p.maxRunSize {
+		return fmt.Errorf("%s written this run would exceed max_run_size %s",
+			utils.FormatSize(written+size), utils.FormatSize(p.maxRunSize))
+	}
+
+	if p.maxFolderSize > 0 && folderSize+size > p.maxFolderSize {
+		return fmt.Errorf("attachment folder of %s would exceed max_folder_size %s",
+			utils.FormatSize(folderSize+size), utils.FormatSize(p.maxFolderSize))
+	}
+
+	return nil
+}
+
+// scan writes the file to a temporary directory and runs the policy command on it through the shell like
+// sync hooks, with PKM_SYNC_ATTACHMENT (its path), PKM_SYNC_ATTACHMENT_NAME and PKM_SYNC_ATTACHMENT_SIZE set.
+func (p *attachmentPolicy) scan(name string, data []byte) error {
+	dir, err := os.MkdirTemp("", "pkm-sync-attachment-")
+	if err != nil {
+		return fmt.Errorf("failed to stage attachment for scanning: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Base(name)
+	if fileName == "." || fileName == string(filepath.Separator) || name == "" {
+		fileName = "attachment"
+	}
+
+	path := filepath.Join(dir, fileName)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to stage attachment for scanning: %w", err)
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", p.command)
+	} else {
+		cmd = exec.Command("sh", "-c", p.command)
+	}
+
+	var output bytes.Buffer
+
+	cmd.Env = append(os.Environ(),
+		"PKM_SYNC_ATTACHMENT="+path,
+		"PKM_SYNC_ATTACHMENT_NAME="+name,
+		"PKM_SYNC_ATTACHMENT_SIZE="+strconv.Itoa(len(data)),
+	)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	if err := cmd.Run(); err != nil {
+		if detail := strings.TrimSpace(output.String()); detail != "" {
+			return fmt.Errorf("attachment_policy command rejected it: %w: %s", err, detail)
+		}
+
+		return fmt.Errorf("attachment_policy command rejected it: %w", err)
+	}
+
+	return nil
+}
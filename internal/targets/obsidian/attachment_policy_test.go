@@ -0,0 +1,174 @@
+package obsidian
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestAttachmentPolicyCheck(t *testing.T) {
+	policy, err := parseAttachmentPolicy(models.AttachmentPolicyConfig{
+		AllowExtensions: []string{".PDF", "png", "exe"},
+		DenyExtensions:  []string{"exe"},
+		MaxSize:         "1KB",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		file   string
+		size   int
+		reason string
+	}{
+		{"allowed", "report.pdf", 10, ""},
+		{"extension case is ignored", "Chart.PNG", 10, ""},
+		{"denied wins over allowed", "setup.exe", 10, "extension 'exe' is denied"},
+		{"not allowed", "notes.docx", 10, "extension 'docx' is not in allow_extensions"},
+		{"too large", "scan.pdf", 2048, "size 2KB exceeds max_size 1KB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.check(tt.file, make([]byte, tt.size))
+			if tt.reason == "" && err != nil {
+				t.Errorf("check() = %v, want allowed", err)
+			}
+
+			if tt.reason != "" && (err == nil || err.Error() != tt.reason) {
+				t.Errorf("check() = %v, want %q", err, tt.reason)
+			}
+		})
+	}
+
+	if empty, err := parseAttachmentPolicy(map[string]interface{}{}); err != nil || empty != nil {
+		t.Errorf("empty policy = %+v, %v, want nil", empty, err)
+	}
+
+	if _, err := parseAttachmentPolicy(map[string]interface{}{"max_size": "huge"}); err == nil {
+		t.Error("invalid max_size accepted")
+	}
+}
+
+func TestExportRejectsAttachmentsFailingTheScanner(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("scanner command uses sh")
+	}
+
+	outputDir := t.TempDir()
+
+	// The scanner flags files containing EICAR and only sees the staged copy's name
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{
+		"download_attachments": true,
+		"attachment_policy": map[string]interface{}{
+			"command": `test "$PKM_SYNC_ATTACHMENT_NAME" = "$(basename "$PKM_SYNC_ATTACHMENT")" && ! grep -q EICAR "$PKM_SYNC_ATTACHMENT"`,
+		},
+	}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	item := models.NewBasicItem("1", "Invoice")
+	item.SetAttachments([]models.Attachment{
+		{Name: "invoice.pdf", Data: base64.StdEncoding.EncodeToString([]byte("%PDF invoice"))},
+		{Name: "invoice.zip", Data: base64.StdEncoding.EncodeToString([]byte("X5O EICAR test file"))},
+	})
+
+	if err := target.Export([]models.FullItem{item}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "Attachments", "invoice.pdf")); err != nil {
+		t.Errorf("clean attachment not stored: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "Attachments", "invoice.zip")); !os.IsNotExist(err) {
+		t.Errorf("rejected attachment written to the vault: %v", err)
+	}
+
+	note, err := os.ReadFile(filepath.Join(outputDir, "Invoice.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(note), "[[Attachments/invoice.pdf]]") || strings.Contains(string(note), "Attachments/invoice.zip") {
+		t.Errorf("note links:\n%s", note)
+	}
+}
+
+func TestExportStopsWritingAttachmentsAtQuota(t *testing.T) {
+	encode := func(size int) string {
+		return base64.StdEncoding.EncodeToString(make([]byte, size))
+	}
+
+	tests := []struct {
+		name     string
+		policy   map[string]interface{}
+		existing int // Bytes already in the attachment folder
+	}{
+		{"run quota", map[string]interface{}{"max_run_size": "20B"}, 0},
+		{"folder quota", map[string]interface{}{"max_folder_size": "120B"}, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+
+			if tt.existing > 0 {
+				if err := os.MkdirAll(filepath.Join(outputDir, "Attachments"), 0755); err != nil {
+					t.Fatal(err)
+				}
+
+				if err := os.WriteFile(filepath.Join(outputDir, "Attachments", "old.bin"),
+					make([]byte, tt.existing), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			target := NewObsidianTarget()
+			if err := target.Configure(map[string]interface{}{
+				"download_attachments": true,
+				"attachment_policy":    tt.policy,
+			}); err != nil {
+				t.Fatalf("Configure() error = %v", err)
+			}
+
+			item := models.NewBasicItem("1", "Scans")
+			item.SetAttachments([]models.Attachment{
+				{Name: "first.pdf", Data: encode(12)},
+				{Name: "second.pdf", Data: encode(15), URL: "https://mail.example/second.pdf"},
+				{Name: "third.pdf", Data: encode(3)}, // Would fit, but the quota was already reached
+			})
+
+			if err := target.Export([]models.FullItem{item}, outputDir); err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+
+			for name, stored := range map[string]bool{"first.pdf": true, "second.pdf": false, "third.pdf": false} {
+				_, err := os.Stat(filepath.Join(outputDir, "Attachments", name))
+				if stored != (err == nil) {
+					t.Errorf("%s stored = %v, want %v", name, err == nil, stored)
+				}
+			}
+
+			note, err := os.ReadFile(filepath.Join(outputDir, "Scans.md"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(string(note), "[second.pdf](https://mail.example/second.pdf)") {
+				t.Errorf("attachment over the quota should link to its source:\n%s", note)
+			}
+		})
+	}
+
+	if _, err := parseAttachmentPolicy(map[string]interface{}{"max_run_size": "lots"}); err == nil {
+		t.Error("invalid max_run_size accepted")
+	}
+}
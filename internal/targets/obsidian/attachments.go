@@ -0,0 +1,333 @@
+package obsidian
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"pkm-sync/internal/statestore"
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+const (
+	defaultAttachmentFolder = "Attachments"
+
+	// attachmentIndexFile maps content hashes to stored files so identical attachments are written once.
+	attachmentIndexFile = ".pkm-sync-attachments.json"
+)
+
+// attachmentStore stores attachment content once per hash in the attachment folder.
+type attachmentStore struct {
+	dir    string            // Absolute attachment folder
+	index  map[string]string // Content hash -> path relative to the output directory
+	dirty  bool
+	policy utils.FilenamePolicy
+	rules  *attachmentPolicy // attachment_policy; checked before new content is written
+
+	// planned holds files that would be written; used for previews instead of touching disk.
+	planned map[string][]byte
+	dryRun  bool
+
+	// companions maps stored attachment paths to their converted companion notes (convert_attachments).
+	companions map[string]string
+
+	// Bytes written this run and held by the attachment folder, checked against the policy's quotas
+	written      int64
+	folderSize   int64
+	quotaReached bool // The quota warning was logged
+}
+
+// openAttachmentStore loads the attachment index for an output directory.
+func (o *ObsidianTarget) openAttachmentStore(outputDir string, dryRun bool) (*attachmentStore, error) {
+	store := &attachmentStore{
+		dir:        filepath.Join(outputDir, o.attachmentFolder),
+		index:      make(map[string]string),
+		planned:    make(map[string][]byte),
+		dryRun:     dryRun,
+		policy:     o.filenamePolicy,
+		rules:      o.attachmentPolicy,
+		companions: make(map[string]string),
+	}
+
+	if store.rules != nil && store.rules.maxFolderSize > 0 {
+		store.folderSize = folderSize(store.dir)
+	}
+
+	data, err := statestore.ReadFile(filepath.Join(store.dir, attachmentIndexFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+
+		return nil, fmt.Errorf("failed to read attachment index: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &store.index); err != nil {
+		return nil, fmt.Errorf("failed to parse attachment index: %w", err)
+	}
+
+	return store, nil
+}
+
+// decodeAttachment returns the raw bytes of an attachment's base64 data.
+func decodeAttachment(attachment models.Attachment) ([]byte, bool) {
+	if attachment.Data == "" {
+		return nil, false
+	}
+
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if data, err := encoding.DecodeString(attachment.Data); err == nil {
+			return data, true
+		}
+	}
+
+	return nil, false
+}
+
+func hashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// store writes the attachment if its content has not been stored before and returns its relative path.
+// Attachments rejected by the attachment policy, or left over once one of its quotas is reached, are not
+// written and return an empty path.
+func (s *attachmentStore) store(attachment models.Attachment, outputDir string) (string, error) {
+	data, ok := decodeAttachment(attachment)
+	if !ok {
+		return "", nil
+	}
+
+	hash := hashContent(data)
+
+	if relPath, exists := s.index[hash]; exists {
+		if _, planned := s.planned[relPath]; planned {
+			return relPath, nil
+		}
+
+		if _, err := os.Stat(filepath.Join(outputDir, relPath)); err == nil {
+			return relPath, nil
+		}
+	}
+
+	if err := s.rules.check(attachment.Name, data); err != nil {
+		slog.Warn("Rejected attachment", "attachment", attachment.Name, "reason", err)
+
+		return "", nil
+	}
+
+	// Once a quota is reached no further files are written this run, however small
+	if !s.quotaReached {
+		if err := s.rules.checkQuota(int64(len(data)), s.written, s.folderSize); err != nil {
+			slog.Warn("Attachment quota reached; further attachments link to their source", "reason", err)
+
+			s.quotaReached = true
+		}
+	}
+
+	if s.quotaReached {
+		return "", nil
+	}
+
+	name := s.policy.Sanitize(strings.TrimSuffix(attachment.Name, filepath.Ext(attachment.Name))) +
+		filepath.Ext(attachment.Name)
+	if attachment.Name == "" {
+		name = hash[:12]
+	}
+
+	path := filepath.Join(s.dir, name)
+
+	// The same file is already on disk but missing from the index (e.g. the index was deleted)
+	if existing, err := os.ReadFile(path); err == nil && hashContent(existing) == hash {
+		return s.remember(hash, path, outputDir)
+	}
+
+	// Another attachment already uses this name; keep both by suffixing a short hash
+	if s.nameTaken(path, outputDir) {
+		ext := filepath.Ext(name)
+		path = filepath.Join(s.dir, strings.TrimSuffix(name, ext)+"-"+hash[:8]+ext)
+	}
+
+	if s.dryRun {
+		if relPath, err := filepath.Rel(outputDir, path); err == nil {
+			s.planned[filepath.ToSlash(relPath)] = data
+		}
+	} else {
+		if err := os.MkdirAll(s.dir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create attachment folder: %w", err)
+		}
+
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return "", fmt.Errorf("failed to write attachment %s: %w", path, err)
+		}
+	}
+
+	s.written += int64(len(data))
+	s.folderSize += int64(len(data))
+
+	return s.remember(hash, path, outputDir)
+}
+
+// folderSize returns the bytes held by the files under dir; a missing folder holds none.
+func folderSize(dir string) int64 {
+	var size int64
+
+	_ = filepath.WalkDir(dir, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+
+		if info, err := entry.Info(); err == nil {
+			size += info.Size()
+		}
+
+		return nil
+	})
+
+	return size
+}
+
+// remember records the stored path for a content hash.
+func (s *attachmentStore) remember(hash, path, outputDir string) (string, error) {
+	relPath, err := filepath.Rel(outputDir, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve attachment path: %w", err)
+	}
+
+	relPath = filepath.ToSlash(relPath)
+	s.index[hash] = relPath
+	s.dirty = true
+
+	return relPath, nil
+}
+
+func (s *attachmentStore) nameTaken(path, outputDir string) bool {
+	if relPath, err := filepath.Rel(outputDir, path); err == nil {
+		if _, planned := s.planned[filepath.ToSlash(relPath)]; planned {
+			return true
+		}
+	}
+
+	_, err := os.Stat(path)
+
+	return err == nil
+}
+
+// lookup returns the stored path for an attachment's content.
+func (s *attachmentStore) lookup(attachment models.Attachment) (string, bool) {
+	data, ok := decodeAttachment(attachment)
+	if !ok {
+		return "", false
+	}
+
+	relPath, exists := s.index[hashContent(data)]
+
+	return relPath, exists
+}
+
+// save persists the attachment index.
+func (s *attachmentStore) save() error {
+	if !s.dirty || s.dryRun {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode attachment index: %w", err)
+	}
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create attachment folder: %w", err)
+	}
+
+	return statestore.WriteFile(filepath.Join(s.dir, attachmentIndexFile), data, 0644)
+}
+
+// storeAttachments stores the attachments of all items, including thread messages.
+func (o *ObsidianTarget) storeAttachments(store *attachmentStore, items []models.FullItem, outputDir string) error {
+	for _, item := range items {
+		for _, attachment := range itemAttachments(item) {
+			if _, err := store.store(attachment, outputDir); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// prepareAttachments opens the attachment store and stores attachments, their converted companions and
+// remote images before notes are rendered. Previews do not download images, so their notes keep the remote
+// links.
+func (o *ObsidianTarget) prepareAttachments(items []models.FullItem, outputDir string, dryRun bool) error {
+	store, err := o.openAttachmentStore(outputDir, dryRun)
+	if err != nil {
+		return err
+	}
+
+	if o.downloadAttachments {
+		if err := o.storeAttachments(store, items, outputDir); err != nil {
+			return err
+		}
+
+		if o.converter != nil {
+			if err := o.convertAttachments(store, items, outputDir); err != nil {
+				return err
+			}
+		}
+	}
+
+	if o.downloadImages && !dryRun {
+		if err := o.storeImages(store, items, outputDir); err != nil {
+			return err
+		}
+	}
+
+	o.attachments = store
+
+	return store.save()
+}
+
+// previewAttachments lists the attachment files a preview would create.
+func (o *ObsidianTarget) previewAttachments(outputDir string) []*interfaces.FilePreview {
+	if o.attachments == nil {
+		return nil
+	}
+
+	paths := make([]string, 0, len(o.attachments.planned))
+	for relPath := range o.attachments.planned {
+		paths = append(paths, relPath)
+	}
+
+	sort.Strings(paths)
+
+	previews := make([]*interfaces.FilePreview, 0, len(paths))
+
+	for _, relPath := range paths {
+		data := o.attachments.planned[relPath]
+
+		content := fmt.Sprintf("(binary attachment, %d bytes)", len(data))
+		if o.attachments.isCompanion(relPath) {
+			content = string(data)
+		}
+
+		previews = append(previews, &interfaces.FilePreview{
+			FilePath: filepath.Join(outputDir, filepath.FromSlash(relPath)),
+			Action:   "create",
+			Content:  content,
+		})
+	}
+
+	return previews
+}
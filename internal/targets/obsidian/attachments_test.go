@@ -0,0 +1,67 @@
+package obsidian
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestExportDeduplicatesAttachments(t *testing.T) {
+	outputDir := t.TempDir()
+
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{"download_attachments": true}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	report := base64.StdEncoding.EncodeToString([]byte("%PDF quarterly report"))
+	other := base64.StdEncoding.EncodeToString([]byte("%PDF something else"))
+
+	first := models.NewBasicItem("1", "First email")
+	first.SetAttachments([]models.Attachment{{Name: "report.pdf", Data: report}})
+
+	second := models.NewBasicItem("2", "Second email")
+	second.SetAttachments([]models.Attachment{
+		{Name: "report (1).pdf", Data: report},
+		{Name: "report.pdf", Data: other},
+	})
+
+	if err := target.Export([]models.FullItem{first, second}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(outputDir, "Attachments"))
+	if err != nil {
+		t.Fatalf("attachment folder not created: %v", err)
+	}
+
+	var files []string
+
+	for _, entry := range entries {
+		if entry.Name() != attachmentIndexFile {
+			files = append(files, entry.Name())
+		}
+	}
+
+	if len(files) != 2 {
+		t.Fatalf("expected 2 stored attachments (one per distinct content), got %v", files)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "Second-email.md"))
+	if err != nil {
+		t.Fatalf("note not written: %v", err)
+	}
+
+	content := string(data)
+	if !strings.Contains(content, "[[Attachments/report.pdf|report (1).pdf]]") {
+		t.Errorf("duplicate attachment should link to the first copy:\n%s", content)
+	}
+
+	if !strings.Contains(content, "[[Attachments/report-") {
+		t.Errorf("distinct file with the same name should get a hash suffix:\n%s", content)
+	}
+}
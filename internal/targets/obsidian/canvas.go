@@ -0,0 +1,332 @@
+package obsidian
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+const (
+	defaultCanvasFolder = "Canvas"
+	canvasExtension     = ".canvas"
+
+	canvasCardWidth   = 400
+	canvasCardHeight  = 240
+	canvasCardSpacing = 80
+	canvasTagColumns  = 4
+
+	// Messages longer than this are truncated on their card; the full text lives in the note.
+	canvasExcerptLength = 500
+)
+
+// canvasDocument is the JSON Canvas format used by Obsidian (.canvas files).
+type canvasDocument struct {
+	Nodes []canvasNode `json:"nodes"`
+	Edges []canvasEdge `json:"edges"`
+}
+
+type canvasNode struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"` // "text" or "file"
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Text   string `json:"text,omitempty"`
+	File   string `json:"file,omitempty"`
+}
+
+type canvasEdge struct {
+	ID       string `json:"id"`
+	FromNode string `json:"fromNode"`
+	FromSide string `json:"fromSide"`
+	ToNode   string `json:"toNode"`
+	ToSide   string `json:"toSide"`
+}
+
+// canvasFile is a canvas that should be written to disk.
+type canvasFile struct {
+	path     string
+	document canvasDocument
+}
+
+// canvasID derives a stable node/edge ID so re-syncs don't rewrite unchanged canvases.
+func canvasID(parts ...string) string {
+	h := fnv.New64a()
+	h.Write([]byte(strings.Join(parts, "\x00")))
+
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// connect appends an edge between two nodes.
+func (d *canvasDocument) connect(from, to, fromSide, toSide string) {
+	d.Edges = append(d.Edges, canvasEdge{
+		ID:       canvasID("edge", from, to),
+		FromNode: from,
+		FromSide: fromSide,
+		ToNode:   to,
+		ToSide:   toSide,
+	})
+}
+
+// collectCanvases builds the thread and tag canvases for the exported items.
+func (o *ObsidianTarget) collectCanvases(items []models.FullItem, outputDir string) []canvasFile {
+	var canvases []canvasFile
+
+	if o.canvasThreads {
+		for _, item := range items {
+			if thread, ok := models.AsThread(item); ok && len(thread.GetMessages()) > 0 {
+				canvases = append(canvases, canvasFile{
+					path:     o.canvasPath(outputDir, item.GetTitle()),
+					document: o.buildThreadCanvas(thread, outputDir),
+				})
+			}
+		}
+	}
+
+	for _, tag := range o.canvasTags {
+		var tagged []models.FullItem
+
+		for _, item := range items {
+			if containsString(item.GetTags(), tag) || containsString(o.noteTags(item), tag) {
+				tagged = append(tagged, item)
+			}
+		}
+
+		if len(tagged) > 0 {
+			path := o.canvasPath(outputDir, strings.ReplaceAll(tag, "/", "-"))
+			canvases = append(canvases, canvasFile{
+				path:     path,
+				document: o.buildTagCanvas(tag, tagged, outputDir, readCanvas(path)),
+			})
+		}
+	}
+
+	return canvases
+}
+
+func (o *ObsidianTarget) canvasPath(outputDir, name string) string {
+	return filepath.Join(outputDir, o.canvasFolder, o.filenamePolicy.Sanitize(name)+canvasExtension)
+}
+
+// notePath returns the vault-relative path of an item's note, as Obsidian expects in file nodes.
+func (o *ObsidianTarget) notePath(item models.ItemInterface, outputDir string) string {
+	notePath := o.itemPath(item, outputDir)
+
+	root := outputDir
+	if o.vaultPath != "" {
+		root = o.vaultPath
+	}
+
+	if rel, err := filepath.Rel(root, notePath); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+
+	return filepath.ToSlash(filepath.Base(notePath))
+}
+
+// buildThreadCanvas lays out a thread's messages as a chain of cards hanging off the thread note.
+func (o *ObsidianTarget) buildThreadCanvas(thread *models.Thread, outputDir string) canvasDocument {
+	doc := canvasDocument{Nodes: []canvasNode{}, Edges: []canvasEdge{}}
+
+	rootID := canvasID("note", thread.GetID())
+	doc.Nodes = append(doc.Nodes, canvasNode{
+		ID:     rootID,
+		Type:   "file",
+		Width:  canvasCardWidth,
+		Height: canvasCardHeight,
+		File:   o.notePath(thread, outputDir),
+	})
+
+	previous := rootID
+
+	for i, message := range thread.GetMessages() {
+		id := canvasID("message", thread.GetID(), message.GetID(), fmt.Sprintf("%d", i))
+		doc.Nodes = append(doc.Nodes, canvasNode{
+			ID:     id,
+			Type:   "text",
+			X:      0,
+			Y:      (i + 1) * (canvasCardHeight + canvasCardSpacing),
+			Width:  canvasCardWidth,
+			Height: canvasCardHeight,
+			Text:   formatCanvasMessage(i+1, message),
+		})
+		doc.connect(previous, id, "bottom", "top")
+		previous = id
+	}
+
+	return doc
+}
+
+// readCanvas reads a canvas written by an earlier sync; a missing or unreadable canvas reads as empty.
+func readCanvas(path string) canvasDocument {
+	var doc canvasDocument
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return doc
+	}
+
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return canvasDocument{}
+	}
+
+	return doc
+}
+
+// buildTagCanvas lays out all items sharing a tag in a chronological grid under a tag card. The cards of
+// earlier syncs in existing are kept in their order, with this sync's items updating their own card and new
+// items added after them.
+func (o *ObsidianTarget) buildTagCanvas(tag string, items []models.FullItem, outputDir string,
+	existing canvasDocument,
+) canvasDocument {
+	doc := canvasDocument{Nodes: []canvasNode{}, Edges: []canvasEdge{}}
+
+	sorted := make([]models.FullItem, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].GetCreatedAt().Before(sorted[j].GetCreatedAt())
+	})
+
+	tagID := canvasID("tag", tag)
+	doc.Nodes = append(doc.Nodes, canvasNode{
+		ID:     tagID,
+		Type:   "text",
+		Width:  canvasCardWidth,
+		Height: canvasCardHeight / 2,
+		Text:   "# #" + tag,
+	})
+
+	cards := make(map[string]canvasNode, len(sorted))
+	order := make([]string, 0, len(existing.Nodes)+len(sorted))
+
+	for _, node := range existing.Nodes {
+		if node.ID != tagID && node.Type == "file" {
+			cards[node.ID] = node
+			order = append(order, node.ID)
+		}
+	}
+
+	for _, item := range sorted {
+		id := canvasID("note", tag, item.GetID())
+		if _, exists := cards[id]; !exists {
+			order = append(order, id)
+		}
+
+		cards[id] = canvasNode{ID: id, Type: "file", File: o.notePath(item, outputDir)}
+	}
+
+	for i, id := range order {
+		card := cards[id]
+		column, row := i%canvasTagColumns, i/canvasTagColumns
+		card.X = column * (canvasCardWidth + canvasCardSpacing)
+		card.Y = canvasCardHeight/2 + canvasCardSpacing + row*(canvasCardHeight+canvasCardSpacing)
+		card.Width, card.Height = canvasCardWidth, canvasCardHeight
+
+		doc.Nodes = append(doc.Nodes, card)
+		doc.connect(tagID, id, "bottom", "top")
+	}
+
+	return doc
+}
+
+// formatCanvasMessage renders the markdown shown on a message card.
+func formatCanvasMessage(messageNum int, message models.ItemInterface) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("### %d. %s\n", messageNum, utils.EscapeMarkdown(message.GetTitle())))
+
+	if from, exists := message.GetMetadata()["from"]; exists && from != nil {
+		sb.WriteString(fmt.Sprintf("**From:** %s  \n", toString(from)))
+	}
+
+	if !message.GetCreatedAt().IsZero() {
+		sb.WriteString(fmt.Sprintf("**Date:** %s\n", message.GetCreatedAt().Format(obsidianDateTimeFormat)))
+	}
+
+	if content := strings.TrimSpace(message.GetContent()); content != "" {
+		if runes := []rune(content); len(runes) > canvasExcerptLength {
+			content = string(runes[:canvasExcerptLength]) + "…"
+		}
+
+		sb.WriteString("\n" + content + "\n")
+	}
+
+	return sb.String()
+}
+
+func marshalCanvas(doc canvasDocument) (string, error) {
+	data, err := json.MarshalIndent(doc, "", "\t")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode canvas: %w", err)
+	}
+
+	return string(data) + "\n", nil
+}
+
+// writeCanvases writes thread and tag canvases for the exported items.
+func (o *ObsidianTarget) writeCanvases(items []models.FullItem, outputDir string) error {
+	for _, canvas := range o.collectCanvases(items, outputDir) {
+		content, err := marshalCanvas(canvas.document)
+		if err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(canvas.path), 0755); err != nil {
+			return fmt.Errorf("failed to create canvas folder: %w", err)
+		}
+
+		if err := os.WriteFile(canvas.path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write canvas %s: %w", canvas.path, err)
+		}
+	}
+
+	return nil
+}
+
+// previewCanvases generates previews for canvases that would be written.
+func (o *ObsidianTarget) previewCanvases(items []models.FullItem, outputDir string) ([]*interfaces.FilePreview, error) {
+	var previews []*interfaces.FilePreview
+
+	for _, canvas := range o.collectCanvases(items, outputDir) {
+		content, err := marshalCanvas(canvas.document)
+		if err != nil {
+			return nil, err
+		}
+
+		var existingContent string
+
+		if data, err := os.ReadFile(canvas.path); err == nil {
+			existingContent = string(data)
+		}
+
+		action := "create"
+		if existingContent != "" {
+			action = "update"
+			if content == existingContent {
+				action = "skip"
+			}
+		}
+
+		previews = append(previews, &interfaces.FilePreview{
+			FilePath:        canvas.path,
+			Action:          action,
+			Content:         content,
+			ExistingContent: existingContent,
+		})
+	}
+
+	return previews, nil
+}
+
+func (o *ObsidianTarget) canvasEnabled() bool {
+	return o.canvasThreads || len(o.canvasTags) > 0
+}
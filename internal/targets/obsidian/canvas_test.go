@@ -0,0 +1,131 @@
+package obsidian
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestExportWritesThreadCanvas(t *testing.T) {
+	outputDir := t.TempDir()
+
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{"canvas_threads": true}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	start := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	thread := models.NewThread("thread-1", "Budget discussion")
+	thread.SetSourceType("gmail")
+
+	for i, title := range []string{"Budget", "Re: Budget", "Re: Re: Budget"} {
+		message := models.NewBasicItem(title, title)
+		message.SetCreatedAt(start.Add(time.Duration(i) * time.Hour))
+		message.SetContent("message body")
+		thread.AddMessage(message)
+	}
+
+	if err := target.Export([]models.FullItem{thread}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "Canvas", "Budget-discussion.canvas"))
+	if err != nil {
+		t.Fatalf("canvas not written: %v", err)
+	}
+
+	var doc canvasDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("canvas is not valid JSON: %v", err)
+	}
+
+	if len(doc.Nodes) != 4 || len(doc.Edges) != 3 {
+		t.Fatalf("expected 4 nodes and 3 edges, got %d nodes and %d edges", len(doc.Nodes), len(doc.Edges))
+	}
+
+	if doc.Nodes[0].Type != "file" || doc.Nodes[0].File != "Budget-discussion.md" {
+		t.Errorf("first node should link to the thread note, got %+v", doc.Nodes[0])
+	}
+
+	for i, edge := range doc.Edges {
+		if edge.FromNode != doc.Nodes[i].ID || edge.ToNode != doc.Nodes[i+1].ID {
+			t.Errorf("edge %d does not connect consecutive cards: %+v", i, edge)
+		}
+	}
+}
+
+func TestTagCanvas(t *testing.T) {
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{"canvas_tags": []interface{}{"project/apollo"}}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	tagged := models.NewBasicItem("1", "Kickoff")
+	tagged.SetTags([]string{"project/apollo"})
+
+	other := models.NewBasicItem("2", "Lunch")
+
+	canvases := target.collectCanvases([]models.FullItem{tagged, other}, "/vault")
+	if len(canvases) != 1 {
+		t.Fatalf("expected 1 canvas, got %d", len(canvases))
+	}
+
+	if canvases[0].path != filepath.Join("/vault", "Canvas", "project-apollo.canvas") {
+		t.Errorf("unexpected canvas path %s", canvases[0].path)
+	}
+
+	if len(canvases[0].document.Nodes) != 2 {
+		t.Errorf("expected tag card plus one note, got %d nodes", len(canvases[0].document.Nodes))
+	}
+}
+
+func TestTagCanvasKeepsCardsOfEarlierSyncs(t *testing.T) {
+	outputDir := t.TempDir()
+
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{"canvas_tags": []interface{}{"apollo"}}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	note := func(id, title string, day int) models.FullItem {
+		item := models.NewBasicItem(id, title)
+		item.SetTags([]string{"apollo"})
+		item.SetCreatedAt(time.Date(2025, 1, day, 9, 0, 0, 0, time.UTC))
+
+		return item
+	}
+
+	// The second sync only fetches what changed since the first
+	for _, items := range [][]models.FullItem{
+		{note("1", "Kickoff", 10), note("2", "Design review", 12)},
+		{note("2", "Design review", 12), note("3", "Launch", 20)},
+	} {
+		if err := target.Export(items, outputDir); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+	}
+
+	canvas := readCanvas(filepath.Join(outputDir, "Canvas", "apollo.canvas"))
+
+	var files []string
+
+	for _, node := range canvas.Nodes {
+		if node.Type == "file" {
+			files = append(files, node.File)
+		}
+	}
+
+	want := []string{"Kickoff.md", "Design-review.md", "Launch.md"}
+	if strings.Join(files, ",") != strings.Join(want, ",") {
+		t.Errorf("canvas cards = %v, want %v", files, want)
+	}
+
+	if len(canvas.Edges) != len(want) {
+		t.Errorf("canvas has %d edges, want one per card", len(canvas.Edges))
+	}
+}
@@ -0,0 +1,219 @@
+package obsidian
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"pkm-sync/internal/statestore"
+	"pkm-sync/pkg/models"
+)
+
+const (
+	// changelogIndexFile keeps a fingerprint of each synced item and the changes recorded for it, so notes of
+	// items edited at the source list what changed instead of being silently replaced.
+	changelogIndexFile = ".pkm-sync-changelog.json"
+
+	changelogHeading = "## Changelog"
+)
+
+// trackedChanges is what the changelog index records about an item: a hash of each of its fields as of the
+// last sync, and the changes seen so far, oldest first.
+type trackedChanges struct {
+	Fields  map[string]string `json:"fields"`
+	Entries []changelogEntry  `json:"entries,omitempty"`
+}
+
+// changelogEntry lists the fields of an item that changed in the syncs of a day.
+type changelogEntry struct {
+	Date    string   `json:"date"` // "2006-01-02"
+	Changes []string `json:"changes"`
+}
+
+// loadChangelogIndex reads the changelog index of an output directory.
+func loadChangelogIndex(outputDir string) (map[string]trackedChanges, error) {
+	index := make(map[string]trackedChanges)
+
+	data, err := statestore.ReadFile(filepath.Join(outputDir, changelogIndexFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return index, nil
+		}
+
+		return nil, fmt.Errorf("failed to read changelog index: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse changelog index: %w", err)
+	}
+
+	return index, nil
+}
+
+// prepareChangelog loads the changelog index and records the changes of items synced before, dated
+// today, before notes are rendered. Items seen for the first time only get their fingerprint recorded.
+func (o *ObsidianTarget) prepareChangelog(items []models.FullItem, outputDir string) error {
+	index, err := loadChangelogIndex(outputDir)
+	if err != nil {
+		return err
+	}
+
+	o.changes = index
+	today := o.formatDateTime(time.Now(), "2006-01-02")
+
+	for _, item := range items {
+		fields := o.changelogFields(item)
+
+		tracked, exists := o.changes[item.GetID()]
+		if exists {
+			if changed := changedFields(tracked.Fields, fields); len(changed) > 0 {
+				tracked.Entries = addChangelogEntry(tracked.Entries, today, changed)
+			}
+		}
+
+		tracked.Fields = fields
+		o.changes[item.GetID()] = tracked
+	}
+
+	return nil
+}
+
+// changelogFields hashes the fields of an item that are compared between syncs: its title, content, tags,
+// attachments, links, thread messages and each metadata key but those in changelog_ignore.
+func (o *ObsidianTarget) changelogFields(item models.ItemInterface) map[string]string {
+	fields := map[string]string{
+		"title":       fieldHash(item.GetTitle()),
+		"description": fieldHash(item.GetContent()),
+		"tags":        fieldHash(item.GetTags()),
+		"attachments": fieldHash(item.GetAttachments()),
+		"links":       fieldHash(item.GetLinks()),
+	}
+
+	if thread, ok := models.AsThread(item); ok {
+		ids := make([]string, 0, len(thread.GetMessages()))
+		for _, message := range thread.GetMessages() {
+			ids = append(ids, message.GetID())
+		}
+
+		fields["messages"] = fieldHash(ids)
+	}
+
+	for key, value := range item.GetMetadata() {
+		// Added by the target itself, and reported by the property anyway
+		if key == rescheduledFromKey || containsString(o.changelogIgnore, key) {
+			continue
+		}
+
+		fields[strings.ReplaceAll(key, "_", " ")] = fieldHash(value)
+	}
+
+	return fields
+}
+
+// fieldHash returns a short hash of a field's JSON encoding.
+func fieldHash(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		data = []byte(fmt.Sprint(value))
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:8])
+}
+
+// changedFields returns the sorted names of fields that differ between two fingerprints, including fields
+// that were added or removed.
+func changedFields(before, after map[string]string) []string {
+	var changed []string
+
+	for name, hash := range after {
+		if before[name] != hash {
+			changed = append(changed, name)
+		}
+	}
+
+	for name := range before {
+		if _, exists := after[name]; !exists {
+			changed = append(changed, name)
+		}
+	}
+
+	sort.Strings(changed)
+
+	return changed
+}
+
+// addChangelogEntry records changes on date, merging them into the last entry when it is of the same day.
+func addChangelogEntry(entries []changelogEntry, date string, changes []string) []changelogEntry {
+	if n := len(entries); n > 0 && entries[n-1].Date == date {
+		for _, change := range changes {
+			if !containsString(entries[n-1].Changes, change) {
+				entries[n-1].Changes = append(entries[n-1].Changes, change)
+			}
+		}
+
+		sort.Strings(entries[n-1].Changes)
+
+		return entries
+	}
+
+	return append(entries, changelogEntry{Date: date, Changes: changes})
+}
+
+// addChangelog appends the changelog section of an item that changed since it was first synced, e.g.
+// "- Updated 2024-06-02: description changed".
+func (o *ObsidianTarget) addChangelog(item models.ItemInterface, content string) string {
+	if !o.changelog {
+		return content
+	}
+
+	entries := o.changes[item.GetID()].Entries
+	if len(entries) == 0 {
+		return content
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(strings.TrimRight(content, "\n"))
+	sb.WriteString("\n\n" + changelogHeading + "\n\n")
+
+	for _, entry := range entries {
+		sb.WriteString(fmt.Sprintf("- Updated %s: %s changed\n", entry.Date, joinChanges(entry.Changes)))
+	}
+
+	return sb.String()
+}
+
+// joinChanges lists changed fields as "title", "title and description" or "title, location and description".
+func joinChanges(changes []string) string {
+	if len(changes) < 2 {
+		return strings.Join(changes, "")
+	}
+
+	return strings.Join(changes[:len(changes)-1], ", ") + " and " + changes[len(changes)-1]
+}
+
+// saveChangelogIndex persists the changelog index.
+func (o *ObsidianTarget) saveChangelogIndex(outputDir string) error {
+	if len(o.changes) == 0 {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(o.changes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode changelog index: %w", err)
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	return statestore.WriteFile(filepath.Join(outputDir, changelogIndexFile), data, 0644)
+}
@@ -0,0 +1,82 @@
+package obsidian
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestExportAppendsChangelog(t *testing.T) {
+	outputDir := t.TempDir()
+	target := NewObsidianTarget()
+
+	if err := target.Configure(map[string]interface{}{
+		"changelog":        true,
+		"changelog_ignore": []string{"synced_at"},
+	}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	ticket := func(description, status, syncedAt string) models.FullItem {
+		item := models.NewBasicItem("PROJ-7", "Fix login")
+		item.SetContent(description)
+		item.SetMetadata(map[string]interface{}{"status": status, "synced_at": syncedAt})
+
+		return item
+	}
+
+	export := func(item models.FullItem) string {
+		t.Helper()
+
+		if err := target.Export([]models.FullItem{item}, outputDir); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(outputDir, "Fix-login.md"))
+		if err != nil {
+			t.Fatalf("note missing: %v", err)
+		}
+
+		return string(data)
+	}
+
+	if content := export(ticket("Login fails", "open", "1")); strings.Contains(content, changelogHeading) {
+		t.Errorf("new note should have no changelog:\n%s", content)
+	}
+
+	if content := export(ticket("Login fails", "open", "2")); strings.Contains(content, changelogHeading) {
+		t.Errorf("ignored metadata should not be recorded as a change:\n%s", content)
+	}
+
+	export(ticket("Login fails on Safari", "open", "3"))
+	content := export(ticket("Login fails on Safari", "in progress", "4"))
+
+	today := time.Now().Format("2006-01-02")
+	want := changelogHeading + "\n\n- Updated " + today + ": description and status changed\n"
+
+	if !strings.HasSuffix(content, want) {
+		t.Errorf("changelog of the day's changes missing, want suffix %q:\n%s", want, content)
+	}
+
+	if !strings.Contains(content, "Login fails on Safari") {
+		t.Errorf("note content not updated:\n%s", content)
+	}
+}
+
+func TestAddChangelogEntry(t *testing.T) {
+	entries := addChangelogEntry(nil, "2024-06-01", []string{"title"})
+	entries = addChangelogEntry(entries, "2024-06-02", []string{"description"})
+	entries = addChangelogEntry(entries, "2024-06-02", []string{"description", "attendees"})
+
+	if len(entries) != 2 || strings.Join(entries[1].Changes, ",") != "attendees,description" {
+		t.Errorf("addChangelogEntry() = %+v, want the 2nd's changes merged", entries)
+	}
+
+	if got := joinChanges([]string{"location", "start time", "title"}); got != "location, start time and title" {
+		t.Errorf("joinChanges() = %q", got)
+	}
+}
@@ -0,0 +1,253 @@
+package obsidian
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+const (
+	defaultCompaniesFolder = "Companies"
+	companyPeopleHeading   = "## People"
+	companyTimelineHeading = "## Timeline"
+	companyDomainProperty  = "domain"
+
+	companyTimelineDateFormat = "2006-01-02"
+)
+
+// freeMailDomains are email providers and calendar resources whose addresses say nothing about a company.
+var freeMailDomains = []string{
+	"gmail.com", "googlemail.com", "outlook.com", "hotmail.com", "live.com", "msn.com", "yahoo.com",
+	"icloud.com", "me.com", "mac.com", "aol.com", "proton.me", "protonmail.com", "gmx.com", "gmx.de",
+	"web.de", "mail.com", "yandex.com", "resource.calendar.google.com", "group.calendar.google.com",
+}
+
+// companyNoteUpdate describes the people and timeline links that should be present in a single company note.
+type companyNoteUpdate struct {
+	name   string
+	domain string
+	path   string
+	people []string
+	links  []string
+}
+
+// parseCompanyNames reads the company_names mapping of domains to company note names.
+func parseCompanyNames(value interface{}) (map[string]string, error) {
+	return parseNoteNames("company_names", "domains", value)
+}
+
+// companyName returns the note name for a domain: its company_names entry, else the domain itself.
+func (o *ObsidianTarget) companyName(domain string) string {
+	if name := o.companyNames[domain]; name != "" {
+		return name
+	}
+
+	return domain
+}
+
+// companyDomain returns the lower-case domain of an address, or "" for addresses that count towards no
+// company: free mail providers and company_exclude_domains.
+func (o *ObsidianTarget) companyDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+
+	domain := strings.ToLower(strings.TrimSpace(email[at+1:]))
+	if domain == "" || slices.Contains(freeMailDomains, domain) {
+		return ""
+	}
+
+	if slices.ContainsFunc(o.companyExcludeDomains, func(excluded string) bool {
+		return strings.EqualFold(strings.TrimPrefix(strings.TrimSpace(excluded), "@"), domain)
+	}) {
+		return ""
+	}
+
+	return domain
+}
+
+// companyContacts returns the people an item was exchanged with: the organizer and attendees of an event and
+// the senders of an email or a thread's messages, leaving out yourself.
+func companyContacts(item models.FullItem) []models.Attendee {
+	people := eventPeople(item)
+
+	senders := []models.ItemInterface{item}
+	if thread, ok := models.AsThread(item); ok {
+		for _, message := range thread.GetMessages() {
+			senders = append(senders, message)
+		}
+	}
+
+	for _, sender := range senders {
+		if from, ok := models.Metadata(sender.GetMetadata()).GetRecipient(models.MetadataFrom); ok {
+			people = append(people, models.Attendee{Email: from.Email, DisplayName: from.Name})
+		}
+	}
+
+	contacts := make([]models.Attendee, 0, len(people))
+
+	for _, person := range people {
+		if !person.Self {
+			contacts = append(contacts, person)
+		}
+	}
+
+	return contacts
+}
+
+// collectCompanyNoteUpdates gathers, for every company domain among the items' contacts, a timeline link per
+// item and a person link per person involved.
+func (o *ObsidianTarget) collectCompanyNoteUpdates(items []models.FullItem, outputDir string) []companyNoteUpdate {
+	byDomain := make(map[string]*companyNoteUpdate)
+
+	sorted := make([]models.FullItem, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].GetCreatedAt().Before(sorted[j].GetCreatedAt())
+	})
+
+	for _, item := range sorted {
+		link := fmt.Sprintf("- %s %s",
+			item.GetCreatedAt().Format(companyTimelineDateFormat), o.formatNoteLink(o.noteName(item), item.GetTitle()))
+
+		linked := make(map[string]bool)
+
+		for _, person := range companyContacts(item) {
+			domain := o.companyDomain(person.Email)
+			if domain == "" {
+				continue
+			}
+
+			update, exists := byDomain[domain]
+			if !exists {
+				name := o.companyName(domain)
+				update = &companyNoteUpdate{
+					name:   name,
+					domain: domain,
+					path:   filepath.Join(outputDir, o.companiesFolder, o.FormatFilename(name)),
+				}
+				byDomain[domain] = update
+			}
+
+			if personLink := "- " + o.formatPersonLink(person); !slices.Contains(update.people, personLink) {
+				update.people = append(update.people, personLink)
+			}
+
+			if !linked[domain] {
+				linked[domain] = true
+				update.links = append(update.links, link)
+			}
+		}
+	}
+
+	domains := make([]string, 0, len(byDomain))
+	for domain := range byDomain {
+		domains = append(domains, domain)
+	}
+
+	sort.Strings(domains)
+
+	updates := make([]companyNoteUpdate, 0, len(domains))
+	for _, domain := range domains {
+		update := byDomain[domain]
+		sort.Strings(update.people)
+		updates = append(updates, *update)
+	}
+
+	return updates
+}
+
+// companyNoteContent adds an update's people, timeline and domain to a company note.
+func (o *ObsidianTarget) companyNoteContent(base string, update companyNoteUpdate) string {
+	content := mergeLinksUnderHeading(base, companyPeopleHeading, update.people)
+	content = mergeLinksUnderHeading(content, companyTimelineHeading, update.links)
+
+	if o.writesFrontmatter() {
+		content = mergeFrontmatterProperties(content, [][2]string{{companyDomainProperty, update.domain}})
+	}
+
+	return content
+}
+
+// readCompanyNote returns the current company note content, or a fresh note with the company's name.
+func (o *ObsidianTarget) readCompanyNote(update companyNoteUpdate) (string, error) {
+	data, err := os.ReadFile(update.path)
+	if err == nil {
+		return string(data), nil
+	}
+
+	if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read company note %s: %w", update.path, err)
+	}
+
+	return fmt.Sprintf("# %s\n", update.name), nil
+}
+
+// updateCompanyNotes adds the exported items and the people involved to each company's note.
+func (o *ObsidianTarget) updateCompanyNotes(items []models.FullItem, outputDir string) error {
+	for _, update := range o.collectCompanyNoteUpdates(items, outputDir) {
+		existing, err := o.readCompanyNote(update)
+		if err != nil {
+			return err
+		}
+
+		content := o.companyNoteContent(existing, update)
+		if content == existing {
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(update.path), 0755); err != nil {
+			return fmt.Errorf("failed to create companies folder: %w", err)
+		}
+
+		if err := os.WriteFile(update.path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write company note %s: %w", update.path, err)
+		}
+	}
+
+	return nil
+}
+
+// previewCompanyNotes generates previews for company notes that would change.
+func (o *ObsidianTarget) previewCompanyNotes(items []models.FullItem, outputDir string) ([]*interfaces.FilePreview, error) {
+	var previews []*interfaces.FilePreview
+
+	for _, update := range o.collectCompanyNoteUpdates(items, outputDir) {
+		var existingContent string
+
+		if data, err := os.ReadFile(update.path); err == nil {
+			existingContent = string(data)
+		}
+
+		base, err := o.readCompanyNote(update)
+		if err != nil {
+			return nil, err
+		}
+
+		content := o.companyNoteContent(base, update)
+
+		action := "create"
+		if existingContent != "" {
+			action = "update"
+			if content == existingContent {
+				action = "skip"
+			}
+		}
+
+		previews = append(previews, &interfaces.FilePreview{
+			FilePath:        update.path,
+			Action:          action,
+			Content:         content,
+			ExistingContent: existingContent,
+		})
+	}
+
+	return previews, nil
+}
@@ -0,0 +1,68 @@
+package obsidian
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestExportUpdatesCompanyNotes(t *testing.T) {
+	outputDir := t.TempDir()
+
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{
+		"company_notes":           true,
+		"company_names":           map[string]interface{}{"Acme.com": "Acme"},
+		"company_exclude_domains": []interface{}{"@mycorp.com"},
+	}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	meeting := newDailyNoteTestItem("evt-1", "Renewal call", time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+	meeting.SetItemType("event")
+	meeting.SetMetadata(map[string]interface{}{
+		"organizer": models.Attendee{Email: "me@mycorp.com", DisplayName: "Me", Self: true},
+		"attendees": []models.Attendee{
+			{Email: "carol@acme.com", DisplayName: "Carol"},
+			{Email: "dave@acme.com", DisplayName: "Dave"},
+			{Email: "erin@mycorp.com", DisplayName: "Erin"},
+			{Email: "friend@gmail.com", DisplayName: "Friend"},
+		},
+	})
+
+	reply := newDailyNoteTestItem("msg-2", "Re: Renewal", time.Date(2025, 1, 20, 9, 0, 0, 0, time.UTC))
+	reply.SetMetadata(map[string]interface{}{models.MetadataFrom: models.Recipient{Name: "Carol", Email: "carol@acme.com"}})
+
+	thread := models.NewThread("thread-1", "Renewal")
+	thread.SetCreatedAt(reply.GetCreatedAt())
+	thread.AddMessage(reply)
+
+	for i := 0; i < 2; i++ {
+		if err := target.Export([]models.FullItem{thread, meeting}, outputDir); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+	}
+
+	acme, err := os.ReadFile(filepath.Join(outputDir, "Companies", "Acme.md"))
+	if err != nil {
+		t.Fatalf("company note not written: %v", err)
+	}
+
+	expected := "---\ndomain: acme.com\n---\n\n# Acme\n\n## People\n\n- [[Carol]]\n- [[Dave]]\n\n## Timeline\n\n" +
+		"- 2025-01-15 [[Renewal-call|Renewal call]]\n- 2025-01-20 [[Renewal]]\n"
+	if string(acme) != expected {
+		t.Errorf("company note =\n%q\nwant\n%q", string(acme), expected)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(outputDir, "Companies"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("company notes = %d, want only Acme (excluded and free mail domains get none)", len(entries))
+	}
+}
@@ -0,0 +1,129 @@
+package obsidian
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"pkm-sync/pkg/models"
+)
+
+// companionSuffix is appended to a stored attachment's path to name its converted companion note.
+const companionSuffix = ".md"
+
+// convertAttachments writes a markdown companion next to every stored attachment the converter supports.
+// Attachments that fail to convert are logged and keep only the file link.
+func (o *ObsidianTarget) convertAttachments(store *attachmentStore, items []models.FullItem, outputDir string) error {
+	for _, item := range items {
+		for _, attachment := range itemAttachments(item) {
+			if !o.converter.Supports(attachment.Name) {
+				continue
+			}
+
+			relPath, stored := store.lookup(attachment)
+			if !stored {
+				continue
+			}
+
+			if _, done := store.companions[relPath]; done {
+				continue
+			}
+
+			companion := relPath + companionSuffix
+
+			// Companions are keyed by the attachment's content hash, so an existing one is already current
+			if _, err := os.Stat(filepath.Join(outputDir, filepath.FromSlash(companion))); err == nil {
+				store.companions[relPath] = companion
+
+				continue
+			}
+
+			data, _ := decodeAttachment(attachment)
+
+			text, err := o.converter.Convert(attachment.Name, data)
+			if err != nil {
+				slog.Warn("Failed to convert attachment", "attachment", attachment.Name, "error", err)
+
+				continue
+			}
+
+			if err := store.writeCompanion(companion, o.companionContent(relPath, text), outputDir); err != nil {
+				return err
+			}
+
+			store.companions[relPath] = companion
+		}
+	}
+
+	return nil
+}
+
+// itemAttachments returns the attachments of an item and of its thread messages.
+func itemAttachments(item models.ItemInterface) []models.Attachment {
+	attachments := item.GetAttachments()
+
+	if thread, ok := models.AsThread(item); ok {
+		for _, message := range thread.GetMessages() {
+			attachments = append(attachments, message.GetAttachments()...)
+		}
+	}
+
+	return attachments
+}
+
+// companionContent renders the companion note of a converted attachment.
+func (o *ObsidianTarget) companionContent(relPath, text string) string {
+	var sb strings.Builder
+
+	sb.WriteString("# " + path.Base(relPath) + "\n\n")
+	sb.WriteString("Converted from " + o.formatFileLink(relPath, "") + "\n\n")
+
+	if strings.TrimSpace(text) == "" {
+		sb.WriteString("_No text could be extracted._\n")
+	} else {
+		sb.WriteString(strings.TrimSpace(text) + "\n")
+	}
+
+	return sb.String()
+}
+
+// writeCompanion writes a companion note, or plans it for previews.
+func (s *attachmentStore) writeCompanion(relPath, content, outputDir string) error {
+	if s.dryRun {
+		s.planned[relPath] = []byte(content)
+
+		return nil
+	}
+
+	path := filepath.Join(outputDir, filepath.FromSlash(relPath))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create attachment folder: %w", err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write converted attachment %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (s *attachmentStore) isCompanion(relPath string) bool {
+	attachment := strings.TrimSuffix(relPath, companionSuffix)
+
+	return attachment != relPath && s.companions[attachment] == relPath
+}
+
+// formatStoredAttachmentLink links to a stored attachment, followed by a link to its converted text if any.
+func (o *ObsidianTarget) formatStoredAttachmentLink(relPath, name string) string {
+	link := o.formatFileLink(relPath, name)
+
+	if companion, ok := o.attachments.companions[relPath]; ok {
+		link += " (" + o.formatFileLink(companion, "text") + ")"
+	}
+
+	return link
+}
@@ -0,0 +1,88 @@
+package obsidian
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func testDocx(t *testing.T, text string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	archive := zip.NewWriter(&buf)
+
+	w, err := archive.Create("word/document.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = w.Write([]byte(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+		`<w:body><w:p><w:r><w:t>` + text + `</w:t></w:r></w:p></w:body></w:document>`))
+
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestExportConvertsAttachments(t *testing.T) {
+	outputDir := t.TempDir()
+
+	target := NewObsidianTarget()
+
+	config := map[string]interface{}{"download_attachments": true, "convert_attachments": true}
+	if err := target.Configure(config); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	item := models.NewBasicItem("1", "Plan review")
+	item.SetAttachments([]models.Attachment{
+		{Name: "plan.docx", Data: testDocx(t, "Ship the beta in May.")},
+		{Name: "notes.txt", Data: base64.StdEncoding.EncodeToString([]byte("plain"))},
+	})
+
+	if err := target.Export([]models.FullItem{item}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	companion, err := os.ReadFile(filepath.Join(outputDir, "Attachments", "plan.docx.md"))
+	if err != nil {
+		t.Fatalf("companion note not written: %v", err)
+	}
+
+	if !strings.Contains(string(companion), "Converted from [[Attachments/plan.docx]]") ||
+		!strings.Contains(string(companion), "Ship the beta in May.") {
+		t.Errorf("unexpected companion note:\n%s", companion)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "Attachments", "notes.txt.md")); err == nil {
+		t.Error("unsupported attachment should not be converted")
+	}
+
+	note, err := os.ReadFile(filepath.Join(outputDir, "Plan-review.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "[[Attachments/plan.docx]] ([[Attachments/plan.docx.md|text]])"; !strings.Contains(string(note), want) {
+		t.Errorf("note missing %q:\n%s", want, note)
+	}
+}
+
+func TestConfigureRejectsUnknownAttachmentConverter(t *testing.T) {
+	target := NewObsidianTarget()
+
+	config := map[string]interface{}{"convert_attachments": true, "attachment_converter": "word"}
+	if err := target.Configure(config); err == nil {
+		t.Error("Configure() expected an error for an unknown attachment_converter")
+	}
+}
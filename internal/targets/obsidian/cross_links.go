@@ -0,0 +1,259 @@
+package obsidian
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"pkm-sync/internal/statestore"
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+const (
+	// crossLinkIndexFile records every synced note with the IDs it is known by and the IDs it references,
+	// so notes synced in different runs (an email today, its calendar invite last week) can be linked.
+	crossLinkIndexFile = ".pkm-sync-links.json"
+
+	relatedHeading = "## Related"
+)
+
+// linkedNote is what the link index records about a synced note.
+type linkedNote struct {
+	Path  string   `json:"path"` // Relative to the output directory
+	Title string   `json:"title"`
+	Keys  []string `json:"keys,omitempty"`
+	Refs  []string `json:"refs,omitempty"`
+}
+
+// loadCrossLinkIndex reads the link index of an output directory.
+func loadCrossLinkIndex(outputDir string) (map[string]linkedNote, error) {
+	index := make(map[string]linkedNote)
+
+	data, err := statestore.ReadFile(filepath.Join(outputDir, crossLinkIndexFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return index, nil
+		}
+
+		return nil, fmt.Errorf("failed to read link index: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse link index: %w", err)
+	}
+
+	return index, nil
+}
+
+// prepareCrossLinks records the exported items in the link index and resolves which notes reference each other.
+func (o *ObsidianTarget) prepareCrossLinks(items []models.FullItem, outputDir string) error {
+	index, err := loadCrossLinkIndex(outputDir)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		// Occurrences collected into a rolling note have no note of their own
+		if _, _, rolling := o.seriesOf(item); rolling {
+			continue
+		}
+
+		relPath, err := filepath.Rel(outputDir, o.itemPath(item, outputDir))
+		if err != nil {
+			continue
+		}
+
+		index[item.GetID()] = linkedNote{
+			Path:  filepath.ToSlash(relPath),
+			Title: item.GetTitle(),
+			Keys:  utils.ItemReferenceKeys(item),
+			Refs:  utils.ExtractReferences(item),
+		}
+	}
+
+	o.linkIndex = index
+	o.related = resolveRelated(index)
+
+	return nil
+}
+
+// resolveRelated links every note that references a key to the notes known by that key, in both directions.
+func resolveRelated(index map[string]linkedNote) map[string][]string {
+	owners := make(map[string][]string)
+
+	for id, note := range index {
+		for _, key := range note.Keys {
+			owners[key] = append(owners[key], id)
+		}
+	}
+
+	sets := make(map[string]map[string]bool)
+	link := func(from, to string) {
+		if sets[from] == nil {
+			sets[from] = make(map[string]bool)
+		}
+
+		sets[from][to] = true
+	}
+
+	for id, note := range index {
+		for _, ref := range note.Refs {
+			for _, owner := range owners[ref] {
+				if owner != id {
+					link(id, owner)
+					link(owner, id)
+				}
+			}
+		}
+	}
+
+	related := make(map[string][]string, len(sets))
+
+	for id, set := range sets {
+		ids := make([]string, 0, len(set))
+		for relatedID := range set {
+			ids = append(ids, relatedID)
+		}
+
+		sort.Slice(ids, func(i, j int) bool {
+			if index[ids[i]].Title != index[ids[j]].Title {
+				return index[ids[i]].Title < index[ids[j]].Title
+			}
+
+			return ids[i] < ids[j]
+		})
+		related[id] = ids
+	}
+
+	return related
+}
+
+// relatedLinks returns the list entries linking a note to the notes related to it.
+func (o *ObsidianTarget) relatedLinks(id string) []string {
+	links := make([]string, 0, len(o.related[id]))
+
+	for _, relatedID := range o.related[id] {
+		note := o.linkIndex[relatedID]
+		name := strings.TrimSuffix(filepath.Base(filepath.FromSlash(note.Path)), o.GetFileExtension())
+		links = append(links, "- "+o.formatNoteLink(name, note.Title))
+	}
+
+	return links
+}
+
+// addRelatedLinks adds the related notes of an item to its rendered content.
+func (o *ObsidianTarget) addRelatedLinks(item models.ItemInterface, content string) string {
+	if !o.crossLinks {
+		return content
+	}
+
+	return mergeLinksUnderHeading(content, relatedHeading, o.relatedLinks(item.GetID()))
+}
+
+// collectCrossLinkedNotes returns the IDs of notes from earlier syncs that gained a link to an exported item.
+func (o *ObsidianTarget) collectCrossLinkedNotes(items []models.FullItem) []string {
+	exported := make(map[string]bool, len(items))
+	for _, item := range items {
+		exported[item.GetID()] = true
+	}
+
+	var ids []string
+
+	for id, relatedIDs := range o.related {
+		if exported[id] {
+			continue
+		}
+
+		for _, relatedID := range relatedIDs {
+			if exported[relatedID] {
+				ids = append(ids, id)
+
+				break
+			}
+		}
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
+
+// updateCrossLinkedNotes adds backlinks to existing notes referenced by, or referencing, the exported items.
+func (o *ObsidianTarget) updateCrossLinkedNotes(items []models.FullItem, outputDir string) error {
+	for _, id := range o.collectCrossLinkedNotes(items) {
+		path := filepath.Join(outputDir, filepath.FromSlash(o.linkIndex[id].Path))
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			// The note was removed from the vault; nothing to link
+			continue
+		}
+
+		existing := string(data)
+
+		content := mergeLinksUnderHeading(existing, relatedHeading, o.relatedLinks(id))
+		if content == existing {
+			continue
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write related links to %s: %w", path, err)
+		}
+	}
+
+	return o.saveCrossLinkIndex(outputDir)
+}
+
+// previewCrossLinkedNotes generates previews for existing notes that would gain related links.
+func (o *ObsidianTarget) previewCrossLinkedNotes(items []models.FullItem, outputDir string) []*interfaces.FilePreview {
+	var previews []*interfaces.FilePreview
+
+	for _, id := range o.collectCrossLinkedNotes(items) {
+		path := filepath.Join(outputDir, filepath.FromSlash(o.linkIndex[id].Path))
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		existing := string(data)
+		content := mergeLinksUnderHeading(existing, relatedHeading, o.relatedLinks(id))
+
+		action := "update"
+		if content == existing {
+			action = "skip"
+		}
+
+		previews = append(previews, &interfaces.FilePreview{
+			FilePath:        path,
+			Action:          action,
+			Content:         content,
+			ExistingContent: existing,
+		})
+	}
+
+	return previews
+}
+
+// saveCrossLinkIndex persists the link index.
+func (o *ObsidianTarget) saveCrossLinkIndex(outputDir string) error {
+	if len(o.linkIndex) == 0 {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(o.linkIndex, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode link index: %w", err)
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	return statestore.WriteFile(filepath.Join(outputDir, crossLinkIndexFile), data, 0644)
+}
@@ -0,0 +1,93 @@
+package obsidian
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestCrossLinksAcrossSyncs(t *testing.T) {
+	outputDir := t.TempDir()
+
+	newTarget := func() *ObsidianTarget {
+		target := NewObsidianTarget()
+		if err := target.Configure(map[string]interface{}{"cross_links": true}); err != nil {
+			t.Fatalf("Configure() error = %v", err)
+		}
+
+		return target
+	}
+
+	// First sync: the calendar event with its agenda doc attached
+	event := models.NewBasicItem("evt123", "Planning")
+	event.SetSourceType("google_calendar")
+	event.SetItemType("event")
+	event.SetMetadata(map[string]interface{}{"start_time": time.Date(2025, 1, 13, 9, 0, 0, 0, time.UTC)})
+	event.SetAttachments([]models.Attachment{
+		{ID: "doc-42", Name: "Agenda", URL: "https://docs.google.com/document/d/doc-42/edit"},
+	})
+
+	if err := newTarget().Export([]models.FullItem{event}, outputDir); err != nil {
+		t.Fatalf("Export(event) error = %v", err)
+	}
+
+	// Second sync: an email sharing the agenda doc
+	email := models.NewBasicItem("msg-1", "Agenda for planning")
+	email.SetSourceType("gmail")
+	email.SetItemType("email")
+	email.SetContent("See https://docs.google.com/document/d/doc-42/edit")
+
+	target := newTarget()
+
+	plan, err := target.Plan([]models.FullItem{email}, outputDir)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	if previews := plan.Changes; len(previews) != 2 || previews[1].Action != "update" {
+		t.Fatalf("Plan() = %d changes, want the email and an update of the event note", len(previews))
+	}
+
+	if plan.Changes[0].ItemID != email.GetID() || plan.Changes[1].ItemID != "" {
+		t.Errorf("Plan() item IDs = %q, %q; want the email's, then none for the updated backlinks",
+			plan.Changes[0].ItemID, plan.Changes[1].ItemID)
+	}
+
+	if err := target.Export([]models.FullItem{email}, outputDir); err != nil {
+		t.Fatalf("Export(email) error = %v", err)
+	}
+
+	emailNote := readNote(t, filepath.Join(outputDir, "Agenda-for-planning.md"))
+	if !strings.Contains(emailNote, "## Related\n\n- [[Planning]]\n") {
+		t.Errorf("email note missing related event link:\n%s", emailNote)
+	}
+
+	eventNote := readNote(t, filepath.Join(outputDir, "Planning.md"))
+	if !strings.Contains(eventNote, "## Related\n\n- [[Agenda-for-planning|Agenda for planning]]\n") {
+		t.Errorf("event note missing backlink:\n%s", eventNote)
+	}
+
+	// Re-exporting the event keeps the backlink from the index
+	if err := newTarget().Export([]models.FullItem{event}, outputDir); err != nil {
+		t.Fatalf("Export(event) error = %v", err)
+	}
+
+	if eventNote := readNote(t, filepath.Join(outputDir, "Planning.md")); !strings.Contains(eventNote, "Agenda-for-planning") {
+		t.Errorf("re-exported event note lost its backlink:\n%s", eventNote)
+	}
+}
+
+func readNote(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+
+	return string(data)
+}
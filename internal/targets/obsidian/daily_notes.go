@@ -0,0 +1,240 @@
+package obsidian
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+const (
+	defaultDailyNotesFolder  = "Daily Notes"
+	defaultDailyNotesHeading = "## Synced Items"
+)
+
+// dailyNoteUpdate describes the links that should be present in a single daily note.
+type dailyNoteUpdate struct {
+	date  string
+	path  string
+	links []string
+}
+
+// collectDailyNoteUpdates groups items by the daily note matching their creation date.
+func (o *ObsidianTarget) collectDailyNoteUpdates(items []models.FullItem, outputDir string) []dailyNoteUpdate {
+	byDate := make(map[string]*dailyNoteUpdate)
+
+	for _, item := range items {
+		if item.GetCreatedAt().IsZero() {
+			continue
+		}
+
+		date := item.GetCreatedAt().Format(o.dailyNotesFormat)
+
+		update, exists := byDate[date]
+		if !exists {
+			update = &dailyNoteUpdate{
+				date: date,
+				path: filepath.Join(outputDir, o.dailyNotesFolder, date+o.GetFileExtension()),
+			}
+			byDate[date] = update
+		}
+
+		update.links = append(update.links, o.formatDailyNoteLink(item))
+	}
+
+	dates := make([]string, 0, len(byDate))
+	for date := range byDate {
+		dates = append(dates, date)
+	}
+
+	sort.Strings(dates)
+
+	updates := make([]dailyNoteUpdate, 0, len(dates))
+	for _, date := range dates {
+		updates = append(updates, *byDate[date])
+	}
+
+	return updates
+}
+
+// formatDailyNoteLink formats the list entry pointing at an exported item.
+func (o *ObsidianTarget) formatDailyNoteLink(item models.FullItem) string {
+	if o.dailyNotesEmbed {
+		return "- " + o.formatNoteEmbed(o.noteName(item))
+	}
+
+	return "- " + o.formatNoteLink(o.noteName(item), "")
+}
+
+// updateDailyNotes appends links for the exported items to their daily notes.
+func (o *ObsidianTarget) updateDailyNotes(items []models.FullItem, outputDir string) error {
+	for _, update := range o.collectDailyNoteUpdates(items, outputDir) {
+		existing, err := o.readDailyNote(update)
+		if err != nil {
+			return err
+		}
+
+		content := mergeDailyNoteLinks(existing, update.links)
+		if content == existing {
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(update.path), 0755); err != nil {
+			return fmt.Errorf("failed to create daily notes folder: %w", err)
+		}
+
+		if err := os.WriteFile(update.path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write daily note %s: %w", update.path, err)
+		}
+	}
+
+	return nil
+}
+
+// previewDailyNotes generates previews for daily notes that would change.
+func (o *ObsidianTarget) previewDailyNotes(items []models.FullItem, outputDir string) ([]*interfaces.FilePreview, error) {
+	var previews []*interfaces.FilePreview
+
+	for _, update := range o.collectDailyNoteUpdates(items, outputDir) {
+		var existingContent string
+
+		if data, err := os.ReadFile(update.path); err == nil {
+			existingContent = string(data)
+		}
+
+		base, err := o.readDailyNote(update)
+		if err != nil {
+			return nil, err
+		}
+
+		content := mergeDailyNoteLinks(base, update.links)
+
+		action := "create"
+		if existingContent != "" {
+			action = "update"
+			if content == existingContent {
+				action = "skip"
+			}
+		}
+
+		previews = append(previews, &interfaces.FilePreview{
+			FilePath:        update.path,
+			Action:          action,
+			Content:         content,
+			ExistingContent: existingContent,
+		})
+	}
+
+	return previews, nil
+}
+
+// readDailyNote returns the current daily note content, or a fresh note rendered from the template.
+func (o *ObsidianTarget) readDailyNote(update dailyNoteUpdate) (string, error) {
+	data, err := os.ReadFile(update.path)
+	if err == nil {
+		return string(data), nil
+	}
+
+	if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read daily note %s: %w", update.path, err)
+	}
+
+	if o.dailyNotesTemplate == "" {
+		return fmt.Sprintf("# %s\n", update.date), nil
+	}
+
+	template, err := os.ReadFile(o.dailyNotesTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to read daily notes template %s: %w", o.dailyNotesTemplate, err)
+	}
+
+	replacer := strings.NewReplacer("{{date}}", update.date, "{{title}}", update.date)
+
+	return replacer.Replace(string(template)), nil
+}
+
+// mergeDailyNoteLinks inserts any missing links under the synced items heading.
+func mergeDailyNoteLinks(content string, links []string) string {
+	return mergeLinksUnderHeading(content, defaultDailyNotesHeading, links)
+}
+
+// mergeLinksUnderHeading inserts any missing links at the end of the section under heading.
+// Links that are already present anywhere in the note are left alone so re-syncs are idempotent.
+func mergeLinksUnderHeading(content, heading string, links []string) string {
+	var missing []string
+
+	for _, link := range links {
+		if !strings.Contains(content, link) && !containsString(missing, link) {
+			missing = append(missing, link)
+		}
+	}
+
+	if len(missing) == 0 {
+		return content
+	}
+
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+
+	headingIndex := -1
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) == heading {
+			headingIndex = i
+
+			break
+		}
+	}
+
+	if headingIndex == -1 {
+		lines = append(lines, "", heading, "")
+		lines = append(lines, missing...)
+
+		return strings.Join(lines, "\n") + "\n"
+	}
+
+	// Insert after the last non-blank line of the section (before the next heading).
+	insertAt := len(lines)
+
+	for i := headingIndex + 1; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "#") {
+			insertAt = i
+
+			break
+		}
+	}
+
+	for insertAt > headingIndex+1 && strings.TrimSpace(lines[insertAt-1]) == "" {
+		insertAt--
+	}
+
+	result := make([]string, 0, len(lines)+len(missing)+1)
+	result = append(result, lines[:insertAt]...)
+
+	if insertAt == headingIndex+1 {
+		result = append(result, "")
+	}
+
+	result = append(result, missing...)
+
+	if insertAt < len(lines) && strings.TrimSpace(lines[insertAt]) != "" {
+		result = append(result, "")
+	}
+
+	result = append(result, lines[insertAt:]...)
+
+	return strings.Join(result, "\n") + "\n"
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
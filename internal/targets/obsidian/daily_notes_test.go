@@ -0,0 +1,122 @@
+package obsidian
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func newDailyNoteTestItem(id, title string, created time.Time) models.FullItem {
+	item := models.NewBasicItem(id, title)
+	item.SetCreatedAt(created)
+	item.SetSourceType("gmail")
+	item.SetItemType("email")
+
+	return item
+}
+
+func TestMergeDailyNoteLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		links    []string
+		expected string
+	}{
+		{
+			name:     "adds heading when missing",
+			content:  "# 2025-01-15\n",
+			links:    []string{"- [[Weekly-sync]]"},
+			expected: "# 2025-01-15\n\n## Synced Items\n\n- [[Weekly-sync]]\n",
+		},
+		{
+			name:     "appends to existing section before next heading",
+			content:  "# 2025-01-15\n\n## Synced Items\n\n- [[First]]\n\n## Journal\n\nNotes\n",
+			links:    []string{"- [[Second]]"},
+			expected: "# 2025-01-15\n\n## Synced Items\n\n- [[First]]\n- [[Second]]\n\n## Journal\n\nNotes\n",
+		},
+		{
+			name:     "skips links already present",
+			content:  "# 2025-01-15\n\n## Synced Items\n\n- [[First]]\n",
+			links:    []string{"- [[First]]"},
+			expected: "# 2025-01-15\n\n## Synced Items\n\n- [[First]]\n",
+		},
+		{
+			name:     "fills empty section",
+			content:  "# 2025-01-15\n\n## Synced Items\n",
+			links:    []string{"- [[First]]", "- [[First]]"},
+			expected: "# 2025-01-15\n\n## Synced Items\n\n- [[First]]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := mergeDailyNoteLinks(tt.content, tt.links)
+			if result != tt.expected {
+				t.Errorf("mergeDailyNoteLinks() =\n%q\nwant\n%q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExportCreatesDailyNotes(t *testing.T) {
+	outputDir := t.TempDir()
+
+	templatePath := filepath.Join(outputDir, "daily-template.md")
+	if err := os.WriteFile(templatePath, []byte("# Daily {{date}}\n"), 0644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{
+		"create_daily_notes":   true,
+		"daily_notes_folder":   "Journal",
+		"daily_notes_template": templatePath,
+	}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	day := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	items := []models.FullItem{
+		newDailyNoteTestItem("1", "Weekly sync", day),
+		newDailyNoteTestItem("2", "Budget review", day.Add(2*time.Hour)),
+	}
+
+	// Export twice to verify links are not duplicated.
+	for i := 0; i < 2; i++ {
+		if err := target.Export(items, outputDir); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "Journal", "2025-01-15.md"))
+	if err != nil {
+		t.Fatalf("daily note not created: %v", err)
+	}
+
+	content := string(data)
+	if !strings.HasPrefix(content, "# Daily 2025-01-15\n") {
+		t.Errorf("daily note was not rendered from template: %q", content)
+	}
+
+	for _, link := range []string{"- [[Weekly-sync]]", "- [[Budget-review]]"} {
+		if strings.Count(content, link) != 1 {
+			t.Errorf("expected exactly one %q in daily note, got:\n%s", link, content)
+		}
+	}
+}
+
+func TestDailyNotesEmbedMode(t *testing.T) {
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{"daily_notes_embed": true}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	link := target.formatDailyNoteLink(newDailyNoteTestItem("1", "Weekly sync", time.Now()))
+	if link != "- ![[Weekly-sync]]" {
+		t.Errorf("formatDailyNoteLink() = %q, want embed", link)
+	}
+}
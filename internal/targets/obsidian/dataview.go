@@ -0,0 +1,115 @@
+package obsidian
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/models"
+)
+
+// Metadata output modes.
+const (
+	metadataFormatFrontmatter = "frontmatter"
+	metadataFormatDataview    = "dataview"
+	metadataFormatBoth        = "both"
+)
+
+// writesFrontmatter reports whether YAML frontmatter should be emitted.
+func (o *ObsidianTarget) writesFrontmatter() bool {
+	return o.metadataFormat != metadataFormatDataview
+}
+
+// writesInlineFields reports whether Dataview inline fields should be emitted in the body.
+func (o *ObsidianTarget) writesInlineFields() bool {
+	return o.metadataFormat == metadataFormatDataview || o.metadataFormat == metadataFormatBoth
+}
+
+// formatInlineFields renders item metadata as Dataview inline fields (key:: value).
+func (o *ObsidianTarget) formatInlineFields(item models.ItemInterface) string {
+	var sb strings.Builder
+
+	metadata := item.GetMetadata()
+
+	if len(o.customFields) > 0 {
+		for _, mapping := range o.customFields {
+			writeInlineField(&sb, mapping.name, o.inlineFieldValues(mapping.name, mergeFieldValues(mapping, metadata)))
+		}
+	} else {
+		keys := make([]string, 0, len(metadata))
+		for key := range metadata {
+			// Only routes the note, see utils.OutputLayout.ItemFolder
+			if key != utils.FolderMetadataKey {
+				keys = append(keys, key)
+			}
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			writeInlineField(&sb, key, o.inlineFieldValues(key, metadata[key]))
+		}
+	}
+
+	writeInlineField(&sb, o.propertyNames.Name("id"), []string{item.GetID()})
+	writeInlineField(&sb, o.propertyNames.Name("source"), []string{item.GetSourceType()})
+	writeInlineField(&sb, o.propertyNames.Name("type"), []string{item.GetItemType()})
+	writeInlineField(&sb, o.propertyNames.Name("created"), []string{o.formatDateTime(item.GetCreatedAt(), time.RFC3339)})
+
+	if thread, ok := models.AsThread(item); ok {
+		writeInlineField(&sb, o.propertyNames.Name("message_count"), []string{fmt.Sprintf("%d", len(thread.GetMessages()))})
+	}
+
+	if noteTags := o.noteTags(item); len(noteTags) > 0 {
+		tags := make([]string, 0, len(noteTags))
+		for _, tag := range noteTags {
+			tags = append(tags, "#"+tag)
+		}
+
+		sb.WriteString(o.propertyNames.Name("tags") + ":: " + strings.Join(tags, " ") + "\n")
+	}
+
+	return sb.String()
+}
+
+// inlineFieldValues converts a metadata value into Dataview-friendly strings.
+func (o *ObsidianTarget) inlineFieldValues(key string, value interface{}) []string {
+	if value == nil {
+		return nil
+	}
+
+	if t, ok := toTime(value); ok {
+		if _, isString := value.(string); !isString {
+			return []string{o.formatDateTime(t, obsidianDateTimeFormat)}
+		}
+	}
+
+	if key == "attendees" || key == "organizer" {
+		if attendees, ok := attendeesOf(value); ok {
+			links := make([]string, len(attendees))
+			for i, attendee := range attendees {
+				links[i] = o.formatPersonLink(attendee)
+			}
+
+			return links
+		}
+	}
+
+	return toStringList(value)
+}
+
+func writeInlineField(sb *strings.Builder, name string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+
+	// Inline fields are single-line; keep multi-line values readable
+	flattened := make([]string, len(values))
+	for i, v := range values {
+		flattened[i] = strings.ReplaceAll(v, "\n", " ")
+	}
+
+	sb.WriteString(name + ":: " + strings.Join(flattened, ", ") + "\n")
+}
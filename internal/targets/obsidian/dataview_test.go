@@ -0,0 +1,108 @@
+package obsidian
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/models"
+)
+
+func newDataviewTestItem() models.FullItem {
+	item := models.NewBasicItem("evt-1", "Weekly sync")
+	item.SetCreatedAt(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+	item.SetSourceType("google_calendar")
+	item.SetItemType("event")
+	item.SetTags([]string{"meeting"})
+	item.SetMetadata(map[string]interface{}{
+		"location":  "Room 1",
+		"attendees": []string{"Alice", "Bob"},
+	})
+
+	return item
+}
+
+func TestMetadataFormat(t *testing.T) {
+	tests := []struct {
+		name            string
+		format          string
+		wantFrontmatter bool
+		wantInline      bool
+	}{
+		{name: "default frontmatter", format: "", wantFrontmatter: true, wantInline: false},
+		{name: "dataview only", format: "dataview", wantFrontmatter: false, wantInline: true},
+		{name: "both", format: "both", wantFrontmatter: true, wantInline: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := NewObsidianTarget()
+			if err := target.Configure(map[string]interface{}{"metadata_format": tt.format}); err != nil {
+				t.Fatalf("Configure() error = %v", err)
+			}
+
+			content := target.formatBasicItemContent(newDataviewTestItem())
+
+			if got := strings.HasPrefix(content, "---\n"); got != tt.wantFrontmatter {
+				t.Errorf("frontmatter present = %v, want %v:\n%s", got, tt.wantFrontmatter, content)
+			}
+
+			for _, field := range []string{
+				"attendees:: [[Alice]], [[Bob]]\n",
+				"location:: Room 1\n",
+				"id:: evt-1\n",
+				"tags:: #meeting\n",
+			} {
+				if got := strings.Contains(content, field); got != tt.wantInline {
+					t.Errorf("inline field %q present = %v, want %v:\n%s", field, got, tt.wantInline, content)
+				}
+			}
+		})
+	}
+}
+
+func TestDataviewFieldsOfRoutedNotes(t *testing.T) {
+	for _, format := range []string{"dataview", "both"} {
+		t.Run(format, func(t *testing.T) {
+			outputDir := t.TempDir()
+
+			target := NewObsidianTarget()
+			if err := target.Configure(map[string]interface{}{
+				"metadata_format": format,
+				"folder_routes":   []interface{}{map[string]interface{}{"when": "tag = meeting", "folder": "Meetings"}},
+			}); err != nil {
+				t.Fatalf("Configure() error = %v", err)
+			}
+
+			routed := newDataviewTestItem()
+			sourceFolder := models.NewBasicItem("evt-2", "Team offsite")
+			sourceFolder.SetItemType("event")
+			sourceFolder.SetMetadata(map[string]interface{}{utils.FolderMetadataKey: "Team", "location": "Berlin"})
+
+			if err := target.Export([]models.FullItem{routed, sourceFolder}, outputDir); err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+
+			for _, path := range []string{filepath.Join("Meetings", "Weekly-sync.md"), filepath.Join("Team", "Team-offsite.md")} {
+				content, err := os.ReadFile(filepath.Join(outputDir, path))
+				if err != nil {
+					t.Fatalf("note not routed to %s: %v", path, err)
+				}
+
+				if strings.Contains(string(content), utils.FolderMetadataKey) {
+					t.Errorf("%s writes the routing key:\n%s", path, content)
+				}
+			}
+		})
+	}
+}
+
+func TestMetadataFormatInvalid(t *testing.T) {
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{"metadata_format": "toml"}); err == nil {
+		t.Error("Configure() expected error for unsupported metadata_format")
+	}
+}
@@ -0,0 +1,153 @@
+package obsidian
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"pkm-sync/internal/statestore"
+	"pkm-sync/pkg/models"
+)
+
+const (
+	// eventIndexFile maps event IDs to the note they were written to, so rescheduled or renamed
+	// events update their existing note instead of creating a new one.
+	eventIndexFile = ".pkm-sync-events.json"
+
+	rescheduledFromKey = "rescheduled_from"
+)
+
+// trackedEvent is what the last sync recorded about an event.
+type trackedEvent struct {
+	Path            string    `json:"path"` // Relative to the output directory
+	Start           time.Time `json:"start"`
+	RescheduledFrom time.Time `json:"rescheduled_from,omitzero"`
+}
+
+// loadEventIndex reads the event index of an output directory.
+func loadEventIndex(outputDir string) (map[string]trackedEvent, error) {
+	index := make(map[string]trackedEvent)
+
+	data, err := statestore.ReadFile(filepath.Join(outputDir, eventIndexFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return index, nil
+		}
+
+		return nil, fmt.Errorf("failed to read event index: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse event index: %w", err)
+	}
+
+	return index, nil
+}
+
+// eventStart returns an item's start time if it is a calendar event.
+func eventStart(item models.ItemInterface) (time.Time, bool) {
+	return models.Metadata(item.GetMetadata()).GetTime(models.MetadataStartTime)
+}
+
+// prepareEvents loads the event index and records reschedules before notes are rendered.
+func (o *ObsidianTarget) prepareEvents(items []models.FullItem, outputDir string) error {
+	events, err := loadEventIndex(outputDir)
+	if err != nil {
+		return err
+	}
+
+	o.events = events
+
+	for _, item := range items {
+		o.recordReschedule(item)
+	}
+
+	return nil
+}
+
+// trackedEventPath returns the note an event was written to by an earlier sync, if that note still exists.
+func (o *ObsidianTarget) trackedEventPath(item models.ItemInterface, outputDir string) (string, bool) {
+	tracked, exists := o.events[item.GetID()]
+	if !exists || tracked.Path == "" {
+		return "", false
+	}
+
+	path := filepath.Join(outputDir, filepath.FromSlash(tracked.Path))
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+
+	return path, true
+}
+
+// recordReschedule adds a rescheduled_from property when an event's start time has moved since the last sync.
+// The previous time is kept on later syncs until the event moves again.
+func (o *ObsidianTarget) recordReschedule(item models.FullItem) {
+	start, isEvent := eventStart(item)
+	if !isEvent {
+		return
+	}
+
+	tracked, exists := o.events[item.GetID()]
+	if !exists {
+		return
+	}
+
+	if !tracked.Start.IsZero() && !tracked.Start.Equal(start) {
+		tracked.RescheduledFrom = tracked.Start
+		o.events[item.GetID()] = tracked
+	}
+
+	if tracked.RescheduledFrom.IsZero() {
+		return
+	}
+
+	// Copy so the caller's metadata map is left untouched
+	metadata := make(map[string]interface{}, len(item.GetMetadata())+1)
+	for key, value := range item.GetMetadata() {
+		metadata[key] = value
+	}
+
+	metadata[rescheduledFromKey] = tracked.RescheduledFrom
+	item.SetMetadata(metadata)
+}
+
+// trackEvents records where each event was written and when it starts.
+func (o *ObsidianTarget) trackEvents(items []models.FullItem, outputDir string) {
+	for _, item := range items {
+		start, isEvent := eventStart(item)
+		if !isEvent {
+			continue
+		}
+
+		relPath, err := filepath.Rel(outputDir, o.itemPath(item, outputDir))
+		if err != nil {
+			continue
+		}
+
+		tracked := o.events[item.GetID()]
+		tracked.Path = filepath.ToSlash(relPath)
+		tracked.Start = start
+		o.events[item.GetID()] = tracked
+	}
+}
+
+// saveEventIndex persists the event index.
+func (o *ObsidianTarget) saveEventIndex(outputDir string) error {
+	if len(o.events) == 0 {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(o.events, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode event index: %w", err)
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	return statestore.WriteFile(filepath.Join(outputDir, eventIndexFile), data, 0644)
+}
@@ -0,0 +1,59 @@
+package obsidian
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func newEventItem(title string, start time.Time) models.FullItem {
+	item := models.NewBasicItem("evt-42", title)
+	item.SetCreatedAt(start)
+	item.SetSourceType("google_calendar")
+	item.SetItemType("event")
+	item.SetMetadata(map[string]interface{}{"start_time": start})
+
+	return item
+}
+
+func TestExportUpdatesRescheduledEventInPlace(t *testing.T) {
+	outputDir := t.TempDir()
+	target := NewObsidianTarget()
+
+	original := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	if err := target.Export([]models.FullItem{newEventItem("Planning", original)}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	moved := original.Add(26 * time.Hour)
+	if err := target.Export([]models.FullItem{newEventItem("Planning (moved)", moved)}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	// A later sync at the new time keeps the previous time
+	if err := target.Export([]models.FullItem{newEventItem("Planning (moved)", moved)}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "Planning-(moved).md")); !os.IsNotExist(err) {
+		t.Error("renamed event should update its existing note, not create a new one")
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "Planning.md"))
+	if err != nil {
+		t.Fatalf("original note missing: %v", err)
+	}
+
+	content := string(data)
+	if !strings.Contains(content, "# Planning (moved)") {
+		t.Errorf("note not updated with new title:\n%s", content)
+	}
+
+	if !strings.Contains(content, "rescheduled_from: 2025-01-15T09:00:00Z") {
+		t.Errorf("note missing rescheduled_from:\n%s", content)
+	}
+}
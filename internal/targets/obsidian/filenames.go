@@ -0,0 +1,85 @@
+package obsidian
+
+import (
+	"path/filepath"
+	"strings"
+
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+// allocateNotePaths assigns every item a unique note path for this export run,
+// so items sharing a title don't overwrite each other and links point at the right note.
+// Notes the user renamed or moved are found by the id in their frontmatter and keep their path.
+func (o *ObsidianTarget) allocateNotePaths(items []models.FullItem, outputDir string) {
+	allocator := utils.NewFilenameAllocator(o.filenamePolicy)
+	allocator.ItemPolicies = o.itemPolicies
+	allocator.IDOf = func(path string) string {
+		return utils.ReadDeclaredID(path, o.propertyNames.Name("id"))
+	}
+	allocator.LocateExisting(outputDir, o.GetFileExtension(), o.propertyNames.Name("id"))
+
+	o.notePaths = make(map[string]string, len(items))
+
+	// Events keep the note an earlier sync wrote them to, even if their title has changed since
+	for _, item := range items {
+		if _, _, rolling := o.seriesOf(item); rolling {
+			continue
+		}
+
+		if path, tracked := o.trackedEventPath(item, outputDir); tracked {
+			allocator.Claim(path, item.GetID())
+			o.notePaths[item.GetID()] = path
+		}
+	}
+
+	for _, item := range items {
+		if _, allocated := o.notePaths[item.GetID()]; allocated {
+			continue
+		}
+
+		// All occurrences of a series share the series' rolling note
+		if key, title, rolling := o.seriesOf(item); rolling {
+			o.notePaths[item.GetID()] = allocator.Allocate(o.rollingNoteDir(item, outputDir), title, o.GetFileExtension(),
+				rollingNoteID(key))
+
+			continue
+		}
+
+		o.notePaths[item.GetID()] = allocator.Allocate(o.layout.ItemDir(outputDir, item), item.GetTitle(), o.GetFileExtension(), item.GetID())
+	}
+}
+
+// SetItemFilenames names the files of individual items with filenames settings layered over the target's,
+// e.g. those of the source that fetched them.
+func (o *ObsidianTarget) SetItemFilenames(filenames map[string]models.FilenameConfig) error {
+	policies, err := utils.ItemPolicies(filenames, o.filenamePolicy)
+	if err != nil {
+		return err
+	}
+
+	o.itemPolicies = policies
+
+	return nil
+}
+
+// itemPath returns the path an item's note is written to.
+func (o *ObsidianTarget) itemPath(item models.ItemInterface, outputDir string) string {
+	if path, exists := o.notePaths[item.GetID()]; exists {
+		return path
+	}
+
+	return filepath.Join(o.layout.ItemDir(outputDir, item), o.FormatFilename(item.GetTitle()))
+}
+
+// noteName returns the note name (filename without extension) an item is exported as.
+func (o *ObsidianTarget) noteName(item models.ItemInterface) string {
+	if path, exists := o.notePaths[item.GetID()]; exists {
+		return strings.TrimSuffix(filepath.Base(path), o.GetFileExtension())
+	}
+
+	return strings.TrimSuffix(o.FormatFilename(item.GetTitle()), o.GetFileExtension())
+}
+
+var _ interfaces.ItemFilenameTarget = (*ObsidianTarget)(nil)
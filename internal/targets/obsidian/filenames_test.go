@@ -0,0 +1,105 @@
+package obsidian
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestExportUpdatesRenamedNote(t *testing.T) {
+	outputDir := t.TempDir()
+	target := NewObsidianTarget()
+
+	item := models.NewBasicItem("msg-1", "Quarterly plan")
+	item.SetContent("First draft")
+
+	if err := target.Export([]models.FullItem{item}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	// The user renames the note and files it away
+	moved := filepath.Join(outputDir, "Projects", "Plan 2025.md")
+	if err := os.MkdirAll(filepath.Dir(moved), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Rename(filepath.Join(outputDir, "Quarterly-plan.md"), moved); err != nil {
+		t.Fatal(err)
+	}
+
+	item.SetContent("Second draft")
+
+	if err := target.Export([]models.FullItem{item}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "Quarterly-plan.md")); !os.IsNotExist(err) {
+		t.Error("sync recreated the note under its old name")
+	}
+
+	data, err := os.ReadFile(moved)
+	if err != nil {
+		t.Fatalf("moved note missing: %v", err)
+	}
+
+	if !strings.Contains(string(data), "Second draft") {
+		t.Errorf("moved note not updated:\n%s", data)
+	}
+}
+
+func TestExportWithPropertyNames(t *testing.T) {
+	outputDir := t.TempDir()
+	target := NewObsidianTarget()
+
+	if err := target.Configure(map[string]interface{}{
+		"property_names": map[string]interface{}{"id": "uid", "created": "date-created", "source": "origin"},
+	}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	item := models.NewBasicItem("msg-1", "Quarterly plan")
+	item.SetSourceType("gmail")
+	item.SetTags([]string{"work"})
+
+	if err := target.Export([]models.FullItem{item}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	path := filepath.Join(outputDir, "Quarterly-plan.md")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("note missing: %v", err)
+	}
+
+	for _, want := range []string{"\nuid: msg-1\n", "\norigin: gmail\n", "\ndate-created: ", "\ntype: "} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("note missing %q:\n%s", want, data)
+		}
+	}
+
+	if strings.Contains(string(data), "\nid: ") || strings.Contains(string(data), "\nsource: ") {
+		t.Errorf("note still has the default property names:\n%s", data)
+	}
+
+	// Renamed notes are still found by the renamed id property
+	moved := filepath.Join(outputDir, "Plan.md")
+	if err := os.Rename(path, moved); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := target.Export([]models.FullItem{item}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("sync recreated the note instead of updating the renamed one")
+	}
+
+	if origin, _ := target.readNoteOrigin(moved); origin != "gmail" {
+		t.Errorf("readNoteOrigin() source = %q, want gmail", origin)
+	}
+}
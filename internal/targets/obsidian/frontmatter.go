@@ -0,0 +1,318 @@
+package obsidian
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/models"
+)
+
+// Property types supported by custom field coercion.
+const (
+	fieldTypeAuto     = ""
+	fieldTypeString   = "string"
+	fieldTypeDate     = "date"
+	fieldTypeDateTime = "datetime"
+	fieldTypeList     = "list"
+	fieldTypeNumber   = "number"
+	fieldTypeBool     = "bool"
+
+	obsidianDateFormat     = "2006-01-02"
+	obsidianDateTimeFormat = "2006-01-02T15:04:05"
+)
+
+// fieldMapping maps one or more metadata keys onto a single frontmatter property.
+type fieldMapping struct {
+	keys []string
+	name string
+	kind string
+}
+
+// parseFieldMapping parses a custom field spec.
+//
+// Supported forms:
+//
+//	"location"                      - copy the key as-is
+//	"start_time -> start"           - rename
+//	"start_time -> start:date"      - rename and coerce
+//	"to+cc -> recipients:list"      - merge several keys into one property
+func parseFieldMapping(spec string) (fieldMapping, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return fieldMapping{}, fmt.Errorf("custom field spec cannot be empty")
+	}
+
+	source, target, renamed := strings.Cut(spec, "->")
+
+	mapping := fieldMapping{}
+
+	for _, key := range strings.Split(source, "+") {
+		if key = strings.TrimSpace(key); key != "" {
+			mapping.keys = append(mapping.keys, key)
+		}
+	}
+
+	if len(mapping.keys) == 0 {
+		return fieldMapping{}, fmt.Errorf("custom field '%s' has no source keys", spec)
+	}
+
+	mapping.name = mapping.keys[0]
+
+	if renamed {
+		name, kind, _ := strings.Cut(strings.TrimSpace(target), ":")
+		if name = strings.TrimSpace(name); name != "" {
+			mapping.name = name
+		}
+
+		mapping.kind = strings.TrimSpace(kind)
+	} else if name, kind, typed := strings.Cut(mapping.name, ":"); typed && len(mapping.keys) == 1 {
+		// "start_time:date" shorthand without rename
+		mapping.keys[0] = name
+		mapping.name = name
+		mapping.kind = strings.TrimSpace(kind)
+	}
+
+	switch mapping.kind {
+	case fieldTypeAuto, fieldTypeString, fieldTypeDate, fieldTypeDateTime, fieldTypeList, fieldTypeNumber, fieldTypeBool:
+	default:
+		return fieldMapping{}, fmt.Errorf("custom field '%s' has unsupported type '%s'", spec, mapping.kind)
+	}
+
+	return mapping, nil
+}
+
+// parseFieldMappings parses the custom_fields configuration value.
+func parseFieldMappings(value interface{}) ([]fieldMapping, error) {
+	specs, err := configStringList("custom_fields", value)
+	if err != nil {
+		return nil, err
+	}
+
+	mappings := make([]fieldMapping, 0, len(specs))
+
+	for _, spec := range specs {
+		mapping, err := parseFieldMapping(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		mappings = append(mappings, mapping)
+	}
+
+	return mappings, nil
+}
+
+// formatMappedMetadata renders only the configured custom fields as frontmatter properties.
+func (o *ObsidianTarget) formatMappedMetadata(metadata map[string]interface{}) string {
+	var sb strings.Builder
+
+	for _, mapping := range o.customFields {
+		// Attendees keep their wikilink rendering unless a type is forced
+		if len(mapping.keys) == 1 && mapping.keys[0] == "attendees" && mapping.kind == fieldTypeAuto {
+			if value, exists := metadata["attendees"]; exists {
+				sb.WriteString(o.formatAttendeesAs(mapping.name, value))
+			}
+
+			continue
+		}
+
+		sb.WriteString(o.formatProperty(mapping.name, mergeFieldValues(mapping, metadata), mapping.kind))
+	}
+
+	return sb.String()
+}
+
+// mergeFieldValues collects the values of all source keys for a mapping.
+// A single present key is returned as-is; multiple keys are flattened into a list.
+func mergeFieldValues(mapping fieldMapping, metadata map[string]interface{}) interface{} {
+	var present []interface{}
+
+	for _, key := range mapping.keys {
+		if value, exists := metadata[key]; exists && value != nil {
+			present = append(present, value)
+		}
+	}
+
+	switch len(present) {
+	case 0:
+		return nil
+	case 1:
+		return present[0]
+	}
+
+	if mapping.kind != fieldTypeList && mapping.kind != fieldTypeAuto {
+		return present[0]
+	}
+
+	var merged []string
+
+	for _, value := range present {
+		for _, s := range toStringList(value) {
+			if !containsString(merged, s) {
+				merged = append(merged, s)
+			}
+		}
+	}
+
+	return merged
+}
+
+// formatProperty renders a single frontmatter property with the requested type coercion.
+func (o *ObsidianTarget) formatProperty(name string, value interface{}, kind string) string {
+	if value == nil {
+		return ""
+	}
+
+	if kind == fieldTypeAuto {
+		kind = inferFieldType(value)
+	}
+
+	switch kind {
+	case fieldTypeList:
+		values := toStringList(value)
+		if len(values) == 0 {
+			return ""
+		}
+
+		var sb strings.Builder
+
+		sb.WriteString(name + ":\n")
+
+		for _, v := range values {
+			sb.WriteString("  - " + utils.YAMLString(v) + "\n")
+		}
+
+		return sb.String()
+	case fieldTypeDate, fieldTypeDateTime:
+		t, ok := toTime(value)
+		if !ok {
+			return fmt.Sprintf("%s: %s\n", name, utils.YAMLString(toString(value)))
+		}
+
+		if kind == fieldTypeDateTime {
+			return fmt.Sprintf("%s: %s\n", name, o.formatDateTime(t, obsidianDateTimeFormat))
+		}
+
+		return fmt.Sprintf("%s: %s\n", name, t.Format(obsidianDateFormat))
+	case fieldTypeNumber:
+		if f, err := strconv.ParseFloat(toString(value), 64); err == nil {
+			return fmt.Sprintf("%s: %s\n", name, strconv.FormatFloat(f, 'f', -1, 64))
+		}
+
+		return ""
+	case fieldTypeBool:
+		if b, err := strconv.ParseBool(toString(value)); err == nil {
+			return fmt.Sprintf("%s: %t\n", name, b)
+		}
+
+		return ""
+	default:
+		return fmt.Sprintf("%s: %s\n", name, utils.YAMLString(toString(value)))
+	}
+}
+
+// inferFieldType picks a property type from the Go type of a value.
+func inferFieldType(value interface{}) string {
+	switch value.(type) {
+	case time.Time, *time.Time:
+		return fieldTypeDateTime
+	case bool:
+		return fieldTypeBool
+	case int, int32, int64, float32, float64:
+		return fieldTypeNumber
+	}
+
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		return fieldTypeList
+	}
+
+	return fieldTypeString
+}
+
+// toTime converts time values and common date strings to time.Time.
+func toTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, !v.IsZero()
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, false
+		}
+
+		return *v, !v.IsZero()
+	case string:
+		for _, layout := range []string{time.RFC3339, obsidianDateTimeFormat, obsidianDateFormat, time.RFC1123Z} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// toStringList flattens slices (including slices of structs such as recipients) into strings.
+func toStringList(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []string:
+		return v
+	case string:
+		if v == "" {
+			return nil
+		}
+
+		return []string{v}
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return []string{toString(value)}
+	}
+
+	result := make([]string, 0, rv.Len())
+
+	for i := 0; i < rv.Len(); i++ {
+		if s := toString(rv.Index(i).Interface()); s != "" {
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
+
+// toString renders a scalar value, preferring display names and emails for contact-like structs.
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case models.Attendee:
+		return v.GetDisplayName()
+	case map[string]interface{}:
+		for _, key := range []string{"DisplayName", "Name", "name", "Email", "email"} {
+			if s, ok := v[key].(string); ok && s != "" {
+				return s
+			}
+		}
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Struct {
+		for _, field := range []string{"DisplayName", "Name", "Email"} {
+			if f := rv.FieldByName(field); f.IsValid() && f.Kind() == reflect.String && f.String() != "" {
+				return f.String()
+			}
+		}
+	}
+
+	return fmt.Sprintf("%v", value)
+}
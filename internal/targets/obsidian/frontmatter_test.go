@@ -0,0 +1,245 @@
+package obsidian
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseFieldMapping(t *testing.T) {
+	tests := []struct {
+		spec     string
+		keys     []string
+		name     string
+		kind     string
+		hasError bool
+	}{
+		{spec: "location", keys: []string{"location"}, name: "location"},
+		{spec: "start_time -> start", keys: []string{"start_time"}, name: "start"},
+		{spec: "start_time -> start:date", keys: []string{"start_time"}, name: "start", kind: "date"},
+		{spec: "end_time:datetime", keys: []string{"end_time"}, name: "end_time", kind: "datetime"},
+		{spec: "to + cc -> recipients:list", keys: []string{"to", "cc"}, name: "recipients", kind: "list"},
+		{spec: "size -> bytes:weird", hasError: true},
+		{spec: "  ", hasError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			mapping, err := parseFieldMapping(tt.spec)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("expected error for spec %q", tt.spec)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(mapping.keys) != len(tt.keys) {
+				t.Fatalf("keys = %v, want %v", mapping.keys, tt.keys)
+			}
+
+			for i := range tt.keys {
+				if mapping.keys[i] != tt.keys[i] {
+					t.Errorf("keys[%d] = %q, want %q", i, mapping.keys[i], tt.keys[i])
+				}
+			}
+
+			if mapping.name != tt.name || mapping.kind != tt.kind {
+				t.Errorf("got name=%q kind=%q, want name=%q kind=%q", mapping.name, mapping.kind, tt.name, tt.kind)
+			}
+		})
+	}
+}
+
+func TestFormatMetadataWithCustomFields(t *testing.T) {
+	type recipient struct {
+		Name  string
+		Email string
+	}
+
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{
+		"custom_fields": []string{
+			"start_time -> date:date",
+			"to+cc -> recipients:list",
+			"location",
+			"size:number",
+		},
+	}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	metadata := map[string]interface{}{
+		"start_time": time.Date(2025, 2, 3, 14, 30, 0, 0, time.UTC),
+		"to":         []recipient{{Name: "Alice", Email: "alice@example.com"}},
+		"cc":         []recipient{{Email: "bob@example.com"}, {Name: "Alice"}},
+		"location":   "Room: 4B",
+		"size":       2048,
+		"snippet":    "not included",
+	}
+
+	expected := "date: 2025-02-03\n" +
+		"recipients:\n  - Alice\n  - bob@example.com\n" +
+		"location: \"Room: 4B\"\n" +
+		"size: 2048\n"
+
+	if result := target.FormatMetadata(metadata); result != expected {
+		t.Errorf("FormatMetadata() =\n%q\nwant\n%q", result, expected)
+	}
+}
+
+func TestFormatMetadataCustomFieldsKeepsAttendeeLinks(t *testing.T) {
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{
+		"custom_fields": []interface{}{"attendees -> people"},
+	}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	metadata := map[string]interface{}{
+		"attendees": []models.Attendee{{Email: "carol@example.com", DisplayName: "Carol"}},
+	}
+
+	expected := "people:\n  - \"[[Carol]]\"\n"
+	if result := target.FormatMetadata(metadata); result != expected {
+		t.Errorf("FormatMetadata() = %q, want %q", result, expected)
+	}
+}
+
+func TestFormatMetadataParticipantCounts(t *testing.T) {
+	metadata := map[string]interface{}{
+		models.MetadataParticipantMessages: map[string]int{"bob@example.com": 1, "alice@example.com": 3},
+	}
+
+	expected := "participant_messages:\n  alice@example.com: 3\n  bob@example.com: 1\n"
+	if result := NewObsidianTarget().FormatMetadata(metadata); result != expected {
+		t.Errorf("FormatMetadata() = %q, want %q", result, expected)
+	}
+}
+
+func TestFormatMetadataSkipsOutputFolder(t *testing.T) {
+	metadata := map[string]interface{}{
+		models.MetadataOutputFolder: "Meetings/Team",
+		models.MetadataFolder:       "Work Projects",
+	}
+
+	expected := "folder: Work Projects\n"
+	if result := NewObsidianTarget().FormatMetadata(metadata); result != expected {
+		t.Errorf("FormatMetadata() = %q, want %q", result, expected)
+	}
+}
+
+func TestTimezoneAndDateTimeFormat(t *testing.T) {
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{
+		"timezone":        "America/New_York",
+		"datetime_format": "2006-01-02 15:04",
+		"custom_fields":   []interface{}{"start_time -> start:datetime"},
+	}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	start := time.Date(2025, 1, 15, 15, 0, 0, 0, time.UTC)
+	item := models.NewBasicItem("evt-1", "Weekly sync")
+	item.SetCreatedAt(start)
+	item.SetMetadata(map[string]interface{}{"start_time": start})
+
+	outputDir := t.TempDir()
+	if err := target.Export([]models.FullItem{item}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "Weekly-sync.md"))
+	if err != nil {
+		t.Fatalf("failed to read note: %v", err)
+	}
+
+	for _, want := range []string{"start: 2025-01-15 10:00\n", "created: 2025-01-15 10:00\n"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("note missing %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestConfigureRejectsUnknownTimezone(t *testing.T) {
+	if err := NewObsidianTarget().Configure(map[string]interface{}{"timezone": "Mars/Olympus"}); err == nil {
+		t.Error("Configure() expected error for unknown timezone")
+	}
+}
+
+func TestFrontmatterSurvivesAdversarialValues(t *testing.T) {
+	title := "Re: \"Q3\" plan 🚀 #urgent"
+
+	item := models.NewBasicItem("msg: 1", title)
+	item.SetSourceType("gmail")
+	item.SetTags([]string{"#inbox", "work"})
+	item.SetMetadata(map[string]interface{}{
+		"subject":   title,
+		"snippet":   "first line\nsecond line: with colon",
+		"labels":    []string{"[Gmail]/Sent", "yes"},
+		"organizer": models.Attendee{Email: "o@example.com", DisplayName: `Olivia "Liv" O'Neil`},
+		"attendees": []models.Attendee{{Email: "a@example.com", DisplayName: `Al "The Pal"`}},
+		"version":   "1.10",
+	})
+
+	outputDir := t.TempDir()
+	if err := NewObsidianTarget().Export([]models.FullItem{item}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(outputDir, "*.md"))
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("expected one note, got %v (%v)", matches, err)
+	}
+
+	data, err := os.ReadFile(matches[0])
+	if err != nil {
+		t.Fatalf("failed to read note: %v", err)
+	}
+
+	parts := strings.SplitN(string(data), "---\n", 3)
+	if len(parts) != 3 {
+		t.Fatalf("note has no frontmatter:\n%s", data)
+	}
+
+	var frontmatter map[string]interface{}
+	if err := yaml.Unmarshal([]byte(parts[1]), &frontmatter); err != nil {
+		t.Fatalf("frontmatter does not parse: %v\n%s", err, parts[1])
+	}
+
+	want := map[string]interface{}{
+		"id":        "msg: 1",
+		"subject":   title,
+		"snippet":   "first line\nsecond line: with colon",
+		"version":   "1.10",
+		"organizer": `[[Olivia-Liv-O'Neil|Olivia "Liv" O'Neil]]`,
+	}
+	for key, value := range want {
+		if frontmatter[key] != value {
+			t.Errorf("%s = %#v, want %#v", key, frontmatter[key], value)
+		}
+	}
+
+	if labels, _ := frontmatter["labels"].([]interface{}); len(labels) != 2 || labels[1] != "yes" {
+		t.Errorf("labels = %#v, want [[Gmail]/Sent yes]", frontmatter["labels"])
+	}
+
+	if tags, _ := frontmatter["tags"].([]interface{}); len(tags) != 2 || tags[0] != "inbox" {
+		t.Errorf("tags = %#v, want [inbox work]", frontmatter["tags"])
+	}
+
+	if attendees, _ := frontmatter["attendees"].([]interface{}); len(attendees) != 1 ||
+		attendees[0] != `[[Al-The-Pal|Al "The Pal"]]` {
+		t.Errorf("attendees = %#v", frontmatter["attendees"])
+	}
+}
@@ -0,0 +1,294 @@
+package obsidian
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"pkm-sync/internal/statestore"
+	"pkm-sync/pkg/models"
+)
+
+const (
+	// maxImageSize is the largest remote image downloaded by download_images; larger images stay remote
+	maxImageSize = 10 << 20
+
+	imageDownloadTimeout = 30 * time.Second
+
+	// imageCacheFile records the ETag and Last-Modified of downloaded images, so later syncs revalidate
+	// them with a conditional request instead of downloading them again.
+	imageCacheFile = ".pkm-sync-images.json"
+)
+
+// errImageNotModified is returned when the server confirms a cached image is still current.
+var errImageNotModified = errors.New("image not modified")
+
+// cachedImage is a downloaded remote image and the validators it was served with.
+type cachedImage struct {
+	Path         string `json:"path"` // Relative to the output directory
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// imageExtensions are the usual extensions of common image types; mime lists rarer ones such as .jfif first.
+var imageExtensions = map[string]string{
+	"image/jpeg":    ".jpg",
+	"image/png":     ".png",
+	"image/gif":     ".gif",
+	"image/webp":    ".webp",
+	"image/svg+xml": ".svg",
+}
+
+var (
+	// ![alt](https://...) as written by content_cleanup, with an optional title
+	markdownRemoteImage = regexp.MustCompile(`!\[((?:\\.|[^\]\\])*)\]\((https?://[^)\s]+)(?:\s+"[^"]*")?\)`)
+
+	// <img src="https://..."> left in content that was not converted to markdown
+	htmlRemoteImage = regexp.MustCompile(`(?i)(<img\b[^>]*?\bsrc\s*=\s*["'])(https?://[^"']+)(["'])`)
+)
+
+// remoteImageURLs returns the remote images referenced in content, in order of appearance.
+func remoteImageURLs(content string) []string {
+	var urls []string
+
+	for _, match := range markdownRemoteImage.FindAllStringSubmatch(content, -1) {
+		urls = append(urls, match[2])
+	}
+
+	for _, match := range htmlRemoteImage.FindAllStringSubmatch(content, -1) {
+		urls = append(urls, match[2])
+	}
+
+	return urls
+}
+
+// itemContents returns the content of an item and of its thread messages.
+func itemContents(item models.ItemInterface) []string {
+	contents := []string{item.GetContent()}
+
+	if thread, ok := models.AsThread(item); ok {
+		for _, message := range thread.GetMessages() {
+			contents = append(contents, message.GetContent())
+		}
+	}
+
+	return contents
+}
+
+// storeImages downloads the remote images referenced by items into the attachment store, remembering the
+// stored path of each URL. Images that fail to download keep their remote link.
+func (o *ObsidianTarget) storeImages(store *attachmentStore, items []models.FullItem, outputDir string) error {
+	o.images = make(map[string]string)
+
+	client := o.imageClient
+	if client == nil {
+		client = &http.Client{Timeout: imageDownloadTimeout}
+	}
+
+	cache := loadImageCache(store.dir)
+	cacheChanged := false
+
+	for _, item := range items {
+		for _, content := range itemContents(item) {
+			for _, imageURL := range remoteImageURLs(content) {
+				if _, done := o.images[imageURL]; done {
+					continue
+				}
+
+				// Validators are only sent while the earlier download is still on disk
+				cached, found := cache[imageURL]
+				if _, err := os.Stat(filepath.Join(outputDir, filepath.FromSlash(cached.Path))); !found || err != nil {
+					cached = cachedImage{}
+				}
+
+				attachment, validators, err := downloadImage(client, imageURL, cached)
+				if errors.Is(err, errImageNotModified) {
+					o.images[imageURL] = cached.Path
+
+					continue
+				}
+
+				if err != nil {
+					slog.Warn("Failed to download image", "url", imageURL, "error", err)
+
+					o.images[imageURL] = ""
+
+					continue
+				}
+
+				relPath, err := store.store(attachment, outputDir)
+				if err != nil {
+					return err
+				}
+
+				o.images[imageURL] = relPath
+
+				if relPath != "" && (validators.ETag != "" || validators.LastModified != "") {
+					validators.Path = relPath
+					cache[imageURL] = validators
+					cacheChanged = true
+				}
+			}
+		}
+	}
+
+	if !cacheChanged {
+		return nil
+	}
+
+	return saveImageCache(store.dir, cache)
+}
+
+// loadImageCache reads the validators of earlier downloads; an unreadable cache starts empty, which only
+// costs full downloads.
+func loadImageCache(dir string) map[string]cachedImage {
+	cache := make(map[string]cachedImage)
+
+	if data, err := statestore.ReadFile(filepath.Join(dir, imageCacheFile)); err == nil {
+		if err := json.Unmarshal(data, &cache); err != nil {
+			slog.Warn("Ignoring unreadable image cache", "error", err)
+		}
+	}
+
+	return cache
+}
+
+func saveImageCache(dir string, cache map[string]cachedImage) error {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode image cache: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create attachment folder: %w", err)
+	}
+
+	return statestore.WriteFile(filepath.Join(dir, imageCacheFile), data, 0644)
+}
+
+// downloadImage fetches a remote image as an attachment named after the last segment of its URL, with the
+// validators it was served with. Given the validators of an earlier download, the request is conditional
+// and errImageNotModified is returned when the image is unchanged.
+func downloadImage(client *http.Client, imageURL string, cached cachedImage) (models.Attachment, cachedImage, error) {
+	req, err := http.NewRequest(http.MethodGet, imageURL, nil)
+	if err != nil {
+		return models.Attachment{}, cachedImage{}, err
+	}
+
+	if cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	if cached.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cached.LastModified)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return models.Attachment{}, cachedImage{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached.Path != "" {
+		return models.Attachment{}, cached, errImageNotModified
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return models.Attachment{}, cachedImage{}, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	validators := cachedImage{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+
+	mimeType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !strings.HasPrefix(mimeType, "image/") {
+		return models.Attachment{}, cachedImage{}, fmt.Errorf("not an image: %q", mimeType)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return models.Attachment{}, cachedImage{}, err
+	}
+
+	if len(data) > maxImageSize {
+		return models.Attachment{}, cachedImage{}, fmt.Errorf("image larger than %d bytes", maxImageSize)
+	}
+
+	return models.Attachment{
+		Name:     imageFilename(imageURL, mimeType),
+		MimeType: mimeType,
+		Size:     int64(len(data)),
+		Data:     base64.StdEncoding.EncodeToString(data),
+	}, validators, nil
+}
+
+// imageFilename names a downloaded image after its URL path, adding an extension for its type when missing.
+func imageFilename(imageURL, mimeType string) string {
+	name := "image"
+
+	if u, err := url.Parse(imageURL); err == nil {
+		if base := path.Base(u.Path); base != "." && base != "/" {
+			name = base
+		}
+	}
+
+	if path.Ext(name) == "" {
+		if ext, ok := imageExtensions[mimeType]; ok {
+			name += ext
+		} else if exts, err := mime.ExtensionsByType(mimeType); err == nil && len(exts) > 0 {
+			name += exts[0]
+		}
+	}
+
+	return name
+}
+
+// localizeImages points the remote images in content at their downloaded copies.
+func (o *ObsidianTarget) localizeImages(content string) string {
+	if len(o.images) == 0 {
+		return content
+	}
+
+	content = markdownRemoteImage.ReplaceAllStringFunc(content, func(match string) string {
+		parts := markdownRemoteImage.FindStringSubmatch(match)
+		if relPath := o.images[parts[2]]; relPath != "" {
+			return o.formatImageEmbed(relPath, parts[1])
+		}
+
+		return match
+	})
+
+	return htmlRemoteImage.ReplaceAllStringFunc(content, func(match string) string {
+		parts := htmlRemoteImage.FindStringSubmatch(match)
+		if relPath := o.images[parts[2]]; relPath != "" {
+			return parts[1] + escapeLinkPath(relPath) + parts[3]
+		}
+
+		return match
+	})
+}
+
+// formatImageEmbed embeds a downloaded image: ![[path]] or ![alt](path).
+func (o *ObsidianTarget) formatImageEmbed(relPath, alt string) string {
+	if o.linkFormat == linkFormatMarkdown {
+		return fmt.Sprintf("![%s](%s)", alt, escapeLinkPath(relPath))
+	}
+
+	return "![[" + relPath + "]]"
+}
+
+// itemContent returns an item's content as written to its note.
+func (o *ObsidianTarget) itemContent(item models.ItemInterface) string {
+	return o.localizeImages(item.GetContent())
+}
@@ -0,0 +1,140 @@
+package obsidian
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestExportDownloadsRemoteImages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/img/chart.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("\x89PNG chart"))
+		case "/logo":
+			w.Header().Set("Content-Type", "image/jpeg")
+			_, _ = w.Write([]byte("\xff\xd8 logo"))
+		case "/page":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	outputDir := t.TempDir()
+
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{"download_images": true}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	item := models.NewBasicItem("1", "Quarterly update")
+	item.SetContent("Results:\n\n![Q3 chart](" + server.URL + "/img/chart.png)\n\n" +
+		`<img src="` + server.URL + `/logo" width="80">` + "\n\n" +
+		"![missing](" + server.URL + "/gone.png) ![page](" + server.URL + "/page)")
+
+	if err := target.Export([]models.FullItem{item}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	for _, name := range []string{"chart.png", "logo.jpg"} {
+		if _, err := os.Stat(filepath.Join(outputDir, "Attachments", name)); err != nil {
+			t.Errorf("image %s not downloaded: %v", name, err)
+		}
+	}
+
+	note, err := os.ReadFile(filepath.Join(outputDir, "Quarterly-update.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"![[Attachments/chart.png]]", `<img src="Attachments/logo.jpg" width="80">`,
+		"![missing](" + server.URL + "/gone.png)", "![page](" + server.URL + "/page)"} {
+		if !strings.Contains(string(note), want) {
+			t.Errorf("note missing %q:\n%s", want, note)
+		}
+	}
+}
+
+func TestLocalizeImagesMarkdownLinks(t *testing.T) {
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{"link_format": "markdown"}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	target.images = map[string]string{"https://example.com/a.png": "Attachments/a b.png"}
+
+	got := target.localizeImages(`See ![the \[chart\]](https://example.com/a.png "Chart")`)
+	if want := `See ![the \[chart\]](Attachments/a%20b.png)`; got != want {
+		t.Errorf("localizeImages() = %q, want %q", got, want)
+	}
+}
+
+func TestExportRevalidatesDownloadedImages(t *testing.T) {
+	downloads, revalidated := 0, 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidated++
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		downloads++
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("\x89PNG chart"))
+	}))
+	defer server.Close()
+
+	outputDir := t.TempDir()
+
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{"download_images": true}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	item := models.NewBasicItem("1", "Quarterly update")
+	item.SetContent("![Q3 chart](" + server.URL + "/chart.png)")
+
+	for i := 0; i < 2; i++ {
+		if err := target.Export([]models.FullItem{item}, outputDir); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+	}
+
+	if downloads != 1 || revalidated != 1 {
+		t.Errorf("downloads = %d, revalidated = %d, want 1 and 1", downloads, revalidated)
+	}
+
+	note, err := os.ReadFile(filepath.Join(outputDir, "Quarterly-update.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(note), "![[Attachments/chart.png]]") {
+		t.Errorf("revalidated image not embedded:\n%s", note)
+	}
+
+	// A deleted copy is downloaded again rather than revalidated
+	if err := os.Remove(filepath.Join(outputDir, "Attachments", "chart.png")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := target.Export([]models.FullItem{item}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	if downloads != 2 {
+		t.Errorf("downloads after deleting the copy = %d, want 2", downloads)
+	}
+}
@@ -0,0 +1,333 @@
+package obsidian
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"pkm-sync/internal/statestore"
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+	"pkm-sync/pkg/query"
+)
+
+const (
+	defaultPriorityField = "priority"
+
+	// importantIndexFile records the items already queued as important, so lines and bookmarks removed
+	// while triaging are not added back when the items are synced again.
+	importantIndexFile = ".pkm-sync-important.json"
+
+	// bookmarksFile is where Obsidian's Bookmarks core plugin keeps its bookmarks.
+	bookmarksFile         = ".obsidian/bookmarks.json"
+	importantBookmarkName = "Important"
+)
+
+// queuedItem is an item the triage queue picked up.
+type queuedItem struct {
+	Path   string    `json:"path"` // Note path relative to the output directory
+	Queued time.Time `json:"queued"`
+}
+
+// importantEnabled reports whether important items are queued in a note or in Obsidian's bookmarks.
+func (o *ObsidianTarget) importantEnabled() bool {
+	return o.importantNote != "" || o.importantBookmarks
+}
+
+// configureImportant reads the important_* options; queuing needs a rule to pick items by.
+func (o *ObsidianTarget) configureImportant(config map[string]interface{}) error {
+	if note, ok := config["important_note"].(string); ok {
+		o.importantNote = note
+	}
+
+	if bookmarks, ok := config["important_bookmarks"].(bool); ok {
+		o.importantBookmarks = bookmarks
+	}
+
+	if when, ok := config["important_when"].(string); ok && when != "" {
+		q, err := query.Parse(when)
+		if err != nil {
+			return fmt.Errorf("important_when: %w", err)
+		}
+
+		o.importantWhen = q
+	}
+
+	if field, ok := config["important_priority_field"].(string); ok && field != "" {
+		o.importantPriorityField = field
+	}
+
+	if minPriority, ok := config["important_min_priority"].(float64); ok {
+		o.importantMinPriority = minPriority
+	}
+
+	if o.importantEnabled() && o.importantWhen == nil && o.importantMinPriority == 0 {
+		return fmt.Errorf("important_note and important_bookmarks need important_when or important_min_priority")
+	}
+
+	return nil
+}
+
+// isImportant reports whether an item matches important_when or its priority reaches important_min_priority.
+func (o *ObsidianTarget) isImportant(item models.ItemInterface) bool {
+	if o.importantWhen != nil && o.importantWhen.MatchItem(item) {
+		return true
+	}
+
+	if o.importantMinPriority == 0 {
+		return false
+	}
+
+	field := o.importantPriorityField
+	if field == "" {
+		field = defaultPriorityField
+	}
+
+	priority, ok := models.Metadata(item.GetMetadata()).GetFloat(field)
+
+	return ok && priority >= o.importantMinPriority
+}
+
+// newImportantItems returns the important items among items that were not queued before, recording them in
+// queued. Items appended to rolling notes have no note of their own and are skipped.
+func (o *ObsidianTarget) newImportantItems(items []models.FullItem, outputDir string,
+	queued map[string]queuedItem,
+) []models.FullItem {
+	var important []models.FullItem
+
+	for _, item := range items {
+		if _, _, rolling := o.seriesOf(item); rolling || !o.isImportant(item) {
+			continue
+		}
+
+		if _, seen := queued[item.GetID()]; seen {
+			continue
+		}
+
+		rel, err := filepath.Rel(outputDir, o.itemPath(item, outputDir))
+		if err != nil {
+			continue
+		}
+
+		queued[item.GetID()] = queuedItem{Path: filepath.ToSlash(rel), Queued: time.Now().UTC()}
+		important = append(important, item)
+	}
+
+	return important
+}
+
+func loadImportantIndex(outputDir string) (map[string]queuedItem, error) {
+	queued := make(map[string]queuedItem)
+
+	data, err := statestore.ReadFile(filepath.Join(outputDir, importantIndexFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return queued, nil
+		}
+
+		return nil, fmt.Errorf("failed to read important item index: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &queued); err != nil {
+		return nil, fmt.Errorf("failed to parse important item index: %w", err)
+	}
+
+	return queued, nil
+}
+
+func saveImportantIndex(outputDir string, queued map[string]queuedItem) error {
+	data, err := json.MarshalIndent(queued, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode important item index: %w", err)
+	}
+
+	return statestore.WriteFile(filepath.Join(outputDir, importantIndexFile), data, 0644)
+}
+
+func (o *ObsidianTarget) importantNotePath(outputDir string) string {
+	return filepath.Join(outputDir, o.filenamePolicy.Sanitize(o.importantNote)+o.GetFileExtension())
+}
+
+// buildImportantNote appends an unchecked line per item to the triage note, creating it with a heading.
+// Lines already in the note, checked or not, are left as they are.
+func (o *ObsidianTarget) buildImportantNote(existing string, items []models.FullItem) string {
+	content := existing
+	if content == "" {
+		content = "# " + o.importantNote + "\n\n"
+	} else if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+
+	for _, item := range items {
+		line := "- [ ] " + o.formatNoteLink(o.noteName(item), "")
+
+		var details []string
+		if source := item.GetSourceType(); source != "" {
+			details = append(details, source)
+		}
+
+		if created := item.GetCreatedAt(); !created.IsZero() {
+			details = append(details, created.Format("2006-01-02"))
+		}
+
+		if len(details) > 0 {
+			line += " (" + strings.Join(details, ", ") + ")"
+		}
+
+		content += line + "\n"
+	}
+
+	return content
+}
+
+// updateImportant queues the important items among the exported ones in the triage note and bookmarks.
+func (o *ObsidianTarget) updateImportant(items []models.FullItem, outputDir string) error {
+	queued, err := loadImportantIndex(outputDir)
+	if err != nil {
+		return err
+	}
+
+	important := o.newImportantItems(items, outputDir, queued)
+	if len(important) == 0 {
+		return nil
+	}
+
+	if o.importantNote != "" {
+		path := o.importantNotePath(outputDir)
+
+		existing, err := readOptionalFile(path)
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(path, []byte(o.buildImportantNote(existing, important)), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	if o.importantBookmarks {
+		if err := bookmarkItems(outputDir, important, queued); err != nil {
+			return err
+		}
+	}
+
+	return saveImportantIndex(outputDir, queued)
+}
+
+// previewImportantNote previews the triage note update, or returns nil when no new item is important.
+func (o *ObsidianTarget) previewImportantNote(items []models.FullItem, outputDir string) (*interfaces.FilePreview, error) {
+	if o.importantNote == "" {
+		return nil, nil
+	}
+
+	queued, err := loadImportantIndex(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	important := o.newImportantItems(items, outputDir, queued)
+	if len(important) == 0 {
+		return nil, nil
+	}
+
+	path := o.importantNotePath(outputDir)
+
+	existing, err := readOptionalFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	action := "create"
+	if existing != "" {
+		action = "update"
+	}
+
+	return &interfaces.FilePreview{
+		FilePath:        path,
+		Action:          action,
+		Content:         o.buildImportantNote(existing, important),
+		ExistingContent: existing,
+	}, nil
+}
+
+// bookmarkItems adds file bookmarks for items to the "Important" group of Obsidian's bookmarks, creating
+// the group when needed. Other bookmarks and unknown fields are kept as they are.
+func bookmarkItems(outputDir string, items []models.FullItem, queued map[string]queuedItem) error {
+	path := filepath.Join(outputDir, filepath.FromSlash(bookmarksFile))
+
+	bookmarks := map[string]interface{}{}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read bookmarks: %w", err)
+	}
+
+	if err == nil {
+		if err := json.Unmarshal(data, &bookmarks); err != nil {
+			return fmt.Errorf("failed to parse bookmarks %s: %w", path, err)
+		}
+	}
+
+	entries, _ := bookmarks["items"].([]interface{})
+	now := time.Now().UnixMilli()
+
+	var group map[string]interface{}
+
+	for _, entry := range entries {
+		if candidate, ok := entry.(map[string]interface{}); ok &&
+			candidate["type"] == "group" && candidate["title"] == importantBookmarkName {
+			group = candidate
+
+			break
+		}
+	}
+
+	if group == nil {
+		group = map[string]interface{}{"type": "group", "ctime": now, "title": importantBookmarkName}
+		entries = append(entries, group)
+	}
+
+	groupItems, _ := group["items"].([]interface{})
+
+	for _, item := range items {
+		groupItems = append(groupItems, map[string]interface{}{
+			"type":  "file",
+			"ctime": now,
+			"path":  queued[item.GetID()].Path,
+		})
+	}
+
+	group["items"] = groupItems
+	bookmarks["items"] = entries
+
+	if data, err = json.MarshalIndent(bookmarks, "", "  "); err != nil {
+		return fmt.Errorf("failed to encode bookmarks: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create bookmarks folder: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write bookmarks: %w", err)
+	}
+
+	return nil
+}
+
+// readOptionalFile returns a file's content, or "" when it does not exist.
+func readOptionalFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return string(data), nil
+}
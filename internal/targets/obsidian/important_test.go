@@ -0,0 +1,119 @@
+package obsidian
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func newImportantTestItem(id, title string, metadata map[string]interface{}) models.FullItem {
+	item := models.NewBasicItem(id, title)
+	item.SetSourceType("gmail")
+	item.SetCreatedAt(time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC))
+	item.SetMetadata(metadata)
+
+	return item
+}
+
+func TestImportantQueue(t *testing.T) {
+	outputDir := t.TempDir()
+
+	// Existing bookmarks are kept
+	bookmarksPath := filepath.Join(outputDir, bookmarksFile)
+	if err := os.MkdirAll(filepath.Dir(bookmarksPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(bookmarksPath, []byte(`{"items":[{"type":"file","path":"Home.md"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{
+		"important_note":         "Inbox – Important",
+		"important_bookmarks":    true,
+		"important_when":         `from ~ "@boss.example.com"`,
+		"important_min_priority": 3.0,
+	}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	items := []models.FullItem{
+		newImportantTestItem("1", "Quarterly plan", map[string]interface{}{"from": "ceo@boss.example.com"}),
+		newImportantTestItem("2", "Outage", map[string]interface{}{"priority": 4}),
+		newImportantTestItem("3", "Newsletter", map[string]interface{}{"priority": 1}),
+	}
+
+	if err := target.Export(items, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	notePath := filepath.Join(outputDir, "Inbox-–-Important.md")
+
+	data, err := os.ReadFile(notePath)
+	if err != nil {
+		t.Fatalf("triage note not written: %v", err)
+	}
+
+	want := "# Inbox – Important\n\n- [ ] [[Quarterly-plan]] (gmail, 2025-03-03)\n- [ ] [[Outage]] (gmail, 2025-03-03)\n"
+	if string(data) != want {
+		t.Errorf("triage note = %q, want %q", data, want)
+	}
+
+	data, err = os.ReadFile(bookmarksPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var bookmarks struct {
+		Items []struct {
+			Type  string `json:"type"`
+			Path  string `json:"path"`
+			Title string `json:"title"`
+			Items []struct {
+				Path string `json:"path"`
+			} `json:"items"`
+		} `json:"items"`
+	}
+
+	if err := json.Unmarshal(data, &bookmarks); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bookmarks.Items) != 2 || bookmarks.Items[0].Path != "Home.md" || bookmarks.Items[1].Title != "Important" ||
+		len(bookmarks.Items[1].Items) != 2 || bookmarks.Items[1].Items[1].Path != "Outage.md" {
+		t.Errorf("bookmarks = %s", data)
+	}
+
+	// Lines removed while triaging stay removed when the items sync again
+	if err := os.WriteFile(notePath, []byte("# Inbox – Important\n\n- [x] [[Outage]]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	items = append(items, newImportantTestItem("4", "Escalation", map[string]interface{}{"priority": 5.0}))
+	if err := target.Export(items, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	data, _ = os.ReadFile(notePath)
+	if want := "# Inbox – Important\n\n- [x] [[Outage]]\n- [ ] [[Escalation]] (gmail, 2025-03-03)\n"; string(data) != want {
+		t.Errorf("triage note after resync = %q, want %q", data, want)
+	}
+}
+
+func TestConfigureImportantNeedsRule(t *testing.T) {
+	if err := NewObsidianTarget().Configure(map[string]interface{}{"important_note": "Important"}); err == nil {
+		t.Error("Configure() accepted important_note without a rule")
+	}
+
+	if err := NewObsidianTarget().Configure(map[string]interface{}{
+		"important_note": "Important",
+		"important_when": "from ~",
+	}); err == nil {
+		t.Error("Configure() accepted an invalid important_when")
+	}
+}
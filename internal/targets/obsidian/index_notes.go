@@ -0,0 +1,186 @@
+package obsidian
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+// Index note groupings supported by index_notes.
+const (
+	indexBySource = "source"
+	indexByMonth  = "month"
+	indexByTag    = "tag"
+
+	defaultIndexFolder  = "Index"
+	defaultIndexHeading = "## Items"
+
+	indexMonthFormat = "2006-01"
+)
+
+// indexNoteUpdate describes the links that should be present in a single index note.
+type indexNoteUpdate struct {
+	title string
+	path  string
+	links []string
+}
+
+// parseIndexGroupings validates the index_notes configuration value.
+func parseIndexGroupings(value interface{}) ([]string, error) {
+	groupings, err := configStringList("index_notes", value)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, grouping := range groupings {
+		switch grouping {
+		case indexBySource, indexByMonth, indexByTag:
+		default:
+			return nil, fmt.Errorf("unsupported index_notes grouping '%s': supported groupings are 'source', 'month', 'tag'",
+				grouping)
+		}
+	}
+
+	return groupings, nil
+}
+
+// indexBuckets returns the index note titles an item belongs to for a grouping.
+func (o *ObsidianTarget) indexBuckets(grouping string, item models.FullItem) []string {
+	switch grouping {
+	case indexBySource:
+		if item.GetSourceType() != "" {
+			return []string{"Source - " + item.GetSourceType()}
+		}
+	case indexByMonth:
+		if !item.GetCreatedAt().IsZero() {
+			return []string{item.GetCreatedAt().Format(indexMonthFormat)}
+		}
+	case indexByTag:
+		tags := o.noteTags(item)
+
+		titles := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			titles = append(titles, "Tag - "+tag)
+		}
+
+		return titles
+	}
+
+	return nil
+}
+
+// collectIndexNoteUpdates groups item links into index notes for each configured grouping.
+func (o *ObsidianTarget) collectIndexNoteUpdates(items []models.FullItem, outputDir string) []indexNoteUpdate {
+	byTitle := make(map[string]*indexNoteUpdate)
+
+	for _, grouping := range o.indexNotes {
+		for _, item := range items {
+			for _, title := range o.indexBuckets(grouping, item) {
+				update, exists := byTitle[title]
+				if !exists {
+					update = &indexNoteUpdate{
+						title: title,
+						path:  filepath.Join(outputDir, o.indexFolder, o.FormatFilename(title)),
+					}
+					byTitle[title] = update
+				}
+
+				update.links = append(update.links, "- "+o.formatNoteLink(o.noteName(item), item.GetTitle()))
+			}
+		}
+	}
+
+	titles := make([]string, 0, len(byTitle))
+	for title := range byTitle {
+		titles = append(titles, title)
+	}
+
+	sort.Strings(titles)
+
+	updates := make([]indexNoteUpdate, 0, len(titles))
+	for _, title := range titles {
+		updates = append(updates, *byTitle[title])
+	}
+
+	return updates
+}
+
+// readIndexNote returns the current index note content, or a fresh note with a title heading.
+func readIndexNote(update indexNoteUpdate) (string, error) {
+	data, err := os.ReadFile(update.path)
+	if err == nil {
+		return string(data), nil
+	}
+
+	if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read index note %s: %w", update.path, err)
+	}
+
+	return fmt.Sprintf("# %s\n", update.title), nil
+}
+
+// updateIndexNotes adds links for the exported items to their index notes.
+func (o *ObsidianTarget) updateIndexNotes(items []models.FullItem, outputDir string) error {
+	for _, update := range o.collectIndexNoteUpdates(items, outputDir) {
+		existing, err := readIndexNote(update)
+		if err != nil {
+			return err
+		}
+
+		content := mergeLinksUnderHeading(existing, defaultIndexHeading, update.links)
+		if content == existing {
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(update.path), 0755); err != nil {
+			return fmt.Errorf("failed to create index folder: %w", err)
+		}
+
+		if err := os.WriteFile(update.path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write index note %s: %w", update.path, err)
+		}
+	}
+
+	return nil
+}
+
+// previewIndexNotes generates previews for index notes that would change.
+func (o *ObsidianTarget) previewIndexNotes(items []models.FullItem, outputDir string) ([]*interfaces.FilePreview, error) {
+	var previews []*interfaces.FilePreview
+
+	for _, update := range o.collectIndexNoteUpdates(items, outputDir) {
+		var existingContent string
+
+		if data, err := os.ReadFile(update.path); err == nil {
+			existingContent = string(data)
+		}
+
+		base, err := readIndexNote(update)
+		if err != nil {
+			return nil, err
+		}
+
+		content := mergeLinksUnderHeading(base, defaultIndexHeading, update.links)
+
+		action := "create"
+		if existingContent != "" {
+			action = "update"
+			if content == existingContent {
+				action = "skip"
+			}
+		}
+
+		previews = append(previews, &interfaces.FilePreview{
+			FilePath:        update.path,
+			Action:          action,
+			Content:         content,
+			ExistingContent: existingContent,
+		})
+	}
+
+	return previews, nil
+}
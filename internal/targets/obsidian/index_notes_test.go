@@ -0,0 +1,47 @@
+package obsidian
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestExportUpdatesIndexNotes(t *testing.T) {
+	outputDir := t.TempDir()
+
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{"index_notes": []interface{}{"source", "month", "tag"}}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	item := newDailyNoteTestItem("1", "Weekly sync", time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+	item.SetTags([]string{"meeting"})
+
+	for i := 0; i < 2; i++ {
+		if err := target.Export([]models.FullItem{item}, outputDir); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+	}
+
+	for _, name := range []string{"Source-gmail.md", "2025-01.md", "Tag-meeting.md"} {
+		data, err := os.ReadFile(filepath.Join(outputDir, "Index", name))
+		if err != nil {
+			entries, _ := os.ReadDir(filepath.Join(outputDir, "Index"))
+			t.Fatalf("index note %s not written (%v): %v", name, entries, err)
+		}
+
+		if strings.Count(string(data), "[[Weekly-sync|Weekly sync]]") != 1 {
+			t.Errorf("index note %s should link the item once:\n%s", name, data)
+		}
+	}
+}
+
+func TestParseIndexGroupingsRejectsUnknown(t *testing.T) {
+	if _, err := parseIndexGroupings([]string{"week"}); err == nil {
+		t.Error("parseIndexGroupings() expected error for unsupported grouping")
+	}
+}
@@ -0,0 +1,252 @@
+package obsidian
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+const (
+	defaultKanbanStatusField = "status"
+	defaultKanbanLane        = "Backlog"
+
+	kanbanFrontmatter = "---\n\nkanban-plugin: board\n\n---\n"
+	kanbanSettings    = "%% kanban:settings\n```\n{\"kanban-plugin\":\"board\"}\n```\n%%"
+)
+
+// defaultKanbanItemTypes are the item types treated as tasks when kanban_item_types is not set.
+var defaultKanbanItemTypes = []string{"task", "issue", "todo"}
+
+// completedStatuses mark cards as checked on the board.
+var completedStatuses = []string{"done", "closed", "completed", "resolved"}
+
+// kanbanLane is a single column of the board.
+type kanbanLane struct {
+	title string
+	cards []string
+}
+
+// isKanbanItem reports whether an item should appear on the Kanban board.
+func (o *ObsidianTarget) isKanbanItem(item models.ItemInterface) bool {
+	itemTypes := o.kanbanItemTypes
+	if len(itemTypes) == 0 {
+		itemTypes = defaultKanbanItemTypes
+	}
+
+	itemType := item.GetItemType()
+
+	return slices.ContainsFunc(itemTypes, func(t string) bool { return strings.EqualFold(t, itemType) })
+}
+
+// kanbanStatus returns the lane title for an item based on its status metadata.
+func (o *ObsidianTarget) kanbanStatus(item models.ItemInterface) string {
+	field := o.kanbanStatusField
+	if field == "" {
+		field = defaultKanbanStatusField
+	}
+
+	status := strings.TrimSpace(toString(item.GetMetadata()[field]))
+	if status == "" || status == "<nil>" {
+		return defaultKanbanLane
+	}
+
+	// Match configured lanes case-insensitively so "in progress" lands in "In Progress"
+	for _, lane := range o.kanbanLanes {
+		if strings.EqualFold(lane, status) {
+			return lane
+		}
+	}
+
+	return status
+}
+
+// formatKanbanCard formats the card pointing at an exported item.
+func (o *ObsidianTarget) formatKanbanCard(item models.ItemInterface, status string) string {
+	checkbox := "[ ]"
+	if containsString(completedStatuses, strings.ToLower(status)) {
+		checkbox = "[x]"
+	}
+
+	return fmt.Sprintf("- %s %s", checkbox, o.formatNoteLink(o.noteName(item), ""))
+}
+
+// hasKanbanItems reports whether any of the items belong on the board.
+func (o *ObsidianTarget) hasKanbanItems(items []models.FullItem) bool {
+	for _, item := range items {
+		if o.isKanbanItem(item) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o *ObsidianTarget) kanbanBoardPath(outputDir string) string {
+	return filepath.Join(outputDir, o.filenamePolicy.Sanitize(o.kanbanBoard)+o.GetFileExtension())
+}
+
+// buildKanbanBoard merges the synced items into the existing board content.
+// Cards for synced items move to their current status lane; any other cards are left untouched.
+func (o *ObsidianTarget) buildKanbanBoard(existing string, items []models.FullItem) string {
+	lanes := parseKanbanLanes(existing)
+
+	for _, title := range o.kanbanLanes {
+		lanes = ensureKanbanLane(lanes, title)
+	}
+
+	for _, item := range items {
+		if !o.isKanbanItem(item) {
+			continue
+		}
+
+		status := o.kanbanStatus(item)
+		link := o.formatNoteLink(o.noteName(item), "")
+
+		for i := range lanes {
+			kept := lanes[i].cards[:0]
+
+			for _, card := range lanes[i].cards {
+				if !strings.Contains(card, link) {
+					kept = append(kept, card)
+				}
+			}
+
+			lanes[i].cards = kept
+		}
+
+		lanes = ensureKanbanLane(lanes, status)
+
+		for i := range lanes {
+			if lanes[i].title == status {
+				lanes[i].cards = append(lanes[i].cards, o.formatKanbanCard(item, status))
+			}
+		}
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(kanbanFrontmatter)
+
+	for _, lane := range lanes {
+		sb.WriteString("\n## " + lane.title + "\n\n")
+
+		for _, card := range lane.cards {
+			sb.WriteString(card + "\n")
+		}
+	}
+
+	sb.WriteString("\n\n" + kanbanSettings + "\n")
+
+	return sb.String()
+}
+
+// parseKanbanLanes reads the lanes and cards of an existing Kanban board.
+func parseKanbanLanes(content string) []kanbanLane {
+	var lanes []kanbanLane
+
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, "%% kanban:settings") {
+			break
+		}
+
+		if title, isLane := strings.CutPrefix(line, "## "); isLane {
+			lanes = append(lanes, kanbanLane{title: strings.TrimSpace(title)})
+
+			continue
+		}
+
+		if len(lanes) > 0 && strings.HasPrefix(strings.TrimSpace(line), "- ") {
+			lanes[len(lanes)-1].cards = append(lanes[len(lanes)-1].cards, strings.TrimRight(line, " "))
+		}
+	}
+
+	return lanes
+}
+
+func ensureKanbanLane(lanes []kanbanLane, title string) []kanbanLane {
+	for _, lane := range lanes {
+		if lane.title == title {
+			return lanes
+		}
+	}
+
+	return append(lanes, kanbanLane{title: title})
+}
+
+func (o *ObsidianTarget) readKanbanBoard(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("failed to read kanban board %s: %w", path, err)
+	}
+
+	return string(data), nil
+}
+
+// updateKanbanBoard writes the Kanban board for the exported items.
+func (o *ObsidianTarget) updateKanbanBoard(items []models.FullItem, outputDir string) error {
+	if !o.hasKanbanItems(items) {
+		return nil
+	}
+
+	path := o.kanbanBoardPath(outputDir)
+
+	existing, err := o.readKanbanBoard(path)
+	if err != nil {
+		return err
+	}
+
+	content := o.buildKanbanBoard(existing, items)
+	if content == existing {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create kanban board folder: %w", err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write kanban board %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// previewKanbanBoard generates a preview of the Kanban board update, or nil when no items belong on it.
+func (o *ObsidianTarget) previewKanbanBoard(items []models.FullItem, outputDir string) (*interfaces.FilePreview, error) {
+	if !o.hasKanbanItems(items) {
+		return nil, nil
+	}
+
+	path := o.kanbanBoardPath(outputDir)
+
+	existing, err := o.readKanbanBoard(path)
+	if err != nil {
+		return nil, err
+	}
+
+	content := o.buildKanbanBoard(existing, items)
+
+	action := "create"
+	if existing != "" {
+		action = "update"
+		if content == existing {
+			action = "skip"
+		}
+	}
+
+	return &interfaces.FilePreview{
+		FilePath:        path,
+		Action:          action,
+		Content:         content,
+		ExistingContent: existing,
+	}, nil
+}
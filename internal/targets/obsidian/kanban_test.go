@@ -0,0 +1,81 @@
+package obsidian
+
+import (
+	"strings"
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func newKanbanTestItem(id, title, status string) models.FullItem {
+	item := models.NewBasicItem(id, title)
+	item.SetItemType("task")
+	item.SetMetadata(map[string]interface{}{"status": status})
+
+	return item
+}
+
+func TestBuildKanbanBoard(t *testing.T) {
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{
+		"kanban_board": "Tasks",
+		"kanban_lanes": []string{"To Do", "In Progress", "Done"},
+	}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	note := models.NewBasicItem("n", "Meeting notes")
+
+	board := target.buildKanbanBoard("", []models.FullItem{
+		newKanbanTestItem("1", "Write spec", "in progress"),
+		newKanbanTestItem("2", "Ship it", "done"),
+		note,
+	})
+
+	for _, want := range []string{
+		"kanban-plugin: board",
+		"## In Progress\n\n- [ ] [[Write-spec]]\n",
+		"## Done\n\n- [x] [[Ship-it]]\n",
+		"%% kanban:settings",
+	} {
+		if !strings.Contains(board, want) {
+			t.Errorf("board missing %q:\n%s", want, board)
+		}
+	}
+
+	if strings.Contains(board, "Meeting-notes") {
+		t.Errorf("non-task item should not be on the board:\n%s", board)
+	}
+
+	// Re-sync moves the card and keeps hand-written cards
+	board = strings.Replace(board, "## To Do\n\n", "## To Do\n\n- [ ] Call vendor\n", 1)
+	board = target.buildKanbanBoard(board, []models.FullItem{newKanbanTestItem("1", "Write spec", "Done")})
+
+	if strings.Count(board, "[[Write-spec]]") != 1 || !strings.Contains(board, "- [x] [[Write-spec]]") {
+		t.Errorf("card was not moved to Done:\n%s", board)
+	}
+
+	if !strings.Contains(board, "- [ ] Call vendor") {
+		t.Errorf("manual card was dropped:\n%s", board)
+	}
+}
+
+func TestKanbanItemTypesIgnoreCase(t *testing.T) {
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{"kanban_item_types": []interface{}{"Task", "Bug"}}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	for _, itemType := range []string{"task", "BUG", "Bug"} {
+		item := newKanbanTestItem("1", "Fix login", "open")
+		item.SetItemType(itemType)
+
+		if !target.isKanbanItem(item) {
+			t.Errorf("item type %q is not on the board for kanban_item_types [Task, Bug]", itemType)
+		}
+	}
+
+	if target.isKanbanItem(models.NewBasicItem("n", "Meeting notes")) {
+		t.Error("an item of another type should not be on the board")
+	}
+}
@@ -0,0 +1,85 @@
+package obsidian
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"pkm-sync/pkg/models"
+)
+
+// Link styles supported by link_format.
+const (
+	linkFormatWikilink = "wikilink"
+	linkFormatMarkdown = "markdown"
+)
+
+// formatNoteLink links to a note in the vault: [[note|display]] or [display](note.md).
+func (o *ObsidianTarget) formatNoteLink(note, display string) string {
+	if o.linkFormat == linkFormatMarkdown {
+		if display == "" {
+			display = note
+		}
+
+		return fmt.Sprintf("[%s](%s)", display, escapeLinkPath(note+o.GetFileExtension()))
+	}
+
+	if display == "" || display == note {
+		return "[[" + note + "]]"
+	}
+
+	return "[[" + note + "|" + display + "]]"
+}
+
+// formatNoteEmbed embeds a note in the vault: ![[note]] or ![note](note.md).
+func (o *ObsidianTarget) formatNoteEmbed(note string) string {
+	return "!" + o.formatNoteLink(note, "")
+}
+
+// formatFileLink links to a non-note file in the vault such as a downloaded attachment.
+func (o *ObsidianTarget) formatFileLink(filePath, display string) string {
+	filePath = strings.TrimPrefix(path.Clean(strings.ReplaceAll(filePath, "\\", "/")), "./")
+
+	if o.linkFormat == linkFormatMarkdown {
+		if display == "" {
+			display = path.Base(filePath)
+		}
+
+		return fmt.Sprintf("[%s](%s)", display, escapeLinkPath(filePath))
+	}
+
+	if display == "" || display == path.Base(filePath) {
+		return "[[" + filePath + "]]"
+	}
+
+	return "[[" + filePath + "|" + display + "]]"
+}
+
+// formatAttachmentLink links to an attachment: local copies use the vault link style, remote ones a URL.
+func (o *ObsidianTarget) formatAttachmentLink(attachment models.Attachment) string {
+	if o.attachments != nil {
+		if relPath, stored := o.attachments.lookup(attachment); stored {
+			return o.formatStoredAttachmentLink(relPath, attachment.Name)
+		}
+	}
+
+	switch {
+	case attachment.LocalPath != "":
+		return o.formatFileLink(attachment.LocalPath, attachment.Name)
+	case attachment.URL != "":
+		return fmt.Sprintf("[%s](%s)", attachment.Name, attachment.URL)
+	default:
+		return attachment.Name
+	}
+}
+
+// escapeLinkPath percent-encodes each path segment so markdown links survive spaces and parentheses.
+func escapeLinkPath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
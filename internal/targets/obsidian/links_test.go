@@ -0,0 +1,84 @@
+package obsidian
+
+import (
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestLinkFormat(t *testing.T) {
+	tests := []struct {
+		name       string
+		format     string
+		note       string
+		attachment models.Attachment
+		wantNote   string
+		wantEmbed  string
+		wantAttach string
+	}{
+		{
+			name:       "wikilink",
+			format:     "wikilink",
+			note:       "Weekly sync",
+			attachment: models.Attachment{Name: "Q1 report.pdf", LocalPath: "Attachments/Q1 report.pdf"},
+			wantNote:   "[[Weekly sync]]",
+			wantEmbed:  "![[Weekly sync]]",
+			wantAttach: "[[Attachments/Q1 report.pdf]]",
+		},
+		{
+			name:       "markdown",
+			format:     "markdown",
+			note:       "Weekly sync",
+			attachment: models.Attachment{Name: "Q1 report.pdf", LocalPath: "Attachments/Q1 report.pdf"},
+			wantNote:   "[Weekly sync](Weekly%20sync.md)",
+			wantEmbed:  "![Weekly sync](Weekly%20sync.md)",
+			wantAttach: "[Q1 report.pdf](Attachments/Q1%20report.pdf)",
+		},
+		{
+			name:       "remote attachment keeps URL",
+			format:     "wikilink",
+			note:       "Note",
+			attachment: models.Attachment{Name: "doc", URL: "https://example.com/doc"},
+			wantNote:   "[[Note]]",
+			wantEmbed:  "![[Note]]",
+			wantAttach: "[doc](https://example.com/doc)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := NewObsidianTarget()
+			if err := target.Configure(map[string]interface{}{"link_format": tt.format}); err != nil {
+				t.Fatalf("Configure() error = %v", err)
+			}
+
+			if got := target.formatNoteLink(tt.note, ""); got != tt.wantNote {
+				t.Errorf("formatNoteLink() = %q, want %q", got, tt.wantNote)
+			}
+
+			if got := target.formatNoteEmbed(tt.note); got != tt.wantEmbed {
+				t.Errorf("formatNoteEmbed() = %q, want %q", got, tt.wantEmbed)
+			}
+
+			if got := target.formatAttachmentLink(tt.attachment); got != tt.wantAttach {
+				t.Errorf("formatAttachmentLink() = %q, want %q", got, tt.wantAttach)
+			}
+		})
+	}
+}
+
+func TestMarkdownLinkFormatAttendees(t *testing.T) {
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{"link_format": "markdown"}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	got := target.formatAttendeesAs("attendees", []models.Attendee{{DisplayName: "Alice"}})
+	if want := "attendees:\n  - \"[Alice](Alice.md)\"\n"; got != want {
+		t.Errorf("formatAttendeesAs() = %q, want %q", got, want)
+	}
+
+	if err := target.Configure(map[string]interface{}{"link_format": "html"}); err == nil {
+		t.Error("Configure() expected error for unsupported link_format")
+	}
+}
@@ -0,0 +1,363 @@
+package obsidian
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+const (
+	defaultPeopleFolder  = "People"
+	defaultPeopleHeading = "## Meetings"
+	peopleEmailsHeading  = "## Emails"
+
+	// signatureMetadataKey holds the contact the signature_removal transformer parsed from a signature
+	signatureMetadataKey = "signature"
+
+	personMeetingDateFormat = "2006-01-02"
+)
+
+// personNoteUpdate describes the meeting and email links that should be present in a single person note,
+// and the contact details known from their email signatures.
+type personNoteUpdate struct {
+	name    string
+	email   string
+	path    string
+	links   []string
+	emails  []string
+	contact models.Contact
+}
+
+// parsePersonNames reads the person_names mapping of email addresses to person note names.
+func parsePersonNames(value interface{}) (map[string]string, error) {
+	return parseNoteNames("person_names", "email addresses", value)
+}
+
+// parseNoteNames reads a mapping of lower-cased keys, such as email addresses, to note names.
+func parseNoteNames(option, keys string, value interface{}) (map[string]string, error) {
+	names := make(map[string]string)
+
+	switch v := value.(type) {
+	case map[string]string:
+		for key, name := range v {
+			names[strings.ToLower(strings.TrimSpace(key))] = name
+		}
+	case map[string]interface{}:
+		for key, name := range v {
+			s, ok := name.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s.%s must be a string, got %T", option, key, name)
+			}
+
+			names[strings.ToLower(strings.TrimSpace(key))] = s
+		}
+	default:
+		return nil, fmt.Errorf("%s must be a map of %s to note names, got %T", option, keys, value)
+	}
+
+	return names, nil
+}
+
+// attendeesOf reads attendees or an organizer from metadata, whether stored as models or decoded from JSON/YAML.
+func attendeesOf(value interface{}) ([]models.Attendee, bool) {
+	return models.AsAttendees(value)
+}
+
+// personName returns the note name for a person: the person_names entry for their email, else their display name.
+func (o *ObsidianTarget) personName(attendee models.Attendee) string {
+	if name := o.personNames[strings.ToLower(attendee.Email)]; name != "" {
+		return name
+	}
+
+	return attendee.GetDisplayName()
+}
+
+// formatPersonLink links to a person's note, named by the filename policy, showing the person's name.
+func (o *ObsidianTarget) formatPersonLink(attendee models.Attendee) string {
+	name := o.personName(attendee)
+
+	return o.formatNoteLink(strings.TrimSuffix(o.FormatFilename(name), o.GetFileExtension()), name)
+}
+
+// eventPeople returns the organizer and attendees of an event, each person once.
+func eventPeople(item models.ItemInterface) []models.Attendee {
+	var people []models.Attendee
+
+	seen := make(map[string]bool)
+
+	for _, key := range []string{"organizer", "attendees"} {
+		attendees, ok := attendeesOf(item.GetMetadata()[key])
+		if !ok {
+			continue
+		}
+
+		for _, attendee := range attendees {
+			id := strings.ToLower(attendee.Email)
+			if id == "" {
+				id = attendee.DisplayName
+			}
+
+			if id == "" || seen[id] {
+				continue
+			}
+
+			seen[id] = true
+			people = append(people, attendee)
+		}
+	}
+
+	return people
+}
+
+// signatureContact returns the contact parsed from an item's email signature, if any.
+func signatureContact(item models.ItemInterface) (models.Contact, bool) {
+	switch contact := item.GetMetadata()[signatureMetadataKey].(type) {
+	case models.Contact:
+		return contact, contact.Name != ""
+	case *models.Contact:
+		if contact != nil {
+			return *contact, contact.Name != ""
+		}
+	}
+
+	return models.Contact{}, false
+}
+
+// collectPersonNoteUpdates gathers a meeting history link per event for every organizer and attendee,
+// and an email link with contact details for every sender whose signature was captured.
+func (o *ObsidianTarget) collectPersonNoteUpdates(items []models.FullItem, outputDir string) []personNoteUpdate {
+	byName := make(map[string]*personNoteUpdate)
+
+	sorted := make([]models.FullItem, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].GetCreatedAt().Before(sorted[j].GetCreatedAt())
+	})
+
+	updateFor := func(person models.Attendee) *personNoteUpdate {
+		name := o.personName(person)
+
+		update, exists := byName[name]
+		if !exists {
+			update = &personNoteUpdate{
+				name:  name,
+				email: person.Email,
+				path:  filepath.Join(outputDir, o.peopleFolder, o.FormatFilename(name)),
+			}
+			byName[name] = update
+		}
+
+		if update.email == "" {
+			update.email = person.Email
+		}
+
+		return update
+	}
+
+	for _, item := range sorted {
+		link := fmt.Sprintf("- %s %s",
+			item.GetCreatedAt().Format(personMeetingDateFormat), o.formatNoteLink(o.noteName(item), item.GetTitle()))
+
+		for _, person := range eventPeople(item) {
+			update := updateFor(person)
+			update.links = append(update.links, link)
+		}
+
+		if contact, ok := signatureContact(item); ok {
+			update := updateFor(contact.Attendee())
+			update.emails = append(update.emails, link)
+			update.contact = mergeContact(update.contact, contact)
+		}
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	updates := make([]personNoteUpdate, 0, len(names))
+	for _, name := range names {
+		updates = append(updates, *byName[name])
+	}
+
+	return updates
+}
+
+// mergeContact fills in contact details, preferring those of the newer signature.
+func mergeContact(older, newer models.Contact) models.Contact {
+	merged := newer
+
+	for _, field := range []struct{ value, fallback *string }{
+		{&merged.Title, &older.Title},
+		{&merged.Company, &older.Company},
+		{&merged.Phone, &older.Phone},
+		{&merged.Email, &older.Email},
+	} {
+		if *field.value == "" {
+			*field.value = *field.fallback
+		}
+	}
+
+	return merged
+}
+
+// contactProperties lists the frontmatter properties of a person's contact details.
+func (update personNoteUpdate) contactProperties() [][2]string {
+	var properties [][2]string
+
+	for _, property := range [][2]string{
+		{"email", update.email},
+		{"title", update.contact.Title},
+		{"company", update.contact.Company},
+		{"phone", update.contact.Phone},
+	} {
+		if property[1] != "" {
+			properties = append(properties, property)
+		}
+	}
+
+	return properties
+}
+
+// mergeFrontmatterProperties adds the properties a note's frontmatter does not have yet, creating the
+// frontmatter when the note has none. Values already in the note are never replaced.
+func mergeFrontmatterProperties(content string, properties [][2]string) string {
+	var lines []string
+
+	body := "\n" + content
+	if strings.HasPrefix(content, "---\n") {
+		if end := strings.Index(content[4:], "\n---"); end >= 0 {
+			lines = strings.Split(content[4:4+end], "\n")
+			body = strings.TrimPrefix(content[4+end+4:], "\n")
+		}
+	}
+
+	added := false
+
+	for _, property := range properties {
+		exists := false
+
+		for _, line := range lines {
+			if strings.HasPrefix(line, property[0]+":") {
+				exists = true
+
+				break
+			}
+		}
+
+		if !exists {
+			lines = append(lines, fmt.Sprintf("%s: %s", property[0], utils.YAMLString(property[1])))
+			added = true
+		}
+	}
+
+	if !added {
+		return content
+	}
+
+	return "---\n" + strings.Join(lines, "\n") + "\n---\n" + body
+}
+
+// personNoteContent adds an update's links and contact details to a person note.
+func (o *ObsidianTarget) personNoteContent(base string, update personNoteUpdate) string {
+	content := base
+
+	if len(update.links) > 0 {
+		content = mergeLinksUnderHeading(content, defaultPeopleHeading, update.links)
+	}
+
+	if len(update.emails) > 0 {
+		content = mergeLinksUnderHeading(content, peopleEmailsHeading, update.emails)
+	}
+
+	if o.writesFrontmatter() {
+		content = mergeFrontmatterProperties(content, update.contactProperties())
+	}
+
+	return content
+}
+
+// readPersonNote returns the current person note content, or a fresh note with the person's name.
+func (o *ObsidianTarget) readPersonNote(update personNoteUpdate) (string, error) {
+	data, err := os.ReadFile(update.path)
+	if err == nil {
+		return string(data), nil
+	}
+
+	if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read person note %s: %w", update.path, err)
+	}
+
+	// The email and contact details are added to the frontmatter by personNoteContent
+	return fmt.Sprintf("# %s\n", update.name), nil
+}
+
+// updatePersonNotes adds the exported meetings to each participant's meeting history.
+func (o *ObsidianTarget) updatePersonNotes(items []models.FullItem, outputDir string) error {
+	for _, update := range o.collectPersonNoteUpdates(items, outputDir) {
+		existing, err := o.readPersonNote(update)
+		if err != nil {
+			return err
+		}
+
+		content := o.personNoteContent(existing, update)
+		if content == existing {
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(update.path), 0755); err != nil {
+			return fmt.Errorf("failed to create people folder: %w", err)
+		}
+
+		if err := os.WriteFile(update.path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write person note %s: %w", update.path, err)
+		}
+	}
+
+	return nil
+}
+
+// previewPersonNotes generates previews for person notes that would change.
+func (o *ObsidianTarget) previewPersonNotes(items []models.FullItem, outputDir string) ([]*interfaces.FilePreview, error) {
+	var previews []*interfaces.FilePreview
+
+	for _, update := range o.collectPersonNoteUpdates(items, outputDir) {
+		var existingContent string
+
+		if data, err := os.ReadFile(update.path); err == nil {
+			existingContent = string(data)
+		}
+
+		base, err := o.readPersonNote(update)
+		if err != nil {
+			return nil, err
+		}
+
+		content := o.personNoteContent(base, update)
+
+		action := "create"
+		if existingContent != "" {
+			action = "update"
+			if content == existingContent {
+				action = "skip"
+			}
+		}
+
+		previews = append(previews, &interfaces.FilePreview{
+			FilePath:        update.path,
+			Action:          action,
+			Content:         content,
+			ExistingContent: existingContent,
+		})
+	}
+
+	return previews, nil
+}
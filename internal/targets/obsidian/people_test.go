@@ -0,0 +1,130 @@
+package obsidian
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestExportUpdatesPersonNotes(t *testing.T) {
+	outputDir := t.TempDir()
+
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{
+		"person_notes": true,
+		"person_names": map[string]interface{}{"Carol@Example.com": "Carol Jones"},
+	}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	item := newDailyNoteTestItem("evt-1", "Roadmap review", time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+	item.SetItemType("event")
+	item.SetMetadata(map[string]interface{}{
+		"organizer": models.Attendee{Email: "carol@example.com", DisplayName: "Carol"},
+		"attendees": []models.Attendee{
+			{Email: "carol@example.com", DisplayName: "Carol"},
+			{Email: "dave@example.com", DisplayName: "Dave"},
+		},
+	})
+
+	for i := 0; i < 2; i++ {
+		if err := target.Export([]models.FullItem{item}, outputDir); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+	}
+
+	note, err := os.ReadFile(filepath.Join(outputDir, "Roadmap-review.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	carolLink := "[[Carol-Jones|Carol Jones]]"
+	for _, want := range []string{`organizer: "` + carolLink + `"`, `  - "` + carolLink + `"`, `  - "[[Dave]]"`} {
+		if !strings.Contains(string(note), want) {
+			t.Errorf("event note missing %s:\n%s", want, note)
+		}
+	}
+
+	// The link resolves to the person note it names
+	linked, _, _ := strings.Cut(strings.TrimPrefix(carolLink, "[["), "|")
+
+	carol, err := os.ReadFile(filepath.Join(outputDir, "People", linked+".md"))
+	if err != nil {
+		t.Fatalf("person note not written where %s points: %v", carolLink, err)
+	}
+
+	expected := "---\nemail: carol@example.com\n---\n\n# Carol Jones\n\n## Meetings\n\n" +
+		"- 2025-01-15 [[Roadmap-review|Roadmap review]]\n"
+	if string(carol) != expected {
+		t.Errorf("person note =\n%q\nwant\n%q", string(carol), expected)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "People", "Dave.md")); err != nil {
+		t.Errorf("attendee note not written: %v", err)
+	}
+}
+
+func TestAttendeesOfDecodedMetadata(t *testing.T) {
+	attendees, ok := attendeesOf([]interface{}{
+		map[string]interface{}{"Email": "erin@example.com", "DisplayName": "Erin"},
+		"Frank",
+	})
+	if !ok || len(attendees) != 2 || attendees[0].Email != "erin@example.com" || attendees[1].DisplayName != "Frank" {
+		t.Errorf("attendeesOf() = %+v, %v", attendees, ok)
+	}
+}
+
+func TestSignatureContactsUpdatePersonNotes(t *testing.T) {
+	outputDir := t.TempDir()
+
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{"person_notes": true}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	// An existing note keeps its content and only gains the missing contact details
+	peopleDir := filepath.Join(outputDir, "People")
+	if err := os.MkdirAll(peopleDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	existing := "---\ntitle: VP Engineering\n---\n\n# Jane Doe\n\nMet at the offsite.\n"
+	if err := os.WriteFile(filepath.Join(peopleDir, "Jane-Doe.md"), []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	item := newDailyNoteTestItem("msg-1", "Budget", time.Date(2025, 2, 3, 9, 0, 0, 0, time.UTC))
+	item.SetItemType("email")
+	item.SetMetadata(map[string]interface{}{
+		"signature": models.Contact{Name: "Jane Doe", Title: "Engineering Manager", Company: "ACME Corp",
+			Phone: "+1 555 123 4567", Email: "jane@acme.com"},
+	})
+
+	if err := target.Export([]models.FullItem{item}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	email, err := os.ReadFile(filepath.Join(outputDir, "Budget.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(email), `signature: "[[Jane-Doe|Jane Doe]]"`) {
+		t.Errorf("email note missing signature person link:\n%s", email)
+	}
+
+	jane, err := os.ReadFile(filepath.Join(peopleDir, "Jane-Doe.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "---\ntitle: VP Engineering\nemail: jane@acme.com\ncompany: ACME Corp\nphone: +1 555 123 4567\n---\n\n" +
+		"# Jane Doe\n\nMet at the offsite.\n\n## Emails\n\n- 2025-02-03 [[Budget]]\n"
+	if string(jane) != expected {
+		t.Errorf("person note =\n%q\nwant\n%q", string(jane), expected)
+	}
+}
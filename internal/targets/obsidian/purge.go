@@ -0,0 +1,280 @@
+package obsidian
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"pkm-sync/internal/statestore"
+	"pkm-sync/pkg/interfaces"
+)
+
+// Purge removes the notes the filter selects, the attachments and downloaded images no remaining note
+// links to, and their entries in the vault indexes. Notes built from many items (daily, people, index and
+// Kanban notes) record no source and are left as they are. A dry run only reports what would be removed.
+func (o *ObsidianTarget) Purge(outputDir string, filter interfaces.PurgeFilter, dryRun bool) (interfaces.PurgeReport, error) {
+	var report interfaces.PurgeReport
+
+	// The filter's tag is an item tag, such as "source:gmail_personal"; notes carry it as written
+	if filter.Tag != "" {
+		tag := o.noteTag(filter.Tag)
+		if tag == "" {
+			return report, fmt.Errorf("tag_names drops the '%s' tag, so the notes to purge cannot be told apart",
+				filter.Tag)
+		}
+
+		filter.Tag = tag
+	}
+
+	attachmentDir := filepath.Join(outputDir, o.attachmentFolder)
+
+	purged := make(map[string]bool)
+
+	var kept []string
+
+	err := filepath.WalkDir(outputDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			if path != outputDir && (strings.HasPrefix(entry.Name(), ".") || path == attachmentDir) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if filepath.Ext(path) != o.GetFileExtension() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(outputDir, path)
+		if err != nil {
+			return err
+		}
+
+		sourceType, created := o.readNoteOrigin(path)
+		tags, _ := o.readNoteTags(path)
+
+		if filter.Matches(sourceType, created, tags) {
+			purged[filepath.ToSlash(rel)] = true
+			report.Notes = append(report.Notes, filepath.ToSlash(rel))
+		} else {
+			kept = append(kept, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return report, fmt.Errorf("failed to scan %s: %w", outputDir, err)
+	}
+
+	if len(purged) == 0 {
+		return report, nil
+	}
+
+	report.Attachments = orphanedAttachments(outputDir, attachmentDir, report.Notes, kept)
+
+	removed := make(map[string]bool, len(purged)+len(report.Attachments))
+	for path := range purged {
+		removed[path] = true
+	}
+
+	for _, path := range report.Attachments {
+		removed[path] = true
+	}
+
+	for _, index := range []string{
+		filepath.Join(outputDir, eventIndexFile),
+		filepath.Join(outputDir, taskIndexFile),
+		filepath.Join(outputDir, tagIndexFile),
+		filepath.Join(outputDir, crossLinkIndexFile),
+		filepath.Join(outputDir, importantIndexFile),
+		filepath.Join(attachmentDir, attachmentIndexFile),
+		filepath.Join(attachmentDir, imageCacheFile),
+	} {
+		count, err := pruneIndex(index, removed, dryRun)
+		if err != nil {
+			return report, err
+		}
+
+		report.Records += count
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	for _, rel := range append(append([]string{}, report.Notes...), report.Attachments...) {
+		if err := os.Remove(filepath.Join(outputDir, filepath.FromSlash(rel))); err != nil && !os.IsNotExist(err) {
+			return report, fmt.Errorf("failed to remove %s: %w", rel, err)
+		}
+	}
+
+	return report, nil
+}
+
+// readNoteOrigin reads the source and created properties of a note from its frontmatter or, for notes
+// written with Dataview inline fields, its "source::" and "created::" fields, under their property_names.
+func (o *ObsidianTarget) readNoteOrigin(path string) (string, time.Time) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", time.Time{}
+	}
+	defer file.Close()
+
+	var sourceType, created string
+
+	frontmatter := false
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	for lineNum := 0; scanner.Scan(); lineNum++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+
+		if line == "---" {
+			if lineNum > 0 {
+				break
+			}
+
+			frontmatter = true
+
+			continue
+		}
+
+		// Without frontmatter only a title heading and the inline fields after it are read
+		if !frontmatter && line != "" && !strings.HasPrefix(line, "# ") && !strings.Contains(line, ":: ") {
+			break
+		}
+
+		for _, field := range []struct {
+			value *string
+			key   string
+		}{{&sourceType, o.propertyNames.Name("source")}, {&created, o.propertyNames.Name("created")}} {
+			for _, marker := range []string{field.key + ":: ", field.key + ": "} {
+				if value, found := strings.CutPrefix(line, marker); found {
+					*field.value = strings.Trim(strings.TrimSpace(value), `"'`)
+				}
+			}
+		}
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, created)
+	if err != nil {
+		createdAt, _ = time.Parse("2006-01-02", created)
+	}
+
+	return sourceType, createdAt
+}
+
+// orphanedAttachments returns the attachments and downloaded images linked from purged notes that no kept
+// note links to.
+func orphanedAttachments(outputDir, attachmentDir string, purged, kept []string) []string {
+	stored := make(map[string]bool)
+
+	var attachments map[string]string
+	if data, err := statestore.ReadFile(filepath.Join(attachmentDir, attachmentIndexFile)); err == nil &&
+		json.Unmarshal(data, &attachments) == nil {
+		for _, path := range attachments {
+			stored[path] = true
+		}
+	}
+
+	for _, image := range loadImageCache(attachmentDir) {
+		stored[image.Path] = true
+	}
+
+	candidates := make(map[string]bool)
+
+	for _, rel := range purged {
+		content, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(rel)))
+		if err != nil {
+			continue
+		}
+
+		for path := range stored {
+			if strings.Contains(string(content), path) {
+				candidates[path] = true
+			}
+		}
+	}
+
+	for _, path := range kept {
+		if len(candidates) == 0 {
+			break
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		for candidate := range candidates {
+			if strings.Contains(string(content), candidate) {
+				delete(candidates, candidate)
+			}
+		}
+	}
+
+	orphans := make([]string, 0, len(candidates))
+	for path := range candidates {
+		orphans = append(orphans, path)
+	}
+
+	sort.Strings(orphans)
+
+	return orphans
+}
+
+// pruneIndex removes the entries of a vault index whose path, relative to the output directory, is in
+// removed, returning how many it removed. The indexes map keys to a path or to an object with a path.
+func pruneIndex(file string, removed map[string]bool, dryRun bool) (int, error) {
+	data, err := statestore.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+
+		return 0, fmt.Errorf("failed to read %s: %w", filepath.Base(file), err)
+	}
+
+	var index map[string]json.RawMessage
+	if err := json.Unmarshal(data, &index); err != nil {
+		return 0, fmt.Errorf("failed to parse %s: %w", filepath.Base(file), err)
+	}
+
+	count := 0
+
+	for key, raw := range index {
+		var entry struct {
+			Path string `json:"path"`
+		}
+
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			_ = json.Unmarshal(raw, &entry.Path)
+		}
+
+		if removed[filepath.ToSlash(entry.Path)] {
+			delete(index, key)
+			count++
+		}
+	}
+
+	if count == 0 || dryRun {
+		return count, nil
+	}
+
+	if data, err = json.MarshalIndent(index, "", "  "); err != nil {
+		return count, fmt.Errorf("failed to encode %s: %w", filepath.Base(file), err)
+	}
+
+	return count, statestore.WriteFile(file, data, 0644)
+}
+
+var _ interfaces.Purger = (*ObsidianTarget)(nil)
@@ -0,0 +1,162 @@
+package obsidian
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+func TestPurgeRemovesNotesAttachmentsAndRecords(t *testing.T) {
+	outputDir := t.TempDir()
+
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{"download_attachments": true}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	shared := base64.StdEncoding.EncodeToString([]byte("%PDF shared"))
+	private := base64.StdEncoding.EncodeToString([]byte("%PDF private"))
+
+	email := func(id, title string, created time.Time, attachments ...models.Attachment) models.FullItem {
+		item := models.NewBasicItem(id, title)
+		item.SetSourceType("gmail")
+		item.SetCreatedAt(created)
+		item.SetAttachments(attachments)
+
+		return item
+	}
+
+	old := email("1", "Old email", time.Date(2022, 5, 1, 9, 0, 0, 0, time.UTC),
+		models.Attachment{Name: "private.pdf", Data: private}, models.Attachment{Name: "shared.pdf", Data: shared})
+	recent := email("2", "Recent email", time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC),
+		models.Attachment{Name: "shared.pdf", Data: shared})
+
+	event := models.NewBasicItem("3", "Old meeting")
+	event.SetSourceType("google_calendar")
+	event.SetCreatedAt(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	if err := target.Export([]models.FullItem{old, recent, event}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	filter := interfaces.PurgeFilter{SourceType: "gmail", Before: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	preview, err := target.Purge(outputDir, filter, true)
+	if err != nil {
+		t.Fatalf("Purge() dry run error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "Old-email.md")); err != nil {
+		t.Fatalf("dry run removed the note: %v", err)
+	}
+
+	report, err := target.Purge(outputDir, filter, false)
+	if err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(report, preview) {
+		t.Errorf("dry run reported %+v, purge %+v", preview, report)
+	}
+
+	if !reflect.DeepEqual(report.Notes, []string{"Old-email.md"}) ||
+		!reflect.DeepEqual(report.Attachments, []string{"Attachments/private.pdf"}) {
+		t.Errorf("Purge() = %+v", report)
+	}
+
+	for _, path := range []string{"Old-email.md", "Attachments/private.pdf"} {
+		if _, err := os.Stat(filepath.Join(outputDir, path)); !os.IsNotExist(err) {
+			t.Errorf("%s not removed: %v", path, err)
+		}
+	}
+
+	for _, path := range []string{"Recent-email.md", "Old-meeting.md", "Attachments/shared.pdf"} {
+		if _, err := os.Stat(filepath.Join(outputDir, path)); err != nil {
+			t.Errorf("%s removed: %v", path, err)
+		}
+	}
+
+	// The attachment index no longer points at the removed file
+	if report.Records == 0 {
+		t.Error("no index records removed")
+	}
+
+	links, err := loadCrossLinkIndex(outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, found := links["1"]; found {
+		t.Error("link index still records the purged note")
+	}
+}
+
+func TestPurgeBySourceTag(t *testing.T) {
+	outputDir := t.TempDir()
+
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{"tag_prefix": "pkm/"}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	email := func(id, title, source string) models.FullItem {
+		item := models.NewBasicItem(id, title)
+		item.SetSourceType("gmail")
+		item.SetCreatedAt(time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC))
+		item.SetTags([]string{"source:" + source})
+
+		return item
+	}
+
+	items := []models.FullItem{email("1", "Personal email", "gmail_personal"), email("2", "Work email", "gmail_work")}
+	if err := target.Export(items, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	report, err := target.Purge(outputDir, interfaces.PurgeFilter{SourceType: "gmail", Tag: "source:gmail_personal"}, false)
+	if err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(report.Notes, []string{"Personal-email.md"}) {
+		t.Errorf("Purge() removed %v, want only the personal email", report.Notes)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "Work-email.md")); err != nil {
+		t.Errorf("note of the other source removed: %v", err)
+	}
+}
+
+func TestPurgeFilterMatches(t *testing.T) {
+	before := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	filter := interfaces.PurgeFilter{SourceType: "gmail", Tag: "source:gmail_personal", Before: before}
+
+	tests := []struct {
+		name       string
+		sourceType string
+		created    time.Time
+		tags       []string
+		want       bool
+	}{
+		{"matching", "gmail", before.AddDate(0, -1, 0), []string{"source:gmail_personal"}, true},
+		{"other source type", "google_calendar", before.AddDate(0, -1, 0), []string{"source:gmail_personal"}, false},
+		{"other source tag", "gmail", before.AddDate(0, -1, 0), []string{"source:gmail_work"}, false},
+		{"too recent", "gmail", before, []string{"source:gmail_personal"}, false},
+		{"no date", "gmail", time.Time{}, []string{"source:gmail_personal"}, false},
+		{"generated note", "", before.AddDate(0, -1, 0), nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filter.Matches(tt.sourceType, tt.created, tt.tags); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package obsidian
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"pkm-sync/internal/utils"
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+const (
+	// recurringEventKey is the metadata key linking calendar occurrences to their series.
+	recurringEventKey = "recurring_event_id"
+
+	rollingSectionFormat = "2006-01-02 15:04"
+
+	// rollingOccurrenceMarker records which occurrence a section holds; Obsidian hides %% comments.
+	rollingOccurrenceMarker = "%%%% occurrence: %s %%%%"
+)
+
+// rollingNoteUpdate collects the occurrences that belong in one rolling note.
+type rollingNoteUpdate struct {
+	id          string
+	title       string
+	path        string
+	occurrences []models.FullItem
+}
+
+// seriesOf returns the series key and rolling note title for an item, if it should be appended to a rolling note.
+// Recurring calendar events are grouped by series when rolling_notes is enabled; recurring reports are matched
+// by the title prefixes in rolling_note_titles.
+func (o *ObsidianTarget) seriesOf(item models.ItemInterface) (string, string, bool) {
+	if o.rollingNotes {
+		if seriesID := toString(item.GetMetadata()[recurringEventKey]); seriesID != "" && seriesID != "<nil>" {
+			return seriesID, item.GetTitle(), true
+		}
+	}
+
+	for _, title := range o.rollingNoteTitles {
+		if strings.HasPrefix(strings.ToLower(item.GetTitle()), strings.ToLower(title)) {
+			return utils.Slugify(title), title, true
+		}
+	}
+
+	return "", "", false
+}
+
+// rollingNoteID is the id recorded in a rolling note's frontmatter.
+func rollingNoteID(seriesKey string) string {
+	return "series-" + seriesKey
+}
+
+// rollingNoteDir keeps a series in one note regardless of date-based subdirectories.
+func (o *ObsidianTarget) rollingNoteDir(item models.ItemInterface, outputDir string) string {
+	return filepath.Join(outputDir, o.layout.ItemFolder(item))
+}
+
+// collectRollingNoteUpdates groups occurrences by rolling note, ordered by note path.
+func (o *ObsidianTarget) collectRollingNoteUpdates(items []models.FullItem, outputDir string) []rollingNoteUpdate {
+	byPath := make(map[string]*rollingNoteUpdate)
+
+	for _, item := range items {
+		key, title, ok := o.seriesOf(item)
+		if !ok {
+			continue
+		}
+
+		path := o.itemPath(item, outputDir)
+
+		update, exists := byPath[path]
+		if !exists {
+			update = &rollingNoteUpdate{id: rollingNoteID(key), title: title, path: path}
+			byPath[path] = update
+		}
+
+		update.occurrences = append(update.occurrences, item)
+	}
+
+	paths := make([]string, 0, len(byPath))
+	for path := range byPath {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	updates := make([]rollingNoteUpdate, 0, len(paths))
+
+	for _, path := range paths {
+		update := byPath[path]
+		sort.SliceStable(update.occurrences, func(i, j int) bool {
+			return update.occurrences[i].GetCreatedAt().Before(update.occurrences[j].GetCreatedAt())
+		})
+		updates = append(updates, *update)
+	}
+
+	return updates
+}
+
+// newRollingNote returns the header of a rolling note that does not exist yet.
+func (o *ObsidianTarget) newRollingNote(update rollingNoteUpdate) string {
+	var sb strings.Builder
+
+	first := update.occurrences[0]
+
+	if o.writesFrontmatter() {
+		sb.WriteString("---\n")
+		sb.WriteString(o.propertyNames.Name("id") + ": " + utils.YAMLString(update.id) + "\n")
+		sb.WriteString(o.propertyNames.Name("source") + ": " + utils.YAMLString(first.GetSourceType()) + "\n")
+		sb.WriteString(o.propertyNames.Name("type") + ": " + utils.YAMLString(first.GetItemType()) + "\n")
+		sb.WriteString("---\n\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("# %s\n", update.title))
+
+	return sb.String()
+}
+
+// formatOccurrenceSection renders one occurrence as a dated section of a rolling note.
+func (o *ObsidianTarget) formatOccurrenceSection(item models.ItemInterface) string {
+	var sb strings.Builder
+
+	heading := "Undated"
+	if !item.GetCreatedAt().IsZero() {
+		heading = item.GetCreatedAt().Format(rollingSectionFormat)
+	}
+
+	sb.WriteString(fmt.Sprintf("## %s\n", heading))
+	sb.WriteString(fmt.Sprintf(rollingOccurrenceMarker+"\n\n", item.GetID()))
+
+	if content := o.itemContent(item); content != "" {
+		sb.WriteString(content)
+		sb.WriteString("\n\n")
+	}
+
+	if thread, ok := models.AsThread(item); ok {
+		for i, message := range thread.GetMessages() {
+			o.formatThreadMessage(&sb, i+1, message)
+		}
+	}
+
+	for _, attachment := range item.GetAttachments() {
+		sb.WriteString("- " + o.formatAttachmentLink(attachment) + "\n")
+	}
+
+	for _, link := range item.GetLinks() {
+		sb.WriteString(fmt.Sprintf("- [%s](%s)\n", link.Title, link.URL))
+	}
+
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
+
+// appendOccurrences adds sections for occurrences not yet in the note; existing sections are left untouched
+// so notes written under earlier occurrences are kept.
+func (o *ObsidianTarget) appendOccurrences(content string, occurrences []models.FullItem) string {
+	for _, item := range occurrences {
+		if strings.Contains(content, fmt.Sprintf(rollingOccurrenceMarker, item.GetID())) {
+			continue
+		}
+
+		content = strings.TrimRight(content, "\n") + "\n\n" + o.formatOccurrenceSection(item)
+	}
+
+	return content
+}
+
+// readRollingNote returns the current rolling note content, or a fresh header.
+func (o *ObsidianTarget) readRollingNote(update rollingNoteUpdate) (string, error) {
+	data, err := os.ReadFile(update.path)
+	if err == nil {
+		return string(data), nil
+	}
+
+	if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read rolling note %s: %w", update.path, err)
+	}
+
+	return o.newRollingNote(update), nil
+}
+
+// updateRollingNotes appends new occurrences to their rolling notes.
+func (o *ObsidianTarget) updateRollingNotes(items []models.FullItem, outputDir string) error {
+	for _, update := range o.collectRollingNoteUpdates(items, outputDir) {
+		existing, err := o.readRollingNote(update)
+		if err != nil {
+			return err
+		}
+
+		content := o.appendOccurrences(existing, update.occurrences)
+		if content == existing {
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(update.path), 0755); err != nil {
+			return fmt.Errorf("failed to create rolling note folder: %w", err)
+		}
+
+		if err := os.WriteFile(update.path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write rolling note %s: %w", update.path, err)
+		}
+	}
+
+	return nil
+}
+
+// previewRollingNotes generates previews for rolling notes that would change.
+func (o *ObsidianTarget) previewRollingNotes(items []models.FullItem, outputDir string) ([]*interfaces.FilePreview, error) {
+	var previews []*interfaces.FilePreview
+
+	for _, update := range o.collectRollingNoteUpdates(items, outputDir) {
+		var existingContent string
+
+		if data, err := os.ReadFile(update.path); err == nil {
+			existingContent = string(data)
+		}
+
+		base, err := o.readRollingNote(update)
+		if err != nil {
+			return nil, err
+		}
+
+		content := o.appendOccurrences(base, update.occurrences)
+
+		action := "create"
+		if existingContent != "" {
+			action = "update"
+			if content == existingContent {
+				action = "skip"
+			}
+		}
+
+		previews = append(previews, &interfaces.FilePreview{
+			FilePath:        update.path,
+			Action:          action,
+			Content:         content,
+			ExistingContent: existingContent,
+		})
+	}
+
+	return previews, nil
+}
+
+func (o *ObsidianTarget) rollingNotesEnabled() bool {
+	return o.rollingNotes || len(o.rollingNoteTitles) > 0
+}
@@ -0,0 +1,105 @@
+package obsidian
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func newOccurrence(id string, start time.Time, content string) models.FullItem {
+	item := models.NewBasicItem(id, "Team standup")
+	item.SetCreatedAt(start)
+	item.SetSourceType("google_calendar")
+	item.SetItemType("event")
+	item.SetContent(content)
+	item.SetMetadata(map[string]interface{}{recurringEventKey: "standup-series"})
+
+	return item
+}
+
+func TestExportAppendsRecurringEventsToRollingNote(t *testing.T) {
+	outputDir := t.TempDir()
+
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{"rolling_notes": true}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	first := newOccurrence("standup_20250113", time.Date(2025, 1, 13, 9, 0, 0, 0, time.UTC), "Agenda one")
+	second := newOccurrence("standup_20250114", time.Date(2025, 1, 14, 9, 0, 0, 0, time.UTC), "Agenda two")
+
+	if err := target.Export([]models.FullItem{first}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	notePath := filepath.Join(outputDir, "Team-standup.md")
+
+	// Notes written under an earlier occurrence survive later syncs
+	data, err := os.ReadFile(notePath)
+	if err != nil {
+		t.Fatalf("rolling note not written: %v", err)
+	}
+
+	if err := os.WriteFile(notePath, []byte(strings.Replace(string(data), "Agenda one", "Agenda one\nMy notes", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := target.Export([]models.FullItem{second, first}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	data, err = os.ReadFile(notePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "---\nid: series-standup-series\nsource: google_calendar\ntype: event\n---\n\n" +
+		"# Team standup\n\n" +
+		"## 2025-01-13 09:00\n%% occurrence: standup_20250113 %%\n\nAgenda one\nMy notes\n\n" +
+		"## 2025-01-14 09:00\n%% occurrence: standup_20250114 %%\n\nAgenda two\n"
+	if string(data) != expected {
+		t.Errorf("rolling note =\n%q\nwant\n%q", string(data), expected)
+	}
+
+	entries, _ := os.ReadDir(outputDir)
+	if len(entries) != 1 {
+		t.Errorf("expected only the rolling note in the output directory, got %d entries", len(entries))
+	}
+}
+
+func TestNewRollingNoteQuotesProperties(t *testing.T) {
+	target := NewObsidianTarget()
+
+	occurrence := newOccurrence("standup_20250113", time.Date(2025, 1, 13, 9, 0, 0, 0, time.UTC), "")
+	occurrence.SetSourceType("calendar: work")
+
+	header := target.newRollingNote(rollingNoteUpdate{id: "series-standup: daily", title: "Team standup",
+		occurrences: []models.ItemInterface{occurrence}})
+
+	expected := "---\nid: \"series-standup: daily\"\nsource: \"calendar: work\"\ntype: event\n---\n\n# Team standup\n"
+	if header != expected {
+		t.Errorf("newRollingNote() =\n%q\nwant\n%q", header, expected)
+	}
+}
+
+func TestSeriesOfMatchesReportTitles(t *testing.T) {
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{"rolling_note_titles": []interface{}{"Weekly Status"}}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	report := models.NewBasicItem("r1", "weekly status - 2025-01-17")
+	if key, title, ok := target.seriesOf(report); !ok || key != "weekly-status" || title != "Weekly Status" {
+		t.Errorf("seriesOf() = %q, %q, %v", key, title, ok)
+	}
+
+	// Recurring events are only grouped when rolling_notes is enabled
+	event := newOccurrence("e1", time.Now(), "")
+	if _, _, ok := target.seriesOf(event); ok {
+		t.Error("seriesOf() grouped a recurring event without rolling_notes")
+	}
+}
@@ -0,0 +1,320 @@
+package obsidian
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"pkm-sync/internal/statestore"
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+// tagIndexFile records the tags each note had when it was last written, so tags added or removed in the
+// vault can be found on the next sync.
+const tagIndexFile = ".pkm-sync-tags.json"
+
+// inlineTagPattern matches #tags in note text the way Obsidian does: after whitespace or at the start of a
+// line, with at least one character that is not a digit.
+var inlineTagPattern = regexp.MustCompile(`(?:^|[\s(])#([\p{L}\p{N}_/-]*[\p{L}_/-][\p{L}\p{N}_/-]*)`)
+
+// repeatedDashes matches the runs of dashes left where spaces and dropped characters were.
+var repeatedDashes = regexp.MustCompile(`-{2,}`)
+
+// parseTagNames reads the tag_names mapping of source tags to the tags written instead. Keys match
+// case-insensitively; an empty tag drops the source tag.
+func parseTagNames(value interface{}) (map[string]string, error) {
+	names := make(map[string]string)
+
+	switch v := value.(type) {
+	case map[string]string:
+		for tag, name := range v {
+			names[strings.ToLower(strings.TrimSpace(tag))] = name
+		}
+	case map[string]interface{}:
+		for tag, name := range v {
+			s, ok := name.(string)
+			if !ok && name != nil {
+				return nil, fmt.Errorf("tag_names.%s must be a string, got %T", tag, name)
+			}
+
+			names[strings.ToLower(strings.TrimSpace(tag))] = s
+		}
+	default:
+		return nil, fmt.Errorf("tag_names must be a map of tags to tags, got %T", value)
+	}
+
+	return names, nil
+}
+
+// sanitizeTag turns text, such as a Gmail label or Slack channel, into a tag Obsidian accepts: whitespace
+// becomes "-", characters other than letters, digits, "_", "-" and "/" (emoji, punctuation) are dropped and
+// empty nesting levels are removed. Tags of digits only get a leading "_", as Obsidian needs a non-digit.
+// Returns "" when nothing is left.
+func sanitizeTag(tag string) string {
+	var sb strings.Builder
+
+	for _, r := range strings.TrimPrefix(strings.TrimSpace(tag), "#") {
+		switch {
+		case unicode.IsSpace(r):
+			sb.WriteRune('-')
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '/':
+			sb.WriteRune(r)
+		}
+	}
+
+	var levels []string
+
+	for _, level := range strings.Split(sb.String(), "/") {
+		if level = strings.Trim(repeatedDashes.ReplaceAllString(level, "-"), "-"); level != "" {
+			levels = append(levels, level)
+		}
+	}
+
+	sanitized := strings.Join(levels, "/")
+	if sanitized != "" && strings.Trim(sanitized, "0123456789/") == "" {
+		sanitized = "_" + sanitized
+	}
+
+	return sanitized
+}
+
+// noteTags returns the tags written for an item: each tag replaced by its tag_names entry, prefixed with
+// tag_prefix and made valid for Obsidian. Tags that end up empty are dropped and duplicates written once.
+func (o *ObsidianTarget) noteTags(item models.ItemInterface) []string {
+	var tags []string
+
+	for _, tag := range item.GetTags() {
+		if tag = o.noteTag(tag); tag != "" && !containsString(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// noteTag returns how an item's tag is written to its note, or "" when it is dropped.
+func (o *ObsidianTarget) noteTag(tag string) string {
+	if name, mapped := o.tagNames[strings.ToLower(strings.TrimSpace(tag))]; mapped {
+		tag = name
+	}
+
+	if sanitizeTag(tag) == "" {
+		return ""
+	}
+
+	return sanitizeTag(o.tagPrefix + tag)
+}
+
+// trackedNote is what the last sync wrote for a note.
+type trackedNote struct {
+	Path       string   `json:"path"` // Relative to the output directory
+	SourceType string   `json:"source_type"`
+	Tags       []string `json:"tags"`
+}
+
+// loadTagIndex reads the tag index of an output directory.
+func loadTagIndex(outputDir string) (map[string]trackedNote, error) {
+	index := make(map[string]trackedNote)
+
+	data, err := statestore.ReadFile(filepath.Join(outputDir, tagIndexFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return index, nil
+		}
+
+		return nil, fmt.Errorf("failed to read tag index: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse tag index: %w", err)
+	}
+
+	return index, nil
+}
+
+// saveTagIndex records the tags of the notes written by this export, as read back from the notes so any
+// layout or template is compared like for like. Notes synced earlier keep their entry.
+func (o *ObsidianTarget) saveTagIndex(items []models.FullItem, outputDir string) error {
+	index, err := loadTagIndex(outputDir)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+
+	for _, item := range items {
+		if _, _, rolling := o.seriesOf(item); rolling {
+			continue
+		}
+
+		path := o.itemPath(item, outputDir)
+
+		relPath, err := filepath.Rel(outputDir, path)
+		if err != nil {
+			continue
+		}
+
+		tags, err := o.readNoteTags(path)
+		if err != nil {
+			continue
+		}
+
+		index[item.GetID()] = trackedNote{Path: filepath.ToSlash(relPath), SourceType: item.GetSourceType(), Tags: tags}
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode tag index: %w", err)
+	}
+
+	return statestore.WriteFile(filepath.Join(outputDir, tagIndexFile), data, 0644)
+}
+
+// TagChanges returns the synced notes whose tags were added or removed in the vault since they were
+// written, from frontmatter tags, tags:: fields or #tags in the text. Tags compare case-insensitively.
+func (o *ObsidianTarget) TagChanges(outputDir string) ([]interfaces.TagChange, error) {
+	index, err := loadTagIndex(outputDir)
+	if err != nil || len(index) == 0 {
+		return nil, err
+	}
+
+	var changes []interfaces.TagChange
+
+	for id, note := range index {
+		tags, err := o.readNoteTags(filepath.Join(outputDir, filepath.FromSlash(note.Path)))
+		if err != nil {
+			continue // Moved or deleted notes are found again when they are re-exported
+		}
+
+		added, removed := diffTags(note.Tags, tags), diffTags(tags, note.Tags)
+		if len(added) > 0 || len(removed) > 0 {
+			changes = append(changes, interfaces.TagChange{
+				ItemID:     id,
+				SourceType: note.SourceType,
+				Added:      added,
+				Removed:    removed,
+			})
+		}
+	}
+
+	return changes, nil
+}
+
+// diffTags returns the tags in current that are not in previous.
+func diffTags(previous, current []string) []string {
+	seen := make(map[string]bool, len(previous))
+	for _, tag := range previous {
+		seen[strings.ToLower(tag)] = true
+	}
+
+	var diff []string
+
+	for _, tag := range current {
+		if !seen[strings.ToLower(tag)] {
+			diff = append(diff, tag)
+			seen[strings.ToLower(tag)] = true
+		}
+	}
+
+	return diff
+}
+
+// readNoteTags reads every tag of a note: the frontmatter tags property, tags:: inline fields and #tags in
+// the text outside code.
+func (o *ObsidianTarget) readNoteTags(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var tags []string
+
+	add := func(tag string) {
+		tag = strings.TrimPrefix(strings.Trim(strings.TrimSpace(tag), `"'`), "#")
+		if tag != "" && len(diffTags(tags, []string{tag})) > 0 {
+			tags = append(tags, tag)
+		}
+	}
+
+	inFrontmatter, inTagList, inCode := false, false, false
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024) // Email bodies can have very long lines
+
+	for lineNum := 0; scanner.Scan(); lineNum++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+
+		if line == "---" && (lineNum == 0 || inFrontmatter) {
+			inFrontmatter, inTagList = lineNum == 0, false
+
+			continue
+		}
+
+		if inFrontmatter {
+			if inTagList && strings.HasPrefix(strings.TrimSpace(line), "- ") {
+				add(strings.TrimPrefix(strings.TrimSpace(line), "- "))
+
+				continue
+			}
+
+			inTagList = false
+
+			if value, found := strings.CutPrefix(line, o.propertyNames.Name("tags")+":"); found {
+				value = strings.Trim(strings.TrimSpace(value), "[]")
+				if value == "" {
+					inTagList = true
+				}
+
+				for _, tag := range strings.Split(value, ",") {
+					add(tag)
+				}
+			}
+
+			continue
+		}
+
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+
+			continue
+		}
+
+		if inCode {
+			continue
+		}
+
+		for _, match := range inlineTagPattern.FindAllStringSubmatch(stripInlineCode(line), -1) {
+			add(match[1])
+		}
+	}
+
+	return tags, scanner.Err()
+}
+
+// stripInlineCode removes `code` spans from a line.
+func stripInlineCode(line string) string {
+	parts := strings.Split(line, "`")
+
+	var sb strings.Builder
+
+	for i := 0; i < len(parts); i += 2 {
+		sb.WriteString(parts[i])
+		sb.WriteString(" ")
+	}
+
+	return sb.String()
+}
+
+// Ensure ObsidianTarget reads tag changes.
+var _ interfaces.TagChangeReader = (*ObsidianTarget)(nil)
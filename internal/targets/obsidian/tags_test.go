@@ -0,0 +1,102 @@
+package obsidian
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestTagChanges(t *testing.T) {
+	outputDir := t.TempDir()
+
+	item := models.NewBasicItem("m1", "Quarterly report")
+	item.SetSourceType("gmail")
+	item.SetItemType("email")
+	item.SetTags([]string{"gmail", "inbox"})
+	item.SetContent("Numbers attached, see #finance and `#not-a-tag`.\n\n```\n#include <stdio.h>\n```")
+
+	target := NewObsidianTarget()
+	if err := target.Export([]models.FullItem{item}, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	if changes, err := target.TagChanges(outputDir); err != nil || len(changes) != 0 {
+		t.Fatalf("TagChanges() after export = %+v, %v, want none", changes, err)
+	}
+
+	notePath := filepath.Join(outputDir, "Quarterly-report.md")
+
+	note, err := os.ReadFile(notePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(note), "  - inbox\n") {
+		t.Fatalf("note has no inbox tag:\n%s", note)
+	}
+
+	edited := strings.Replace(string(note), "  - inbox\n", "", 1) + "\nDone here #Archive\n"
+	if err := os.WriteFile(notePath, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := target.TagChanges(outputDir)
+	if err != nil {
+		t.Fatalf("TagChanges() error = %v", err)
+	}
+
+	if len(changes) != 1 {
+		t.Fatalf("TagChanges() = %+v, want one change", changes)
+	}
+
+	change := changes[0]
+	if change.ItemID != "m1" || change.SourceType != "gmail" ||
+		!reflect.DeepEqual(change.Added, []string{"Archive"}) || !reflect.DeepEqual(change.Removed, []string{"inbox"}) {
+		t.Errorf("TagChanges() = %+v", change)
+	}
+}
+
+func TestSanitizeTag(t *testing.T) {
+	tests := map[string]string{
+		"work":               "work",
+		"#inbox":             "inbox",
+		"Project Alpha":      "Project-Alpha",
+		"🔥 Urgent":           "Urgent",
+		"Q3 🚀 launch":        "Q3-launch",
+		"⭐":                  "",
+		"Work/Projects":      "Work/Projects",
+		"/Work//Projects/":   "Work/Projects",
+		"[Gmail]/Sent Mail":  "Gmail/Sent-Mail",
+		"2025":               "_2025",
+		"team: design & ux!": "team-design-ux",
+		"café":               "café",
+	}
+
+	for tag, want := range tests {
+		if got := sanitizeTag(tag); got != want {
+			t.Errorf("sanitizeTag(%q) = %q, want %q", tag, got, want)
+		}
+	}
+}
+
+func TestNoteTagsApplyNamesAndPrefix(t *testing.T) {
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{
+		"tag_prefix": "gmail/",
+		"tag_names":  map[string]interface{}{"⭐": "starred", "CATEGORY_PROMOTIONS": "", "IMPORTANT": "important"},
+	}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	item := models.NewBasicItem("msg-1", "Offer")
+	item.SetTags([]string{"⭐", "category_promotions", "Important", "important", "Client X", "🎉"})
+
+	want := []string{"gmail/starred", "gmail/important", "gmail/Client-X"}
+	if got := target.noteTags(item); !reflect.DeepEqual(got, want) {
+		t.Errorf("noteTags() = %v, want %v", got, want)
+	}
+}
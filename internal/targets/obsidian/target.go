@@ -2,25 +2,153 @@ package obsidian
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
+	"pkm-sync/internal/convert"
 	"pkm-sync/internal/utils"
 	"pkm-sync/pkg/interfaces"
 	"pkm-sync/pkg/models"
+	"pkm-sync/pkg/query"
 )
 
 type ObsidianTarget struct {
 	vaultPath        string
 	templateDir      string
 	dailyNotesFormat string
+
+	// Daily notes integration
+	createDailyNotes   bool
+	dailyNotesFolder   string
+	dailyNotesTemplate string
+	dailyNotesEmbed    bool
+
+	// Custom note template (template_file)
+	template *template.Template
+
+	// Metadata keys promoted to frontmatter properties (custom_fields)
+	customFields []fieldMapping
+
+	// Names of the standard id, source, type, created, message_count and tags properties (property_names)
+	propertyNames utils.PropertyNames
+
+	// Tag rewriting: tag_names maps source tags (lower-case) to tags, tag_prefix is added to every tag
+	tagNames  map[string]string
+	tagPrefix string
+
+	// Notes written out of the items of an export, see SetProgress
+	progress interfaces.ProgressFunc
+
+	// Decides about items that fail to export, see SetItemErrorHandler
+	itemErrors interfaces.ItemErrorFunc
+
+	// Where metadata is written: "frontmatter", "dataview" (inline fields) or "both"
+	metadataFormat string
+
+	// Canvas generation for threads and tagged items
+	canvasThreads bool
+	canvasTags    []string
+	canvasFolder  string
+
+	// Kanban board for task-like items (kanban_board is the board note name)
+	kanbanBoard       string
+	kanbanItemTypes   []string
+	kanbanStatusField string
+	kanbanLanes       []string
+
+	// Triage queue of important items in a note (important_note) and Obsidian bookmarks
+	importantNote          string
+	importantBookmarks     bool
+	importantWhen          *query.Query
+	importantPriorityField string
+	importantMinPriority   float64
+
+	// Link style for vault links: "wikilink" (default) or "markdown"
+	linkFormat string
+
+	// Attachment downloads, stored once per content hash in attachmentFolder
+	downloadAttachments bool
+	attachmentFolder    string
+	attachments         *attachmentStore
+
+	// Remote images downloaded into the attachment folder (image URL -> stored path, "" when it failed)
+	downloadImages bool
+	imageClient    *http.Client
+	images         map[string]string
+
+	// Markdown/text companions written next to stored Office and PDF attachments
+	converter *convert.Converter
+
+	// Rules attachments and images must pass before they are written; nil allows everything
+	attachmentPolicy *attachmentPolicy
+
+	// Name of a note listing every stored attachment (attachment_manifest); disabled when empty
+	attachmentManifest string
+
+	// Map-of-content index notes (index_notes groupings: source, month, tag)
+	indexNotes  []string
+	indexFolder string
+
+	// Filename policy and the note paths allocated for the current export run (item ID -> path)
+	filenamePolicy utils.FilenamePolicy
+	itemPolicies   map[string]utils.FilenamePolicy // Item ID -> policy of its source's filenames settings
+	notePaths      map[string]string
+
+	// Rolling notes: recurring events and reports appended as dated sections to one note per series
+	rollingNotes      bool
+	rollingNoteTitles []string
+
+	// Person notes with a meeting history per organizer/attendee (person_names maps email -> note name)
+	personNotes  bool
+	peopleFolder string
+	personNames  map[string]string
+
+	// Company notes per contact domain (company_names maps domain -> note name)
+	companyNotes          bool
+	companiesFolder       string
+	companyNames          map[string]string
+	companyExcludeDomains []string
+
+	// Event ID -> note and start time recorded by earlier syncs
+	events map[string]trackedEvent
+
+	// Changelog sections listing what changed in items edited at the source (changelog_ignore leaves
+	// metadata keys out of the comparison); item ID -> fingerprint and changes recorded by earlier syncs
+	changelog       bool
+	changelogIgnore []string
+	changes         map[string]trackedChanges
+
+	// Cross-source links between notes sharing event, message or Drive file IDs
+	crossLinks bool
+	linkIndex  map[string]linkedNote
+	related    map[string][]string
+
+	// Folder routing for notes inside the output directory (item_folders, sync.subdir_format)
+	layout utils.OutputLayout
+
+	// Dates are rendered in location (nil keeps each item's zone); dateTimeFormat overrides the default layouts
+	location       *time.Location
+	dateTimeFormat string
 }
 
 func NewObsidianTarget() *ObsidianTarget {
 	return &ObsidianTarget{
 		dailyNotesFormat: "2006-01-02", // Default: YYYY-MM-DD
+		dailyNotesFolder: defaultDailyNotesFolder,
+		metadataFormat:   metadataFormatFrontmatter,
+		canvasFolder:     defaultCanvasFolder,
+		linkFormat:       linkFormatWikilink,
+		attachmentFolder: defaultAttachmentFolder,
+		filenamePolicy:   utils.DefaultFilenamePolicy(),
+		indexFolder:      defaultIndexFolder,
+		peopleFolder:     defaultPeopleFolder,
+		companiesFolder:  defaultCompaniesFolder,
 	}
 }
 
@@ -37,17 +165,429 @@ func (o *ObsidianTarget) Configure(config map[string]interface{}) error {
 		o.templateDir = templateDir
 	}
 
-	if format, ok := config["daily_notes_format"].(string); ok {
+	policy, err := utils.ParseFilenamePolicy(config, o.filenamePolicy)
+	if err != nil {
+		return err
+	}
+
+	o.filenamePolicy = policy
+
+	layout, err := utils.ParseOutputLayout(config, o.layout)
+	if err != nil {
+		return err
+	}
+
+	o.layout = layout
+
+	location, err := utils.ParseTimezone(config)
+	if err != nil {
+		return err
+	}
+
+	o.location = location
+
+	if format, ok := config["datetime_format"].(string); ok && format != "" {
+		o.dateTimeFormat = format
+	}
+
+	if format, ok := config["daily_notes_format"].(string); ok && format != "" {
 		o.dailyNotesFormat = format
 	}
 
-	return nil
+	if createDailyNotes, ok := config["create_daily_notes"].(bool); ok {
+		o.createDailyNotes = createDailyNotes
+	}
+
+	if folder, ok := config["daily_notes_folder"].(string); ok && folder != "" {
+		o.dailyNotesFolder = folder
+	}
+
+	if template, ok := config["daily_notes_template"].(string); ok {
+		o.dailyNotesTemplate = template
+	}
+
+	if embed, ok := config["daily_notes_embed"].(bool); ok {
+		o.dailyNotesEmbed = embed
+	}
+
+	if templateFile, ok := config["template_file"].(string); ok && templateFile != "" {
+		if err := o.loadTemplate(templateFile); err != nil {
+			return err
+		}
+	}
+
+	if customFields, exists := config["custom_fields"]; exists && customFields != nil {
+		mappings, err := parseFieldMappings(customFields)
+		if err != nil {
+			return err
+		}
+
+		o.customFields = mappings
+	}
+
+	if value, exists := config["property_names"]; exists && value != nil {
+		names, err := utils.ParsePropertyNames(value)
+		if err != nil {
+			return err
+		}
+
+		o.propertyNames = names
+	}
+
+	if value, exists := config["tag_names"]; exists && value != nil {
+		names, err := parseTagNames(value)
+		if err != nil {
+			return err
+		}
+
+		o.tagNames = names
+	}
+
+	if prefix, ok := config["tag_prefix"].(string); ok {
+		o.tagPrefix = prefix
+	}
+
+	if metadataFormat, ok := config["metadata_format"].(string); ok && metadataFormat != "" {
+		switch metadataFormat {
+		case metadataFormatFrontmatter, metadataFormatDataview, metadataFormatBoth:
+			o.metadataFormat = metadataFormat
+		default:
+			return fmt.Errorf("unsupported metadata_format '%s': supported formats are 'frontmatter', 'dataview', 'both'",
+				metadataFormat)
+		}
+	}
+
+	if linkFormat, ok := config["link_format"].(string); ok && linkFormat != "" {
+		switch linkFormat {
+		case linkFormatWikilink, linkFormatMarkdown:
+			o.linkFormat = linkFormat
+		default:
+			return fmt.Errorf("unsupported link_format '%s': supported formats are 'wikilink', 'markdown'", linkFormat)
+		}
+	}
+
+	if download, ok := config["download_attachments"].(bool); ok {
+		o.downloadAttachments = download
+	}
+
+	if download, ok := config["download_images"].(bool); ok {
+		o.downloadImages = download
+	}
+
+	if enabled, ok := config["convert_attachments"].(bool); ok {
+		o.converter = nil
+
+		if enabled {
+			mode, _ := config["attachment_converter"].(string)
+
+			converter, err := convert.New(mode)
+			if err != nil {
+				return err
+			}
+
+			o.converter = converter
+		}
+	}
+
+	if policyConfig, exists := config["attachment_policy"]; exists && policyConfig != nil {
+		policy, err := parseAttachmentPolicy(policyConfig)
+		if err != nil {
+			return err
+		}
+
+		o.attachmentPolicy = policy
+	}
+
+	if folder, ok := config["attachment_folder"].(string); ok && folder != "" {
+		o.attachmentFolder = folder
+	}
+
+	if manifest, ok := config["attachment_manifest"].(string); ok {
+		o.attachmentManifest = manifest
+	}
+
+	if o.attachmentManifest != "" && !o.downloadAttachments {
+		return fmt.Errorf("attachment_manifest needs download_attachments")
+	}
+
+	o.attachmentFolder = o.layout.Folder(utils.AttachmentsFolderKey, o.attachmentFolder)
+
+	if canvasThreads, ok := config["canvas_threads"].(bool); ok {
+		o.canvasThreads = canvasThreads
+	}
+
+	if canvasTags, exists := config["canvas_tags"]; exists && canvasTags != nil {
+		tags, err := configStringList("canvas_tags", canvasTags)
+		if err != nil {
+			return err
+		}
+
+		o.canvasTags = tags
+	}
+
+	if folder, ok := config["canvas_folder"].(string); ok && folder != "" {
+		o.canvasFolder = folder
+	}
+
+	if indexNotes, exists := config["index_notes"]; exists && indexNotes != nil {
+		groupings, err := parseIndexGroupings(indexNotes)
+		if err != nil {
+			return err
+		}
+
+		o.indexNotes = groupings
+	}
+
+	if folder, ok := config["index_folder"].(string); ok && folder != "" {
+		o.indexFolder = folder
+	}
+
+	if crossLinks, ok := config["cross_links"].(bool); ok {
+		o.crossLinks = crossLinks
+	}
+
+	if changelog, ok := config["changelog"].(bool); ok {
+		o.changelog = changelog
+	}
+
+	if value, exists := config["changelog_ignore"]; exists && value != nil {
+		keys, err := configStringList("changelog_ignore", value)
+		if err != nil {
+			return err
+		}
+
+		o.changelogIgnore = keys
+	}
+
+	if personNotes, ok := config["person_notes"].(bool); ok {
+		o.personNotes = personNotes
+	}
+
+	if folder, ok := config["people_folder"].(string); ok && folder != "" {
+		o.peopleFolder = folder
+	}
+
+	if value, exists := config["person_names"]; exists && value != nil {
+		names, err := parsePersonNames(value)
+		if err != nil {
+			return err
+		}
+
+		o.personNames = names
+	}
+
+	if companyNotes, ok := config["company_notes"].(bool); ok {
+		o.companyNotes = companyNotes
+	}
+
+	if folder, ok := config["companies_folder"].(string); ok && folder != "" {
+		o.companiesFolder = folder
+	}
+
+	if value, exists := config["company_names"]; exists && value != nil {
+		names, err := parseCompanyNames(value)
+		if err != nil {
+			return err
+		}
+
+		o.companyNames = names
+	}
+
+	if rollingNotes, ok := config["rolling_notes"].(bool); ok {
+		o.rollingNotes = rollingNotes
+	}
+
+	if value, exists := config["rolling_note_titles"]; exists && value != nil {
+		titles, err := configStringList("rolling_note_titles", value)
+		if err != nil {
+			return err
+		}
+
+		o.rollingNoteTitles = titles
+	}
+
+	if board, ok := config["kanban_board"].(string); ok {
+		o.kanbanBoard = board
+	}
+
+	if statusField, ok := config["kanban_status_field"].(string); ok {
+		o.kanbanStatusField = statusField
+	}
+
+	for key, dest := range map[string]*[]string{
+		"kanban_item_types":       &o.kanbanItemTypes,
+		"kanban_lanes":            &o.kanbanLanes,
+		"company_exclude_domains": &o.companyExcludeDomains,
+	} {
+		if value, exists := config[key]; exists && value != nil {
+			values, err := configStringList(key, value)
+			if err != nil {
+				return err
+			}
+
+			*dest = values
+		}
+	}
+
+	itemTypes := make([]string, 0, len(o.kanbanItemTypes))
+	for _, itemType := range o.kanbanItemTypes {
+		itemTypes = append(itemTypes, strings.ToLower(itemType))
+	}
+
+	o.kanbanItemTypes = itemTypes
+
+	return o.configureImportant(config)
+}
+
+// configStringList reads a list of strings from a config value decoded from YAML or built in Go.
+func configStringList(key string, value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case []string:
+		return v, nil
+	case []interface{}:
+		values := make([]string, 0, len(v))
+
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s[%d] must be a string, got %T", key, i, item)
+			}
+
+			values = append(values, s)
+		}
+
+		return values, nil
+	default:
+		return nil, fmt.Errorf("%s must be a list of strings, got %T", key, value)
+	}
 }
 
 func (o *ObsidianTarget) Export(items []models.FullItem, outputDir string) error {
-	for _, item := range items {
+	utils.LocalizeItems(items, o.location)
+
+	if err := o.prepareEvents(items, outputDir); err != nil {
+		return err
+	}
+
+	if o.changelog {
+		if err := o.prepareChangelog(items, outputDir); err != nil {
+			return err
+		}
+	}
+
+	o.allocateNotePaths(items, outputDir)
+
+	if o.crossLinks {
+		if err := o.prepareCrossLinks(items, outputDir); err != nil {
+			return err
+		}
+	}
+
+	if o.downloadAttachments || o.downloadImages {
+		if err := o.prepareAttachments(items, outputDir, false); err != nil {
+			return fmt.Errorf("failed to store attachments: %w", err)
+		}
+
+		defer func() { o.attachments, o.images = nil, nil }()
+	}
+
+	for i, item := range items {
+		if o.progress != nil {
+			o.progress(i, len(items))
+		}
+
+		if _, _, rolling := o.seriesOf(item); rolling {
+			continue
+		}
+
 		if err := o.exportItem(item, outputDir); err != nil {
-			return fmt.Errorf("failed to export item %s: %w", item.GetID(), err)
+			if err := o.itemFailed(item, fmt.Errorf("failed to export item %s: %w", item.GetID(), err)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if o.progress != nil {
+		o.progress(len(items), len(items))
+	}
+
+	if o.rollingNotesEnabled() {
+		if err := o.updateRollingNotes(items, outputDir); err != nil {
+			return fmt.Errorf("failed to update rolling notes: %w", err)
+		}
+	}
+
+	o.trackEvents(items, outputDir)
+
+	if err := o.saveEventIndex(outputDir); err != nil {
+		return fmt.Errorf("failed to save event index: %w", err)
+	}
+
+	if o.changelog {
+		if err := o.saveChangelogIndex(outputDir); err != nil {
+			return fmt.Errorf("failed to save changelog index: %w", err)
+		}
+	}
+
+	if err := o.saveTaskIndex(items, outputDir); err != nil {
+		return fmt.Errorf("failed to save task index: %w", err)
+	}
+
+	if err := o.saveTagIndex(items, outputDir); err != nil {
+		return fmt.Errorf("failed to save tag index: %w", err)
+	}
+
+	if o.crossLinks {
+		if err := o.updateCrossLinkedNotes(items, outputDir); err != nil {
+			return fmt.Errorf("failed to update related links: %w", err)
+		}
+	}
+
+	if o.createDailyNotes {
+		if err := o.updateDailyNotes(items, outputDir); err != nil {
+			return fmt.Errorf("failed to update daily notes: %w", err)
+		}
+	}
+
+	if o.canvasEnabled() {
+		if err := o.writeCanvases(items, outputDir); err != nil {
+			return fmt.Errorf("failed to write canvases: %w", err)
+		}
+	}
+
+	if o.kanbanBoard != "" {
+		if err := o.updateKanbanBoard(items, outputDir); err != nil {
+			return fmt.Errorf("failed to update kanban board: %w", err)
+		}
+	}
+
+	if o.importantEnabled() {
+		if err := o.updateImportant(items, outputDir); err != nil {
+			return fmt.Errorf("failed to queue important items: %w", err)
+		}
+	}
+
+	if len(o.indexNotes) > 0 {
+		if err := o.updateIndexNotes(items, outputDir); err != nil {
+			return fmt.Errorf("failed to update index notes: %w", err)
+		}
+	}
+
+	if o.personNotes {
+		if err := o.updatePersonNotes(items, outputDir); err != nil {
+			return fmt.Errorf("failed to update person notes: %w", err)
+		}
+	}
+
+	if o.companyNotes {
+		if err := o.updateCompanyNotes(items, outputDir); err != nil {
+			return fmt.Errorf("failed to update company notes: %w", err)
+		}
+	}
+
+	if o.attachmentManifest != "" {
+		if err := o.updateAttachmentManifest(items, outputDir); err != nil {
+			return fmt.Errorf("failed to update attachment manifest: %w", err)
 		}
 	}
 
@@ -55,56 +595,91 @@ func (o *ObsidianTarget) Export(items []models.FullItem, outputDir string) error
 }
 
 func (o *ObsidianTarget) exportItem(item models.FullItem, outputDir string) error {
-	filename := o.FormatFilename(item.GetTitle())
-	filePath := filepath.Join(outputDir, filename)
+	filePath := o.itemPath(item, outputDir)
 
 	// Create directory if needed
 	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
 		return err
 	}
 
-	content := o.formatContent(item)
+	content, err := o.formatContent(item)
+	if err != nil {
+		return err
+	}
 
 	return os.WriteFile(filePath, []byte(content), 0644)
 }
 
-func (o *ObsidianTarget) formatContent(item models.ItemInterface) string {
+func (o *ObsidianTarget) formatContent(item models.ItemInterface) (string, error) {
+	// A user-provided template replaces the built-in layout entirely
+	if o.template != nil {
+		content, err := o.renderTemplate(item)
+		if err != nil {
+			return "", err
+		}
+
+		return o.addChangelog(item, o.addRelatedLinks(item, content)), nil
+	}
+
 	// Handle different item types
 	if models.IsThread(item) {
-		return o.formatThreadContent(item)
+		return o.addChangelog(item, o.addRelatedLinks(item, o.formatThreadContent(item))), nil
 	}
 
 	// Default: format as basic item
-	return o.formatBasicItemContent(item)
+	return o.addChangelog(item, o.addRelatedLinks(item, o.formatBasicItemContent(item))), nil
 }
 
-func (o *ObsidianTarget) formatBasicItemContent(item models.ItemInterface) string {
+// formatFrontmatter builds the YAML frontmatter block for an item.
+func (o *ObsidianTarget) formatFrontmatter(item models.ItemInterface) string {
 	var sb strings.Builder
 
-	// YAML frontmatter
 	sb.WriteString("---\n")
 	sb.WriteString(o.FormatMetadata(item.GetMetadata()))
-	sb.WriteString(fmt.Sprintf("id: %s\n", item.GetID()))
-	sb.WriteString(fmt.Sprintf("source: %s\n", item.GetSourceType()))
-	sb.WriteString(fmt.Sprintf("type: %s\n", item.GetItemType()))
-	sb.WriteString(fmt.Sprintf("created: %s\n", item.GetCreatedAt().Format(time.RFC3339)))
+	sb.WriteString(o.propertyNames.Name("id") + ": " + utils.YAMLString(item.GetID()) + "\n")
+	sb.WriteString(o.propertyNames.Name("source") + ": " + utils.YAMLString(item.GetSourceType()) + "\n")
+	sb.WriteString(o.propertyNames.Name("type") + ": " + utils.YAMLString(item.GetItemType()) + "\n")
+	sb.WriteString(fmt.Sprintf("%s: %s\n", o.propertyNames.Name("created"),
+		o.formatDateTime(item.GetCreatedAt(), time.RFC3339)))
+
+	if thread, ok := models.AsThread(item); ok {
+		sb.WriteString(fmt.Sprintf("%s: %d\n", o.propertyNames.Name("message_count"), len(thread.GetMessages())))
+	}
 
-	if len(item.GetTags()) > 0 {
-		sb.WriteString("tags:\n")
+	if tags := o.noteTags(item); len(tags) > 0 {
+		sb.WriteString(o.propertyNames.Name("tags") + ":\n")
 
-		for _, tag := range item.GetTags() {
-			sb.WriteString(fmt.Sprintf("  - %s\n", tag))
+		for _, tag := range tags {
+			sb.WriteString("  - " + utils.YAMLString(tag) + "\n")
 		}
 	}
 
-	sb.WriteString("---\n\n")
+	sb.WriteString("---\n")
+
+	return sb.String()
+}
+
+func (o *ObsidianTarget) formatBasicItemContent(item models.ItemInterface) string {
+	var sb strings.Builder
+
+	// YAML frontmatter
+	if o.writesFrontmatter() {
+		sb.WriteString(o.formatFrontmatter(item))
+		sb.WriteString("\n")
+	}
 
 	// Title
-	sb.WriteString(fmt.Sprintf("# %s\n\n", item.GetTitle()))
+	sb.WriteString(fmt.Sprintf("# %s\n\n", utils.EscapeMarkdown(item.GetTitle())))
+
+	// Dataview inline fields
+	if o.writesInlineFields() {
+		sb.WriteString(o.formatInlineFields(item))
+		sb.WriteString("\n")
+	}
 
 	// Content
-	if item.GetContent() != "" {
-		sb.WriteString(item.GetContent())
+	if content := o.itemContent(item); content != "" {
+		sb.WriteString(content)
 		sb.WriteString("\n\n")
 	}
 
@@ -113,11 +688,7 @@ func (o *ObsidianTarget) formatBasicItemContent(item models.ItemInterface) strin
 		sb.WriteString("## Attachments\n\n")
 
 		for _, attachment := range item.GetAttachments() {
-			if attachment.URL != "" {
-				sb.WriteString(fmt.Sprintf("- [%s](%s)\n", attachment.Name, attachment.URL))
-			} else {
-				sb.WriteString(fmt.Sprintf("- %s\n", attachment.Name))
-			}
+			sb.WriteString("- " + o.formatAttachmentLink(attachment) + "\n")
 		}
 
 		sb.WriteString("\n")
@@ -147,31 +718,24 @@ func (o *ObsidianTarget) formatThreadContent(item models.ItemInterface) string {
 	var sb strings.Builder
 
 	// YAML frontmatter for thread
-	sb.WriteString("---\n")
-	sb.WriteString(o.FormatMetadata(thread.GetMetadata()))
-	sb.WriteString(fmt.Sprintf("id: %s\n", thread.GetID()))
-	sb.WriteString(fmt.Sprintf("source: %s\n", thread.GetSourceType()))
-	sb.WriteString(fmt.Sprintf("type: %s\n", thread.GetItemType()))
-	sb.WriteString(fmt.Sprintf("created: %s\n", thread.GetCreatedAt().Format(time.RFC3339)))
-	sb.WriteString(fmt.Sprintf("message_count: %d\n", len(thread.GetMessages())))
-
-	if len(thread.GetTags()) > 0 {
-		sb.WriteString("tags:\n")
-
-		for _, tag := range thread.GetTags() {
-			sb.WriteString(fmt.Sprintf("  - %s\n", tag))
-		}
+	if o.writesFrontmatter() {
+		sb.WriteString(o.formatFrontmatter(thread))
+		sb.WriteString("\n")
 	}
 
-	sb.WriteString("---\n\n")
-
 	// Thread title
-	sb.WriteString(fmt.Sprintf("# %s\n\n", thread.GetTitle()))
+	sb.WriteString(fmt.Sprintf("# %s\n\n", utils.EscapeMarkdown(thread.GetTitle())))
+
+	// Dataview inline fields
+	if o.writesInlineFields() {
+		sb.WriteString(o.formatInlineFields(thread))
+		sb.WriteString("\n")
+	}
 
 	// Thread summary/content
-	if thread.GetContent() != "" {
+	if content := o.itemContent(thread); content != "" {
 		sb.WriteString("## Thread Summary\n\n")
-		sb.WriteString(thread.GetContent())
+		sb.WriteString(content)
 		sb.WriteString("\n\n")
 	}
 
@@ -189,21 +753,21 @@ func (o *ObsidianTarget) formatThreadContent(item models.ItemInterface) string {
 
 // formatThreadMessage formats a single message within a thread to reduce complexity.
 func (o *ObsidianTarget) formatThreadMessage(sb *strings.Builder, messageNum int, message models.ItemInterface) {
-	sb.WriteString(fmt.Sprintf("### Message %d: %s\n\n", messageNum, message.GetTitle()))
+	sb.WriteString(fmt.Sprintf("### Message %d: %s\n\n", messageNum, utils.EscapeMarkdown(message.GetTitle())))
 
 	// Message metadata
 	sb.WriteString(fmt.Sprintf("**From:** %s  \n", message.GetSourceType()))
 	sb.WriteString(fmt.Sprintf("**Created:** %s  \n", message.GetCreatedAt().Format(time.RFC3339)))
 
-	if len(message.GetTags()) > 0 {
-		sb.WriteString(fmt.Sprintf("**Tags:** %s  \n", strings.Join(message.GetTags(), ", ")))
+	if tags := o.noteTags(message); len(tags) > 0 {
+		sb.WriteString(fmt.Sprintf("**Tags:** %s  \n", strings.Join(tags, ", ")))
 	}
 
 	sb.WriteString("\n")
 
 	// Message content
-	if message.GetContent() != "" {
-		sb.WriteString(message.GetContent())
+	if content := o.itemContent(message); content != "" {
+		sb.WriteString(content)
 		sb.WriteString("\n\n")
 	}
 
@@ -212,11 +776,7 @@ func (o *ObsidianTarget) formatThreadMessage(sb *strings.Builder, messageNum int
 		sb.WriteString("**Attachments:**\n")
 
 		for _, attachment := range message.GetAttachments() {
-			if attachment.URL != "" {
-				sb.WriteString(fmt.Sprintf("- [%s](%s)\n", attachment.Name, attachment.URL))
-			} else {
-				sb.WriteString(fmt.Sprintf("- %s\n", attachment.Name))
-			}
+			sb.WriteString("- " + o.formatAttachmentLink(attachment) + "\n")
 		}
 
 		sb.WriteString("\n")
@@ -225,8 +785,28 @@ func (o *ObsidianTarget) formatThreadMessage(sb *strings.Builder, messageNum int
 	sb.WriteString("---\n\n")
 }
 
+// SetProgress reports the item notes written during Export.
+func (o *ObsidianTarget) SetProgress(report interfaces.ProgressFunc) {
+	o.progress = report
+}
+
+// SetItemErrorHandler lets Export carry on past item notes that fail to be written when handle allows it.
+func (o *ObsidianTarget) SetItemErrorHandler(handle interfaces.ItemErrorFunc) {
+	o.itemErrors = handle
+}
+
+// itemFailed returns the error an item failed to export with, unless the item error handler lets the
+// export carry on.
+func (o *ObsidianTarget) itemFailed(item models.FullItem, err error) error {
+	if o.itemErrors == nil {
+		return err
+	}
+
+	return o.itemErrors(item, err)
+}
+
 func (o *ObsidianTarget) FormatFilename(title string) string {
-	return utils.SanitizeFilename(title) + o.GetFileExtension()
+	return o.filenamePolicy.Sanitize(title) + o.GetFileExtension()
 }
 
 func (o *ObsidianTarget) GetFileExtension() string {
@@ -238,76 +818,130 @@ func (o *ObsidianTarget) FormatMetadata(metadata map[string]interface{}) string
 		return ""
 	}
 
+	// Only the declared custom fields are emitted when a mapping is configured
+	if len(o.customFields) > 0 {
+		return o.formatMappedMetadata(metadata)
+	}
+
 	var sb strings.Builder
 
-	for key, value := range metadata {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		// Only routes the note, see utils.OutputLayout.ItemFolder
+		if key != utils.FolderMetadataKey {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := metadata[key]
+
 		if key == "attendees" {
-			sb.WriteString(o.formatAttendees(value))
+			sb.WriteString(o.formatAttendeesAs("attendees", value))
+		} else if organizer, ok := attendeesOf(value); ok && key == "organizer" && len(organizer) == 1 {
+			sb.WriteString("organizer: " + strconv.Quote(o.formatPersonLink(organizer[0])) + "\n")
+		} else if contact, ok := value.(models.Contact); ok {
+			sb.WriteString(utils.YAMLString(key) + ": " + strconv.Quote(o.formatPersonLink(contact.Attendee())) + "\n")
+		} else if t, ok := value.(time.Time); ok && o.dateTimeFormat != "" {
+			sb.WriteString(utils.YAMLString(key) + ": " + t.Format(o.dateTimeFormat) + "\n")
+		} else if counts, ok := value.(map[string]int); ok && len(counts) == 0 {
+			continue
 		} else {
-			sb.WriteString(fmt.Sprintf("%s: %v\n", key, value))
+			sb.WriteString(utils.YAMLProperty(key, value))
 		}
 	}
 
 	return sb.String()
 }
 
-// formatAttendees formats attendees as wikilink arrays for Obsidian.
-func (o *ObsidianTarget) formatAttendees(attendeesValue interface{}) string {
+// formatDateTime renders a timestamp with the configured datetime_format, or layout by default.
+func (o *ObsidianTarget) formatDateTime(t time.Time, layout string) string {
+	if o.dateTimeFormat != "" {
+		return t.Format(o.dateTimeFormat)
+	}
+
+	return t.Format(layout)
+}
+
+// formatAttendeesAs formats attendees as an array of person links for Obsidian.
+func (o *ObsidianTarget) formatAttendeesAs(property string, attendeesValue interface{}) string {
+	attendees, ok := attendeesOf(attendeesValue)
+	if !ok {
+		// Fallback for other types
+		return utils.YAMLProperty(property, attendeesValue)
+	}
+
+	if len(attendees) == 0 {
+		return ""
+	}
+
 	var sb strings.Builder
 
-	// Handle different types that attendees might be stored as
-	switch attendees := attendeesValue.(type) {
-	case []models.Attendee:
-		if len(attendees) == 0 {
-			return ""
+	sb.WriteString(property + ":\n")
+
+	for _, attendee := range attendees {
+		sb.WriteString("  - " + strconv.Quote(o.formatPersonLink(attendee)) + "\n")
+	}
+
+	return sb.String()
+}
+
+// Plan works out which files Export would create or modify, without actually writing them.
+func (o *ObsidianTarget) Plan(items []models.FullItem, outputDir string) (*interfaces.Plan, error) {
+	previews := make([]*interfaces.FilePreview, 0, len(items))
+
+	utils.LocalizeItems(items, o.location)
+
+	if err := o.prepareEvents(items, outputDir); err != nil {
+		return nil, err
+	}
+
+	if o.changelog {
+		if err := o.prepareChangelog(items, outputDir); err != nil {
+			return nil, err
 		}
+	}
 
-		sb.WriteString("attendees:\n")
+	o.allocateNotePaths(items, outputDir)
 
-		for _, attendee := range attendees {
-			displayName := attendee.GetDisplayName()
-			sb.WriteString(fmt.Sprintf("  - \"[[%s]]\"\n", displayName))
+	if o.crossLinks {
+		if err := o.prepareCrossLinks(items, outputDir); err != nil {
+			return nil, err
 		}
-	case []interface{}:
-		// Handle case where attendees might be stored as generic interface slice
-		if len(attendees) == 0 {
-			return ""
+	}
+
+	if o.downloadAttachments || o.downloadImages {
+		if err := o.prepareAttachments(items, outputDir, true); err != nil {
+			return nil, fmt.Errorf("failed to plan attachments: %w", err)
 		}
 
-		sb.WriteString("attendees:\n")
+		defer func() { o.attachments, o.images = nil, nil }()
 
-		for _, attendee := range attendees {
-			if attendeeMap, ok := attendee.(map[string]interface{}); ok {
-				var displayName string
-				if name, exists := attendeeMap["DisplayName"].(string); exists && name != "" {
-					displayName = name
-				} else if email, exists := attendeeMap["Email"].(string); exists {
-					displayName = email
-				} else {
-					displayName = fmt.Sprintf("%v", attendee)
-				}
+		previews = append(previews, o.previewAttachments(outputDir)...)
+	}
 
-				sb.WriteString(fmt.Sprintf("  - \"[[%s]]\"\n", displayName))
-			} else {
-				sb.WriteString(fmt.Sprintf("  - \"[[%v]]\"\n", attendee))
-			}
+	if o.rollingNotesEnabled() {
+		rollingPreviews, err := o.previewRollingNotes(items, outputDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to preview rolling notes: %w", err)
 		}
-	default:
-		// Fallback for other types
-		sb.WriteString(fmt.Sprintf("attendees: %v\n", attendeesValue))
+
+		previews = append(previews, rollingPreviews...)
 	}
 
-	return sb.String()
-}
+	for _, item := range items {
+		if _, _, rolling := o.seriesOf(item); rolling {
+			continue
+		}
 
-// Preview generates a preview of what files would be created/modified without actually writing them.
-func (o *ObsidianTarget) Preview(items []models.FullItem, outputDir string) ([]*interfaces.FilePreview, error) {
-	previews := make([]*interfaces.FilePreview, 0, len(items))
+		filePath := o.itemPath(item, outputDir)
 
-	for _, item := range items {
-		filename := o.FormatFilename(item.GetTitle())
-		filePath := filepath.Join(outputDir, filename)
-		content := o.formatContent(item)
+		content, err := o.formatContent(item)
+		if err != nil {
+			return nil, err
+		}
 
 		var existingContent string
 
@@ -334,12 +968,93 @@ func (o *ObsidianTarget) Preview(items []models.FullItem, outputDir string) ([]*
 			Content:         content,
 			ExistingContent: existingContent,
 			Conflict:        conflict,
+			ItemID:          item.GetID(),
 		}
 
 		previews = append(previews, preview)
 	}
 
-	return previews, nil
+	if o.crossLinks {
+		previews = append(previews, o.previewCrossLinkedNotes(items, outputDir)...)
+	}
+
+	if o.createDailyNotes {
+		dailyPreviews, err := o.previewDailyNotes(items, outputDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to preview daily notes: %w", err)
+		}
+
+		previews = append(previews, dailyPreviews...)
+	}
+
+	if o.canvasEnabled() {
+		canvasPreviews, err := o.previewCanvases(items, outputDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to preview canvases: %w", err)
+		}
+
+		previews = append(previews, canvasPreviews...)
+	}
+
+	if o.kanbanBoard != "" {
+		boardPreview, err := o.previewKanbanBoard(items, outputDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to preview kanban board: %w", err)
+		}
+
+		if boardPreview != nil {
+			previews = append(previews, boardPreview)
+		}
+	}
+
+	importantPreview, err := o.previewImportantNote(items, outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to preview important items: %w", err)
+	}
+
+	if importantPreview != nil {
+		previews = append(previews, importantPreview)
+	}
+
+	if len(o.indexNotes) > 0 {
+		indexPreviews, err := o.previewIndexNotes(items, outputDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to preview index notes: %w", err)
+		}
+
+		previews = append(previews, indexPreviews...)
+	}
+
+	if o.personNotes {
+		personPreviews, err := o.previewPersonNotes(items, outputDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to preview person notes: %w", err)
+		}
+
+		previews = append(previews, personPreviews...)
+	}
+
+	if o.companyNotes {
+		companyPreviews, err := o.previewCompanyNotes(items, outputDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to preview company notes: %w", err)
+		}
+
+		previews = append(previews, companyPreviews...)
+	}
+
+	if o.attachmentManifest != "" {
+		manifestPreview, err := o.previewAttachmentManifest(items, outputDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to preview attachment manifest: %w", err)
+		}
+
+		if manifestPreview != nil {
+			previews = append(previews, manifestPreview)
+		}
+	}
+
+	return &interfaces.Plan{OutputDir: outputDir, Changes: previews}, nil
 }
 
 // Ensure ObsidianTarget implements Target interface.
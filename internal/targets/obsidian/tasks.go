@@ -0,0 +1,188 @@
+package obsidian
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"pkm-sync/internal/statestore"
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+// taskIndexFile records the completion state each task was last written with, so checking or unchecking
+// it in the vault can be told apart from an unchanged task.
+const taskIndexFile = ".pkm-sync-tasks.json"
+
+// trackedTask is what the last sync wrote for a task.
+type trackedTask struct {
+	Path       string `json:"path"` // Relative to the output directory
+	Note       string `json:"note"` // Note name used in Kanban card links
+	SourceType string `json:"source_type"`
+	Completed  bool   `json:"completed"`
+}
+
+// loadTaskIndex reads the task index of an output directory.
+func loadTaskIndex(outputDir string) (map[string]trackedTask, error) {
+	index := make(map[string]trackedTask)
+
+	data, err := statestore.ReadFile(filepath.Join(outputDir, taskIndexFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return index, nil
+		}
+
+		return nil, fmt.Errorf("failed to read task index: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse task index: %w", err)
+	}
+
+	return index, nil
+}
+
+// taskCompleted reports whether a task item is done: its completed property if set, else its status.
+func (o *ObsidianTarget) taskCompleted(item models.ItemInterface) bool {
+	if completed, ok := models.Metadata(item.GetMetadata()).GetBool(models.MetadataCompleted); ok {
+		return completed
+	}
+
+	return containsString(completedStatuses, strings.ToLower(o.kanbanStatus(item)))
+}
+
+// saveTaskIndex records the tasks written by this export; tasks synced earlier keep their entry.
+func (o *ObsidianTarget) saveTaskIndex(items []models.FullItem, outputDir string) error {
+	index, err := loadTaskIndex(outputDir)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+
+	for _, item := range items {
+		if !o.isKanbanItem(item) {
+			continue
+		}
+
+		relPath, err := filepath.Rel(outputDir, o.itemPath(item, outputDir))
+		if err != nil {
+			continue
+		}
+
+		index[item.GetID()] = trackedTask{
+			Path:       filepath.ToSlash(relPath),
+			Note:       o.noteName(item),
+			SourceType: item.GetSourceType(),
+			Completed:  o.taskCompleted(item),
+		}
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode task index: %w", err)
+	}
+
+	return statestore.WriteFile(filepath.Join(outputDir, taskIndexFile), data, 0644)
+}
+
+// TaskStatusChanges returns the synced tasks whose completed property or Kanban card checkbox was changed
+// in the vault since they were written. A changed property wins over the card.
+func (o *ObsidianTarget) TaskStatusChanges(outputDir string) ([]interfaces.TaskStatusChange, error) {
+	index, err := loadTaskIndex(outputDir)
+	if err != nil || len(index) == 0 {
+		return nil, err
+	}
+
+	cards := make(map[string]bool)
+
+	if o.kanbanBoard != "" {
+		board, err := o.readKanbanBoard(o.kanbanBoardPath(outputDir))
+		if err != nil {
+			return nil, err
+		}
+
+		cards = o.kanbanCardStates(board, index)
+	}
+
+	var changes []interfaces.TaskStatusChange
+
+	for id, task := range index {
+		completed, found := readCompletedProperty(filepath.Join(outputDir, filepath.FromSlash(task.Path)))
+		if !found || completed == task.Completed {
+			completed, found = cards[id]
+		}
+
+		if found && completed != task.Completed {
+			changes = append(changes, interfaces.TaskStatusChange{ItemID: id, SourceType: task.SourceType, Completed: completed})
+		}
+	}
+
+	return changes, nil
+}
+
+// kanbanCardStates returns whether each tracked task's card on the board is checked.
+func (o *ObsidianTarget) kanbanCardStates(board string, index map[string]trackedTask) map[string]bool {
+	states := make(map[string]bool)
+
+	for _, lane := range parseKanbanLanes(board) {
+		for _, card := range lane.cards {
+			checked := strings.HasPrefix(strings.TrimSpace(card), "- [x]") || strings.HasPrefix(strings.TrimSpace(card), "- [X]")
+
+			for id, task := range index {
+				if strings.Contains(card, o.formatNoteLink(task.Note, "")) {
+					states[id] = checked
+				}
+			}
+		}
+	}
+
+	return states
+}
+
+// readCompletedProperty reads the completed property from a note's frontmatter.
+func readCompletedProperty(path string) (bool, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+
+	for lineNum := 0; scanner.Scan(); lineNum++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+
+		if line == "---" {
+			if lineNum > 0 {
+				break
+			}
+
+			continue
+		}
+
+		if lineNum == 0 {
+			break
+		}
+
+		if value, found := strings.CutPrefix(line, models.MetadataCompleted+":"); found {
+			completed, err := strconv.ParseBool(strings.TrimSpace(value))
+
+			return completed, err == nil
+		}
+	}
+
+	return false, false
+}
+
+// Ensure ObsidianTarget reads task status changes.
+var _ interfaces.TaskStatusReader = (*ObsidianTarget)(nil)
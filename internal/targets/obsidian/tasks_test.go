@@ -0,0 +1,97 @@
+package obsidian
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func newStatusTestItem(id, title string, completed bool) models.FullItem {
+	item := models.NewBasicItem(id, title)
+	item.SetSourceType("google_tasks")
+	item.SetItemType("task")
+
+	status := "todo"
+	if completed {
+		status = "done"
+	}
+
+	item.SetMetadata(map[string]interface{}{"status": status, "completed": completed})
+
+	return item
+}
+
+func TestTaskStatusChanges(t *testing.T) {
+	outputDir := t.TempDir()
+
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{
+		"kanban_board": "Tasks",
+		"kanban_lanes": []string{"To Do", "Done"},
+	}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	items := []models.FullItem{
+		newStatusTestItem("1", "Write spec", false),
+		newStatusTestItem("2", "Ship it", true),
+		newStatusTestItem("3", "Untouched", false),
+	}
+	if err := target.Export(items, outputDir); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	if changes, err := target.TaskStatusChanges(outputDir); err != nil || len(changes) != 0 {
+		t.Fatalf("TaskStatusChanges() after export = %+v, %v, want none", changes, err)
+	}
+
+	// Check the first task through its property and reopen the second on the board
+	notePath := filepath.Join(outputDir, "Write-spec.md")
+
+	note, err := os.ReadFile(notePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(note), "completed: false") {
+		t.Fatalf("note has no completed property:\n%s", note)
+	}
+
+	edited := strings.Replace(string(note), "completed: false", "completed: true", 1)
+	if err := os.WriteFile(notePath, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	boardPath := filepath.Join(outputDir, "Tasks.md")
+
+	board, err := os.ReadFile(boardPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edited = strings.Replace(string(board), "- [x] [[Ship-it]]", "- [ ] [[Ship-it]]", 1)
+	if err := os.WriteFile(boardPath, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := target.TaskStatusChanges(outputDir)
+	if err != nil {
+		t.Fatalf("TaskStatusChanges() error = %v", err)
+	}
+
+	got := make(map[string]bool)
+	for _, change := range changes {
+		if change.SourceType != "google_tasks" {
+			t.Errorf("change %s has source type %q", change.ItemID, change.SourceType)
+		}
+
+		got[change.ItemID] = change.Completed
+	}
+
+	if len(got) != 2 || got["1"] != true || got["2"] != false {
+		t.Errorf("TaskStatusChanges() = %+v, want task 1 completed and task 2 reopened", changes)
+	}
+}
@@ -0,0 +1,129 @@
+package obsidian
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+// TemplateData is the data passed to a user-provided note template.
+type TemplateData struct {
+	ID          string
+	Title       string
+	Content     string
+	SourceType  string
+	ItemType    string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Tags        []string
+	Attachments []models.Attachment
+	Metadata    map[string]interface{}
+	Links       []models.Link
+	Messages    []TemplateData
+	IsThread    bool
+
+	// Frontmatter is the YAML frontmatter block the default layout would produce (including --- delimiters).
+	Frontmatter string
+	// InlineFields is the metadata rendered as Dataview inline fields (key:: value).
+	InlineFields string
+}
+
+// templateFuncs returns helper functions available to note templates.
+func (o *ObsidianTarget) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"date": func(layout string, t time.Time) string {
+			return t.Format(layout)
+		},
+		"join": strings.Join,
+		"wikilink": func(name string) string {
+			return "[[" + name + "]]"
+		},
+		"link": func(name string) string {
+			return o.formatNoteLink(name, "")
+		},
+		"default": func(fallback, value interface{}) interface{} {
+			if value == nil || value == "" {
+				return fallback
+			}
+
+			return value
+		},
+	}
+}
+
+// loadTemplate parses the template file used to render notes.
+func (o *ObsidianTarget) loadTemplate(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read template file %s: %w", path, err)
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Funcs(o.templateFuncs()).Parse(string(data))
+	if err != nil {
+		return fmt.Errorf("failed to parse template file %s: %w", path, err)
+	}
+
+	o.template = tmpl
+
+	return nil
+}
+
+// newTemplateData builds the template data for an item, including thread messages.
+func (o *ObsidianTarget) newTemplateData(item models.FullItem) TemplateData {
+	data := TemplateData{
+		ID:          item.GetID(),
+		Title:       item.GetTitle(),
+		Content:     o.itemContent(item),
+		SourceType:  item.GetSourceType(),
+		ItemType:    item.GetItemType(),
+		CreatedAt:   item.GetCreatedAt(),
+		UpdatedAt:   item.GetUpdatedAt(),
+		Tags:        o.noteTags(item),
+		Attachments: item.GetAttachments(),
+		Metadata:    item.GetMetadata(),
+		Links:       item.GetLinks(),
+		Frontmatter: o.formatFrontmatter(item),
+
+		InlineFields: o.formatInlineFields(item),
+	}
+
+	if thread, ok := models.AsThread(item); ok {
+		data.IsThread = true
+
+		for _, message := range thread.GetMessages() {
+			data.Messages = append(data.Messages, o.newTemplateData(message))
+		}
+	}
+
+	return data
+}
+
+// renderTemplate renders an item through the configured template.
+func (o *ObsidianTarget) renderTemplate(item models.FullItem) (string, error) {
+	var sb strings.Builder
+
+	if err := o.template.Execute(&sb, o.newTemplateData(item)); err != nil {
+		return "", fmt.Errorf("failed to render template for item %s: %w", item.GetID(), err)
+	}
+
+	return sb.String(), nil
+}
+
+// RenderTemplateFile renders an item through a template file, as an export with template_file set to
+// path would write its note, so templates can be tried without syncing. The file replaces the target's
+// configured template.
+func (o *ObsidianTarget) RenderTemplateFile(path string, item models.FullItem) (string, error) {
+	if err := o.loadTemplate(path); err != nil {
+		return "", err
+	}
+
+	return o.formatContent(item)
+}
+
+var _ interfaces.TemplateRenderer = (*ObsidianTarget)(nil)
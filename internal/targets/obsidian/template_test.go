@@ -0,0 +1,122 @@
+package obsidian
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func writeTestTemplate(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "note.tmpl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	return path
+}
+
+func TestTemplateFileRendering(t *testing.T) {
+	templatePath := writeTestTemplate(t, `{{.Frontmatter}}
+# {{.Title}} ({{.SourceType}})
+Date: {{date "2006-01-02" .CreatedAt}}
+Tags: {{join .Tags ", "}}
+Location: {{default "none" (index .Metadata "location")}}
+{{.Content}}`)
+
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{"template_file": templatePath}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	item := models.NewBasicItem("evt-1", "Planning")
+	item.SetSourceType("google_calendar")
+	item.SetItemType("event")
+	item.SetContent("Agenda goes here")
+	item.SetCreatedAt(time.Date(2025, 3, 4, 9, 0, 0, 0, time.UTC))
+	item.SetTags([]string{"work", "meeting"})
+
+	content, err := target.formatContent(item)
+	if err != nil {
+		t.Fatalf("formatContent() error = %v", err)
+	}
+
+	expectedParts := []string{
+		"---\nid: evt-1\n",
+		"# Planning (google_calendar)",
+		"Date: 2025-03-04",
+		"Tags: work, meeting",
+		"Location: none",
+		"Agenda goes here",
+	}
+
+	for _, part := range expectedParts {
+		if !strings.Contains(content, part) {
+			t.Errorf("rendered content missing %q:\n%s", part, content)
+		}
+	}
+}
+
+func TestTemplateFileThreadMessages(t *testing.T) {
+	templatePath := writeTestTemplate(t, `{{range .Messages}}- {{.Title}}
+{{end}}`)
+
+	target := NewObsidianTarget()
+	if err := target.Configure(map[string]interface{}{"template_file": templatePath}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	thread := models.NewThread("thread-1", "Discussion")
+	thread.AddMessage(models.NewBasicItem("m1", "First"))
+	thread.AddMessage(models.NewBasicItem("m2", "Second"))
+
+	content, err := target.formatContent(thread)
+	if err != nil {
+		t.Fatalf("formatContent() error = %v", err)
+	}
+
+	if content != "- First\n- Second\n" {
+		t.Errorf("formatContent() = %q", content)
+	}
+}
+
+func TestTemplateFileInvalid(t *testing.T) {
+	target := NewObsidianTarget()
+
+	err := target.Configure(map[string]interface{}{"template_file": writeTestTemplate(t, "{{.Title")})
+	if err == nil {
+		t.Error("expected parse error for invalid template")
+	}
+
+	err = target.Configure(map[string]interface{}{"template_file": "/nonexistent/template.tmpl"})
+	if err == nil {
+		t.Error("expected error for missing template file")
+	}
+}
+
+func TestRenderTemplateFile(t *testing.T) {
+	templatePath := writeTestTemplate(t, `# {{.Title}}{{range .Messages}}
+- {{.Title}}{{end}}`)
+
+	thread := models.NewThread("thread-1", "Budget")
+	thread.AddMessage(models.NewBasicItem("msg-1", "Budget draft"))
+	thread.AddMessage(models.NewBasicItem("msg-2", "Re: Budget draft"))
+
+	content, err := NewObsidianTarget().RenderTemplateFile(templatePath, thread)
+	if err != nil {
+		t.Fatalf("RenderTemplateFile() error = %v", err)
+	}
+
+	if want := "# Budget\n- Budget draft\n- Re: Budget draft"; !strings.HasPrefix(content, want) {
+		t.Errorf("RenderTemplateFile() = %q, want it to start with %q", content, want)
+	}
+
+	if _, err := NewObsidianTarget().RenderTemplateFile(writeTestTemplate(t, "{{.Title"), thread); err == nil {
+		t.Error("RenderTemplateFile() accepted a template that does not parse")
+	}
+}
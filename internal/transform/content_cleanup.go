@@ -1,13 +1,16 @@
 package transform
 
 import (
+	"fmt"
 	"html"
 	"log"
 	"regexp"
+	"strconv"
 	"strings"
 
 	nethtml "golang.org/x/net/html"
 
+	"pkm-sync/internal/utils"
 	"pkm-sync/pkg/interfaces"
 	"pkm-sync/pkg/models"
 )
@@ -16,6 +19,21 @@ const (
 	transformerNameContentCleanup = "content_cleanup"
 	htmlTagTh                     = "th"
 	htmlTagTd                     = "td"
+
+	// maxTableColumns is the widest table rendered as a markdown table; wider ones become fenced text
+	maxTableColumns = 20
+
+	// Handling of quoted replies, set with quoted_text_mode
+	quotedTextStrip    = "strip"
+	quotedTextCollapse = "collapse"
+
+	quotedTitlePrevious  = "Previous messages"
+	quotedTitleForwarded = "Forwarded message"
+	quotedTitleSignature = "Signature"
+
+	// Rendering of <details> blocks, set with details_style
+	detailsStyleCallout = "callout"
+	detailsStyleHTML    = "html"
 )
 
 // ContentCleanupTransformer provides HTML→Markdown conversion and content cleanup.
@@ -26,6 +44,9 @@ type ContentCleanupTransformer struct {
 	// Pre-compiled regular expressions for performance
 	whitespaceCleanupRegex *regexp.Regexp
 	consecutiveAsterisks   *regexp.Regexp
+	codeDepth              int // Nesting of <code>/<pre> during conversion, where text is kept verbatim
+
+	quoteRules []quoteRule // Reply and forward headers that start quoted text
 }
 
 func NewContentCleanupTransformer() *ContentCleanupTransformer {
@@ -33,6 +54,7 @@ func NewContentCleanupTransformer() *ContentCleanupTransformer {
 		config:                 make(map[string]interface{}),
 		whitespaceCleanupRegex: regexp.MustCompile(`\n\s*\n\s*\n`),
 		consecutiveAsterisks:   regexp.MustCompile(`\*{4,}`),
+		quoteRules:             quoteLocales[defaultQuoteLocale],
 	}
 }
 
@@ -41,7 +63,29 @@ func (t *ContentCleanupTransformer) Name() string {
 }
 
 func (t *ContentCleanupTransformer) Configure(config map[string]interface{}) error {
+	if style, ok := config["details_style"].(string); ok {
+		switch style {
+		case "", detailsStyleCallout, detailsStyleHTML:
+		default:
+			return fmt.Errorf("unsupported details_style '%s': supported styles are 'callout', 'html'", style)
+		}
+	}
+
+	if mode, ok := config["quoted_text_mode"].(string); ok {
+		switch mode {
+		case "", quotedTextStrip, quotedTextCollapse:
+		default:
+			return fmt.Errorf("unsupported quoted_text_mode '%s': supported modes are 'strip', 'collapse'", mode)
+		}
+	}
+
+	quoteRules, err := loadQuoteRules(config)
+	if err != nil {
+		return err
+	}
+
 	t.config = config
+	t.quoteRules = quoteRules
 
 	return nil
 }
@@ -118,9 +162,20 @@ func (t *ContentCleanupTransformer) Transform(items []models.FullItem) ([]models
 			}
 		}
 
-		// Strip quoted text if enabled
+		// Strip or collapse quoted text if enabled
 		if t.shouldStripQuotedText() {
-			cleanedContent := t.StripQuotedText(newItem.GetContent())
+			threshold := t.getSignatureDetectionThreshold()
+			if sourceThreshold, ok := itemSignatureThreshold(t.config, item); ok {
+				threshold = sourceThreshold
+			}
+
+			var cleanedContent string
+			if t.getQuotedTextMode() == quotedTextCollapse {
+				cleanedContent = t.collapseQuotedText(newItem.GetContent(), threshold)
+			} else {
+				cleanedContent = t.stripQuotedText(newItem.GetContent(), threshold)
+			}
+
 			if cleanedContent != newItem.GetContent() {
 				newItem.SetContent(cleanedContent)
 
@@ -178,50 +233,86 @@ func (t *ContentCleanupTransformer) ProcessHTMLContent(htmlContent string) strin
 // StripQuotedText removes quoted text from email content with enhanced detection.
 // Extracted from Gmail's ContentProcessor.StripQuotedText.
 func (t *ContentCleanupTransformer) StripQuotedText(content string) string {
+	return t.stripQuotedText(content, t.getSignatureDetectionThreshold())
+}
+
+func (t *ContentCleanupTransformer) stripQuotedText(content string, threshold int) string {
 	lines := strings.Split(content, "\n")
-	result := make([]string, 0, len(lines))
 
-	for i, line := range lines {
-		trimmed := strings.TrimSpace(line)
+	if start, _ := t.quotedTextStart(lines, threshold); start >= 0 {
+		lines = lines[:start]
+	}
 
-		// Skip lines that start with common quote indicators
-		if strings.HasPrefix(trimmed, ">") {
-			break // Stop processing at first quoted line
-		}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
 
-		// Check for "On [date] [person] wrote:" patterns
-		if strings.HasPrefix(trimmed, "On ") && strings.Contains(trimmed, " wrote:") {
-			break
-		}
+// CollapseQuotedText keeps quoted and forwarded text, or a trailing signature, but moves it into a
+// collapsed Obsidian callout after the new text. The Logseq target renders such callouts as collapsed blocks.
+func (t *ContentCleanupTransformer) CollapseQuotedText(content string) string {
+	return t.collapseQuotedText(content, t.getSignatureDetectionThreshold())
+}
 
-		// Check for "From: [email]" patterns (often indicates forwarded content)
-		if strings.HasPrefix(trimmed, "From: ") && strings.Contains(trimmed, "@") {
-			break
+func (t *ContentCleanupTransformer) collapseQuotedText(content string, threshold int) string {
+	lines := strings.Split(content, "\n")
+
+	start, title := t.quotedTextStart(lines, threshold)
+	if start < 0 {
+		return strings.TrimSpace(content)
+	}
+
+	quoted := strings.Split(strings.TrimSpace(strings.Join(lines[start:], "\n")), "\n")
+
+	var sb strings.Builder
+
+	if text := strings.TrimSpace(strings.Join(lines[:start], "\n")); text != "" {
+		sb.WriteString(text)
+		sb.WriteString("\n\n")
+	}
+
+	sb.WriteString("> [!quote]- " + title + "\n")
+
+	for _, line := range quoted {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			sb.WriteString(">\n")
+		} else {
+			sb.WriteString("> " + line + "\n")
 		}
+	}
 
-		// Check for "-----Original Message-----" patterns
-		if strings.Contains(trimmed, "Original Message") || strings.Contains(trimmed, "original message") {
-			break
+	return strings.TrimSpace(sb.String())
+}
+
+// quotedTextStart returns the line where quoted text or a trailing signature begins, with a title
+// describing it, or -1 when there is none. A "--" separator counts as a signature when at most threshold
+// lines remain.
+func (t *ContentCleanupTransformer) quotedTextStart(lines []string, threshold int) (int, string) {
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		// Skip lines that start with common quote indicators
+		if strings.HasPrefix(trimmed, ">") {
+			return i, quotedTitlePrevious // Stop processing at first quoted line
 		}
 
-		// Check for forwarding indicators
-		if strings.HasPrefix(trimmed, "---------- Forwarded message") {
-			break
+		// Reply headers such as "On [date] [person] wrote:", forwarded and original message markers
+		for _, rule := range t.quoteRules {
+			if rule.pattern.MatchString(trimmed) {
+				return i, rule.title
+			}
 		}
 
 		// Check for signature separators
 		if trimmed == "--" || strings.HasPrefix(trimmed, "-- ") {
 			// This might be a signature, check if this is near the end
 			remainingLines := len(lines) - i
-			if remainingLines <= t.getSignatureDetectionThreshold() {
-				break
+			if remainingLines <= threshold {
+				return i, quotedTitleSignature
 			}
 		}
-
-		result = append(result, line)
 	}
 
-	return strings.TrimSpace(strings.Join(result, "\n"))
+	return -1, ""
 }
 
 // convertNodeToMarkdown recursively converts HTML nodes to markdown.
@@ -230,6 +321,11 @@ func (t *ContentCleanupTransformer) convertNodeToMarkdown(n *nethtml.Node, markd
 	switch n.Type {
 	case nethtml.TextNode:
 		text := t.unescapeHTMLEntities(n.Data)
+		if t.codeDepth == 0 {
+			// Literal *, _, [ or # in prose must not turn into formatting
+			text = utils.EscapeMarkdown(text)
+		}
+
 		markdown.WriteString(text)
 
 	case nethtml.ElementNode:
@@ -272,13 +368,17 @@ func (t *ContentCleanupTransformer) convertNodeToMarkdown(n *nethtml.Node, markd
 			t.convertChildNodes(n, markdown)
 			markdown.WriteString("*")
 		case "code":
+			t.codeDepth++
 			markdown.WriteString("`")
 			t.convertChildNodes(n, markdown)
 			markdown.WriteString("`")
+			t.codeDepth--
 		case "pre":
+			t.codeDepth++
 			markdown.WriteString("```\n")
 			t.convertChildNodes(n, markdown)
 			markdown.WriteString("\n```\n")
+			t.codeDepth--
 		case "blockquote":
 			// Process blockquote content and add > prefix to each line
 			var blockquoteContent strings.Builder
@@ -297,18 +397,44 @@ func (t *ContentCleanupTransformer) convertNodeToMarkdown(n *nethtml.Node, markd
 					}
 				}
 			}
-		case "ul":
+		case "ul", "ol":
 			markdown.WriteString("\n")
-			t.convertChildNodes(n, markdown)
+			t.convertList(n, markdown)
 			markdown.WriteString("\n")
-		case "ol":
+		case "li":
+			// A list item outside a list
+			markdown.WriteString("- ")
+			t.convertChildNodes(n, markdown)
 			markdown.WriteString("\n")
+		case "del", "s", "strike":
+			markdown.WriteString("~~")
+			t.convertChildNodes(n, markdown)
+			markdown.WriteString("~~")
+		case "sup", "sub":
+			// Markdown has no syntax for these; Obsidian and Logseq render the inline HTML
+			markdown.WriteString("<" + n.Data + ">")
 			t.convertChildNodes(n, markdown)
+			markdown.WriteString("</" + n.Data + ">")
+		case "hr":
+			markdown.WriteString("\n\n---\n\n")
+		case "dl":
 			markdown.WriteString("\n")
-		case "li":
-			markdown.WriteString("- ")
 			t.convertChildNodes(n, markdown)
 			markdown.WriteString("\n")
+		case "dt":
+			var term strings.Builder
+
+			t.convertChildNodes(n, &term)
+			markdown.WriteString("\n**")
+			markdown.WriteString(strings.Join(strings.Fields(term.String()), " "))
+			markdown.WriteString("**\n")
+		case "dd":
+			var definition strings.Builder
+
+			t.convertChildNodes(n, &definition)
+			writeListItem(markdown, ": ", definition.String())
+		case "details":
+			t.convertDetails(n, markdown)
 		case "a":
 			href := t.getAttributeValue(n, "href")
 			if href != "" {
@@ -326,7 +452,7 @@ func (t *ContentCleanupTransformer) convertNodeToMarkdown(n *nethtml.Node, markd
 
 			if src != "" {
 				markdown.WriteString("![")
-				markdown.WriteString(alt)
+				markdown.WriteString(utils.EscapeMarkdown(alt))
 				markdown.WriteString("](")
 				markdown.WriteString(src)
 				markdown.WriteString(")")
@@ -336,11 +462,10 @@ func (t *ContentCleanupTransformer) convertNodeToMarkdown(n *nethtml.Node, markd
 			markdown.WriteString("\n")
 		case "table":
 			markdown.WriteString("\n")
-			t.convertChildNodes(n, markdown)
+			t.convertTable(n, markdown)
 			markdown.WriteString("\n")
-		case "tr":
-			t.convertTableRow(n, markdown)
-		case htmlTagTd, htmlTagTh:
+		case "tr", htmlTagTd, htmlTagTh:
+			// Table parts outside a table
 			t.convertChildNodes(n, markdown)
 		case "style", "script":
 			// Skip style and script tags completely
@@ -363,57 +488,380 @@ func (t *ContentCleanupTransformer) convertChildNodes(n *nethtml.Node, markdown
 	}
 }
 
-// convertTableRow processes a table row with proper cell separation.
-func (t *ContentCleanupTransformer) convertTableRow(n *nethtml.Node, markdown *strings.Builder) {
-	markdown.WriteString("| ")
+// convertList renders a list's items as "-" or numbered markers, counting from the start attribute of an
+// <ol> and honouring value attributes on its items. Nested lists, including ones placed directly inside
+// the list rather than in an item, are indented under the preceding item.
+func (t *ContentCleanupTransformer) convertList(n *nethtml.Node, markdown *strings.Builder) {
+	ordered := n.Data == "ol"
 
-	// Count cells first
-	var cells []*nethtml.Node
+	number := 1
+	if start, err := strconv.Atoi(strings.TrimSpace(t.getAttributeValue(n, "start"))); err == nil {
+		number = start
+	}
+
+	indent := ""
 
 	for child := n.FirstChild; child != nil; child = child.NextSibling {
-		if child.Type == nethtml.ElementNode && (child.Data == "td" || child.Data == "th") {
-			cells = append(cells, child)
+		switch {
+		case child.Type == nethtml.TextNode && strings.TrimSpace(child.Data) == "":
+			// Whitespace between items
+		case child.Type == nethtml.ElementNode && child.Data == "li":
+			marker := "- "
+			if ordered {
+				if value, err := strconv.Atoi(strings.TrimSpace(t.getAttributeValue(child, "value"))); err == nil {
+					number = value
+				}
+
+				marker = strconv.Itoa(number) + ". "
+				number++
+			}
+
+			var item strings.Builder
+
+			t.convertChildNodes(child, &item)
+			writeListItem(markdown, marker, item.String())
+
+			indent = strings.Repeat(" ", len(marker))
+		case child.Type == nethtml.ElementNode && (child.Data == "ul" || child.Data == "ol"):
+			var nested strings.Builder
+
+			t.convertList(child, &nested)
+			writeIndentedLines(markdown, indent, nested.String())
+		default:
+			t.convertNodeToMarkdown(child, markdown)
+		}
+	}
+}
+
+// writeListItem writes an item's marker and content, indenting continuation lines and nested lists to the
+// item's content column. Blank lines are dropped so the list stays tight.
+func writeListItem(markdown *strings.Builder, marker, content string) {
+	lines := nonEmptyLines(content)
+	if len(lines) == 0 {
+		lines = []string{""}
+	}
+
+	markdown.WriteString(marker)
+	markdown.WriteString(strings.TrimSpace(lines[0]))
+	markdown.WriteString("\n")
+
+	writeIndentedLines(markdown, strings.Repeat(" ", len(marker)), strings.Join(lines[1:], "\n"))
+}
+
+func writeIndentedLines(markdown *strings.Builder, indent, content string) {
+	for _, line := range nonEmptyLines(content) {
+		markdown.WriteString(indent)
+		markdown.WriteString(line)
+		markdown.WriteString("\n")
+	}
+}
+
+func nonEmptyLines(content string) []string {
+	var lines []string
+
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, strings.TrimRight(line, " \t"))
+		}
+	}
+
+	return lines
+}
+
+// convertDetails renders a <details> block as a collapsed Obsidian callout titled by its <summary>, or
+// keeps it as a <details> element when details_style is "html". Blocks with the open attribute stay expanded.
+func (t *ContentCleanupTransformer) convertDetails(n *nethtml.Node, markdown *strings.Builder) {
+	var summary, body strings.Builder
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == nethtml.ElementNode && child.Data == "summary" && summary.Len() == 0 {
+			t.convertChildNodes(child, &summary)
+
+			continue
+		}
+
+		t.convertNodeToMarkdown(child, &body)
+	}
+
+	title := strings.Join(strings.Fields(summary.String()), " ")
+	if title == "" {
+		title = "Details"
+	}
+
+	_, open := t.getAttribute(n, "open")
+	content := strings.TrimSpace(t.whitespaceCleanupRegex.ReplaceAllString(body.String(), "\n\n"))
+
+	if t.getDetailsStyle() == detailsStyleHTML {
+		markdown.WriteString("\n<details")
+
+		if open {
+			markdown.WriteString(" open")
+		}
+
+		markdown.WriteString("><summary>" + title + "</summary>\n\n")
+
+		if content != "" {
+			markdown.WriteString(content + "\n\n")
 		}
+
+		markdown.WriteString("</details>\n")
+
+		return
 	}
 
-	// Process each cell
-	for i, cell := range cells {
-		t.convertChildNodes(cell, markdown)
+	fold := "-"
+	if open {
+		fold = "+"
+	}
+
+	markdown.WriteString("\n> [!note]" + fold + " " + title + "\n")
+
+	if content == "" {
+		return
+	}
 
-		if i < len(cells)-1 {
-			markdown.WriteString(" | ")
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			markdown.WriteString(">\n")
 		} else {
-			// Check if this row has header cells - if so, add trailing space
-			hasHeaders := false
+			markdown.WriteString("> " + line + "\n")
+		}
+	}
+}
 
-			for _, c := range cells {
-				if c.Data == "th" {
-					hasHeaders = true
+// tableCell is a converted table cell.
+type tableCell struct {
+	content string
+	align   string
+}
 
-					break
-				}
+// convertTable buffers a table's rows and renders them as a markdown table with a header separator. The
+// first row is the header; a colspan repeats empty cells so columns stay aligned. Tables markdown cannot
+// represent (nested tables, more than maxTableColumns columns) are rendered as fenced text instead.
+func (t *ContentCleanupTransformer) convertTable(n *nethtml.Node, markdown *strings.Builder) {
+	rowNodes := tableRows(n)
+	if len(rowNodes) == 0 {
+		return
+	}
+
+	if t.codeDepth > 0 {
+		// A table nested in a fenced-text table
+		t.writeTableText(rowNodes, markdown)
+
+		return
+	}
+
+	if hasNestedTable(n) || tableColumns(rowNodes) > maxTableColumns {
+		t.convertTableAsText(rowNodes, markdown)
+
+		return
+	}
+
+	rows := make([][]tableCell, 0, len(rowNodes))
+	columns := 0
+
+	for _, rowNode := range rowNodes {
+		var row []tableCell
+
+		for _, cellNode := range tableCells(rowNode) {
+			var content strings.Builder
+
+			t.convertChildNodes(cellNode, &content)
+
+			row = append(row, tableCell{
+				content: utils.EscapeTableCell(content.String()),
+				align:   strings.ToLower(t.getAttributeValue(cellNode, "align")),
+			})
+
+			for i := 1; i < colspan(cellNode); i++ {
+				row = append(row, tableCell{})
 			}
+		}
 
-			if hasHeaders {
-				markdown.WriteString(" | ")
-			} else {
-				markdown.WriteString(" |")
+		rows = append(rows, row)
+		columns = max(columns, len(row))
+	}
+
+	if columns == 0 {
+		return
+	}
+
+	writeTableRow(markdown, rows[0], columns)
+
+	markdown.WriteString("|")
+
+	for i := 0; i < columns; i++ {
+		var align string
+		if i < len(rows[0]) {
+			align = rows[0][i].align
+		}
+
+		switch align {
+		case "center":
+			markdown.WriteString(" :---: |")
+		case "right":
+			markdown.WriteString(" ---: |")
+		case "left":
+			markdown.WriteString(" :--- |")
+		default:
+			markdown.WriteString(" --- |")
+		}
+	}
+
+	markdown.WriteString("\n")
+
+	for _, row := range rows[1:] {
+		writeTableRow(markdown, row, columns)
+	}
+}
+
+// convertTableAsText renders a table as fenced text, one line per row with cells separated by " | ".
+func (t *ContentCleanupTransformer) convertTableAsText(rowNodes []*nethtml.Node, markdown *strings.Builder) {
+	t.codeDepth++
+	defer func() { t.codeDepth-- }()
+
+	markdown.WriteString("```text\n")
+	t.writeTableText(rowNodes, markdown)
+	markdown.WriteString("```\n")
+}
+
+// writeTableText writes each row's non-empty cells as one line of text.
+func (t *ContentCleanupTransformer) writeTableText(rowNodes []*nethtml.Node, markdown *strings.Builder) {
+	for _, rowNode := range rowNodes {
+		var cells []string
+
+		for _, cellNode := range tableCells(rowNode) {
+			var content strings.Builder
+
+			t.convertChildNodes(cellNode, &content)
+
+			if text := strings.Join(strings.Fields(content.String()), " "); text != "" {
+				cells = append(cells, text)
 			}
 		}
+
+		if len(cells) > 0 {
+			markdown.WriteString(strings.Join(cells, " | "))
+			markdown.WriteString("\n")
+		}
+	}
+}
+
+func writeTableRow(markdown *strings.Builder, row []tableCell, columns int) {
+	markdown.WriteString("|")
+
+	for i := 0; i < columns; i++ {
+		markdown.WriteString(" ")
+
+		if i < len(row) && row[i].content != "" {
+			markdown.WriteString(row[i].content)
+			markdown.WriteString(" ")
+		}
+
+		markdown.WriteString("|")
 	}
 
 	markdown.WriteString("\n")
 }
 
+// tableRows returns the rows of a table, including those in thead, tbody and tfoot but not in nested tables.
+func tableRows(table *nethtml.Node) []*nethtml.Node {
+	var rows []*nethtml.Node
+
+	for child := table.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != nethtml.ElementNode {
+			continue
+		}
+
+		switch child.Data {
+		case "tr":
+			rows = append(rows, child)
+		case "thead", "tbody", "tfoot":
+			rows = append(rows, tableRows(child)...)
+		}
+	}
+
+	return rows
+}
+
+func tableCells(row *nethtml.Node) []*nethtml.Node {
+	var cells []*nethtml.Node
+
+	for child := row.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == nethtml.ElementNode && (child.Data == htmlTagTd || child.Data == htmlTagTh) {
+			cells = append(cells, child)
+		}
+	}
+
+	return cells
+}
+
+// tableColumns returns the widest row's column count, counting colspans.
+func tableColumns(rows []*nethtml.Node) int {
+	columns := 0
+
+	for _, row := range rows {
+		width := 0
+		for _, cell := range tableCells(row) {
+			width += colspan(cell)
+		}
+
+		columns = max(columns, width)
+	}
+
+	return columns
+}
+
+func colspan(cell *nethtml.Node) int {
+	for _, attr := range cell.Attr {
+		if attr.Key == "colspan" {
+			if span, err := strconv.Atoi(strings.TrimSpace(attr.Val)); err == nil && span > 1 {
+				return min(span, maxTableColumns+1)
+			}
+		}
+	}
+
+	return 1
+}
+
+func hasNestedTable(table *nethtml.Node) bool {
+	var found bool
+
+	var walk func(n *nethtml.Node)
+	walk = func(n *nethtml.Node) {
+		for child := n.FirstChild; child != nil && !found; child = child.NextSibling {
+			if child.Type == nethtml.ElementNode && child.Data == "table" {
+				found = true
+
+				return
+			}
+
+			walk(child)
+		}
+	}
+
+	walk(table)
+
+	return found
+}
+
 // getAttributeValue gets the value of an HTML attribute.
 func (t *ContentCleanupTransformer) getAttributeValue(n *nethtml.Node, attrName string) string {
+	value, _ := t.getAttribute(n, attrName)
+
+	return value
+}
+
+// getAttribute gets the value of an HTML attribute and whether it is present, for boolean attributes.
+func (t *ContentCleanupTransformer) getAttribute(n *nethtml.Node, attrName string) (string, bool) {
 	for _, attr := range n.Attr {
 		if attr.Key == attrName {
-			return attr.Val
+			return attr.Val, true
 		}
 	}
 
-	return ""
+	return "", false
 }
 
 // unescapeHTMLEntities handles HTML entities including common ones like &hellip;, &ldquo;, etc.
@@ -522,6 +970,24 @@ func (t *ContentCleanupTransformer) shouldRemoveExtraWhitespace() bool {
 	return true // Default: enabled
 }
 
+// getQuotedTextMode returns what happens to quoted text: "strip" (default) or "collapse".
+func (t *ContentCleanupTransformer) getQuotedTextMode() string {
+	if mode, ok := t.config["quoted_text_mode"].(string); ok && mode != "" {
+		return mode
+	}
+
+	return quotedTextStrip
+}
+
+// getDetailsStyle returns how <details> blocks are rendered: "callout" (default) or "html".
+func (t *ContentCleanupTransformer) getDetailsStyle() string {
+	if style, ok := t.config["details_style"].(string); ok && style != "" {
+		return style
+	}
+
+	return detailsStyleCallout
+}
+
 // getSignatureDetectionThreshold returns the configurable threshold for signature detection.
 func (t *ContentCleanupTransformer) getSignatureDetectionThreshold() int {
 	if val, exists := t.config["signature_detection_threshold"]; exists {
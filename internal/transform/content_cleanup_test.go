@@ -53,6 +53,94 @@ func TestContentCleanupTransformer_ProcessHTMLContent(t *testing.T) {
 			input:    "<ul><li>Item 1</li><li>Item 2</li></ul>",
 			expected: "- Item 1\n- Item 2",
 		},
+		{
+			name:     "HTML with ordered list",
+			input:    "<ol>\n  <li>First</li>\n  <li>Second</li>\n</ol>",
+			expected: "1. First\n2. Second",
+		},
+		{
+			name:     "HTML with ordered list start and value",
+			input:    "<ol start=\"9\"><li>Nine</li><li>Ten</li><li value=\"20\">Twenty</li><li>Next</li></ol>",
+			expected: "9. Nine\n10. Ten\n20. Twenty\n21. Next",
+		},
+		{
+			name:     "HTML with nested lists",
+			input:    "<ol><li>Plan<ul><li>Scope</li><li>Budget<ol><li>Q1</li></ol></li></ul></li><li>Build</li></ol>",
+			expected: "1. Plan\n   - Scope\n   - Budget\n     1. Q1\n2. Build",
+		},
+		{
+			name:     "HTML with list nested directly in list",
+			input:    "<ul><li>Parent</li><ul><li>Child</li></ul><li>Sibling</li></ul>",
+			expected: "- Parent\n  - Child\n- Sibling",
+		},
+		{
+			name:     "HTML with literal markdown characters",
+			input:    "<p>*** Flash sale *** on [all] items_ #deals</p>",
+			expected: "\\*\\*\\* Flash sale \\*\\*\\* on \\[all\\] items\\_ \\#deals",
+		},
+		{
+			name:     "HTML code keeps literal characters",
+			input:    "<p>Run <code>ls *_test.go</code></p><pre>a[0] = *p</pre>",
+			expected: "Run `ls *_test.go`\n\n```\na[0] = *p\n```",
+		},
+		{
+			name:     "HTML table cells with pipes and line breaks",
+			input:    "<table><tr><th>Dir</th><th>Notes</th></tr><tr><td>in | out</td><td><p>1</p><p>2</p></td></tr></table>",
+			expected: "| Dir | Notes |\n| --- | --- |\n| in \\| out | 1<br>2 |",
+		},
+		{
+			name: "HTML table with sections, alignment and colspan",
+			input: "<table><thead><tr><th align=\"left\">Item</th><th align=\"right\">Q1</th>" +
+				"<th align=\"center\">Q2</th></tr></thead>" +
+				"<tbody><tr><td>Sales</td><td>10</td><td>12</td></tr><tr><td colspan=\"2\">Total</td><td>22</td></tr>" +
+				"<tr><td>Short</td></tr></tbody></table>",
+			expected: "| Item | Q1 | Q2 |\n| :--- | ---: | :---: |\n| Sales | 10 | 12 |\n| Total | | 22 |\n| Short | | |",
+		},
+		{
+			name:     "HTML table without header cells uses first row",
+			input:    "<table>\n<tr>\n<td>a</td>\n<td>b</td>\n</tr>\n<tr><td>c</td><td>d</td></tr></table>",
+			expected: "| a | b |\n| --- | --- |\n| c | d |",
+		},
+		{
+			name:     "HTML layout table with nested table falls back to text",
+			input:    "<table><tr><td>Logo</td><td><table><tr><td>*Deal*</td></tr></table></td></tr></table>",
+			expected: "```text\nLogo | *Deal*\n```",
+		},
+		{
+			name:     "HTML with strikethrough",
+			input:    "<p>Was <del>$99</del> <s>$79</s> now <strike>free</strike></p>",
+			expected: "Was ~~$99~~ ~~$79~~ now ~~free~~",
+		},
+		{
+			name:     "HTML with superscript and subscript",
+			input:    "<p>E = mc<sup>2</sup>, H<sub>2</sub>O</p>",
+			expected: "E = mc<sup>2</sup>, H<sub>2</sub>O",
+		},
+		{
+			name:     "HTML with horizontal rule",
+			input:    "<p>Above</p><hr><p>Below</p>",
+			expected: "Above\n\n---\n\nBelow",
+		},
+		{
+			name:     "HTML with definition list",
+			input:    "<dl><dt>SLA</dt><dd>Service level agreement</dd><dt>RPO</dt><dd><p>Recovery</p><p>point</p></dd></dl>",
+			expected: "**SLA**\n: Service level agreement\n\n**RPO**\n: Recovery\n  point",
+		},
+		{
+			name:     "HTML with details",
+			input:    "<details><summary>Release <b>notes</b></summary><p>Fixed bugs</p><ul><li>Login</li></ul></details>",
+			expected: "> [!note]- Release **notes**\n> Fixed bugs\n>\n> - Login",
+		},
+		{
+			name:     "HTML with open details",
+			input:    "<details open><p>Body</p></details>",
+			expected: "> [!note]+ Details\n> Body",
+		},
+		{
+			name:     "HTML with paragraphs in list items",
+			input:    "<ul><li><p>Intro</p><p>More</p></li><li>Short</li></ul>",
+			expected: "- Intro\n  More\n- Short",
+		},
 		{
 			name:     "HTML with links",
 			input:    "<a href=\"https://example.com\">Link</a>",
@@ -88,6 +176,24 @@ func TestContentCleanupTransformer_ProcessHTMLContent(t *testing.T) {
 	}
 }
 
+func TestContentCleanupTransformer_DetailsStyle(t *testing.T) {
+	transformer := NewContentCleanupTransformer()
+	if err := transformer.Configure(map[string]interface{}{"details_style": "html"}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	got := transformer.ProcessHTMLContent("<details open><summary>More</summary><p>Body</p></details>")
+
+	expected := "<details open><summary>More</summary>\n\nBody\n\n</details>"
+	if got != expected {
+		t.Errorf("ProcessHTMLContent() = %q, want %q", got, expected)
+	}
+
+	if err := transformer.Configure(map[string]interface{}{"details_style": "fold"}); err == nil {
+		t.Error("Configure() expected error for unsupported details_style")
+	}
+}
+
 func TestContentCleanupTransformer_StripQuotedText(t *testing.T) {
 	transformer := NewContentCleanupTransformer()
 
@@ -149,6 +255,147 @@ From: sender@example.com`,
 	}
 }
 
+func TestContentCleanupTransformer_CollapseQuotedText(t *testing.T) {
+	transformer := NewContentCleanupTransformer()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:  "Collapse reply history",
+			input: "Sounds good.\n\nOn Mon, Jan 1, 2024 at 10:00 AM, John Doe wrote:\n> Lunch?\n>\n> John",
+			expected: "Sounds good.\n\n> [!quote]- Previous messages\n" +
+				"> On Mon, Jan 1, 2024 at 10:00 AM, John Doe wrote:\n> > Lunch?\n> >\n> > John",
+		},
+		{
+			name:  "Collapse forwarded message",
+			input: "FYI\n\n---------- Forwarded message ---------\nFrom: someone@example.com\n\nDetails",
+			expected: "FYI\n\n> [!quote]- Forwarded message\n" +
+				"> ---------- Forwarded message ---------\n> From: someone@example.com\n>\n> Details",
+		},
+		{
+			name:     "Collapse signature",
+			input:    "Thanks\n--\nJane Doe\nACME Corp",
+			expected: "Thanks\n\n> [!quote]- Signature\n> --\n> Jane Doe\n> ACME Corp",
+		},
+		{
+			name:     "Keep content when no quoted text",
+			input:    "Just regular content\n",
+			expected: "Just regular content",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := transformer.CollapseQuotedText(tt.input); result != tt.expected {
+				t.Errorf("Expected:\n'%s'\nGot:\n'%s'", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestContentCleanupTransformer_QuotedTextMode(t *testing.T) {
+	transformer := NewContentCleanupTransformer()
+	if err := transformer.Configure(map[string]interface{}{"quoted_text_mode": "collapse"}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	item := models.NewBasicItem("1", "Re: Lunch")
+	item.SetContent("Sure!\n\n> Lunch?")
+
+	result, err := transformer.Transform([]models.FullItem{item})
+	if err != nil {
+		t.Fatalf("Transform() error = %v", err)
+	}
+
+	if expected := "Sure!\n\n> [!quote]- Previous messages\n> > Lunch?"; result[0].GetContent() != expected {
+		t.Errorf("Transform() content = %q, want %q", result[0].GetContent(), expected)
+	}
+
+	if err := transformer.Configure(map[string]interface{}{"quoted_text_mode": "hide"}); err == nil {
+		t.Error("Configure() expected error for unsupported quoted_text_mode")
+	}
+}
+
+func TestContentCleanupTransformer_QuoteLocales(t *testing.T) {
+	transformer := NewContentCleanupTransformer()
+
+	config := map[string]interface{}{
+		"quote_locales":  []interface{}{"en", "de", "fr"},
+		"quote_patterns": []interface{}{`^_{10,}$`},
+	}
+	if err := transformer.Configure(config); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"German reply", "Passt.\n\nAm 01.02.2024 um 10:00 schrieb Max Muster <max@example.de>:\nMittag?", "Passt."},
+		{"French reply", "D'accord.\n\nLe lun. 1 janv. 2024, Jean Dupont a écrit :\nDéjeuner ?", "D'accord."},
+		{"German forward", "FYI\n\n---------- Weitergeleitete Nachricht ---------\nVon: a@example.de", "FYI"},
+		{"Custom pattern", "Noted.\n\n________________________________\nFrom Outlook", "Noted."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := transformer.StripQuotedText(tt.input); result != tt.expected {
+				t.Errorf("Expected:\n'%s'\nGot:\n'%s'", tt.expected, result)
+			}
+		})
+	}
+
+	// English stays the default
+	german := "Passt.\n\nAm 1. Feb. schrieb Max:\nMittag?"
+	if result := NewContentCleanupTransformer().StripQuotedText(german); result == "Passt." {
+		t.Error("StripQuotedText() should not apply German rules unless configured")
+	}
+
+	if err := transformer.Configure(map[string]interface{}{"quote_locales": []interface{}{"xx"}}); err == nil {
+		t.Error("Configure() expected error for unsupported locale")
+	}
+
+	if err := transformer.Configure(map[string]interface{}{"quote_patterns": []interface{}{"("}}); err == nil {
+		t.Error("Configure() expected error for invalid quote pattern")
+	}
+}
+
+func TestContentCleanupTransformer_SourceSignatureThreshold(t *testing.T) {
+	var config models.TransformConfig
+
+	ApplySignatureThresholds(&config, map[string]int{"short": 2})
+
+	transformer := NewContentCleanupTransformer()
+	if err := transformer.Configure(config.Transformers[transformerNameContentCleanup]); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	content := "Hi\n--\nJane Doe\nACME Corp"
+
+	short := models.NewBasicItem("short", "Hello")
+	short.SetContent(content)
+
+	other := models.NewBasicItem("other", "Hello")
+	other.SetContent(content)
+
+	result, err := transformer.Transform([]models.FullItem{short, other})
+	if err != nil {
+		t.Fatalf("Transform() error = %v", err)
+	}
+
+	if result[0].GetContent() != content {
+		t.Errorf("signature beyond the source threshold should be kept, got %q", result[0].GetContent())
+	}
+
+	if result[1].GetContent() != "Hi" {
+		t.Errorf("signature within the default threshold should be stripped, got %q", result[1].GetContent())
+	}
+}
+
 func TestContentCleanupTransformer_Transform(t *testing.T) {
 	transformer := NewContentCleanupTransformer()
 
@@ -0,0 +1,212 @@
+package transform
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+const (
+	transformerNameEmailClassification = "email_classification"
+
+	CategoryNewsletter   = "newsletter"
+	CategoryNotification = "notification"
+	CategoryPersonal     = "personal"
+)
+
+// defaultNotificationSenders match the addresses automated systems send from.
+var defaultNotificationSenders = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)^(no-?reply|do-?not-?reply|notifications?|notify|alerts?|mailer-daemon|postmaster|` +
+		`bounces?|automated|system)([+._-][^@]*)?@`),
+}
+
+// defaultNewsletterSenders match the addresses newsletters and marketing mail are sent from.
+var defaultNewsletterSenders = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)^(newsletters?|news|digest|marketing|promotions?|offers|deals)([+._-][^@]*)?@`),
+}
+
+// bulkPrecedences are Precedence header values of mail sent to many recipients.
+var bulkPrecedences = []string{"bulk", "list", "junk"}
+
+// EmailClassificationTransformer sorts emails into newsletters, notifications and personal mail using
+// their headers and sender, and stamps the result as category metadata, and optionally a tag, so routing
+// rules and filters can treat them differently. Items without a sender that are not emails are left alone.
+type EmailClassificationTransformer struct {
+	config map[string]interface{}
+
+	personalSenders     []*regexp.Regexp
+	notificationSenders []*regexp.Regexp
+	newsletterSenders   []*regexp.Regexp
+	tagPrefix           string // Prefix of the category tag; empty adds no tag
+}
+
+func NewEmailClassificationTransformer() *EmailClassificationTransformer {
+	return &EmailClassificationTransformer{
+		config:              make(map[string]interface{}),
+		notificationSenders: defaultNotificationSenders,
+		newsletterSenders:   defaultNewsletterSenders,
+	}
+}
+
+func (t *EmailClassificationTransformer) Name() string {
+	return transformerNameEmailClassification
+}
+
+func (t *EmailClassificationTransformer) Configure(config map[string]interface{}) error {
+	personalSenders, err := compilePatterns(config, "personal_senders")
+	if err != nil {
+		return err
+	}
+
+	notificationSenders, err := compilePatterns(config, "notification_senders")
+	if err != nil {
+		return err
+	}
+
+	newsletterSenders, err := compilePatterns(config, "newsletter_senders")
+	if err != nil {
+		return err
+	}
+
+	tagPrefix, _ := config["tag_prefix"].(string)
+
+	t.config = config
+	t.personalSenders = personalSenders
+	t.notificationSenders = append(slices.Clone(defaultNotificationSenders), notificationSenders...)
+	t.newsletterSenders = append(slices.Clone(defaultNewsletterSenders), newsletterSenders...)
+	t.tagPrefix = tagPrefix
+
+	return nil
+}
+
+func (t *EmailClassificationTransformer) Transform(items []models.FullItem) ([]models.FullItem, error) {
+	transformedItems := make([]models.FullItem, len(items))
+
+	for i, item := range items {
+		transformedItems[i] = t.stampItem(item)
+	}
+
+	return transformedItems, nil
+}
+
+// stampItem returns a copy of the item with its category, or the item itself when it is not an email.
+// Thread messages are classified one by one, and a thread is personal when any of its messages is.
+func (t *EmailClassificationTransformer) stampItem(item models.FullItem) models.FullItem {
+	category, classified := t.Classify(item)
+
+	var newItem models.FullItem
+
+	if thread, isThread := models.AsThread(item); isThread {
+		newThread := models.NewThread(thread.GetID(), thread.GetTitle())
+
+		for _, message := range thread.GetMessages() {
+			stamped := t.stampItem(message)
+			newThread.AddMessage(stamped)
+
+			messageCategory, ok := models.Metadata(stamped.GetMetadata()).GetString(models.MetadataCategory)
+			if ok && (!classified || messageCategory == CategoryPersonal) {
+				category, classified = messageCategory, true
+			}
+		}
+
+		newItem = newThread
+	} else {
+		if !classified {
+			return item
+		}
+
+		newItem = models.NewBasicItem(item.GetID(), item.GetTitle())
+	}
+
+	newItem.SetContent(item.GetContent())
+	newItem.SetSourceType(item.GetSourceType())
+	newItem.SetItemType(item.GetItemType())
+	newItem.SetCreatedAt(item.GetCreatedAt())
+	newItem.SetUpdatedAt(item.GetUpdatedAt())
+	newItem.SetTags(item.GetTags())
+	newItem.SetAttachments(item.GetAttachments())
+	newItem.SetMetadata(item.GetMetadata())
+	newItem.SetLinks(item.GetLinks())
+
+	if classified {
+		newItem.SetMetadata(withMetadata(item.GetMetadata(), models.MetadataCategory, category))
+
+		if tag := t.tagPrefix + category; t.tagPrefix != "" && !slices.Contains(item.GetTags(), tag) {
+			newItem.SetTags(append(slices.Clone(item.GetTags()), tag))
+		}
+	}
+
+	return newItem
+}
+
+// Classify returns the category of an email. Senders matching personal_senders are personal; automated
+// senders and Auto-Submitted mail are notifications; mailing list headers, bulk precedence, newsletter
+// senders, Gmail's promotions label or an unsubscribe link make a newsletter; anything else is personal.
+// Items that are neither emails nor have a sender are not classified.
+func (t *EmailClassificationTransformer) Classify(item models.FullItem) (string, bool) {
+	metadata := models.Metadata(item.GetMetadata())
+
+	sender, hasSender := metadata.GetRecipient(models.MetadataFrom)
+	if !hasSender && !strings.HasPrefix(item.GetItemType(), "email") {
+		return "", false
+	}
+
+	address := strings.TrimSpace(sender.Email)
+
+	if matchesAny(t.personalSenders, address) {
+		return CategoryPersonal, true
+	}
+
+	if autoSubmitted, ok := metadata.GetString(models.MetadataAutoSubmitted); ok &&
+		!strings.EqualFold(strings.TrimSpace(autoSubmitted), "no") {
+		return CategoryNotification, true
+	}
+
+	if matchesAny(t.notificationSenders, address) {
+		return CategoryNotification, true
+	}
+
+	if _, ok := metadata.GetString(models.MetadataListUnsubscribe); ok {
+		return CategoryNewsletter, true
+	}
+
+	if _, ok := metadata.GetString(models.MetadataListID); ok {
+		return CategoryNewsletter, true
+	}
+
+	if precedence, ok := metadata.GetString(models.MetadataPrecedence); ok &&
+		slices.Contains(bulkPrecedences, strings.ToLower(strings.TrimSpace(precedence))) {
+		return CategoryNewsletter, true
+	}
+
+	if labels, ok := metadata.GetStrings(models.MetadataLabels); ok && slices.Contains(labels, "CATEGORY_PROMOTIONS") {
+		return CategoryNewsletter, true
+	}
+
+	if matchesAny(t.newsletterSenders, address) ||
+		strings.Contains(strings.ToLower(item.GetContent()), "unsubscribe") {
+		return CategoryNewsletter, true
+	}
+
+	return CategoryPersonal, true
+}
+
+// matchesAny reports whether any of the patterns matches a non-empty address.
+func matchesAny(patterns []*regexp.Regexp, address string) bool {
+	if address == "" {
+		return false
+	}
+
+	for _, pattern := range patterns {
+		if pattern.MatchString(address) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var _ interfaces.Transformer = (*EmailClassificationTransformer)(nil)
@@ -0,0 +1,137 @@
+package transform
+
+import (
+	"slices"
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestEmailClassify(t *testing.T) {
+	transformer := NewEmailClassificationTransformer()
+	if err := transformer.Configure(map[string]interface{}{
+		"personal_senders":     []interface{}{`@family\.example$`},
+		"notification_senders": []interface{}{`^builds@ci\.example$`},
+	}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	email := func(from string, metadata map[string]interface{}, content string) models.FullItem {
+		item := models.NewBasicItem("email", "Subject")
+		item.SetItemType("email")
+		item.SetContent(content)
+
+		if metadata == nil {
+			metadata = make(map[string]interface{})
+		}
+
+		metadata[models.MetadataFrom] = models.Recipient{Email: from}
+		item.SetMetadata(metadata)
+
+		return item
+	}
+
+	tests := []struct {
+		name string
+		item models.FullItem
+		want string
+	}{
+		{"plain mail", email("alice@example.com", nil, "See you tomorrow."), CategoryPersonal},
+		{"noreply sender", email("no-reply@service.example", nil, ""), CategoryNotification},
+		{"notifications sender", email("notifications@github.com",
+			map[string]interface{}{models.MetadataListID: "<repo.github.com>"}, ""), CategoryNotification},
+		{"configured notification sender", email("builds@ci.example", nil, ""), CategoryNotification},
+		{"auto-submitted", email("calendar@example.com",
+			map[string]interface{}{models.MetadataAutoSubmitted: "auto-generated"}, ""), CategoryNotification},
+		{"auto-submitted no", email("bob@example.com",
+			map[string]interface{}{models.MetadataAutoSubmitted: "no"}, ""), CategoryPersonal},
+		{"list-unsubscribe", email("editor@weekly.example",
+			map[string]interface{}{models.MetadataListUnsubscribe: "<mailto:leave@weekly.example>"}, ""),
+			CategoryNewsletter},
+		{"bulk precedence", email("team@shop.example",
+			map[string]interface{}{models.MetadataPrecedence: "Bulk"}, ""), CategoryNewsletter},
+		{"promotions label", email("team@shop.example",
+			map[string]interface{}{models.MetadataLabels: []string{"INBOX", "CATEGORY_PROMOTIONS"}}, ""),
+			CategoryNewsletter},
+		{"newsletter sender", email("newsletter@blog.example", nil, ""), CategoryNewsletter},
+		{"unsubscribe link", email("hello@startup.example", nil, "Click here to unsubscribe."), CategoryNewsletter},
+		{"configured personal sender", email("mom@family.example",
+			map[string]interface{}{models.MetadataListUnsubscribe: "<mailto:x@family.example>"}, ""),
+			CategoryPersonal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := transformer.Classify(tt.item)
+			if !ok || got != tt.want {
+				t.Errorf("Classify() = %q, %v; want %q", got, ok, tt.want)
+			}
+		})
+	}
+
+	if got, ok := transformer.Classify(models.NewBasicItem("doc", "Design doc")); ok {
+		t.Errorf("Classify() of a document = %q, want no category", got)
+	}
+}
+
+func TestEmailClassificationTransformer(t *testing.T) {
+	transformer := NewEmailClassificationTransformer()
+	if err := transformer.Configure(map[string]interface{}{"tag_prefix": "mail/"}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	newsletter := models.NewBasicItem("newsletter", "This week")
+	newsletter.SetItemType("email")
+	newsletter.SetMetadata(map[string]interface{}{
+		models.MetadataFrom:            models.Recipient{Email: "editor@weekly.example"},
+		models.MetadataListUnsubscribe: "<https://weekly.example/leave>",
+	})
+
+	alert := models.NewBasicItem("alert", "Build failed")
+	alert.SetItemType("email")
+	alert.SetMetadata(map[string]interface{}{models.MetadataFrom: "noreply@ci.example"})
+
+	reply := models.NewBasicItem("reply", "Re: Build failed")
+	reply.SetItemType("email")
+	reply.SetMetadata(map[string]interface{}{models.MetadataFrom: "carol@example.com"})
+
+	thread := models.NewThread("thread", "Build failed")
+	thread.AddMessage(alert)
+	thread.AddMessage(reply)
+
+	doc := models.NewBasicItem("doc", "Design doc")
+
+	items, err := transformer.Transform([]models.FullItem{newsletter, thread, doc})
+	if err != nil {
+		t.Fatalf("Transform() error = %v", err)
+	}
+
+	if category := items[0].GetMetadata()[models.MetadataCategory]; category != CategoryNewsletter {
+		t.Errorf("category = %v, want newsletter", category)
+	}
+
+	if !slices.Contains(items[0].GetTags(), "mail/newsletter") {
+		t.Errorf("tags = %v, want mail/newsletter", items[0].GetTags())
+	}
+
+	if newsletter.GetMetadata()[models.MetadataCategory] != nil {
+		t.Error("Transform() changed the original item")
+	}
+
+	stamped, ok := models.AsThread(items[1])
+	if !ok {
+		t.Fatal("thread was not kept a thread")
+	}
+
+	if category := stamped.GetMetadata()[models.MetadataCategory]; category != CategoryPersonal {
+		t.Errorf("thread category = %v, want personal since one message is", category)
+	}
+
+	if category := stamped.GetMessages()[0].GetMetadata()[models.MetadataCategory]; category != CategoryNotification {
+		t.Errorf("message category = %v, want notification", category)
+	}
+
+	if items[2] != doc {
+		t.Error("Transform() changed an item that is not an email")
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"pkm-sync/pkg/interfaces"
 	"pkm-sync/pkg/models"
+	"pkm-sync/pkg/query"
 )
 
 // NOTE: ContentCleanupTransformer is now implemented in content_cleanup.go
@@ -18,9 +19,14 @@ type AutoTaggingTransformer struct {
 	rules  []TaggingRule
 }
 
+// TaggingRule adds tags to items whose title or content contains Pattern, or that match Condition
+// (a query such as `from ~ "@company.com" AND has(attachment)`).
 type TaggingRule struct {
-	Pattern string   `json:"pattern" yaml:"pattern"`
-	Tags    []string `json:"tags"    yaml:"tags"`
+	Pattern   string   `json:"pattern"   yaml:"pattern"`
+	Condition string   `json:"condition" yaml:"condition"`
+	Tags      []string `json:"tags"      yaml:"tags"`
+
+	query *query.Query
 }
 
 func NewAutoTaggingTransformer() *AutoTaggingTransformer {
@@ -69,20 +75,43 @@ func (t *AutoTaggingTransformer) parseTaggingRule(ruleInterface interface{}) *Ta
 	rule := TaggingRule{}
 
 	pattern, hasPattern := ruleMap["pattern"]
-	if !hasPattern {
-		log.Printf("Warning: tagging rule missing required 'pattern' field")
+	condition, hasCondition := ruleMap["condition"]
+
+	if !hasPattern && !hasCondition {
+		log.Printf("Warning: tagging rule missing required 'pattern' or 'condition' field")
 
 		return nil
 	}
 
-	patternStr, ok := pattern.(string)
-	if !ok {
-		log.Printf("Warning: tagging rule 'pattern' must be a string, got %T", pattern)
+	if hasPattern {
+		patternStr, ok := pattern.(string)
+		if !ok {
+			log.Printf("Warning: tagging rule 'pattern' must be a string, got %T", pattern)
 
-		return nil
+			return nil
+		}
+
+		rule.Pattern = patternStr
 	}
 
-	rule.Pattern = patternStr
+	if hasCondition {
+		conditionStr, ok := condition.(string)
+		if !ok {
+			log.Printf("Warning: tagging rule 'condition' must be a string, got %T", condition)
+
+			return nil
+		}
+
+		q, err := query.Parse(conditionStr)
+		if err != nil {
+			log.Printf("Warning: tagging rule 'condition': %v", err)
+
+			return nil
+		}
+
+		rule.Condition = conditionStr
+		rule.query = q
+	}
 
 	if tagsInterface, exists := ruleMap["tags"]; exists {
 		tagsSlice, ok := tagsInterface.([]interface{})
@@ -182,9 +211,16 @@ func (t *AutoTaggingTransformer) applyTaggingRulesInterface(item models.FullItem
 	searchText := strings.ToLower(item.GetTitle() + " " + item.GetContent())
 
 	for _, rule := range t.rules {
-		if strings.Contains(searchText, strings.ToLower(rule.Pattern)) {
-			newTags = append(newTags, rule.Tags...)
+		// A rule with both a pattern and a condition needs both to match
+		if rule.Pattern != "" && !strings.Contains(searchText, strings.ToLower(rule.Pattern)) {
+			continue
+		}
+
+		if rule.query != nil && !rule.query.MatchItem(item) {
+			continue
 		}
+
+		newTags = append(newTags, rule.Tags...)
 	}
 
 	// Add source-based tags
@@ -203,7 +239,9 @@ func (t *AutoTaggingTransformer) applyTaggingRulesInterface(item models.FullItem
 
 // FilterTransformer filters items based on criteria.
 type FilterTransformer struct {
-	config map[string]interface{}
+	config  map[string]interface{}
+	where   *query.Query // Keep only items matching this query
+	exclude *query.Query // Drop items matching this query
 }
 
 func NewFilterTransformer() *FilterTransformer {
@@ -219,9 +257,39 @@ func (t *FilterTransformer) Name() string {
 func (t *FilterTransformer) Configure(config map[string]interface{}) error {
 	t.config = config
 
+	var err error
+
+	if t.where, err = parseQueryOption(config, "where"); err != nil {
+		return err
+	}
+
+	if t.exclude, err = parseQueryOption(config, "exclude"); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// parseQueryOption parses an optional query-valued configuration key.
+func parseQueryOption(config map[string]interface{}, key string) (*query.Query, error) {
+	val, exists := config[key]
+	if !exists {
+		return nil, nil
+	}
+
+	expr, ok := val.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid type for %s: expected string, got %T", key, val)
+	}
+
+	q, err := query.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	return q, nil
+}
+
 func (t *FilterTransformer) Transform(items []models.FullItem) ([]models.FullItem, error) {
 	var filteredItems []models.FullItem
 
@@ -241,6 +309,14 @@ func (t *FilterTransformer) Transform(items []models.FullItem) ([]models.FullIte
 	}
 
 	for _, item := range items {
+		if t.where != nil && !t.where.MatchItem(item) {
+			continue
+		}
+
+		if t.exclude != nil && t.exclude.MatchItem(item) {
+			continue
+		}
+
 		// Convert to struct for compatibility with existing filter logic
 		legacyItem := models.AsItemStruct(item)
 		if t.shouldIncludeItem(legacyItem, minContentLength, excludeSourceTypes, requiredTags) {
@@ -361,11 +437,14 @@ func GetAllExampleTransformers() []interfaces.Transformer {
 // These include the enhanced transformers extracted from Gmail processing logic.
 func GetAllContentProcessingTransformers() []interfaces.Transformer {
 	return []interfaces.Transformer{
-		NewContentCleanupTransformer(),   // Enhanced version with HTML processing from content_cleanup.go
-		NewLinkExtractionTransformer(),   // URL extraction from link_extraction.go
-		NewSignatureRemovalTransformer(), // Signature detection from signature_removal.go
-		NewThreadGroupingTransformer(),   // Thread consolidation from thread_grouping.go
-		NewAutoTaggingTransformer(),      // Existing example transformer
-		NewFilterTransformer(),           // Existing example transformer
+		NewContentCleanupTransformer(),      // Enhanced version with HTML processing from content_cleanup.go
+		NewLinkExtractionTransformer(),      // URL extraction from link_extraction.go
+		NewSignatureRemovalTransformer(),    // Signature detection from signature_removal.go
+		NewThreadGroupingTransformer(),      // Thread consolidation from thread_grouping.go
+		NewPrivacyCleanupTransformer(),      // Tracking pixel and parameter removal from privacy_cleanup.go
+		NewLanguageDetectionTransformer(),   // Language metadata from language_detection.go
+		NewEmailClassificationTransformer(), // Newsletter/notification/personal categories from email_classification.go
+		NewAutoTaggingTransformer(),         // Existing example transformer
+		NewFilterTransformer(),              // Existing example transformer
 	}
 }
@@ -260,6 +260,88 @@ func TestFilterTransformerInvalidConfig(t *testing.T) {
 	}
 }
 
+func TestFilterTransformerQueries(t *testing.T) {
+	transformer := NewFilterTransformer()
+
+	err := transformer.Configure(map[string]interface{}{
+		"where":   `source = gmail OR source = google_calendar`,
+		"exclude": `tag = newsletter`,
+	})
+	if err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+
+	newItem := func(id, sourceType string, tags ...string) models.ItemInterface {
+		item := models.NewBasicItem(id, "Item "+id)
+		item.SetSourceType(sourceType)
+		item.SetTags(tags)
+
+		return item
+	}
+
+	result, err := transformer.Transform([]models.ItemInterface{
+		newItem("1", "gmail"),
+		newItem("2", "gmail", "newsletter"),
+		newItem("3", "slack"),
+		newItem("4", "google_calendar"),
+	})
+	if err != nil {
+		t.Fatalf("Transform failed: %v", err)
+	}
+
+	if len(result) != 2 || result[0].GetID() != "1" || result[1].GetID() != "4" {
+		t.Errorf("Expected items 1 and 4, got %d items", len(result))
+	}
+
+	if err := NewFilterTransformer().Configure(map[string]interface{}{"where": "tag = ("}); err == nil {
+		t.Error("Expected an error for an invalid where query")
+	}
+}
+
+func TestAutoTaggingTransformerConditions(t *testing.T) {
+	transformer := NewAutoTaggingTransformer()
+
+	err := transformer.Configure(map[string]interface{}{
+		"rules": []interface{}{
+			map[string]interface{}{
+				"condition": `from ~ "@company.com" AND has(attachment)`,
+				"tags":      []interface{}{"work-files"},
+			},
+			map[string]interface{}{
+				"pattern":   "invoice",
+				"condition": `source = slack`,
+				"tags":      []interface{}{"slack-invoice"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+
+	item := models.NewBasicItem("1", "Invoice")
+	item.SetSourceType("gmail")
+	item.SetAttachments([]models.Attachment{{Name: "invoice.pdf"}})
+	item.SetMetadata(map[string]interface{}{"from": "alice@company.com"})
+
+	result, err := transformer.Transform([]models.ItemInterface{item})
+	if err != nil {
+		t.Fatalf("Transform failed: %v", err)
+	}
+
+	tagMap := make(map[string]bool)
+	for _, tag := range result[0].GetTags() {
+		tagMap[tag] = true
+	}
+
+	if !tagMap["work-files"] {
+		t.Error("Missing tag from matching condition: work-files")
+	}
+
+	if tagMap["slack-invoice"] {
+		t.Error("Rule with pattern and condition should need both to match")
+	}
+}
+
 func TestGetAllExampleTransformers(t *testing.T) {
 	transformers := GetAllExampleTransformers()
 	if len(transformers) != 2 {
@@ -269,8 +351,8 @@ func TestGetAllExampleTransformers(t *testing.T) {
 
 func TestGetAllContentProcessingTransformers(t *testing.T) {
 	transformers := GetAllContentProcessingTransformers()
-	if len(transformers) != 6 {
-		t.Errorf("Expected 6 content processing transformers, got %d", len(transformers))
+	if len(transformers) != 9 {
+		t.Errorf("Expected 9 content processing transformers, got %d", len(transformers))
 	}
 }
 
@@ -28,8 +28,8 @@ func (m *MockSource) Fetch(since time.Time, limit int) ([]models.ItemInterface,
 	return m.items, nil
 }
 
-func (m *MockSource) SupportsRealtime() bool {
-	return false
+func (m *MockSource) Capabilities() interfaces.SourceCapabilities {
+	return interfaces.SourceCapabilities{}
 }
 
 // MockTarget implements interfaces.Target for testing pipeline integration.
@@ -63,8 +63,8 @@ func (m *MockTarget) FormatMetadata(metadata map[string]interface{}) string {
 	return ""
 }
 
-func (m *MockTarget) Preview(items []models.ItemInterface, outputDir string) ([]*interfaces.FilePreview, error) {
-	return nil, nil
+func (m *MockTarget) Plan(items []models.ItemInterface, outputDir string) (*interfaces.Plan, error) {
+	return &interfaces.Plan{OutputDir: outputDir}, nil
 }
 
 // TestPipelineIntegrationWithSyncEngine tests the complete flow from source -> pipeline -> target.
@@ -0,0 +1,264 @@
+package transform
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+const (
+	transformerNameLanguageDetection = "language_detection"
+
+	// defaultLanguageMinWords is the fewest words a text needs for its language to be detected
+	defaultLanguageMinWords = 5
+
+	// scriptShare is the share of letters a script needs for a text to be written in it
+	scriptShare = 0.3
+)
+
+// languageStopwords are frequent words of the languages told apart by their vocabulary. Words shared by
+// several of them, such as "de" or "a", are left out.
+var languageStopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "was", "were", "of", "to", "in", "that", "it", "for", "you", "with",
+		"this", "have", "not", "be", "on", "at", "we", "they", "will", "would", "from", "or", "but", "what"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ich", "sie", "es", "mit", "den", "dem", "ein", "eine",
+		"zu", "auf", "für", "sich", "wir", "auch", "wird", "oder", "aber", "bei", "noch", "nach", "sind"},
+	"fr": {"le", "la", "les", "et", "est", "une", "des", "du", "pour", "que", "qui", "dans", "pas", "sur",
+		"nous", "vous", "avec", "ce", "sont", "mais", "ou", "être", "au", "aux", "cette", "il", "elle"},
+	"es": {"el", "los", "las", "y", "es", "una", "del", "que", "por", "para", "con", "como", "pero", "está",
+		"su", "al", "lo", "se", "más", "muy", "también", "son", "hay", "fue", "nosotros", "usted", "este"},
+	"it": {"il", "di", "che", "è", "gli", "della", "per", "non", "sono", "una", "con", "del", "anche", "ma",
+		"questo", "nel", "alla", "come", "più", "ci", "noi", "voi", "essere", "lo", "sul", "degli", "molto"},
+	"pt": {"o", "os", "da", "do", "das", "dos", "não", "uma", "com", "para", "em", "que", "é", "mas", "você",
+		"nós", "são", "está", "como", "mais", "também", "ao", "pelo", "pela", "foi", "isso", "muito"},
+	"nl": {"het", "een", "en", "van", "is", "niet", "dat", "die", "ik", "je", "zijn", "op", "met", "voor",
+		"wij", "ook", "maar", "aan", "bij", "wordt", "nog", "naar", "heeft", "deze", "dit", "geen", "u"},
+	"sv": {"och", "att", "är", "som", "för", "på", "inte", "med", "har", "jag", "vi", "ett", "till", "av",
+		"men", "om", "det", "den", "var", "kan", "också", "eller", "ska", "från", "vid", "efter", "så"},
+}
+
+// languageScripts are languages recognized by the script they are written in.
+var languageScripts = []struct {
+	lang   string
+	script *unicode.RangeTable
+}{
+	{"ja", unicode.Hiragana},
+	{"ja", unicode.Katakana},
+	{"ko", unicode.Hangul},
+	{"zh", unicode.Han},
+	{"ru", unicode.Cyrillic},
+	{"el", unicode.Greek},
+	{"ar", unicode.Arabic},
+	{"he", unicode.Hebrew},
+	{"th", unicode.Thai},
+	{"hi", unicode.Devanagari},
+}
+
+// LanguageDetectionTransformer stamps items with the language of their title and content as lang
+// metadata, and optionally a tag, so they can be routed and filtered by language. Detection is
+// lightweight: languages in Latin script are told apart by their most frequent words, others by script.
+type LanguageDetectionTransformer struct {
+	config map[string]interface{}
+
+	languages []string // Languages to choose from; empty allows every supported language
+	minWords  int
+	tagPrefix string // Prefix of the language tag; empty adds no tag
+}
+
+func NewLanguageDetectionTransformer() *LanguageDetectionTransformer {
+	return &LanguageDetectionTransformer{
+		config:   make(map[string]interface{}),
+		minWords: defaultLanguageMinWords,
+	}
+}
+
+func (t *LanguageDetectionTransformer) Name() string {
+	return transformerNameLanguageDetection
+}
+
+func (t *LanguageDetectionTransformer) Configure(config map[string]interface{}) error {
+	languages := stringList(config["languages"])
+	for _, lang := range languages {
+		if !isSupportedLanguage(lang) {
+			return fmt.Errorf("unsupported language '%s': supported languages are %s", lang,
+				strings.Join(SupportedLanguages(), ", "))
+		}
+	}
+
+	minWords := defaultLanguageMinWords
+
+	switch v := config["min_words"].(type) {
+	case int:
+		minWords = v
+	case float64:
+		minWords = int(v)
+	}
+
+	tagPrefix, _ := config["tag_prefix"].(string)
+
+	t.config = config
+	t.languages = languages
+	t.minWords = minWords
+	t.tagPrefix = tagPrefix
+
+	return nil
+}
+
+func (t *LanguageDetectionTransformer) Transform(items []models.FullItem) ([]models.FullItem, error) {
+	transformedItems := make([]models.FullItem, len(items))
+
+	for i, item := range items {
+		transformedItems[i] = t.stampItem(item)
+	}
+
+	return transformedItems, nil
+}
+
+// stampItem returns a copy of the item with its detected language, or the item itself when none was
+// detected. Thread messages are stamped one by one.
+func (t *LanguageDetectionTransformer) stampItem(item models.FullItem) models.FullItem {
+	lang, detected := t.DetectLanguage(item.GetTitle() + "\n" + item.GetContent())
+
+	thread, isThread := models.AsThread(item)
+	if !detected && !isThread {
+		return item
+	}
+
+	var newItem models.FullItem
+
+	if isThread {
+		newThread := models.NewThread(thread.GetID(), thread.GetTitle())
+		for _, message := range thread.GetMessages() {
+			newThread.AddMessage(t.stampItem(message))
+		}
+
+		newItem = newThread
+	} else {
+		newItem = models.NewBasicItem(item.GetID(), item.GetTitle())
+	}
+
+	newItem.SetContent(item.GetContent())
+	newItem.SetSourceType(item.GetSourceType())
+	newItem.SetItemType(item.GetItemType())
+	newItem.SetCreatedAt(item.GetCreatedAt())
+	newItem.SetUpdatedAt(item.GetUpdatedAt())
+	newItem.SetTags(item.GetTags())
+	newItem.SetAttachments(item.GetAttachments())
+	newItem.SetMetadata(item.GetMetadata())
+	newItem.SetLinks(item.GetLinks())
+
+	if detected {
+		newItem.SetMetadata(withMetadata(item.GetMetadata(), models.MetadataLang, lang))
+
+		if tag := t.tagPrefix + lang; t.tagPrefix != "" && !slices.Contains(item.GetTags(), tag) {
+			newItem.SetTags(append(slices.Clone(item.GetTags()), tag))
+		}
+	}
+
+	return newItem
+}
+
+// DetectLanguage returns the ISO 639-1 code of the language a text is written in. Texts shorter than
+// min_words, or without a clear winner among the allowed languages, are not detected.
+func (t *LanguageDetectionTransformer) DetectLanguage(text string) (string, bool) {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+
+	letters := 0
+	scripts := make(map[string]int)
+
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+
+		letters++
+
+		for _, candidate := range languageScripts {
+			if unicode.Is(candidate.script, r) {
+				scripts[candidate.lang]++
+
+				break
+			}
+		}
+	}
+
+	// Japanese mixes kana with Han characters, so any share of kana makes a Han text Japanese
+	if scripts["ja"] > 0 && scripts["zh"] > 0 {
+		scripts["ja"] += scripts["zh"]
+		delete(scripts, "zh")
+	}
+
+	for _, candidate := range languageScripts {
+		if letters > 0 && float64(scripts[candidate.lang]) >= scriptShare*float64(letters) && t.allows(candidate.lang) {
+			return candidate.lang, true
+		}
+	}
+
+	if len(words) < t.minWords {
+		return "", false
+	}
+
+	best, bestHits, runnerUpHits := "", 0, 0
+
+	for _, lang := range SupportedLanguages() {
+		stopwords, ok := languageStopwords[lang]
+		if !ok || !t.allows(lang) {
+			continue
+		}
+
+		hits := 0
+
+		for _, word := range words {
+			if slices.Contains(stopwords, word) {
+				hits++
+			}
+		}
+
+		if hits > bestHits {
+			best, bestHits, runnerUpHits = lang, hits, bestHits
+		} else if hits > runnerUpHits {
+			runnerUpHits = hits
+		}
+	}
+
+	if bestHits < 2 || bestHits == runnerUpHits {
+		return "", false
+	}
+
+	return best, true
+}
+
+// allows reports whether a language is one the transformer may choose.
+func (t *LanguageDetectionTransformer) allows(lang string) bool {
+	return len(t.languages) == 0 || slices.Contains(t.languages, lang)
+}
+
+// SupportedLanguages returns the codes of the languages the language_detection transformer detects.
+func SupportedLanguages() []string {
+	languages := make([]string, 0, len(languageStopwords)+len(languageScripts))
+	for lang := range languageStopwords {
+		languages = append(languages, lang)
+	}
+
+	for _, candidate := range languageScripts {
+		if !slices.Contains(languages, candidate.lang) {
+			languages = append(languages, candidate.lang)
+		}
+	}
+
+	slices.Sort(languages)
+
+	return languages
+}
+
+func isSupportedLanguage(lang string) bool {
+	return slices.Contains(SupportedLanguages(), lang)
+}
+
+var _ interfaces.Transformer = (*LanguageDetectionTransformer)(nil)
@@ -0,0 +1,88 @@
+package transform
+
+import (
+	"slices"
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	transformer := NewLanguageDetectionTransformer()
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Thanks for the update, we will review the draft and get back to you on Monday.", "en"},
+		{"Vielen Dank für die Nachricht, wir melden uns nach dem Termin und schicken die Unterlagen.", "de"},
+		{"Merci pour votre message, nous allons vous envoyer les documents dans la semaine.", "fr"},
+		{"Gracias por el mensaje, te enviamos los documentos para la reunión con el equipo.", "es"},
+		{"Hartelijk dank voor het bericht, wij sturen de documenten naar u voor de vergadering.", "nl"},
+		{"Спасибо за письмо, мы отправим документы на следующей неделе.", "ru"},
+		{"ありがとうございます。資料は来週お送りします。", "ja"},
+		{"感谢您的来信，我们下周发送文件。", "zh"},
+	}
+
+	for _, tt := range tests {
+		got, ok := transformer.DetectLanguage(tt.text)
+		if !ok || got != tt.want {
+			t.Errorf("DetectLanguage(%q) = %q, %v; want %q", tt.text, got, ok, tt.want)
+		}
+	}
+
+	for _, text := range []string{"", "Lunch?", "1234 5678 9012 3456 7890", "Quarterly OKR sync notes"} {
+		if got, ok := transformer.DetectLanguage(text); ok {
+			t.Errorf("DetectLanguage(%q) = %q, want no language", text, got)
+		}
+	}
+}
+
+func TestLanguageDetectionTransformer(t *testing.T) {
+	transformer := NewLanguageDetectionTransformer()
+	if err := transformer.Configure(map[string]interface{}{
+		"languages":  []interface{}{"en", "de"},
+		"tag_prefix": "lang/",
+	}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	email := models.NewBasicItem("email", "Termin")
+	email.SetContent("Wir sehen uns nach dem Termin, die Unterlagen sind auch schon bei mir.")
+
+	thread := models.NewThread("thread", "Planning")
+	thread.AddMessage(models.NewBasicItem("msg", "Planning"))
+	thread.GetMessages()[0].SetContent("We will meet at the office, and the agenda is in the doc.")
+
+	short := models.NewBasicItem("short", "Hi")
+
+	items, err := transformer.Transform([]models.FullItem{email, thread, short})
+	if err != nil {
+		t.Fatalf("Transform() error = %v", err)
+	}
+
+	if lang := items[0].GetMetadata()[models.MetadataLang]; lang != "de" {
+		t.Errorf("lang = %v, want de", lang)
+	}
+
+	if !slices.Contains(items[0].GetTags(), "lang/de") {
+		t.Errorf("tags = %v, want lang/de", items[0].GetTags())
+	}
+
+	if email.GetMetadata()[models.MetadataLang] != nil {
+		t.Error("Transform() changed the original item")
+	}
+
+	stamped, ok := models.AsThread(items[1])
+	if !ok || stamped.GetMessages()[0].GetMetadata()[models.MetadataLang] != "en" {
+		t.Error("thread messages were not stamped with their language")
+	}
+
+	if items[2] != short {
+		t.Error("an item without a detected language was copied")
+	}
+
+	if err := transformer.Configure(map[string]interface{}{"languages": []interface{}{"xx"}}); err == nil {
+		t.Error("Configure() accepted an unsupported language")
+	}
+}
@@ -0,0 +1,333 @@
+package transform
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"pkm-sync/pkg/interfaces"
+	"pkm-sync/pkg/models"
+)
+
+const (
+	transformerNamePrivacyCleanup = "privacy_cleanup"
+
+	// urlTrailingSymbols are left after URLs in prose and not part of them
+	urlTrailingSymbols = ".,;:!?"
+)
+
+// defaultTrackingParams are the query parameters newsletters and ad platforms add to links to track clicks.
+var defaultTrackingParams = []string{
+	"utm_*", "mc_cid", "mc_eid", "fbclid", "gclid", "dclid", "msclkid", "yclid", "igshid",
+	"_hsenc", "_hsmi", "mkt_tok", "vero_id", "vero_conv", "oly_anon_id", "oly_enc_id", "trk", "trkCampaign",
+}
+
+// defaultTrackingPixelURLs match the open-tracking images of common mailing services.
+var defaultTrackingPixelURLs = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)/(track|tracking)/open`),
+	regexp.MustCompile(`(?i)/open\.(aspx|php|gif|png)\b`),
+	regexp.MustCompile(`(?i)/wf/open\b`),
+	regexp.MustCompile(`(?i)list-manage\.com/track/`),
+	regexp.MustCompile(`(?i)\b(mailtrack\.io|pixel\.mailchimp|t\.sidekickopen\d*\.com|mandrillapp\.com/track)`),
+	regexp.MustCompile(`(?i)/(pixel|beacon)(\.gif|\.png|/|\?|$)`),
+}
+
+var (
+	htmlImageTag     = regexp.MustCompile(`(?is)<img\b[^>]*>`)
+	htmlAttribute    = regexp.MustCompile(`(?is)([\w-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	markdownImage    = regexp.MustCompile(`!\[(?:\\.|[^\]\\])*\]\(([^)\s]+)(?:\s+"[^"]*")?\)`)
+	contentURL       = regexp.MustCompile(`https?://[^\s<>"'()\[\]\\]+`)
+	hiddenImageStyle = regexp.MustCompile(`(?i)display\s*:\s*none|visibility\s*:\s*hidden`)
+	pixelSizeStyle   = regexp.MustCompile(`(?i)\b(width|height)\s*:\s*([01])(px)?\b`)
+)
+
+// PrivacyCleanupTransformer removes tracking pixels from email content and strips click-tracking query
+// parameters from links. Place it before content_cleanup so pixels are recognized by their HTML size.
+type PrivacyCleanupTransformer struct {
+	config map[string]interface{}
+
+	trackingParams    []string         // Query parameter names, "*" globs allowed
+	trackingPixelURLs []*regexp.Regexp // Image URLs that are tracking pixels whatever their size
+}
+
+func NewPrivacyCleanupTransformer() *PrivacyCleanupTransformer {
+	return &PrivacyCleanupTransformer{
+		config:            make(map[string]interface{}),
+		trackingParams:    defaultTrackingParams,
+		trackingPixelURLs: defaultTrackingPixelURLs,
+	}
+}
+
+func (t *PrivacyCleanupTransformer) Name() string {
+	return transformerNamePrivacyCleanup
+}
+
+func (t *PrivacyCleanupTransformer) Configure(config map[string]interface{}) error {
+	params := append([]string{}, defaultTrackingParams...)
+
+	for _, param := range stringList(config["tracking_params"]) {
+		if _, err := path.Match(param, ""); err != nil {
+			return fmt.Errorf("invalid tracking_params pattern '%s': %w", param, err)
+		}
+
+		params = append(params, param)
+	}
+
+	pixelURLs, err := compilePatterns(config, "tracking_pixel_patterns")
+	if err != nil {
+		return err
+	}
+
+	t.config = config
+	t.trackingParams = params
+	t.trackingPixelURLs = append(append([]*regexp.Regexp{}, defaultTrackingPixelURLs...), pixelURLs...)
+
+	return nil
+}
+
+func (t *PrivacyCleanupTransformer) Transform(items []models.FullItem) ([]models.FullItem, error) {
+	transformedItems := make([]models.FullItem, len(items))
+
+	for i, item := range items {
+		content := t.CleanContent(item.GetContent())
+		links := t.cleanLinks(item.GetLinks())
+
+		thread, isThread := models.AsThread(item)
+
+		var messages []models.FullItem
+
+		messagesChanged := false
+
+		if isThread {
+			var err error
+
+			messages, err = t.Transform(thread.GetMessages())
+			if err != nil {
+				return nil, err
+			}
+
+			for j, message := range messages {
+				messagesChanged = messagesChanged || message != thread.GetMessages()[j]
+			}
+		}
+
+		if content == item.GetContent() && links == nil && !messagesChanged {
+			transformedItems[i] = item
+
+			continue
+		}
+
+		if links == nil {
+			links = item.GetLinks()
+		}
+
+		var newItem models.FullItem
+
+		if isThread {
+			newThread := models.NewThread(thread.GetID(), thread.GetTitle())
+			for _, message := range messages {
+				newThread.AddMessage(message)
+			}
+
+			newItem = newThread
+		} else {
+			newItem = models.NewBasicItem(item.GetID(), item.GetTitle())
+		}
+
+		newItem.SetContent(content)
+		newItem.SetSourceType(item.GetSourceType())
+		newItem.SetItemType(item.GetItemType())
+		newItem.SetCreatedAt(item.GetCreatedAt())
+		newItem.SetUpdatedAt(item.GetUpdatedAt())
+		newItem.SetTags(item.GetTags())
+		newItem.SetAttachments(item.GetAttachments())
+		newItem.SetMetadata(item.GetMetadata())
+		newItem.SetLinks(links)
+
+		transformedItems[i] = newItem
+	}
+
+	return transformedItems, nil
+}
+
+// CleanContent removes tracking pixels and strips tracking parameters from the URLs in content.
+func (t *PrivacyCleanupTransformer) CleanContent(content string) string {
+	if t.shouldStripTrackingPixels() {
+		content = htmlImageTag.ReplaceAllStringFunc(content, func(tag string) string {
+			if t.isTrackingPixelTag(tag) {
+				return ""
+			}
+
+			return tag
+		})
+
+		content = markdownImage.ReplaceAllStringFunc(content, func(image string) string {
+			if t.isTrackingPixelURL(markdownImage.FindStringSubmatch(image)[1]) {
+				return ""
+			}
+
+			return image
+		})
+	}
+
+	if t.shouldStripTrackingParams() {
+		content = contentURL.ReplaceAllStringFunc(content, func(rawURL string) string {
+			trimmed := strings.TrimRight(rawURL, urlTrailingSymbols)
+
+			return t.StripTrackingParams(trimmed) + rawURL[len(trimmed):]
+		})
+	}
+
+	return content
+}
+
+// cleanLinks strips tracking parameters from extracted links; nil means nothing changed.
+func (t *PrivacyCleanupTransformer) cleanLinks(links []models.Link) []models.Link {
+	if !t.shouldStripTrackingParams() {
+		return nil
+	}
+
+	var cleaned []models.Link
+
+	for i, link := range links {
+		stripped := t.StripTrackingParams(link.URL)
+		if stripped == link.URL {
+			continue
+		}
+
+		if cleaned == nil {
+			cleaned = append([]models.Link{}, links...)
+		}
+
+		cleaned[i].URL = stripped
+	}
+
+	return cleaned
+}
+
+// StripTrackingParams removes tracking query parameters from a URL, keeping every other part as written.
+// Query strings from HTML attributes that separate parameters with "&amp;" are handled too.
+func (t *PrivacyCleanupTransformer) StripTrackingParams(rawURL string) string {
+	start := strings.Index(rawURL, "?")
+	if start < 0 {
+		return rawURL
+	}
+
+	query, fragment := rawURL[start+1:], ""
+	if end := strings.Index(query, "#"); end >= 0 {
+		query, fragment = query[:end], query[end:]
+	}
+
+	separator := "&"
+	if strings.Contains(query, "&amp;") {
+		separator = "&amp;"
+	}
+
+	var kept []string
+
+	removed := false
+
+	for _, param := range strings.Split(query, separator) {
+		name, _, _ := strings.Cut(param, "=")
+		if t.isTrackingParam(name) {
+			removed = true
+		} else if param != "" {
+			kept = append(kept, param)
+		}
+	}
+
+	if !removed {
+		return rawURL
+	}
+
+	result := rawURL[:start]
+	if len(kept) > 0 {
+		result += "?" + strings.Join(kept, separator)
+	}
+
+	return result + fragment
+}
+
+func (t *PrivacyCleanupTransformer) isTrackingParam(name string) bool {
+	for _, pattern := range t.trackingParams {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isTrackingPixelTag reports whether an <img> tag is a tracking pixel: at most 1x1, hidden, or loaded from
+// a known tracking URL.
+func (t *PrivacyCleanupTransformer) isTrackingPixelTag(tag string) bool {
+	attributes := make(map[string]string)
+
+	for _, match := range htmlAttribute.FindAllStringSubmatch(tag, -1) {
+		attributes[strings.ToLower(match[1])] = match[2] + match[3] + match[4]
+	}
+
+	if t.isTrackingPixelURL(attributes["src"]) {
+		return true
+	}
+
+	style := attributes["style"]
+	if hiddenImageStyle.MatchString(style) {
+		return true
+	}
+
+	width, height := attributes["width"], attributes["height"]
+
+	for _, match := range pixelSizeStyle.FindAllStringSubmatch(style, -1) {
+		if strings.EqualFold(match[1], "width") {
+			width = match[2]
+		} else {
+			height = match[2]
+		}
+	}
+
+	return isPixelDimension(width) && isPixelDimension(height)
+}
+
+func isPixelDimension(value string) bool {
+	size, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(value), "px"))
+
+	return err == nil && size <= 1
+}
+
+func (t *PrivacyCleanupTransformer) isTrackingPixelURL(imageURL string) bool {
+	if imageURL == "" {
+		return false
+	}
+
+	for _, pattern := range t.trackingPixelURLs {
+		if pattern.MatchString(imageURL) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Configuration helper methods
+
+func (t *PrivacyCleanupTransformer) shouldStripTrackingPixels() bool {
+	if b, ok := t.config["strip_tracking_pixels"].(bool); ok {
+		return b
+	}
+
+	return true // Default: enabled
+}
+
+func (t *PrivacyCleanupTransformer) shouldStripTrackingParams() bool {
+	if b, ok := t.config["strip_tracking_params"].(bool); ok {
+		return b
+	}
+
+	return true // Default: enabled
+}
+
+// Ensure interface compliance.
+var _ interfaces.Transformer = (*PrivacyCleanupTransformer)(nil)
@@ -0,0 +1,139 @@
+package transform
+
+import (
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestPrivacyCleanupTransformer_StripTrackingParams(t *testing.T) {
+	transformer := NewPrivacyCleanupTransformer()
+	if err := transformer.Configure(map[string]interface{}{"tracking_params": []interface{}{"ref_*"}}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"https://example.com/post?utm_source=news&utm_medium=email", "https://example.com/post"},
+		{"https://example.com/post?id=5&utm_campaign=x&mc_eid=abc#top", "https://example.com/post?id=5#top"},
+		{"https://example.com/p?fbclid=1&amp;page=2&amp;ref_src=tw", "https://example.com/p?page=2"},
+		{"https://example.com/search?q=go&&sort=new", "https://example.com/search?q=go&&sort=new"},
+		{"https://example.com/plain", "https://example.com/plain"},
+	}
+
+	for _, tt := range tests {
+		if got := transformer.StripTrackingParams(tt.input); got != tt.expected {
+			t.Errorf("StripTrackingParams(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+
+	if err := transformer.Configure(map[string]interface{}{"tracking_params": []interface{}{"["}}); err == nil {
+		t.Error("Configure() expected error for invalid tracking_params pattern")
+	}
+}
+
+func TestPrivacyCleanupTransformer_CleanContent(t *testing.T) {
+	transformer := NewPrivacyCleanupTransformer()
+	if err := transformer.Configure(map[string]interface{}{
+		"tracking_pixel_patterns": []interface{}{`newsletter\.example\.com/o/`},
+	}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "1x1 image",
+			input:    `<p>Hello</p><img src="https://t.example.com/x.gif" width="1" height="1" alt="">`,
+			expected: `<p>Hello</p>`,
+		},
+		{
+			name:     "Hidden image",
+			input:    `<img src="https://t.example.com/x.gif" style="display:none">Hi`,
+			expected: `Hi`,
+		},
+		{
+			name:     "Pixel sized by style",
+			input:    `<img src='https://t.example.com/x.gif' style="width:1px;height:0">Hi`,
+			expected: `Hi`,
+		},
+		{
+			name:     "Regular image kept",
+			input:    `<img src="https://example.com/chart.png" width="600" height="1">`,
+			expected: `<img src="https://example.com/chart.png" width="600" height="1">`,
+		},
+		{
+			name:     "Known tracking URL in markdown",
+			input:    "Hi ![](https://mandrillapp.com/track/open.php?u=1) ![](https://newsletter.example.com/o/42)",
+			expected: "Hi  ",
+		},
+		{
+			name:     "Tracking parameters in links",
+			input:    `Read [the post](https://example.com/post?utm_source=news). Or https://example.com/a?gclid=9.`,
+			expected: `Read [the post](https://example.com/post). Or https://example.com/a.`,
+		},
+		{
+			name:     "HTML link attributes",
+			input:    `<a href="https://example.com/x?a=1&amp;utm_medium=email">x</a>`,
+			expected: `<a href="https://example.com/x?a=1">x</a>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transformer.CleanContent(tt.input); got != tt.expected {
+				t.Errorf("CleanContent() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPrivacyCleanupTransformer_Transform(t *testing.T) {
+	transformer := NewPrivacyCleanupTransformer()
+	if err := transformer.Configure(map[string]interface{}{"strip_tracking_pixels": false}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	message := models.NewBasicItem("m1", "Newsletter")
+	message.SetContent(`<img src="https://t.example.com/x.gif" width="1" height="1">https://example.com/?utm_id=1`)
+	message.SetLinks([]models.Link{{URL: "https://example.com/?utm_id=1&id=2", Title: "Post"}})
+
+	thread := models.NewThread("t1", "Newsletter")
+	thread.AddMessage(message)
+
+	untouched := models.NewBasicItem("2", "Plain")
+	untouched.SetContent("Nothing to clean")
+
+	result, err := transformer.Transform([]models.FullItem{thread, untouched})
+	if err != nil {
+		t.Fatalf("Transform() error = %v", err)
+	}
+
+	cleanedThread, ok := models.AsThread(result[0])
+	if !ok {
+		t.Fatalf("Transform() should keep threads as threads, got %T", result[0])
+	}
+
+	cleaned := cleanedThread.GetMessages()[0]
+	want := `<img src="https://t.example.com/x.gif" width="1" height="1">https://example.com/`
+	if cleaned.GetContent() != want {
+		t.Errorf("message content = %q, want %q", cleaned.GetContent(), want)
+	}
+
+	if links := cleaned.GetLinks(); len(links) != 1 || links[0].URL != "https://example.com/?id=2" {
+		t.Errorf("message links = %+v", links)
+	}
+
+	if message.GetLinks()[0].URL != "https://example.com/?utm_id=1&id=2" {
+		t.Error("Transform() should not modify the original item's links")
+	}
+
+	if result[1] != untouched {
+		t.Error("Transform() should return unchanged items as they are")
+	}
+}
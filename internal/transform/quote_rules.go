@@ -0,0 +1,204 @@
+package transform
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"pkm-sync/pkg/models"
+)
+
+const (
+	defaultQuoteLocale = "en"
+
+	// configItemSignatureThresholds holds per-item signature thresholds, set by ApplySignatureThresholds
+	configItemSignatureThresholds = "item_signature_thresholds"
+)
+
+// quoteRule marks the line where quoted text begins; title names the collapsed callout.
+type quoteRule struct {
+	pattern *regexp.Regexp
+	title   string
+}
+
+// quoteLocales are the reply and forward headers mail clients write, per language.
+var quoteLocales = map[string][]quoteRule{
+	"en": {
+		{regexp.MustCompile(`^On .* wrote:`), quotedTitlePrevious},
+		{regexp.MustCompile(`^From: .*@`), quotedTitlePrevious},
+		{regexp.MustCompile(`Original Message|original message`), quotedTitlePrevious},
+		{regexp.MustCompile(`^---------- Forwarded message`), quotedTitleForwarded},
+	},
+	"de": {
+		{regexp.MustCompile(`^Am .* schrieb .*:`), quotedTitlePrevious},
+		{regexp.MustCompile(`^Von: .*@`), quotedTitlePrevious},
+		{regexp.MustCompile(`(?i)Ursprüngliche Nachricht`), quotedTitlePrevious},
+		{regexp.MustCompile(`(?i)^-+ ?Weitergeleitete Nachricht`), quotedTitleForwarded},
+	},
+	"fr": {
+		{regexp.MustCompile(`^Le .* a écrit ?:`), quotedTitlePrevious},
+		{regexp.MustCompile(`^De ?: .*@`), quotedTitlePrevious},
+		{regexp.MustCompile(`(?i)Message d'origine`), quotedTitlePrevious},
+		{regexp.MustCompile(`(?i)^-+ ?Message transféré`), quotedTitleForwarded},
+	},
+}
+
+// signOffLocales are closing lines that start a signature, per language. English sign-offs are part of
+// signature_removal's default patterns.
+var signOffLocales = map[string][]*regexp.Regexp{
+	"en": nil,
+	"de": {
+		regexp.MustCompile(`(?i)^(Mit )?(freundlichen|besten|viele|liebe) Grüßen?,?`),
+		regexp.MustCompile(`(?i)^(Beste|Viele|Liebe) Grüße,?`),
+		regexp.MustCompile(`(?i)^Gesendet von meinem`),
+	},
+	"fr": {
+		regexp.MustCompile(`(?i)^Cordialement,?`),
+		regexp.MustCompile(`(?i)^Bien (à vous|cordialement),?`),
+		regexp.MustCompile(`(?i)^Envoyé de mon`),
+	},
+}
+
+// loadQuoteRules builds the quote rules for the configured quote_locales, default "en", followed by
+// the user's quote_patterns.
+func loadQuoteRules(config map[string]interface{}) ([]quoteRule, error) {
+	locales, err := configLocales(config, "quote_locales")
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []quoteRule
+	for _, locale := range locales {
+		rules = append(rules, quoteLocales[locale]...)
+	}
+
+	patterns, err := compilePatterns(config, "quote_patterns")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pattern := range patterns {
+		rules = append(rules, quoteRule{pattern: pattern, title: quotedTitlePrevious})
+	}
+
+	return rules, nil
+}
+
+// loadSignOffPatterns returns the sign-off patterns of the configured locales.
+func loadSignOffPatterns(config map[string]interface{}) ([]*regexp.Regexp, error) {
+	locales, err := configLocales(config, "locales")
+	if err != nil {
+		return nil, err
+	}
+
+	var patterns []*regexp.Regexp
+	for _, locale := range locales {
+		patterns = append(patterns, signOffLocales[locale]...)
+	}
+
+	return patterns, nil
+}
+
+// configLocales reads a list of locale packs from config, defaulting to English.
+func configLocales(config map[string]interface{}, key string) ([]string, error) {
+	values := stringList(config[key])
+	if len(values) == 0 {
+		return []string{defaultQuoteLocale}, nil
+	}
+
+	locales := make([]string, 0, len(values))
+	for _, value := range values {
+		locale := strings.ToLower(strings.TrimSpace(value))
+		if _, ok := quoteLocales[locale]; !ok {
+			return nil, fmt.Errorf("unsupported locale '%s' in %s: supported locales are %s",
+				value, key, supportedLocales())
+		}
+
+		locales = append(locales, locale)
+	}
+
+	return locales, nil
+}
+
+func supportedLocales() string {
+	names := make([]string, 0, len(quoteLocales))
+	for name := range quoteLocales {
+		names = append(names, "'"+name+"'")
+	}
+
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
+
+// compilePatterns compiles a list of regular expressions from config.
+func compilePatterns(config map[string]interface{}, key string) ([]*regexp.Regexp, error) {
+	var patterns []*regexp.Regexp
+
+	for _, value := range stringList(config[key]) {
+		pattern, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s pattern '%s': %w", key, value, err)
+		}
+
+		patterns = append(patterns, pattern)
+	}
+
+	return patterns, nil
+}
+
+// stringList reads a list of strings from a config value decoded from YAML or set in code.
+func stringList(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+
+		return values
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	}
+
+	return nil
+}
+
+// ApplySignatureThresholds passes per-item signature thresholds, keyed by item ID, to content_cleanup
+// and signature_removal. Sync uses it for sources that set signature_threshold.
+func ApplySignatureThresholds(config *models.TransformConfig, thresholds map[string]int) {
+	if len(thresholds) == 0 {
+		return
+	}
+
+	if config.Transformers == nil {
+		config.Transformers = make(map[string]map[string]interface{})
+	}
+
+	for _, name := range []string{transformerNameContentCleanup, transformerNameSignatureRemoval} {
+		if config.Transformers[name] == nil {
+			config.Transformers[name] = make(map[string]interface{})
+		}
+
+		config.Transformers[name][configItemSignatureThresholds] = thresholds
+	}
+}
+
+// itemSignatureThreshold returns the threshold set for an item's source, if any.
+func itemSignatureThreshold(config map[string]interface{}, item models.ItemInterface) (int, bool) {
+	thresholds, ok := config[configItemSignatureThresholds].(map[string]int)
+	if !ok {
+		return 0, false
+	}
+
+	threshold, ok := thresholds[item.GetID()]
+
+	return threshold, ok && threshold > 0
+}
@@ -0,0 +1,79 @@
+package transform
+
+import (
+	"fmt"
+	"slices"
+
+	"pkm-sync/pkg/models"
+)
+
+const (
+	SourcePipelineAfter  = "after"
+	SourcePipelineBefore = "before"
+)
+
+// IsScoped reports whether a source changes the transformer pipeline for its items.
+func IsScoped(source models.SourceTransformConfig) bool {
+	return len(source.Disable) > 0 || len(source.PipelineOrder) > 0
+}
+
+// SourcePipelineConfig returns the pipeline configuration for the items of a source: the global pipeline
+// without the transformers the source disables, with the source's own transformers before or after it.
+// Global transformers only run when the global pipeline is enabled; the source's always run. Options the
+// source sets replace the global options of the same transformer.
+func SourcePipelineConfig(global models.TransformConfig, source models.SourceTransformConfig,
+) (models.TransformConfig, error) {
+	if !IsScoped(source) {
+		return global, nil
+	}
+
+	for _, name := range source.Disable {
+		if !slices.Contains(global.PipelineOrder, name) {
+			return models.TransformConfig{}, fmt.Errorf("cannot disable transformer '%s': it is not in the global "+
+				"pipeline_order", name)
+		}
+	}
+
+	var globalOrder []string
+
+	if global.Enabled {
+		for _, name := range global.PipelineOrder {
+			if !slices.Contains(source.Disable, name) {
+				globalOrder = append(globalOrder, name)
+			}
+		}
+	}
+
+	for _, name := range source.PipelineOrder {
+		if slices.Contains(globalOrder, name) {
+			return models.TransformConfig{}, fmt.Errorf("transformer '%s' already runs in the global pipeline: "+
+				"set its options under the source's transformers instead", name)
+		}
+	}
+
+	scoped := models.TransformConfig{
+		Enabled:       len(globalOrder) > 0 || len(source.PipelineOrder) > 0,
+		ErrorStrategy: global.ErrorStrategy,
+		Transformers:  make(map[string]map[string]interface{}, len(global.Transformers)+len(source.Transformers)),
+	}
+
+	switch source.Position {
+	case "", SourcePipelineAfter:
+		scoped.PipelineOrder = append(slices.Clone(globalOrder), source.PipelineOrder...)
+	case SourcePipelineBefore:
+		scoped.PipelineOrder = append(slices.Clone(source.PipelineOrder), globalOrder...)
+	default:
+		return models.TransformConfig{}, fmt.Errorf("unsupported position '%s': supported positions are '%s' and '%s'",
+			source.Position, SourcePipelineAfter, SourcePipelineBefore)
+	}
+
+	for name, settings := range global.Transformers {
+		scoped.Transformers[name] = settings
+	}
+
+	for name, settings := range source.Transformers {
+		scoped.Transformers[name] = settings
+	}
+
+	return scoped, nil
+}
@@ -0,0 +1,77 @@
+package transform
+
+import (
+	"slices"
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestSourcePipelineConfig(t *testing.T) {
+	global := models.TransformConfig{
+		Enabled:       true,
+		PipelineOrder: []string{"content_cleanup", "signature_removal", "auto_tagging"},
+		Transformers: map[string]map[string]interface{}{
+			"auto_tagging": {"rules": []interface{}{}},
+		},
+	}
+
+	unscoped, err := SourcePipelineConfig(global, models.SourceTransformConfig{})
+	if err != nil || !slices.Equal(unscoped.PipelineOrder, global.PipelineOrder) {
+		t.Errorf("SourcePipelineConfig() without scoping = %v, %v; want the global pipeline", unscoped.PipelineOrder, err)
+	}
+
+	source := models.SourceTransformConfig{
+		Disable:       []string{"signature_removal"},
+		PipelineOrder: []string{"link_extraction"},
+		Transformers: map[string]map[string]interface{}{
+			"auto_tagging": {"rules": []interface{}{"work"}},
+		},
+	}
+
+	scoped, err := SourcePipelineConfig(global, source)
+	if err != nil {
+		t.Fatalf("SourcePipelineConfig() error = %v", err)
+	}
+
+	if want := []string{"content_cleanup", "auto_tagging", "link_extraction"}; !slices.Equal(scoped.PipelineOrder, want) {
+		t.Errorf("PipelineOrder = %v, want %v", scoped.PipelineOrder, want)
+	}
+
+	if rules := scoped.Transformers["auto_tagging"]["rules"].([]interface{}); len(rules) != 1 {
+		t.Error("the source's auto_tagging options did not replace the global ones")
+	}
+
+	if len(global.Transformers["auto_tagging"]["rules"].([]interface{})) != 0 {
+		t.Error("SourcePipelineConfig() changed the global options")
+	}
+
+	source.Position = SourcePipelineBefore
+
+	scoped, err = SourcePipelineConfig(global, source)
+	if want := []string{"link_extraction", "content_cleanup", "auto_tagging"}; err != nil ||
+		!slices.Equal(scoped.PipelineOrder, want) {
+		t.Errorf("PipelineOrder before = %v, %v; want %v", scoped.PipelineOrder, err, want)
+	}
+
+	global.Enabled = false
+
+	scoped, err = SourcePipelineConfig(global, source)
+	if err != nil || !scoped.Enabled || !slices.Equal(scoped.PipelineOrder, []string{"link_extraction"}) {
+		t.Errorf("with the global pipeline disabled = %v, %v; want only the source's transformers", scoped, err)
+	}
+
+	global.Enabled = true
+
+	invalid := []models.SourceTransformConfig{
+		{Disable: []string{"link_extraction"}},
+		{PipelineOrder: []string{"content_cleanup"}},
+		{PipelineOrder: []string{"link_extraction"}, Position: "middle"},
+	}
+
+	for _, source := range invalid {
+		if _, err := SourcePipelineConfig(global, source); err == nil {
+			t.Errorf("SourcePipelineConfig(%+v) accepted invalid scoping", source)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package transform
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"pkm-sync/pkg/models"
+)
+
+// signatureMetadataKey holds the contact parsed from a removed signature, with capture_signatures.
+const signatureMetadataKey = "signature"
+
+var (
+	signatureEmail = regexp.MustCompile(`[\w.+-]+@[\w-]+(\.[\w-]+)+`)
+	signaturePhone = regexp.MustCompile(`\+?\(?\d[\d\s().-]{6,}\d`)
+	signatureURL   = regexp.MustCompile(`(?i)^(https?://|www\.)`)
+
+	// Labels such as "Tel:", "M:" or "Mobile -" in front of a phone number or address
+	signatureLabel = regexp.MustCompile(`(?i)^(tel|phone|mobile|cell|office|fax|m|p|t|e|email|e-mail)\.?\s*[:\-]\s*`)
+)
+
+// parseSignature reads a name, job title, company, phone number and email address from signature lines.
+// The first line that looks like a person's name is the name; the next text lines are the title and
+// company. No contact is returned when there is no name.
+func (t *SignatureRemovalTransformer) parseSignature(lines []string) (models.Contact, bool) {
+	var (
+		contact models.Contact
+		details []string
+	)
+
+	for _, line := range signatureParts(lines) {
+		line = strings.TrimSpace(strings.TrimPrefix(line, "-- "))
+		if line == "" || line == "--" || signatureURL.MatchString(line) {
+			continue
+		}
+
+		if email := signatureEmail.FindString(line); email != "" {
+			if contact.Email == "" {
+				contact.Email = strings.ToLower(email)
+			}
+
+			continue
+		}
+
+		if phone := signaturePhone.FindString(line); phone != "" && digitCount(phone) >= 7 {
+			if contact.Phone == "" {
+				contact.Phone = strings.TrimSpace(phone)
+			}
+
+			continue
+		}
+
+		line = signatureLabel.ReplaceAllString(line, "")
+
+		switch {
+		case contact.Name == "" && isSignOff(line):
+			// "Best regards," and friends precede the name
+		case contact.Name == "" && looksLikePersonName(line):
+			contact.Name = strings.TrimSuffix(line, ",")
+		case contact.Name != "":
+			details = append(details, line)
+		}
+	}
+
+	if contact.Name == "" {
+		return models.Contact{}, false
+	}
+
+	if len(details) > 0 {
+		contact.Title = details[0]
+	}
+
+	if len(details) > 1 {
+		contact.Company = details[1]
+	}
+
+	return contact, true
+}
+
+// signatureParts splits lines such as "Jane Doe | Engineering Manager | ACME Corp" into their parts.
+func signatureParts(lines []string) []string {
+	var parts []string
+
+	for _, line := range lines {
+		for _, part := range strings.Split(line, "|") {
+			parts = append(parts, strings.TrimSpace(part))
+		}
+	}
+
+	return parts
+}
+
+// isSignOff reports whether a line is a closing such as "Best regards," rather than part of the contact.
+func isSignOff(line string) bool {
+	for _, patterns := range signOffLocales {
+		for _, pattern := range patterns {
+			if pattern.MatchString(line) {
+				return true
+			}
+		}
+	}
+
+	lower := strings.ToLower(strings.TrimRight(line, ",!. "))
+	for _, signOff := range []string{"best", "best regards", "regards", "kind regards", "sincerely", "thanks",
+		"thank you", "cheers", "all the best", "warm regards", "warmly"} {
+		if lower == signOff {
+			return true
+		}
+	}
+
+	return false
+}
+
+// looksLikePersonName reports whether a line is two to four capitalized words without digits.
+func looksLikePersonName(line string) bool {
+	words := strings.Fields(strings.TrimSuffix(line, ","))
+	if len(words) < 2 || len(words) > 4 {
+		return false
+	}
+
+	for _, word := range words {
+		first := []rune(word)[0]
+		if !unicode.IsUpper(first) || strings.IndexFunc(word, unicode.IsDigit) >= 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func digitCount(s string) int {
+	count := 0
+
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// withMetadata returns a copy of metadata with key set, leaving the original item's metadata unchanged.
+func withMetadata(metadata map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	updated := make(map[string]interface{}, len(metadata)+1)
+	for k, v := range metadata {
+		updated[k] = v
+	}
+
+	updated[key] = value
+
+	return updated
+}
@@ -8,6 +8,8 @@ import (
 	"pkm-sync/pkg/models"
 )
 
+const transformerNameSignatureRemoval = "signature_removal"
+
 // SignatureRemovalTransformer detects and removes email signatures from content.
 // Extracted from Gmail's ContentProcessor.ExtractSignatures to be universally available.
 type SignatureRemovalTransformer struct {
@@ -38,10 +40,15 @@ func NewSignatureRemovalTransformer() *SignatureRemovalTransformer {
 }
 
 func (t *SignatureRemovalTransformer) Name() string {
-	return "signature_removal"
+	return transformerNameSignatureRemoval
 }
 
 func (t *SignatureRemovalTransformer) Configure(config map[string]interface{}) error {
+	signOffs, err := loadSignOffPatterns(config)
+	if err != nil {
+		return err
+	}
+
 	t.config = config
 
 	// Load custom patterns if provided
@@ -49,6 +56,9 @@ func (t *SignatureRemovalTransformer) Configure(config map[string]interface{}) e
 		t.loadCustomPatterns(patterns)
 	}
 
+	// Sign-offs of the configured locales, kept even when custom patterns replace the defaults
+	t.signatureRegexPatterns = append(t.signatureRegexPatterns, signOffs...)
+
 	return nil
 }
 
@@ -56,9 +66,19 @@ func (t *SignatureRemovalTransformer) Transform(items []models.FullItem) ([]mode
 	transformedItems := make([]models.FullItem, len(items))
 
 	for i, item := range items {
-		cleanedContent := t.ExtractSignatures(item.GetContent())
+		maxSignatureLines := t.getMaxSignatureLines()
+		if threshold, ok := itemSignatureThreshold(t.config, item); ok {
+			maxSignatureLines = threshold
+		}
+
+		cleanedContent, signatureLines := t.extractSignatures(item.GetContent(), maxSignatureLines)
 
 		if cleanedContent != item.GetContent() {
+			metadata := item.GetMetadata()
+			if contact, ok := t.parseSignature(signatureLines); ok && t.shouldCaptureSignatures() {
+				metadata = withMetadata(metadata, signatureMetadataKey, contact)
+			}
+
 			// Create a new item copy (preserving type)
 			var newItem models.ItemInterface
 
@@ -71,7 +91,7 @@ func (t *SignatureRemovalTransformer) Transform(items []models.FullItem) ([]mode
 				newThread.SetUpdatedAt(thread.GetUpdatedAt())
 				newThread.SetTags(thread.GetTags())
 				newThread.SetAttachments(thread.GetAttachments())
-				newThread.SetMetadata(thread.GetMetadata())
+				newThread.SetMetadata(metadata)
 				newThread.SetLinks(thread.GetLinks())
 
 				// Copy messages
@@ -89,7 +109,7 @@ func (t *SignatureRemovalTransformer) Transform(items []models.FullItem) ([]mode
 				newBasicItem.SetUpdatedAt(item.GetUpdatedAt())
 				newBasicItem.SetTags(item.GetTags())
 				newBasicItem.SetAttachments(item.GetAttachments())
-				newBasicItem.SetMetadata(item.GetMetadata())
+				newBasicItem.SetMetadata(metadata)
 				newBasicItem.SetLinks(item.GetLinks())
 
 				newItem = newBasicItem
@@ -108,15 +128,22 @@ func (t *SignatureRemovalTransformer) Transform(items []models.FullItem) ([]mode
 // ExtractSignatures extracts email signatures from content.
 // Extracted from Gmail's ContentProcessor.ExtractSignatures.
 func (t *SignatureRemovalTransformer) ExtractSignatures(content string) string {
+	result, _ := t.extractSignatures(content, t.getMaxSignatureLines())
+
+	return result
+}
+
+// extractSignatures removes a signature starting within the last maxSignatureLines lines, returning the
+// remaining content and the removed signature lines.
+func (t *SignatureRemovalTransformer) extractSignatures(content string, maxSignatureLines int) (string, []string) {
 	lines := strings.Split(content, "\n")
 
 	var (
-		contentLines []string
-		inSignature  bool
+		contentLines   []string
+		signatureLines []string
+		inSignature    bool
 	)
 
-	maxSignatureLines := t.getMaxSignatureLines()
-
 	for i, line := range lines {
 		trimmed := strings.TrimSpace(line)
 
@@ -135,13 +162,16 @@ func (t *SignatureRemovalTransformer) ExtractSignatures(content string) string {
 				if t.looksLikeSignature(trimmed) {
 					inSignature = true
 					// Don't include this line either
+					signatureLines = append(signatureLines, trimmed)
 
 					continue
 				}
 			}
 		}
 
-		if !inSignature {
+		if inSignature {
+			signatureLines = append(signatureLines, trimmed)
+		} else {
 			contentLines = append(contentLines, line)
 		}
 	}
@@ -155,7 +185,7 @@ func (t *SignatureRemovalTransformer) ExtractSignatures(content string) string {
 	}
 	// Note: When trim_empty_lines is false, we preserve all content as-is
 
-	return result
+	return result, signatureLines
 }
 
 // looksLikeSignature checks if a line looks like it could be part of a signature.
@@ -211,6 +241,14 @@ func (t *SignatureRemovalTransformer) shouldMergeWithDefaults() bool {
 	return true // Default: merge custom patterns with defaults
 }
 
+func (t *SignatureRemovalTransformer) shouldCaptureSignatures() bool {
+	if b, ok := t.config["capture_signatures"].(bool); ok {
+		return b
+	}
+
+	return false // Default: signatures are discarded
+}
+
 func (t *SignatureRemovalTransformer) shouldTrimEmptyLines() bool {
 	if val, exists := t.config["trim_empty_lines"]; exists {
 		if b, ok := val.(bool); ok {
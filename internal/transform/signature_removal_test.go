@@ -310,6 +310,92 @@ Important message.`
 	}
 }
 
+func TestSignatureRemovalTransformer_Locales(t *testing.T) {
+	transformer := NewSignatureRemovalTransformer()
+	if err := transformer.Configure(map[string]interface{}{"locales": []interface{}{"de", "fr"}}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Anbei der Bericht.\n\nMit freundlichen Grüßen\nmax", "Anbei der Bericht."},
+		{"Voici le rapport.\n\nCordialement,\njean", "Voici le rapport."},
+	}
+
+	for _, tt := range tests {
+		if result := strings.TrimSpace(transformer.ExtractSignatures(tt.input)); result != tt.expected {
+			t.Errorf("ExtractSignatures(%q) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+
+	if err := NewSignatureRemovalTransformer().Configure(map[string]interface{}{"locales": "xx"}); err == nil {
+		t.Error("Configure() expected error for unsupported locale")
+	}
+}
+
+func TestSignatureRemovalTransformer_CaptureSignatures(t *testing.T) {
+	transformer := NewSignatureRemovalTransformer()
+	if err := transformer.Configure(map[string]interface{}{"capture_signatures": true}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected models.Contact
+	}{
+		{
+			name: "Sign-off with contact lines",
+			content: "Notes attached.\n\nBest regards,\nJane Doe\nEngineering Manager\nACME Corp\n" +
+				"Phone: +1 (555) 123-4567\njane.doe@acme.com",
+			expected: models.Contact{Name: "Jane Doe", Title: "Engineering Manager", Company: "ACME Corp",
+				Phone: "+1 (555) 123-4567", Email: "jane.doe@acme.com"},
+		},
+		{
+			name:     "Separator with one-line signature",
+			content:  "Ok\n--\nJohn Smith | Director | Initech\nM: 555-987-6543",
+			expected: models.Contact{Name: "John Smith", Title: "Director", Company: "Initech", Phone: "555-987-6543"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := models.NewBasicItem("1", "Notes")
+			item.SetContent(tt.content)
+			item.SetMetadata(map[string]interface{}{"from": "jane@acme.com"})
+
+			result, err := transformer.Transform([]models.FullItem{item})
+			if err != nil {
+				t.Fatalf("Transform() error = %v", err)
+			}
+
+			contact, ok := result[0].GetMetadata()["signature"].(models.Contact)
+			if !ok || contact != tt.expected {
+				t.Errorf("signature metadata = %#v, want %#v", result[0].GetMetadata()["signature"], tt.expected)
+			}
+
+			if _, changed := item.GetMetadata()["signature"]; changed {
+				t.Error("Transform() should not modify the original item's metadata")
+			}
+		})
+	}
+
+	// Without a name there is no contact to record
+	item := models.NewBasicItem("2", "Short")
+	item.SetContent("Thanks!\nBob")
+
+	result, err := transformer.Transform([]models.FullItem{item})
+	if err != nil {
+		t.Fatalf("Transform() error = %v", err)
+	}
+
+	if _, ok := result[0].GetMetadata()["signature"]; ok {
+		t.Errorf("unexpected signature metadata: %v", result[0].GetMetadata())
+	}
+}
+
 func TestSignatureRemovalTransformer_ConfigurationOptions(t *testing.T) {
 	transformer := NewSignatureRemovalTransformer()
 
@@ -2,6 +2,7 @@ package transform
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -21,23 +22,28 @@ const (
 // ThreadGroupingTransformer consolidates related items based on thread metadata.
 // Extracted from Gmail's ThreadProcessor to be universally available.
 type ThreadGroupingTransformer struct {
-	config map[string]interface{}
+	config    map[string]interface{}
+	filenames utils.FilenamePolicy // Naming of thread subjects in thread titles (filename_* keys)
 }
 
 // ThreadGroup represents a group of items that belong to the same thread.
 type ThreadGroup struct {
 	ThreadID     string         `json:"thread_id"`
-	Subject      string         `json:"subject"`
+	Subject      string         `json:"subject"` // Subject of the newest item
 	Items        []*models.Item `json:"items"`
 	Participants []string       `json:"participants"`
 	StartTime    time.Time      `json:"start_time"`
 	EndTime      time.Time      `json:"end_time"`
 	ItemCount    int            `json:"item_count"`
+
+	// AlternateSubjects are the earlier subjects of a thread whose subject changed mid-conversation, oldest first
+	AlternateSubjects []string `json:"alternate_subjects,omitempty"`
 }
 
 func NewThreadGroupingTransformer() *ThreadGroupingTransformer {
 	return &ThreadGroupingTransformer{
-		config: make(map[string]interface{}),
+		config:    make(map[string]interface{}),
+		filenames: utils.DefaultFilenamePolicy(),
 	}
 }
 
@@ -46,7 +52,13 @@ func (t *ThreadGroupingTransformer) Name() string {
 }
 
 func (t *ThreadGroupingTransformer) Configure(config map[string]interface{}) error {
+	filenames, err := utils.ParseFilenamePolicy(config, utils.DefaultFilenamePolicy())
+	if err != nil {
+		return fmt.Errorf("thread_grouping: %w", err)
+	}
+
 	t.config = config
+	t.filenames = filenames
 
 	return nil
 }
@@ -100,6 +112,7 @@ func (t *ThreadGroupingTransformer) Transform(items []models.FullItem) ([]models
 // groupItemsByThread groups items by their thread ID.
 func (t *ThreadGroupingTransformer) groupItemsByThread(items []*models.Item) map[string]*ThreadGroup {
 	threadGroups := make(map[string]*ThreadGroup)
+	replyChains := t.replyChainThreadIDs(items)
 
 	for _, item := range items {
 		if item == nil {
@@ -107,6 +120,11 @@ func (t *ThreadGroupingTransformer) groupItemsByThread(items []*models.Item) map
 		}
 
 		threadID := t.extractThreadID(item)
+		if threadID == "" {
+			// Sources without thread IDs are threaded by their reply headers
+			threadID = replyChains[item]
+		}
+
 		if threadID == "" {
 			// No thread ID - treat as individual item
 			threadID = item.ID
@@ -147,6 +165,9 @@ func (t *ThreadGroupingTransformer) groupItemsByThread(items []*models.Item) map
 		})
 		// Update item count to be thread-safe
 		group.ItemCount = len(group.Items)
+
+		// Title the thread by its newest subject, whatever the subject was when it started
+		group.Subject, group.AlternateSubjects = t.threadSubjects(group.Items)
 	}
 
 	return threadGroups
@@ -176,7 +197,7 @@ func (t *ThreadGroupingTransformer) consolidateThreads(threadGroups map[string]*
 
 		// Create consolidated thread item
 		title := fmt.Sprintf("Thread_%s_%d-items",
-			utils.SanitizeThreadSubject(group.Subject, group.ThreadID),
+			t.filenames.ThreadSubject(group.Subject, group.ThreadID),
 			group.ItemCount)
 
 		consolidated := &models.Item{
@@ -218,7 +239,7 @@ func (t *ThreadGroupingTransformer) summarizeThreads(threadGroups map[string]*Th
 		}
 
 		title := fmt.Sprintf("Thread-Summary_%s_%d-items",
-			utils.SanitizeThreadSubject(group.Subject, group.ThreadID),
+			t.filenames.ThreadSubject(group.Subject, group.ThreadID),
 			group.ItemCount)
 
 		summary := &models.Item{
@@ -403,14 +424,78 @@ func (t *ThreadGroupingTransformer) selectAdditionalItems(items []*models.Item,
 // Helper functions
 
 func (t *ThreadGroupingTransformer) extractThreadID(item *models.Item) string {
-	if threadID, exists := item.Metadata["thread_id"].(string); exists {
-		return threadID
+	threadID, _ := models.Metadata(item.Metadata).GetString(models.MetadataThreadID)
+
+	return threadID
+}
+
+// replyChainThreadIDs reconstructs threads for items without a thread ID, such as mail from IMAP or Outlook
+// exports, from their Message-ID, In-Reply-To and References headers. An item's thread is named after the
+// Message-ID of the conversation's first message: the first of its References, else the root of the
+// In-Reply-To chain through the other items, else its own Message-ID.
+func (t *ThreadGroupingTransformer) replyChainThreadIDs(items []*models.Item) map[*models.Item]string {
+	byMessageID := make(map[string]*models.Item)
+
+	for _, item := range items {
+		if item == nil || t.extractThreadID(item) != "" {
+			continue
+		}
+
+		if id := t.headerMessageID(item, models.MetadataMessageID); id != "" {
+			byMessageID[id] = item
+		}
+	}
+
+	var root func(item *models.Item, depth int) string
+	root = func(item *models.Item, depth int) string {
+		// References lists the conversation oldest first, possibly as one header string
+		if references, _ := models.Metadata(item.Metadata).GetStrings(models.MetadataReferences); len(references) > 0 {
+			if fields := strings.Fields(references[0]); len(fields) > 0 && strings.Trim(fields[0], "<>,") != "" {
+				return strings.Trim(fields[0], "<>,")
+			}
+		}
+
+		parent := t.headerMessageID(item, models.MetadataInReplyTo)
+		if parent == "" {
+			return t.headerMessageID(item, models.MetadataMessageID)
+		}
+
+		// Bounded by the number of items, so a reply loop cannot recurse forever
+		if message, ok := byMessageID[parent]; ok && depth < len(items) {
+			return root(message, depth+1)
+		}
+
+		return parent
+	}
+
+	threadIDs := make(map[*models.Item]string)
+
+	for _, item := range items {
+		if item == nil || t.extractThreadID(item) != "" {
+			continue
+		}
+
+		if threadID := root(item, 0); threadID != "" {
+			threadIDs[item] = threadID
+		}
 	}
 
-	return ""
+	return threadIDs
+}
+
+// headerMessageID returns a Message-ID metadata value without its angle brackets.
+func (t *ThreadGroupingTransformer) headerMessageID(item *models.Item, key string) string {
+	id, _ := models.Metadata(item.Metadata).GetString(key)
+
+	return strings.Trim(id, "<> ")
 }
 
 func (t *ThreadGroupingTransformer) extractThreadSubject(item *models.Item) string {
+	// Sources whose messages have no subject line of their own name the thread explicitly
+	if subject, _ := models.Metadata(item.Metadata).GetString(models.MetadataThreadSubject); subject != "" {
+		return subject
+	}
+
 	// Clean up subject line (remove Re:, Fwd:, etc.)
 	subject := item.Title
 	subject = strings.TrimSpace(subject)
@@ -439,26 +524,42 @@ func (t *ThreadGroupingTransformer) extractThreadSubject(item *models.Item) stri
 	return subject
 }
 
+// threadSubjects returns the subject of the newest item with one and the distinct earlier subjects.
+func (t *ThreadGroupingTransformer) threadSubjects(items []*models.Item) (string, []string) {
+	var subjects []string
+
+	for _, item := range items {
+		subject := t.extractThreadSubject(item)
+		if subject == "" {
+			continue
+		}
+
+		subjects = slices.DeleteFunc(subjects, func(seen string) bool {
+			return strings.EqualFold(seen, subject)
+		})
+		subjects = append(subjects, subject)
+	}
+
+	if len(subjects) == 0 {
+		return "", nil
+	}
+
+	return subjects[len(subjects)-1], subjects[:len(subjects)-1]
+}
+
 func (t *ThreadGroupingTransformer) extractParticipants(item *models.Item) []string {
 	var participants []string
 
 	// Extract from metadata if available
-	if from, exists := item.Metadata["from"]; exists {
-		if author := t.extractEmailFromRecipient(from); author != "" {
-			participants = append(participants, author)
-		}
+	if author := t.extractEmailFromRecipient(item.Metadata[models.MetadataFrom]); author != "" {
+		participants = append(participants, author)
 	}
 
 	return participants
 }
 
 func (t *ThreadGroupingTransformer) updateParticipants(group *ThreadGroup, item *models.Item) {
-	from, exists := item.Metadata["from"]
-	if !exists {
-		return
-	}
-
-	author := t.extractEmailFromRecipient(from)
+	author := t.extractEmailFromRecipient(item.Metadata[models.MetadataFrom])
 	if author == "" {
 		return
 	}
@@ -473,46 +574,14 @@ func (t *ThreadGroupingTransformer) updateParticipants(group *ThreadGroup, item
 }
 
 func (t *ThreadGroupingTransformer) extractAuthor(item *models.Item) string {
-	if from, exists := item.Metadata["from"]; exists {
-		return t.extractEmailFromRecipient(from)
-	}
-
-	return ""
+	return t.extractEmailFromRecipient(item.Metadata[models.MetadataFrom])
 }
 
+// extractEmailFromRecipient returns the address of a sender, or their name when there is no address.
 func (t *ThreadGroupingTransformer) extractEmailFromRecipient(recipient interface{}) string {
-	if recipient == nil {
-		return ""
-	}
-
-	switch r := recipient.(type) {
-	case string:
-		// Handle "Name <email@example.com>" format
-		if strings.Contains(r, "<") && strings.Contains(r, ">") {
-			start := strings.LastIndex(r, "<")
-
-			end := strings.LastIndex(r, ">")
-			if start != -1 && end != -1 && end > start {
-				return r[start+1 : end]
-			}
-		}
-
-		return r
-	case map[string]interface{}:
-		if r == nil {
-			return ""
-		}
-
-		if email, ok := r["email"].(string); ok && email != "" {
-			return email
-		}
+	r, _ := models.AsRecipient(recipient)
 
-		if name, ok := r["name"].(string); ok && name != "" {
-			return name
-		}
-	}
-
-	return ""
+	return r.Identity()
 }
 
 func (t *ThreadGroupingTransformer) buildThreadMetadata(group *ThreadGroup) map[string]interface{} {
@@ -528,6 +597,14 @@ func (t *ThreadGroupingTransformer) buildThreadMetadata(group *ThreadGroup) map[
 	metadata["start_time"] = group.StartTime
 	metadata["end_time"] = group.EndTime
 
+	if len(group.AlternateSubjects) > 0 {
+		metadata[models.MetadataAlternateSubjects] = group.AlternateSubjects
+	}
+
+	if ids := t.messageIDs(group.Items); len(ids) > 0 {
+		metadata[models.MetadataMessageIDs] = ids
+	}
+
 	// Safe duration calculation
 	if !group.StartTime.IsZero() && !group.EndTime.IsZero() {
 		metadata["duration_hours"] = group.EndTime.Sub(group.StartTime).Hours()
@@ -538,6 +615,20 @@ func (t *ThreadGroupingTransformer) buildThreadMetadata(group *ThreadGroup) map[
 	return metadata
 }
 
+// messageIDs returns the Message-IDs of a thread's items, which link the thread to its copies in other
+// accounts.
+func (t *ThreadGroupingTransformer) messageIDs(items []*models.Item) []string {
+	var ids []string
+
+	for _, item := range items {
+		if id := t.headerMessageID(item, models.MetadataMessageID); id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
 func (t *ThreadGroupingTransformer) buildThreadTags(group *ThreadGroup) []string {
 	var tags []string
 
@@ -549,6 +549,36 @@ func TestThreadGroupingTransformer_groupItemsByThread(t *testing.T) {
 	}
 }
 
+func TestThreadGroupingTransformer_groupItemsByThread_SubjectChange(t *testing.T) {
+	transformer := NewThreadGroupingTransformer()
+
+	now := time.Now()
+	items := []*models.Item{
+		{ID: "2", Title: "Re: Offsite (moved to Friday)", CreatedAt: now.Add(time.Hour),
+			Metadata: map[string]interface{}{"thread_id": "threadA"}},
+		{ID: "1", Title: "Offsite", CreatedAt: now, Metadata: map[string]interface{}{"thread_id": "threadA"}},
+	}
+
+	groups := transformer.groupItemsByThread(items)
+	if len(groups) != 1 {
+		t.Fatalf("Expected 1 thread group, got %d", len(groups))
+	}
+
+	threadA := groups["threadA"]
+	if threadA.Subject != "Offsite (moved to Friday)" {
+		t.Errorf("Expected the newest subject, got '%s'", threadA.Subject)
+	}
+
+	if len(threadA.AlternateSubjects) != 1 || threadA.AlternateSubjects[0] != "Offsite" {
+		t.Errorf("Expected alternate subjects [Offsite], got %v", threadA.AlternateSubjects)
+	}
+
+	metadata := transformer.buildThreadMetadata(threadA)
+	if subjects, _ := metadata[models.MetadataAlternateSubjects].([]string); len(subjects) != 1 {
+		t.Errorf("Expected alternate_subjects in metadata, got %v", metadata[models.MetadataAlternateSubjects])
+	}
+}
+
 func TestThreadGroupingTransformer_ErrorHandling(t *testing.T) {
 	transformer := NewThreadGroupingTransformer()
 
@@ -579,3 +609,46 @@ func TestThreadGroupingTransformer_ErrorHandling(t *testing.T) {
 		t.Error("Expected error with invalid mode")
 	}
 }
+
+func TestThreadGroupingTransformer_groupItemsByThread_ReplyChains(t *testing.T) {
+	transformer := NewThreadGroupingTransformer()
+
+	now := time.Now()
+	message := func(id string, offset time.Duration, metadata map[string]interface{}) *models.Item {
+		return &models.Item{ID: id, Title: "Offsite", CreatedAt: now.Add(offset), Metadata: metadata}
+	}
+
+	// Mail from an IMAP export: no thread IDs, only reply headers
+	items := []*models.Item{
+		message("1", 0, map[string]interface{}{"message_id": "<root@mail.example>"}),
+		message("2", time.Hour, map[string]interface{}{
+			"message_id":  "<reply@mail.example>",
+			"in_reply_to": "<root@mail.example>",
+		}),
+		message("3", 2*time.Hour, map[string]interface{}{
+			"message_id":  "<second@mail.example>",
+			"in_reply_to": "<reply@mail.example>",
+		}),
+		message("4", 3*time.Hour, map[string]interface{}{
+			"message_id": "<third@mail.example>",
+			"references": "<root@mail.example> <second@mail.example>",
+		}),
+		message("5", 0, map[string]interface{}{"message_id": "<other@mail.example>"}),
+		message("6", 0, map[string]interface{}{}),
+	}
+
+	groups := transformer.groupItemsByThread(items)
+
+	if len(groups) != 3 {
+		t.Fatalf("Expected 3 thread groups, got %d", len(groups))
+	}
+
+	thread := groups["root@mail.example"]
+	if thread == nil || len(thread.Items) != 4 {
+		t.Fatalf("Expected the reply chain grouped under its first message, got %v", thread)
+	}
+
+	if groups["other@mail.example"] == nil || groups["6"] == nil {
+		t.Error("Expected unrelated messages to stay on their own")
+	}
+}
@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxDiffCells bounds the line-by-line table of a diff; texts beyond it diff as a whole replacement.
+const maxDiffCells = 4_000_000
+
+type diffOp struct {
+	kind byte // ' ' for an unchanged line, '-' for a removed one and '+' for an added one
+	line string
+}
+
+// UnifiedDiff renders the changes from before to after as a unified diff, with context unchanged lines
+// around each change. Identical texts give "".
+func UnifiedDiff(fromName, toName, before, after string, context int) string {
+	if before == after {
+		return ""
+	}
+
+	ops := diffLines(splitLines(before), splitLines(after))
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+
+	for start := 0; start < len(ops); {
+		first := nextChange(ops, start)
+		if first < 0 {
+			break
+		}
+
+		// A hunk runs until a gap between changes is too wide to share its context
+		last := first
+		for next := nextChange(ops, last+1); next >= 0 && next-last <= 2*context+1; next = nextChange(ops, last+1) {
+			last = next
+		}
+
+		from, to := max(first-context, 0), min(last+context+1, len(ops))
+		writeHunk(&sb, ops, from, to)
+
+		start = to
+	}
+
+	return sb.String()
+}
+
+// splitLines splits text into lines, without an empty last line for a trailing newline.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines finds the edits from a to b along their longest common subsequence of lines.
+func diffLines(a, b []string) []diffOp {
+	ops := make([]diffOp, 0, len(a)+len(b))
+
+	if len(a)*len(b) > maxDiffCells {
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+
+		return ops
+	}
+
+	// common[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case common[i+1][j] >= common[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+
+	return ops
+}
+
+// nextChange returns the index of the first changed line at or after start, or -1.
+func nextChange(ops []diffOp, start int) int {
+	for i := start; i < len(ops); i++ {
+		if ops[i].kind != ' ' {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// writeHunk writes ops[from:to] with its "@@ -line,count +line,count @@" header.
+func writeHunk(sb *strings.Builder, ops []diffOp, from, to int) {
+	oldLine, newLine := 1, 1
+
+	for _, op := range ops[:from] {
+		if op.kind != '+' {
+			oldLine++
+		}
+
+		if op.kind != '-' {
+			newLine++
+		}
+	}
+
+	oldCount, newCount := 0, 0
+
+	for _, op := range ops[from:to] {
+		if op.kind != '+' {
+			oldCount++
+		}
+
+		if op.kind != '-' {
+			newCount++
+		}
+	}
+
+	// An empty side is numbered by the line before it
+	if oldCount == 0 {
+		oldLine--
+	}
+
+	if newCount == 0 {
+		newLine--
+	}
+
+	fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+
+	for _, op := range ops[from:to] {
+		sb.WriteByte(op.kind)
+		sb.WriteString(op.line)
+		sb.WriteByte('\n')
+	}
+}
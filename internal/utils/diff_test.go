@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	before := "title\n\nline 1\nline 2\nline 3\nline 4\nline 5\nline 6\nline 7\nline 8\n"
+	after := "title\n\nline 1\nline two\nline 3\nline 4\nline 5\nline 6\nline 7\nline 8\nline 9\n"
+
+	want := `--- a/note.md
++++ b/note.md
+@@ -2,5 +2,5 @@
+ 
+ line 1
+-line 2
++line two
+ line 3
+ line 4
+@@ -9,2 +9,3 @@
+ line 7
+ line 8
++line 9
+`
+
+	if got := UnifiedDiff("a/note.md", "b/note.md", before, after, 2); got != want {
+		t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := UnifiedDiff("a", "b", before, before, 3); got != "" {
+		t.Errorf("UnifiedDiff() of identical texts = %q, want empty", got)
+	}
+}
+
+func TestUnifiedDiffNewFile(t *testing.T) {
+	got := UnifiedDiff("/dev/null", "b/new.md", "", "one\ntwo\n", 3)
+
+	want := "--- /dev/null\n+++ b/new.md\n@@ -0,0 +1,2 @@\n+one\n+two\n"
+	if got != want {
+		t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestUnifiedDiffMergesCloseChanges(t *testing.T) {
+	before := strings.Join([]string{"a", "b", "c", "d", "e"}, "\n")
+	after := strings.Join([]string{"A", "b", "c", "d", "E"}, "\n")
+
+	if got := UnifiedDiff("a", "b", before, after, 1); strings.Count(got, "@@ -") != 2 {
+		t.Errorf("changes 3 lines apart with 1 line of context should be separate hunks:\n%s", got)
+	}
+
+	if got := UnifiedDiff("a", "b", before, after, 2); strings.Count(got, "@@ -") != 1 {
+		t.Errorf("changes 3 lines apart with 2 lines of context should share a hunk:\n%s", got)
+	}
+}
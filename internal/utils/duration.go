@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+// durationUnits are the units of duration settings. Unlike in since expressions, "m" is minutes, as it always
+// was for email ages and is in Go durations; months are "mo". Months and years are approximated as 30 and 365
+// days.
+var durationUnits = map[string]time.Duration{
+	"m": time.Minute, "min": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": day, "day": day, "days": day,
+	"w": 7 * day, "week": 7 * day, "weeks": 7 * day,
+	"mo": 30 * day, "month": 30 * day, "months": 30 * day,
+	"y": 365 * day, "year": 365 * day, "years": 365 * day,
+}
+
+// ParseDuration parses a duration setting such as max_email_age, max_file_age or token_expiration: a count
+// and a unit like "30d", "2w", "6mo" or "1y", or a Go duration like "1h30m".
+func ParseDuration(raw string) (time.Duration, error) {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	if value == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+
+	count := strings.TrimRightFunc(value, func(r rune) bool { return r < '0' || r > '9' })
+	if unit, isUnit := durationUnits[value[len(count):]]; isUnit && isDigits(count) {
+		if n, err := strconv.Atoi(count); err == nil {
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	if duration, err := time.ParseDuration(value); err == nil && duration >= 0 {
+		return duration, nil
+	}
+
+	return 0, fmt.Errorf("invalid duration '%s': use a count and unit such as '30d', '2w', '6mo' or '1y'", raw)
+}
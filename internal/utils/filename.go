@@ -108,31 +108,10 @@ func SanitizeFilename(filename string) string {
 	return cleaned
 }
 
-// SanitizeThreadSubject sanitizes a thread subject for use in filenames
-// with fallback handling for empty subjects and thread ID collision prevention.
+// SanitizeThreadSubject sanitizes a thread subject for use in filenames with the default filename policy.
+// See FilenamePolicy.ThreadSubject for the configurable form.
 func SanitizeThreadSubject(subject, threadID string) string {
-	if subject == "" {
-		if threadID != "" {
-			return "email-thread-" + SanitizeFilename(threadID)
-		}
-
-		return "email-thread"
-	}
-
-	// Clean up subject line (remove Re:, Fwd:, etc.)
-	cleaned := cleanEmailSubject(subject)
-	if cleaned == "" {
-		cleaned = subject // Fallback to original if extraction fails
-	}
-
-	sanitized := SanitizeFilename(cleaned)
-
-	// If sanitization results in a generic name and we have a thread ID, append it
-	if (sanitized == safeFilename || sanitized == "default-filename" || sanitized == "email-thread") && threadID != "" {
-		sanitized = sanitized + "-" + SanitizeFilename(threadID)
-	}
-
-	return sanitized
+	return DefaultFilenamePolicy().ThreadSubject(subject, threadID)
 }
 
 // cleanEmailSubject removes common email prefixes like Re:, Fwd:, etc.
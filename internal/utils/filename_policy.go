@@ -0,0 +1,587 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"pkm-sync/pkg/models"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Filename styles.
+const (
+	// FilenameStyleHyphenated replaces spaces and punctuation with hyphens (SanitizeFilename).
+	FilenameStyleHyphenated = "hyphenated"
+	// FilenameStyleSlug transliterates to lowercase ASCII words joined by hyphens.
+	FilenameStyleSlug = "slug"
+	// FilenameStylePreserve keeps spaces and Unicode, removing only characters invalid in filenames.
+	FilenameStylePreserve = "preserve"
+)
+
+// Collision strategies.
+const (
+	// CollisionSuffix appends -1, -2, ... to later items sharing a name.
+	CollisionSuffix = "suffix"
+	// CollisionID appends the item ID to later items sharing a name.
+	CollisionID = "id"
+	// CollisionOverwrite lets later items replace earlier ones.
+	CollisionOverwrite = "overwrite"
+)
+
+const (
+	defaultMaxFilenameLength = 80
+
+	// minTruncatedLength keeps names recognizable when shortened to fit a path limit.
+	minTruncatedLength = 8
+)
+
+// windowsReservedNames cannot be used as file names on Windows, with or without an extension.
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// transliterations covers letters that do not decompose into a base letter plus accents.
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'Æ': "AE", 'ø': "o", 'Ø': "O", 'œ': "oe", 'Œ': "OE",
+	'ł': "l", 'Ł': "L", 'đ': "d", 'Đ': "D", 'þ': "th", 'Þ': "TH", 'ð': "d", 'Ð': "D",
+}
+
+// FilenamePolicy controls how titles become file names for all targets.
+type FilenamePolicy struct {
+	Style         string // FilenameStyleHyphenated, FilenameStyleSlug or FilenameStylePreserve
+	Collision     string // CollisionSuffix, CollisionID or CollisionOverwrite
+	MaxLength     int    // Maximum name length in bytes, excluding the extension
+	MaxPathLength int    // Maximum absolute path length; 0 disables the check
+	Lowercase     bool   // Lowercase names
+	ASCII         bool   // Transliterate to ASCII, dropping characters without an ASCII form
+	Separator     string // Word separator replacing the style's hyphens (or spaces for preserve); "" keeps them
+}
+
+// filenameSeparators are the supported word separators.
+var filenameSeparators = []string{"-", "_", " "}
+
+// DefaultFilenamePolicy returns the policy matching the historical hyphenated file names, limited to the
+// running OS's path length.
+func DefaultFilenamePolicy() FilenamePolicy {
+	return FilenamePolicy{
+		Style:         FilenameStyleHyphenated,
+		Collision:     CollisionSuffix,
+		MaxLength:     defaultMaxFilenameLength,
+		MaxPathLength: DefaultMaxPathLength(),
+	}
+}
+
+// ParseFilenamePolicy applies the filename_* keys of a target configuration onto defaults.
+func ParseFilenamePolicy(config map[string]interface{}, defaults FilenamePolicy) (FilenamePolicy, error) {
+	policy := defaults
+
+	if style, ok := config["filename_style"].(string); ok && style != "" {
+		switch style {
+		case FilenameStyleHyphenated, FilenameStyleSlug, FilenameStylePreserve:
+			policy.Style = style
+		default:
+			return policy, fmt.Errorf("unsupported filename_style '%s': supported styles are 'hyphenated', 'slug', 'preserve'",
+				style)
+		}
+	}
+
+	if collision, ok := config["filename_collision"].(string); ok && collision != "" {
+		switch collision {
+		case CollisionSuffix, CollisionID, CollisionOverwrite:
+			policy.Collision = collision
+		default:
+			return policy, fmt.Errorf(
+				"unsupported filename_collision '%s': supported strategies are 'suffix', 'id', 'overwrite'", collision)
+		}
+	}
+
+	if maxLength, ok := config["filename_max_length"].(int); ok && maxLength > 0 {
+		policy.MaxLength = maxLength
+	}
+
+	// A negative max_path_length turns off the OS default
+	if maxPathLength, ok := config["max_path_length"].(int); ok && maxPathLength > 0 {
+		policy.MaxPathLength = maxPathLength
+	} else if ok && maxPathLength < 0 {
+		policy.MaxPathLength = 0
+	}
+
+	if lowercase, ok := config["filename_lowercase"].(bool); ok {
+		policy.Lowercase = lowercase
+	}
+
+	if ascii, ok := config["filename_ascii"].(bool); ok {
+		policy.ASCII = ascii
+	}
+
+	if separator, ok := config["filename_separator"].(string); ok && separator != "" {
+		if !slices.Contains(filenameSeparators, separator) {
+			return policy, fmt.Errorf("unsupported filename_separator '%s': supported separators are '-', '_', ' '",
+				separator)
+		}
+
+		policy.Separator = separator
+	}
+
+	return policy, nil
+}
+
+// FilenamePolicyFromConfig applies the settings of a filenames block onto defaults. Unset settings, including
+// lowercase and ascii when false, keep the defaults, so a source's block only overrides what it names.
+func FilenamePolicyFromConfig(config models.FilenameConfig, defaults FilenamePolicy) (FilenamePolicy, error) {
+	configMap := map[string]interface{}{
+		"filename_style":      config.Style,
+		"filename_collision":  config.Collision,
+		"filename_max_length": config.MaxLength,
+		"max_path_length":     config.MaxPathLength,
+		"filename_separator":  config.Separator,
+	}
+
+	if config.Lowercase {
+		configMap["filename_lowercase"] = true
+	}
+
+	if config.ASCII {
+		configMap["filename_ascii"] = true
+	}
+
+	return ParseFilenamePolicy(configMap, defaults)
+}
+
+// ItemPolicies resolves per-item filenames settings (item ID -> settings) against a target's policy. Items
+// sharing settings share the resolved policy.
+func ItemPolicies(filenames map[string]models.FilenameConfig, base FilenamePolicy) (map[string]FilenamePolicy, error) {
+	resolved := make(map[models.FilenameConfig]FilenamePolicy)
+	policies := make(map[string]FilenamePolicy, len(filenames))
+
+	for id, config := range filenames {
+		policy, found := resolved[config]
+		if !found {
+			var err error
+			if policy, err = FilenamePolicyFromConfig(config, base); err != nil {
+				return nil, err
+			}
+
+			resolved[config] = policy
+		}
+
+		policies[id] = policy
+	}
+
+	return policies, nil
+}
+
+// Sanitize converts a title into a safe file name (without extension) according to the policy style.
+func (p FilenamePolicy) Sanitize(title string) string {
+	var name string
+
+	switch p.Style {
+	case FilenameStyleSlug:
+		name = Slugify(title)
+	case FilenameStylePreserve:
+		name = sanitizePreserving(title)
+	default:
+		name = SanitizeFilename(title)
+	}
+
+	name = p.applyWordRules(name)
+
+	if p.MaxLength > 0 {
+		name = truncateName(name, p.MaxLength)
+	}
+
+	return avoidReservedName(name)
+}
+
+// applyWordRules applies the ascii, lowercase and separator settings to a name produced by the style.
+func (p FilenamePolicy) applyWordRules(name string) string {
+	if p.ASCII && p.Style != FilenameStyleSlug {
+		name = toASCII(name)
+	}
+
+	if p.Lowercase {
+		name = strings.ToLower(name)
+	}
+
+	separator := "-"
+	if p.Style == FilenameStylePreserve {
+		separator = " "
+	}
+
+	if p.Separator != "" {
+		name = strings.ReplaceAll(name, separator, p.Separator)
+		separator = p.Separator
+	}
+
+	// Dropped characters may leave separators doubled or at the ends
+	if p.ASCII {
+		for strings.Contains(name, separator+separator) {
+			name = strings.ReplaceAll(name, separator+separator, separator)
+		}
+
+		name = strings.Trim(name, separator)
+	}
+
+	if strings.Trim(name, "-_ ") == "" {
+		return p.applyWordRules(safeFilename)
+	}
+
+	return name
+}
+
+// ThreadSubject turns an email thread subject into a file name: reply and forward prefixes are removed, and
+// subjects that leave nothing recognizable are named after the thread ID.
+func (p FilenamePolicy) ThreadSubject(subject, threadID string) string {
+	if subject == "" {
+		if threadID != "" {
+			return p.Sanitize("email-thread-" + SanitizeFilename(threadID))
+		}
+
+		return p.Sanitize("email-thread")
+	}
+
+	// Clean up subject line (remove Re:, Fwd:, etc.)
+	cleaned := cleanEmailSubject(subject)
+	if cleaned == "" {
+		cleaned = subject // Fallback to original if extraction fails
+	}
+
+	sanitized := p.Sanitize(cleaned)
+
+	// If sanitization results in a generic name and we have a thread ID, append it
+	if threadID != "" {
+		for _, generic := range []string{safeFilename, "default-filename", "email-thread"} {
+			if sanitized == p.Sanitize(generic) {
+				return p.Sanitize(generic + "-" + SanitizeFilename(threadID))
+			}
+		}
+	}
+
+	return sanitized
+}
+
+// toASCII transliterates accented and special letters to ASCII and drops characters without an ASCII form.
+func toASCII(name string) string {
+	var sb strings.Builder
+
+	for _, r := range norm.NFD.String(name) {
+		if replacement, ok := transliterations[r]; ok {
+			sb.WriteString(replacement)
+
+			continue
+		}
+
+		if r < utf8.RuneSelf {
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String()
+}
+
+// Slugify transliterates a title to lowercase ASCII words joined by hyphens ("Café Résumé" -> "cafe-resume").
+func Slugify(title string) string {
+	var sb strings.Builder
+
+	prevWasHyphen := true // Suppress leading hyphens
+
+	for _, r := range norm.NFD.String(title) {
+		if replacement, ok := transliterations[r]; ok {
+			sb.WriteString(strings.ToLower(replacement))
+
+			prevWasHyphen = false
+
+			continue
+		}
+
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Combining accent left over from decomposition
+		case r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			sb.WriteRune(unicode.ToLower(r))
+
+			prevWasHyphen = false
+		case !prevWasHyphen:
+			sb.WriteRune('-')
+
+			prevWasHyphen = true
+		}
+	}
+
+	slug := strings.Trim(sb.String(), "-")
+	if slug == "" {
+		return safeFilename
+	}
+
+	return slug
+}
+
+// sanitizePreserving removes characters that are invalid in file names while keeping spaces and Unicode.
+func sanitizePreserving(title string) string {
+	replacer := strings.NewReplacer(
+		"/", "-", "\\", "-", ":", "-", "|", "-",
+		"*", "", "?", "", "\"", "", "<", "", ">", "",
+		"\n", " ", "\r", " ", "\t", " ", "\x00", "",
+	)
+
+	name := strings.Join(strings.Fields(replacer.Replace(title)), " ")
+
+	// Leading dots hide files and trailing dots/spaces are stripped by Windows
+	name = strings.TrimLeft(name, ".")
+	name = strings.TrimRight(name, ". ")
+
+	if name == "" {
+		return safeFilename
+	}
+
+	return name
+}
+
+// truncateName shortens a name to at most maxBytes without splitting a UTF-8 sequence.
+func truncateName(name string, maxBytes int) string {
+	if len(name) <= maxBytes {
+		return name
+	}
+
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(name[cut]) {
+		cut--
+	}
+
+	return strings.TrimRight(name[:cut], "- ._")
+}
+
+// avoidReservedName suffixes Windows device names such as CON or LPT1 so they can be created.
+func avoidReservedName(name string) string {
+	base, rest, hasExt := strings.Cut(name, ".")
+	if !windowsReservedNames[strings.ToUpper(base)] {
+		return name
+	}
+
+	if hasExt {
+		return base + "_." + rest
+	}
+
+	return base + "_"
+}
+
+// FilenameAllocator hands out unique file paths for one export run.
+type FilenameAllocator struct {
+	policy FilenamePolicy
+
+	// IDOf returns the item ID recorded in an existing file, or "" if unknown.
+	// Files recording a different ID are treated as taken; files without an ID are overwritten as before.
+	IDOf func(path string) string
+
+	// ItemPolicies overrides the policy for individual item IDs, e.g. with the filenames settings of the
+	// source that fetched them.
+	ItemPolicies map[string]FilenamePolicy
+
+	claimed map[string]string // Path -> item ID
+	located map[string]string // Item ID -> existing note declaring it, see LocateExisting
+}
+
+// NewFilenameAllocator creates an allocator for a single export run.
+func NewFilenameAllocator(policy FilenamePolicy) *FilenameAllocator {
+	return &FilenameAllocator{
+		policy:  policy,
+		claimed: make(map[string]string),
+	}
+}
+
+// Allocate returns the path for an item, resolving collisions with other items according to the policy.
+// Calling Allocate again for the same item ID returns the same path.
+func (a *FilenameAllocator) Allocate(dir, title, ext, id string) string {
+	if path, found := a.located[id]; found {
+		if owner, claimed := a.claimed[path]; !claimed || owner == id {
+			a.claimed[path] = id
+
+			return path
+		}
+	}
+
+	policy := a.policy
+	if override, found := a.ItemPolicies[id]; found {
+		policy = override
+	}
+
+	name := policy.Sanitize(title)
+	path := a.fitPath(policy, dir, name, ext, "")
+
+	if policy.Collision == CollisionOverwrite {
+		a.claimed[path] = id
+
+		return path
+	}
+
+	for attempt := 1; a.taken(path, id); attempt++ {
+		suffix := fmt.Sprintf("-%d", attempt)
+		if policy.Collision == CollisionID && attempt == 1 {
+			suffix = "-" + SanitizeFilename(id)
+		} else if policy.Collision == CollisionID {
+			suffix = fmt.Sprintf("-%s-%d", SanitizeFilename(id), attempt-1)
+		}
+
+		path = a.fitPath(policy, dir, name, ext, suffix)
+	}
+
+	a.claimed[path] = id
+
+	return path
+}
+
+// Claim reserves a known path for an item, e.g. the note it was written to by an earlier sync.
+func (a *FilenameAllocator) Claim(path, id string) {
+	a.claimed[path] = id
+}
+
+// LocateExisting scans root for files with the extension that declare an item ID, so Allocate keeps
+// returning a note's current path after the user renamed or moved it. Hidden directories and those named
+// in skipDirs are not scanned; when several files declare the same ID the first in lexical order wins.
+// idKey is the property holding the ID, see ReadDeclaredID.
+func (a *FilenameAllocator) LocateExisting(root, ext, idKey string, skipDirs ...string) {
+	a.located = make(map[string]string)
+
+	skip := make(map[string]bool, len(skipDirs))
+	for _, dir := range skipDirs {
+		skip[dir] = true
+	}
+
+	// Unreadable entries are skipped, leaving those items to be allocated by title
+	_ = filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if entry.IsDir() {
+			if path != root && (strings.HasPrefix(entry.Name(), ".") || skip[entry.Name()]) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !strings.EqualFold(filepath.Ext(path), ext) {
+			return nil
+		}
+
+		if id := readHeaderID(path, idKey); id != "" {
+			if _, found := a.located[id]; !found {
+				a.located[id] = path
+			}
+		}
+
+		return nil
+	})
+}
+
+func (a *FilenameAllocator) taken(path, id string) bool {
+	if owner, claimed := a.claimed[path]; claimed {
+		return owner != id
+	}
+
+	if a.IDOf == nil {
+		return false
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return false
+	}
+
+	existingID := a.IDOf(path)
+
+	return existingID != "" && existingID != id
+}
+
+// fitPath joins the parts, shortening the name with a hash when the file name would exceed
+// maxComponentLength or the absolute path the policy's MaxPathLength.
+func (a *FilenameAllocator) fitPath(policy FilenamePolicy, dir, name, ext, suffix string) string {
+	path := filepath.Join(dir, name+suffix+ext)
+
+	available := maxComponentLength - len(suffix) - len(ext)
+	if policy.MaxPathLength > 0 {
+		available = min(available, len(name)-(pathLength(path)-policy.MaxPathLength))
+	}
+
+	if len(name) <= available {
+		return path
+	}
+
+	available = max(available, minTruncatedLength+pathHashLength+1)
+
+	return filepath.Join(dir, shortenName(name, available)+suffix+ext)
+}
+
+// ReadDeclaredID returns the value of the first idKey property in a file, written as YAML ("id: ") or as a
+// Logseq/Dataview property ("id:: "). idKey is "id" unless property_names or a Logseq property prefix such as
+// "pkm-" changes it.
+func ReadDeclaredID(path, idKey string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "- ")
+
+		for _, marker := range []string{idKey + ":: ", idKey + ": "} {
+			if value, found := strings.CutPrefix(line, marker); found {
+				return strings.Trim(strings.TrimSpace(value), `"'`)
+			}
+		}
+	}
+
+	return ""
+}
+
+// readHeaderID is ReadDeclaredID limited to a file's frontmatter or leading property block, where targets
+// write the ID, so scanning a vault does not read every note in full. Only top-level keys match.
+func readHeaderID(path, idKey string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	frontmatter := false
+
+	for lineNum := 0; scanner.Scan(); lineNum++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+
+		if line == "---" {
+			if lineNum > 0 {
+				return ""
+			}
+
+			frontmatter = true
+
+			continue
+		}
+
+		property := strings.TrimPrefix(line, "- ")
+		for _, marker := range []string{idKey + ":: ", idKey + ": "} {
+			if value, found := strings.CutPrefix(property, marker); found {
+				return strings.Trim(strings.TrimSpace(value), `"'`)
+			}
+		}
+
+		// Without frontmatter only a title heading and the "key:: value" properties after it are read
+		if !frontmatter && line != "" && !strings.HasPrefix(line, "# ") && !strings.Contains(line, ":: ") {
+			return ""
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,210 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestFilenamePolicySanitize(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   FilenamePolicy
+		input    string
+		expected string
+	}{
+		{"hyphenated", DefaultFilenamePolicy(), "Weekly sync: Q1", "Weekly-sync-Q1"},
+		{"slug transliterates", FilenamePolicy{Style: FilenameStyleSlug}, "Café Résumé & Straße", "cafe-resume-strasse"},
+		{"slug drops non-latin", FilenamePolicy{Style: FilenameStyleSlug}, "会议", safeFilename},
+		{"preserve keeps unicode", FilenamePolicy{Style: FilenameStylePreserve}, "Réunion: équipe / Q1", "Réunion- équipe - Q1"},
+		{"preserve trims dots", FilenamePolicy{Style: FilenameStylePreserve}, ".hidden.", "hidden"},
+		{"reserved name", FilenamePolicy{Style: FilenameStylePreserve}, "CON", "CON_"},
+		{"reserved name with extension", FilenamePolicy{Style: FilenameStylePreserve}, "lpt1.txt", "lpt1_.txt"},
+		{"max length keeps utf8 intact", FilenamePolicy{Style: FilenameStylePreserve, MaxLength: 4}, "ééé", "éé"},
+		{"lowercase underscores", FilenamePolicy{Lowercase: true, Separator: "_"}, "Weekly sync: Q1", "weekly_sync_q1"},
+		{"ascii hyphenated", FilenamePolicy{ASCII: true}, "Café Straße 会议", "Cafe-Strasse"},
+		{"preserve with separator", FilenamePolicy{Style: FilenameStylePreserve, Separator: "_"}, "Réunion équipe", "Réunion_équipe"},
+		{"separator on truncation", FilenamePolicy{Separator: "_", MaxLength: 5}, "Plan the offsite", "Plan"},
+		{"nothing left after ascii", FilenamePolicy{ASCII: true, Separator: "_"}, "会议", "safe_filename"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Sanitize(tt.input); got != tt.expected {
+				t.Errorf("Sanitize(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFilenamePolicyThreadSubject(t *testing.T) {
+	policy := FilenamePolicy{Style: FilenameStyleSlug, Separator: "_", MaxLength: 12}
+
+	if got := policy.ThreadSubject("Re: Fwd: Budget review for Q3", "t1"); got != "budget_revie" {
+		t.Errorf("ThreadSubject() = %q, want %q", got, "budget_revie")
+	}
+
+	if got := policy.ThreadSubject("???", "abc123"); got != "safe_filenam" {
+		t.Errorf("ThreadSubject() of a symbol subject = %q", got)
+	}
+
+	if got := (FilenamePolicy{Separator: "_"}).ThreadSubject("???", "abc123"); got != "safe_filename_abc123" {
+		t.Errorf("ThreadSubject() of a symbol subject = %q, want the thread ID appended", got)
+	}
+
+	if got := (FilenamePolicy{Lowercase: true}).ThreadSubject("", "AB12"); got != "email-thread-ab12" {
+		t.Errorf("ThreadSubject() of an empty subject = %q", got)
+	}
+
+	// The default policy keeps the historical names
+	long := strings.Repeat("word ", 30)
+	if got := SanitizeThreadSubject("RE: "+long, "t1"); got != SanitizeFilename(long) {
+		t.Errorf("SanitizeThreadSubject() = %q, want %q", got, SanitizeFilename(long))
+	}
+}
+
+func TestFilenamePolicyFromConfig(t *testing.T) {
+	base := DefaultFilenamePolicy()
+	base.Lowercase = true
+
+	policy, err := FilenamePolicyFromConfig(models.FilenameConfig{Separator: "_", MaxLength: 40}, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := FilenamePolicy{Style: FilenameStyleHyphenated, Collision: CollisionSuffix, MaxLength: 40, Lowercase: true,
+		Separator: "_"}
+	if policy != want {
+		t.Errorf("FilenamePolicyFromConfig() = %+v, want %+v", policy, want)
+	}
+
+	if _, err := FilenamePolicyFromConfig(models.FilenameConfig{Separator: "+"}, base); err == nil {
+		t.Error("unsupported separator accepted")
+	}
+}
+
+func TestFilenameAllocatorItemPolicies(t *testing.T) {
+	dir := t.TempDir()
+
+	policies, err := ItemPolicies(map[string]models.FilenameConfig{"mail": {Style: FilenameStyleSlug}},
+		DefaultFilenamePolicy())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allocator := NewFilenameAllocator(DefaultFilenamePolicy())
+	allocator.ItemPolicies = policies
+
+	if got := filepath.Base(allocator.Allocate(dir, "Weekly Sync", ".md", "mail")); got != "weekly-sync.md" {
+		t.Errorf("item with an override = %s", got)
+	}
+
+	if got := filepath.Base(allocator.Allocate(dir, "Weekly Sync", ".md", "event")); got != "Weekly-Sync.md" {
+		t.Errorf("item without an override = %s", got)
+	}
+}
+
+func TestFilenameAllocatorCollisions(t *testing.T) {
+	dir := t.TempDir()
+
+	suffix := NewFilenameAllocator(DefaultFilenamePolicy())
+	first := suffix.Allocate(dir, "Standup", ".md", "a")
+	second := suffix.Allocate(dir, "Standup", ".md", "b")
+
+	if filepath.Base(first) != "Standup.md" || filepath.Base(second) != "Standup-1.md" {
+		t.Errorf("suffix collision got %s and %s", first, second)
+	}
+
+	if again := suffix.Allocate(dir, "Standup", ".md", "a"); again != first {
+		t.Errorf("same item should keep its path, got %s", again)
+	}
+
+	policy := DefaultFilenamePolicy()
+	policy.Collision = CollisionID
+	byID := NewFilenameAllocator(policy)
+	byID.Allocate(dir, "Standup", ".md", "a")
+
+	if got := filepath.Base(byID.Allocate(dir, "Standup", ".md", "b")); got != "Standup-b.md" {
+		t.Errorf("id collision got %s", got)
+	}
+
+	// Existing files recording another ID are not overwritten
+	if err := os.WriteFile(filepath.Join(dir, "Retro.md"), []byte("---\nid: other\n---\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	existing := NewFilenameAllocator(DefaultFilenamePolicy())
+	existing.IDOf = func(path string) string { return ReadDeclaredID(path, "id") }
+
+	if got := filepath.Base(existing.Allocate(dir, "Retro", ".md", "mine")); got != "Retro-1.md" {
+		t.Errorf("expected collision with existing file, got %s", got)
+	}
+
+	if got := filepath.Base(existing.Allocate(dir, "Retro", ".md", "other")); got != "Retro.md" {
+		t.Errorf("owner of existing file should reuse it, got %s", got)
+	}
+}
+
+func TestFilenameAllocatorMaxPathLength(t *testing.T) {
+	policy := DefaultFilenamePolicy()
+	policy.MaxPathLength = 40
+
+	dir := "/vault/notes"
+	path := NewFilenameAllocator(policy).Allocate(dir, strings.Repeat("long title ", 10), ".md", "1")
+
+	if len(path) > 40 {
+		t.Errorf("path %q exceeds limit (%d)", path, len(path))
+	}
+
+	if !strings.HasSuffix(path, ".md") {
+		t.Errorf("extension lost: %q", path)
+	}
+}
+
+func TestFilenameAllocatorLocateExisting(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"Archive/Renamed standup.md": "---\nattendees:\n  id: nested\nid: a\n---\n# Standup\n",
+		"Pages/Retro.md":             "# Retro\n\npkm-id:: b\nsource:: gmail\n",
+		"Pages/Body mention.md":      "Some text\n\nid: c\n",
+		".trash/Old standup.md":      "---\nid: a\n---\n",
+		"bak/Retro.md":               "pkm-id:: b\n",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	obsidian := NewFilenameAllocator(DefaultFilenamePolicy())
+	obsidian.LocateExisting(dir, ".md", "id")
+
+	if got := obsidian.Allocate(dir, "Standup", ".md", "a"); got != filepath.Join(dir, "Archive", "Renamed standup.md") {
+		t.Errorf("moved note not found by its frontmatter id, got %s", got)
+	}
+
+	if got := filepath.Base(obsidian.Allocate(dir, "Mention", ".md", "c")); got != "Mention.md" {
+		t.Errorf("id outside the header should be ignored, got %s", got)
+	}
+
+	if got := filepath.Base(obsidian.Allocate(dir, "Nested", ".md", "nested")); got != "Nested.md" {
+		t.Errorf("nested frontmatter keys should be ignored, got %s", got)
+	}
+
+	logseq := NewFilenameAllocator(DefaultFilenamePolicy())
+	logseq.LocateExisting(dir, ".md", "pkm-id", "bak")
+
+	if got := logseq.Allocate(dir, "Retro renamed", ".md", "b"); got != filepath.Join(dir, "Pages", "Retro.md") {
+		t.Errorf("renamed page not found by its id property, got %s", got)
+	}
+}
@@ -0,0 +1,244 @@
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"pkm-sync/pkg/models"
+	"pkm-sync/pkg/query"
+)
+
+// Subdirectory layouts for sync.subdir_format.
+const (
+	// SubdirYearMonth nests notes by year and month ("2025/01").
+	SubdirYearMonth = "yyyy/mm"
+	// SubdirYearMonthFlat groups notes by month in a single level ("2025-01").
+	SubdirYearMonthFlat = "yyyy-mm"
+	// SubdirSource groups notes by the source type that produced them ("gmail").
+	SubdirSource = "source"
+	// SubdirFlat writes all notes directly into the output directory.
+	SubdirFlat = "flat"
+)
+
+// AttachmentsFolderKey routes downloaded attachments when used as a key in item_folders.
+const AttachmentsFolderKey = "attachments"
+
+// FolderMetadataKey lets a source choose an item's folder (e.g. per calendar); it takes precedence over item_folders.
+// Targets route by it without writing it as a property.
+const FolderMetadataKey = models.MetadataOutputFolder
+
+// OutputLayout decides which directory inside the output directory an item is written to.
+type OutputLayout struct {
+	SubdirFormat string            // Date or source subdirectory, see ValidateSubdirFormat
+	Folders      map[string]string // Item type -> folder relative to the output directory
+	Routes       []FolderRoute     // Checked in order before any other folder choice
+}
+
+// FolderRoute sends items matching a query to a folder.
+type FolderRoute struct {
+	When   *query.Query
+	Folder string // Relative to the output directory
+}
+
+// ParseOutputLayout applies the subdir_format and item_folders keys of a target configuration onto defaults.
+func ParseOutputLayout(config map[string]interface{}, defaults OutputLayout) (OutputLayout, error) {
+	layout := defaults
+
+	if format, ok := config["subdir_format"].(string); ok {
+		if err := ValidateSubdirFormat(format); err != nil {
+			return layout, err
+		}
+
+		layout.SubdirFormat = format
+	}
+
+	if value, exists := config["item_folders"]; exists && value != nil {
+		folders, err := parseItemFolders(value)
+		if err != nil {
+			return layout, err
+		}
+
+		layout.Folders = folders
+	}
+
+	if value, exists := config["folder_routes"]; exists && value != nil {
+		routes, err := parseFolderRoutes(value)
+		if err != nil {
+			return layout, err
+		}
+
+		layout.Routes = routes
+	}
+
+	return layout, nil
+}
+
+// parseFolderRoutes compiles folder_routes entries, rejecting invalid queries and escaping folders.
+func parseFolderRoutes(value interface{}) ([]FolderRoute, error) {
+	var rules []models.FolderRouteConfig
+
+	switch v := value.(type) {
+	case []models.FolderRouteConfig:
+		rules = v
+	case []interface{}:
+		for i, entry := range v {
+			m, ok := entry.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("folder_routes[%d] must be a map with 'when' and 'folder', got %T", i, entry)
+			}
+
+			when, _ := m["when"].(string)
+			folder, _ := m["folder"].(string)
+			rules = append(rules, models.FolderRouteConfig{When: when, Folder: folder})
+		}
+	default:
+		return nil, fmt.Errorf("folder_routes must be a list of routes, got %T", value)
+	}
+
+	routes := make([]FolderRoute, 0, len(rules))
+
+	for i, rule := range rules {
+		q, err := query.Parse(rule.When)
+		if err != nil {
+			return nil, fmt.Errorf("folder_routes[%d].when: %w", i, err)
+		}
+
+		cleaned, ok := cleanFolder(rule.Folder)
+		if !ok || rule.Folder == "" {
+			return nil, fmt.Errorf("folder_routes[%d].folder must be a folder inside the output directory, got '%s'",
+				i, rule.Folder)
+		}
+
+		routes = append(routes, FolderRoute{When: q, Folder: cleaned})
+	}
+
+	return routes, nil
+}
+
+// ValidateSubdirFormat checks a subdir_format value; an empty format means flat.
+func ValidateSubdirFormat(format string) error {
+	switch format {
+	case "", SubdirYearMonth, SubdirYearMonthFlat, SubdirSource, SubdirFlat:
+		return nil
+	default:
+		return fmt.Errorf("unsupported subdir_format '%s': supported formats are 'yyyy/mm', 'yyyy-mm', 'source', 'flat'",
+			format)
+	}
+}
+
+// parseItemFolders reads an item type -> folder map, rejecting folders that would escape the output directory.
+func parseItemFolders(value interface{}) (map[string]string, error) {
+	folders := make(map[string]string)
+
+	switch v := value.(type) {
+	case map[string]string:
+		for itemType, folder := range v {
+			folders[itemType] = folder
+		}
+	case map[string]interface{}:
+		for itemType, folder := range v {
+			s, ok := folder.(string)
+			if !ok {
+				return nil, fmt.Errorf("item_folders.%s must be a string, got %T", itemType, folder)
+			}
+
+			folders[itemType] = s
+		}
+	default:
+		return nil, fmt.Errorf("item_folders must be a map of item types to folders, got %T", value)
+	}
+
+	for itemType, folder := range folders {
+		cleaned, ok := cleanFolder(folder)
+		if !ok {
+			return nil, fmt.Errorf("item_folders.%s must be a folder inside the output directory, got '%s'", itemType, folder)
+		}
+
+		folders[itemType] = cleaned
+	}
+
+	return folders, nil
+}
+
+// cleanFolder normalizes a relative folder, reporting false if it would escape the output directory.
+func cleanFolder(folder string) (string, bool) {
+	cleaned := filepath.Clean(filepath.FromSlash(folder))
+	if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	if cleaned == "." {
+		return "", true
+	}
+
+	return cleaned, true
+}
+
+// Folder returns the configured folder for a key, or fallback when none is set.
+func (l OutputLayout) Folder(key, fallback string) string {
+	if folder := l.Folders[key]; folder != "" {
+		return folder
+	}
+
+	return fallback
+}
+
+// ItemDir returns the directory an item is written to: its folder followed by its subdirectory.
+func (l OutputLayout) ItemDir(outputDir string, item models.ItemInterface) string {
+	return filepath.Join(outputDir, l.ItemFolder(item), ItemSubdir(l.SubdirFormat, item))
+}
+
+// ItemFolder returns the folder of the first matching route, else the folder chosen by the item's source,
+// else the folder for its item type. Its components are made safe with SafeRelativePath.
+func (l OutputLayout) ItemFolder(item models.ItemInterface) string {
+	for _, route := range l.Routes {
+		if route.When.MatchItem(item) {
+			return SafeRelativePath(route.Folder)
+		}
+	}
+
+	if folder, _ := models.Metadata(item.GetMetadata()).GetString(FolderMetadataKey); folder != "" {
+		if cleaned, safe := cleanFolder(folder); safe {
+			return SafeRelativePath(cleaned)
+		}
+	}
+
+	return SafeRelativePath(l.Folders[item.GetItemType()])
+}
+
+// ItemSubdir returns the subdirectory (relative to the output directory) an item belongs in.
+// Items missing the date or source needed by the layout are placed directly in the output directory.
+func ItemSubdir(format string, item models.ItemInterface) string {
+	switch format {
+	case SubdirYearMonth, SubdirYearMonthFlat:
+		date := itemDate(item)
+		if date.IsZero() {
+			return ""
+		}
+
+		if format == SubdirYearMonth {
+			return filepath.Join(date.Format("2006"), date.Format("01"))
+		}
+
+		return date.Format("2006-01")
+	case SubdirSource:
+		if item.GetSourceType() == "" {
+			return ""
+		}
+
+		return SanitizeFilename(item.GetSourceType())
+	default:
+		return ""
+	}
+}
+
+// itemDate returns the date an item is filed under: its creation time, falling back to its last update.
+func itemDate(item models.ItemInterface) time.Time {
+	if created := item.GetCreatedAt(); !created.IsZero() {
+		return created
+	}
+
+	return item.GetUpdatedAt()
+}
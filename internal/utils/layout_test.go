@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestItemSubdir(t *testing.T) {
+	dated := &models.BasicItem{
+		SourceType: "google_calendar",
+		CreatedAt:  time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC),
+	}
+	updatedOnly := &models.BasicItem{UpdatedAt: time.Date(2024, 12, 31, 23, 0, 0, 0, time.UTC)}
+	bare := &models.BasicItem{}
+
+	tests := []struct {
+		name     string
+		format   string
+		item     models.ItemInterface
+		expected string
+	}{
+		{"year and month", SubdirYearMonth, dated, filepath.Join("2025", "01")},
+		{"year-month", SubdirYearMonthFlat, dated, "2025-01"},
+		{"falls back to updated time", SubdirYearMonthFlat, updatedOnly, "2024-12"},
+		{"undated stays at root", SubdirYearMonth, bare, ""},
+		{"source", SubdirSource, dated, "google_calendar"},
+		{"missing source stays at root", SubdirSource, bare, ""},
+		{"flat", SubdirFlat, dated, ""},
+		{"unset", "", dated, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ItemSubdir(tt.format, tt.item); got != tt.expected {
+				t.Errorf("ItemSubdir(%q) = %q, want %q", tt.format, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateSubdirFormat(t *testing.T) {
+	for _, format := range []string{"", SubdirYearMonth, SubdirYearMonthFlat, SubdirSource, SubdirFlat} {
+		if err := ValidateSubdirFormat(format); err != nil {
+			t.Errorf("ValidateSubdirFormat(%q) returned error: %v", format, err)
+		}
+	}
+
+	if err := ValidateSubdirFormat("yyyy/mm/dd"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestOutputLayoutItemDir(t *testing.T) {
+	layout, err := ParseOutputLayout(map[string]interface{}{
+		"subdir_format": SubdirYearMonthFlat,
+		"item_folders": map[string]interface{}{
+			"email":        "Mail",
+			"email_thread": "Mail/Threads",
+			"attachments":  "Files",
+		},
+	}, OutputLayout{})
+	if err != nil {
+		t.Fatalf("ParseOutputLayout() error = %v", err)
+	}
+
+	created := time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		itemType string
+		expected string
+	}{
+		{"email", filepath.Join("out", "Mail", "2025-02")},
+		{"email_thread", filepath.Join("out", "Mail", "Threads", "2025-02")},
+		{"event", filepath.Join("out", "2025-02")},
+	}
+
+	for _, tt := range tests {
+		item := &models.BasicItem{ItemType: tt.itemType, CreatedAt: created}
+		if got := layout.ItemDir("out", item); got != tt.expected {
+			t.Errorf("ItemDir(%s) = %q, want %q", tt.itemType, got, tt.expected)
+		}
+	}
+
+	if got := layout.Folder(AttachmentsFolderKey, "Attachments"); got != "Files" {
+		t.Errorf("Folder(attachments) = %q, want %q", got, "Files")
+	}
+
+	if got := layout.Folder("document", "Docs"); got != "Docs" {
+		t.Errorf("Folder(document) = %q, want fallback %q", got, "Docs")
+	}
+}
+
+func TestParseOutputLayoutRejectsEscapingFolders(t *testing.T) {
+	for _, folder := range []string{"../outside", "/etc"} {
+		config := map[string]interface{}{"item_folders": map[string]string{"email": folder}}
+		if _, err := ParseOutputLayout(config, OutputLayout{}); err == nil {
+			t.Errorf("expected error for folder %q", folder)
+		}
+	}
+}
+
+func TestOutputLayoutItemFolderPrefersSourceFolder(t *testing.T) {
+	layout := OutputLayout{Folders: map[string]string{"event": "Meetings"}}
+
+	item := &models.BasicItem{ItemType: "event", Metadata: map[string]interface{}{FolderMetadataKey: "Team/Calendar"}}
+	if got := layout.ItemFolder(item); got != filepath.Join("Team", "Calendar") {
+		t.Errorf("ItemFolder() = %q, want source folder", got)
+	}
+
+	item.Metadata[FolderMetadataKey] = "../escape"
+	if got := layout.ItemFolder(item); got != "Meetings" {
+		t.Errorf("ItemFolder() = %q, want item type folder for unsafe source folder", got)
+	}
+
+	// A folder property, such as an Apple Note's, is not a routing folder
+	item.Metadata = map[string]interface{}{models.MetadataFolder: "Home"}
+	if got := layout.ItemFolder(item); got != "Meetings" {
+		t.Errorf("ItemFolder() = %q, want item type folder for a folder property", got)
+	}
+}
+
+func TestOutputLayoutFolderRoutes(t *testing.T) {
+	config := map[string]interface{}{
+		"item_folders": map[string]string{"email": "Mail"},
+		"folder_routes": []interface{}{
+			map[string]interface{}{"when": `from ~ "@company.com"`, "folder": "Work/Mail"},
+			map[string]interface{}{"when": "tag = personal", "folder": "Personal"},
+		},
+	}
+
+	layout, err := ParseOutputLayout(config, OutputLayout{})
+	if err != nil {
+		t.Fatalf("ParseOutputLayout() error = %v", err)
+	}
+
+	item := &models.BasicItem{ItemType: "email", Metadata: map[string]interface{}{"from": "boss@company.com"}}
+	if got := layout.ItemFolder(item); got != filepath.Join("Work", "Mail") {
+		t.Errorf("ItemFolder() = %q, want first matching route", got)
+	}
+
+	item.Metadata["from"] = "friend@example.com"
+	if got := layout.ItemFolder(item); got != "Mail" {
+		t.Errorf("ItemFolder() = %q, want item type folder when no route matches", got)
+	}
+
+	for _, routes := range []interface{}{
+		[]interface{}{map[string]interface{}{"when": "tag = (", "folder": "Broken"}},
+		[]interface{}{map[string]interface{}{"when": "tag = a", "folder": "../outside"}},
+		"not a list",
+	} {
+		if _, err := ParseOutputLayout(map[string]interface{}{"folder_routes": routes}, OutputLayout{}); err == nil {
+			t.Errorf("expected error for folder_routes %v", routes)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	// windowsMaxPath is the classic Windows MAX_PATH. Go writes longer paths itself, but Explorer, Obsidian
+	// plugins and many sync clients still fail beyond it.
+	windowsMaxPath = 260
+	// maxComponentLength is the longest file or folder name, in bytes, that common filesystems accept.
+	maxComponentLength = 255
+	// pathHashLength is the number of hex digits of the hash that keeps shortened names distinct.
+	pathHashLength = 8
+)
+
+// DefaultMaxPathLength returns the path length limit of the running OS: MAX_PATH on Windows, none elsewhere.
+func DefaultMaxPathLength() int {
+	if runtime.GOOS == "windows" {
+		return windowsMaxPath
+	}
+
+	return 0
+}
+
+// shortenName shortens a name to at most maxBytes, ending it with a hash of the full name so two long
+// names sharing a prefix stay distinct and a name always shortens the same way.
+func shortenName(name string, maxBytes int) string {
+	if len(name) <= maxBytes {
+		return name
+	}
+
+	sum := sha256.Sum256([]byte(name))
+	hash := hex.EncodeToString(sum[:])[:pathHashLength]
+
+	prefix := truncateName(name, maxBytes-pathHashLength-1)
+	if prefix == "" {
+		return hash
+	}
+
+	return prefix + "-" + hash
+}
+
+// SafePathComponent makes one folder or file name usable on every OS: Windows device names such as CON are
+// suffixed, trailing dots and spaces (which Windows strips) are removed and names longer than
+// maxComponentLength are shortened with a hash.
+func SafePathComponent(name string) string {
+	if name == "." || name == ".." {
+		return name
+	}
+
+	name = strings.TrimRight(name, ". ")
+	if name == "" {
+		return safeFilename
+	}
+
+	return shortenName(avoidReservedName(name), maxComponentLength)
+}
+
+// SafeRelativePath applies SafePathComponent to every component of a relative path.
+func SafeRelativePath(rel string) string {
+	if rel == "" {
+		return ""
+	}
+
+	components := strings.Split(filepath.ToSlash(rel), "/")
+	for i, component := range components {
+		if component != "" {
+			components[i] = SafePathComponent(component)
+		}
+	}
+
+	return filepath.FromSlash(strings.Join(components, "/"))
+}
+
+// pathLength returns the length a path has once made absolute, which is what MAX_PATH limits.
+func pathLength(path string) int {
+	if abs, err := filepath.Abs(path); err == nil {
+		return len(abs)
+	}
+
+	return len(path)
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestSafeRelativePath(t *testing.T) {
+	long := strings.Repeat("a", 300)
+
+	tests := []struct {
+		name     string
+		rel      string
+		expected string
+	}{
+		{"empty", "", ""},
+		{"unchanged", "Work/Mail", filepath.Join("Work", "Mail")},
+		{"reserved folder", "Projects/CON/aux.notes", filepath.Join("Projects", "CON_", "aux_.notes")},
+		{"trailing dots and spaces", "Drafts. /Q1 ", filepath.Join("Drafts", "Q1")},
+		{"long component", "Mail/" + long, filepath.Join("Mail", shortenName(long, maxComponentLength))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SafeRelativePath(tt.rel); got != tt.expected {
+				t.Errorf("SafeRelativePath(%q) = %q, want %q", tt.rel, got, tt.expected)
+			}
+		})
+	}
+
+	if got := len(shortenName(long, maxComponentLength)); got != maxComponentLength {
+		t.Errorf("shortened component is %d bytes, want %d", got, maxComponentLength)
+	}
+}
+
+func TestShortenNameKeepsLongNamesDistinct(t *testing.T) {
+	prefix := strings.Repeat("Quarterly planning ", 5)
+
+	first, second := shortenName(prefix+"Berlin", 40), shortenName(prefix+"Munich", 40)
+	if first == second {
+		t.Errorf("names sharing a prefix collide: %q", first)
+	}
+
+	if len(first) > 40 || !strings.HasPrefix(first, "Quarterly planning") {
+		t.Errorf("shortenName() = %q", first)
+	}
+
+	if again := shortenName(prefix+"Berlin", 40); again != first {
+		t.Errorf("shortenName() is not stable: %q, then %q", first, again)
+	}
+}
+
+func TestFilenameAllocatorFitsLongNames(t *testing.T) {
+	policy := DefaultFilenamePolicy()
+	policy.Style = FilenameStylePreserve
+	policy.MaxLength = 0
+	policy.MaxPathLength = 0
+
+	// File names stay within the filesystem limit even without a path limit
+	allocator := NewFilenameAllocator(policy)
+	title := strings.Repeat("Weekly sync notes ", 20)
+
+	path := allocator.Allocate("/vault", title+"one", ".md", "1")
+	if base := filepath.Base(path); len(base) > maxComponentLength || !strings.HasSuffix(base, ".md") {
+		t.Errorf("file name %q is %d bytes", base, len(base))
+	}
+
+	if other := allocator.Allocate("/vault", title+"two", ".md", "2"); other == path {
+		t.Errorf("long titles sharing a prefix map to the same file %q", path)
+	}
+}
+
+func TestParseFilenamePolicyMaxPathLength(t *testing.T) {
+	defaults := DefaultFilenamePolicy()
+	defaults.MaxPathLength = windowsMaxPath
+
+	policy, err := ParseFilenamePolicy(map[string]interface{}{"max_path_length": -1}, defaults)
+	if err != nil || policy.MaxPathLength != 0 {
+		t.Errorf("negative max_path_length = %d, %v, want disabled", policy.MaxPathLength, err)
+	}
+
+	if policy, _ := ParseFilenamePolicy(map[string]interface{}{"max_path_length": 0}, defaults); policy.MaxPathLength != windowsMaxPath {
+		t.Errorf("unset max_path_length = %d, want the default", policy.MaxPathLength)
+	}
+}
+
+func TestItemFolderAvoidsReservedNames(t *testing.T) {
+	layout := OutputLayout{Folders: map[string]string{"email": "Mail/PRN"}}
+
+	item := models.NewBasicItem("1", "Hello")
+	item.SetItemType("email")
+
+	if got := layout.ItemFolder(item); got != filepath.Join("Mail", "PRN_") {
+		t.Errorf("ItemFolder() = %q, want reserved name suffixed", got)
+	}
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// EscapeMarkdown escapes characters in literal text that markdown would otherwise read as formatting:
+// backslashes, backticks, emphasis markers, brackets, "~~" and "#" where it would start a heading or an
+// Obsidian tag. Underscores inside words are left alone since they never start emphasis.
+func EscapeMarkdown(text string) string {
+	runes := []rune(text)
+
+	var sb strings.Builder
+
+	sb.Grow(len(text))
+
+	for i, r := range runes {
+		var prev, next rune
+		if i > 0 {
+			prev = runes[i-1]
+		}
+
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		if needsEscape(r, prev, next, i == 0) {
+			sb.WriteRune('\\')
+		}
+
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
+
+func needsEscape(r, prev, next rune, first bool) bool {
+	switch r {
+	case '\\', '`', '*', '[', ']':
+		return true
+	case '_':
+		return !isWordRune(prev) || !isWordRune(next)
+	case '~':
+		return next == '~' || prev == '~'
+	case '#':
+		// "# Heading" at the start of a line, "#tag" after a space
+		return first || prev == '\n' || (unicode.IsSpace(prev) && (unicode.IsLetter(next) || next == '#'))
+	}
+
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// EscapeTableCell makes text safe inside a markdown table cell: pipes are escaped and line breaks, which
+// would end the row, become <br>.
+func EscapeTableCell(text string) string {
+	var lines []string
+
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	// Pipes already escaped by the text's author are not escaped twice
+	cell := strings.ReplaceAll(strings.Join(lines, "<br>"), `\|`, "|")
+
+	return strings.ReplaceAll(cell, "|", `\|`)
+}
@@ -0,0 +1,47 @@
+package utils
+
+import "testing"
+
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Weekly report", "Weekly report"},
+		{"*** SALE *** 50% off", `\*\*\* SALE \*\*\* 50% off`},
+		{"[External] Re: budget", `\[External\] Re: budget`},
+		{"snake_case_name", "snake_case_name"},
+		{"_underlined_", `\_underlined\_`},
+		{"# not a heading", `\# not a heading`},
+		{"Issue #123 and #urgent", `Issue #123 and \#urgent`},
+		{"C# tips", "C# tips"},
+		{"use `make`", "use \\`make\\`"},
+		{"~~gone~~ ~ approx", `\~\~gone\~\~ ~ approx`},
+		{`C:\path`, `C:\\path`},
+		{"a | b", "a | b"},
+	}
+
+	for _, tt := range tests {
+		if got := EscapeMarkdown(tt.input); got != tt.want {
+			t.Errorf("EscapeMarkdown(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestEscapeTableCell(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Alice", "Alice"},
+		{"in | out", `in \| out`},
+		{`already \| escaped`, `already \| escaped`},
+		{"line one\n\n  line two\n", "line one<br>line two"},
+	}
+
+	for _, tt := range tests {
+		if got := EscapeTableCell(tt.input); got != tt.want {
+			t.Errorf("EscapeTableCell(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"pkm-sync/pkg/models"
+)
+
+// meetingURLChars matches the rest of a join link, up to whitespace, quotes, brackets or markup.
+const meetingURLChars = `[^\s<>"'()\[\]]+`
+
+// MeetingProviderOther names the provider of a join link that no provider recognizes, such as one set by
+// the calendar's own conferencing data.
+const MeetingProviderOther = "other"
+
+// MeetingProvider recognizes the join links of a video meeting service.
+type MeetingProvider struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// DefaultMeetingProviders are the video meeting services recognized by every source.
+var DefaultMeetingProviders = []MeetingProvider{
+	{"google_meet", regexp.MustCompile(`https://meet\.google\.com/[a-z0-9-]+`)},
+	{"zoom", regexp.MustCompile(`https://(?:[\w-]+\.)*zoom(?:gov)?\.(?:us|com)/(?:j|my|s|w|wc)/` + meetingURLChars)},
+	{"teams", regexp.MustCompile(`https://teams\.(?:microsoft|live)\.com/(?:l/meetup-join|meet)/` + meetingURLChars)},
+	{"webex", regexp.MustCompile(`https://(?:[\w-]+\.)+webex\.com/` + meetingURLChars)},
+	{"whereby", regexp.MustCompile(`https://(?:[\w-]+\.)?whereby\.com/[\w-]+`)},
+}
+
+// ParseMeetingProviders compiles a source's meeting_providers, which are tried before the defaults so
+// they can recognize links of other services or override a default's name.
+func ParseMeetingProviders(configs []models.MeetingProviderConfig) ([]MeetingProvider, error) {
+	if len(configs) == 0 {
+		return DefaultMeetingProviders, nil
+	}
+
+	providers := make([]MeetingProvider, 0, len(configs)+len(DefaultMeetingProviders))
+
+	for i, config := range configs {
+		if config.Name == "" {
+			return nil, fmt.Errorf("meeting_providers[%d]: name is required", i)
+		}
+
+		if config.Pattern == "" {
+			return nil, fmt.Errorf("meeting_providers[%d] '%s': pattern is required", i, config.Name)
+		}
+
+		pattern, err := regexp.Compile(config.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("meeting_providers[%d] '%s': invalid pattern: %w", i, config.Name, err)
+		}
+
+		providers = append(providers, MeetingProvider{Name: config.Name, Pattern: pattern})
+	}
+
+	return append(providers, DefaultMeetingProviders...), nil
+}
+
+// FindMeetingURL returns the first join link found in texts, such as an event's location and description,
+// with the name of its provider. Texts are searched in order, each with every provider in order.
+func FindMeetingURL(providers []MeetingProvider, texts ...string) (provider, url string) {
+	for _, text := range texts {
+		for _, p := range providers {
+			if match := p.Pattern.FindString(text); match != "" {
+				return p.Name, strings.TrimRight(html.UnescapeString(match), ".,;:!?")
+			}
+		}
+	}
+
+	return "", ""
+}
+
+// MeetingProviderOf returns the name of the provider recognizing a join link, or MeetingProviderOther.
+func MeetingProviderOf(providers []MeetingProvider, url string) string {
+	for _, p := range providers {
+		if p.Pattern.MatchString(url) {
+			return p.Name
+		}
+	}
+
+	return MeetingProviderOther
+}
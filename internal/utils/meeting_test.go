@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestFindMeetingURL(t *testing.T) {
+	providers, err := ParseMeetingProviders([]models.MeetingProviderConfig{
+		{Name: "jitsi", Pattern: `https://meet\.jit\.si/\S+`},
+	})
+	if err != nil {
+		t.Fatalf("ParseMeetingProviders() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		texts        []string
+		wantProvider string
+		wantURL      string
+	}{
+		{"google meet", []string{"", "Join at https://meet.google.com/abc-defg-hij."}, "google_meet",
+			"https://meet.google.com/abc-defg-hij"},
+		{"zoom vanity domain", []string{"https://acme.zoom.us/j/123456789?pwd=abc"}, "zoom",
+			"https://acme.zoom.us/j/123456789?pwd=abc"},
+		{"teams in HTML",
+			[]string{`<a href="https://teams.microsoft.com/l/meetup-join/19%3ameeting_x/0?a=1&amp;b=2">Join</a>`},
+			"teams", "https://teams.microsoft.com/l/meetup-join/19%3ameeting_x/0?a=1&b=2"},
+		{"webex", []string{"(https://acme.webex.com/meet/jdoe)"}, "webex", "https://acme.webex.com/meet/jdoe"},
+		{"whereby", []string{"https://whereby.com/team-standup"}, "whereby", "https://whereby.com/team-standup"},
+		{"configured provider", []string{"Room: https://meet.jit.si/TeamSync"}, "jitsi", "https://meet.jit.si/TeamSync"},
+		{"location before description", []string{"https://whereby.com/room", "https://meet.google.com/abc-defg-hij"},
+			"whereby", "https://whereby.com/room"},
+		{"none", []string{"Room 4.01", "https://example.com/agenda"}, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, url := FindMeetingURL(providers, tt.texts...)
+			if provider != tt.wantProvider || url != tt.wantURL {
+				t.Errorf("FindMeetingURL() = %q, %q, want %q, %q", provider, url, tt.wantProvider, tt.wantURL)
+			}
+		})
+	}
+
+	if got := MeetingProviderOf(providers, "https://example.com/call"); got != MeetingProviderOther {
+		t.Errorf("MeetingProviderOf() of an unknown link = %q, want %q", got, MeetingProviderOther)
+	}
+}
+
+func TestParseMeetingProvidersErrors(t *testing.T) {
+	for _, configs := range [][]models.MeetingProviderConfig{
+		{{Pattern: `https://meet\.jit\.si/\S+`}},
+		{{Name: "jitsi"}},
+		{{Name: "jitsi", Pattern: `https://(`}},
+	} {
+		if _, err := ParseMeetingProviders(configs); err == nil {
+			t.Errorf("ParseMeetingProviders(%+v) error = nil", configs)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// StandardProperties are the properties targets write for every item, which property_names can rename.
+var StandardProperties = []string{"id", "source", "type", "created", "message_count", "tags"}
+
+// PropertyNames maps standard properties to the names a vault uses for them, e.g. created -> date-created.
+type PropertyNames map[string]string
+
+// Name returns the name a standard property is written as: its property_names entry, else the property itself.
+func (p PropertyNames) Name(property string) string {
+	if name := p[property]; name != "" {
+		return name
+	}
+
+	return property
+}
+
+// ParsePropertyNames reads a target's property_names option. Only standard properties can be renamed, and
+// no two may end up with the same name, so synced notes can still be read back.
+func ParsePropertyNames(value interface{}) (PropertyNames, error) {
+	raw := make(map[string]interface{})
+
+	switch v := value.(type) {
+	case map[string]string:
+		for key, name := range v {
+			raw[key] = name
+		}
+	case map[string]interface{}:
+		raw = v
+	default:
+		return nil, fmt.Errorf("property_names must be a map of properties to names, got %T", value)
+	}
+
+	names := make(PropertyNames, len(raw))
+
+	for key, value := range raw {
+		name, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("property_names.%s must be a string, got %T", key, value)
+		}
+
+		key, name = strings.TrimSpace(key), strings.TrimSpace(name)
+
+		if !slices.Contains(StandardProperties, key) {
+			return nil, fmt.Errorf("property_names: unknown property '%s', expected one of %s",
+				key, strings.Join(StandardProperties, ", "))
+		}
+
+		if name == "" || strings.ContainsAny(name, ": \t\n#") {
+			return nil, fmt.Errorf("property_names.%s: invalid name '%s': names must not be empty or contain "+
+				"spaces, ':' or '#'", key, name)
+		}
+
+		names[key] = name
+	}
+
+	for _, property := range StandardProperties {
+		for _, other := range StandardProperties {
+			if property < other && names.Name(property) == names.Name(other) {
+				return nil, fmt.Errorf("property_names: '%s' and '%s' would both be written as '%s'",
+					property, other, names.Name(property))
+			}
+		}
+	}
+
+	return names, nil
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePropertyNames(t *testing.T) {
+	names, err := ParsePropertyNames(map[string]interface{}{"created": "date-created", "source": "origin"})
+	if err != nil {
+		t.Fatalf("ParsePropertyNames() error = %v", err)
+	}
+
+	for property, want := range map[string]string{"created": "date-created", "source": "origin", "id": "id"} {
+		if got := names.Name(property); got != want {
+			t.Errorf("Name(%q) = %q, want %q", property, got, want)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"unknown property", map[string]interface{}{"subject": "title"}, "unknown property 'subject'"},
+		{"not a string", map[string]interface{}{"id": 1}, "property_names.id must be a string"},
+		{"invalid name", map[string]interface{}{"created": "date created"}, "invalid name"},
+		{"collision", map[string]interface{}{"source": "type"}, "would both be written as 'type'"},
+		{"not a map", []string{"id"}, "must be a map"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParsePropertyNames(tt.value); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParsePropertyNames() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
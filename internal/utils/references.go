@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"encoding/base64"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"pkm-sync/pkg/models"
+)
+
+// Reference key prefixes. Keys identify what an item is; references are keys an item points to.
+const (
+	EventKeyPrefix   = "event:"
+	DriveKeyPrefix   = "drive:"
+	MessageKeyPrefix = "message:"
+)
+
+var (
+	referenceURLRegex = regexp.MustCompile(`https?://[^\s<>"'()\[\]]+`)
+	driveFileRegex    = regexp.MustCompile(`(?:docs|drive)\.google\.com/(?:document|spreadsheets|presentation|forms|file)/d/([\w-]+)`)
+	gmailMessageRegex = regexp.MustCompile(`mail\.google\.com/mail/[^#\s]*#[^/\s]+/([\w-]+)`)
+)
+
+// ItemReferenceKeys returns the keys other items can use to reference an item: its event ID, Gmail and
+// RFC 822 message IDs, and the Drive file IDs of Drive files and attachments.
+func ItemReferenceKeys(item models.ItemInterface) []string {
+	keys := make(map[string]bool)
+	metadata := models.Metadata(item.GetMetadata())
+
+	if _, isEvent := metadata[models.MetadataStartTime]; isEvent || item.GetItemType() == "event" {
+		keys[EventKeyPrefix+item.GetID()] = true
+	}
+
+	if item.GetSourceType() == "gmail" {
+		keys[MessageKeyPrefix+item.GetID()] = true
+
+		if messageID, _ := metadata.GetString(models.MetadataMessageID); messageID != "" {
+			keys[MessageKeyPrefix+strings.Trim(messageID, "<> ")] = true
+		}
+	}
+
+	if item.GetSourceType() == "google_drive" {
+		keys[DriveKeyPrefix+item.GetID()] = true
+	}
+
+	for _, attachment := range item.GetAttachments() {
+		if id := driveFileID(attachment.URL); id != "" {
+			keys[DriveKeyPrefix+id] = true
+		} else if attachment.ID != "" && strings.Contains(attachment.URL, "google.com") {
+			keys[DriveKeyPrefix+attachment.ID] = true
+		}
+	}
+
+	if thread, ok := models.AsThread(item); ok {
+		for _, message := range thread.GetMessages() {
+			for _, key := range ItemReferenceKeys(message) {
+				keys[key] = true
+			}
+		}
+	}
+
+	return sortedKeys(keys)
+}
+
+// ExtractReferences returns the keys of calendar invites, Drive files and Gmail messages an item links to,
+// found in its content and links.
+func ExtractReferences(item models.ItemInterface) []string {
+	refs := make(map[string]bool)
+
+	urls := referenceURLRegex.FindAllString(item.GetContent(), -1)
+	for _, link := range item.GetLinks() {
+		urls = append(urls, link.URL)
+	}
+
+	for _, rawURL := range urls {
+		if id := driveFileID(rawURL); id != "" {
+			refs[DriveKeyPrefix+id] = true
+		}
+
+		if id := calendarEventID(rawURL); id != "" {
+			refs[EventKeyPrefix+id] = true
+		}
+
+		if match := gmailMessageRegex.FindStringSubmatch(rawURL); match != nil {
+			refs[MessageKeyPrefix+match[1]] = true
+		}
+	}
+
+	if thread, ok := models.AsThread(item); ok {
+		for _, message := range thread.GetMessages() {
+			for _, ref := range ExtractReferences(message) {
+				refs[ref] = true
+			}
+		}
+	}
+
+	return sortedKeys(refs)
+}
+
+// driveFileID returns the file ID of a Google Docs or Drive URL.
+func driveFileID(rawURL string) string {
+	if match := driveFileRegex.FindStringSubmatch(rawURL); match != nil {
+		return match[1]
+	}
+
+	if parsed, err := url.Parse(rawURL); err == nil && parsed.Host == "drive.google.com" && parsed.Path == "/open" {
+		return parsed.Query().Get("id")
+	}
+
+	return ""
+}
+
+// calendarEventID decodes the event ID of a Google Calendar invite URL (calendar.google.com or
+// www.google.com/calendar). The eid parameter is the base64-encoded "<event ID> <calendar ID>".
+func calendarEventID(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	onGoogle := parsed.Host == "www.google.com" || parsed.Host == "google.com"
+	if parsed.Host != "calendar.google.com" && !(onGoogle && strings.HasPrefix(parsed.Path, "/calendar/")) {
+		return ""
+	}
+
+	eid := parsed.Query().Get("eid")
+	if eid == "" {
+		return ""
+	}
+
+	for _, encoding := range []*base64.Encoding{base64.RawURLEncoding, base64.URLEncoding, base64.RawStdEncoding,
+		base64.StdEncoding} {
+		if decoded, err := encoding.DecodeString(eid); err == nil {
+			if fields := strings.Fields(string(decoded)); len(fields) > 0 {
+				return fields[0]
+			}
+		}
+	}
+
+	return ""
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
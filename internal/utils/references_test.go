@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestExtractReferences(t *testing.T) {
+	eid := base64.RawURLEncoding.EncodeToString([]byte("evt123_20250113T090000Z me@example.com"))
+
+	item := models.NewBasicItem("msg-1", "Invitation: Planning")
+	item.SetSourceType("gmail")
+	item.SetContent("Join: https://www.google.com/calendar/event?eid=" + eid + "\n" +
+		"Agenda: https://docs.google.com/document/d/doc-42/edit?usp=sharing")
+	item.SetLinks([]models.Link{{URL: "https://mail.google.com/mail/u/0/#inbox/18c2f0a1b", Title: "Earlier"}})
+
+	want := []string{"drive:doc-42", "event:evt123_20250113T090000Z", "message:18c2f0a1b"}
+	if got := ExtractReferences(item); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractReferences() = %v, want %v", got, want)
+	}
+}
+
+func TestItemReferenceKeys(t *testing.T) {
+	event := models.NewBasicItem("evt123", "Planning")
+	event.SetItemType("event")
+	event.SetAttachments([]models.Attachment{
+		{ID: "doc-42", URL: "https://drive.google.com/file/d/doc-42/view"},
+	})
+
+	want := []string{"drive:doc-42", "event:evt123"}
+	if got := ItemReferenceKeys(event); !reflect.DeepEqual(got, want) {
+		t.Errorf("ItemReferenceKeys() = %v, want %v", got, want)
+	}
+
+	email := models.NewBasicItem("18c2f0a1b", "Notes")
+	email.SetSourceType("gmail")
+	email.SetMetadata(map[string]interface{}{"message_id": "<abc@mail.example.com>"})
+
+	want = []string{"message:18c2f0a1b", "message:abc@mail.example.com"}
+	if got := ItemReferenceKeys(email); !reflect.DeepEqual(got, want) {
+		t.Errorf("ItemReferenceKeys() = %v, want %v", got, want)
+	}
+
+	file := models.NewBasicItem("doc-42", "Roadmap")
+	file.SetSourceType("google_drive")
+	file.SetItemType("document")
+
+	want = []string{"drive:doc-42"}
+	if got := ItemReferenceKeys(file); !reflect.DeepEqual(got, want) {
+		t.Errorf("ItemReferenceKeys() = %v, want %v", got, want)
+	}
+}
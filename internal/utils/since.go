@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sinceUnits are the calendar units of relative since expressions such as "2w": days, weeks, months and
+// years. "m" is months; minutes are written as Go durations like "1h30m".
+var sinceUnits = map[byte]func(t time.Time, n int) time.Time{
+	'd': func(t time.Time, n int) time.Time { return t.AddDate(0, 0, -n) },
+	'w': func(t time.Time, n int) time.Time { return t.AddDate(0, 0, -7*n) },
+	'm': func(t time.Time, n int) time.Time { return t.AddDate(0, -n, 0) },
+	'y': func(t time.Time, n int) time.Time { return t.AddDate(-n, 0, 0) },
+}
+
+// ParseSince parses the start of a sync window, as given to --since and the since fields of the config:
+//
+//	today, yesterday       the start of the day (UTC)
+//	last monday            the start of the most recent Monday before today (any weekday)
+//	7d, 2w, 3m, 1y         days, weeks, months or years before now
+//	24h, 1h30m             Go durations before now
+//	2025-01-15             a date (UTC)
+//	2025-01-15T09:00:00Z   an RFC 3339 timestamp
+func ParseSince(since string, now time.Time) (time.Time, error) {
+	expr := strings.ToLower(strings.TrimSpace(since))
+	today := now.Truncate(24 * time.Hour)
+
+	switch expr {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.Add(-24 * time.Hour), nil
+	}
+
+	if name, found := strings.CutPrefix(expr, "last "); found {
+		if weekday, ok := parseWeekday(strings.TrimSpace(name)); ok {
+			days := (int(today.UTC().Weekday()) - int(weekday) + 7) % 7
+			if days == 0 {
+				days = 7
+			}
+
+			return today.Add(-time.Duration(days) * 24 * time.Hour), nil
+		}
+	}
+
+	if len(expr) > 1 {
+		if subtract, isUnit := sinceUnits[expr[len(expr)-1]]; isUnit {
+			if count := expr[:len(expr)-1]; isDigits(count) {
+				if n, err := strconv.Atoi(count); err == nil {
+					return subtract(now, n), nil
+				}
+			}
+		}
+	}
+
+	if duration, err := time.ParseDuration(expr); err == nil && duration >= 0 {
+		return now.Add(-duration), nil
+	}
+
+	if t, err := time.Parse("2006-01-02", expr); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, strings.TrimSpace(since)); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("unable to parse since time '%s': supported formats are 'today', 'yesterday', "+
+		"'last <weekday>', relative durations (7d, 2w, 3m, 1y, 24h), absolute dates (2006-01-02) or RFC 3339 "+
+		"timestamps (2006-01-02T15:04:05Z)", since)
+}
+
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if name == strings.ToLower(day.String()) {
+			return day, true
+		}
+	}
+
+	return 0, false
+}
+
+// isDigits reports whether s is a plain unsigned number, rejecting the signs strconv.Atoi accepts.
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return s != ""
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	// A Wednesday
+	now := time.Date(2025, 3, 12, 15, 30, 0, 0, time.UTC)
+	day := func(month time.Month, d int) time.Time { return time.Date(2025, month, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		since string
+		want  time.Time
+	}{
+		{"today", day(3, 12)},
+		{"Yesterday", day(3, 11)},
+		{"last monday", day(3, 10)},
+		{"last Wednesday", day(3, 5)},
+		{"last thursday", day(3, 6)},
+		{"7d", now.AddDate(0, 0, -7)},
+		{"2w", now.AddDate(0, 0, -14)},
+		{"3m", now.AddDate(0, -3, 0)},
+		{"1y", now.AddDate(-1, 0, 0)},
+		{"0d", now},
+		{"24h", now.Add(-24 * time.Hour)},
+		{"1h30m", now.Add(-90 * time.Minute)},
+		{"2025-01-15", day(1, 15)},
+		{"2025-01-15T09:00:00+01:00", time.Date(2025, 1, 15, 8, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.since, func(t *testing.T) {
+			got, err := ParseSince(tt.since, now)
+			if err != nil {
+				t.Fatalf("ParseSince() error = %v", err)
+			}
+
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseSince() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"", "soon", "-1d", "+2w", "3.5d", "1week", "last funday", "-1h", "2025/01/15"} {
+		if _, err := ParseSince(invalid, now); err == nil {
+			t.Errorf("ParseSince(%q) accepted an invalid expression", invalid)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize parses a size setting such as max_doc_size or max_attachment_size: "10MB", "512KB", "1.5GB" or
+// a byte count like "2048". Units are binary and case-insensitive. An empty value is 0, meaning no limit.
+func ParseSize(raw string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(raw))
+	if value == "" {
+		return 0, nil
+	}
+
+	multiplier := int64(1)
+
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.bytes
+
+			break
+		}
+	}
+
+	size, err := strconv.ParseFloat(value, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid size '%s': use a size such as '10MB', '512KB' or a byte count", raw)
+	}
+
+	return int64(size * float64(multiplier)), nil
+}
+
+// FormatSize renders a byte count with the largest fitting unit ("1.5MB").
+func FormatSize(size int64) string {
+	for _, unit := range sizeUnits[:len(sizeUnits)-1] {
+		if size >= unit.bytes {
+			rounded := math.Round(float64(size)/float64(unit.bytes)*10) / 10
+
+			return strconv.FormatFloat(rounded, 'f', -1, 64) + unit.suffix
+		}
+	}
+
+	return fmt.Sprintf("%dB", size)
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+// LoadTimezone resolves an IANA timezone name ("Europe/Berlin", "UTC", "Local"); an empty name returns nil,
+// which leaves times in the zone they were fetched in.
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported timezone '%s': use an IANA name such as 'UTC' or 'Europe/Berlin': %w",
+			name, err)
+	}
+
+	return loc, nil
+}
+
+// ParseTimezone reads the timezone key of a target configuration.
+func ParseTimezone(config map[string]interface{}) (*time.Location, error) {
+	name, _ := config["timezone"].(string)
+
+	return LoadTimezone(name)
+}
+
+// LocalizeItems converts the timestamps of items, their thread messages and time-valued metadata to loc,
+// so rendered dates do not depend on the machine running the sync. A nil location leaves items unchanged.
+func LocalizeItems(items []models.FullItem, loc *time.Location) {
+	if loc == nil {
+		return
+	}
+
+	for _, item := range items {
+		localizeItem(item, loc)
+	}
+}
+
+func localizeItem(item models.FullItem, loc *time.Location) {
+	item.SetCreatedAt(item.GetCreatedAt().In(loc))
+	item.SetUpdatedAt(item.GetUpdatedAt().In(loc))
+
+	if metadata := item.GetMetadata(); len(metadata) > 0 {
+		// Copy so metadata maps shared with other items are left untouched
+		localized := make(map[string]interface{}, len(metadata))
+
+		for key, value := range metadata {
+			switch v := value.(type) {
+			case time.Time:
+				localized[key] = v.In(loc)
+			case *time.Time:
+				if v != nil {
+					t := v.In(loc)
+					localized[key] = &t
+				} else {
+					localized[key] = v
+				}
+			default:
+				localized[key] = value
+			}
+		}
+
+		item.SetMetadata(localized)
+	}
+
+	if thread, ok := models.AsThread(item); ok {
+		for _, message := range thread.GetMessages() {
+			localizeItem(message, loc)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+func TestLocalizeItems(t *testing.T) {
+	berlin, err := LoadTimezone("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("LoadTimezone() error = %v", err)
+	}
+
+	start := time.Date(2025, 1, 15, 23, 30, 0, 0, time.UTC)
+	metadata := map[string]interface{}{"start_time": start, "location": "Room 1"}
+
+	message := models.NewBasicItem("msg-1", "Re: Plan")
+	message.SetCreatedAt(start)
+
+	thread := models.NewThread("thread-1", "Plan")
+	thread.SetCreatedAt(start)
+	thread.SetMetadata(metadata)
+	thread.AddMessage(message)
+
+	LocalizeItems([]models.FullItem{thread}, berlin)
+
+	if got := thread.GetCreatedAt(); got.Location() != berlin || got.Day() != 16 || !got.Equal(start) {
+		t.Errorf("created = %v, want %v in Europe/Berlin", got, start)
+	}
+
+	if got := thread.GetMetadata()["start_time"].(time.Time); got.Location() != berlin {
+		t.Errorf("start_time = %v, want Europe/Berlin", got)
+	}
+
+	if metadata["start_time"].(time.Time).Location() != time.UTC {
+		t.Error("LocalizeItems() modified the original metadata map")
+	}
+
+	if message.GetCreatedAt().Location() != berlin {
+		t.Errorf("thread message created = %v, want Europe/Berlin", message.GetCreatedAt())
+	}
+}
+
+func TestLoadTimezone(t *testing.T) {
+	if loc, err := LoadTimezone(""); loc != nil || err != nil {
+		t.Errorf("LoadTimezone(\"\") = %v, %v, want nil, nil", loc, err)
+	}
+
+	if _, err := LoadTimezone("Mars/Olympus"); err == nil {
+		t.Error("LoadTimezone() expected error for unknown timezone")
+	}
+}
@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// yamlTimestampPattern matches strings a YAML parser would read as a date or timestamp rather than a string.
+var yamlTimestampPattern = regexp.MustCompile(`^\d{4}-\d{1,2}-\d{1,2}`)
+
+// yamlReserved are the plain scalars YAML 1.1 and 1.2 parsers read as booleans or null.
+var yamlReserved = map[string]bool{
+	"true": true, "false": true, "yes": true, "no": true, "on": true, "off": true, "y": true, "n": true,
+	"null": true, "~": true,
+}
+
+// YAMLString renders s as a YAML scalar that parses back to the same string: plain when that is
+// unambiguous, double-quoted with escapes otherwise. Strings that would read as structure (colons, comment
+// markers, leading indicators such as "-", "[" or "&"), as another type (true, null, 42, 2025-01-02) or that
+// hold line breaks, tabs, control characters or surrounding spaces are quoted. Emoji and other printable
+// characters are kept as they are.
+func YAMLString(s string) string {
+	if !yamlNeedsQuotes(s) {
+		return s
+	}
+
+	return strconv.Quote(s)
+}
+
+func yamlNeedsQuotes(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return true
+	}
+
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return true
+	}
+
+	for _, r := range s {
+		if r == '"' || r == '\\' || r == unicode.ReplacementChar || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+
+	if yamlReserved[strings.ToLower(s)] || yamlTimestampPattern.MatchString(s) {
+		return true
+	}
+
+	// Numbers in any notation YAML knows: 42, -1.5, 1e3, 0x1F, 0o17, .inf, .NaN and YAML 1.1's 10:30
+	lower := strings.ToLower(s)
+	if strings.Trim(s, "0123456789:.") == "" {
+		return true
+	}
+
+	if _, err := strconv.ParseFloat(strings.ReplaceAll(s, "_", ""), 64); err == nil {
+		return true
+	}
+
+	if _, err := strconv.ParseInt(s, 0, 64); err == nil {
+		return true
+	}
+
+	return lower == ".inf" || lower == "-.inf" || lower == "+.inf" || lower == ".nan"
+}
+
+// YAMLScalar renders a value as a YAML scalar: booleans and numbers as they are, times as RFC 3339 and
+// anything else as a string.
+func YAMLScalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return YAMLString(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case fmt.Stringer:
+		return YAMLString(v.String())
+	}
+
+	return YAMLString(fmt.Sprintf("%v", value))
+}
+
+// YAMLProperty renders a frontmatter property: a scalar on the key's line, slices as a block list of
+// scalars and maps with string keys as a nested mapping sorted by key. Empty slices and maps render as
+// "[]" and "{}" so the property keeps its type.
+func YAMLProperty(key string, value interface{}) string {
+	key = YAMLString(key)
+
+	if _, isTime := value.(time.Time); value == nil || isTime {
+		return key + ": " + YAMLScalar(value) + "\n"
+	}
+
+	rv := reflect.ValueOf(value)
+
+	switch {
+	case (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Type().Elem().Kind() != reflect.Uint8:
+		if rv.Len() == 0 {
+			return key + ": []\n"
+		}
+
+		var sb strings.Builder
+
+		sb.WriteString(key + ":\n")
+
+		for i := 0; i < rv.Len(); i++ {
+			sb.WriteString("  - " + YAMLScalar(rv.Index(i).Interface()) + "\n")
+		}
+
+		return sb.String()
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		if rv.Len() == 0 {
+			return key + ": {}\n"
+		}
+
+		names := make([]string, 0, rv.Len())
+		for _, name := range rv.MapKeys() {
+			names = append(names, name.String())
+		}
+
+		sort.Strings(names)
+
+		var sb strings.Builder
+
+		sb.WriteString(key + ":\n")
+
+		for _, name := range names {
+			entry := rv.MapIndex(reflect.ValueOf(name).Convert(rv.Type().Key())).Interface()
+			sb.WriteString("  " + YAMLString(name) + ": " + YAMLScalar(entry) + "\n")
+		}
+
+		return sb.String()
+	}
+
+	return key + ": " + YAMLScalar(value) + "\n"
+}
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestYAMLStringRoundTrips(t *testing.T) {
+	adversarial := []string{
+		"",
+		"Weekly sync",
+		"Re: Budget",
+		"Note:",
+		"Issue #42",
+		"# heading",
+		"- not a list",
+		"[draft] proposal",
+		"{braces}",
+		"&anchor",
+		"*alias",
+		"!tag",
+		"| literal",
+		"> folded",
+		"'single'",
+		`Say "hello"`,
+		`C:\path\to\file`,
+		"line one\nline two",
+		"tab\tseparated",
+		"bell\a",
+		"line\u2028separator",
+		" padded ",
+		"true",
+		"No",
+		"off",
+		"null",
+		"~",
+		"42",
+		"-1.5",
+		"1e3",
+		"0x1F",
+		".inf",
+		"2025-01-15",
+		"2025-01-15 10:00",
+		"10:30",
+		"1.2.3",
+		"🚀 Launch: day one 🎉",
+		"Café ☕ — planning",
+		"%percent",
+		"@mention",
+		"`code`",
+		"?question",
+	}
+
+	for _, s := range adversarial {
+		var got map[string]interface{}
+		if err := yaml.Unmarshal([]byte("title: "+YAMLString(s)+"\n"), &got); err != nil {
+			t.Errorf("YAMLString(%q) = %s does not parse: %v", s, YAMLString(s), err)
+
+			continue
+		}
+
+		if value, ok := got["title"].(string); !ok || value != s {
+			t.Errorf("YAMLString(%q) = %s parses as %#v", s, YAMLString(s), got["title"])
+		}
+	}
+}
+
+func TestYAMLStringKeepsPlainText(t *testing.T) {
+	for _, s := range []string{"Weekly sync", "alice@example.com", "🚀 Launch", "v1.2.3", "Room 4.2"} {
+		if got := YAMLString(s); got != s {
+			t.Errorf("YAMLString(%q) = %s, want it unquoted", s, got)
+		}
+	}
+}
+
+func TestYAMLProperty(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value interface{}
+		want  string
+	}{
+		{"string", "subject", "Re: Plan", "subject: \"Re: Plan\"\n"},
+		{"int", "count", 3, "count: 3\n"},
+		{"float", "hours", 1.5, "hours: 1.5\n"},
+		{"bool", "recurring", true, "recurring: true\n"},
+		{"time", "start", time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC), "start: 2025-01-15T09:00:00Z\n"},
+		{"list", "labels", []string{"work", "#urgent"}, "labels:\n  - work\n  - \"#urgent\"\n"},
+		{"empty list", "labels", []string{}, "labels: []\n"},
+		{"map", "counts", map[string]int{"b@x.com": 1, "a@x.com": 2}, "counts:\n  a@x.com: 2\n  b@x.com: 1\n"},
+		{"nil", "location", nil, "location: null\n"},
+		{"odd key", "key: with colon", "value", "\"key: with colon\": value\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := YAMLProperty(tt.key, tt.value); got != tt.want {
+				t.Errorf("YAMLProperty() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -13,7 +13,16 @@ type Source interface {
 	Name() string
 	Configure(config map[string]interface{}, client *http.Client) error
 	Fetch(since time.Time, limit int) ([]models.FullItem, error)
-	SupportsRealtime() bool
+	Capabilities() SourceCapabilities
+}
+
+// SourceCapabilities are the features a source supports, so a sync can adapt to the source and warn when
+// the config asks it for something it can't do.
+type SourceCapabilities struct {
+	Incremental bool // Fetch returns only the items created or changed since the given time
+	Realtime    bool // Items are pushed to the source as they happen, rather than only fetched
+	Attachments bool // Items can carry attachments
+	WriteBack   bool // Edits in the vault, such as checked tasks or changed tags, can be written to the source
 }
 
 // Target represents any PKM system (Obsidian, Logseq, etc.)
@@ -25,7 +34,151 @@ type Target interface {
 	FormatFilename(title string) string
 	GetFileExtension() string
 	FormatMetadata(metadata map[string]interface{}) string
-	Preview(items []models.FullItem, outputDir string) ([]*FilePreview, error)
+	// Plan works out what Export would write for items without writing anything
+	Plan(items []models.FullItem, outputDir string) (*Plan, error)
+}
+
+// TaskStatusChange is a task the user checked or unchecked in the vault since it was last synced.
+type TaskStatusChange struct {
+	ItemID     string
+	SourceType string
+	Completed  bool
+}
+
+// TaskStatusReader is implemented by targets that can tell which synced tasks were checked or unchecked
+// in the vault.
+type TaskStatusReader interface {
+	TaskStatusChanges(outputDir string) ([]TaskStatusChange, error)
+}
+
+// TaskStatusWriter is implemented by sources that can complete or reopen their tasks. SetTaskCompleted
+// writes the status to the source and updates the fetched item to match.
+type TaskStatusWriter interface {
+	WritesTaskStatus() bool // Whether write-back is enabled for the source
+	SetTaskCompleted(item models.FullItem, completed bool) error
+}
+
+// TagChange is a synced item whose note had tags added or removed in the vault since it was last synced.
+type TagChange struct {
+	ItemID     string
+	SourceType string
+	Added      []string
+	Removed    []string
+}
+
+// TagChangeReader is implemented by targets that can tell which tags were edited on synced notes.
+type TagChangeReader interface {
+	TagChanges(outputDir string) ([]TagChange, error)
+}
+
+// TagWriter is implemented by sources that can turn tag edits into changes on the source, such as Gmail
+// labels. ApplyTagChanges ignores tags the source has no mapping for and updates the fetched item to match.
+type TagWriter interface {
+	WritesTags() bool // Whether write-back is enabled for the source
+	ApplyTagChanges(item models.FullItem, added, removed []string) error
+}
+
+// RangeFetcher is implemented by sources that can fetch a bounded window of their history, so a large
+// history can be imported in resumable chunks. FetchRange returns the items dated from since up to, but
+// excluding, until; a limit of 0 or less fetches all of them.
+type RangeFetcher interface {
+	FetchRange(since, until time.Time, limit int) ([]models.FullItem, error)
+}
+
+// SyncStateSaver is implemented by sources that keep incremental sync state, such as a changes token, that
+// must only advance once the items fetched with it are exported. SaveSyncState saves the state of the last
+// fetch, or nothing if that fetch was cut short.
+type SyncStateSaver interface {
+	SaveSyncState() error
+}
+
+// ProgressFunc receives how many of the total units of work, such as messages to download, are done.
+type ProgressFunc func(done, total int)
+
+// ProgressReporter is implemented by sources and targets that can report how far along Fetch or Export is,
+// so long runs can show progress.
+type ProgressReporter interface {
+	SetProgress(report ProgressFunc)
+}
+
+// ItemErrorFunc decides what happens to an item that failed to be written: it returns nil to carry on with
+// the next item, or an error to stop.
+type ItemErrorFunc func(item models.FullItem, err error) error
+
+// ItemErrorHandler is implemented by targets that can carry on exporting when a single item fails, leaving
+// what to do with the failure to the handler. Without a handler, the first failing item fails the export.
+type ItemErrorHandler interface {
+	SetItemErrorHandler(handle ItemErrorFunc)
+}
+
+// RawErrorFunc decides what happens to a raw payload of a source, such as a Gmail message, that failed to
+// convert to an item: it returns nil to carry on without it, or an error to fail the fetch.
+type RawErrorFunc func(id string, raw interface{}, err error) error
+
+// RawErrorHandler is implemented by sources that can carry on fetching when a single payload fails to
+// convert, e.g. because of a bad date or broken MIME. Without a handler, the failure fails the fetch.
+type RawErrorHandler interface {
+	SetRawErrorHandler(handle RawErrorFunc)
+}
+
+// ItemFilenameTarget is implemented by targets that can name individual items' files with other filenames
+// settings than their own, so each source's filenames settings apply to the items it fetched. The settings
+// are keyed by item ID and override only what they set.
+type ItemFilenameTarget interface {
+	SetItemFilenames(filenames map[string]models.FilenameConfig) error
+}
+
+// PurgeFilter selects the notes to purge: those synced from a source type, optionally only those carrying a
+// tag (such as the source tag "source:gmail_personal") and created before a time. Targets match the tag as
+// they write it to notes, e.g. with their tag prefix.
+type PurgeFilter struct {
+	SourceType string
+	Tag        string    // "" matches notes with any tags
+	Before     time.Time // Zero matches notes of any date
+}
+
+// Matches reports whether a note with the given source type, creation time and tags is selected. Notes
+// without a creation time are only selected when Before is zero.
+func (f PurgeFilter) Matches(sourceType string, created time.Time, tags []string) bool {
+	if sourceType == "" || sourceType != f.SourceType {
+		return false
+	}
+
+	if !f.Before.IsZero() && (created.IsZero() || !created.Before(f.Before)) {
+		return false
+	}
+
+	if f.Tag == "" {
+		return true
+	}
+
+	for _, tag := range tags {
+		if tag == f.Tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PurgeReport lists what a purge removed, or would remove in a dry run. Paths are slash-separated and
+// relative to the output directory.
+type PurgeReport struct {
+	Notes       []string
+	Attachments []string
+	Records     int // Entries removed from the target's indexes and caches
+}
+
+// Purger is implemented by targets that can remove synced notes together with everything they keep about
+// them, such as attachments only those notes link to and their entries in the target's indexes.
+type Purger interface {
+	Purge(outputDir string, filter PurgeFilter, dryRun bool) (PurgeReport, error)
+}
+
+// TemplateRenderer is implemented by targets that render notes through a user-provided template file, so
+// a template can be tried against an item without syncing.
+type TemplateRenderer interface {
+	RenderTemplateFile(path string, item models.FullItem) (string, error)
 }
 
 // ContentTarget represents a target that only needs core item content for export.
@@ -45,6 +198,58 @@ type FilePreview struct {
 	Content         string // Full content that would be written
 	ExistingContent string // Current content if file exists
 	Conflict        bool   // True if there would be a conflict
+	ItemID          string // Item the file is the note of; empty for files such as daily notes or feeds
+}
+
+// Plan is what exporting items to a target would change in the output directory: a FilePreview per file
+// the export writes, including index files, attachments and the notes it updates on the way. Dry runs and
+// diffs are built from it, so they report the same changes for every target.
+type Plan struct {
+	OutputDir string
+	Changes   []*FilePreview
+}
+
+// PlanSummary counts a plan's changes by action.
+type PlanSummary struct {
+	Create    int
+	Update    int
+	Skip      int
+	Conflicts int
+}
+
+// Summary counts the plan's changes by action.
+func (p *Plan) Summary() PlanSummary {
+	var summary PlanSummary
+
+	for _, change := range p.Changes {
+		switch change.Action {
+		case "create":
+			summary.Create++
+		case "update":
+			summary.Update++
+		case "skip":
+			summary.Skip++
+		}
+
+		if change.Conflict {
+			summary.Conflicts++
+		}
+	}
+
+	return summary
+}
+
+// Writes returns the changes that would write a file, leaving out the skipped ones.
+func (p *Plan) Writes() []*FilePreview {
+	var writes []*FilePreview
+
+	for _, change := range p.Changes {
+		if change.Action != "skip" {
+			writes = append(writes, change)
+		}
+	}
+
+	return writes
 }
 
 // Syncer coordinates between sources and targets.
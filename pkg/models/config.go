@@ -21,6 +21,9 @@ type Config struct {
 
 	// General application settings
 	App AppConfig `json:"app" yaml:"app"`
+
+	// Contact groups, e.g. "team", referenced by the sources' allow_contacts and deny_contacts
+	Contacts map[string][]string `json:"contacts,omitempty" yaml:"contacts,omitempty"`
 }
 
 // TransformConfig defines transformer pipeline configuration.
@@ -32,6 +35,16 @@ type TransformConfig struct {
 	Transformers  map[string]map[string]interface{} `json:"transformers"   yaml:"transformers"`
 }
 
+// SourceTransformConfig scopes the transformer pipeline for the items of one source: global transformers
+// can be disabled for it, and a pipeline of its own runs before or after the global one.
+type SourceTransformConfig struct {
+	Disable       []string `json:"disable,omitempty"        yaml:"disable,omitempty"`        // Global transformers skipped for the source
+	PipelineOrder []string `json:"pipeline_order,omitempty" yaml:"pipeline_order,omitempty"` // The source's own transformers, in order
+	// Where the source's transformers run relative to the global ones: "after" (default) or "before"
+	Position     string                            `json:"position,omitempty"     yaml:"position,omitempty"`
+	Transformers map[string]map[string]interface{} `json:"transformers,omitempty" yaml:"transformers,omitempty"`
+}
+
 type SyncConfig struct {
 	// Multi-source configuration
 	EnabledSources []string `json:"enabled_sources" yaml:"enabled_sources"` // ["google_calendar", "slack", "gmail"]
@@ -40,6 +53,13 @@ type SyncConfig struct {
 	// Default time range for syncing
 	DefaultSince string `json:"default_since" yaml:"default_since"`
 
+	// Without --since, sources sync from their last successful run minus this overlap (default "1h")
+	LastRunOverlap string `json:"last_run_overlap,omitempty" yaml:"last_run_overlap,omitempty"`
+	// Always sync from default_since or the source's since instead of the last successful run
+	IgnoreLastRun bool `json:"ignore_last_run,omitempty" yaml:"ignore_last_run,omitempty"`
+	// Queue fetches that fail for lack of connectivity, and items that fail to be written, for the next sync
+	OfflineQueue bool `json:"offline_queue,omitempty" yaml:"offline_queue,omitempty"`
+
 	// Default output directory
 	DefaultOutputDir string `json:"default_output_dir" yaml:"default_output_dir"`
 
@@ -55,12 +75,61 @@ type SyncConfig struct {
 	SourceTags    bool   `json:"source_tags"    yaml:"source_tags"`    // Add source-specific tags
 	OnConflict    string `json:"on_conflict"    yaml:"on_conflict"`    // "skip", "overwrite", "prompt"
 	DeduplicateBy string `json:"deduplicate_by" yaml:"deduplicate_by"` // "id", "title", "content", "none"
+	// Query limiting deduplication to matching items, e.g. "source = gmail"
+	DeduplicateWhere string `json:"deduplicate_where,omitempty" yaml:"deduplicate_where,omitempty"`
+	// Merge email threads a conversation has in several accounts by their Message-ID and References headers
+	MergeThreads bool `json:"merge_threads,omitempty" yaml:"merge_threads,omitempty"`
 
 	// File management
 	CreateSubdirs   bool   `json:"create_subdirs"    yaml:"create_subdirs"`
 	SubdirFormat    string `json:"subdir_format"     yaml:"subdir_format"` // "yyyy/mm", "yyyy-mm", "source", "flat"
-	MaxFileAge      string `json:"max_file_age"      yaml:"max_file_age"`  // "30d", "6m", "1y"
+	MaxFileAge      string `json:"max_file_age"      yaml:"max_file_age"`  // "30d", "6mo", "1y"
 	ArchiveOldFiles bool   `json:"archive_old_files" yaml:"archive_old_files"`
+
+	// Write a note with sync statistics to this path in the output directory, e.g. "Sync Stats.md"
+	StatsNote string `json:"stats_note,omitempty" yaml:"stats_note,omitempty"`
+
+	// Keep a full-text index of the output directory for `pkm-sync search`
+	SearchIndex bool `json:"search_index,omitempty" yaml:"search_index,omitempty"`
+
+	// Keep the fetched items of each source in the config directory, so `pkm-sync replay` can regenerate
+	// the output without the APIs
+	ItemCache bool `json:"item_cache,omitempty" yaml:"item_cache,omitempty"`
+
+	// Commit the output directory to git after each sync
+	Git GitConfig `json:"git,omitempty" yaml:"git,omitempty"`
+
+	// Shell commands run around every sync
+	Hooks HooksConfig `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+
+	// What happens when a single item fails to be written: the sync fails, or the item is skipped
+	ItemErrors ItemErrorsConfig `json:"item_errors,omitempty" yaml:"item_errors,omitempty"`
+}
+
+// ItemErrorsConfig controls how a sync handles items that fail to be written, e.g. because of a broken
+// attachment, while the other items could be.
+type ItemErrorsConfig struct {
+	// "fail_fast" (default) stops the sync, "skip" leaves the item out, "quarantine" also saves it to
+	// quarantine_folder
+	Policy string `json:"policy,omitempty" yaml:"policy,omitempty"`
+	// With skip or quarantine, the sync still fails once more items than this failed; 0 allows any number
+	MaxErrors int `json:"max_errors,omitempty" yaml:"max_errors,omitempty"`
+	// Folder of quarantined items, relative to the output directory (default "errors")
+	QuarantineFolder string `json:"quarantine_folder,omitempty" yaml:"quarantine_folder,omitempty"`
+}
+
+// HooksConfig holds shell commands run at points of a sync, e.g. to re-index a vault or send a notification.
+type HooksConfig struct {
+	PreSync  string `json:"pre_sync,omitempty"  yaml:"pre_sync,omitempty"`  // Before fetching; failing aborts the sync
+	PostSync string `json:"post_sync,omitempty" yaml:"post_sync,omitempty"` // After a successful export
+	OnError  string `json:"on_error,omitempty"  yaml:"on_error,omitempty"`  // When the sync fails
+}
+
+// GitConfig controls committing synced content to git for a versioned history of the vault.
+type GitConfig struct {
+	AutoCommit bool   `json:"auto_commit"      yaml:"auto_commit"`      // Commit changes after each sync
+	Push       bool   `json:"push"             yaml:"push"`             // Push after committing
+	Remote     string `json:"remote,omitempty" yaml:"remote,omitempty"` // Remote to push to; empty uses the branch's upstream
 }
 
 type SourceConfig struct {
@@ -74,24 +143,72 @@ type SourceConfig struct {
 	OutputTarget string        `json:"output_target,omitempty" yaml:"output_target,omitempty"`
 	SyncInterval time.Duration `json:"sync_interval,omitempty" yaml:"sync_interval,omitempty"`
 	Since        string        `json:"since,omitempty"         yaml:"since,omitempty"`
+	Until        string        `json:"until,omitempty"         yaml:"until,omitempty"` // End of the sync window, exclusive
 	Priority     int           `json:"priority,omitempty"      yaml:"priority,omitempty"`
+	// IANA timezone item dates are converted to after fetching, e.g. "America/New_York"
+	Timezone string `json:"timezone,omitempty" yaml:"timezone,omitempty"`
+	// Shell commands run around syncing this source
+	Hooks HooksConfig `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+	// Transformer pipeline scoping for this source's items
+	Transformers SourceTransformConfig `json:"transformers,omitempty" yaml:"transformers,omitempty"`
+	// Lines from the end where a signature is detected, overriding the signature transformers' setting
+	SignatureThreshold int `json:"signature_threshold,omitempty" yaml:"signature_threshold,omitempty"`
+	// Google account whose auth.google_quota budget the source's requests count against (default "default")
+	Account string `json:"account,omitempty" yaml:"account,omitempty"`
+	// File naming of this source's notes and thread titles, overriding the target's filenames settings it sets
+	Filenames FilenameConfig `json:"filenames,omitempty" yaml:"filenames,omitempty"`
+	// Keep only items whose title or body mentions one of these words or phrases
+	IncludeKeywords []string `json:"include_keywords,omitempty" yaml:"include_keywords,omitempty"`
+	// Drop items whose title or body mentions one of these words or phrases
+	ExcludeKeywords []string `json:"exclude_keywords,omitempty" yaml:"exclude_keywords,omitempty"`
+	// Keep only items with one of these contacts: contact group names, addresses, domains or display names
+	AllowContacts []string `json:"allow_contacts,omitempty" yaml:"allow_contacts,omitempty"`
+	// Drop items sent or organized by one of these contacts
+	DenyContacts []string `json:"deny_contacts,omitempty" yaml:"deny_contacts,omitempty"`
+	// Video meeting services whose join links are found in event locations and descriptions, besides
+	// Google Meet, Zoom, Teams, Webex and Whereby
+	MeetingProviders []MeetingProviderConfig `json:"meeting_providers,omitempty" yaml:"meeting_providers,omitempty"`
 
 	// Source-specific configurations
 	// Source-specific configurations
-	Google GoogleSourceConfig `json:"google,omitempty" yaml:"google,omitempty"`
-	Slack  SlackSourceConfig  `json:"slack,omitempty"  yaml:"slack,omitempty"`
-	Gmail  GmailSourceConfig  `json:"gmail,omitempty"  yaml:"gmail,omitempty"`
-	Jira   JiraSourceConfig   `json:"jira,omitempty"   yaml:"jira,omitempty"`
+	Google      GoogleSourceConfig      `json:"google,omitempty"       yaml:"google,omitempty"`
+	Slack       SlackSourceConfig       `json:"slack,omitempty"        yaml:"slack,omitempty"`
+	Gmail       GmailSourceConfig       `json:"gmail,omitempty"        yaml:"gmail,omitempty"`
+	Jira        JiraSourceConfig        `json:"jira,omitempty"         yaml:"jira,omitempty"`
+	Ingest      IngestSourceConfig      `json:"ingest,omitempty"       yaml:"ingest,omitempty"`
+	Bookmarks   BookmarksSourceConfig   `json:"bookmarks,omitempty"    yaml:"bookmarks,omitempty"`
+	AppleNotes  AppleNotesSourceConfig  `json:"apple_notes,omitempty"  yaml:"apple_notes,omitempty"`
+	Teams       TeamsSourceConfig       `json:"teams,omitempty"        yaml:"teams,omitempty"`
+	Outlook     OutlookSourceConfig     `json:"outlook,omitempty"      yaml:"outlook,omitempty"`
+	GoogleTasks GoogleTasksSourceConfig `json:"google_tasks,omitempty" yaml:"google_tasks,omitempty"`
+	Mock        MockSourceConfig        `json:"mock,omitempty"         yaml:"mock,omitempty"`
+}
+
+// MeetingProviderConfig recognizes join links of a video meeting service by a regular expression, e.g.
+// {name: jitsi, pattern: 'https://meet\.jit\.si/\S+'}.
+type MeetingProviderConfig struct {
+	Name    string `json:"name"    yaml:"name"`
+	Pattern string `json:"pattern" yaml:"pattern"`
 }
 
 type GoogleSourceConfig struct {
 	// Calendar settings
-	CalendarID      string   `json:"calendar_id"      yaml:"calendar_id"` // "primary" or specific calendar
+	CalendarID      string   `json:"calendar_id"      yaml:"calendar_id"` // "primary", specific calendar or "all"
 	IncludeDeclined bool     `json:"include_declined" yaml:"include_declined"`
 	IncludePrivate  bool     `json:"include_private"  yaml:"include_private"`
 	EventTypes      []string `json:"event_types"      yaml:"event_types"` // filter by event types
+	// multiple calendars with per-calendar tags and folders; takes precedence over calendar_id
+	Calendars []CalendarConfig `json:"calendars,omitempty" yaml:"calendars,omitempty"`
 	// maximum number of events to fetch (default: 1000)
 	MaxResults int `json:"max_results" yaml:"max_results"`
+	// "expand" (default) emits one item per occurrence, "series" one item per recurring series
+	RecurringEvents string `json:"recurring_events,omitempty" yaml:"recurring_events,omitempty"`
+	// "week" and/or "month": add a meeting analytics item per period
+	Analytics []string `json:"analytics,omitempty" yaml:"analytics,omitempty"`
+	// add an "## Agenda" section parsed from descriptions and attach the docs they link to
+	ExtractAgenda bool `json:"extract_agenda,omitempty" yaml:"extract_agenda,omitempty"`
+	// drop events outside working hours, all-day out-of-office events, or too short or long events
+	EventFilters EventFilterConfig `json:"event_filters,omitempty" yaml:"event_filters,omitempty"`
 
 	// Attendee filtering
 	// only include events with these attendees
@@ -106,12 +223,30 @@ type GoogleSourceConfig struct {
 	DocFormats    []string `json:"doc_formats"    yaml:"doc_formats"`  // "markdown", "pdf", "docx"
 	MaxDocSize    string   `json:"max_doc_size"   yaml:"max_doc_size"` // "10MB"
 	IncludeShared bool     `json:"include_shared" yaml:"include_shared"`
+	// "section" or "footnotes": add comment threads to markdown exports (empty: no comments)
+	DocComments string `json:"doc_comments,omitempty" yaml:"doc_comments,omitempty"`
+
+	// Drive folder sync (google_drive sources): folder mirrored recursively into the vault
+	FolderID string `json:"folder_id,omitempty" yaml:"folder_id,omitempty"`
 
 	// Rate limiting
 	RequestDelay time.Duration `json:"request_delay" yaml:"request_delay"`
 	MaxRequests  int           `json:"max_requests"  yaml:"max_requests"`
 }
 
+// FolderRouteConfig sends items matching a query to a folder inside the output directory.
+type FolderRouteConfig struct {
+	When   string `json:"when"   yaml:"when"`   // Query, e.g. `from ~ "@company.com"`
+	Folder string `json:"folder" yaml:"folder"` // e.g. "Work/Mail"
+}
+
+// CalendarConfig selects one calendar of a Google source instance.
+type CalendarConfig struct {
+	ID     string   `json:"id"               yaml:"id"`               // Calendar ID, "primary" or "all" for every readable calendar
+	Tags   []string `json:"tags,omitempty"   yaml:"tags,omitempty"`   // Tags added to the calendar's events
+	Folder string   `json:"folder,omitempty" yaml:"folder,omitempty"` // Folder inside the output directory
+}
+
 type TargetConfig struct {
 	// Target type (output directory comes from SyncConfig.DefaultOutputDir)
 	Type string `json:"type" yaml:"type"`
@@ -121,6 +256,32 @@ type TargetConfig struct {
 
 	// Logseq-specific settings
 	Logseq LogseqTargetConfig `json:"logseq,omitempty" yaml:"logseq,omitempty"`
+
+	// iCalendar feed settings
+	ICal ICalTargetConfig `json:"ical,omitempty" yaml:"ical,omitempty"`
+
+	// File naming policy shared by all targets
+	Filenames FilenameConfig `json:"filenames,omitempty" yaml:"filenames,omitempty"`
+
+	// Item type -> folder inside the output directory, e.g. {"email": "Mail", "event": "Meetings"}
+	ItemFolders map[string]string `json:"item_folders,omitempty" yaml:"item_folders,omitempty"`
+
+	// Folders for items matching a query; the first matching route wins over item_folders and source folders
+	FolderRoutes []FolderRouteConfig `json:"folder_routes,omitempty" yaml:"folder_routes,omitempty"`
+
+	// IANA timezone dates are rendered in, e.g. "Europe/Berlin"; empty keeps each item's own zone
+	Timezone string `json:"timezone,omitempty" yaml:"timezone,omitempty"`
+}
+
+// FilenameConfig controls how item titles become file names.
+type FilenameConfig struct {
+	Style         string `json:"style,omitempty"           yaml:"style,omitempty"`     // "hyphenated", "slug", "preserve"
+	Collision     string `json:"collision,omitempty"       yaml:"collision,omitempty"` // "suffix", "id", "overwrite"
+	MaxLength     int    `json:"max_length,omitempty"      yaml:"max_length,omitempty"`
+	MaxPathLength int    `json:"max_path_length,omitempty" yaml:"max_path_length,omitempty"` // e.g. 260 for Windows
+	Lowercase     bool   `json:"lowercase,omitempty"       yaml:"lowercase,omitempty"`
+	ASCII         bool   `json:"ascii,omitempty"           yaml:"ascii,omitempty"`     // Transliterate to ASCII
+	Separator     string `json:"separator,omitempty"       yaml:"separator,omitempty"` // "-", "_" or " "
 }
 
 type ObsidianTargetConfig struct {
@@ -131,20 +292,98 @@ type ObsidianTargetConfig struct {
 	FilenameTemplate string `json:"filename_template" yaml:"filename_template"` // "{{date}} - {{title}}"
 	DateFormat       string `json:"date_format"       yaml:"date_format"`       // "2006-01-02"
 	TagPrefix        string `json:"tag_prefix"        yaml:"tag_prefix"`        // "calendar/"
+	// Source tag -> tag written instead, e.g. Gmail's "⭐" -> "starred"; "" drops the tag
+	TagNames map[string]string `json:"tag_names,omitempty" yaml:"tag_names,omitempty"`
 
 	// Content formatting
 	IncludeFrontmatter bool     `json:"include_frontmatter" yaml:"include_frontmatter"`
 	CustomFields       []string `json:"custom_fields"       yaml:"custom_fields"`
 	TemplateFile       string   `json:"template_file"       yaml:"template_file"`
+	// "frontmatter" (default), "dataview" (inline key:: value fields), "both"
+	MetadataFormat string `json:"metadata_format,omitempty" yaml:"metadata_format,omitempty"`
+	// Go layout for date-time properties and the created field (default: RFC 3339 / "2006-01-02T15:04:05")
+	DateTimeFormat string `json:"datetime_format,omitempty" yaml:"datetime_format,omitempty"`
+	// Standard property -> name written, e.g. created -> date-created (id, source, type, created, message_count, tags)
+	PropertyNames map[string]string `json:"property_names,omitempty" yaml:"property_names,omitempty"`
 
 	// Linking and references
 	CreateDailyNotes bool   `json:"create_daily_notes" yaml:"create_daily_notes"`
 	DailyNotesFolder string `json:"daily_notes_folder" yaml:"daily_notes_folder"`
 	LinkFormat       string `json:"link_format"        yaml:"link_format"` // "wikilink", "markdown"
+	// Template used when a daily note doesn't exist yet ({{date}} is replaced)
+	DailyNotesTemplate string `json:"daily_notes_template,omitempty" yaml:"daily_notes_template,omitempty"`
+	// Embed items (![[note]]) instead of linking them
+	DailyNotesEmbed bool `json:"daily_notes_embed,omitempty" yaml:"daily_notes_embed,omitempty"`
+
+	// Canvas generation (.canvas files)
+	CanvasThreads bool     `json:"canvas_threads,omitempty" yaml:"canvas_threads,omitempty"` // One canvas per email thread
+	CanvasTags    []string `json:"canvas_tags,omitempty"    yaml:"canvas_tags,omitempty"`    // One canvas per tag
+	CanvasFolder  string   `json:"canvas_folder,omitempty"  yaml:"canvas_folder,omitempty"`  // Default: "Canvas"
+
+	// Kanban board for task-like items (Obsidian Kanban plugin format)
+	KanbanBoard       string   `json:"kanban_board,omitempty"        yaml:"kanban_board,omitempty"`        // Board note name
+	KanbanItemTypes   []string `json:"kanban_item_types,omitempty"   yaml:"kanban_item_types,omitempty"`   // Default: task, issue, todo
+	KanbanStatusField string   `json:"kanban_status_field,omitempty" yaml:"kanban_status_field,omitempty"` // Default: "status"
+	KanbanLanes       []string `json:"kanban_lanes,omitempty"        yaml:"kanban_lanes,omitempty"`        // Lane order
+
+	// Triage queue: link items matching important_when, or with a priority of at least important_min_priority
+	ImportantNote          string  `json:"important_note,omitempty"           yaml:"important_note,omitempty"`           // e.g. "Inbox – Important"
+	ImportantBookmarks     bool    `json:"important_bookmarks,omitempty"      yaml:"important_bookmarks,omitempty"`      // Bookmarks "Important" group
+	ImportantWhen          string  `json:"important_when,omitempty"           yaml:"important_when,omitempty"`           // Query
+	ImportantPriorityField string  `json:"important_priority_field,omitempty" yaml:"important_priority_field,omitempty"` // Default: "priority"
+	ImportantMinPriority   float64 `json:"important_min_priority,omitempty"   yaml:"important_min_priority,omitempty"`
+
+	// Map-of-content index notes
+	IndexNotes  []string `json:"index_notes,omitempty"  yaml:"index_notes,omitempty"`  // "source", "month", "tag"
+	IndexFolder string   `json:"index_folder,omitempty" yaml:"index_folder,omitempty"` // Default: "Index"
+
+	// Link notes of different sources that share event, message or Drive file IDs
+	CrossLinks bool `json:"cross_links,omitempty" yaml:"cross_links,omitempty"`
+
+	// Changelog section in notes of items edited at the source ("Updated 2024-06-02: description changed")
+	Changelog       bool     `json:"changelog,omitempty"        yaml:"changelog,omitempty"`
+	ChangelogIgnore []string `json:"changelog_ignore,omitempty" yaml:"changelog_ignore,omitempty"` // Metadata keys not compared
+
+	// Person notes with a meeting history for each organizer and attendee
+	PersonNotes  bool              `json:"person_notes,omitempty"  yaml:"person_notes,omitempty"`
+	PeopleFolder string            `json:"people_folder,omitempty" yaml:"people_folder,omitempty"` // Default: "People"
+	PersonNames  map[string]string `json:"person_names,omitempty"  yaml:"person_names,omitempty"`  // Email -> note name
+
+	// Company notes with the people and interaction timeline of each sender/attendee domain
+	CompanyNotes          bool              `json:"company_notes,omitempty"           yaml:"company_notes,omitempty"`
+	CompaniesFolder       string            `json:"companies_folder,omitempty"        yaml:"companies_folder,omitempty"` // Default: "Companies"
+	CompanyNames          map[string]string `json:"company_names,omitempty"           yaml:"company_names,omitempty"`    // Domain -> note name
+	CompanyExcludeDomains []string          `json:"company_exclude_domains,omitempty" yaml:"company_exclude_domains,omitempty"`
+
+	// Rolling notes: append each occurrence of a recurring item to one note per series
+	RollingNotes      bool     `json:"rolling_notes,omitempty"       yaml:"rolling_notes,omitempty"`       // Recurring calendar events
+	RollingNoteTitles []string `json:"rolling_note_titles,omitempty" yaml:"rolling_note_titles,omitempty"` // Title prefixes of recurring reports
 
 	// Attachments
-	AttachmentFolder    string `json:"attachment_folder"    yaml:"attachment_folder"`
-	DownloadAttachments bool   `json:"download_attachments" yaml:"download_attachments"`
+	AttachmentFolder    string `json:"attachment_folder"              yaml:"attachment_folder"`
+	DownloadAttachments bool   `json:"download_attachments"           yaml:"download_attachments"`
+	DownloadImages      bool   `json:"download_images,omitempty"      yaml:"download_images,omitempty"`      // Remote images in email HTML
+	ConvertAttachments  bool   `json:"convert_attachments,omitempty"  yaml:"convert_attachments,omitempty"`  // docx/pptx/pdf companions
+	AttachmentConverter string `json:"attachment_converter,omitempty" yaml:"attachment_converter,omitempty"` // "native" or "pandoc"
+	AttachmentManifest  string `json:"attachment_manifest,omitempty"  yaml:"attachment_manifest,omitempty"`  // Note listing stored attachments
+
+	// Rules every attachment and downloaded image must pass before it is written to the vault
+	AttachmentPolicy AttachmentPolicyConfig `json:"attachment_policy,omitempty" yaml:"attachment_policy,omitempty"`
+}
+
+// AttachmentPolicyConfig rejects attachments by extension, size or the exit code of a scanner command, and
+// caps the bytes written to the attachment folder. Files that fail a rule are not written and their notes keep
+// the attachment's name or remote link.
+type AttachmentPolicyConfig struct {
+	AllowExtensions []string `json:"allow_extensions,omitempty" yaml:"allow_extensions,omitempty"` // Empty allows all
+	DenyExtensions  []string `json:"deny_extensions,omitempty"  yaml:"deny_extensions,omitempty"`
+	MaxSize         string   `json:"max_size,omitempty"         yaml:"max_size,omitempty"` // "10MB", "512KB" or bytes
+	// Quotas: once the files written in a run, or all files in the attachment folder, would exceed them,
+	// further attachments and images are not written and notes link to their source instead
+	MaxRunSize    string `json:"max_run_size,omitempty"    yaml:"max_run_size,omitempty"`
+	MaxFolderSize string `json:"max_folder_size,omitempty" yaml:"max_folder_size,omitempty"`
+	// Shell command run with the file's path in PKM_SYNC_ATTACHMENT; a non-zero exit rejects the file
+	Command string `json:"command,omitempty" yaml:"command,omitempty"`
 }
 
 type LogseqTargetConfig struct {
@@ -154,11 +393,24 @@ type LogseqTargetConfig struct {
 	// Content formatting
 	UseProperties    bool   `json:"use_properties"    yaml:"use_properties"`
 	PropertyPrefix   string `json:"property_prefix"   yaml:"property_prefix"`
-	BlockIndentation int    `json:"block_indentation" yaml:"block_indentation"`
+	BlockIndentation int    `json:"block_indentation" yaml:"block_indentation"` // 0 (default) indents with tabs
+	// Standard property -> name written before property_prefix, e.g. source -> origin
+	PropertyNames map[string]string `json:"property_names,omitempty" yaml:"property_names,omitempty"`
 
 	// Journal integration
-	CreateJournalRefs bool   `json:"create_journal_refs" yaml:"create_journal_refs"`
+	// Write dates as journal page references (default: true)
+	CreateJournalRefs *bool  `json:"create_journal_refs,omitempty" yaml:"create_journal_refs,omitempty"`
 	JournalDateFormat string `json:"journal_date_format" yaml:"journal_date_format"`
+	// "pages" (default) or "journal" to append items as blocks to their day's journal page
+	ExportMode string `json:"export_mode,omitempty" yaml:"export_mode,omitempty"`
+}
+
+// ICalTargetConfig configures the ical target, which keeps an .ics feed of synced events and tasks with due
+// dates in the output directory.
+type ICalTargetConfig struct {
+	FeedFile     string `json:"feed_file,omitempty"     yaml:"feed_file,omitempty"`     // Default: "calendar.ics"
+	CalendarName string `json:"calendar_name,omitempty" yaml:"calendar_name,omitempty"` // Default: "pkm-sync"
+	SkipTasks    bool   `json:"skip_tasks,omitempty"    yaml:"skip_tasks,omitempty"`    // Leave out tasks with due dates
 }
 
 type AuthConfig struct {
@@ -169,6 +421,9 @@ type AuthConfig struct {
 	// Security settings
 	EncryptTokens   bool   `json:"encrypt_tokens"   yaml:"encrypt_tokens"`
 	TokenExpiration string `json:"token_expiration" yaml:"token_expiration"` // "30d"
+
+	// Daily Google API request budget by account; Google sources stop for the day once theirs is used
+	GoogleQuota map[string]int `json:"google_quota,omitempty" yaml:"google_quota,omitempty"`
 }
 
 type AppConfig struct {
@@ -188,11 +443,21 @@ type AppConfig struct {
 	CacheDir     string        `json:"cache_dir"     yaml:"cache_dir"`
 	CacheTTL     time.Duration `json:"cache_ttl"     yaml:"cache_ttl"`
 
+	// Encryption of the state stores and indexes kept in the config and output directories
+	StateEncryption StateEncryptionConfig `json:"state_encryption,omitempty" yaml:"state_encryption,omitempty"`
+
 	// Notifications
 	NotifyOnSuccess bool `json:"notify_on_success" yaml:"notify_on_success"`
 	NotifyOnError   bool `json:"notify_on_error"   yaml:"notify_on_error"`
 }
 
+// StateEncryptionConfig encrypts state stores and indexes at rest with a passphrase (app.state_encryption).
+type StateEncryptionConfig struct {
+	Enabled           bool   `json:"enabled"                      yaml:"enabled"`
+	PassphraseEnv     string `json:"passphrase_env,omitempty"     yaml:"passphrase_env,omitempty"`     // Default PKM_SYNC_PASSPHRASE
+	PassphraseCommand string `json:"passphrase_command,omitempty" yaml:"passphrase_command,omitempty"` // Prints the passphrase, e.g. from the OS keyring
+}
+
 // Future source configurations (placeholders for planned integrations)
 
 type SlackSourceConfig struct {
@@ -208,6 +473,19 @@ type SlackSourceConfig struct {
 	MinLength    int      `json:"min_length"    yaml:"min_length"` // Minimum message length
 	IncludeFiles bool     `json:"include_files" yaml:"include_files"`
 	FileTypes    []string `json:"file_types"    yaml:"file_types"` // ["pdf", "doc", "img"]
+
+	// Saved-items and reaction capture instead of full-channel sync
+	Capture ChatCaptureConfig `json:"capture,omitempty" yaml:"capture,omitempty"`
+}
+
+// ChatCaptureConfig limits a chat source to the messages worth keeping: saved (starred) messages and
+// messages carrying one of the capture reactions. Without either, every message is synced.
+type ChatCaptureConfig struct {
+	SavedOnly bool `json:"saved_only,omitempty" yaml:"saved_only,omitempty"`
+	// Emoji or shortcodes, e.g. ["📌", ":bookmark:"]
+	Reactions []string `json:"reactions,omitempty" yaml:"reactions,omitempty"`
+	// Whose reactions count: "me" (default) or "anyone"
+	ReactedBy string `json:"reacted_by,omitempty" yaml:"reacted_by,omitempty"`
 }
 
 type GmailSourceConfig struct {
@@ -270,13 +548,127 @@ type GmailSourceConfig struct {
 	IncludeThreadContext bool          `json:"include_thread_context,omitempty" yaml:"include_thread_context,omitempty"`
 	GroupByThread        bool          `json:"group_by_thread,omitempty"        yaml:"group_by_thread,omitempty"`
 	TaggingRules         []TaggingRule `json:"tagging_rules,omitempty"          yaml:"tagging_rules,omitempty"`
+
+	// Label write-back: tag edits on email notes become Gmail label changes on the next sync
+	LabelWriteBack bool `json:"label_write_back,omitempty" yaml:"label_write_back,omitempty"`
+	// Tag to label name, e.g. {"follow-up": "Follow-up"}; the labels are also synced as these tags
+	TagLabels map[string]string `json:"tag_labels,omitempty" yaml:"tag_labels,omitempty"`
 }
 
 type TaggingRule struct {
-	Condition string   `json:"condition" yaml:"condition"` // "from:boss@company.com"
+	Condition string   `json:"condition" yaml:"condition"` // Query, e.g. `from ~ "@company.com"`
 	Tags      []string `json:"tags"      yaml:"tags"`      // ["urgent", "work"]
 }
 
+// IngestSourceConfig configures an ingest source, which reads items in the canonical Item JSON schema.
+type IngestSourceConfig struct {
+	Path          string        `json:"path,omitempty"           yaml:"path,omitempty"`           // JSON or JSON Lines file; "-" or empty reads stdin
+	Listen        string        `json:"listen,omitempty"         yaml:"listen,omitempty"`         // HTTP listener address, e.g. "127.0.0.1:8089"
+	ListenTimeout time.Duration `json:"listen_timeout,omitempty" yaml:"listen_timeout,omitempty"` // How long the listener accepts items (default: 5m)
+	Token         string        `json:"token,omitempty"          yaml:"token,omitempty"`          // Bearer token the listener requires
+}
+
+// BookmarksSourceConfig configures a bookmarks source, which syncs browser bookmarks as link notes.
+type BookmarksSourceConfig struct {
+	Browser        string            `json:"browser,omitempty"         yaml:"browser,omitempty"`         // "chrome", "chromium", "brave", "edge" or "firefox"
+	Path           string            `json:"path,omitempty"            yaml:"path,omitempty"`            // Bookmarks file, export or profile folder; empty uses the browser's default profile
+	Folders        []string          `json:"folders,omitempty"         yaml:"folders,omitempty"`         // Only sync bookmarks under these folder paths
+	ExcludeFolders []string          `json:"exclude_folders,omitempty" yaml:"exclude_folders,omitempty"` // Skip bookmarks under these folder paths
+	FolderTags     bool              `json:"folder_tags,omitempty"     yaml:"folder_tags,omitempty"`     // Tag bookmarks with their folder names
+	FolderTagMap   map[string]string `json:"folder_tag_map,omitempty"  yaml:"folder_tag_map,omitempty"`  // Folder name -> tag, "" to skip the folder
+	TagPrefix      string            `json:"tag_prefix,omitempty"      yaml:"tag_prefix,omitempty"`      // Prefix for folder tags, e.g. "bookmarks/"
+}
+
+// AppleNotesSourceConfig configures an apple_notes source (macOS only).
+type AppleNotesSourceConfig struct {
+	Accounts       []string `json:"accounts,omitempty"        yaml:"accounts,omitempty"`        // Only sync these accounts, e.g. "iCloud"
+	Folders        []string `json:"folders,omitempty"         yaml:"folders,omitempty"`         // Only sync these folders
+	ExcludeFolders []string `json:"exclude_folders,omitempty" yaml:"exclude_folders,omitempty"` // Skip these folders
+	FolderTags     bool     `json:"folder_tags,omitempty"     yaml:"folder_tags,omitempty"`     // Tag notes with their folder name
+}
+
+// MockSourceConfig configures a mock source, which generates items from fixture files instead of an account.
+type MockSourceConfig struct {
+	// YAML or JSON files of emails and events; empty uses the built-in demo fixtures
+	Fixtures []string `json:"fixtures,omitempty" yaml:"fixtures,omitempty"`
+}
+
+// MicrosoftAuthConfig identifies the Azure app registration Microsoft Graph sources sign in with.
+type MicrosoftAuthConfig struct {
+	ClientID string `json:"client_id" yaml:"client_id"`
+	// "common" (default), "organizations" or a tenant ID
+	TenantID string `json:"tenant_id,omitempty" yaml:"tenant_id,omitempty"`
+	// Token cache; defaults to microsoft_<source type>_token.json in the config directory
+	TokenPath string `json:"token_path,omitempty" yaml:"token_path,omitempty"`
+}
+
+type TeamsSourceConfig struct {
+	Auth MicrosoftAuthConfig `json:"auth" yaml:"auth"`
+
+	// Channel messages: team and channel names or IDs; empty syncs every joined team and channel
+	Teams    []string `json:"teams,omitempty"    yaml:"teams,omitempty"`
+	Channels []string `json:"channels,omitempty" yaml:"channels,omitempty"`
+	// Chats: one-on-one, group and meeting chats, optionally limited to these topics or IDs
+	IncludeChats bool     `json:"include_chats,omitempty" yaml:"include_chats,omitempty"`
+	Chats        []string `json:"chats,omitempty"         yaml:"chats,omitempty"`
+
+	// "individual", "consolidated" (default), "summary"
+	ThreadMode string `json:"thread_mode,omitempty" yaml:"thread_mode,omitempty"`
+	// Max messages in summary (default: 5)
+	ThreadSummaryLength int `json:"thread_summary_length,omitempty" yaml:"thread_summary_length,omitempty"`
+
+	// Reaction capture instead of syncing every message
+	Capture ChatCaptureConfig `json:"capture,omitempty" yaml:"capture,omitempty"`
+}
+
+// OutlookSourceConfig configures an outlook_calendar source. The filters match the Google calendar settings
+// of the same names.
+type OutlookSourceConfig struct {
+	Auth MicrosoftAuthConfig `json:"auth" yaml:"auth"`
+
+	// Calendar names or IDs; empty syncs the default calendar, "all" every calendar
+	Calendars       []string `json:"calendars,omitempty"        yaml:"calendars,omitempty"`
+	IncludeDeclined bool     `json:"include_declined,omitempty" yaml:"include_declined,omitempty"`
+	// "expand" (default) emits one item per occurrence, "series" one item per recurring series
+	RecurringEvents string `json:"recurring_events,omitempty" yaml:"recurring_events,omitempty"`
+
+	// Attendee filtering
+	// only include events with these attendees
+	AttendeeAllowList []string `json:"attendee_allow_list,omitempty" yaml:"attendee_allow_list,omitempty"`
+	// exclude events with 0-1 attendees (default: true)
+	RequireMultipleAttendees *bool `json:"require_multiple_attendees,omitempty" yaml:"require_multiple_attendees,omitempty"`
+	// include events where you're the only attendee (default: false)
+	IncludeSelfOnlyEvents bool `json:"include_self_only_events,omitempty" yaml:"include_self_only_events,omitempty"`
+
+	// drop events outside working hours, all-day out-of-office events, or too short or long events
+	EventFilters EventFilterConfig `json:"event_filters,omitempty" yaml:"event_filters,omitempty"`
+}
+
+// EventFilterConfig drops calendar events that are noise in the vault by when they happen and how long
+// they last. Working hours are in the source's timezone, or the local one.
+type EventFilterConfig struct {
+	WorkingHours WorkingHoursConfig `json:"working_hours,omitempty" yaml:"working_hours,omitempty"`
+	// Drop all-day out-of-office events: Google outOfOffice events, Outlook events shown as away and
+	// all-day events titled OOO, PTO, vacation or out of office
+	ExcludeOutOfOffice bool   `json:"exclude_out_of_office,omitempty" yaml:"exclude_out_of_office,omitempty"`
+	MinDuration        string `json:"min_duration,omitempty"          yaml:"min_duration,omitempty"` // e.g. "10min"
+	MaxDuration        string `json:"max_duration,omitempty"          yaml:"max_duration,omitempty"` // e.g. "4h"
+}
+
+// WorkingHoursConfig keeps only timed events overlapping the working hours of a working day.
+type WorkingHoursConfig struct {
+	Start string   `json:"start,omitempty" yaml:"start,omitempty"` // "09:00"
+	End   string   `json:"end,omitempty"   yaml:"end,omitempty"`   // "17:30"
+	Days  []string `json:"days,omitempty"  yaml:"days,omitempty"`  // Default: mon-fri
+}
+
+type GoogleTasksSourceConfig struct {
+	// Task list names or IDs; empty syncs every list
+	TaskLists []string `json:"task_lists,omitempty" yaml:"task_lists,omitempty"`
+	// Complete and reopen tasks checked or unchecked in the vault; needs write access to Google Tasks
+	WriteBack bool `json:"write_back,omitempty" yaml:"write_back,omitempty"`
+}
+
 type JiraSourceConfig struct {
 	// Instance and authentication
 	InstanceURL string   `json:"instance_url" yaml:"instance_url"` // "https://company.atlassian.net"
@@ -369,8 +369,8 @@ func TestLogseqTargetConfigDefaults(t *testing.T) {
 		t.Error("Expected block_indentation to be 0 by default")
 	}
 
-	if config.CreateJournalRefs {
-		t.Error("Expected create_journal_refs to be false by default")
+	if config.CreateJournalRefs != nil {
+		t.Error("Expected create_journal_refs to be unset by default")
 	}
 
 	if config.JournalDateFormat != "" {
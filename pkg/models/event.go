@@ -5,6 +5,7 @@ import "time"
 type Attendee struct {
 	Email       string
 	DisplayName string
+	Self        bool // The authenticated user
 }
 
 // GetDisplayName returns the display name if available, otherwise returns email.
@@ -26,9 +27,24 @@ type CalendarEvent struct {
 	EndTime     time.Time
 	IsAllDay    bool
 	Location    string
+	Organizer   Attendee
 	Attendees   []Attendee
 	MeetingURL  string
 	Attachments []CalendarAttachment
+
+	// MeetingProvider names the video meeting service of MeetingURL, e.g. "zoom".
+	MeetingProvider string
+	// OutOfOffice is set for events marking an absence, such as Google outOfOffice events.
+	OutOfOffice bool
+
+	// CalendarID is the calendar the event was fetched from.
+	CalendarID string
+	// RecurringEventID is the series ID for occurrences of a recurring event.
+	RecurringEventID string
+	// Occurrences holds the start times of all occurrences when the event represents a whole series.
+	Occurrences []time.Time
+	// Agenda holds the markdown list lines of the agenda found in the description, if extracted.
+	Agenda []string
 }
 
 type CalendarAttachment struct {
@@ -47,4 +63,24 @@ type DriveFile struct {
 	ModifiedTime time.Time
 	Owners       []string
 	Shared       bool
+	Size         int64 // Bytes; Drive reports 0 for most native Google files
+}
+
+// Contact holds the details parsed from an email signature.
+type Contact struct {
+	Name    string `json:"name"              yaml:"name"`
+	Title   string `json:"title,omitempty"   yaml:"title,omitempty"`
+	Company string `json:"company,omitempty" yaml:"company,omitempty"`
+	Phone   string `json:"phone,omitempty"   yaml:"phone,omitempty"`
+	Email   string `json:"email,omitempty"   yaml:"email,omitempty"`
+}
+
+// Attendee returns the contact as a person, for person links and notes.
+func (c Contact) Attendee() Attendee {
+	return Attendee{Email: c.Email, DisplayName: c.Name}
+}
+
+// String returns the contact's name.
+func (c Contact) String() string {
+	return c.Name
 }
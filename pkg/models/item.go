@@ -3,6 +3,7 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -185,6 +186,26 @@ func FromCalendarEvent(event *CalendarEvent) *Item {
 		},
 	}
 
+	if event.Organizer.Email != "" {
+		item.Metadata["organizer"] = event.Organizer
+	}
+
+	if event.RecurringEventID != "" {
+		item.Metadata["recurring_event_id"] = event.RecurringEventID
+	}
+
+	if len(event.Agenda) > 0 {
+		item.Content = appendAgenda(item.Content, event.Agenda)
+	}
+
+	// A series event lists its occurrences instead of describing a single meeting
+	if len(event.Occurrences) > 0 {
+		item.Metadata["occurrence_count"] = len(event.Occurrences)
+		item.Metadata["first_occurrence"] = event.Occurrences[0]
+		item.Metadata["last_occurrence"] = event.Occurrences[len(event.Occurrences)-1]
+		item.Content = appendOccurrenceList(item.Content, event.Occurrences)
+	}
+
 	// Convert Calendar attachments
 	for _, attachment := range event.Attachments {
 		item.Attachments = append(item.Attachments, Attachment{
@@ -197,6 +218,11 @@ func FromCalendarEvent(event *CalendarEvent) *Item {
 
 	// Add meeting URL as a link
 	if event.MeetingURL != "" {
+		item.Metadata[MetadataMeetingURL] = event.MeetingURL
+		if event.MeetingProvider != "" {
+			item.Metadata[MetadataMeetingProvider] = event.MeetingProvider
+		}
+
 		item.Links = append(item.Links, Link{
 			URL:   event.MeetingURL,
 			Title: "Meeting URL",
@@ -207,6 +233,40 @@ func FromCalendarEvent(event *CalendarEvent) *Item {
 	return item
 }
 
+// appendAgenda adds an agenda section to an event description.
+func appendAgenda(content string, agenda []string) string {
+	var sb strings.Builder
+
+	if content != "" {
+		sb.WriteString(strings.TrimRight(content, "\n"))
+		sb.WriteString("\n\n")
+	}
+
+	sb.WriteString("## Agenda\n\n")
+	sb.WriteString(strings.Join(agenda, "\n"))
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+// appendOccurrenceList adds a markdown list of occurrence start times to an event description.
+func appendOccurrenceList(content string, occurrences []time.Time) string {
+	var sb strings.Builder
+
+	if content != "" {
+		sb.WriteString(strings.TrimRight(content, "\n"))
+		sb.WriteString("\n\n")
+	}
+
+	sb.WriteString("## Occurrences\n\n")
+
+	for _, start := range occurrences {
+		sb.WriteString("- " + start.Format("2006-01-02 15:04") + "\n")
+	}
+
+	return sb.String()
+}
+
 // BasicItem interface implementation
 
 func (b *BasicItem) GetID() string   { return b.ID }
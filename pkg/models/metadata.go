@@ -0,0 +1,475 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Well-known metadata keys. Sources store them in the shape registered in wellKnownMetadata; the typed
+// accessors also read the shapes they take after a JSON or YAML round trip.
+const (
+	MetadataFrom          = "from"
+	MetadataTo            = "to"
+	MetadataCC            = "cc"
+	MetadataBCC           = "bcc"
+	MetadataReplyTo       = "reply_to"
+	MetadataMessageID     = "message_id"
+	MetadataThreadID      = "thread_id"
+	MetadataThreadSubject = "thread_subject"
+	MetadataLabels        = "labels"
+	MetadataParticipants  = "participants"
+	MetadataMessageCount  = "message_count"
+	MetadataDurationHours = "duration_hours"
+
+	// Earlier subjects of a thread whose subject changed mid-conversation, oldest first
+	MetadataAlternateSubjects = "alternate_subjects"
+
+	// RFC 5322 message threading headers, Message-IDs without angle brackets
+	MetadataInReplyTo  = "in_reply_to"
+	MetadataReferences = "references"  // []string, oldest first
+	MetadataMessageIDs = "message_ids" // []string: Message-IDs of a consolidated thread's messages
+
+	// Email thread participation
+	MetadataParticipantMessages  = "participant_messages"   // map[string]int: messages sent per participant
+	MetadataLastSender           = "last_sender"            // Sender of the newest message
+	MetadataAverageResponseHours = "average_response_hours" // Average hours until another participant answered
+	MetadataStartTime            = "start_time"
+	MetadataEndTime              = "end_time"
+	MetadataOrganizer            = "organizer"
+	MetadataAttendees            = "attendees"
+	MetadataFolder               = "folder"
+	MetadataOutputFolder         = "output_folder" // Folder a source routes the item to, not written to notes
+	MetadataStatus               = "status"
+	MetadataCompleted            = "completed"
+	MetadataDue                  = "due"  // Task due date, "2006-01-02"
+	MetadataLang                 = "lang" // ISO 639-1 code of the content's language, e.g. "en"
+	MetadataMeetingURL           = "meeting_url"
+	MetadataMeetingProvider      = "meeting_provider" // e.g. "google_meet", "zoom", "teams", "webex", "whereby"
+
+	// Mailing list headers of emails, kept for classification.
+	MetadataListID          = "list_id"
+	MetadataListUnsubscribe = "list_unsubscribe"
+	MetadataPrecedence      = "precedence"     // Precedence header, e.g. "bulk"
+	MetadataAutoSubmitted   = "auto_submitted" // Auto-Submitted header, e.g. "auto-generated"
+	MetadataCategory        = "category"       // Email category: newsletter, notification or personal
+)
+
+// MetadataKind is the value shape of a well-known metadata key.
+type MetadataKind string
+
+const (
+	MetadataKindString     MetadataKind = "string"     // string
+	MetadataKindStrings    MetadataKind = "strings"    // []string
+	MetadataKindInt        MetadataKind = "int"        // int
+	MetadataKindFloat      MetadataKind = "float"      // float64
+	MetadataKindBool       MetadataKind = "bool"       // bool
+	MetadataKindTime       MetadataKind = "time"       // time.Time
+	MetadataKindRecipient  MetadataKind = "recipient"  // Recipient
+	MetadataKindRecipients MetadataKind = "recipients" // []Recipient
+	MetadataKindAttendee   MetadataKind = "attendee"   // Attendee
+	MetadataKindAttendees  MetadataKind = "attendees"  // []Attendee
+)
+
+var wellKnownMetadata = map[string]MetadataKind{
+	MetadataFrom:            MetadataKindRecipient,
+	MetadataTo:              MetadataKindRecipients,
+	MetadataCC:              MetadataKindRecipients,
+	MetadataBCC:             MetadataKindRecipients,
+	MetadataReplyTo:         MetadataKindRecipients,
+	MetadataMessageID:       MetadataKindString,
+	MetadataThreadID:        MetadataKindString,
+	MetadataThreadSubject:   MetadataKindString,
+	MetadataLabels:          MetadataKindStrings,
+	MetadataParticipants:    MetadataKindStrings,
+	MetadataMessageCount:    MetadataKindInt,
+	MetadataDurationHours:   MetadataKindFloat,
+	MetadataStartTime:       MetadataKindTime,
+	MetadataEndTime:         MetadataKindTime,
+	MetadataOrganizer:       MetadataKindAttendee,
+	MetadataAttendees:       MetadataKindAttendees,
+	MetadataFolder:          MetadataKindString,
+	MetadataOutputFolder:    MetadataKindString,
+	MetadataStatus:          MetadataKindString,
+	MetadataCompleted:       MetadataKindBool,
+	MetadataDue:             MetadataKindString,
+	MetadataLang:            MetadataKindString,
+	MetadataMeetingURL:      MetadataKindString,
+	MetadataMeetingProvider: MetadataKindString,
+	MetadataListID:          MetadataKindString,
+	MetadataListUnsubscribe: MetadataKindString,
+	MetadataPrecedence:      MetadataKindString,
+	MetadataAutoSubmitted:   MetadataKindString,
+	MetadataCategory:        MetadataKindString,
+
+	MetadataAlternateSubjects:    MetadataKindStrings,
+	MetadataInReplyTo:            MetadataKindString,
+	MetadataReferences:           MetadataKindStrings,
+	MetadataMessageIDs:           MetadataKindStrings,
+	MetadataLastSender:           MetadataKindString,
+	MetadataAverageResponseHours: MetadataKindFloat,
+}
+
+// MetadataKindOf returns the registered kind of a well-known metadata key.
+func MetadataKindOf(key string) (MetadataKind, bool) {
+	kind, ok := wellKnownMetadata[key]
+
+	return kind, ok
+}
+
+// Recipient is an email sender or recipient.
+type Recipient struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// Identity returns the email address if available, otherwise the name.
+func (r Recipient) Identity() string {
+	if r.Email != "" {
+		return r.Email
+	}
+
+	return r.Name
+}
+
+// Metadata wraps an item's metadata map with typed accessors:
+//
+//	start, ok := models.Metadata(item.GetMetadata()).GetTime(models.MetadataStartTime)
+type Metadata map[string]interface{}
+
+// GetString returns a string value; other types report false.
+func (m Metadata) GetString(key string) (string, bool) {
+	s, ok := m[key].(string)
+
+	return s, ok
+}
+
+// GetStrings returns a list of strings; a single string is returned as a one-element list.
+func (m Metadata) GetStrings(key string) ([]string, bool) {
+	switch v := m[key].(type) {
+	case []string:
+		return v, true
+	case string:
+		return []string{v}, true
+	case []interface{}:
+		strs := make([]string, 0, len(v))
+
+		for _, entry := range v {
+			s, ok := entry.(string)
+			if !ok {
+				return nil, false
+			}
+
+			strs = append(strs, s)
+		}
+
+		return strs, true
+	}
+
+	return nil, false
+}
+
+// GetInt returns an integer value, converting whole floats and numeric strings.
+func (m Metadata) GetInt(key string) (int, bool) {
+	f, ok := m.GetFloat(key)
+	if !ok || f != float64(int(f)) {
+		return 0, false
+	}
+
+	return int(f), true
+}
+
+// GetFloat returns a numeric value of any Go number type, json.Number or numeric string.
+func (m Metadata) GetFloat(key string) (float64, bool) {
+	switch v := m[key].(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+
+		return f, err == nil
+	}
+
+	return 0, false
+}
+
+// GetBool returns a boolean value, parsing "true" and "false" strings.
+func (m Metadata) GetBool(key string) (bool, bool) {
+	switch v := m[key].(type) {
+	case bool:
+		return v, true
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+
+		return b, err == nil
+	}
+
+	return false, false
+}
+
+// GetTime returns a time value, parsing RFC 3339 timestamps and dates.
+func (m Metadata) GetTime(key string) (time.Time, bool) {
+	switch v := m[key].(type) {
+	case time.Time:
+		return v, !v.IsZero()
+	case *time.Time:
+		if v != nil {
+			return *v, !v.IsZero()
+		}
+	case string:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+			if t, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+				return t, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// GetRecipient returns a single recipient, such as the sender in "from".
+func (m Metadata) GetRecipient(key string) (Recipient, bool) {
+	return AsRecipient(m[key])
+}
+
+// GetRecipients returns a list of recipients; a single recipient or an address list string is accepted too.
+func (m Metadata) GetRecipients(key string) ([]Recipient, bool) {
+	switch v := m[key].(type) {
+	case nil:
+		return nil, false
+	case []Recipient:
+		return v, true
+	case string:
+		if addresses, err := mail.ParseAddressList(v); err == nil {
+			recipients := make([]Recipient, 0, len(addresses))
+			for _, address := range addresses {
+				recipients = append(recipients, Recipient{Name: address.Name, Email: address.Address})
+			}
+
+			return recipients, true
+		}
+	}
+
+	if rv := reflect.ValueOf(m[key]); rv.Kind() == reflect.Slice {
+		recipients := make([]Recipient, 0, rv.Len())
+
+		for i := 0; i < rv.Len(); i++ {
+			recipient, ok := AsRecipient(rv.Index(i).Interface())
+			if !ok {
+				return nil, false
+			}
+
+			recipients = append(recipients, recipient)
+		}
+
+		return recipients, true
+	}
+
+	if recipient, ok := AsRecipient(m[key]); ok {
+		return []Recipient{recipient}, true
+	}
+
+	return nil, false
+}
+
+// GetAttendees returns calendar attendees; a single attendee, such as the organizer, is returned as a list.
+func (m Metadata) GetAttendees(key string) ([]Attendee, bool) {
+	return AsAttendees(m[key])
+}
+
+// AsAttendees reads attendees from metadata values stored as models or decoded from JSON/YAML; a single
+// attendee, such as the organizer, is returned as a list.
+func AsAttendees(value interface{}) ([]Attendee, bool) {
+	switch v := value.(type) {
+	case nil:
+		return nil, false
+	case []Attendee:
+		return v, true
+	case []string:
+		attendees := make([]Attendee, 0, len(v))
+		for _, name := range v {
+			attendees = append(attendees, Attendee{DisplayName: name})
+		}
+
+		return attendees, true
+	case []interface{}:
+		attendees := make([]Attendee, 0, len(v))
+		for _, entry := range v {
+			attendees = append(attendees, asAttendee(entry))
+		}
+
+		return attendees, true
+	case Attendee, *Attendee, map[string]interface{}:
+		if attendee := asAttendee(v); attendee.Email != "" || attendee.DisplayName != "" {
+			return []Attendee{attendee}, true
+		}
+	}
+
+	return nil, false
+}
+
+// Normalize converts the values of well-known keys to their registered shape, so items decoded from JSON
+// or YAML read like freshly fetched ones. Values that cannot be converted are left as they are.
+func (m Metadata) Normalize() {
+	for key, kind := range wellKnownMetadata {
+		if _, exists := m[key]; !exists {
+			continue
+		}
+
+		var (
+			value interface{}
+			ok    bool
+		)
+
+		switch kind {
+		case MetadataKindString:
+			value, ok = m.GetString(key)
+		case MetadataKindStrings:
+			value, ok = m.GetStrings(key)
+		case MetadataKindInt:
+			value, ok = m.GetInt(key)
+		case MetadataKindFloat:
+			value, ok = m.GetFloat(key)
+		case MetadataKindBool:
+			value, ok = m.GetBool(key)
+		case MetadataKindTime:
+			value, ok = m.GetTime(key)
+		case MetadataKindRecipient:
+			value, ok = m.GetRecipient(key)
+		case MetadataKindRecipients:
+			value, ok = m.GetRecipients(key)
+		case MetadataKindAttendee:
+			var attendees []Attendee
+			if attendees, ok = m.GetAttendees(key); ok && len(attendees) == 1 {
+				value = attendees[0]
+			} else {
+				ok = false
+			}
+		case MetadataKindAttendees:
+			value, ok = m.GetAttendees(key)
+		}
+
+		if ok {
+			m[key] = value
+		}
+	}
+}
+
+// AsRecipient reads a recipient from a Recipient, an Attendee, a decoded map, a "Name <address>" string or
+// any struct with Name and Email fields.
+func AsRecipient(value interface{}) (Recipient, bool) {
+	switch v := value.(type) {
+	case nil:
+		return Recipient{}, false
+	case Recipient:
+		return v, v.Identity() != ""
+	case *Recipient:
+		if v == nil {
+			return Recipient{}, false
+		}
+
+		return *v, v.Identity() != ""
+	case Attendee:
+		return Recipient{Name: v.DisplayName, Email: v.Email}, v.GetDisplayName() != ""
+	case string:
+		return parseRecipient(v)
+	case map[string]interface{}:
+		recipient := Recipient{Name: firstString(v, "name", "Name", "DisplayName"), Email: firstString(v, "email", "Email")}
+
+		return recipient, recipient.Identity() != ""
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(value))
+	if rv.Kind() != reflect.Struct {
+		return Recipient{}, false
+	}
+
+	var recipient Recipient
+
+	if field := rv.FieldByName("Email"); field.IsValid() && field.Kind() == reflect.String {
+		recipient.Email = field.String()
+	}
+
+	if field := rv.FieldByName("Name"); field.IsValid() && field.Kind() == reflect.String {
+		recipient.Name = field.String()
+	}
+
+	return recipient, recipient.Identity() != ""
+}
+
+// parseRecipient parses an address, falling back to the text between angle brackets, a bare address or a name.
+func parseRecipient(s string) (Recipient, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Recipient{}, false
+	}
+
+	if address, err := mail.ParseAddress(s); err == nil {
+		return Recipient{Name: address.Name, Email: address.Address}, true
+	}
+
+	if start, end := strings.LastIndex(s, "<"), strings.LastIndex(s, ">"); start >= 0 && end > start {
+		return Recipient{Name: strings.Trim(strings.TrimSpace(s[:start]), `"`), Email: s[start+1 : end]}, true
+	}
+
+	if strings.Contains(s, "@") {
+		return Recipient{Email: s}, true
+	}
+
+	return Recipient{Name: s}, true
+}
+
+func asAttendee(value interface{}) Attendee {
+	switch v := value.(type) {
+	case Attendee:
+		return v
+	case *Attendee:
+		if v == nil {
+			return Attendee{}
+		}
+
+		return *v
+	case map[string]interface{}:
+		attendee := Attendee{
+			Email:       firstString(v, "Email", "email"),
+			DisplayName: firstString(v, "DisplayName", "display_name", "name"),
+		}
+		attendee.Self, _ = v["Self"].(bool)
+
+		return attendee
+	case string:
+		recipient, _ := parseRecipient(v)
+
+		return Attendee{Email: recipient.Email, DisplayName: recipient.Name}
+	case nil:
+		return Attendee{}
+	}
+
+	return Attendee{DisplayName: fmt.Sprintf("%v", value)}
+}
+
+func firstString(m map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if s, ok := m[key].(string); ok && s != "" {
+			return s
+		}
+	}
+
+	return ""
+}
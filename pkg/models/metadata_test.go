@@ -0,0 +1,139 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMetadataAccessors(t *testing.T) {
+	start := time.Date(2025, 3, 3, 15, 0, 0, 0, time.UTC)
+
+	metadata := Metadata{
+		MetadataThreadID:      "t1",
+		MetadataStartTime:     start,
+		MetadataEndTime:       "2025-03-03T16:00:00Z",
+		MetadataLabels:        []interface{}{"INBOX", "work"},
+		MetadataMessageCount:  float64(3),
+		MetadataDurationHours: json.Number("1.5"),
+		MetadataFrom:          map[string]interface{}{"name": "Ada", "email": "ada@example.com"},
+		MetadataTo:            `Grace <grace@example.com>, bob@example.com`,
+		"size":                42,
+	}
+
+	if got, ok := metadata.GetString(MetadataThreadID); !ok || got != "t1" {
+		t.Errorf("GetString() = %q, %v", got, ok)
+	}
+
+	if _, ok := metadata.GetString("size"); ok {
+		t.Error("GetString() accepted a number")
+	}
+
+	if got, ok := metadata.GetTime(MetadataStartTime); !ok || !got.Equal(start) {
+		t.Errorf("GetTime(time.Time) = %v, %v", got, ok)
+	}
+
+	if got, ok := metadata.GetTime(MetadataEndTime); !ok || !got.Equal(start.Add(time.Hour)) {
+		t.Errorf("GetTime(string) = %v, %v", got, ok)
+	}
+
+	if got, ok := metadata.GetStrings(MetadataLabels); !ok || !reflect.DeepEqual(got, []string{"INBOX", "work"}) {
+		t.Errorf("GetStrings() = %v, %v", got, ok)
+	}
+
+	if got, ok := metadata.GetInt(MetadataMessageCount); !ok || got != 3 {
+		t.Errorf("GetInt() = %d, %v", got, ok)
+	}
+
+	if got, ok := metadata.GetFloat(MetadataDurationHours); !ok || got != 1.5 {
+		t.Errorf("GetFloat() = %v, %v", got, ok)
+	}
+
+	if got, ok := metadata.GetRecipient(MetadataFrom); !ok || got != (Recipient{Name: "Ada", Email: "ada@example.com"}) {
+		t.Errorf("GetRecipient() = %+v, %v", got, ok)
+	}
+
+	want := []Recipient{{Name: "Grace", Email: "grace@example.com"}, {Email: "bob@example.com"}}
+	if got, ok := metadata.GetRecipients(MetadataTo); !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("GetRecipients() = %+v, %v", got, ok)
+	}
+
+	if _, ok := metadata.GetTime("missing"); ok {
+		t.Error("GetTime() reported a missing key")
+	}
+}
+
+func TestAsRecipient(t *testing.T) {
+	type gmailLike struct {
+		Name  string
+		Email string
+	}
+
+	tests := []struct {
+		value interface{}
+		want  Recipient
+		ok    bool
+	}{
+		{Recipient{Email: "a@example.com"}, Recipient{Email: "a@example.com"}, true},
+		{"Alice Smith <alice@example.com>", Recipient{Name: "Alice Smith", Email: "alice@example.com"}, true},
+		{"alice@example.com", Recipient{Email: "alice@example.com"}, true},
+		{"Ada Lovelace", Recipient{Name: "Ada Lovelace"}, true},
+		{map[string]interface{}{"name": "Charlie Brown"}, Recipient{Name: "Charlie Brown"}, true},
+		{&gmailLike{Name: "Dan", Email: "dan@example.com"}, Recipient{Name: "Dan", Email: "dan@example.com"}, true},
+		{Attendee{Email: "erin@example.com"}, Recipient{Email: "erin@example.com"}, true},
+		{nil, Recipient{}, false},
+		{123, Recipient{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := AsRecipient(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("AsRecipient(%#v) = %+v, %v, want %+v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestMetadataNormalizeDecodedJSON(t *testing.T) {
+	var metadata Metadata
+	if err := json.Unmarshal([]byte(`{
+		"start_time": "2025-03-03T15:00:00Z",
+		"from": {"name": "Ada", "email": "ada@example.com"},
+		"cc": [{"email": "grace@example.com"}],
+		"organizer": {"Email": "me@example.com", "DisplayName": "Me", "Self": true},
+		"message_count": 2,
+		"custom": {"kept": true}
+	}`), &metadata); err != nil {
+		t.Fatal(err)
+	}
+
+	metadata.Normalize()
+
+	if _, ok := metadata[MetadataStartTime].(time.Time); !ok {
+		t.Errorf("start_time = %T, want time.Time", metadata[MetadataStartTime])
+	}
+
+	if from, ok := metadata[MetadataFrom].(Recipient); !ok || from.Email != "ada@example.com" {
+		t.Errorf("from = %#v, want a Recipient", metadata[MetadataFrom])
+	}
+
+	if cc, ok := metadata[MetadataCC].([]Recipient); !ok || len(cc) != 1 {
+		t.Errorf("cc = %#v, want []Recipient", metadata[MetadataCC])
+	}
+
+	if organizer, ok := metadata[MetadataOrganizer].(Attendee); !ok || !organizer.Self {
+		t.Errorf("organizer = %#v, want an Attendee", metadata[MetadataOrganizer])
+	}
+
+	if metadata[MetadataMessageCount] != 2 {
+		t.Errorf("message_count = %#v, want int 2", metadata[MetadataMessageCount])
+	}
+
+	if _, ok := metadata["custom"].(map[string]interface{}); !ok {
+		t.Errorf("unregistered key changed: %#v", metadata["custom"])
+	}
+
+	if kind, ok := MetadataKindOf(MetadataFrom); !ok || kind != MetadataKindRecipient {
+		t.Errorf("MetadataKindOf(from) = %q, %v", kind, ok)
+	}
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+)
+
+// ItemSchemaVersion is the version of the canonical Item JSON schema. Bump it, and add an upgrade to
+// itemUpgrades, when a field is renamed, removed or changes meaning; new optional fields keep the version.
+const ItemSchemaVersion = 1
+
+// ItemSchemaID identifies the published JSON Schema of the current version.
+const ItemSchemaID = "https://github.com/jhjaggars/docs2obsidian/raw/main/pkg/models/schema/item.v1.json"
+
+// ItemSchema is the JSON Schema of the current Item version, also published as schema/item.v1.json.
+//
+//go:embed schema/item.v1.json
+var ItemSchema []byte
+
+// itemUpgrades[v] converts a decoded item of schema version v to version v+1.
+var itemUpgrades = []func(item map[string]interface{}) error{
+	// Version 0 is JSON written before items were versioned, which already has the version 1 shape
+	func(map[string]interface{}) error { return nil },
+}
+
+// CheckItemSchemaVersion rejects versions newer than this release understands, since their fields may
+// have changed meaning.
+func CheckItemSchemaVersion(version int) error {
+	if version < 0 || version > ItemSchemaVersion {
+		return fmt.Errorf("unsupported schema_version %d: this release supports versions 0 to %d, upgrade pkm-sync "+
+			"to read newer items", version, ItemSchemaVersion)
+	}
+
+	return nil
+}
+
+// UpgradeItemJSON converts one item to the current schema version. version is the version of the document
+// the item came in (0 when unversioned); a schema_version property on the item itself takes precedence.
+func UpgradeItemJSON(raw json.RawMessage, version int) (json.RawMessage, error) {
+	var probe struct {
+		SchemaVersion *int `json:"schema_version"`
+	}
+
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, err
+	}
+
+	if probe.SchemaVersion != nil {
+		version = *probe.SchemaVersion
+	}
+
+	if err := CheckItemSchemaVersion(version); err != nil {
+		return nil, err
+	}
+
+	if version == ItemSchemaVersion {
+		return raw, nil
+	}
+
+	var item map[string]interface{}
+	if err := json.Unmarshal(raw, &item); err != nil {
+		return nil, err
+	}
+
+	for v := version; v < ItemSchemaVersion; v++ {
+		if err := itemUpgrades[v](item); err != nil {
+			return nil, fmt.Errorf("failed to upgrade item from schema version %d: %w", v, err)
+		}
+	}
+
+	item["schema_version"] = ItemSchemaVersion
+
+	return json.Marshal(item)
+}
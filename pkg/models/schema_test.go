@@ -0,0 +1,113 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type schemaObject struct {
+	ID         string                     `json:"$id"`
+	Properties map[string]json.RawMessage `json:"properties"`
+	Defs       map[string]schemaObject    `json:"$defs"`
+}
+
+// jsonFields returns the JSON property names of a struct type.
+func jsonFields(v interface{}) []string {
+	var fields []string
+
+	typ := reflect.TypeOf(v)
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+
+	return fields
+}
+
+func keys(properties map[string]json.RawMessage) map[string]bool {
+	set := make(map[string]bool, len(properties))
+	for key := range properties {
+		set[key] = true
+	}
+
+	return set
+}
+
+// TestItemSchemaMatchesModels keeps the published schema in step with the item structs.
+func TestItemSchemaMatchesModels(t *testing.T) {
+	var schema schemaObject
+	if err := json.Unmarshal(ItemSchema, &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+
+	if schema.ID != ItemSchemaID {
+		t.Errorf("schema $id = %q, want %q", schema.ID, ItemSchemaID)
+	}
+
+	checks := []struct {
+		name       string
+		fields     []string
+		properties map[string]json.RawMessage
+	}{
+		{"item", append(jsonFields(BasicItem{}), "messages", "schema_version"), schema.Properties},
+		{"attachment", jsonFields(Attachment{}), schema.Defs["attachment"].Properties},
+		{"link", jsonFields(Link{}), schema.Defs["link"].Properties},
+	}
+
+	for _, check := range checks {
+		properties := keys(check.properties)
+
+		var missing []string
+
+		for _, field := range check.fields {
+			if !properties[field] {
+				missing = append(missing, field)
+			}
+
+			delete(properties, field)
+		}
+
+		var extra []string
+		for property := range properties {
+			extra = append(extra, property)
+		}
+
+		sort.Strings(extra)
+
+		if len(missing) > 0 || len(extra) > 0 {
+			t.Errorf("%s schema out of date: missing %v, not in model %v", check.name, missing, extra)
+		}
+	}
+}
+
+func TestUpgradeItemJSON(t *testing.T) {
+	raw := json.RawMessage(`{"id": "a", "title": "Unversioned"}`)
+
+	upgraded, err := UpgradeItemJSON(raw, 0)
+	if err != nil {
+		t.Fatalf("UpgradeItemJSON() error = %v", err)
+	}
+
+	var item map[string]interface{}
+	if err := json.Unmarshal(upgraded, &item); err != nil {
+		t.Fatal(err)
+	}
+
+	if item["schema_version"] != float64(ItemSchemaVersion) || item["title"] != "Unversioned" {
+		t.Errorf("unexpected upgrade result: %s", upgraded)
+	}
+
+	current := json.RawMessage(`{"id": "b", "title": "Current"}`)
+	if got, err := UpgradeItemJSON(current, ItemSchemaVersion); err != nil || string(got) != string(current) {
+		t.Errorf("current item changed: %s, %v", got, err)
+	}
+
+	if _, err := UpgradeItemJSON(json.RawMessage(`{"schema_version": 2, "title": "Newer"}`), 0); err == nil {
+		t.Error("expected an error for an item from a newer schema version")
+	}
+}
@@ -0,0 +1,447 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// SyntaxError reports an invalid query and where parsing stopped.
+type SyntaxError struct {
+	Query string
+	Pos   int // Byte offset into Query
+	Msg   string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid query %q: %s at position %d", e.Query, e.Msg, e.Pos+1)
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString
+	tokRegex
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+	tokNot
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	pos   int
+	regex *regexp.Regexp
+}
+
+// lexer splits a query into tokens. A "/.../" regular expression is only recognized right after an operator,
+// so slashes in words (paths, dates) need no quoting.
+type lexer struct {
+	src  string
+	pos  int
+	prev tokenKind
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) && unicode.IsSpace(rune(l.src[l.pos])) {
+		l.pos++
+	}
+
+	tok, err := l.scan()
+	if err == nil {
+		l.prev = tok.kind
+	}
+
+	return tok, err
+}
+
+func (l *lexer) scan() (token, error) {
+	start := l.pos
+	if start >= len(l.src) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+
+	c := l.src[start]
+
+	switch {
+	case c == '(':
+		l.pos++
+
+		return token{kind: tokLParen, text: "(", pos: start}, nil
+	case c == ')':
+		l.pos++
+
+		return token{kind: tokRParen, text: ")", pos: start}, nil
+	case c == ',':
+		l.pos++
+
+		return token{kind: tokComma, text: ",", pos: start}, nil
+	case c == '"' || c == '\'':
+		return l.scanString(c)
+	case c == '/' && l.prev == tokOp:
+		return l.scanRegex()
+	case c == '=' || c == '~':
+		l.pos++
+
+		return token{kind: tokOp, text: string(c), pos: start}, nil
+	case c == '!':
+		if start+1 < len(l.src) && (l.src[start+1] == '=' || l.src[start+1] == '~') {
+			l.pos += 2
+
+			return token{kind: tokOp, text: l.src[start : start+2], pos: start}, nil
+		}
+
+		l.pos++
+
+		return token{kind: tokNot, text: "!", pos: start}, nil
+	case c == '-' && l.prev != tokOp:
+		// "-term" negates a term, as in Gmail search
+		l.pos++
+
+		return token{kind: tokNot, text: "-", pos: start}, nil
+	}
+
+	for l.pos < len(l.src) && !isDelimiter(l.src[l.pos]) {
+		l.pos++
+	}
+
+	word := l.src[start:l.pos]
+	if strings.EqualFold(word, "NOT") {
+		return token{kind: tokNot, text: word, pos: start}, nil
+	}
+
+	return token{kind: tokWord, text: word, pos: start}, nil
+}
+
+func isDelimiter(c byte) bool {
+	return unicode.IsSpace(rune(c)) || strings.IndexByte(`()=~!,"'`, c) >= 0
+}
+
+func (l *lexer) scanString(quote byte) (token, error) {
+	start := l.pos
+	l.pos++
+
+	var sb strings.Builder
+
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+
+		switch {
+		case c == '\\' && l.pos+1 < len(l.src):
+			sb.WriteByte(l.src[l.pos+1])
+			l.pos += 2
+		case c == quote:
+			l.pos++
+
+			return token{kind: tokString, text: sb.String(), pos: start}, nil
+		default:
+			sb.WriteByte(c)
+			l.pos++
+		}
+	}
+
+	return token{}, &SyntaxError{Query: l.src, Pos: start, Msg: "unterminated string"}
+}
+
+func (l *lexer) scanRegex() (token, error) {
+	start := l.pos
+	l.pos++
+
+	var sb strings.Builder
+
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+
+		if c == '\\' && l.pos+1 < len(l.src) && l.src[l.pos+1] == '/' {
+			sb.WriteByte('/')
+			l.pos += 2
+
+			continue
+		}
+
+		if c == '/' {
+			l.pos++
+
+			// Regular expressions are case-insensitive, like every other comparison
+			pattern := "(?i)" + sb.String()
+
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return token{}, &SyntaxError{Query: l.src, Pos: start, Msg: "invalid regular expression: " + err.Error()}
+			}
+
+			return token{kind: tokRegex, text: sb.String(), pos: start, regex: re}, nil
+		}
+
+		sb.WriteByte(c)
+		l.pos++
+	}
+
+	return token{}, &SyntaxError{Query: l.src, Pos: start, Msg: "unterminated regular expression"}
+}
+
+// parser is a recursive descent parser over the lexer's tokens:
+//
+//	or         = and { "OR" and }
+//	and        = unary { ["AND"] unary }
+//	unary      = ("NOT" | "!" | "-") unary | primary
+//	primary    = "(" or ")" | call | comparison | shorthand | text
+//	call       = word "(" [value { "," value }] ")"
+//	comparison = word ("=" | "!=" | "~" | "!~") (value | regex)
+//	shorthand  = word ":" value      (Gmail style, e.g. from:boss@company.com)
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+// Parse compiles a query expression.
+func Parse(expr string) (*Query, error) {
+	p := &parser{lex: &lexer{src: expr}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind == tokEOF {
+		return nil, p.errorf("empty query")
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %q", p.tok.text)
+	}
+
+	return &Query{source: expr, root: root}, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+
+	p.tok = tok
+
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Query: p.lex.src, Pos: p.tok.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) isKeyword(keyword string) bool {
+	return p.tok.kind == tokWord && strings.EqualFold(p.tok.text, keyword)
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword("OR") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = orNode{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if p.isKeyword("AND") {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		} else if p.tok.kind == tokEOF || p.tok.kind == tokRParen || p.isKeyword("OR") {
+			return left, nil
+		}
+
+		// Terms next to each other are combined with AND
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = andNode{left, right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.tok.kind == tokNot {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return notNode{operand}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	switch p.tok.kind {
+	case tokLParen:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.tok.kind != tokRParen {
+			return nil, p.errorf("expected \")\"")
+		}
+
+		return inner, p.advance()
+	case tokString:
+		text := p.tok.text
+
+		return compareNode{field: FieldText, op: "~", value: text}, p.advance()
+	case tokWord:
+		return p.parseWord()
+	case tokEOF:
+		return nil, p.errorf("unexpected end of query")
+	default:
+		return nil, p.errorf("unexpected %q", p.tok.text)
+	}
+}
+
+func (p *parser) parseWord() (node, error) {
+	word := p.tok
+
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.tok.kind == tokLParen && p.tok.pos == word.pos+len(word.text):
+		return p.parseCall(word)
+	case p.tok.kind == tokOp:
+		return p.parseComparison(word)
+	}
+
+	if idx := strings.IndexByte(word.text, ':'); idx > 0 {
+		return p.parseShorthand(word, idx)
+	}
+
+	return compareNode{field: FieldText, op: "~", value: word.text}, nil
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	if !strings.EqualFold(name.text, "has") {
+		return nil, &SyntaxError{Query: p.lex.src, Pos: name.pos, Msg: fmt.Sprintf("unknown function %q", name.text)}
+	}
+
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var args []string
+
+	for p.tok.kind != tokRParen {
+		if p.tok.kind != tokWord && p.tok.kind != tokString {
+			return nil, p.errorf("expected an argument to has()")
+		}
+
+		args = append(args, strings.ToLower(p.tok.text))
+
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		if p.tok.kind == tokComma {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(args) != 1 {
+		return nil, &SyntaxError{Query: p.lex.src, Pos: name.pos, Msg: "has() takes one argument"}
+	}
+
+	return hasNode{name: args[0]}, p.advance()
+}
+
+func (p *parser) parseComparison(field token) (node, error) {
+	op := p.tok.text
+
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	switch p.tok.kind {
+	case tokWord, tokString:
+		value := p.tok.text
+
+		return compareNode{field: strings.ToLower(field.text), op: op, value: value}, p.advance()
+	case tokRegex:
+		if op != "~" && op != "!~" {
+			return nil, p.errorf("regular expressions need the ~ or !~ operator")
+		}
+
+		re := p.tok.regex
+
+		return compareNode{field: strings.ToLower(field.text), op: op, regex: re}, p.advance()
+	default:
+		return nil, p.errorf("expected a value after %q", op)
+	}
+}
+
+// parseShorthand handles Gmail-style "field:value" terms. The value may follow as a quoted string
+// (subject:"weekly report"). has:x checks for x; label and tag values must match exactly; other
+// fields match when they contain the value.
+func (p *parser) parseShorthand(word token, idx int) (node, error) {
+	field := strings.ToLower(word.text[:idx])
+	value := word.text[idx+1:]
+
+	if value == "" {
+		if p.tok.kind != tokString {
+			return nil, &SyntaxError{Query: p.lex.src, Pos: word.pos, Msg: fmt.Sprintf("missing value for %q", word.text)}
+		}
+
+		value = p.tok.text
+
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	switch field {
+	case "has":
+		return hasNode{name: strings.ToLower(value)}, nil
+	case "label", "labels", "tag", "tags":
+		return compareNode{field: field, op: "=", value: value}, nil
+	default:
+		return compareNode{field: field, op: "~", value: value}, nil
+	}
+}
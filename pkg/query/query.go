@@ -0,0 +1,117 @@
+// Package query implements the condition language shared by tagging rules, filters, folder routing and
+// deduplication, for example:
+//
+//	from ~ "@company.com" AND has(attachment)
+//	subject ~ /^(re|fwd):/ OR tag = urgent
+//	NOT source = gmail
+//
+// Comparisons are case-insensitive: "=" and "!=" compare whole values, "~" and "!~" check that a value
+// contains the text or matches a /regular expression/. A field with several values (tags, recipients)
+// matches when any value does. Bare words and quoted strings search the title and content, terms next to
+// each other are combined with AND, and Gmail-style terms (from:x, subject:x, label:x, has:attachment)
+// are accepted as shorthand.
+package query
+
+import (
+	"regexp"
+	"strings"
+)
+
+// FieldText is the field searched by bare words: the title and content.
+const FieldText = "text"
+
+// Record is something a query can be evaluated against.
+type Record interface {
+	// Values returns the values of a field; fields with several values (tags, recipients) return each.
+	Values(field string) []string
+	// Has reports whether the record has something, such as an attachment.
+	Has(name string) bool
+}
+
+// Query is a parsed condition.
+type Query struct {
+	source string
+	root   node
+}
+
+// Match reports whether a record satisfies the query.
+func (q *Query) Match(r Record) bool {
+	return q.root.eval(r)
+}
+
+// String returns the expression the query was parsed from.
+func (q *Query) String() string {
+	return q.source
+}
+
+type node interface {
+	eval(r Record) bool
+}
+
+type andNode struct{ left, right node }
+
+func (n andNode) eval(r Record) bool {
+	return n.left.eval(r) && n.right.eval(r)
+}
+
+type orNode struct{ left, right node }
+
+func (n orNode) eval(r Record) bool {
+	return n.left.eval(r) || n.right.eval(r)
+}
+
+type notNode struct{ operand node }
+
+func (n notNode) eval(r Record) bool {
+	return !n.operand.eval(r)
+}
+
+type hasNode struct{ name string }
+
+func (n hasNode) eval(r Record) bool {
+	return r.Has(n.name)
+}
+
+type compareNode struct {
+	field string
+	op    string
+	value string
+	regex *regexp.Regexp
+}
+
+func (n compareNode) eval(r Record) bool {
+	values := r.Values(n.field)
+
+	switch n.op {
+	case "!=":
+		return !anyValue(values, n.equals)
+	case "~":
+		return anyValue(values, n.contains)
+	case "!~":
+		return !anyValue(values, n.contains)
+	default:
+		return anyValue(values, n.equals)
+	}
+}
+
+func (n compareNode) equals(value string) bool {
+	return strings.EqualFold(strings.TrimSpace(value), n.value)
+}
+
+func (n compareNode) contains(value string) bool {
+	if n.regex != nil {
+		return n.regex.MatchString(value)
+	}
+
+	return strings.Contains(strings.ToLower(value), strings.ToLower(n.value))
+}
+
+func anyValue(values []string, match func(string) bool) bool {
+	for _, value := range values {
+		if match(value) {
+			return true
+		}
+	}
+
+	return false
+}
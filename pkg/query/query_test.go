@@ -0,0 +1,115 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	"pkm-sync/pkg/models"
+)
+
+type recipient struct {
+	Name  string
+	Email string
+}
+
+func testItem() models.ItemInterface {
+	item := models.NewBasicItem("msg-1", "Re: Q3 invoice")
+	item.SetContent("Please find the invoice attached.")
+	item.SetSourceType("gmail")
+	item.SetItemType("email")
+	item.SetTags([]string{"finance", "Urgent"})
+	item.SetAttachments([]models.Attachment{{Name: "invoice.pdf"}})
+	item.SetMetadata(map[string]interface{}{
+		"from":   recipient{Name: "Alice", Email: "alice@company.com"},
+		"to":     []recipient{{Email: "me@example.com"}, {Email: "bob@company.com"}},
+		"labels": []string{"INBOX", "Label_7"},
+	})
+
+	return item
+}
+
+func TestMatchItem(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{`from ~ "@company.com" AND has(attachment)`, true},
+		{`from ~ "@other.com" AND has(attachment)`, false},
+		{`from = alice@company.com`, true},
+		{`from = "Alice"`, true},
+		{`from != alice@company.com`, false},
+		{`to ~ bob`, true},
+		{`subject ~ /^(re|fwd):/`, true},
+		{`subject !~ /^fwd:/`, true},
+		{`tag = urgent OR tag = later`, true},
+		{`tag = later`, false},
+		{`NOT source = gmail`, false},
+		{`-source:calendar`, true},
+		{`!has(link)`, true},
+		{`invoice attached`, true},
+		{`invoice "not here"`, false},
+		{`(type = event OR type = email) and has:attachment`, true},
+		{`attachment ~ .pdf`, true},
+		{`from:alice@company.com`, true},
+		{`subject:"q3 invoice"`, true},
+		{`label:inbox`, true},
+		{`label:inb`, false},
+		{`has:attachment`, true},
+		{`meta.from ~ alice`, true},
+		{`calendar_id = primary`, false},
+	}
+
+	item := testItem()
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if got := q.MatchItem(item); got != tt.want {
+				t.Errorf("MatchItem() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchThreadMessages(t *testing.T) {
+	thread := models.NewThread("thread-1", "Planning")
+	thread.SetSourceType("gmail")
+	thread.AddMessage(testItem())
+
+	q, err := Parse(`from ~ "@company.com" AND has(attachment)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !q.MatchItem(thread) {
+		t.Error("MatchItem() should match fields of thread messages")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		`from ~`,
+		`(tag = a`,
+		`tag = a)`,
+		`subject ~ "unterminated`,
+		`subject = /regex/`,
+		`subject ~ /[/`,
+		`count(tags)`,
+		`has(a, b)`,
+		`tag = a OR`,
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := Parse(expr)
+
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Errorf("Parse(%q) error = %v, want *SyntaxError", expr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"pkm-sync/pkg/models"
+)
+
+// itemRecord evaluates queries against an item. Thread items also match on the fields of their messages.
+type itemRecord struct {
+	item models.ItemInterface
+}
+
+// ItemRecord returns a Record for an item. Fields:
+//
+//	title (subject), content (body), text (title and content), id, source, type, tag (tags),
+//	label (labels), attachment (attachment names), link (link URLs)
+//
+// Any other field is read from the item's metadata, e.g. from, to, calendar_id or meta.from.
+// has() accepts attachment, link, tag or any field name.
+func ItemRecord(item models.ItemInterface) Record {
+	return itemRecord{item: item}
+}
+
+// MatchItem reports whether an item satisfies a query.
+func (q *Query) MatchItem(item models.ItemInterface) bool {
+	return q.Match(ItemRecord(item))
+}
+
+func (r itemRecord) Values(field string) []string {
+	values := itemValues(r.item, field)
+
+	switch field {
+	case "id", "source", "type":
+		// Identity fields describe the thread itself, not its messages
+		return values
+	}
+
+	if thread, ok := models.AsThread(r.item); ok {
+		for _, message := range thread.GetMessages() {
+			values = append(values, itemValues(message, field)...)
+		}
+	}
+
+	return values
+}
+
+func (r itemRecord) Has(name string) bool {
+	switch name {
+	case "attachment", "attachments":
+		name = "attachment"
+	case "link", "links":
+		name = "link"
+	case "tag", "tags":
+		name = "tag"
+	}
+
+	for _, value := range r.Values(name) {
+		if strings.TrimSpace(value) != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func itemValues(item models.ItemInterface, field string) []string {
+	switch field {
+	case "title", "subject":
+		return []string{item.GetTitle()}
+	case "content", "body":
+		return []string{item.GetContent()}
+	case FieldText:
+		return []string{item.GetTitle(), item.GetContent()}
+	case "id":
+		return []string{item.GetID()}
+	case "source":
+		return []string{item.GetSourceType()}
+	case "type":
+		return []string{item.GetItemType()}
+	case "tag", "tags":
+		return item.GetTags()
+	case "label", "labels":
+		return flatten(item.GetMetadata()["labels"])
+	case "attachment", "attachments":
+		var names []string
+		for _, attachment := range item.GetAttachments() {
+			names = append(names, attachment.Name)
+		}
+
+		return names
+	case "link", "links":
+		var urls []string
+		for _, link := range item.GetLinks() {
+			urls = append(urls, link.URL)
+		}
+
+		return urls
+	}
+
+	return flatten(item.GetMetadata()[strings.TrimPrefix(field, "meta.")])
+}
+
+// flatten turns a metadata value into the strings a query compares: lists and maps yield each element,
+// structs (such as email recipients) each exported field.
+func flatten(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case time.Time:
+		return []string{v.Format(time.RFC3339)}
+	case fmt.Stringer:
+		return []string{v.String()}
+	}
+
+	rv := reflect.ValueOf(value)
+
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+
+		return flatten(rv.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		var values []string
+		for i := 0; i < rv.Len(); i++ {
+			values = append(values, flatten(rv.Index(i).Interface())...)
+		}
+
+		return values
+	case reflect.Map:
+		var values []string
+		for _, key := range rv.MapKeys() {
+			values = append(values, flatten(rv.MapIndex(key).Interface())...)
+		}
+
+		return values
+	case reflect.Struct:
+		var values []string
+		for i := 0; i < rv.NumField(); i++ {
+			if rv.Type().Field(i).IsExported() {
+				values = append(values, flatten(rv.Field(i).Interface())...)
+			}
+		}
+
+		return values
+	default:
+		return []string{fmt.Sprint(value)}
+	}
+}